@@ -289,6 +289,12 @@ func newClusterSetParasCmd(client *master.MasterClient) *cobra.Command {
 	dataMediaType := ""
 	handleTimeout := ""
 	readDataNodeTimeout := ""
+	metaSnapshotPersistenceMode := ""
+	metaSnapshotSendRate := ""
+	metaSnapshotRecvRate := ""
+	metaSnapshotPartitionRate := ""
+	mpHeatSplitOpRate := ""
+	mpHeatSplitInterval := ""
 	cmd := &cobra.Command{
 		Use:   CliOpSetCluster,
 		Short: cmdClusterSetClusterInfoShort,
@@ -423,12 +429,20 @@ func newClusterSetParasCmd(client *master.MasterClient) *cobra.Command {
 				}
 			}
 
+			if metaSnapshotPersistenceMode != "" {
+				if _, err = proto.ParsePersistenceMode(metaSnapshotPersistenceMode); err != nil {
+					return
+				}
+			}
+
 			if err = client.AdminAPI().SetClusterParas(optDelBatchCount, optMarkDeleteRate, optDelWorkerSleepMs,
 				optAutoRepairRate, optLoadFactor, opMaxDpCntLimit, opMaxMpCntLimit, clientIDKey,
 				autoDecommissionDisk, autoDecommissionDiskInterval,
 				autoDpMetaRepair, autoDpMetaRepairParallelCnt,
 				dpRepairTimeout, dpTimeout, mpTimeout, dpBackupTimeout, decommissionDpLimit, decommissionDiskLimit,
-				forbidWriteOpOfProtoVersion0, dataMediaType, handleTimeout, readDataNodeTimeout); err != nil {
+				forbidWriteOpOfProtoVersion0, dataMediaType, handleTimeout, readDataNodeTimeout, metaSnapshotPersistenceMode,
+				metaSnapshotSendRate, metaSnapshotRecvRate, metaSnapshotPartitionRate,
+				mpHeatSplitOpRate, mpHeatSplitInterval); err != nil {
 				return
 			}
 			stdout("Cluster parameters has been set successfully. \n")
@@ -462,6 +476,18 @@ func newClusterSetParasCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&dataMediaType, "clusterDataMediaType", "", "set cluster media type, 1(ssd), 2(hdd)")
 	cmd.Flags().StringVar(&handleTimeout, "flashNodeHandleReadTimeout", "", "Specify flash node handle read timeout (example:1000ms)")
 	cmd.Flags().StringVar(&readDataNodeTimeout, "flashNodeReadDataNodeTimeout", "", "Specify flash node read data node timeout (example:3000ms)")
+	cmd.Flags().StringVar(&metaSnapshotPersistenceMode, "metaSnapshotPersistenceMode", "",
+		"Persistence mode of the snapshot files of meta partitions: [writeThrough | writeBack]")
+	cmd.Flags().StringVar(&metaSnapshotSendRate, "metaSnapshotSendRateMB", "",
+		"MB/s of the snapshots sent by a meta node, 0 keeps the one of the node")
+	cmd.Flags().StringVar(&metaSnapshotRecvRate, "metaSnapshotRecvRateMB", "",
+		"MB/s of the snapshots received by a meta node, 0 keeps the one of the node")
+	cmd.Flags().StringVar(&metaSnapshotPartitionRate, "metaSnapshotPartitionRateMB", "",
+		"MB/s of the snapshot of a meta partition sent or received, 0 keeps the one of the node")
+	cmd.Flags().StringVar(&mpHeatSplitOpRate, "mpHeatSplitOpRate", "",
+		"Write ops per second of the last meta partition of a volume to split it early, 0 disables")
+	cmd.Flags().StringVar(&mpHeatSplitInterval, "mpHeatSplitInterval", "",
+		"Seconds between the splits of the meta partitions of a volume by heat, 0 is the default 600")
 	return cmd
 }
 
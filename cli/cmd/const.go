@@ -152,6 +152,15 @@ const (
 	CliFlagTrashInterval                = "trashInterval"
 	CliFlagAccessTimeValidInterval      = "accessTimeValidInterval"
 	CliFlagEnablePersistAccessTime      = "enablePersistAccessTime"
+	CliFlagAtimeMode                    = "atimeMode"
+	CliFlagXAttrMaxCount                = "xattrMaxCount"
+	CliFlagXAttrMaxKeySize              = "xattrMaxKeySize"
+	CliFlagXAttrMaxValueSize            = "xattrMaxValueSize"
+	CliFlagXAttrMaxTotalSize            = "xattrMaxTotalSize"
+	CliFlagExtentConflictPolicy         = "extentConflictPolicy"
+	CliFlagEnableOpAudit                = "enableOpAudit"
+	CliFlagEnableCheckExtentKey         = "enableCheckExtentKey"
+	CliFlagEnableDirStat                = "enableDirStat"
 	CliFlagDecommissionRaftForce        = "raftForceDel"
 	CliFLagDecommissionWeight           = "decommissionWeight"
 	CliFlagDecommissionDstNodeSet       = "decommissionDstNodeSet"
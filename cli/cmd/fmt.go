@@ -278,6 +278,12 @@ func formatSimpleVolView(svv *proto.SimpleVolView) string {
 	sb.WriteString(fmt.Sprintf("  AccessTimeValidInterval         : %v\n", time.Duration(svv.AccessTimeInterval)*time.Second))
 	sb.WriteString(fmt.Sprintf("  MetaLeaderRetryTimeout          : %v\n", time.Duration(svv.LeaderRetryTimeOut)*time.Second))
 	sb.WriteString(fmt.Sprintf("  EnablePersistAccessTime         : %v\n", svv.EnablePersistAccessTime))
+	sb.WriteString(fmt.Sprintf("  AtimeMode                       : %v\n", svv.AtimeMode))
+	sb.WriteString(fmt.Sprintf("  XAttrLimit                      : %v\n", formatXAttrLimit(svv.XAttrLimit)))
+	sb.WriteString(fmt.Sprintf("  ExtentConflictPolicy            : %v\n", formatExtentConflictPolicy(svv.ExtentConflictPolicy)))
+	sb.WriteString(fmt.Sprintf("  EnableOpAudit                   : %v\n", svv.EnableOpAudit))
+	sb.WriteString(fmt.Sprintf("  EnableCheckExtentKey            : %v\n", svv.EnableCheckExtentKey))
+	sb.WriteString(fmt.Sprintf("  EnableDirStat                   : %v\n", svv.EnableDirStat))
 	sb.WriteString(fmt.Sprintf("  ForbidWriteOpOfProtoVer0        : %v\n", svv.ForbidWriteOpOfProtoVer0))
 	if svv.Forbidden && svv.Status == 1 {
 		sb.WriteString(fmt.Sprintf("  DeleteDelayTime                 : %v\n", time.Until(svv.DeleteExecTime)))
@@ -362,6 +368,16 @@ func formatVolInfoTableRow(vi *proto.VolInfo) string {
 		formatVolumeStatus(vi.Status), time.Unix(vi.CreateTime, 0).Local().Format(time.RFC1123))
 }
 
+var (
+	volumeClientTablePattern = "%-20v    %-8v    %-12v    %-12v    %-30v    %-30v"
+	volumeClientTableHeader  = fmt.Sprintf(volumeClientTablePattern, "HOST", "PID", "ROLE", "VERSION", "MOUNT TIME", "LAST KEEPALIVE")
+)
+
+func formatVolClientTableRow(c *proto.VolClientInfo) string {
+	return fmt.Sprintf(volumeClientTablePattern, c.Host, c.Pid, c.Role, c.Version,
+		time.Unix(c.MountTime, 0).Local().Format(time.RFC1123), time.Unix(c.LastKeepAlive, 0).Local().Format(time.RFC1123))
+}
+
 func quotaLimitStr(cap uint64) string {
 	if cap == 0 {
 		return "no limit(0)"
@@ -891,6 +907,25 @@ func formatEnabledDisabled(b bool) string {
 	return "Disabled"
 }
 
+func formatExtentConflictPolicy(policy string) string {
+	if policy == proto.ExtentConflictPolicyNone {
+		return "none"
+	}
+	return policy
+}
+
+// formatXAttrLimit shows the zero fields of limit as default, which are decided by metanode.
+func formatXAttrLimit(limit proto.XAttrLimit) string {
+	format := func(v uint64) string {
+		if v == 0 {
+			return "default"
+		}
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("count(%v) keySize(%v) valueSize(%v) totalSize(%v)", format(uint64(limit.MaxCount)),
+		format(uint64(limit.MaxKeySize)), format(uint64(limit.MaxValueSize)), format(limit.MaxTotalSize))
+}
+
 func formatNodeStatus(status bool) string {
 	if status {
 		return "Active"
@@ -1178,6 +1213,10 @@ func formatMetaNodeDetail(mn *proto.MetaNodeInfo, rowTable bool) string {
 	sb.WriteString(fmt.Sprintf("  Can alloc partition : %v\n", mn.CanAllowPartition))
 	sb.WriteString(fmt.Sprintf("  Max partition count : %v\n", mn.MaxMpCntLimit))
 	sb.WriteString(fmt.Sprintf("  CpuUtil             : %.1f%%\n", mn.CpuUtil))
+	sb.WriteString(fmt.Sprintf("  Draining            : %v\n", mn.Draining))
+	sb.WriteString(fmt.Sprintf("  Drained             : %v\n", mn.Drained))
+	sb.WriteString(fmt.Sprintf("  NoPlacement         : %v\n", mn.NoPlacement))
+	sb.WriteString(fmt.Sprintf("  MediaType           : %v\n", proto.MediaTypeString(mn.MediaType)))
 	return sb.String()
 }
 
@@ -65,6 +65,7 @@ func newVolCmd(client *master.MasterClient) *cobra.Command {
 		newVolQueryOpCmd(client),
 		newVolGetInodeByIdCmd(client),
 		newVolCheckDomain(client),
+		newVolClientsCmd(client),
 	)
 	return cmd
 }
@@ -384,6 +385,15 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	var optTrashInterval int64
 	var optAccessTimeValidInterval int64
 	var optEnablePersistAccessTime string
+	var optAtimeMode string
+	var optXAttrMaxCount int64
+	var optXAttrMaxKeySize int64
+	var optXAttrMaxValueSize int64
+	var optXAttrMaxTotalSize int64
+	var optExtentConflictPolicy string
+	var optEnableOpAudit string
+	var optEnableCheckExtentKey string
+	var optEnableDirStat string
 	var optVolStorageClass int
 	var optForbidWriteOpOfProtoVer0 string
 	var optVolQuotaClass int
@@ -698,6 +708,108 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 			} else {
 				confirmString.WriteString(fmt.Sprintf("  EnablePersistAccessTime        : %v \n", vv.EnablePersistAccessTime))
 			}
+
+			if optAtimeMode != "" && optAtimeMode != vv.AtimeMode {
+				if !proto.IsValidAtimeMode(optAtimeMode) {
+					err = fmt.Errorf("invalid atime mode %v, expect %v, %v or %v", optAtimeMode,
+						proto.AtimeModeStrict, proto.AtimeModeRelatime, proto.AtimeModeNoatime)
+					return
+				}
+				isChange = true
+				confirmString.WriteString(fmt.Sprintf("  AtimeMode                      : %v -> %v \n", vv.AtimeMode, optAtimeMode))
+				vv.AtimeMode = optAtimeMode
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  AtimeMode                      : %v \n", vv.AtimeMode))
+			}
+
+			xattrLimit := vv.XAttrLimit
+			if optXAttrMaxCount >= 0 {
+				xattrLimit.MaxCount = uint32(optXAttrMaxCount)
+			}
+			if optXAttrMaxKeySize >= 0 {
+				xattrLimit.MaxKeySize = uint32(optXAttrMaxKeySize)
+			}
+			if optXAttrMaxValueSize >= 0 {
+				xattrLimit.MaxValueSize = uint32(optXAttrMaxValueSize)
+			}
+			if optXAttrMaxTotalSize >= 0 {
+				xattrLimit.MaxTotalSize = uint64(optXAttrMaxTotalSize)
+			}
+			if xattrLimit != vv.XAttrLimit {
+				isChange = true
+				confirmString.WriteString(fmt.Sprintf("  XAttrLimit                     : %v -> %v \n",
+					formatXAttrLimit(vv.XAttrLimit), formatXAttrLimit(xattrLimit)))
+				vv.XAttrLimit = xattrLimit
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  XAttrLimit                     : %v \n", formatXAttrLimit(vv.XAttrLimit)))
+			}
+
+			if optExtentConflictPolicy != "" {
+				policy := optExtentConflictPolicy
+				if policy == "none" {
+					policy = proto.ExtentConflictPolicyNone
+				}
+				if !proto.IsValidExtentConflictPolicy(policy) {
+					err = fmt.Errorf("invalid extent conflict policy %v, expect none, %v or %v", optExtentConflictPolicy,
+						proto.ExtentConflictPolicyReject, proto.ExtentConflictPolicyLWW)
+					return
+				}
+				if policy != vv.ExtentConflictPolicy {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  ExtentConflictPolicy           : %v -> %v \n",
+						formatExtentConflictPolicy(vv.ExtentConflictPolicy), formatExtentConflictPolicy(policy)))
+					vv.ExtentConflictPolicy = policy
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  ExtentConflictPolicy           : %v \n", formatExtentConflictPolicy(vv.ExtentConflictPolicy)))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  ExtentConflictPolicy           : %v \n", formatExtentConflictPolicy(vv.ExtentConflictPolicy)))
+			}
+			if optEnableOpAudit != "" {
+				enable := false
+				if enable, err = strconv.ParseBool(optEnableOpAudit); err != nil {
+					return
+				}
+				if vv.EnableOpAudit != enable {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  EnableOpAudit                  : %v -> %v \n", vv.EnableOpAudit, enable))
+					vv.EnableOpAudit = enable
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  EnableOpAudit                  : %v \n", vv.EnableOpAudit))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  EnableOpAudit                  : %v \n", vv.EnableOpAudit))
+			}
+			if optEnableCheckExtentKey != "" {
+				enable := false
+				if enable, err = strconv.ParseBool(optEnableCheckExtentKey); err != nil {
+					return
+				}
+				if vv.EnableCheckExtentKey != enable {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  EnableCheckExtentKey           : %v -> %v \n", vv.EnableCheckExtentKey, enable))
+					vv.EnableCheckExtentKey = enable
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  EnableCheckExtentKey           : %v \n", vv.EnableCheckExtentKey))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  EnableCheckExtentKey           : %v \n", vv.EnableCheckExtentKey))
+			}
+			if optEnableDirStat != "" {
+				enable := false
+				if enable, err = strconv.ParseBool(optEnableDirStat); err != nil {
+					return
+				}
+				if vv.EnableDirStat != enable {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  EnableDirStat                  : %v -> %v \n", vv.EnableDirStat, enable))
+					vv.EnableDirStat = enable
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  EnableDirStat                  : %v \n", vv.EnableDirStat))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  EnableDirStat                  : %v \n", vv.EnableDirStat))
+			}
 			if optEnableDpAutoMetaRepair != "" {
 				enable := false
 				if enable, err = strconv.ParseBool(optEnableDpAutoMetaRepair); err != nil {
@@ -924,6 +1036,15 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().Int64Var(&optTrashInterval, CliFlagTrashInterval, -1, "The retention period for files in trash")
 	cmd.Flags().Int64Var(&optAccessTimeValidInterval, CliFlagAccessTimeValidInterval, -1, fmt.Sprintf("Effective time interval for accesstime, at least %v [Unit: second]", proto.MinAccessTimeValidInterval))
 	cmd.Flags().StringVar(&optEnablePersistAccessTime, CliFlagEnablePersistAccessTime, "", "true/false to enable/disable persisting access time")
+	cmd.Flags().StringVar(&optAtimeMode, CliFlagAtimeMode, "", "Access time update policy, strict and relatime require enablePersistAccessTime: [strict | relatime | noatime]")
+	cmd.Flags().Int64Var(&optXAttrMaxCount, CliFlagXAttrMaxCount, -1, "Max number of xattrs of an inode, 0 to use the default of metanode")
+	cmd.Flags().Int64Var(&optXAttrMaxKeySize, CliFlagXAttrMaxKeySize, -1, "Max bytes of a xattr key, 0 to use the default of metanode")
+	cmd.Flags().Int64Var(&optXAttrMaxValueSize, CliFlagXAttrMaxValueSize, -1, "Max bytes of a xattr value, 0 to use the default of metanode")
+	cmd.Flags().Int64Var(&optXAttrMaxTotalSize, CliFlagXAttrMaxTotalSize, -1, "Max bytes of all the xattrs of an inode, 0 to use the default of metanode")
+	cmd.Flags().StringVar(&optExtentConflictPolicy, CliFlagExtentConflictPolicy, "", "Policy of appended extent keys overlapping other extents: [none | reject | lww]")
+	cmd.Flags().StringVar(&optEnableOpAudit, CliFlagEnableOpAudit, "", "Enable the op audit log of namespace mutations on metanode: [true | false]")
+	cmd.Flags().StringVar(&optEnableCheckExtentKey, CliFlagEnableCheckExtentKey, "", "Enable checking the extent keys appended on metanode: [true | false]")
+	cmd.Flags().StringVar(&optEnableDirStat, CliFlagEnableDirStat, "", "Enable the stats of the subtrees kept on the dirs by metanode: [true | false]")
 	cmd.Flags().StringVar(&optForbidWriteOpOfProtoVer0, CliForbidWriteOpOfProtoVersion0, "",
 		"set volume forbid write operates of packet whose protocol version is version-0: [true | false]")
 
@@ -1439,6 +1560,40 @@ func newVolSetDpRepairBlockSize(client *master.MasterClient) *cobra.Command {
 	return cmd
 }
 
+var (
+	cmdVolClientsUse   = "clients [VOLUME]"
+	cmdVolClientsShort = "List clients mounting the volume"
+)
+
+func newVolClientsCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdVolClientsUse,
+		Short: cmdVolClientsShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			clients, err := client.AdminAPI().GetVolumeClients(args[0])
+			if err != nil {
+				return
+			}
+			stdout("%v\n", volumeClientTableHeader)
+			for _, c := range clients {
+				stdout("%v\n", formatVolClientTableRow(c))
+			}
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
 var (
 	cmdVolSetTrashIntervalUse   = "set-trash-interval [VOLUME] [INTERVAL MINUTES]"
 	cmdVolSetTrashIntervalShort = "set trash interval for volume"
@@ -353,7 +353,7 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 		if dentryInfo == nil {
 			lookupMetric := exporter.NewCounter("lookupDcacheMiss")
 			lookupMetric.AddWithLabels(1, map[string]string{exporter.Vol: d.super.volname})
-			ino, _, err = d.super.mw.Lookup_ll(d.info.Inode, req.Name)
+			ino, err = d.lookup(req.Name)
 			if err != nil {
 				if err != syscall.ENOENT {
 					log.LogErrorf("Lookup: parent(%v) name(%v) err(%v)", d.info.Inode, req.Name, err)
@@ -373,7 +373,7 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 	} else {
 		cino, ok := d.dcache.Get(req.Name)
 		if !ok {
-			cino, _, err = d.super.mw.Lookup_ll(d.info.Inode, req.Name)
+			cino, err = d.lookup(req.Name)
 			if err != nil {
 				if err != syscall.ENOENT {
 					log.LogErrorf("Lookup: parent(%v) name(%v) err(%v)", d.info.Inode, req.Name, err)
@@ -468,7 +468,7 @@ func (d *Dir) ReadDir(ctx context.Context, req *fuse.ReadRequest, resp *fuse.Rea
 	} else {
 		dirCtx = DirContext{}
 	}
-	children, err := d.super.mw.ReadDirLimit_ll(d.info.Inode, dirCtx.Name, limit)
+	children, infos, err := d.super.mw.ReadDirPlus_ll(d.info.Inode, dirCtx.Name, limit)
 	if err != nil {
 		log.LogErrorf("readdirlimit: Readdir: ino(%v) err(%v) offset %v", d.info.Inode, err, req.Offset)
 		return make([]fuse.Dirent, 0), ParseError(err)
@@ -519,7 +519,6 @@ func (d *Dir) ReadDir(ctx context.Context, req *fuse.ReadRequest, resp *fuse.Rea
 	dirCtx.Name = children[len(children)-1].Name
 	d.dctx.Put(req.Handle, &dirCtx)
 
-	inodes := make([]uint64, 0, len(children))
 	dirents := make([]fuse.Dirent, 0, len(children))
 
 	log.LogDebugf("Readdir ino(%v) path(%v) d.super.bcacheDir(%v)", d.info.Inode, d.getCwd(), d.super.bcacheDir)
@@ -540,7 +539,6 @@ func (d *Dir) ReadDir(ctx context.Context, req *fuse.ReadRequest, resp *fuse.Rea
 			Name:  child.Name,
 		}
 
-		inodes = append(inodes, child.Inode)
 		dirents = append(dirents, dentry)
 		if dcachev2 {
 			info := &proto.DentryInfo{
@@ -553,7 +551,6 @@ func (d *Dir) ReadDir(ctx context.Context, req *fuse.ReadRequest, resp *fuse.Rea
 		}
 	}
 
-	infos := d.super.mw.BatchInodeGet(inodes)
 	for _, info := range infos {
 		cacheInfo := d.super.ic.Get(info.Inode)
 		if cacheInfo != nil {
@@ -590,8 +587,9 @@ func (d *Dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 	noMore := false
 	from := ""
 	var children []proto.Dentry
+	var infos []*proto.InodeInfo
 	for !noMore {
-		batches, err := d.super.mw.ReadDirLimit_ll(d.info.Inode, from, DefaultReaddirLimit)
+		batches, batchInfos, err := d.super.mw.ReadDirPlus_ll(d.info.Inode, from, DefaultReaddirLimit)
 		if err != nil {
 			log.LogErrorf("Readdir: ino(%v) err(%v) from(%v)", d.info.Inode, err, from)
 			return make([]fuse.Dirent, 0), ParseError(err)
@@ -606,10 +604,10 @@ func (d *Dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 			batches = batches[1:]
 		}
 		children = append(children, batches...)
+		infos = append(infos, batchInfos...)
 		from = batches[len(batches)-1].Name
 	}
 
-	inodes := make([]uint64, 0, len(children))
 	dirents := make([]fuse.Dirent, 0, len(children))
 
 	log.LogDebugf("Readdir ino(%v) path(%v) d.super.bcacheDir(%v)", d.info.Inode, d.getCwd(), d.super.bcacheDir)
@@ -630,7 +628,6 @@ func (d *Dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 			Name:  child.Name,
 		}
 
-		inodes = append(inodes, child.Inode)
 		dirents = append(dirents, dentry)
 		if dcachev2 {
 			info := &proto.DentryInfo{
@@ -643,7 +640,6 @@ func (d *Dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 		}
 	}
 
-	infos := d.super.mw.BatchInodeGet(inodes)
 	for _, info := range infos {
 		d.super.ic.Put(info)
 	}
@@ -1070,6 +1066,21 @@ func (d *Dir) getCwd() string {
 	return buildPath(pathComponents)
 }
 
+// lookup looks up the name in the directory, the inodes of the siblings following it returned
+// with it warm the inode cache for the lookups of them to come.
+func (d *Dir) lookup(name string) (ino uint64, err error) {
+	ino, _, siblings, err := d.super.mw.LookupWithSiblings_ll(d.info.Inode, name)
+	if err != nil {
+		return
+	}
+	for _, sibling := range siblings {
+		if sibling.Info != nil {
+			d.super.ic.Put(sibling.Info)
+		}
+	}
+	return
+}
+
 func (d *Dir) needDentrycache() bool {
 	// TODO: cannot find .git when git clone
 	// return !DisableMetaCache && d.super.bcacheDir != "" && strings.HasPrefix(d.getCwd(), d.super.bcacheDir)
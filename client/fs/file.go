@@ -276,6 +276,7 @@ func (f *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenR
 		f.super.ec.OpenStream(ino, openForWrite, isCache, path.Join(f.getParentPath(), f.name))
 	}
 	log.LogDebugf("TRACE open ino(%v) f.super.bcacheDir(%v) needBCache(%v)", ino, f.super.bcacheDir, needBCache)
+	f.super.mw.AcquireAttrLease(ino)
 
 	f.super.ec.RefreshExtentsCache(ino)
 
@@ -369,6 +370,13 @@ func (f *File) Release(ctx context.Context, req *fuse.ReleaseRequest) (err error
 	//	f.fWriter.Close()
 	// }
 
+	f.super.mw.ReleaseAttrLease(ino)
+	if req.ReleaseFlags&fuse.ReleaseFlockUnlock != 0 {
+		// the flocks of the file are released once it is closed, as on a local file system
+		if lerr := f.super.mw.ReleaseFileLocks(ino, req.LockOwner, true); lerr != nil {
+			log.LogWarnf("Release: release flocks failed, ino(%v) req(%v) err(%v)", ino, req, lerr)
+		}
+	}
 	err = f.super.ec.CloseStream(ino)
 	if err != nil {
 		log.LogErrorf("Release: close writer failed, ino(%v) req(%v) err(%v)", ino, req, err)
@@ -483,8 +491,17 @@ func (f *File) Write(ctx context.Context, req *fuse.WriteRequest, resp *fuse.Wri
 		if req.Offset > int64(filesize) && reqlen == 1 && req.Data[0] == 0 {
 
 			// workaround: posix_fallocate would write 1 byte if fallocate is not supported.
+			// Reserve the range on the metanode, or truncate if it does not support preallocation.
 			fullPath := path.Join(f.getParentPath(), f.name)
-			err = f.super.ec.Truncate(f.super.mw, f.parentIno, ino, int(req.Offset)+reqlen, fullPath)
+			if err = f.super.ec.Flush(ino); err == nil {
+				end := uint64(req.Offset) + uint64(reqlen)
+				if err = f.super.mw.ExtentsPreAlloc(ino, uint64(filesize), end-uint64(filesize), false, fullPath); err == nil {
+					f.super.ic.Delete(ino)
+					err = f.super.ec.RefreshExtentsCache(ino)
+				} else {
+					err = f.super.ec.Truncate(f.super.mw, f.parentIno, ino, int(req.Offset)+reqlen, fullPath)
+				}
+			}
 			if err == nil {
 				resp.Size = reqlen
 			}
@@ -588,7 +605,8 @@ func (f *File) Write(ctx context.Context, req *fuse.WriteRequest, resp *fuse.Wri
 	return nil
 }
 
-// Flush only when fsyncOnClose is enabled.
+// Flush only when fsyncOnClose is enabled. The POSIX locks of the owner are released by the
+// flush of each close if the file locks are enabled.
 func (f *File) Flush(ctx context.Context, req *fuse.FlushRequest) (err error) {
 	bgTime := stat.BeginStat()
 	runningStat := f.super.runningMonitor.AddClientOp("filesync", req.Hdr().Pid)
@@ -597,7 +615,16 @@ func (f *File) Flush(ctx context.Context, req *fuse.FlushRequest) (err error) {
 		f.super.runningMonitor.SubClientOp(runningStat, err)
 	}()
 
+	if f.super.enableFileLock {
+		if lerr := f.super.mw.ReleaseFileLocks(f.info.Inode, req.LockOwner, false); lerr != nil {
+			log.LogWarnf("Flush: release POSIX locks failed, ino(%v) req(%v) err(%v)", f.info.Inode, req, lerr)
+		}
+	}
 	if !f.super.fsyncOnClose {
+		if f.super.enableFileLock {
+			// the kernel sends no more flushes once ENOSYS is returned
+			return nil
+		}
 		return fuse.ENOSYS
 	}
 	log.LogDebugf("TRACE Flush enter: ino(%v)", f.info.Inode)
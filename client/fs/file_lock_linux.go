@@ -0,0 +1,85 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"context"
+	"math"
+	"syscall"
+
+	"github.com/cubefs/cubefs/depends/bazil.org/fuse"
+	"github.com/cubefs/cubefs/depends/bazil.org/fuse/fs"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/stat"
+)
+
+var _ fs.HandleLocker = (*File)(nil)
+
+// FileLock acquires, releases or tests the flock or POSIX lock of the file, which is shared
+// by the clients of the volume through the metanodes. The kernel sends the locks only if the
+// client mounts with enableFileLock.
+func (f *File) FileLock(ctx context.Context, req *fuse.LockRequest, resp *fuse.LockResponse) (err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("FileLock", err, bgTime, 1)
+	}()
+
+	lock := &proto.FileLock{
+		Owner: req.Owner,
+		Pid:   req.Pid,
+		Flock: req.Flock,
+	}
+	switch req.Type {
+	case syscall.F_RDLCK:
+		lock.Type = proto.FileLockRead
+	case syscall.F_WRLCK:
+		lock.Type = proto.FileLockWrite
+	case syscall.F_UNLCK:
+		lock.Type = proto.FileLockUnlock
+	default:
+		return fuse.Errno(syscall.EINVAL)
+	}
+	// the range of the kernel is inclusive, up to OFFSET_MAX for the end of file
+	lock.Start = req.Start
+	if req.End < math.MaxInt64 {
+		lock.End = req.End + 1
+	}
+
+	ino := f.info.Inode
+	conflict, err := f.super.mw.FileLock(ino, lock, req.Wait, req.Test)
+	log.LogDebugf("TRACE FileLock: ino(%v) req(%v) conflict(%v) err(%v)", ino, req, conflict, err)
+	if err != nil && err != syscall.EAGAIN {
+		log.LogErrorf("FileLock: ino(%v) req(%v) err(%v)", ino, req, err)
+	}
+	if req.Test {
+		resp.Type = syscall.F_UNLCK
+		if err == nil && conflict != nil {
+			resp.Type = syscall.F_RDLCK
+			if conflict.Type == proto.FileLockWrite {
+				resp.Type = syscall.F_WRLCK
+			}
+			resp.Pid = conflict.Pid
+			resp.Start, resp.End = conflict.Start, math.MaxInt64
+			if conflict.End != 0 && conflict.End != math.MaxUint64 {
+				resp.End = conflict.End - 1
+			}
+		}
+	}
+	if err != nil {
+		return ParseError(err)
+	}
+	return nil
+}
@@ -62,11 +62,13 @@ type Super struct {
 
 	nodeCache map[uint64]fs.Node
 	fslock    sync.Mutex
+	server    *fs.Server // the attrs cached by the kernel are invalidated through
 
-	disableDcache bool
-	fsyncOnClose  bool
-	enableXattr   bool
-	rootIno       uint64
+	disableDcache  bool
+	fsyncOnClose   bool
+	enableXattr    bool
+	enableFileLock bool
+	rootIno        uint64
 
 	state     fs.FSStatType
 	sockaddr  string
@@ -115,6 +117,15 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		TicketMess:      opt.TicketMess,
 		ValidateOwner:   opt.Authenticate || opt.AccessKey == "",
 		MetaSendTimeout: opt.MetaSendTimeout,
+		MetaCompression: opt.MetaCompression,
+		LookupPrefetch:  uint32(opt.LookupPrefetch),
+		ReadDirPlus:     opt.ReadDirPlus,
+		TraceSampleRate: opt.MetaTraceSampleRate,
+		MuxConns:        int(opt.MetaMuxConns),
+		MuxPortShift:    int(opt.MetaMuxPortShift),
+		AttrLease:       opt.AttrLease,
+		AccessKey:       opt.AccessKey,
+		SecretKey:       opt.SecretKey,
 		// EnableTransaction: opt.EnableTransaction,
 		SubDir:                     opt.SubDir,
 		TrashRebuildGoroutineLimit: int(opt.TrashRebuildGoroutineLimit),
@@ -158,9 +169,13 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 	}
 	s.orphan = NewOrphanInodeList()
 	s.nodeCache = make(map[uint64]fs.Node)
+	if opt.AttrLease {
+		s.mw.SetAttrInvalidator(s.invalidateAttr)
+	}
 	s.disableDcache = opt.DisableDcache
 	s.fsyncOnClose = opt.FsyncOnClose
 	s.enableXattr = opt.EnableXattr
+	s.enableFileLock = opt.EnableFileLock
 	s.bcacheCheckInterval = opt.BcacheCheckIntervalS
 	s.bcacheFilterFiles = opt.BcacheFilterFiles
 	s.bcacheBatchCnt = opt.BcacheBatchCnt
@@ -377,6 +392,25 @@ func (s *Super) Node(ino, pino uint64, mode uint32) (fs.Node, error) {
 	return node, nil
 }
 
+// SetFuseServer sets the server the super is served by.
+func (s *Super) SetFuseServer(server *fs.Server) {
+	s.server = server
+}
+
+// invalidateAttr drops the attrs of the inode changed by the other clients from the caches.
+func (s *Super) invalidateAttr(ino uint64) {
+	s.ic.Delete(ino)
+	s.fslock.Lock()
+	node, ok := s.nodeCache[ino]
+	s.fslock.Unlock()
+	if !ok || s.server == nil {
+		return
+	}
+	if err := s.server.InvalidateNodeAttr(node); err != nil && err != fuse.ErrNotCached {
+		log.LogWarnf("invalidateAttr: ino(%v) err(%v)", ino, err)
+	}
+}
+
 // Statfs handles the Statfs request and returns a set of statistics.
 func (s *Super) Statfs(ctx context.Context, req *fuse.StatfsRequest, resp *fuse.StatfsResponse) error {
 	const defaultMaxMetaPartitionInodeID uint64 = 1<<63 - 1
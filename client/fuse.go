@@ -38,6 +38,7 @@ import (
 	"runtime"
 	"runtime/debug"
 	runtimepprof "runtime/pprof"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -581,7 +582,9 @@ func main() {
 		errMetric.AddWithLabels(1, map[string]string{exporter.Op: "EXIT", exporter.Type: exitInfo})
 	}
 
-	if err = fs.Serve(fsConn, super, opt); err != nil {
+	server := fs.New(fsConn, nil)
+	super.SetFuseServer(server)
+	if err = server.Serve(super, opt); err != nil {
 		log.LogFlush()
 		syslog.Printf("fs Serve returns err(%v)", err)
 		os.Exit(1)
@@ -864,6 +867,10 @@ func mount(opt *proto.MountOptions) (fsConn *fuse.Conn, super *cfs.Super, err er
 		options = append(options, fuse.DefaultPermissions())
 	}
 
+	if opt.EnableFileLock {
+		options = append(options, fuse.FileLocks())
+	}
+
 	fsConn, err = fuse.Mount(opt.MountPoint, opt.NeedRestoreFuse, options...)
 	return
 }
@@ -993,7 +1000,26 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 		log.LogDebugf("oonfig.verReadSeq %v opt.VerReadSeq %v", verReadSeq, opt.VerReadSeq)
 	}
 	opt.MetaSendTimeout = GlobalMountOptions[proto.MetaSendTimeout].GetInt64()
+	opt.MetaCompression = GlobalMountOptions[proto.MetaCompression].GetBool()
+	opt.ReadDirPlus = GlobalMountOptions[proto.ReadDirPlus].GetBool()
+	opt.LookupPrefetch = GlobalMountOptions[proto.LookupPrefetch].GetInt64()
+	if opt.LookupPrefetch < 0 || opt.LookupPrefetch > proto.MaxLookupSiblings {
+		return nil, errors.New(fmt.Sprintf("invalid fields, LookupPrefetch(%v) must be in [0, %v]", opt.LookupPrefetch, proto.MaxLookupSiblings))
+	}
+	if rate := GlobalMountOptions[proto.MetaTraceSampleRate].GetString(); rate != "" {
+		if opt.MetaTraceSampleRate, err = strconv.ParseFloat(rate, 64); err != nil || opt.MetaTraceSampleRate < 0 || opt.MetaTraceSampleRate > 1 {
+			return nil, errors.New(fmt.Sprintf("invalid fields, MetaTraceSampleRate(%v) must be in [0, 1]", rate))
+		}
+	}
+
+	if opt.MetaMuxConns = GlobalMountOptions[proto.MetaMuxConns].GetInt64(); opt.MetaMuxConns < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid fields, MetaMuxConns(%v) must not be negative", opt.MetaMuxConns))
+	}
+	if opt.MetaMuxPortShift = GlobalMountOptions[proto.MetaMuxPortShift].GetInt64(); opt.MetaMuxPortShift < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid fields, MetaMuxPortShift(%v) must not be negative", opt.MetaMuxPortShift))
+	}
 
+	opt.AttrLease = GlobalMountOptions[proto.AttrLease].GetBool()
 	opt.BuffersTotalLimit = GlobalMountOptions[proto.BuffersTotalLimit].GetInt64()
 	opt.BufferChanSize = GlobalMountOptions[proto.BufferChanSize].GetInt64()
 	opt.MetaSendTimeout = GlobalMountOptions[proto.MetaSendTimeout].GetInt64()
@@ -1005,6 +1031,7 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 	opt.DisableMountSubtype = GlobalMountOptions[proto.DisableMountSubtype].GetBool()
 	opt.StreamRetryTimeout = int(GlobalMountOptions[proto.StreamRetryTimeOut].GetInt64())
 	opt.ForceRemoteCache = GlobalMountOptions[proto.ForceRemoteCache].GetBool()
+	opt.EnableFileLock = GlobalMountOptions[proto.EnableFileLock].GetBool()
 	opt.AheadReadEnable = GlobalMountOptions[proto.AheadReadEnable].GetBool()
 	if opt.AheadReadEnable {
 		var (
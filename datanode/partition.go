@@ -176,6 +176,14 @@ func (dp *DataPartition) SetForbidden(status bool) {
 	dp.config.Forbidden = status
 }
 
+func (dp *DataPartition) IsReadOnlyWindow() bool {
+	return dp.config.ReadOnlyWindow
+}
+
+func (dp *DataPartition) SetReadOnlyWindow(status bool) {
+	dp.config.ReadOnlyWindow = status
+}
+
 func (dp *DataPartition) IsForbidWriteOpOfProtoVer0() bool {
 	return dp.config.ForbidWriteOpOfProtoVer0
 }
@@ -53,6 +53,7 @@ type dataPartitionCfg struct {
 	VerSeq                   uint64 `json:"ver_seq"`
 	CreateType               int
 	Forbidden                bool
+	ReadOnlyWindow           bool `json:"-"` // the vol is in a read-only window told by master
 	DpRepairBlockSize        uint64
 	IsEnableSnapshot         bool
 	ForbidWriteOpOfProtoVer0 bool
@@ -244,6 +244,7 @@ type DataNode struct {
 	nodeForbidWriteOpOfProtoVer0       bool                // whether forbid by node granularity,
 	VolsForbidWriteOpOfProtoVer0       map[string]struct{} // whether forbid by volume granularity,
 	DirectReadVols                     map[string]struct{}
+	ReadOnlyVols                       map[string]struct{} // vols in a read-only window told by master
 	IgnoreTinyRecoverVols              map[string]struct{}
 	ExtentCacheTtlByMin                int
 }
@@ -800,6 +800,13 @@ func (s *DataNode) buildHeartBeatResponse(response *proto.DataNodeHeartbeatRespo
 				partition.volumeID, partition.partitionID, newVal)
 		}
 
+		_, readOnly := s.ReadOnlyVols[partition.volumeID]
+		if partition.IsReadOnlyWindow() != readOnly {
+			log.LogWarnf("[Heartbeats] vol(%v) dpId(%v) read-only window change to %v",
+				partition.volumeID, partition.partitionID, readOnly)
+			partition.SetReadOnlyWindow(readOnly)
+		}
+
 		directReadVols := s.DirectReadVols
 		if _, ok := directReadVols[partition.volumeID]; ok {
 			partition.extentStore.SetDirectRead(true)
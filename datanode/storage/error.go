@@ -27,6 +27,7 @@ var (
 	NoSpaceError                     = errors.New("no space left on the device")
 	ForbiddenDataPartitionError      = errors.New("the data partition is forbidden")
 	ForbiddenMetaPartitionError      = errors.New("meta partition is forbidden")
+	ReadOnlyWindowError              = errors.New("the data partition is in a read-only window")
 	TryAgainError                    = errors.New("try again")
 	LimitedIoError                   = errors.New("limited io error")
 	TinyRecoverError                 = errors.New("tiny extent recovering error")
@@ -618,6 +618,12 @@ func (s *DataNode) handleHeartbeatPacket(p *repl.Packet) {
 			}
 			s.DirectReadVols = directReadVols
 
+			readOnlyVols := make(map[string]struct{})
+			for _, vol := range request.ReadOnlyVols {
+				readOnlyVols[vol] = struct{}{}
+			}
+			s.ReadOnlyVols = readOnlyVols
+
 			ignoreTinyRecoverVols := make(map[string]struct{})
 			for _, vol := range request.IgnoreTinyRecoverVols {
 				if _, ok := ignoreTinyRecoverVols[vol]; !ok {
@@ -928,6 +934,10 @@ func (s *DataNode) handleWritePacket(p *repl.Packet) {
 		err = storage.ForbiddenDataPartitionError
 		return
 	}
+	if partition.IsReadOnlyWindow() {
+		err = storage.ReadOnlyWindowError
+		return
+	}
 
 	if partition.isRepairing {
 		err = storage.DpDecommissionRepairError
@@ -1092,6 +1102,10 @@ func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 		err = storage.ForbiddenDataPartitionError
 		return
 	}
+	if partition.IsReadOnlyWindow() {
+		err = storage.ReadOnlyWindowError
+		return
+	}
 
 	if err = s.checkForbidWriteOpOfProtoVer0(p, partition); err != nil {
 		return
@@ -299,6 +299,12 @@ func nodeAttr(ctx context.Context, n Node, attr *fuse.Attr) error {
 type Handle interface {
 }
 
+type HandleLocker interface {
+	// FileLock acquires, releases or tests a POSIX lock or a flock of the file, the mount needs
+	// the option fuse.FileLocks for the kernel to send them.
+	FileLock(ctx context.Context, req *fuse.LockRequest, resp *fuse.LockResponse) error
+}
+
 type HandleFlusher interface {
 	// Flush is called each time the file or directory is closed.
 	// Because there can be multiple file descriptors referring to a
@@ -1929,6 +1935,24 @@ func (c *Server) handleRequest(ctx context.Context, node Node, snode *serveNode,
 		r.Respond()
 		return nil
 
+	case *fuse.LockRequest:
+		shandle := c.getHandle(r.Handle)
+		if shandle == nil {
+			return fuse.ESTALE
+		}
+		handle := shandle.handle
+
+		if h, ok := handle.(HandleLocker); ok {
+			s := &fuse.LockResponse{}
+			if err := h.FileLock(ctx, r, s); err != nil {
+				return err
+			}
+			done(s)
+			r.Respond(s)
+			return nil
+		}
+		return fuse.ENOSYS
+
 	case *fuse.ReleaseRequest:
 		shandle := c.getHandle(r.Handle)
 		if shandle == nil {
@@ -980,12 +980,23 @@ loop:
 			Flags:        InitFlags(in.Flags),
 		}
 
-	case opGetlk:
-		panic("opGetlk")
-	case opSetlk:
-		panic("opSetlk")
-	case opSetlkw:
-		panic("opSetlkw")
+	case opGetlk, opSetlk, opSetlkw:
+		in := (*lkIn)(m.data())
+		if m.len() < lkInSize(c.proto) {
+			goto corrupt
+		}
+		req = &LockRequest{
+			Header: m.Header(),
+			Handle: HandleID(in.Fh),
+			Owner:  in.Owner,
+			Start:  in.Lk.Start,
+			End:    in.Lk.End,
+			Type:   in.Lk.Type,
+			Pid:    in.Lk.Pid,
+			Flock:  c.proto.GE(Protocol{7, 9}) && in.LkFlags&lkFlock != 0,
+			Wait:   m.hdr.Opcode == opSetlkw,
+			Test:   m.hdr.Opcode == opGetlk,
+		}
 
 	case opAccess:
 		in := (*accessIn)(m.data())
@@ -1825,7 +1836,7 @@ type ReleaseRequest struct {
 	Handle       HandleID
 	Flags        OpenFlags // flags from OpenRequest
 	ReleaseFlags ReleaseFlags
-	LockOwner    uint32
+	LockOwner    uint64
 }
 
 var _ = Request(&ReleaseRequest{})
@@ -2125,6 +2136,60 @@ func (r *FlushRequest) Respond() {
 	r.respond(buf)
 }
 
+// A LockRequest asks to acquire or release a POSIX lock or a flock of the bytes [Start, End]
+// of an open file for the lock owner, waiting for the conflicting locks if Wait. With Test it
+// only asks for the lock conflicting with it. Type is one of F_RDLCK, F_WRLCK and F_UNLCK.
+type LockRequest struct {
+	Header `json:"-"`
+	Handle HandleID
+	Owner  uint64
+	Start  uint64
+	End    uint64 // inclusive
+	Type   uint32
+	Pid    uint32
+	Flock  bool
+	Wait   bool
+	Test   bool
+}
+
+var _ = Request(&LockRequest{})
+
+func (r *LockRequest) String() string {
+	return fmt.Sprintf("Lock [%s] %v owner=%#x [%d, %d] type=%d pid=%d flock=%v wait=%v test=%v",
+		&r.Header, r.Handle, r.Owner, r.Start, r.End, r.Type, r.Pid, r.Flock, r.Wait, r.Test)
+}
+
+// Respond replies to the request, with the lock conflicting with a test, or with the type
+// F_UNLCK if there is none.
+func (r *LockRequest) Respond(resp *LockResponse) {
+	if !r.Test {
+		buf := newBuffer(0)
+		r.respond(buf)
+		return
+	}
+	buf := newBuffer(unsafe.Sizeof(lkOut{}))
+	out := (*lkOut)(buf.alloc(unsafe.Sizeof(lkOut{})))
+	out.Lk = fileLock{
+		Start: resp.Start,
+		End:   resp.End,
+		Type:  resp.Type,
+		Pid:   resp.Pid,
+	}
+	r.respond(buf)
+}
+
+// A LockResponse is the lock conflicting with a LockRequest of Test.
+type LockResponse struct {
+	Start uint64
+	End   uint64 // inclusive
+	Type  uint32
+	Pid   uint32
+}
+
+func (r *LockResponse) String() string {
+	return fmt.Sprintf("Lock [%d, %d] type=%d pid=%d", r.Start, r.End, r.Type, r.Pid)
+}
+
 // A RemoveRequest asks to remove a file or directory from the
 // directory r.Node.
 type RemoveRequest struct {
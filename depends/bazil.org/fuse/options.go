@@ -236,6 +236,15 @@ func AsyncRead() MountOption {
 	}
 }
 
+// FileLocks lets the kernel send the POSIX locks and the flocks of the files to the FUSE
+// server, instead of locking them locally. The handles implement fs.HandleLocker for them.
+func FileLocks() MountOption {
+	return func(conf *mountConfig) error {
+		conf.initFlags |= InitPosixLocks | InitFlockLocks
+		return nil
+	}
+}
+
 // WritebackCache enables the kernel to buffer writes before sending
 // them to the FUSE server. Without this, writethrough caching is
 // used.
@@ -73,6 +73,7 @@ func NewS3Scanner(adminTask *proto.AdminTask, l *LcNode) (*LcScanner, error) {
 		InnerReq:             true,
 		MetaSendTimeout:      600,
 		DisableTrashByClient: true,
+		Background:           true,
 	}
 	var metaWrapper *meta.MetaWrapper
 	if metaWrapper, err = meta.NewMetaWrapper(metaConfig); err != nil {
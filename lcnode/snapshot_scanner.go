@@ -57,6 +57,7 @@ func NewSnapshotScanner(adminTask *proto.AdminTask, l *LcNode) (*SnapshotScanner
 		InnerReq:             true,
 		MetaSendTimeout:      600,
 		DisableTrashByClient: true,
+		Background:           true,
 	}
 
 	var metaWrapper *meta.MetaWrapper
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -38,6 +39,9 @@ const (
 	connectTimeout     = 10                     // seconds
 	MaxRetryNum        = 3                      // maximum number of retries for sending tasks
 	RetryInterval      = 100 * time.Millisecond // retry interval for sending tasks
+
+	// a node is reported by the cluster view if its oldest task is older than it
+	stuckTaskAge = 5 * 60 // seconds
 )
 
 // AdminTaskManager sends administration commands to the metaNode or dataNode.
@@ -48,6 +52,10 @@ type AdminTaskManager struct {
 	sync.RWMutex
 	exitCh   chan struct{}
 	connPool *util.ConnectPool
+
+	failedSends   uint64
+	lastSendError string
+	lastErrorTime int64
 }
 
 func newAdminTaskManager(targetAddr, clusterID string) (sender *AdminTaskManager) {
@@ -149,12 +157,14 @@ func (sender *AdminTaskManager) sendTasks(tasks []*proto.AdminTask) {
 		if err != nil {
 			msg := fmt.Sprintf("clusterID[%v] get connection to %v,err,%v", sender.clusterID, sender.targetAddr, errors.Stack(err))
 			WarnBySpecialKey(fmt.Sprintf("%v_%v_sendTask", sender.clusterID, ModuleName), msg)
+			sender.recordSendError(err)
 			sender.putConn(conn, true)
 			sender.updateTaskInfo(task, false)
 			break
 		}
 		if err = sender.sendAdminTask(task, conn); err != nil {
 			log.LogError(fmt.Sprintf("send task %v to %v err %v,errStack,%v", task.ID, sender.targetAddr, err, errors.Stack(err)))
+			sender.recordSendError(err)
 			sender.putConn(conn, true)
 			sender.updateTaskInfo(task, true)
 			continue
@@ -210,6 +220,7 @@ func (sender *AdminTaskManager) syncSendAdminTask(task *proto.AdminTask) (packet
 	log.LogInfof("action[syncSendAdminTask],task[%s], op %s, reqId %d", task.ToString(), packet.GetOpMsg(), packet.GetReqID())
 	conn, err := sender.getConn()
 	if err != nil {
+		sender.recordSendError(err)
 		return nil, errors.Trace(err, "action[syncSendAdminTask get conn failed,task:%v]", task.ID)
 	}
 	defer func() {
@@ -229,6 +240,7 @@ func (sender *AdminTaskManager) syncSendAdminTask(task *proto.AdminTask) (packet
 		break
 	}
 	if err != nil {
+		sender.recordSendError(err)
 		return nil, errors.Trace(err, "action[syncSendAdminTask],WriteToConn failed,task:%v,reqID[%v]", task.ID, packet.ReqID)
 	}
 
@@ -241,6 +253,7 @@ func (sender *AdminTaskManager) syncSendAdminTask(task *proto.AdminTask) (packet
 		break
 	}
 	if err != nil {
+		sender.recordSendError(err)
 		return nil, errors.Trace(err, "action[syncSendAdminTask],ReadFromConn failed task:%v,reqID[%v]", task.ID, packet.ReqID)
 	}
 
@@ -312,3 +325,94 @@ func (sender *AdminTaskManager) getToDoTasks(id string) (tasks []*proto.AdminTas
 	}
 	return
 }
+
+func (sender *AdminTaskManager) recordSendError(err error) {
+	sender.Lock()
+	defer sender.Unlock()
+	sender.failedSends++
+	sender.lastSendError = err.Error()
+	sender.lastErrorTime = time.Now().Unix()
+}
+
+// getTaskStat returns the stat of the tasks queued for the node.
+func (sender *AdminTaskManager) getTaskStat(nodeType string) (stat *proto.NodeTaskStat) {
+	now := time.Now().Unix()
+	sender.RLock()
+	defer sender.RUnlock()
+	stat = &proto.NodeTaskStat{
+		Addr:          sender.targetAddr,
+		NodeType:      nodeType,
+		Total:         len(sender.TaskMap),
+		Ops:           make(map[string]int),
+		FailedSends:   sender.failedSends,
+		LastSendError: sender.lastSendError,
+		LastErrorTime: sender.lastErrorTime,
+	}
+	for _, task := range sender.TaskMap {
+		if task.SendTime == 0 {
+			stat.Pending++
+		} else {
+			stat.Running++
+		}
+		if task.SendCount > 1 {
+			stat.Retried++
+		}
+		if int(task.SendCount) > stat.MaxSendCount {
+			stat.MaxSendCount = int(task.SendCount)
+		}
+		op := (&proto.Packet{Opcode: task.OpCode}).GetOpMsg()
+		stat.Ops[op]++
+		if age := now - task.CreateTime; task.CreateTime > 0 && age > stat.OldestTaskAge {
+			stat.OldestTaskAge = age
+			stat.OldestTask = op
+		}
+	}
+	return
+}
+
+// getNodeTaskStats returns the task stat of the node on addr, or of all the nodes with tasks
+// queued if addr is empty.
+func (c *Cluster) getNodeTaskStats(addr string) (stats []*proto.NodeTaskStat) {
+	stats = make([]*proto.NodeTaskStat, 0)
+	add := func(nodeType, nodeAddr string, sender *AdminTaskManager) bool {
+		if sender == nil || (addr != "" && addr != nodeAddr) {
+			return true
+		}
+		if stat := sender.getTaskStat(nodeType); addr != "" || stat.Total > 0 || stat.FailedSends > 0 {
+			stats = append(stats, stat)
+		}
+		return true
+	}
+	c.metaNodes.Range(func(key, value interface{}) bool {
+		node := value.(*MetaNode)
+		return add("meta", node.Addr, node.Sender)
+	})
+	c.dataNodes.Range(func(key, value interface{}) bool {
+		node := value.(*DataNode)
+		return add("data", node.Addr, node.TaskManager)
+	})
+	c.lcNodes.Range(func(key, value interface{}) bool {
+		node := value.(*LcNode)
+		return add("lc", node.Addr, node.TaskManager)
+	})
+	c.flashNodeTopo.flashNodeMap.Range(func(key, value interface{}) bool {
+		node := value.(*FlashNode)
+		return add("flash", node.Addr, node.TaskManager)
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].OldestTaskAge > stats[j].OldestTaskAge
+	})
+	return
+}
+
+// getStuckTaskNodes returns the nodes whose oldest task is queued longer than stuckTaskAge,
+// e.g. an unreachable node, before they stall the recovery.
+func (c *Cluster) getStuckTaskNodes() (stats []*proto.NodeTaskStat) {
+	stats = make([]*proto.NodeTaskStat, 0)
+	for _, stat := range c.getNodeTaskStats("") {
+		if stat.OldestTaskAge > stuckTaskAge {
+			stats = append(stats, stat)
+		}
+	}
+	return
+}
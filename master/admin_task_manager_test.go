@@ -0,0 +1,58 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeTaskStat(t *testing.T) {
+	addr := "127.0.0.1:19999"
+	sender := &AdminTaskManager{targetAddr: addr, TaskMap: make(map[string]*proto.AdminTask)}
+	old := proto.NewAdminTask(proto.OpDeleteDataPartition, addr, nil)
+	old.CreateTime = time.Now().Unix() - 2*stuckTaskAge
+	old.SendTime = time.Now().Unix()
+	old.SendCount = 3
+	sender.AddTask(old)
+	sender.AddTask(proto.NewAdminTask(proto.OpCreateDataPartition, addr, nil))
+	sender.recordSendError(errors.New("connection refused"))
+
+	stat := sender.getTaskStat("data")
+	require.Equal(t, addr, stat.Addr)
+	require.Equal(t, 2, stat.Total)
+	require.Equal(t, 1, stat.Pending)
+	require.Equal(t, 1, stat.Running)
+	require.Equal(t, 1, stat.Retried)
+	require.Equal(t, 3, stat.MaxSendCount)
+	require.True(t, stat.OldestTaskAge > stuckTaskAge)
+	require.Equal(t, "OpDeleteDataPartition", stat.OldestTask)
+	require.Equal(t, 1, stat.Ops["OpCreateDataPartition"])
+	require.EqualValues(t, 1, stat.FailedSends)
+	require.Equal(t, "connection refused", stat.LastSendError)
+
+	// the nodes of the cluster
+	stats := server.cluster.getNodeTaskStats(mds1Addr)
+	require.Len(t, stats, 1)
+	require.Equal(t, "data", stats[0].NodeType)
+	require.Empty(t, server.cluster.getNodeTaskStats("noSuchNode"))
+	processWithFatalV2(proto.AdminNodeTasks, true, map[string]interface{}{"addr": mms1Addr}, t)
+	processWithFatalV2(proto.AdminNodeTasks, false, map[string]interface{}{"addr": "noSuchNode"}, t)
+	processWithFatalV2(proto.AdminNodeTasks, true, nil, t)
+}
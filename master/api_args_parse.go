@@ -22,6 +22,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/compressor"
 	"github.com/cubefs/cubefs/util/cryptoutil"
+	"github.com/cubefs/cubefs/util/iputil"
 	"github.com/cubefs/cubefs/util/log"
 )
 
@@ -390,6 +392,49 @@ func extractStrWithDefault(r *http.Request, key string, def string) (val string)
 	return val
 }
 
+// extractAtimeMode returns the atime mode of the request, empty if neither it nor def sets one.
+func extractAtimeMode(r *http.Request, def string) (mode string, err error) {
+	if mode = extractStrWithDefault(r, atimeModeKey, def); mode != "" && !proto.IsValidAtimeMode(mode) {
+		err = fmt.Errorf("invalid %v %v, expect %v, %v or %v", atimeModeKey, mode,
+			proto.AtimeModeStrict, proto.AtimeModeRelatime, proto.AtimeModeNoatime)
+	}
+	return
+}
+
+// checkAtimeMode rejects a mode updating the atime on the accesses of a vol not persisting
+// them, it would take no effect.
+func checkAtimeMode(mode string, enablePersistAccessTime bool) error {
+	if !enablePersistAccessTime && (mode == proto.AtimeModeStrict || mode == proto.AtimeModeRelatime) {
+		return fmt.Errorf("%v %v requires %v", atimeModeKey, mode, enablePersistAccessTimeKey)
+	}
+	return nil
+}
+
+func extractExtentConflictPolicy(r *http.Request, def string) (policy string, err error) {
+	if _, ok := r.Form[extentConflictPolicyKey]; !ok {
+		return def, nil
+	}
+	if policy = r.FormValue(extentConflictPolicyKey); !proto.IsValidExtentConflictPolicy(policy) {
+		err = fmt.Errorf("invalid %v %v, expect empty, %v or %v", extentConflictPolicyKey, policy,
+			proto.ExtentConflictPolicyReject, proto.ExtentConflictPolicyLWW)
+	}
+	return
+}
+
+func extractXAttrLimit(r *http.Request, def proto.XAttrLimit) (limit proto.XAttrLimit, err error) {
+	if limit.MaxCount, err = extractUint32WithDefault(r, xattrMaxCountKey, def.MaxCount); err != nil {
+		return
+	}
+	if limit.MaxKeySize, err = extractUint32WithDefault(r, xattrMaxKeySizeKey, def.MaxKeySize); err != nil {
+		return
+	}
+	if limit.MaxValueSize, err = extractUint32WithDefault(r, xattrMaxValueSizeKey, def.MaxValueSize); err != nil {
+		return
+	}
+	limit.MaxTotalSize, err = extractUint64WithDefault(r, xattrMaxTotalSizeKey, def.MaxTotalSize)
+	return
+}
+
 func extractBoolWithDefault(r *http.Request, key string, def bool) (val bool, err error) {
 	var str string
 	if str = r.FormValue(key); str == "" {
@@ -434,6 +479,12 @@ type updateVolReq struct {
 	enableAutoDpMetaRepair   bool
 	accessTimeValidInterval  int64
 	enablePersistAccessTime  bool
+	atimeMode                string
+	xattrLimit               proto.XAttrLimit
+	extentConflictPolicy     string
+	enableOpAudit            bool
+	enableCheckExtentKey     bool
+	enableDirStat            bool
 	volStorageClass          uint32
 	forbidWriteOpOfProtoVer0 bool
 	quotaOfClass             uint64
@@ -559,6 +610,34 @@ func parseVolUpdateReq(r *http.Request, vol *Vol, req *updateVolReq) (err error)
 	if req.enablePersistAccessTime, err = extractBoolWithDefault(r, enablePersistAccessTimeKey, vol.EnablePersistAccessTime); err != nil {
 		return
 	}
+	if req.atimeMode, err = extractAtimeMode(r, vol.atimeMode); err != nil {
+		return
+	}
+	if req.atimeMode != vol.atimeMode {
+		if err = checkAtimeMode(req.atimeMode, req.enablePersistAccessTime); err != nil {
+			return
+		}
+	}
+	if req.xattrLimit, err = extractXAttrLimit(r, vol.xattrLimit); err != nil {
+		return
+	}
+	// the default xattrs are set on every new inode, they must be within the limit
+	if err = req.xattrLimit.Check(vol.getDefaultXAttrs()); err != nil {
+		err = fmt.Errorf("default xattrs of vol exceed the xattr limit: %v", err)
+		return
+	}
+	if req.extentConflictPolicy, err = extractExtentConflictPolicy(r, vol.extentConflictPolicy); err != nil {
+		return
+	}
+	if req.enableOpAudit, err = extractBoolWithDefault(r, enableOpAuditKey, vol.enableOpAudit); err != nil {
+		return
+	}
+	if req.enableCheckExtentKey, err = extractBoolWithDefault(r, enableCheckExtentKeyKey, vol.enableCheckExtentKey); err != nil {
+		return
+	}
+	if req.enableDirStat, err = extractBoolWithDefault(r, enableDirStatKey, vol.enableDirStat); err != nil {
+		return
+	}
 	if req.enableAutoDpMetaRepair, err = extractBoolWithDefault(r, autoDpMetaRepairKey, vol.EnableAutoMetaRepair.Load()); err != nil {
 		return
 	}
@@ -753,6 +832,7 @@ type createVolReq struct {
 	trashInterval           int64
 	accessTimeValidInterval int64
 	enablePersistAccessTime bool
+	atimeMode               string
 	// cold vol args
 	coldArgs coldVolArgs
 
@@ -771,6 +851,30 @@ type createVolReq struct {
 	flashNodeTimeoutCount        int64
 	remoteCacheSameZoneTimeout   int64
 	remoteCacheSameRegionTimeout int64
+	// pre-warm partitions in background
+	preWarm        bool
+	expectedInodes uint64
+	// read-only replica of the source vol
+	sourceVol           string
+	replicaSyncInterval int64
+}
+
+func parseRequestToCreateVolReplica(r *http.Request) (name, owner, source, authKey string, interval int64, err error) {
+	if name, err = parseAndExtractName(r); err != nil {
+		return
+	}
+	if owner, err = extractOwner(r); err != nil {
+		return
+	}
+	if source = extractStr(r, sourceVolKey); source == "" {
+		err = keyNotFound(sourceVolKey)
+		return
+	}
+	if authKey, err = extractAuthKey(r); err != nil {
+		return
+	}
+	interval, err = extractInt64WithDefault(r, syncIntervalKey, 0)
+	return
 }
 
 func parseColdArgs(r *http.Request) (args coldVolArgs, err error) {
@@ -836,6 +940,14 @@ func parseRequestToCreateVol(r *http.Request, req *createVolReq) (err error) {
 		return
 	}
 
+	if req.preWarm, err = extractBoolWithDefault(r, preWarmKey, false); err != nil {
+		return
+	}
+
+	if req.expectedInodes, err = extractUint64WithDefault(r, expectedInodesKey, 0); err != nil {
+		return
+	}
+
 	var parsedDpReplicaNum int
 	if parsedDpReplicaNum, err = extractUint(r, replicaNumKey); err != nil {
 		return
@@ -985,6 +1097,12 @@ func parseRequestToCreateVol(r *http.Request, req *createVolReq) (err error) {
 	if req.enablePersistAccessTime, err = extractBoolWithDefault(r, enablePersistAccessTimeKey, false); err != nil {
 		return
 	}
+	if req.atimeMode, err = extractAtimeMode(r, ""); err != nil {
+		return
+	}
+	if err = checkAtimeMode(req.atimeMode, req.enablePersistAccessTime); err != nil {
+		return
+	}
 
 	if req.allowedStorageClass, err = parseAllowedStorageClass(r); err != nil {
 		return
@@ -1247,6 +1365,157 @@ func extractDpRepairBlockSize(r *http.Request) (size uint64, err error) {
 	return
 }
 
+// parseAndExtractDefaultXAttrs extracts the xattrs in form of "key1:value1,key2:value2",
+// a backslash keeps the next char of a key or a value as it is, see proto.EscapeXAttrPair.
+// An empty value clears the default xattrs of the volume.
+func parseAndExtractDefaultXAttrs(r *http.Request) (xattrs map[string]string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if _, ok := r.Form[defaultXAttrsKey]; !ok {
+		err = keyNotFound(defaultXAttrsKey)
+		return
+	}
+	return parseDefaultXAttrs(r.FormValue(defaultXAttrsKey))
+}
+
+// splitEscaped splits s by sep not escaped by a backslash into n parts at most, the escapes
+// are kept in the parts.
+func splitEscaped(s string, sep byte, n int) (parts []string, err error) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			if i++; i == len(s) {
+				return nil, fmt.Errorf("%v ends with an escape", s)
+			}
+		case s[i] == sep && (n <= 0 || len(parts) < n-1):
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:]), nil
+}
+
+// unescape drops the backslashes of s escaping the chars after them.
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func parseDefaultXAttrs(value string) (xattrs map[string]string, err error) {
+	xattrs = make(map[string]string)
+	pairs, err := splitEscaped(value, ',', -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		var kv []string
+		if kv, err = splitEscaped(pair, ':', 2); err != nil {
+			return nil, err
+		}
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid xattr %v, expect key:value", pair)
+		}
+		key := unescape(kv[0])
+		if key == proto.QuotaKey || strings.HasPrefix(key, proto.InnerXAttrPrefix) {
+			return nil, fmt.Errorf("xattr key %v is reserved", key)
+		}
+		xattrs[key] = unescape(kv[1])
+	}
+	if len(xattrs) > maxVolDefaultXAttrCount {
+		return nil, fmt.Errorf("too many xattrs %v, max %v", len(xattrs), maxVolDefaultXAttrCount)
+	}
+	return
+}
+
+// extractDpPinPath extracts the absolute path to pin, it is cleaned so that
+// the client could match it as a prefix of file paths.
+func extractDpPinPath(r *http.Request) (pinPath string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if pinPath = r.FormValue(dpPinPathKey); pinPath == "" {
+		err = keyNotFound(dpPinPathKey)
+		return
+	}
+	if !path.IsAbs(pinPath) {
+		err = fmt.Errorf("pin path %v should be absolute", pinPath)
+		return
+	}
+	pinPath = path.Clean(pinPath)
+	return
+}
+
+func parseAndExtractDpPin(r *http.Request) (pin *proto.DataPartitionPin, err error) {
+	pin = &proto.DataPartitionPin{}
+	if pin.Path, err = extractDpPinPath(r); err != nil {
+		return
+	}
+	if pin.MediaType, err = extractMediaType(r); err != nil {
+		return
+	}
+	if pin.MediaType != proto.MediaType_Unspecified && !proto.IsValidMediaType(pin.MediaType) {
+		err = fmt.Errorf("invalid media type %v", pin.MediaType)
+		return
+	}
+	pin.ZoneName = r.FormValue(zoneNameKey)
+	if pin.MediaType == proto.MediaType_Unspecified && pin.ZoneName == "" {
+		err = fmt.Errorf("either %v or %v should be specified", mediaTypeKey, zoneNameKey)
+	}
+	return
+}
+
+// parseAndExtractReadOnlyWindow extracts the window in unix seconds, it starts at once if the
+// start is not specified.
+func parseAndExtractReadOnlyWindow(r *http.Request, now int64) (window *proto.VolReadOnlyWindow, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	window = &proto.VolReadOnlyWindow{Reason: r.FormValue(windowReasonKey)}
+	if window.Start, err = extractInt64WithDefault(r, windowStartKey, now); err != nil {
+		return
+	}
+	end, err := extractPositiveUint64(r, windowEndKey)
+	if err != nil {
+		return
+	}
+	window.End = int64(end)
+	return
+}
+
+func parseVolClientInfo(r *http.Request) (client *proto.VolClientInfo, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	client = &proto.VolClientInfo{
+		Host:    r.FormValue(clientHostKey),
+		Role:    r.FormValue(clientRoleKey),
+		Version: r.FormValue(clientVersion),
+	}
+	if client.Host == "" {
+		client.Host = iputil.FromRequest(r)
+	}
+	pid, err := extractInt64WithDefault(r, clientPidKey, 0)
+	if err != nil {
+		return
+	}
+	client.Pid = int(pid)
+	if client.MountTime, err = extractInt64WithDefault(r, clientMountTimeKey, 0); err != nil {
+		return
+	}
+	return
+}
+
 func extractDataNodesetSelector(r *http.Request) string {
 	return r.FormValue(dataNodesetSelectorKey)
 }
@@ -1475,6 +1744,51 @@ func parseAndExtractSetNodeInfoParams(r *http.Request) (params map[string]interf
 		params[nodeDeleteWorkerSleepMs] = val
 	}
 
+	for _, key := range []string{
+		metaSnapshotSendRateKey, metaSnapshotRecvRateKey, metaSnapshotPartitionRateKey,
+		metaPartitionHeatSplitOpRateKey, metaPartitionHeatSplitIntervalKey,
+	} {
+		if value = r.FormValue(key); value != "" {
+			noParams = false
+			val := uint64(0)
+			if val, err = strconv.ParseUint(value, 10, 64); err != nil {
+				err = unmatchedKey(key)
+				return
+			}
+			params[key] = val
+		}
+	}
+
+	for _, key := range []string{metaCreateOpRateKey, metaDeleteOpRateKey, metaLookupOpRateKey} {
+		if value = r.FormValue(key); value != "" {
+			noParams = false
+			val := uint64(0)
+			if val, err = strconv.ParseUint(value, 10, 64); err != nil {
+				err = unmatchedKey(key)
+				return
+			}
+			params[key] = val
+			// the op rates are of the vol named
+			if params[nameKey] == nil {
+				if value = r.FormValue(nameKey); value == "" {
+					err = keyNotFound(nameKey)
+					return
+				}
+				params[nameKey] = value
+			}
+		}
+	}
+
+	if value = r.FormValue(metaSnapshotPersistenceModeKey); value != "" {
+		noParams = false
+		var mode uint32
+		if mode, err = proto.ParsePersistenceMode(value); err != nil {
+			err = unmatchedKey(metaSnapshotPersistenceModeKey)
+			return
+		}
+		params[metaSnapshotPersistenceModeKey] = mode
+	}
+
 	if value = r.FormValue(clusterLoadFactorKey); value != "" {
 		noParams = false
 		valF, err := strconv.ParseFloat(value, 64)
@@ -2150,6 +2464,9 @@ func parseSetConfigParam(r *http.Request) (config map[string]string, err error)
 		cfgMetaNodeMemoryHighPer,
 		cfgMetaNodeMemoryLowPer,
 		cfgAutoMpMigrate,
+		cfgMpApplyLagThreshold,
+		cfgMpApplyLagCycles,
+		cfgMpApplyLagAutoRepair,
 		flashNodeHandleReadTimeout,
 		flashNodeReadDataNodeTimeout,
 	}
@@ -16,6 +16,7 @@ package master
 
 import (
 	"context"
+	"crypto/hmac"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -412,8 +413,11 @@ func (m *Server) getTopology(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		doStatAndMetric(proto.GetTopologyView, metric, nil, nil)
 	}()
+	sendOkReply(w, r, newSuccessHTTPReply(m.topologyView()))
+}
 
-	tv := &TopologyView{
+func (m *Server) topologyView() (tv *TopologyView) {
+	tv = &TopologyView{
 		Zones: make([]*ZoneView, 0),
 	}
 	zones := m.cluster.t.getAllZones()
@@ -441,14 +445,15 @@ func (m *Server) getTopology(w http.ResponseWriter, r *http.Request) {
 				nsView.MetaNodes = append(nsView.MetaNodes, proto.MetaNodeView{
 					ID: metaNode.ID, Addr: metaNode.Addr,
 					DomainAddr: metaNode.DomainAddr, Status: metaNode.IsActive,
-					IsWritable: metaNode.IsWriteAble(), MediaType: proto.MediaType_Unspecified,
+					IsWritable: metaNode.IsWriteAble(), MediaType: metaNode.MediaType,
 					Ratio: metaNode.Ratio, SystemRatio: CaculateNodeMemoryRatio(metaNode),
+					NoPlacement: metaNode.NoPlacement,
 				})
 				return true
 			})
 		}
 	}
-	sendOkReply(w, r, newSuccessHTTPReply(tv))
+	return
 }
 
 func (m *Server) updateZone(w http.ResponseWriter, r *http.Request) {
@@ -500,6 +505,91 @@ func (m *Server) updateZone(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("update zone status to [%v] successfully", status)))
 }
 
+// evacuateZone starts moving all the data and meta partitions out of the zone.
+func (m *Server) evacuateZone(w http.ResponseWriter, r *http.Request) {
+	var (
+		name          string
+		mpConcurrency int
+		weight        int
+		view          *proto.ZoneEvacuationView
+		err           error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.EvacuateZone))
+	defer func() {
+		doStatAndMetric(proto.EvacuateZone, metric, err, nil)
+		AuditLog(r, proto.EvacuateZone, fmt.Sprintf("evacuate zone(%s) mpConcurrency(%v)", name, mpConcurrency), err)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if name = r.FormValue(nameKey); name == "" {
+		err = keyNotFound(nameKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mpConcurrency, err = parseUintParam(r, mpConcurrencyKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if weight, err = parseWeight(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if view, err = m.cluster.evacuateZone(name, mpConcurrency, weight); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(view))
+}
+
+func (m *Server) evacuateZoneStatus(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		e    *zoneEvacuation
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.EvacuateZoneStatus))
+	defer func() {
+		doStatAndMetric(proto.EvacuateZoneStatus, metric, err, nil)
+	}()
+
+	if name = r.FormValue(nameKey); name == "" {
+		err = keyNotFound(nameKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if e, err = m.cluster.getZoneEvacuation(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.zoneEvacuationView(e)))
+}
+
+func (m *Server) abortEvacuateZone(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.EvacuateZoneAbort))
+	defer func() {
+		doStatAndMetric(proto.EvacuateZoneAbort, metric, err, nil)
+		AuditLog(r, proto.EvacuateZoneAbort, fmt.Sprintf("abort evacuating zone(%s)", name), err)
+	}()
+
+	if name = r.FormValue(nameKey); name == "" {
+		err = keyNotFound(nameKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.abortZoneEvacuation(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("abort evacuating zone[%v] successfully", name)))
+}
+
 func (m *Server) listZone(w http.ResponseWriter, r *http.Request) {
 	metric := exporter.NewTPCnt(apiToMetricsName(proto.GetAllZones))
 	defer func() {
@@ -689,6 +779,23 @@ func (m *Server) clusterStat(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(cs))
 }
 
+// getNodeTasks replies the admin tasks queued for the node of addr, or for all the nodes with
+// tasks queued if addr is not given.
+func (m *Server) getNodeTasks(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminNodeTasks))
+	defer func() {
+		doStatAndMetric(proto.AdminNodeTasks, metric, nil, nil)
+	}()
+
+	addr := extractStr(r, addrKey)
+	stats := m.cluster.getNodeTaskStats(addr)
+	if addr != "" && len(stats) == 0 {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: fmt.Sprintf("node %v not found", addr)})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(stats))
+}
+
 func (m *Server) UidOperate(w http.ResponseWriter, r *http.Request) {
 	var (
 		uid     uint32
@@ -905,6 +1012,82 @@ func (m *Server) getOpLog(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(opv))
 }
 
+// createClusterSnapshot takes a snapshot of the cluster state to be compared by getClusterDiff.
+func (m *Server) createClusterSnapshot(w http.ResponseWriter, r *http.Request) {
+	var (
+		err      error
+		snapshot *clusterSnapshot
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminCreateClusterSnapshot))
+	defer func() {
+		doStatAndMetric(proto.AdminCreateClusterSnapshot, metric, err, nil)
+	}()
+
+	if snapshot, err = m.cluster.takeClusterSnapshot(); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if err = m.cluster.addClusterSnapshot(snapshot); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(snapshot.info()))
+}
+
+func (m *Server) listClusterSnapshots(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminListClusterSnapshots))
+	defer func() {
+		doStatAndMetric(proto.AdminListClusterSnapshots, metric, nil, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.clusterSnapshots.list()))
+}
+
+// getClusterDiff reports what changed from the snapshot given by from to the one given by to,
+// or to the current state of the cluster if to is not given.
+func (m *Server) getClusterDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		err      error
+		fromID   uint64
+		toID     uint64
+		from, to *clusterSnapshot
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminClusterDiff))
+	defer func() {
+		doStatAndMetric(proto.AdminClusterDiff, metric, err, nil)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if fromID, err = extractUint64(r, fromKey); err != nil || fromID == 0 {
+		if err == nil {
+			err = keyNotFound(fromKey)
+		}
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if toID, err = extractUint64(r, toKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if from, err = m.cluster.clusterSnapshots.get(fromID); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if toID == 0 {
+		to, err = m.cluster.takeClusterSnapshot()
+	} else {
+		to, err = m.cluster.clusterSnapshots.get(toID)
+	}
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(diffClusterSnapshots(from, to)))
+}
+
 func (m *Server) getCluster(w http.ResponseWriter, r *http.Request) {
 	var volStorageClass bool
 
@@ -974,6 +1157,7 @@ func (m *Server) getCluster(w http.ResponseWriter, r *http.Request) {
 	cv.MetaNodes = m.cluster.allMetaNodes()
 	cv.DataNodes = m.cluster.allDataNodes()
 	cv.FlashNodes = m.cluster.allFlashNodes()
+	cv.StuckTaskNodes = m.cluster.getStuckTaskNodes()
 	cv.DataNodeStatInfo = m.cluster.dataNodeStatInfo
 	cv.MetaNodeStatInfo = m.cluster.metaNodeStatInfo
 	for _, name := range vols {
@@ -1166,11 +1350,19 @@ func (m *Server) getIPAddr(w http.ResponseWriter, r *http.Request) {
 	autoRepairRate := atomic.LoadUint64(&m.cluster.cfg.DataNodeAutoRepairLimitRate)
 	dirChildrenNumLimit := atomic.LoadUint32(&m.cluster.cfg.DirChildrenNumLimit)
 	dpMaxRepairErrCnt := atomic.LoadUint64(&m.cluster.cfg.DpMaxRepairErrCnt)
+	masterAddrs := make([]string, 0)
+	for _, node := range m.cluster.allMasterNodes() {
+		masterAddrs = append(masterAddrs, node.Addr)
+	}
 
 	cInfo := &proto.ClusterInfo{
 		Cluster:                     m.cluster.Name,
 		MetaNodeDeleteBatchCount:    batchCount,
 		MetaNodeDeleteWorkerSleepMs: deleteSleepMs,
+		MetaSnapshotPersistenceMode: atomic.LoadUint32(&m.cluster.cfg.MetaSnapshotPersistenceMode),
+		MetaSnapshotSendRateMB:      atomic.LoadUint64(&m.cluster.cfg.MetaSnapshotSendRateMB),
+		MetaSnapshotRecvRateMB:      atomic.LoadUint64(&m.cluster.cfg.MetaSnapshotRecvRateMB),
+		MetaSnapshotPartitionRateMB: atomic.LoadUint64(&m.cluster.cfg.MetaSnapshotPartitionRateMB),
 		DataNodeDeleteLimitRate:     limitRate,
 		DataNodeAutoRepairLimitRate: autoRepairRate,
 		DpMaxRepairErrCnt:           dpMaxRepairErrCnt,
@@ -1183,6 +1375,7 @@ func (m *Server) getIPAddr(w http.ResponseWriter, r *http.Request) {
 		ClusterUuidEnable:                  m.cluster.clusterUuidEnable,
 		ClusterEnableSnapshot:              m.cluster.cfg.EnableSnapshot,
 		RaftPartitionCanUsingDifferentPort: m.cluster.RaftPartitionCanUsingDifferentPortEnabled(),
+		MasterAddrs:                        masterAddrs,
 	}
 
 	sendOkReply(w, r, newSuccessHTTPReply(cInfo))
@@ -2166,8 +2359,28 @@ func (m *Server) decommissionDataPartition(w http.ResponseWriter, r *http.Reques
 
 func (m *Server) diagnoseDataPartition(w http.ResponseWriter, r *http.Request) {
 	var (
-		err                         error
-		rstMsg                      *proto.DataPartitionDiagnosis
+		err    error
+		rstMsg *proto.DataPartitionDiagnosis
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDiagnoseDataPartition))
+	defer func() {
+		doStatAndMetric(proto.AdminDiagnoseDataPartition, metric, err, nil)
+	}()
+
+	ignoreDiscardDp, err := pareseBoolWithDefault(r, ignoreDiscardKey, false)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if rstMsg, err = m.diagnoseDataPartitions(ignoreDiscardDp); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(rstMsg))
+}
+
+func (m *Server) diagnoseDataPartitions(ignoreDiscardDp bool) (rstMsg *proto.DataPartitionDiagnosis, err error) {
+	var (
 		inactiveNodes               []string
 		corruptDps                  []*DataPartition
 		lackReplicaDps              []*DataPartition
@@ -2187,16 +2400,6 @@ func (m *Server) diagnoseDataPartition(w http.ResponseWriter, r *http.Request) {
 		diskErrorDataPartitionInfos proto.DiskErrPartitionView
 		start                       = time.Now()
 	)
-	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDiagnoseDataPartition))
-	defer func() {
-		doStatAndMetric(proto.AdminDiagnoseDataPartition, metric, err, nil)
-	}()
-
-	ignoreDiscardDp, err := pareseBoolWithDefault(r, ignoreDiscardKey, false)
-	if err != nil {
-		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
-		return
-	}
 
 	corruptDpIDs = make([]uint64, 0)
 	lackReplicaDpIDs = make([]uint64, 0)
@@ -2208,13 +2411,11 @@ func (m *Server) diagnoseDataPartition(w http.ResponseWriter, r *http.Request) {
 
 	subStep := time.Now()
 	if inactiveNodes, err = m.cluster.checkInactiveDataNodes(); err != nil {
-		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
 	log.LogDebugf("diagnoseDataPartition checkInactiveDataNodes cost %v", time.Since(subStep).String())
 	if lackReplicaDps, badReplicaDps, repFileCountDifferDps, repUsedSizeDifferDps, excessReplicaDPs,
 		corruptDps, missingTinyExtentDPs, err = m.cluster.checkReplicaOfDataPartitions(ignoreDiscardDp); err != nil {
-		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
 	for _, dp := range corruptDps {
@@ -2264,7 +2465,7 @@ func (m *Server) diagnoseDataPartition(w http.ResponseWriter, r *http.Request) {
 		m.cluster.Name, inactiveNodes, corruptDpIDs,
 		lackReplicaDpIDs, badReplicaDpIDs,
 		repFileCountDifferDpIDs, repUsedSizeDifferDpIDs, excessReplicaDpIDs, time.Since(start).String())
-	sendOkReply(w, r, newSuccessHTTPReply(rstMsg))
+	return
 }
 
 func (m *Server) resetDataPartitionDecommissionStatus(w http.ResponseWriter, r *http.Request) {
@@ -2363,6 +2564,14 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
 		return
 	}
+	if status {
+		if vol, e := m.cluster.getVol(name); e == nil {
+			if err = m.cluster.checkVolReplicasBeforeDelete(vol); err != nil {
+				sendErrReply(w, r, newErrHTTPReply(err))
+				return
+			}
+		}
+	}
 
 	if enableDirectDeleteVol {
 		if err = m.cluster.markDeleteVol(name, authKey, false, true); err != nil {
@@ -2477,6 +2686,49 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) listVolRecycleBin(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminListVolRecycleBin))
+	defer func() {
+		doStatAndMetric(proto.AdminListVolRecycleBin, metric, nil, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getVolRecycleBin()))
+}
+
+// purgeVolRecycleBin deletes a volume in the recycle bin without waiting for the end of its retention.
+func (m *Server) purgeVolRecycleBin(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    string
+		authKey string
+		err     error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminPurgeVolRecycleBin))
+	defer func() {
+		doStatAndMetric(proto.AdminPurgeVolRecycleBin, metric, err, map[string]string{exporter.Vol: name})
+		AuditLog(r, proto.AdminPurgeVolRecycleBin, fmt.Sprintf("purge vol[%v] from recycle bin, from[%v]", name, r.RemoteAddr), err)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if name, err = extractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if authKey, err = extractAuthKey(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.purgeVolFromRecycleBin(name, authKey); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg := fmt.Sprintf("purge vol[%v] from recycle bin successfully, from[%v]", name, r.RemoteAddr)
+	log.LogWarn(msg)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
 func (m *Server) checkReplicaNum(r *http.Request, vol *Vol, req *updateVolReq) (err error) {
 	var (
 		replicaNumInt64 int64
@@ -2618,6 +2870,12 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 	newArgs.trashInterval = req.trashInterval
 	newArgs.accessTimeValidInterval = req.accessTimeValidInterval
 	newArgs.enablePersistAccessTime = req.enablePersistAccessTime
+	newArgs.atimeMode = req.atimeMode
+	newArgs.xattrLimit = req.xattrLimit
+	newArgs.extentConflictPolicy = req.extentConflictPolicy
+	newArgs.enableOpAudit = req.enableOpAudit
+	newArgs.enableCheckExtentKey = req.enableCheckExtentKey
+	newArgs.enableDirStat = req.enableDirStat
 	if req.coldArgs != nil {
 		newArgs.coldArgs = req.coldArgs
 	}
@@ -3018,9 +3276,44 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 	}
 
 	msg := fmt.Sprintf("create vol[%v] successfully, has allocate [%v] data partitions", req.name, len(vol.dataPartitions.partitions))
+	if req.preWarm {
+		mpCount, dpCount := computePreWarmTarget(req.expectedInodes, vol.Capacity,
+			vol.dataPartitionSize/util.GB, gConfig.MetaPartitionInodeIdStep)
+		m.cluster.startPreWarm(vol, mpCount, dpCount)
+		msg += fmt.Sprintf(", pre-warm to [%v] meta partitions and [%v] data partitions in background", mpCount, dpCount)
+	}
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) getVolPreWarmStatus(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		name   string
+		vol    *Vol
+		status *proto.VolPreWarmStatus
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolPreWarmStatus))
+	defer func() {
+		doStatAndMetric(proto.AdminVolPreWarmStatus, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+
+	if status, err = vol.getPreWarmStatus(); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(status))
+}
+
 func (m *Server) qosUpload(w http.ResponseWriter, r *http.Request) {
 	var (
 		err   error
@@ -3177,6 +3470,12 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		EnableAutoDpMetaRepair:  vol.EnableAutoMetaRepair.Load(),
 		AccessTimeInterval:      vol.AccessTimeValidInterval,
 		EnablePersistAccessTime: vol.EnablePersistAccessTime,
+		AtimeMode:               vol.atimeMode,
+		XAttrLimit:              vol.xattrLimit,
+		ExtentConflictPolicy:    vol.extentConflictPolicy,
+		EnableOpAudit:           vol.enableOpAudit,
+		EnableCheckExtentKey:    vol.enableCheckExtentKey,
+		EnableDirStat:           vol.enableDirStat,
 
 		VolStorageClass:          vol.volStorageClass,
 		ForbidWriteOpOfProtoVer0: vol.ForbidWriteOpOfProtoVer0.Load(),
@@ -3193,6 +3492,13 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		FlashNodeTimeoutCount:        vol.flashNodeTimeoutCount,
 		RemoteCacheSameZoneTimeout:   vol.remoteCacheSameZoneTimeout,
 		RemoteCacheSameRegionTimeout: vol.remoteCacheSameRegionTimeout,
+		DefaultXAttrs:                vol.getDefaultXAttrs(),
+		DpPins:                       vol.getDpPins(),
+		ReadOnlyWindows:              vol.getReadOnlyWindows(),
+		MetaFrozen:                   vol.metaFrozen.Load(),
+		MetaAuth:                     vol.metaAuthEnabled(),
+		MetaMediaType:                vol.getMetaMediaType(),
+		SourceVol:                    vol.SourceVol,
 	}
 	view.AllowedStorageClass = make([]uint32, len(vol.allowedStorageClass))
 	copy(view.AllowedStorageClass, vol.allowedStorageClass)
@@ -3644,10 +3950,61 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = m.setNodeInfo(params); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	dataNodesetSelector := extractDataNodesetSelector(r)
+	metaNodesetSelector := extractMetaNodesetSelector(r)
+	dataNodeSelector := extractDataNodeSelector(r)
+	metaNodeSelector := extractMetaNodeSelector(r)
+	if err = m.updateClusterSelector(dataNodesetSelector, metaNodesetSelector, dataNodeSelector, metaNodeSelector); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set nodeinfo params %v successfully", params)))
+}
+
+// setVolMetaOpQuota sets the meta op rates in params of the vol named, the rates not in params
+// are kept and 0 removes one. Each metanode takes the rates of the vol on its own.
+func (m *Server) setVolMetaOpQuota(params map[string]interface{}) (err error) {
+	name, ok := params[nameKey].(string)
+	if !ok {
+		return
+	}
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		return
+	}
+	old := proto.MetaOpQuota{}
+	if quota := vol.getMetaOpQuota(); quota != nil {
+		old = *quota
+	}
+	quota := old
+	for key, rate := range map[string]*uint64{
+		metaCreateOpRateKey: &quota.CreateOps,
+		metaDeleteOpRateKey: &quota.DeleteOps,
+		metaLookupOpRateKey: &quota.LookupOps,
+	} {
+		if v, ok := params[key].(uint64); ok {
+			*rate = v
+		}
+	}
+	vol.setMetaOpQuota(quota)
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setMetaOpQuota(old)
+		return
+	}
+	log.LogWarnf("[setVolMetaOpQuota] vol(%v) meta op quota from %+v to %+v", name, old, quota)
+	return
+}
+
+// setNodeInfo applies the node settings parsed by parseAndExtractSetNodeInfoParams.
+func (m *Server) setNodeInfo(params map[string]interface{}) (err error) {
 	if batchCount, ok := params[nodeDeleteBatchCountKey]; ok {
 		if bc, ok := batchCount.(uint64); ok {
 			if err = m.cluster.setMetaNodeDeleteBatchCount(bc); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3656,7 +4013,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[clusterLoadFactorKey]; ok {
 		if factor, ok := val.(float32); ok {
 			if err = m.cluster.setClusterLoadFactor(factor); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3665,7 +4021,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[nodeMarkDeleteRateKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setDataNodeDeleteLimitRate(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3674,7 +4029,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[flashNodeHandleReadTimeout]; ok {
 		if v, ok := val.(int64); ok {
 			if err = m.setConfig(flashNodeHandleReadTimeout, strconv.FormatInt(v, 10)); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3683,7 +4037,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[flashNodeReadDataNodeTimeout]; ok {
 		if v, ok := val.(int64); ok {
 			if err = m.setConfig(flashNodeReadDataNodeTimeout, strconv.FormatInt(v, 10)); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3692,7 +4045,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[nodeAutoRepairRateKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setDataNodeAutoRepairLimitRate(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3701,7 +4053,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[nodeDpRepairTimeOutKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setDataPartitionRepairTimeOut(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3710,7 +4061,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[nodeDpBackupKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setDataPartitionBackupTimeOut(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3719,7 +4069,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[nodeDpMaxRepairErrCntKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setDataPartitionMaxRepairErrCnt(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3728,16 +4077,53 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[nodeDeleteWorkerSleepMs]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setMetaNodeDeleteWorkerSleepMs(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
 	}
 
+	if val, ok := params[metaSnapshotPersistenceModeKey]; ok {
+		if v, ok := val.(uint32); ok {
+			if err = m.cluster.setMetaSnapshotPersistenceMode(v); err != nil {
+				return
+			}
+		}
+	}
+
+	for key, rate := range map[string]*uint64{
+		metaSnapshotSendRateKey:      &m.cluster.cfg.MetaSnapshotSendRateMB,
+		metaSnapshotRecvRateKey:      &m.cluster.cfg.MetaSnapshotRecvRateMB,
+		metaSnapshotPartitionRateKey: &m.cluster.cfg.MetaSnapshotPartitionRateMB,
+	} {
+		if val, ok := params[key]; ok {
+			if v, ok := val.(uint64); ok {
+				if err = m.cluster.setMetaSnapshotRate(rate, v); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	for key, policy := range map[string]*uint64{
+		metaPartitionHeatSplitOpRateKey:   &m.cluster.cfg.MetaPartitionHeatSplitOpRate,
+		metaPartitionHeatSplitIntervalKey: &m.cluster.cfg.MetaPartitionHeatSplitInterval,
+	} {
+		if val, ok := params[key]; ok {
+			if v, ok := val.(uint64); ok {
+				if err = m.cluster.setMetaPartitionHeatSplit(policy, v); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	if err = m.setVolMetaOpQuota(params); err != nil {
+		return
+	}
+
 	if val, ok := params[maxDpCntLimitKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setMaxDpCntLimit(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3746,7 +4132,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[maxMpCntLimitKey]; ok {
 		if v, ok := val.(uint64); ok {
 			if err = m.cluster.setMaxMpCntLimit(v); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3755,13 +4140,10 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[clusterCreateTimeKey]; ok {
 		if createTimeParam, ok := val.(string); ok {
 			var createTime time.Time
-			var err error
 			if createTime, err = time.ParseInLocation(proto.TimeFormat, createTimeParam, time.Local); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 			if err = m.cluster.setClusterCreateTime(createTime.Unix()); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3770,7 +4152,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[markDiskBrokenThresholdKey]; ok {
 		if markDiskBrokenThreshold, ok := val.(float64); ok {
 			if err = m.cluster.setMarkDiskBrokenThreshold(markDiskBrokenThreshold); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3782,7 +4163,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 			m.cluster.EnableAutoDecommissionDisk.Store(autoDecomm)
 			if err = m.cluster.syncPutCluster(); err != nil {
 				m.cluster.EnableAutoDecommissionDisk.Store(old)
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3791,7 +4171,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[autoDecommissionDiskIntervalKey]; ok {
 		if interval, ok := val.(time.Duration); ok {
 			if err = m.cluster.setAutoDecommissionDiskInterval(interval); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3800,7 +4179,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[autoDpMetaRepairKey]; ok {
 		if autoRepair, ok := val.(bool); ok {
 			if err = m.cluster.setEnableAutoDpMetaRepair(autoRepair); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3809,7 +4187,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[autoDpMetaRepairParallelCntKey]; ok {
 		if cnt, ok := val.(int); ok {
 			if err = m.cluster.setAutoDpMetaRepairParallelCnt(cnt); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3818,7 +4195,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[dpTimeoutKey]; ok {
 		if dpTimeout, ok := val.(int64); ok {
 			if err = m.cluster.setDataPartitionTimeout(dpTimeout); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3827,7 +4203,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[mpTimeoutKey]; ok {
 		if mpTimeout, ok := val.(int64); ok {
 			if err = m.cluster.setMetaPartitionTimeout(mpTimeout); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3836,7 +4211,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[decommissionDiskLimit]; ok {
 		if diskLimit, ok := val.(uint64); ok {
 			if err = m.cluster.setDecommissionDiskLimit(uint32(diskLimit)); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3845,7 +4219,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[decommissionLimit]; ok {
 		if dpLimit, ok := val.(uint64); ok {
 			if err = m.cluster.setDecommissionDpLimit(dpLimit); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3855,7 +4228,6 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 		if mediaType, ok := val.(uint64); ok {
 			if err = m.cluster.setClusterMediaType(uint32(mediaType)); err != nil {
 				log.LogErrorf("setClusterMediaType: set mediaType failed, err %s", err.Error())
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
@@ -3864,21 +4236,11 @@ func (m *Server) setNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if val, ok := params[forbidWriteOpOfProtoVersion0]; ok {
 		if forbidWriteOpOfProtoVer0, ok := val.(bool); ok {
 			if err = m.cluster.setForbidWriteOpOfProtoVersion0(forbidWriteOpOfProtoVer0); err != nil {
-				sendErrReply(w, r, newErrHTTPReply(err))
 				return
 			}
 		}
 	}
-
-	dataNodesetSelector := extractDataNodesetSelector(r)
-	metaNodesetSelector := extractMetaNodesetSelector(r)
-	dataNodeSelector := extractDataNodeSelector(r)
-	metaNodeSelector := extractMetaNodeSelector(r)
-	if err = m.updateClusterSelector(dataNodesetSelector, metaNodesetSelector, dataNodeSelector, metaNodeSelector); err != nil {
-		sendErrReply(w, r, newErrHTTPReply(err))
-		return
-	}
-	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set nodeinfo params %v successfully", params)))
+	return
 }
 
 func (m *Server) updateDataUseRatio(ratio float64) (err error) {
@@ -4125,6 +4487,31 @@ func (m *Server) setNodeRdOnly(addr string, nodeType uint32, rdOnly bool) (err e
 	return
 }
 
+func (m *Server) setMetaNodeState(addr, state string) (err error) {
+	m.cluster.mnMutex.Lock()
+	defer m.cluster.mnMutex.Unlock()
+
+	value, ok := m.cluster.metaNodes.Load(addr)
+	if !ok {
+		return fmt.Errorf("[setMetaNodeState] meta node %s is not exist", addr)
+	}
+
+	metaNode := value.(*MetaNode)
+	metaNode.Lock()
+	oldNoPlacement := metaNode.NoPlacement
+	metaNode.NoPlacement = state == proto.NodeStateNoPlacement
+	metaNode.Unlock()
+
+	if err = m.cluster.syncUpdateMetaNode(metaNode); err != nil {
+		metaNode.Lock()
+		metaNode.NoPlacement = oldNoPlacement
+		metaNode.Unlock()
+		return fmt.Errorf("[setMetaNodeState] syncUpdateMetaNode err(%s)", err.Error())
+	}
+
+	return
+}
+
 func (m *Server) updateNodesetCapcity(zoneName string, nodesetId uint64, capcity uint64) (err error) {
 	var ns *nodeSet
 	var ok bool
@@ -4277,6 +4664,26 @@ func parseSetNodeRdOnlyParam(r *http.Request) (addr string, nodeType uint32, rdO
 	return
 }
 
+func parseSetNodeStateParam(r *http.Request) (addr, state string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+
+	if addr = r.FormValue(addrKey); addr == "" {
+		err = fmt.Errorf("parseSetNodeStateParam %s is empty", addrKey)
+		return
+	}
+
+	state = r.FormValue(nodeStateKey)
+	if state != proto.NodeStateNormal && state != proto.NodeStateNoPlacement {
+		err = fmt.Errorf("parseSetNodeStateParam %s is not legal %s, must be %s or %s", nodeStateKey, state,
+			proto.NodeStateNormal, proto.NodeStateNoPlacement)
+		return
+	}
+
+	return
+}
+
 func parseSetDpRdOnlyParam(r *http.Request) (dpId uint64, rdOnly bool, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -4353,6 +4760,35 @@ func (m *Server) setNodeRdOnlyHandler(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("[setNodeRdOnlyHandler] set node %s to rdOnly(%v) success", addr, rdOnly)))
 }
 
+func (m *Server) setNodeStateByAddrHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		addr  string
+		state string
+		err   error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSetNodeStateByAddr))
+	defer func() {
+		doStatAndMetric(proto.AdminSetNodeStateByAddr, metric, err, nil)
+		AuditLog(r, proto.AdminSetNodeStateByAddr, fmt.Sprintf("set node %s to state(%v)", addr, state), err)
+	}()
+
+	addr, state, err = parseSetNodeStateParam(r)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	log.LogInfof("[setNodeStateByAddrHandler] set node %s to state(%v)", addr, state)
+
+	if err = m.setMetaNodeState(addr, state); err != nil {
+		log.LogErrorf("[setNodeStateByAddrHandler] set node %s to state %v, err (%s)", addr, state, err.Error())
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("[setNodeStateByAddrHandler] set node %s to state(%v) success", addr, state)))
+}
+
 func (m *Server) setDpRdOnlyHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		dpId   uint64
@@ -4646,24 +5082,46 @@ func (m *Server) getNodeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		doStatAndMetric(proto.AdminGetNodeInfo, metric, nil, nil)
 	}()
+	sendOkReply(w, r, newSuccessHTTPReply(m.nodeInfo()))
+}
 
-	resp := make(map[string]string)
+func (m *Server) nodeInfo() (resp map[string]string) {
+	resp = make(map[string]string)
 	resp[nodeDeleteBatchCountKey] = fmt.Sprintf("%v", m.cluster.cfg.MetaNodeDeleteBatchCount)
 	resp[nodeMarkDeleteRateKey] = fmt.Sprintf("%v", m.cluster.cfg.DataNodeDeleteLimitRate)
 	resp[nodeDeleteWorkerSleepMs] = fmt.Sprintf("%v", m.cluster.cfg.MetaNodeDeleteWorkerSleepMs)
+	resp[metaSnapshotPersistenceModeKey] = proto.PersistenceModeString(atomic.LoadUint32(&m.cluster.cfg.MetaSnapshotPersistenceMode))
+	resp[metaSnapshotSendRateKey] = fmt.Sprintf("%v", atomic.LoadUint64(&m.cluster.cfg.MetaSnapshotSendRateMB))
+	resp[metaSnapshotRecvRateKey] = fmt.Sprintf("%v", atomic.LoadUint64(&m.cluster.cfg.MetaSnapshotRecvRateMB))
+	resp[metaSnapshotPartitionRateKey] = fmt.Sprintf("%v", atomic.LoadUint64(&m.cluster.cfg.MetaSnapshotPartitionRateMB))
+	resp[metaPartitionHeatSplitOpRateKey] = fmt.Sprintf("%v", atomic.LoadUint64(&m.cluster.cfg.MetaPartitionHeatSplitOpRate))
+	resp[metaPartitionHeatSplitIntervalKey] = fmt.Sprintf("%v", m.cluster.metaPartitionHeatSplitInterval())
 	resp[nodeAutoRepairRateKey] = fmt.Sprintf("%v", m.cluster.cfg.DataNodeAutoRepairLimitRate)
 	resp[nodeDpMaxRepairErrCntKey] = fmt.Sprintf("%v", m.cluster.cfg.DpMaxRepairErrCnt)
 	resp[clusterLoadFactorKey] = fmt.Sprintf("%v", m.cluster.cfg.ClusterLoadFactor)
 	resp[maxDpCntLimitKey] = fmt.Sprintf("%v", m.cluster.getMaxDpCntLimit())
 	resp[maxMpCntLimitKey] = fmt.Sprintf("%v", m.cluster.getMaxMpCntLimit())
-
-	sendOkReply(w, r, newSuccessHTTPReply(resp))
+	return
 }
 
 func (m *Server) diagnoseMetaPartitionDelayDelted(w http.ResponseWriter, r *http.Request) {
 	var (
-		err                             error
-		rstMsg                          *proto.MetaPartitionDiagnosis
+		err    error
+		rstMsg *proto.MetaPartitionDiagnosis
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDiagnoseMetaPartition))
+	defer func() {
+		doStatAndMetric(proto.AdminDiagnoseMetaPartition, metric, err, nil)
+	}()
+	if rstMsg, err = m.diagnoseMetaPartitions(); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(rstMsg))
+}
+
+func (m *Server) diagnoseMetaPartitions() (rstMsg *proto.MetaPartitionDiagnosis, err error) {
+	var (
 		inactiveNodes                   []string
 		noLeaderMps                     []*MetaPartition
 		lackReplicaMps                  []*MetaPartition
@@ -4681,21 +5139,15 @@ func (m *Server) diagnoseMetaPartitionDelayDelted(w http.ResponseWriter, r *http
 		dentryCountNotEqualReplicaMpIDs []uint64
 		badMetaPartitions               []badPartitionView
 	)
-	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDiagnoseMetaPartition))
-	defer func() {
-		doStatAndMetric(proto.AdminDiagnoseMetaPartition, metric, err, nil)
-	}()
 	corruptMpIDs = make([]uint64, 0)
 	lackReplicaMpIDs = make([]uint64, 0)
 	badReplicaMpIDs = make([]uint64, 0)
 	excessReplicaMpIDs = make([]uint64, 0)
 	if inactiveNodes, err = m.cluster.checkInactiveMetaNodes(); err != nil {
-		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
 	if lackReplicaMps, noLeaderMps, badReplicaMps, excessReplicaMPs,
 		inodeCountNotEqualReplicaMps, maxInodeNotEqualMPs, dentryCountNotEqualReplicaMps, err = m.cluster.checkReplicaMetaPartitions(); err != nil {
-		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
 	for _, mp := range noLeaderMps {
@@ -4736,7 +5188,7 @@ func (m *Server) diagnoseMetaPartitionDelayDelted(w http.ResponseWriter, r *http
 		"inodeCountNotEqualReplicaMpIDs[%v] dentryCountNotEqualReplicaMpIDs[%v]",
 		m.cluster.Name, inactiveNodes, corruptMpIDs, lackReplicaMpIDs, badReplicaMpIDs, excessReplicaMpIDs,
 		inodeCountNotEqualReplicaMpIDs, dentryCountNotEqualReplicaMpIDs)
-	sendOkReply(w, r, newSuccessHTTPReply(rstMsg))
+	return
 }
 
 func (m *Server) diagnoseMetaPartition(w http.ResponseWriter, r *http.Request) {
@@ -5276,6 +5728,10 @@ func (m *Server) getMetaNode(w http.ResponseWriter, r *http.Request) {
 		CanAllowPartition:         metaNode.IsWriteAble() && metaNode.PartitionCntLimited(),
 		MaxMpCntLimit:             metaNode.GetPartitionLimitCnt(),
 		CpuUtil:                   metaNode.CpuUtil.Load(),
+		Draining:                  metaNode.Draining,
+		Drained:                   metaNode.Drained,
+		MediaType:                 metaNode.MediaType,
+		NoPlacement:               metaNode.NoPlacement,
 	}
 	sendOkReply(w, r, newSuccessHTTPReply(metaNodeInfo))
 }
@@ -5348,6 +5804,49 @@ func (m *Server) decommissionMetaPartition(w http.ResponseWriter, r *http.Reques
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) migrateMetaPartitionToNodeSet(w http.ResponseWriter, r *http.Request) {
+	var (
+		partitionID uint64
+		nodeSetID   uint64
+		mp          *MetaPartition
+		srcs        []string
+		err         error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminMigrateMetaPartitionToNodeSet))
+	defer func() {
+		doStatAndMetric(proto.AdminMigrateMetaPartitionToNodeSet, metric, err, nil)
+		AuditLog(r, proto.AdminMigrateMetaPartitionToNodeSet, fmt.Sprintf("meta partition %v nodeset %v", partitionID, nodeSetID), err)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if partitionID, err = extractMetaPartitionID(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if r.FormValue(nodesetIdKey) == "" {
+		err = keyNotFound(nodesetIdKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if nodeSetID, err = extractUint64(r, nodesetIdKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+		return
+	}
+	if srcs, err = m.cluster.migrateMetaPartitionToNodeSet(mp, nodeSetID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("meta partition %v starts moving the replicas on %v to nodeset %v",
+		partitionID, srcs, nodeSetID)))
+}
+
 func parseMigrateNodeParam(r *http.Request) (srcAddr, targetAddr string, limit int, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -5755,6 +6254,45 @@ func (m *Server) getMetaPartitions(w http.ResponseWriter, r *http.Request) {
 	send(w, r, mpsCache)
 }
 
+// getNsEventEndpoints returns the meta partitions of the vol to watch the namespace events
+// from. With ino, the inode of the dir a path prefix resolves to, only the partition keeping
+// the dentries of the dir is returned.
+func (m *Server) getNsEventEndpoints(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		ino  uint64
+		vol  *Vol
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.ClientNsEventEndpoints))
+	defer func() {
+		doStatAndMetric(proto.ClientNsEventEndpoints, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if ino, err = extractUint64WithDefault(r, inodeKey, 0); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+
+	views := make([]*proto.MetaPartitionView, 0)
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		if ino > 0 && (ino < mp.Start || ino > mp.End) {
+			continue
+		}
+		views = append(views, getMetaPartitionView(mp))
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].PartitionID < views[j].PartitionID })
+	sendOkReply(w, r, newSuccessHTTPReply(views))
+}
+
 func (m *Server) putDataPartitions(w http.ResponseWriter, r *http.Request) {
 	var (
 		body []byte
@@ -5829,12 +6367,7 @@ func (m *Server) getDataPartitions(w http.ResponseWriter, r *http.Request) {
 		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
 		return
 	}
-	if compress {
-		body, err = vol.getDataPartitionViewCompress()
-	} else {
-		body, err = vol.getDataPartitionsView()
-	}
-	if err != nil {
+	if body, err = m.cluster.getVolDataPartitionsView(vol, compress); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -6019,6 +6552,7 @@ func volStat(vol *Vol, countByMeta bool) (stat *proto.VolStatInfo) {
 	stat.MetaFollowerRead = vol.MetaFollowerRead
 	stat.MaximallyRead = vol.MaximallyRead
 	stat.LeaderRetryTimeOut = int(vol.LeaderRetryTimeout)
+	stat.AtimeMode = vol.atimeMode
 
 	log.LogDebugf("[volStat] vol[%v] total[%v],usedSize[%v] TrashInterval[%v] DefaultStorageClass[%v]",
 		vol.Name, stat.TotalSize, stat.UsedSize, stat.TrashInterval, stat.DefaultStorageClass)
@@ -6056,90 +6590,319 @@ func getMetaPartitionView(mp *MetaPartition) (mpView *proto.MetaPartitionView) {
 	return
 }
 
-func (m *Server) getMetaPartition(w http.ResponseWriter, r *http.Request) {
+// getMetaPartitionLagInfo returns the apply index lag of the replicas of the meta partition
+// given by id, or all the lagging meta partitions of the volume given by name or of the cluster.
+func (m *Server) getMetaPartitionLagInfo(w http.ResponseWriter, r *http.Request) {
 	var (
 		err         error
 		partitionID uint64
 		mp          *MetaPartition
+		vol         *Vol
 	)
-	metric := exporter.NewTPCnt(apiToMetricsName(proto.ClientMetaPartition))
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminMetaPartitionLagInfo))
 	defer func() {
-		doStatAndMetric(proto.ClientMetaPartition, metric, err, nil)
+		doStatAndMetric(proto.AdminMetaPartitionLagInfo, metric, err, nil)
 	}()
 
-	if partitionID, err = parseAndExtractPartitionInfo(r); err != nil {
+	if err = r.ParseForm(); err != nil {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
 		return
 	}
-	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
-		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+	threshold := m.cluster.cfg.MpApplyLagThreshold
+	if r.FormValue(idKey) != "" {
+		if partitionID, err = extractMetaPartitionID(r); err != nil {
+			sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+			return
+		}
+		if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
+			sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+			return
+		}
+		info, _ := mp.getLagInfo(threshold)
+		sendOkReply(w, r, newSuccessHTTPReply(info))
 		return
 	}
 
-	toInfo := func(mp *MetaPartition) *proto.MetaPartitionInfo {
-		mp.RLock()
-		defer mp.RUnlock()
-		replicas := make([]*proto.MetaReplicaInfo, len(mp.Replicas))
-		zones := make([]string, len(mp.Hosts))
-		nodeSets := make([]uint64, len(mp.Hosts))
-		for idx, host := range mp.Hosts {
-			metaNode, err := m.cluster.metaNode(host)
-			if err == nil {
-				zones[idx] = metaNode.ZoneName
-				nodeSets[idx] = metaNode.NodeSetID
-			}
+	vols := m.cluster.allVols()
+	if name := r.FormValue(nameKey); name != "" {
+		if vol, err = m.cluster.getVol(name); err != nil {
+			sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+			return
 		}
-		for i := 0; i < len(replicas); i++ {
-			replicas[i] = &proto.MetaReplicaInfo{
-				Addr:            mp.Replicas[i].Addr,
-				NodeID:          mp.Replicas[i].nodeID,
-				DomainAddr:      mp.Replicas[i].metaNode.DomainAddr,
-				MaxInodeID:      mp.Replicas[i].MaxInodeID,
-				ReportTime:      mp.Replicas[i].ReportTime,
-				Status:          mp.Replicas[i].Status,
-				IsLeader:        mp.Replicas[i].IsLeader,
-				InodeCount:      mp.Replicas[i].InodeCount,
-				DentryCount:     mp.Replicas[i].DentryCount,
-				MaxInode:        mp.Replicas[i].MaxInodeID,
-				ReadOnlyReasons: mp.Replicas[i].ReadOnlyReasons,
+		vols = map[string]*Vol{name: vol}
+	}
+	infos := make([]*proto.MetaPartitionLagInfo, 0)
+	for _, vol := range vols {
+		for _, mp := range vol.getSortMetaPartitions() {
+			if info, lagging := mp.getLagInfo(threshold); lagging {
+				infos = append(infos, info)
 			}
 		}
-
-		forbidden := true
-		vol, err := m.cluster.getVol(mp.volName)
-		if err == nil {
-			forbidden = vol.Forbidden
-		} else {
-			log.LogErrorf("action[getMetaPartition]failed to get volume %v, err %v", mp.volName, err)
-		}
-		mpInfo := &proto.MetaPartitionInfo{
-			PartitionID:               mp.PartitionID,
-			Start:                     mp.Start,
-			End:                       mp.End,
-			VolName:                   mp.volName,
-			MaxInodeID:                mp.MaxInodeID,
-			InodeCount:                mp.InodeCount,
-			DentryCount:               mp.DentryCount,
-			Replicas:                  replicas,
-			ReplicaNum:                mp.ReplicaNum,
-			Status:                    mp.Status,
-			IsRecover:                 mp.IsRecover,
-			Hosts:                     mp.Hosts,
-			Peers:                     mp.Peers,
-			Zones:                     zones,
-			NodeSets:                  nodeSets,
-			MissNodes:                 mp.MissNodes,
-			OfflinePeerID:             mp.OfflinePeerID,
-			LoadResponse:              mp.LoadResponse,
-			Forbidden:                 forbidden,
-			Freeze:                    mp.Freeze,
-			StatByStorageClass:        mp.StatByStorageClass,
-			StatByMigrateStorageClass: mp.StatByMigrateStorageClass,
-			ForbidWriteOpOfProtoVer0:  mp.ForbidWriteOpOfProtoVer0,
-		}
-		return mpInfo
 	}
-
+	sendOkReply(w, r, newSuccessHTTPReply(infos))
+}
+
+// diffMetaPartitionTree compares the CRCs of the trees of the meta partition replicas in ranges
+// of inode IDs and reports the ranges that diverge.
+func (m *Server) diffMetaPartitionTree(w http.ResponseWriter, r *http.Request) {
+	var (
+		err         error
+		partitionID uint64
+		rangeSize   uint64
+		mp          *MetaPartition
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDiffMetaPartitionTree))
+	defer func() {
+		doStatAndMetric(proto.AdminDiffMetaPartitionTree, metric, err, nil)
+	}()
+
+	if partitionID, err = parseAndExtractPartitionInfo(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if rangeSize, err = extractUint64WithDefault(r, rangeSizeKey, proto.DefaultMetaTreeCRCRangeSize); err != nil || rangeSize == 0 {
+		err = fmt.Errorf("invalid %v", rangeSizeKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.diffMetaPartitionTree(mp, rangeSize)))
+}
+
+// repairMetaInodeNLink recounts the link count of the inode from the dentries of the vol, and
+// sets it if it differs unless dryRun, which is the default.
+func (m *Server) repairMetaInodeNLink(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		name   string
+		ino    uint64
+		dryRun bool
+		vol    *Vol
+		report *proto.MetaNLinkRepairReport
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminRepairMetaInodeNLink))
+	defer func() {
+		doStatAndMetric(proto.AdminRepairMetaInodeNLink, metric, err, nil)
+		AuditLog(r, proto.AdminRepairMetaInodeNLink, fmt.Sprintf("vol(%v) ino(%v) dryRun(%v) report(%+v)",
+			name, ino, dryRun, report), err)
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if ino, err = extractUint64(r, inodeKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if dryRun, err = extractBoolWithDefault(r, dryRunKey, true); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if report, err = m.cluster.repairInodeNLink(vol, ino, dryRun); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(report))
+}
+
+// truncateMetaRaftLog truncates the raft log of the leader of the meta partition up to the
+// index, after the operator has verified the snapshots of the replicas.
+func (m *Server) truncateMetaRaftLog(w http.ResponseWriter, r *http.Request) {
+	var (
+		err         error
+		partitionID uint64
+		index       uint64
+		mp          *MetaPartition
+		resp        *proto.MetaRaftLogTruncateResponse
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminTruncateMetaRaftLog))
+	defer func() {
+		doStatAndMetric(proto.AdminTruncateMetaRaftLog, metric, err, nil)
+		AuditLog(r, proto.AdminTruncateMetaRaftLog, fmt.Sprintf("mp(%v) index(%v) resp(%+v)",
+			partitionID, index, resp), err)
+	}()
+
+	if partitionID, err = parseAndExtractPartitionInfo(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if index, err = extractUint64(r, indexKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+		return
+	}
+	if resp, err = m.cluster.truncateMetaRaftLog(mp, index); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(resp))
+}
+
+// getNodeBlastRadius estimates the volumes and partitions affected by the loss of the data node
+// or meta node given by addr, or of the disk of the data node if disk is given.
+func (m *Server) getNodeBlastRadius(w http.ResponseWriter, r *http.Request) {
+	var (
+		err      error
+		nodeAddr string
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminNodeBlastRadius))
+	defer func() {
+		doStatAndMetric(proto.AdminNodeBlastRadius, metric, err, nil)
+	}()
+
+	if nodeAddr, err = parseAndExtractNodeAddr(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	diskPath := r.FormValue(diskPathKey)
+	if _, err = m.cluster.dataNode(nodeAddr); err == nil {
+		sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getBlastRadius(nodeAddr, diskPath, true)))
+		return
+	}
+	if diskPath != "" {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrDataNodeNotExists))
+		return
+	}
+	if _, err = m.cluster.metaNode(nodeAddr); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: fmt.Sprintf("node[%v] not found", nodeAddr)})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getBlastRadius(nodeAddr, "", false)))
+}
+
+// getMetaPartitionDistribution reports the replicas of the meta partitions of the volume given by
+// name, or of all the volumes, in each zone, nodeset and meta node, and the partitions breaking the
+// zone policy of their volumes. The decommissions restoring the policy are reported if fixPlan.
+func (m *Server) getMetaPartitionDistribution(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		fixPlan bool
+		vol     *Vol
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminMetaPartitionDistribution))
+	defer func() {
+		doStatAndMetric(proto.AdminMetaPartitionDistribution, metric, err, nil)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if fixPlan, err = extractBoolWithDefault(r, fixPlanKey, false); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vols := m.cluster.allVols()
+	if name := r.FormValue(nameKey); name != "" {
+		if vol, err = m.cluster.getVol(name); err != nil {
+			sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+			return
+		}
+		vols = map[string]*Vol{name: vol}
+	}
+	list := make([]*Vol, 0, len(vols))
+	for _, v := range vols {
+		list = append(list, v)
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getMetaPartitionDistribution(list, fixPlan)))
+}
+
+func (m *Server) getMetaPartition(w http.ResponseWriter, r *http.Request) {
+	var (
+		err         error
+		partitionID uint64
+		mp          *MetaPartition
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.ClientMetaPartition))
+	defer func() {
+		doStatAndMetric(proto.ClientMetaPartition, metric, err, nil)
+	}()
+
+	if partitionID, err = parseAndExtractPartitionInfo(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+		return
+	}
+
+	toInfo := func(mp *MetaPartition) *proto.MetaPartitionInfo {
+		mp.RLock()
+		defer mp.RUnlock()
+		replicas := make([]*proto.MetaReplicaInfo, len(mp.Replicas))
+		zones := make([]string, len(mp.Hosts))
+		nodeSets := make([]uint64, len(mp.Hosts))
+		for idx, host := range mp.Hosts {
+			metaNode, err := m.cluster.metaNode(host)
+			if err == nil {
+				zones[idx] = metaNode.ZoneName
+				nodeSets[idx] = metaNode.NodeSetID
+			}
+		}
+		for i := 0; i < len(replicas); i++ {
+			replicas[i] = &proto.MetaReplicaInfo{
+				Addr:            mp.Replicas[i].Addr,
+				NodeID:          mp.Replicas[i].nodeID,
+				DomainAddr:      mp.Replicas[i].metaNode.DomainAddr,
+				MaxInodeID:      mp.Replicas[i].MaxInodeID,
+				ReportTime:      mp.Replicas[i].ReportTime,
+				Status:          mp.Replicas[i].Status,
+				IsLeader:        mp.Replicas[i].IsLeader,
+				InodeCount:      mp.Replicas[i].InodeCount,
+				DentryCount:     mp.Replicas[i].DentryCount,
+				MaxInode:        mp.Replicas[i].MaxInodeID,
+				ReadOnlyReasons: mp.Replicas[i].ReadOnlyReasons,
+				Stat:            mp.Replicas[i].Stat,
+			}
+		}
+
+		forbidden := true
+		vol, err := m.cluster.getVol(mp.volName)
+		if err == nil {
+			forbidden = vol.Forbidden
+		} else {
+			log.LogErrorf("action[getMetaPartition]failed to get volume %v, err %v", mp.volName, err)
+		}
+		mpInfo := &proto.MetaPartitionInfo{
+			PartitionID:               mp.PartitionID,
+			Start:                     mp.Start,
+			End:                       mp.End,
+			VolName:                   mp.volName,
+			MaxInodeID:                mp.MaxInodeID,
+			InodeCount:                mp.InodeCount,
+			DentryCount:               mp.DentryCount,
+			Replicas:                  replicas,
+			ReplicaNum:                mp.ReplicaNum,
+			Status:                    mp.Status,
+			IsRecover:                 mp.IsRecover,
+			Hosts:                     mp.Hosts,
+			Peers:                     mp.Peers,
+			Zones:                     zones,
+			NodeSets:                  nodeSets,
+			MissNodes:                 mp.MissNodes,
+			OfflinePeerID:             mp.OfflinePeerID,
+			LoadResponse:              mp.LoadResponse,
+			Forbidden:                 forbidden,
+			Freeze:                    mp.Freeze,
+			StatByStorageClass:        mp.StatByStorageClass,
+			StatByMigrateStorageClass: mp.StatByMigrateStorageClass,
+			ForbidWriteOpOfProtoVer0:  mp.ForbidWriteOpOfProtoVer0,
+		}
+		return mpInfo
+	}
+
 	sendOkReply(w, r, newSuccessHTTPReply(toInfo(mp)))
 }
 
@@ -7207,6 +7970,33 @@ func (m *Server) setConfig(key string, value string) (err error) {
 		}
 		oldBoolValue = m.config.AutoMpMigrate
 		m.config.AutoMpMigrate = autoMigrate
+	case cfgMpApplyLagThreshold:
+		var lag uint64
+		if lag, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return err
+		}
+		if lag == 0 {
+			return fmt.Errorf("%v should be greater than 0", key)
+		}
+		oldUint64Value = m.config.MpApplyLagThreshold
+		m.config.MpApplyLagThreshold = lag
+	case cfgMpApplyLagCycles:
+		var cycles int
+		if cycles, err = strconv.Atoi(value); err != nil {
+			return err
+		}
+		if cycles <= 0 {
+			return fmt.Errorf("%v should be greater than 0", key)
+		}
+		oldIntValue = m.config.MpApplyLagCycles
+		m.config.MpApplyLagCycles = cycles
+	case cfgMpApplyLagAutoRepair:
+		var autoRepair bool
+		if autoRepair, err = strconv.ParseBool(value); err != nil {
+			return err
+		}
+		oldBoolValue = m.config.MpApplyLagAutoRepair
+		m.config.MpApplyLagAutoRepair = autoRepair
 
 	case flashNodeHandleReadTimeout:
 		fnHandleReadTimeout, err = strconv.Atoi(value)
@@ -7239,6 +8029,12 @@ func (m *Server) setConfig(key string, value string) (err error) {
 			m.config.metaNodeMemLowPer = oldFloat64Value
 		case cfgAutoMpMigrate:
 			m.config.AutoMpMigrate = oldBoolValue
+		case cfgMpApplyLagThreshold:
+			m.config.MpApplyLagThreshold = oldUint64Value
+		case cfgMpApplyLagCycles:
+			m.config.MpApplyLagCycles = oldIntValue
+		case cfgMpApplyLagAutoRepair:
+			m.config.MpApplyLagAutoRepair = oldBoolValue
 		case flashNodeHandleReadTimeout:
 			m.config.flashNodeHandleReadTimeout = oldIntValue
 		case flashNodeReadDataNodeTimeout:
@@ -7266,6 +8062,12 @@ func (m *Server) getConfig(key string) (value string, err error) {
 		value = strconv.FormatFloat(m.config.metaNodeMemLowPer, 'f', -1, 64)
 	case cfgAutoMpMigrate:
 		value = strconv.FormatBool(m.config.AutoMpMigrate)
+	case cfgMpApplyLagThreshold:
+		value = strconv.FormatUint(m.config.MpApplyLagThreshold, 10)
+	case cfgMpApplyLagCycles:
+		value = strconv.Itoa(m.config.MpApplyLagCycles)
+	case cfgMpApplyLagAutoRepair:
+		value = strconv.FormatBool(m.config.MpApplyLagAutoRepair)
 	case flashNodeHandleReadTimeout:
 		value = strconv.Itoa(m.config.flashNodeHandleReadTimeout)
 	case flashNodeReadDataNodeTimeout:
@@ -7608,16 +8410,17 @@ func (m *Server) getDiscardDpHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (m *Server) queryBadDisks(w http.ResponseWriter, r *http.Request) {
-	var (
-		err   error
-		infos proto.DiskInfos
-	)
+	var err error
 
 	metric := exporter.NewTPCnt("req_queryBadDisks")
 	defer func() {
 		metric.Set(err)
 	}()
+	sendOkReply(w, r, newSuccessHTTPReply(m.badDisks()))
+}
 
+// badDisks returns the unavailable disks of the data nodes.
+func (m *Server) badDisks() (infos proto.DiskInfos) {
 	m.cluster.dataNodes.Range(func(addr, node interface{}) bool {
 		dataNode, ok := node.(*DataNode)
 		if !ok {
@@ -7638,8 +8441,7 @@ func (m *Server) queryBadDisks(w http.ResponseWriter, r *http.Request) {
 		}
 		return true
 	})
-
-	sendOkReply(w, r, newSuccessHTTPReply(infos))
+	return
 }
 
 func (m *Server) queryDisks(w http.ResponseWriter, r *http.Request) {
@@ -8241,6 +9043,748 @@ func (m *Server) setVolDpRepairBlockSize(w http.ResponseWriter, r *http.Request)
 	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set volume dp repair block size to (%v) success", repairSize)))
 }
 
+func (m *Server) setVolDefaultXAttrs(w http.ResponseWriter, r *http.Request) {
+	var (
+		xattrs map[string]string
+		name   string
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolSetDefaultXAttrs))
+	defer func() {
+		doStatAndMetric(proto.AdminVolSetDefaultXAttrs, metric, err, nil)
+		AuditLog(r, proto.AdminVolSetDefaultXAttrs, fmt.Sprintf("vol(%v) xattrs(%v)", name, xattrs), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if xattrs, err = parseAndExtractDefaultXAttrs(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	// the default xattrs are set on every new inode, they must be within the limit of the vol
+	if err = vol.xattrLimit.Check(xattrs); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	oldXAttrs := vol.getDefaultXAttrs()
+	vol.setDefaultXAttrs(xattrs)
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setDefaultXAttrs(oldXAttrs)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogInfof("[setVolDefaultXAttrs] vol(%v) default xattrs from (%v) to (%v)", name, oldXAttrs, xattrs)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set volume default xattrs to (%v) success", xattrs)))
+}
+
+func (m *Server) setVolMetaWorkerWeight(w http.ResponseWriter, r *http.Request) {
+	var (
+		weight int
+		name   string
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolSetMetaWorkerWeight))
+	defer func() {
+		doStatAndMetric(proto.AdminVolSetMetaWorkerWeight, metric, err, nil)
+		AuditLog(r, proto.AdminVolSetMetaWorkerWeight, fmt.Sprintf("vol(%v) weight(%v)", name, weight), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if weight, err = extractUint(r, metaWorkerWeightKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if weight > proto.MaxMetaWorkerWeight {
+		err = fmt.Errorf("weight %v is larger than %v", weight, proto.MaxMetaWorkerWeight)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	oldWeight := vol.getMetaWorkerWeight()
+	vol.setMetaWorkerWeight(int32(weight))
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setMetaWorkerWeight(oldWeight)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogInfof("[setVolMetaWorkerWeight] vol(%v) meta worker weight from (%v) to (%v)", name, oldWeight, weight)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set volume meta worker weight to (%v) success", weight)))
+}
+
+// setVolMetaMediaType places the new meta partitions of the vol, and the replicas moved by
+// decommission, on the meta nodes of the media type only. The partitions with the replicas on
+// the other meta nodes are replied, 0 of the media type places the partitions on any meta node.
+func (m *Server) setVolMetaMediaType(w http.ResponseWriter, r *http.Request) {
+	var (
+		mediaType uint32
+		name      string
+		err       error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolSetMetaMediaType))
+	defer func() {
+		doStatAndMetric(proto.AdminVolSetMetaMediaType, metric, err, nil)
+		AuditLog(r, proto.AdminVolSetMetaMediaType, fmt.Sprintf("vol(%v) mediaType(%v)", name, mediaType), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mediaType, err = extractMediaType(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if mediaType != proto.MediaType_Unspecified && !proto.IsValidMediaType(mediaType) {
+		err = fmt.Errorf("invalid media type %v", mediaType)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if mediaType != proto.MediaType_Unspecified {
+		if count := m.cluster.countWritableMetaNodesOfMediaType(mediaType); count < int(vol.mpReplicaNum) {
+			err = fmt.Errorf("only %v writable meta nodes of media type %v, vol replica num %v",
+				count, proto.MediaTypeString(mediaType), vol.mpReplicaNum)
+			sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+			return
+		}
+	}
+	oldMediaType := vol.getMetaMediaType()
+	vol.setMetaMediaType(mediaType)
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setMetaMediaType(oldMediaType)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	report := m.cluster.getMetaMediaTypeReport(vol, mediaType)
+	log.LogInfof("[setVolMetaMediaType] vol(%v) meta media type from (%v) to (%v), %v partitions not on it",
+		name, proto.MediaTypeString(oldMediaType), proto.MediaTypeString(mediaType), len(report.Partitions))
+	sendOkReply(w, r, newSuccessHTTPReply(report))
+}
+
+// checkVolMetaMediaType replies the meta partitions of the vol with the replicas on the meta
+// nodes not of the meta media type of the vol.
+func (m *Server) checkVolMetaMediaType(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolCheckMetaMediaType))
+	defer func() {
+		doStatAndMetric(proto.AdminVolCheckMetaMediaType, metric, err, nil)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getMetaMediaTypeReport(vol, vol.getMetaMediaType())))
+}
+
+func (m *Server) setVolDpPin(w http.ResponseWriter, r *http.Request) {
+	var (
+		pin  *proto.DataPartitionPin
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolSetDpPin))
+	defer func() {
+		doStatAndMetric(proto.AdminVolSetDpPin, metric, err, nil)
+		AuditLog(r, proto.AdminVolSetDpPin, fmt.Sprintf("vol(%v) pin(%v)", name, pin), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if pin, err = parseAndExtractDpPin(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if pin.ZoneName != "" {
+		if _, err = m.cluster.t.getZone(pin.ZoneName); err != nil {
+			sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+			return
+		}
+	}
+	oldPins := vol.getDpPins()
+	if err = vol.putDpPin(pin); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setDpPins(oldPins)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogInfof("[setVolDpPin] vol(%v) pin path(%v) to mediaType(%v) zone(%v)",
+		name, pin.Path, proto.MediaTypeString(pin.MediaType), pin.ZoneName)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("pin path (%v) of volume (%v) success", pin.Path, name)))
+}
+
+func (m *Server) removeVolDpPin(w http.ResponseWriter, r *http.Request) {
+	var (
+		path string
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolRemoveDpPin))
+	defer func() {
+		doStatAndMetric(proto.AdminVolRemoveDpPin, metric, err, nil)
+		AuditLog(r, proto.AdminVolRemoveDpPin, fmt.Sprintf("vol(%v) path(%v)", name, path), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if path, err = extractDpPinPath(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	oldPins := vol.getDpPins()
+	if err = vol.removeDpPin(path); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setDpPins(oldPins)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogInfof("[removeVolDpPin] vol(%v) unpin path(%v)", name, path)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("unpin path (%v) of volume (%v) success", path, name)))
+}
+
+// addVolReadOnlyWindow adds a window during which the data and meta partitions of the vol are
+// told by heartbeat to refuse modifications, it takes effect in a heartbeat interval.
+func (m *Server) addVolReadOnlyWindow(w http.ResponseWriter, r *http.Request) {
+	var (
+		window *proto.VolReadOnlyWindow
+		name   string
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolAddReadOnlyWindow))
+	defer func() {
+		doStatAndMetric(proto.AdminVolAddReadOnlyWindow, metric, err, nil)
+		AuditLog(r, proto.AdminVolAddReadOnlyWindow, fmt.Sprintf("vol(%v) window(%v)", name, window), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	now := time.Now().Unix()
+	if window, err = parseAndExtractReadOnlyWindow(r, now); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	oldWindows := vol.getReadOnlyWindows()
+	if err = vol.addReadOnlyWindow(window, now); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setReadOnlyWindows(oldWindows)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogWarnf("[addVolReadOnlyWindow] vol(%v) read-only window(%v) from (%v) to (%v) reason(%v)",
+		name, window.ID, window.Start, window.End, window.Reason)
+	sendOkReply(w, r, newSuccessHTTPReply(window))
+}
+
+func (m *Server) listVolReadOnlyWindows(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolListReadOnlyWindows))
+	defer func() {
+		doStatAndMetric(proto.AdminVolListReadOnlyWindows, metric, err, nil)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	windows := vol.getReadOnlyWindows()
+	if windows == nil {
+		windows = make([]*proto.VolReadOnlyWindow, 0)
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(windows))
+}
+
+func (m *Server) cancelVolReadOnlyWindow(w http.ResponseWriter, r *http.Request) {
+	var (
+		id   uint64
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolCancelReadOnlyWindow))
+	defer func() {
+		doStatAndMetric(proto.AdminVolCancelReadOnlyWindow, metric, err, nil)
+		AuditLog(r, proto.AdminVolCancelReadOnlyWindow, fmt.Sprintf("vol(%v) window(%v)", name, id), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if id, err = extractPositiveUint64(r, idKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	oldWindows := vol.getReadOnlyWindows()
+	if err = vol.cancelReadOnlyWindow(id); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setReadOnlyWindows(oldWindows)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogWarnf("[cancelVolReadOnlyWindow] vol(%v) cancel read-only window(%v)", name, id)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("cancel read-only window (%v) of volume (%v) success", id, name)))
+}
+
+// setVolMetaFreeze freezes all the meta partitions of the vol to refuse modifications, or
+// unfreezes them, for the emergencies. The meta nodes are told by heartbeat, so it takes
+// effect in a heartbeat interval.
+func (m *Server) setVolMetaFreeze(w http.ResponseWriter, r *http.Request) {
+	var (
+		name   string
+		freeze bool
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolMetaFreeze))
+	defer func() {
+		doStatAndMetric(proto.AdminVolMetaFreeze, metric, err, nil)
+		AuditLog(r, proto.AdminVolMetaFreeze, fmt.Sprintf("vol(%v) freeze(%v)", name, freeze), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if freeze, err = extractBoolWithDefault(r, freezeKey, true); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	old := vol.metaFrozen.Load()
+	vol.metaFrozen.Store(freeze)
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.metaFrozen.Store(old)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogWarnf("[setVolMetaFreeze] vol(%v) meta freeze from (%v) to (%v)", name, old, freeze)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set meta freeze of volume (%v) to (%v) success", name, freeze)))
+}
+
+// setVolMetaAuth has the meta partitions of the vol refuse the modifying ops without a meta
+// access token issued by /client/metaToken, or stop refusing them. rotate signs the tokens to
+// come by a new key, the tokens signed by the key rotated out are valid until they expire.
+func (m *Server) setVolMetaAuth(w http.ResponseWriter, r *http.Request) {
+	var (
+		name   string
+		enable bool
+		rotate bool
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolMetaAuth))
+	defer func() {
+		doStatAndMetric(proto.AdminVolMetaAuth, metric, err, nil)
+		AuditLog(r, proto.AdminVolMetaAuth, fmt.Sprintf("vol(%v) enable(%v) rotate(%v)", name, enable, rotate), err)
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if enable, err = extractBoolWithDefault(r, enableKey, true); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if rotate, err = extractBoolWithDefault(r, rotateKey, false); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	old := vol.getMetaAccessKeys()
+	var keys []*proto.MetaAccessKey
+	if enable {
+		keys = old
+		if len(old) == 0 || rotate {
+			if keys, err = vol.rotateMetaAccessKey(time.Now().Unix()); err != nil {
+				sendErrReply(w, r, newErrHTTPReply(err))
+				return
+			}
+		}
+	}
+	vol.setMetaAccessKeys(keys)
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setMetaAccessKeys(old)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	keyID := uint32(0)
+	if len(keys) > 0 {
+		keyID = keys[len(keys)-1].ID
+	}
+	log.LogWarnf("[setVolMetaAuth] vol(%v) meta auth enable(%v) key(%v)", name, enable, keyID)
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set meta auth of volume (%v) to (%v) with key (%v) success", name, enable, keyID)))
+}
+
+// setVolPurge pauses, resumes or forces the purge of the inodes deleted of the meta partitions
+// of the vol by the op, and replies the backlog of the purge. Only the backlog is replied
+// without the op.
+func (m *Server) setVolPurge(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		op   string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolPurge))
+	defer func() {
+		doStatAndMetric(proto.AdminVolPurge, metric, err, nil)
+		if op != "" {
+			AuditLog(r, proto.AdminVolPurge, fmt.Sprintf("vol(%v) op(%v)", name, op), err)
+		}
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if op = r.FormValue(OperateKey); op == "" {
+		sendOkReply(w, r, newSuccessHTTPReply(vol.getPurgeStatus()))
+		return
+	}
+
+	old := proto.VolPurgeCtrl{}
+	if ctrl := vol.getPurgeCtrl(); ctrl != nil {
+		old = *ctrl
+	}
+	ctrl := old
+	switch op {
+	case proto.PurgeOpPause:
+		ctrl.Paused = true
+	case proto.PurgeOpResume:
+		ctrl.Paused = false
+	case proto.PurgeOpForce:
+		ctrl.Paused = false
+		ctrl.ForceSeq++
+	default:
+		err = fmt.Errorf("unknown %v %v", OperateKey, op)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vol.setPurgeCtrl(ctrl)
+	if err = m.cluster.syncUpdateVol(vol); err != nil {
+		vol.setPurgeCtrl(old)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	log.LogWarnf("[setVolPurge] vol(%v) purge %v, paused(%v) forceSeq(%v)", name, op, ctrl.Paused, ctrl.ForceSeq)
+	sendOkReply(w, r, newSuccessHTTPReply(vol.getPurgeStatus()))
+}
+
+// getMetaAccessToken issues the meta access token of the vol to the user of the access key,
+// if the user owns the vol or is authorized to write it. The request is signed by
+// proto.MetaTokenRequestSign with the secret key of the user. No token is replied if the vol
+// does not enforce the meta auth.
+func (m *Server) getMetaAccessToken(w http.ResponseWriter, r *http.Request) {
+	var (
+		name     string
+		ak       string
+		sign     string
+		ts       int64
+		userInfo *proto.UserInfo
+		vol      *Vol
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.ClientMetaToken))
+	defer func() {
+		doStatAndMetric(proto.ClientMetaToken, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if ak = r.FormValue(akKey); ak == "" {
+		err = keyNotFound(akKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if sign = r.FormValue(signKey); sign == "" {
+		err = keyNotFound(signKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if ts, err = strconv.ParseInt(r.FormValue(tsKey), 10, 64); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+
+	now := time.Now().Unix()
+	if ts < now-proto.MetaTokenRequestWindow || ts > now+proto.MetaTokenRequestWindow {
+		err = fmt.Errorf("ts %v of the request is not within %vs of now %v", ts, proto.MetaTokenRequestWindow, now)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if userInfo, err = m.user.getKeyInfo(ak); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if !hmac.Equal([]byte(sign), []byte(proto.MetaTokenRequestSign(userInfo.SecretKey, name, ak, ts))) {
+		err = proto.ErrNoPermission
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if !userInfo.Policy.IsOwn(name) && !userInfo.Policy.IsAuthorized(name, "", proto.POSIXWriteAction) {
+		err = proto.ErrNoPermission
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	reply := &proto.MetaAccessTokenReply{}
+	if key := vol.currentMetaAccessKey(); key != nil {
+		token := &proto.MetaAccessToken{Vol: name, UserID: userInfo.UserID, Expire: now + proto.MetaAccessTokenTTL}
+		token.SignBy(key)
+		if reply.Token, err = token.Encode(); err != nil {
+			sendErrReply(w, r, newErrHTTPReply(err))
+			return
+		}
+		reply.Expire = token.Expire
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(reply))
+}
+
+func (m *Server) volClientKeepAlive(w http.ResponseWriter, r *http.Request) {
+	var (
+		client *proto.VolClientInfo
+		name   string
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolClientKeepAlive))
+	defer func() {
+		doStatAndMetric(proto.AdminVolClientKeepAlive, metric, err, map[string]string{exporter.Vol: name})
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if client, err = parseVolClientInfo(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.volClientKeepAlive(vol, client); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply("success"))
+}
+
+func (m *Server) getVolClients(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		err  error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolClients))
+	defer func() {
+		doStatAndMetric(proto.AdminVolClients, metric, err, map[string]string{exporter.Vol: name})
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.listVolClients(vol)))
+}
+
+// createVolReplica creates a read-only replica of the source vol, whose metadata is synced
+// from the source every sync interval and whose data is read from the source.
+func (m *Server) createVolReplica(w http.ResponseWriter, r *http.Request) {
+	var (
+		name     string
+		owner    string
+		source   string
+		authKey  string
+		interval int64
+		src      *Vol
+		vol      *Vol
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminCreateVolReplica))
+	defer func() {
+		doStatAndMetric(proto.AdminCreateVolReplica, metric, err, map[string]string{exporter.Vol: name})
+		AuditLog(r, proto.AdminCreateVolReplica, fmt.Sprintf("create vol[%v] replica of [%v]", name, source), err)
+	}()
+	if name, owner, source, authKey, interval, err = parseRequestToCreateVolReplica(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if src, err = m.cluster.getVol(source); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+	if !matchKey(src.Owner, authKey) {
+		err = proto.ErrVolAuthKeyNotMatch
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if vol, err = m.cluster.createVolReplica(name, owner, source, interval); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if err = m.associateVolWithUser(owner, name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if _, err = m.cluster.syncVolReplica(vol, true); err != nil {
+		log.LogWarnf("action[createVolReplica] start the first sync of vol[%v] err[%v]", name, err)
+		err = nil
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("create vol[%v] replica of [%v] successfully", name, source)))
+}
+
+func (m *Server) getVolReplicaStatus(w http.ResponseWriter, r *http.Request) {
+	m.doVolReplicaSync(w, r, proto.AdminGetVolReplicaStatus, false)
+}
+
+func (m *Server) syncVolReplica(w http.ResponseWriter, r *http.Request) {
+	m.doVolReplicaSync(w, r, proto.AdminSyncVolReplica, true)
+}
+
+func (m *Server) doVolReplicaSync(w http.ResponseWriter, r *http.Request, api string, start bool) {
+	var (
+		name   string
+		vol    *Vol
+		status *proto.VolReplicaStatus
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(api))
+	defer func() {
+		doStatAndMetric(api, metric, err, map[string]string{exporter.Vol: name})
+		if start {
+			AuditLog(r, api, fmt.Sprintf("sync vol[%v] replica", name), err)
+		}
+	}()
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if !vol.isVolReplica() {
+		err = fmt.Errorf("vol[%v] is not a replica", name)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if status, err = m.cluster.syncVolReplica(vol, start); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(status))
+}
+
+func (m *Server) listVolReplicas(w http.ResponseWriter, r *http.Request) {
+	var (
+		source string
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminListVolReplicas))
+	defer func() {
+		doStatAndMetric(proto.AdminListVolReplicas, metric, err, map[string]string{exporter.Vol: source})
+	}()
+	if source = extractStr(r, sourceVolKey); source == "" {
+		err = keyNotFound(sourceVolKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if _, err = m.cluster.getVol(source); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.volReplicas(source)))
+}
+
 func (m *Server) checkReplicaMeta(w http.ResponseWriter, r *http.Request) {
 	var resp proto.BadReplicaMetaResponse
 
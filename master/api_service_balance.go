@@ -224,6 +224,78 @@ func (m *Server) cleanEmptyMetaPartition(w http.ResponseWriter, r *http.Request)
 	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("Clean frozen meta partition for volume (%s) in the background. It may takes several hours. task id: %s", name, name)))
 }
 
+func (m *Server) mergeEmptyMetaPartition(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminMetaPartitionMergeEmpty))
+	defer func() {
+		doStatAndMetric(proto.AdminMetaPartitionMergeEmpty, metric, nil, nil)
+	}()
+
+	var (
+		name   string
+		count  int
+		dryRun bool
+		err    error
+	)
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if name, err = extractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if value := r.FormValue(countKey); value != "" {
+		if count, err = strconv.Atoi(value); err != nil || count < 0 {
+			sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: fmt.Sprintf("invalid %s: %s", countKey, value)})
+			return
+		}
+	}
+
+	if dryRun, err = extractBoolWithDefault(r, dryRunKey, true); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	vol, err := m.cluster.getVol(name)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if vol.Status == proto.VolStatusMarkDelete {
+		sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("volume (%s) is deleted already.", name)))
+		return
+	}
+
+	pairs := m.cluster.getEmptyMetaPartitionMergePairs(vol, count)
+	view := &proto.VolEmptyMpMerge{Name: name, DryRun: dryRun, Pairs: make([]proto.EmptyMpMergePair, 0, len(pairs))}
+	for _, pair := range pairs {
+		view.Pairs = append(view.Pairs, pair.view())
+	}
+	if dryRun {
+		sendOkReply(w, r, newSuccessHTTPReply(view))
+		return
+	}
+	if len(pairs) == 0 {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: "no empty meta partition to merge"})
+		return
+	}
+
+	err = m.cluster.MergeEmptyMetaPartitionJob(vol, pairs)
+
+	rstMsg := fmt.Sprintf("Merge empty volume(%s) meta partitions(%d)", name, len(pairs))
+	AuditLog(r, "mergeEmptyMetaPartition", rstMsg, err)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeInternalError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(view))
+}
+
 func (m *Server) removeBackupMetaPartition(w http.ResponseWriter, r *http.Request) {
 	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminMetaPartitionRemoveBackup))
 	defer func() {
@@ -27,6 +27,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1759,6 +1760,37 @@ func TestSetMarkDiskBrokenThreshold(t *testing.T) {
 	require.EqualValues(t, oldVal, server.cluster.getMarkDiskBrokenThreshold())
 }
 
+func TestSetMetaSnapshotPersistenceMode(t *testing.T) {
+	reqUrl := fmt.Sprintf("%v%v", hostAddr, proto.AdminSetNodeInfo)
+	setUrl := fmt.Sprintf("%v?%v=%v&dirSizeLimit=0", reqUrl, metaSnapshotPersistenceModeKey, "writeBack")
+	unsetUrl := fmt.Sprintf("%v?%v=%v&dirSizeLimit=0", reqUrl, metaSnapshotPersistenceModeKey, "writeThrough")
+	process(setUrl, t)
+	require.EqualValues(t, proto.PersistenceModeWriteBack, atomic.LoadUint32(&server.cluster.cfg.MetaSnapshotPersistenceMode))
+	info, err := mc.AdminAPI().GetClusterInfo()
+	require.NoError(t, err)
+	require.EqualValues(t, proto.PersistenceModeWriteBack, info.MetaSnapshotPersistenceMode)
+	reply := processNoCheck(fmt.Sprintf("%v?%v=%v&dirSizeLimit=0", reqUrl, metaSnapshotPersistenceModeKey, "lazy"), t)
+	require.NotEqual(t, proto.ErrCodeSuccess, reply.Code)
+	process(unsetUrl, t)
+	require.EqualValues(t, proto.PersistenceModeWriteThrough, atomic.LoadUint32(&server.cluster.cfg.MetaSnapshotPersistenceMode))
+}
+
+func TestSetMetaSnapshotRates(t *testing.T) {
+	reqUrl := fmt.Sprintf("%v%v?dirSizeLimit=0", hostAddr, proto.AdminSetNodeInfo)
+	process(fmt.Sprintf("%v&%v=100&%v=50&%v=10", reqUrl, metaSnapshotSendRateKey, metaSnapshotRecvRateKey,
+		metaSnapshotPartitionRateKey), t)
+	info, err := mc.AdminAPI().GetClusterInfo()
+	require.NoError(t, err)
+	require.EqualValues(t, 100, info.MetaSnapshotSendRateMB)
+	require.EqualValues(t, 50, info.MetaSnapshotRecvRateMB)
+	require.EqualValues(t, 10, info.MetaSnapshotPartitionRateMB)
+	reply := processNoCheck(fmt.Sprintf("%v&%v=fast", reqUrl, metaSnapshotSendRateKey), t)
+	require.NotEqual(t, proto.ErrCodeSuccess, reply.Code)
+	process(fmt.Sprintf("%v&%v=0&%v=0&%v=0", reqUrl, metaSnapshotSendRateKey, metaSnapshotRecvRateKey,
+		metaSnapshotPartitionRateKey), t)
+	require.Zero(t, atomic.LoadUint64(&server.cluster.cfg.MetaSnapshotSendRateMB))
+}
+
 func TestSetEnableAutoDecommissionDisk(t *testing.T) {
 	reqUrl := fmt.Sprintf("%v%v", hostAddr, proto.AdminSetNodeInfo)
 	oldVal := server.cluster.EnableAutoDecommissionDisk.Load()
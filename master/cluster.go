@@ -106,6 +106,8 @@ type ClusterDecommission struct {
 	BadDataPartitionIds                    *sync.Map
 	BadMetaPartitionIds                    *sync.Map
 	DecommissionDisks                      sync.Map
+	zoneEvacuations                        sync.Map // zone name -> *zoneEvacuation
+	mpNodeSetMigrations                    sync.Map // meta partition id -> target nodeset id
 	DataNodeToDecommissionRepairDpMap      sync.Map
 	NoSamePeerDps                          sync.Map
 	DecommissionFirstHostDiskParallelLimit uint64
@@ -130,6 +132,7 @@ type CleanTask struct {
 	FreezeCnt int       `json:"-"`
 	CleanCnt  int       `json:"-"`
 	ResetCnt  int       `json:"-"`
+	MergeCnt  int       `json:"-"`
 	Timeout   time.Time `json:"-"`
 	UnFreeze  int       `json:"unfreeze"`
 	Freezing  int       `json:"freezing"`
@@ -171,6 +174,7 @@ type Cluster struct {
 	apiLimiter     *ApiLimiter
 
 	followerReadManager *followerReadManager
+	clusterSnapshots    *clusterSnapshots
 	lcMgr               *lifecycleManager
 	snapshotMgr         *snapshotDelManager
 
@@ -301,7 +305,7 @@ func (mgr *followerReadManager) sendFollowerVolumeDpView() {
 		time.Sleep(avgSleepTime)
 
 		var body []byte
-		if body, err = vol.getDataPartitionsView(); err != nil {
+		if body, err = mgr.c.getVolDataPartitionsView(vol, false); err != nil {
 			log.LogErrorf("followerReadManager.sendFollowerVolumeDpView err %v", err)
 			continue
 		}
@@ -454,6 +458,7 @@ func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition
 	c.FaultDomain = cfg.faultDomain
 	c.zoneStatInfos = make(map[string]*proto.ZoneStat)
 	c.followerReadManager = newFollowerReadManager(c)
+	c.clusterSnapshots = newClusterSnapshots(defaultMaxClusterSnapshots)
 	c.fsm = fsm
 	c.partition = partition
 	c.idAlloc = newIDAllocator(c.fsm.store, c.partition)
@@ -515,6 +520,7 @@ func (c *Cluster) scheduleTask() {
 	c.scheduleToUpdateFlashGroupSlots()
 	c.scheduleToCheckDataPartitionRepairingStatus()
 	c.scheduleToCheckDataPartitionDecommissionDiskRetryMap()
+	c.scheduleToSyncVolReplicas()
 }
 
 func (c *Cluster) masterAddr() (addr string) {
@@ -899,6 +905,7 @@ func (c *Cluster) scheduleToCheckHeartbeat() {
 
 func (c *Cluster) checkDataNodeHeartbeat() {
 	tasks := make([]*proto.AdminTask, 0)
+	now := time.Now().Unix()
 	id := uuid.New()
 	log.LogDebugf("checkDataNodeHeartbeat start %v", id.String())
 	c.dataNodes.Range(func(addr, dataNode interface{}) bool {
@@ -931,6 +938,9 @@ func (c *Cluster) checkDataNodeHeartbeat() {
 			if vol.ForbidWriteOpOfProtoVer0.Load() {
 				hbReq.VolsForbidWriteOpOfProtoVer0 = append(hbReq.VolsForbidWriteOpOfProtoVer0, vol.Name)
 			}
+			if vol.inReadOnlyWindow(now) {
+				hbReq.ReadOnlyVols = append(hbReq.ReadOnlyVols, vol.Name)
+			}
 		}
 		tasks = append(tasks, task)
 		return true
@@ -942,6 +952,7 @@ func (c *Cluster) checkDataNodeHeartbeat() {
 
 func (c *Cluster) checkMetaNodeHeartbeat() {
 	tasks := make([]*proto.AdminTask, 0)
+	now := time.Now().Unix()
 
 	c.metaNodes.Range(func(addr, metaNode interface{}) bool {
 		node := metaNode.(*MetaNode)
@@ -965,6 +976,42 @@ func (c *Cluster) checkMetaNodeHeartbeat() {
 			if vol.ForbidWriteOpOfProtoVer0.Load() {
 				hbReq.VolsForbidWriteOpOfProtoVer0 = append(hbReq.VolsForbidWriteOpOfProtoVer0, vol.Name)
 			}
+			if vol.inReadOnlyWindow(now) {
+				hbReq.ReadOnlyVols = append(hbReq.ReadOnlyVols, vol.Name)
+			}
+			if vol.metaFrozen.Load() {
+				hbReq.MetaFrozenVols = append(hbReq.MetaFrozenVols, vol.Name)
+			}
+			if keys := vol.getMetaAccessKeys(); len(keys) > 0 {
+				if hbReq.VolMetaAccessKeys == nil {
+					hbReq.VolMetaAccessKeys = make(map[string][]*proto.MetaAccessKey)
+				}
+				hbReq.VolMetaAccessKeys[vol.Name] = keys
+			}
+			if ctrl := vol.getPurgeCtrl(); ctrl != nil {
+				if hbReq.VolPurgeCtrls == nil {
+					hbReq.VolPurgeCtrls = make(map[string]*proto.VolPurgeCtrl)
+				}
+				hbReq.VolPurgeCtrls[vol.Name] = ctrl
+			}
+			if quota := vol.getMetaOpQuota(); quota != nil {
+				if hbReq.VolMetaOpQuotas == nil {
+					hbReq.VolMetaOpQuotas = make(map[string]*proto.MetaOpQuota)
+				}
+				hbReq.VolMetaOpQuotas[vol.Name] = quota
+			}
+			if xattrs := vol.getDefaultXAttrs(); len(xattrs) > 0 {
+				if hbReq.VolDefaultXAttrs == nil {
+					hbReq.VolDefaultXAttrs = make(map[string]map[string]string)
+				}
+				hbReq.VolDefaultXAttrs[vol.Name] = xattrs
+			}
+			if weight := vol.getMetaWorkerWeight(); weight > 0 {
+				if hbReq.VolMetaWorkerWeights == nil {
+					hbReq.VolMetaWorkerWeights = make(map[string]int32)
+				}
+				hbReq.VolMetaWorkerWeights[vol.Name] = weight
+			}
 
 			spaceInfo := vol.uidSpaceManager.getSpaceOp()
 			hbReq.UidLimitInfo = append(hbReq.UidLimitInfo, spaceInfo...)
@@ -1791,7 +1838,12 @@ func (c *Cluster) markDeleteVol(name, authKey string, force bool, isNotCancel bo
 		return
 	}
 
-	if !c.cfg.volForceDeletion {
+	if err = c.checkVolReplicasBeforeDelete(vol); err != nil {
+		return
+	}
+
+	// the dentries of a replica vol are synced from the source
+	if !c.cfg.volForceDeletion && !vol.isVolReplica() {
 		volDentryCount := uint64(0)
 		mpsCopy := vol.cloneMetaPartitionMap()
 		for _, mp := range mpsCopy {
@@ -2056,10 +2108,19 @@ func (c *Cluster) syncCreateDataPartitionToDataNode(host string, size uint64, dp
 	return string(resp.Data), nil
 }
 
+// getVolDefaultXAttrs returns the default xattrs carried by the requests of creating meta partitions of the vol.
+func (c *Cluster) getVolDefaultXAttrs(volName string) map[string]string {
+	vol, err := c.getVol(volName)
+	if err != nil {
+		return nil
+	}
+	return vol.getDefaultXAttrs()
+}
+
 func (c *Cluster) syncCreateMetaPartitionToMetaNode(host string, mp *MetaPartition) (err error) {
 	hosts := make([]string, 0)
 	hosts = append(hosts, host)
-	tasks := mp.buildNewMetaPartitionTasks(hosts, mp.Peers, mp.volName)
+	tasks := mp.buildNewMetaPartitionTasks(hosts, mp.Peers, mp.volName, c.getVolDefaultXAttrs(mp.volName))
 	metaNode, err := c.metaNode(host)
 	if err != nil {
 		return
@@ -4099,6 +4160,7 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 		TrashInterval:           req.trashInterval,
 		AccessTimeInterval:      req.accessTimeValidInterval,
 		EnablePersistAccessTime: req.enablePersistAccessTime,
+		AtimeMode:               req.atimeMode,
 
 		VolStorageClass:     req.volStorageClass,
 		AllowedStorageClass: req.allowedStorageClass,
@@ -4114,6 +4176,9 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 		FlashNodeTimeoutCount:        req.flashNodeTimeoutCount,
 		RemoteCacheSameZoneTimeout:   req.remoteCacheSameZoneTimeout,
 		RemoteCacheSameRegionTimeout: req.remoteCacheSameRegionTimeout,
+
+		SourceVol:           req.sourceVol,
+		ReplicaSyncInterval: req.replicaSyncInterval,
 	}
 
 	vv.QuotaOfClass = make([]*proto.StatOfStorageClass, 0)
@@ -4587,6 +4652,31 @@ func (c *Cluster) setMetaNodeDeleteWorkerSleepMs(val uint64) (err error) {
 	return
 }
 
+// setMetaSnapshotRate sets one of the snapshot transfer rates of the metanodes in cfg.
+func (c *Cluster) setMetaSnapshotRate(rate *uint64, val uint64) (err error) {
+	oldVal := atomic.LoadUint64(rate)
+	atomic.StoreUint64(rate, val)
+	if err = c.syncPutCluster(); err != nil {
+		log.LogErrorf("action[setMetaSnapshotRate] err[%v]", err)
+		atomic.StoreUint64(rate, oldVal)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	return
+}
+
+func (c *Cluster) setMetaSnapshotPersistenceMode(val uint32) (err error) {
+	oldVal := atomic.LoadUint32(&c.cfg.MetaSnapshotPersistenceMode)
+	atomic.StoreUint32(&c.cfg.MetaSnapshotPersistenceMode, val)
+	if err = c.syncPutCluster(); err != nil {
+		log.LogErrorf("action[setMetaSnapshotPersistenceMode] err[%v]", err)
+		atomic.StoreUint32(&c.cfg.MetaSnapshotPersistenceMode, oldVal)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	return
+}
+
 func (c *Cluster) getMaxDpCntLimit() (dpCntInLimit uint64) {
 	dpCntInLimit = atomic.LoadUint64(&clusterDpCntLimit)
 	return
@@ -40,7 +40,7 @@ func (c *Cluster) FreezeEmptyMetaPartitionJob(name string, freezeList []*MetaPar
 	c.mu.Lock()
 	task, ok := c.cleanTask[name]
 	if ok {
-		if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping {
+		if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping || task.Status == CleanTaskMerging {
 			c.mu.Unlock()
 			return fmt.Errorf("The clean task for volume(%s) is %s", name, task.Status)
 		}
@@ -119,7 +119,7 @@ func (c *Cluster) StartCleanEmptyMetaPartition(name string) error {
 	c.mu.Lock()
 	task, ok := c.cleanTask[name]
 	if ok {
-		if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping {
+		if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping || task.Status == CleanTaskMerging {
 			c.mu.Unlock()
 			return fmt.Errorf("The clean task for volume(%s) is %s", name, task.Status)
 		}
@@ -237,6 +237,215 @@ func (c *Cluster) CleanEmptyMetaPartition(mp *MetaPartition) error {
 	return nil
 }
 
+// mpMergePair is a pair of adjacent meta partitions, the empty higher one of which is merged
+// into the lower one.
+type mpMergePair struct {
+	lower  *MetaPartition
+	higher *MetaPartition
+}
+
+func (pair *mpMergePair) view() proto.EmptyMpMergePair {
+	return proto.EmptyMpMergePair{
+		LowerID:     pair.lower.PartitionID,
+		LowerStart:  pair.lower.Start,
+		LowerEnd:    pair.lower.End,
+		HigherID:    pair.higher.PartitionID,
+		HigherStart: pair.higher.Start,
+		HigherEnd:   pair.higher.End,
+	}
+}
+
+func canMergeEmptyMetaPartition(lower, higher *MetaPartition, maxPartitionID uint64) bool {
+	if higher.PartitionID == maxPartitionID || lower.End+1 != higher.Start {
+		return false
+	}
+	if !higher.IsEmptyToBeClean() || higher.FreeListLen != 0 {
+		return false
+	}
+	if lower.IsMetaPartitionFreezed() || higher.IsMetaPartitionFreezed() || lower.IsRecover || higher.IsRecover {
+		return false
+	}
+	return lower.Status != proto.Unavailable && higher.Status != proto.Unavailable
+}
+
+// getEmptyMetaPartitionMergePairs returns up to count pairs of the volume to merge, all of them
+// if count is 0. Each partition is in one pair at most, and RsvEmptyMetaPartitionCnt empty
+// partitions are kept for the new inodes.
+func (c *Cluster) getEmptyMetaPartitionMergePairs(vol *Vol, count int) (pairs []*mpMergePair) {
+	pairs = make([]*mpMergePair, 0)
+	mps := vol.getSortMetaPartitions()
+	maxPartitionID := vol.maxMetaPartitionID()
+	empty := 0
+	for _, mp := range mps {
+		if mp.IsEmptyToBeClean() && !mp.IsMetaPartitionFreezed() {
+			empty++
+		}
+	}
+	for i := 0; i+1 < len(mps); i++ {
+		if empty-len(pairs) <= RsvEmptyMetaPartitionCnt || (count > 0 && len(pairs) >= count) {
+			break
+		}
+		if !canMergeEmptyMetaPartition(mps[i], mps[i+1], maxPartitionID) {
+			continue
+		}
+		pairs = append(pairs, &mpMergePair{lower: mps[i], higher: mps[i+1]})
+		i++
+	}
+	return
+}
+
+// MergeEmptyMetaPartitionJob sets the higher partitions of the pairs read only, and merges them
+// into the lower ones once the clients have updated the partitions of the volume.
+func (c *Cluster) MergeEmptyMetaPartitionJob(vol *Vol, pairs []*mpMergePair) error {
+	if vol.isVolReplica() || len(c.volReplicas(vol.Name)) > 0 {
+		return fmt.Errorf("the meta partitions of volume(%s) are mirrored by its replicas", vol.Name)
+	}
+	name := vol.Name
+	c.mu.Lock()
+	task, ok := c.cleanTask[name]
+	if ok {
+		if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping || task.Status == CleanTaskMerging {
+			c.mu.Unlock()
+			return fmt.Errorf("The clean task for volume(%s) is %s", name, task.Status)
+		}
+		task.Status = CleanTaskMerging
+		task.TaskCnt += len(pairs)
+	} else {
+		task = &CleanTask{
+			Name:    name,
+			Status:  CleanTaskMerging,
+			TaskCnt: len(pairs),
+		}
+		c.cleanTask[name] = task
+	}
+	c.mu.Unlock()
+
+	mergeList := make([]*mpMergePair, 0, len(pairs))
+	for _, pair := range pairs {
+		mp := pair.higher
+		mp.Freeze = proto.FreezingMetaPartition
+		if mp.Status == proto.ReadWrite {
+			mp.Status = proto.ReadOnly
+		}
+		if err := c.syncUpdateMetaPartition(mp); err != nil {
+			log.LogErrorf("volume(%s) meta partition(%d) update failed: %s", name, mp.PartitionID, err.Error())
+			continue
+		}
+		mergeList = append(mergeList, pair)
+	}
+
+	go func() {
+		// waiting for client to update meta partition 10 minutes.
+		time.Sleep(WaitForClientUpdateTimeMin * time.Minute)
+
+		for _, pair := range mergeList {
+			if err := c.mergeEmptyMetaPartition(vol, pair.lower, pair.higher); err != nil {
+				log.LogErrorf("Failed to merge volume(%s) meta partition(%d) into (%d), error: %s",
+					name, pair.higher.PartitionID, pair.lower.PartitionID, err.Error())
+				task.ResetCnt += 1
+				continue
+			}
+			task.MergeCnt += 1
+		}
+
+		task.Status = CleanTaskMerged
+		task.Timeout = time.Now().Add(WaitForTaskDeleteByHour * time.Hour)
+		c.mu.Lock()
+		if !c.Cleaning {
+			go c.DeleteCleanTasks()
+			c.Cleaning = true
+		}
+		c.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// mergeEmptyMetaPartition extends the End of lower to the one of the empty higher partition and
+// retires higher. higher is frozen on the metanodes first, which fails if it is written, and the
+// extended lower and the deleted higher are committed together. The lower partition goes on
+// allocating inodes from its cursor, the ones in the range of higher are allocated long after
+// the clients have dropped it.
+func (c *Cluster) mergeEmptyMetaPartition(vol *Vol, lower, higher *MetaPartition) (err error) {
+	vol.createMpMutex.Lock()
+	defer vol.createMpMutex.Unlock()
+
+	if _, err = vol.metaPartition(higher.PartitionID); err != nil {
+		return
+	}
+	frozen := false
+	defer func() {
+		if err != nil {
+			c.resetMergingMetaPartition(higher, frozen)
+		}
+	}()
+
+	if _, err = vol.metaPartition(lower.PartitionID); err != nil {
+		return
+	}
+	if higher.PartitionID == vol.maxMetaPartitionID() || lower.End+1 != higher.Start {
+		return fmt.Errorf("meta partition(%d) is not next to (%d)", higher.PartitionID, lower.PartitionID)
+	}
+	if higher.InodeCount != 0 || higher.DentryCount != 0 || higher.FreeListLen != 0 {
+		return fmt.Errorf("meta partition(%d) is not empty", higher.PartitionID)
+	}
+	if _, err = lower.getMetaReplicaLeader(); err != nil {
+		return
+	}
+
+	if err = c.FreezeEmptyMetaPartition(higher, true); err != nil {
+		return
+	}
+	frozen = true
+	higher.Freeze = proto.FreezedMetaPartition
+
+	lower.Lock()
+	oldEnd := lower.End
+	lower.End = higher.End
+	cmdMap := make(map[string]*RaftCmd)
+	updateCmd, err := c.buildMetaPartitionRaftCmd(opSyncUpdateMetaPartition, lower)
+	if err == nil {
+		cmdMap[updateCmd.K] = updateCmd
+		var deleteCmd *RaftCmd
+		if deleteCmd, err = c.buildMetaPartitionRaftCmd(opSyncDeleteMetaPartition, higher); err == nil {
+			cmdMap[deleteCmd.K] = deleteCmd
+			err = c.syncBatchCommitCmd(cmdMap)
+		}
+	}
+	if err != nil {
+		lower.End = oldEnd
+		lower.Unlock()
+		return
+	}
+	lower.updateInodeIDRangeForAllReplicas()
+	lower.addUpdateMetaReplicaTask(c)
+	lower.Unlock()
+
+	vol.mpsLock.Lock()
+	delete(vol.MetaPartitions, higher.PartitionID)
+	vol.mpsLock.UnLock()
+
+	c.CleanEmptyMetaPartition(higher)
+	log.LogWarnf("action[mergeEmptyMetaPartition] vol(%s) meta partition(%d) merged into (%d), end from %d to %d",
+		vol.Name, higher.PartitionID, lower.PartitionID, oldEnd, lower.End)
+	return nil
+}
+
+// resetMergingMetaPartition takes back the partition not merged for the new inodes.
+func (c *Cluster) resetMergingMetaPartition(mp *MetaPartition, frozen bool) {
+	if frozen {
+		if err := c.FreezeEmptyMetaPartition(mp, false); err != nil {
+			log.LogErrorf("Failed to unfreeze volume(%s) meta partition(%d), error: %s", mp.volName, mp.PartitionID, err.Error())
+			return
+		}
+	}
+	mp.Freeze = proto.FreezeMetaPartitionInit
+	mp.Status = proto.ReadWrite
+	if err := c.syncUpdateMetaPartition(mp); err != nil {
+		log.LogErrorf("volume(%s) meta partition(%d) update failed: %s", mp.volName, mp.PartitionID, err.Error())
+	}
+}
+
 func (c *Cluster) DeleteCleanTasks() {
 	ticker := time.NewTicker(time.Hour)
 	defer ticker.Stop()
@@ -248,7 +457,7 @@ func (c *Cluster) DeleteCleanTasks() {
 			now := time.Now()
 			keysToDelete := make([]string, 0, len(c.cleanTask))
 			for key, task := range c.cleanTask {
-				if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping {
+				if task.Status == CleanTaskFreezing || task.Status == CleanTaskBackuping || task.Status == CleanTaskMerging {
 					continue
 				}
 				if task.Timeout.Before(now) {
@@ -0,0 +1,379 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	clusterConfigKindZone       = "zone"
+	clusterConfigKindUser       = "user"
+	clusterConfigKindVol        = "vol"
+	clusterConfigKindUserPolicy = "userPolicy"
+	clusterConfigKindNodeInfo   = "nodeInfo"
+)
+
+func (m *Server) clusterConfig() (conf *proto.ClusterConfig) {
+	conf = &proto.ClusterConfig{
+		Version:    proto.ClusterConfigVersion,
+		Cluster:    m.cluster.Name,
+		CreateTime: time.Now().Format(proto.TimeFormat),
+		Zones:      make([]*proto.ClusterConfigZone, 0),
+		NodeInfo:   m.nodeInfo(),
+		Vols:       make([]*proto.ClusterConfigVol, 0),
+		Users:      m.user.getAllUserInfo(""),
+	}
+	for _, zv := range m.topologyView().Zones {
+		zone := &proto.ClusterConfigZone{
+			Name:          zv.Name,
+			Status:        zv.Status,
+			DataMediaType: zv.DataMediaType,
+			NodeSets:      make([]*proto.ClusterConfigNodeSet, 0, len(zv.NodeSet)),
+		}
+		for id, nsv := range zv.NodeSet {
+			ns := &proto.ClusterConfigNodeSet{ID: id, DataNodes: make([]string, 0), MetaNodes: make([]string, 0)}
+			for _, node := range nsv.DataNodes {
+				ns.DataNodes = append(ns.DataNodes, node.Addr)
+			}
+			for _, node := range nsv.MetaNodes {
+				ns.MetaNodes = append(ns.MetaNodes, node.Addr)
+			}
+			sort.Strings(ns.DataNodes)
+			sort.Strings(ns.MetaNodes)
+			zone.NodeSets = append(zone.NodeSets, ns)
+		}
+		sort.Slice(zone.NodeSets, func(i, j int) bool { return zone.NodeSets[i].ID < zone.NodeSets[j].ID })
+		conf.Zones = append(conf.Zones, zone)
+	}
+	sort.Slice(conf.Zones, func(i, j int) bool { return conf.Zones[i].Name < conf.Zones[j].Name })
+	for _, vol := range m.cluster.allVols() {
+		// a vol being deleted is not rebuilt
+		if vol.Status == proto.VolStatusMarkDelete {
+			continue
+		}
+		conf.Vols = append(conf.Vols, &proto.ClusterConfigVol{
+			SimpleVolView: newSimpleView(vol),
+			DpSize:        vol.dataPartitionSize / util.GB,
+		})
+	}
+	sort.Slice(conf.Vols, func(i, j int) bool { return conf.Vols[i].Name < conf.Vols[j].Name })
+	sort.Slice(conf.Users, func(i, j int) bool { return conf.Users[i].UserID < conf.Users[j].UserID })
+	return
+}
+
+// createVolForm returns the form of the vol created by /admin/createVol, so that the import
+// creates the vol with the checks and the defaults of it.
+func createVolForm(vol *proto.ClusterConfigVol) url.Values {
+	form := url.Values{}
+	form.Set(nameKey, vol.Name)
+	form.Set(volOwnerKey, vol.Owner)
+	form.Set(metaPartitionCountKey, strconv.Itoa(vol.MpCnt))
+	form.Set(replicaNumKey, strconv.Itoa(int(vol.DpReplicaNum)))
+	form.Set(dataPartitionSizeKey, strconv.FormatUint(vol.DpSize, 10))
+	form.Set(volCapacityKey, strconv.FormatUint(vol.Capacity, 10))
+	form.Set(volDeleteLockTimeKey, strconv.FormatInt(vol.DeleteLockTime, 10))
+	form.Set(volStorageClassKey, strconv.FormatUint(uint64(vol.VolStorageClass), 10))
+	form.Set(followerReadKey, strconv.FormatBool(vol.FollowerRead))
+	form.Set(proto.MetaFollowerReadKey, strconv.FormatBool(vol.MetaFollowerRead))
+	form.Set(proto.MaximallyReadKey, strconv.FormatBool(vol.MaximallyRead))
+	form.Set(authenticateKey, strconv.FormatBool(vol.Authenticate))
+	form.Set(crossZoneKey, strconv.FormatBool(vol.CrossZone))
+	form.Set(zoneNameKey, vol.ZoneName)
+	form.Set(descriptionKey, vol.Description)
+	form.Set(enablePosixAclKey, strconv.FormatBool(vol.EnablePosixAcl))
+	form.Set(dpReadOnlyWhenVolFull, strconv.FormatBool(vol.DpReadOnlyWhenVolFull))
+	form.Set(enableTxMaskKey, vol.EnableTransactionV1)
+	// zero is taken as out of range by the creation rather than its default
+	if vol.TxTimeout > 0 {
+		form.Set(txTimeoutKey, strconv.FormatInt(vol.TxTimeout, 10))
+	}
+	if vol.TxConflictRetryNum > 0 {
+		form.Set(txConflictRetryNumKey, strconv.FormatInt(vol.TxConflictRetryNum, 10))
+	}
+	if vol.TxConflictRetryInterval > 0 {
+		form.Set(txConflictRetryIntervalKey, strconv.FormatInt(vol.TxConflictRetryInterval, 10))
+	}
+	form.Set(enableQuota, strconv.FormatBool(vol.EnableQuota))
+	form.Set(trashIntervalKey, strconv.FormatInt(vol.TrashInterval, 10))
+	form.Set(accessTimeIntervalKey, strconv.FormatInt(vol.AccessTimeInterval, 10))
+	form.Set(enablePersistAccessTimeKey, strconv.FormatBool(vol.EnablePersistAccessTime))
+	form.Set(atimeModeKey, vol.AtimeMode)
+	if proto.IsCold(vol.VolType) {
+		form.Set(ebsBlkSizeKey, strconv.Itoa(vol.ObjBlockSize))
+	}
+	classes := make([]string, 0, len(vol.AllowedStorageClass))
+	for _, class := range vol.AllowedStorageClass {
+		classes = append(classes, strconv.FormatUint(uint64(class), 10))
+	}
+	form.Set(allowedStorageClassKey, strings.Join(classes, ","))
+	form.Set(remoteCacheEnable, strconv.FormatBool(vol.RemoteCacheEnable))
+	form.Set(remoteCachePath, vol.RemoteCachePath)
+	form.Set(remoteCacheAutoPrepare, strconv.FormatBool(vol.RemoteCacheAutoPrepare))
+	form.Set(remoteCacheTTL, strconv.FormatInt(vol.RemoteCacheTTL, 10))
+	form.Set(remoteCacheReadTimeout, strconv.FormatInt(vol.RemoteCacheReadTimeout, 10))
+	form.Set(remoteCacheMaxFileSizeGB, strconv.FormatInt(vol.RemoteCacheMaxFileSizeGB, 10))
+	form.Set(remoteCacheOnlyForNotSSD, strconv.FormatBool(vol.RemoteCacheOnlyForNotSSD))
+	form.Set(remoteCacheMultiRead, strconv.FormatBool(vol.RemoteCacheMultiRead))
+	form.Set(flashNodeTimeoutCount, strconv.FormatInt(vol.FlashNodeTimeoutCount, 10))
+	form.Set(remoteCacheSameZoneTimeout, strconv.FormatInt(vol.RemoteCacheSameZoneTimeout, 10))
+	form.Set(remoteCacheSameRegionTimeout, strconv.FormatInt(vol.RemoteCacheSameRegionTimeout, 10))
+	return form
+}
+
+func newFormRequest(path string, form url.Values) (r *http.Request, err error) {
+	if r, err = http.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode())); err != nil {
+		return
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return
+}
+
+func (m *Server) parseImportedVol(vol *proto.ClusterConfigVol) (req *createVolReq, err error) {
+	if vol.SimpleVolView == nil {
+		return nil, fmt.Errorf("no settings of the vol")
+	}
+	r, err := newFormRequest(proto.AdminCreateVol, createVolForm(vol))
+	if err != nil {
+		return
+	}
+	req = &createVolReq{}
+	if err = parseRequestToCreateVol(r, req); err != nil {
+		return
+	}
+	if err = m.checkCreateVolReq(req); err != nil {
+		return
+	}
+	if req.zoneName != "" {
+		for _, zone := range strings.Split(req.zoneName, ",") {
+			if _, err = m.cluster.t.getZone(zone); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// importVol creates the vol with the settings not taken by the creation applied after.
+func (m *Server) importVol(vol *proto.ClusterConfigVol, req *createVolReq) (err error) {
+	var created *Vol
+	if created, err = m.cluster.createVol(req); err != nil {
+		return
+	}
+	if err = m.associateVolWithUser(req.owner, req.name); err != nil {
+		return
+	}
+	created.xattrLimit = vol.XAttrLimit
+	created.extentConflictPolicy = vol.ExtentConflictPolicy
+	created.enableOpAudit = vol.EnableOpAudit
+	created.enableCheckExtentKey = vol.EnableCheckExtentKey
+	created.enableDirStat = vol.EnableDirStat
+	created.setDefaultXAttrs(vol.DefaultXAttrs)
+	created.setDpPins(vol.DpPins)
+	return m.cluster.syncUpdateVol(created)
+}
+
+// importClusterConfig plans the actions to import the config, and takes them unless dryRun.
+// The zones are only checked, as they are made by the nodes registered. The vols and the users
+// existing are kept as they are, only the policies of the users are granted.
+func (m *Server) importClusterConfig(conf *proto.ClusterConfig, dryRun bool) (result *proto.ClusterConfigImportResult) {
+	result = &proto.ClusterConfigImportResult{DryRun: dryRun, Actions: make([]*proto.ClusterConfigAction, 0)}
+	add := func(kind, name, action string, err error) {
+		a := &proto.ClusterConfigAction{Kind: kind, Name: name, Action: action}
+		if err != nil {
+			a.Action = proto.ClusterConfigActionError
+			a.Msg = err.Error()
+			result.Failed++
+		}
+		result.Actions = append(result.Actions, a)
+	}
+
+	for _, zone := range conf.Zones {
+		if _, err := m.cluster.t.getZone(zone.Name); err != nil {
+			result.Actions = append(result.Actions, &proto.ClusterConfigAction{
+				Kind: clusterConfigKindZone, Name: zone.Name, Action: proto.ClusterConfigActionMissing,
+				Msg: "the nodes of the zone are to be registered",
+			})
+			continue
+		}
+		add(clusterConfigKindZone, zone.Name, proto.ClusterConfigActionExists, nil)
+	}
+
+	for _, user := range conf.Users {
+		if _, err := m.user.getUserInfo(user.UserID); err == nil {
+			add(clusterConfigKindUser, user.UserID, proto.ClusterConfigActionExists, nil)
+			continue
+		}
+		var err error
+		if user.AccessKey != "" && !proto.IsValidAK(user.AccessKey) {
+			err = proto.ErrInvalidAccessKey
+		} else if user.SecretKey != "" && !proto.IsValidSK(user.SecretKey) {
+			err = proto.ErrInvalidSecretKey
+		} else if !dryRun {
+			_, err = m.user.createKey(&proto.UserCreateParam{
+				ID: user.UserID, AccessKey: user.AccessKey, SecretKey: user.SecretKey,
+				Type: user.UserType, Description: user.Description,
+			})
+		}
+		add(clusterConfigKindUser, user.UserID, proto.ClusterConfigActionCreate, err)
+	}
+
+	vols := make(map[string]bool)
+	for _, vol := range conf.Vols {
+		if vol.SimpleVolView == nil {
+			add(clusterConfigKindVol, "", proto.ClusterConfigActionCreate, fmt.Errorf("no settings of the vol"))
+			continue
+		}
+		if _, err := m.cluster.getVol(vol.Name); err == nil {
+			vols[vol.Name] = true
+			add(clusterConfigKindVol, vol.Name, proto.ClusterConfigActionExists, nil)
+			continue
+		}
+		req, err := m.parseImportedVol(vol)
+		if err == nil && !dryRun {
+			err = m.importVol(vol, req)
+		}
+		vols[vol.Name] = err == nil
+		add(clusterConfigKindVol, vol.Name, proto.ClusterConfigActionCreate, err)
+	}
+
+	for _, user := range conf.Users {
+		if user.Policy == nil {
+			continue
+		}
+		var current map[string][]string
+		if info, err := m.user.getUserInfo(user.UserID); err == nil {
+			current = info.Policy.AuthorizedVols
+		}
+		names := make([]string, 0, len(user.Policy.AuthorizedVols))
+		for name := range user.Policy.AuthorizedVols {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			policies := user.Policy.AuthorizedVols[name]
+			if reflect.DeepEqual(current[name], policies) {
+				continue
+			}
+			var err error
+			ok, imported := vols[name]
+			if !imported {
+				_, err = m.cluster.getVol(name)
+				ok = err == nil
+			}
+			if !ok {
+				err = fmt.Errorf("vol %v is not imported", name)
+			} else if !dryRun {
+				_, err = m.user.updatePolicy(&proto.UserPermUpdateParam{UserID: user.UserID, Volume: name, Policy: policies})
+			}
+			add(clusterConfigKindUserPolicy, fmt.Sprintf("%v:%v", user.UserID, name), proto.ClusterConfigActionUpdate, err)
+		}
+	}
+
+	form := url.Values{}
+	current := m.nodeInfo()
+	for key, value := range conf.NodeInfo {
+		if current[key] != value {
+			form.Set(key, value)
+		}
+	}
+	if len(form) > 0 {
+		keys := make([]string, 0, len(form))
+		for key := range form {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var params map[string]interface{}
+		r, err := newFormRequest(proto.AdminSetNodeInfo, form)
+		if err == nil {
+			params, err = parseAndExtractSetNodeInfoParams(r)
+		}
+		if err == nil && !dryRun {
+			err = m.setNodeInfo(params)
+		}
+		add(clusterConfigKindNodeInfo, strings.Join(keys, ","), proto.ClusterConfigActionUpdate, err)
+	}
+	return
+}
+
+// exportClusterConfigHandler exports the zones, the node settings, the vols and the users with their
+// policies of the cluster in a versioned json, to be imported by /admin/config/import.
+func (m *Server) exportClusterConfigHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminExportClusterConfig))
+	defer func() {
+		doStatAndMetric(proto.AdminExportClusterConfig, metric, err, nil)
+		AuditLog(r, proto.AdminExportClusterConfig, "", err)
+	}()
+	sendOkReply(w, r, newSuccessHTTPReply(m.clusterConfig()))
+}
+
+// importClusterConfigHandler imports the cluster config in the body, the actions are only
+// planned unless dryRun=false.
+func (m *Server) importClusterConfigHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		conf   *proto.ClusterConfig
+		dryRun bool
+		body   []byte
+		err    error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminImportClusterConfig))
+	defer func() {
+		doStatAndMetric(proto.AdminImportClusterConfig, metric, err, nil)
+		AuditLog(r, proto.AdminImportClusterConfig, fmt.Sprintf("dryRun(%v)", dryRun), err)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if dryRun, err = extractBoolWithDefault(r, dryRunKey, true); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if body, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	conf = &proto.ClusterConfig{}
+	if err = json.Unmarshal(body, conf); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if conf.Version != proto.ClusterConfigVersion {
+		err = fmt.Errorf("cluster config version %v should be %v", conf.Version, proto.ClusterConfigVersion)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	result := m.importClusterConfig(conf, dryRun)
+	log.LogWarnf("[importClusterConfig] config of cluster(%v) exported at (%v) dryRun(%v) actions(%v) failed(%v)",
+		conf.Cluster, conf.CreateTime, dryRun, len(result.Actions), result.Failed)
+	sendOkReply(w, r, newSuccessHTTPReply(result))
+}
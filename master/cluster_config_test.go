@@ -0,0 +1,93 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterConfig(t *testing.T) {
+	conf, err := mc.AdminAPI().ExportClusterConfig()
+	require.NoError(t, err)
+	require.Equal(t, proto.ClusterConfigVersion, conf.Version)
+	require.Equal(t, server.cluster.Name, conf.Cluster)
+	require.NotEmpty(t, conf.Zones)
+	require.NotEmpty(t, conf.NodeInfo)
+	var common *proto.ClusterConfigVol
+	for _, vol := range conf.Vols {
+		if vol.Name == commonVolName {
+			common = vol
+		}
+	}
+	require.NotNil(t, common)
+
+	// the config of the cluster itself is there already
+	result, err := mc.AdminAPI().ImportClusterConfig(conf, false)
+	require.NoError(t, err)
+	require.Zero(t, result.Failed)
+	for _, action := range result.Actions {
+		require.Equal(t, proto.ClusterConfigActionExists, action.Action, "%v %v", action.Kind, action.Name)
+	}
+
+	volName := "clusterConfigVol"
+	userID := "clusterConfigUser"
+	view := *common.SimpleVolView
+	view.Name = volName
+	view.Owner = userID
+	vol := &proto.ClusterConfigVol{SimpleVolView: &view, DpSize: common.DpSize}
+	user := &proto.UserInfo{
+		UserID: userID, AccessKey: "clusterConfigAK1", SecretKey: "clusterConfigSecretKey1234567890",
+		UserType: proto.UserTypeNormal,
+		Policy:   &proto.UserPolicy{AuthorizedVols: map[string][]string{commonVolName: {proto.BuiltinPermissionReadOnly.String()}}},
+	}
+	imported := &proto.ClusterConfig{
+		Version: proto.ClusterConfigVersion,
+		Vols:    []*proto.ClusterConfigVol{vol},
+		Users:   []*proto.UserInfo{user},
+	}
+
+	result, err = mc.AdminAPI().ImportClusterConfig(imported, true)
+	require.NoError(t, err)
+	require.True(t, result.DryRun)
+	require.Zero(t, result.Failed)
+	require.Len(t, result.Actions, 3)
+	for _, action := range result.Actions {
+		require.NotEqual(t, proto.ClusterConfigActionExists, action.Action)
+	}
+	_, err = server.cluster.getVol(volName)
+	require.Error(t, err)
+	_, err = server.user.getUserInfo(userID)
+	require.Error(t, err)
+
+	result, err = mc.AdminAPI().ImportClusterConfig(imported, false)
+	require.NoError(t, err)
+	require.Zero(t, result.Failed)
+	created, err := server.cluster.getVol(volName)
+	require.NoError(t, err)
+	require.Equal(t, userID, created.Owner)
+	require.Equal(t, common.DpSize*1024*1024*1024, created.dataPartitionSize)
+	info, err := server.user.getUserInfo(userID)
+	require.NoError(t, err)
+	require.Equal(t, user.AccessKey, info.AccessKey)
+	require.Contains(t, info.Policy.OwnVols, volName)
+	require.Equal(t, user.Policy.AuthorizedVols[commonVolName], info.Policy.AuthorizedVols[commonVolName])
+
+	imported.Version = proto.ClusterConfigVersion + 1
+	_, err = mc.AdminAPI().ImportClusterConfig(imported, true)
+	require.Error(t, err)
+}
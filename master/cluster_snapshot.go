@@ -0,0 +1,483 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultMaxClusterSnapshots = 16
+	// the partitions persisted in a raft entry of a snapshot at most
+	clusterSnapshotPartSize = 4096
+)
+
+type partitionHosts struct {
+	volName string
+	hosts   []string
+}
+
+// clusterSnapshot is the state of the cluster compared by the cluster diff: the nodes,
+// the hosts of the partitions and the persisted settings of the volumes.
+type clusterSnapshot struct {
+	id             uint64
+	createTime     int64
+	parts          int // the raft entries the partitions are persisted in
+	dataNodes      map[string]struct{}
+	metaNodes      map[string]struct{}
+	dataPartitions map[uint64]*partitionHosts
+	metaPartitions map[uint64]*partitionHosts
+	volSettings    map[string]map[string]interface{}
+}
+
+func (s *clusterSnapshot) info() *proto.ClusterSnapshotInfo {
+	return &proto.ClusterSnapshotInfo{
+		ID:                 s.id,
+		CreateTime:         s.createTime,
+		DataNodeCount:      len(s.dataNodes),
+		MetaNodeCount:      len(s.metaNodes),
+		VolCount:           len(s.volSettings),
+		DataPartitionCount: len(s.dataPartitions),
+		MetaPartitionCount: len(s.metaPartitions),
+	}
+}
+
+// clusterSnapshots keeps the latest snapshots. They are persisted in the raft store of master
+// and loaded by the new leader.
+type clusterSnapshots struct {
+	sync.RWMutex
+	capacity  int
+	snapshots []*clusterSnapshot
+}
+
+func newClusterSnapshots(capacity int) *clusterSnapshots {
+	return &clusterSnapshots{capacity: capacity, snapshots: make([]*clusterSnapshot, 0)}
+}
+
+// add keeps the snapshot of the largest id so far, it returns the oldest ones beyond the capacity.
+func (cs *clusterSnapshots) add(s *clusterSnapshot) (evicted []*clusterSnapshot) {
+	cs.Lock()
+	defer cs.Unlock()
+	cs.snapshots = append(cs.snapshots, s)
+	return cs.evict()
+}
+
+// reset replaces the snapshots by the ones loaded, it returns the oldest ones beyond the capacity.
+func (cs *clusterSnapshots) reset(snapshots []*clusterSnapshot) (evicted []*clusterSnapshot) {
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].id < snapshots[j].id })
+	cs.Lock()
+	defer cs.Unlock()
+	cs.snapshots = snapshots
+	return cs.evict()
+}
+
+func (cs *clusterSnapshots) evict() (evicted []*clusterSnapshot) {
+	if n := len(cs.snapshots) - cs.capacity; n > 0 {
+		evicted = cs.snapshots[:n]
+		cs.snapshots = cs.snapshots[n:]
+	}
+	return
+}
+
+func (cs *clusterSnapshots) get(id uint64) (s *clusterSnapshot, err error) {
+	cs.RLock()
+	defer cs.RUnlock()
+	for _, s = range cs.snapshots {
+		if s.id == id {
+			return
+		}
+	}
+	return nil, fmt.Errorf("cluster snapshot[%v] not found", id)
+}
+
+func (cs *clusterSnapshots) list() (infos []*proto.ClusterSnapshotInfo) {
+	cs.RLock()
+	defer cs.RUnlock()
+	infos = make([]*proto.ClusterSnapshotInfo, 0, len(cs.snapshots))
+	for _, s := range cs.snapshots {
+		infos = append(infos, s.info())
+	}
+	return
+}
+
+type partitionHostsValue struct {
+	VolName string
+	Hosts   []string
+}
+
+// clusterSnapshotValue is the head of a clusterSnapshot persisted in the raft store, the hosts
+// of the partitions are persisted in Parts entries of clusterSnapshotPartValue after it, so that
+// no proposal carries the partitions of the whole cluster.
+type clusterSnapshotValue struct {
+	ID          uint64
+	CreateTime  int64
+	DataNodes   []string
+	MetaNodes   []string
+	VolSettings map[string]map[string]interface{}
+	Parts       int
+}
+
+// clusterSnapshotPartValue is up to clusterSnapshotPartSize partitions of a clusterSnapshot.
+type clusterSnapshotPartValue struct {
+	ID             uint64
+	Seq            int
+	DataPartitions map[uint64]*partitionHostsValue
+	MetaPartitions map[uint64]*partitionHostsValue
+}
+
+func newNodeList(nodes map[string]struct{}) []string {
+	addrs := make([]string, 0, len(nodes))
+	for addr := range nodes {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func newClusterSnapshotValue(s *clusterSnapshot) (head *clusterSnapshotValue, parts []*clusterSnapshotPartValue) {
+	head = &clusterSnapshotValue{
+		ID:          s.id,
+		CreateTime:  s.createTime,
+		DataNodes:   newNodeList(s.dataNodes),
+		MetaNodes:   newNodeList(s.metaNodes),
+		VolSettings: s.volSettings,
+	}
+	var part *clusterSnapshotPartValue
+	count := 0
+	nextPart := func() *clusterSnapshotPartValue {
+		if part == nil || count >= clusterSnapshotPartSize {
+			part = &clusterSnapshotPartValue{
+				ID:             s.id,
+				Seq:            len(parts),
+				DataPartitions: make(map[uint64]*partitionHostsValue),
+				MetaPartitions: make(map[uint64]*partitionHostsValue),
+			}
+			parts = append(parts, part)
+			count = 0
+		}
+		count++
+		return part
+	}
+	for _, id := range sortedPartitionHostIDs(s.dataPartitions) {
+		ph := s.dataPartitions[id]
+		nextPart().DataPartitions[id] = &partitionHostsValue{VolName: ph.volName, Hosts: ph.hosts}
+	}
+	for _, id := range sortedPartitionHostIDs(s.metaPartitions) {
+		ph := s.metaPartitions[id]
+		nextPart().MetaPartitions[id] = &partitionHostsValue{VolName: ph.volName, Hosts: ph.hosts}
+	}
+	head.Parts = len(parts)
+	return
+}
+
+func sortedPartitionHostIDs(partitions map[uint64]*partitionHosts) []uint64 {
+	ids := make([]uint64, 0, len(partitions))
+	for id := range partitions {
+		ids = append(ids, id)
+	}
+	sortPartitionIDs(ids)
+	return ids
+}
+
+func (v *clusterSnapshotValue) snapshot(parts []*clusterSnapshotPartValue) *clusterSnapshot {
+	s := &clusterSnapshot{
+		id:             v.ID,
+		createTime:     v.CreateTime,
+		parts:          v.Parts,
+		dataNodes:      make(map[string]struct{}, len(v.DataNodes)),
+		metaNodes:      make(map[string]struct{}, len(v.MetaNodes)),
+		dataPartitions: make(map[uint64]*partitionHosts),
+		metaPartitions: make(map[uint64]*partitionHosts),
+		volSettings:    v.VolSettings,
+	}
+	if s.volSettings == nil {
+		s.volSettings = make(map[string]map[string]interface{})
+	}
+	for _, settings := range s.volSettings {
+		redactSecrets(settings)
+	}
+	for _, addr := range v.DataNodes {
+		s.dataNodes[addr] = struct{}{}
+	}
+	for _, addr := range v.MetaNodes {
+		s.metaNodes[addr] = struct{}{}
+	}
+	for _, part := range parts {
+		for id, ph := range part.DataPartitions {
+			s.dataPartitions[id] = &partitionHosts{volName: ph.VolName, hosts: ph.Hosts}
+		}
+		for id, ph := range part.MetaPartitions {
+			s.metaPartitions[id] = &partitionHosts{volName: ph.VolName, hosts: ph.Hosts}
+		}
+	}
+	return s
+}
+
+func clusterSnapshotKey(id uint64) string {
+	return clusterSnapshotPrefix + strconv.FormatUint(id, 10)
+}
+
+func clusterSnapshotPartKey(id uint64, seq int) string {
+	return clusterSnapshotPartPrefix + strconv.FormatUint(id, 10) + keySeparator + strconv.Itoa(seq)
+}
+
+// syncAddClusterSnapshot persists the parts of the snapshot and then its head, the parts
+// persisted are deleted if it fails.
+func (c *Cluster) syncAddClusterSnapshot(s *clusterSnapshot) (err error) {
+	head, parts := newClusterSnapshotValue(s)
+	for _, part := range parts {
+		if err = c.syncPutClusterSnapshotInfo(opSyncAddClusterSnapshot, clusterSnapshotPartKey(s.id, part.Seq), part); err != nil {
+			c.syncDeleteClusterSnapshotKeys(clusterSnapshotPartKeys(s.id, part.Seq))
+			return
+		}
+	}
+	if err = c.syncPutClusterSnapshotInfo(opSyncAddClusterSnapshot, clusterSnapshotKey(s.id), head); err != nil {
+		c.syncDeleteClusterSnapshotKeys(clusterSnapshotPartKeys(s.id, len(parts)))
+		return
+	}
+	s.parts = len(parts)
+	return
+}
+
+func clusterSnapshotPartKeys(id uint64, parts int) []string {
+	keys := make([]string, 0, parts)
+	for seq := 0; seq < parts; seq++ {
+		keys = append(keys, clusterSnapshotPartKey(id, seq))
+	}
+	return keys
+}
+
+// syncDeleteClusterSnapshot deletes the head of the snapshot before its parts, so that a
+// snapshot failed to delete is not loaded without them.
+func (c *Cluster) syncDeleteClusterSnapshot(s *clusterSnapshot) (err error) {
+	return c.syncDeleteClusterSnapshotKeys(append([]string{clusterSnapshotKey(s.id)}, clusterSnapshotPartKeys(s.id, s.parts)...))
+}
+
+func (c *Cluster) syncDeleteClusterSnapshotKeys(keys []string) (err error) {
+	for _, key := range keys {
+		metadata := &RaftCmd{Op: opSyncDeleteClusterSnapshot, K: key}
+		if err = c.submit(metadata); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (c *Cluster) syncPutClusterSnapshotInfo(opType uint32, key string, value interface{}) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opType
+	metadata.K = key
+	metadata.V, err = json.Marshal(value)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+// addClusterSnapshot persists the snapshot under a new id and drops the oldest ones beyond the capacity.
+func (c *Cluster) addClusterSnapshot(s *clusterSnapshot) (err error) {
+	if s.id, err = c.idAlloc.allocateCommonID(); err != nil {
+		return
+	}
+	if err = c.syncAddClusterSnapshot(s); err != nil {
+		return
+	}
+	c.deleteClusterSnapshots(c.clusterSnapshots.add(s))
+	return
+}
+
+func (c *Cluster) deleteClusterSnapshots(snapshots []*clusterSnapshot) {
+	for _, s := range snapshots {
+		if err := c.syncDeleteClusterSnapshot(s); err != nil {
+			log.LogWarnf("action[deleteClusterSnapshots] snapshot[%v] err[%v]", s.id, err)
+		}
+	}
+}
+
+func copyHosts(hosts []string) []string {
+	dst := make([]string, len(hosts))
+	copy(dst, hosts)
+	return dst
+}
+
+func (c *Cluster) takeClusterSnapshot() (s *clusterSnapshot, err error) {
+	s = &clusterSnapshot{
+		createTime:     time.Now().Unix(),
+		dataNodes:      make(map[string]struct{}),
+		metaNodes:      make(map[string]struct{}),
+		dataPartitions: make(map[uint64]*partitionHosts),
+		metaPartitions: make(map[uint64]*partitionHosts),
+		volSettings:    make(map[string]map[string]interface{}),
+	}
+	c.dataNodes.Range(func(addr, _ interface{}) bool {
+		s.dataNodes[addr.(string)] = struct{}{}
+		return true
+	})
+	c.metaNodes.Range(func(addr, _ interface{}) bool {
+		s.metaNodes[addr.(string)] = struct{}{}
+		return true
+	})
+	for name, vol := range c.allVols() {
+		var data []byte
+		if data, err = json.Marshal(newVolValue(vol)); err != nil {
+			return
+		}
+		settings := make(map[string]interface{})
+		if err = json.Unmarshal(data, &settings); err != nil {
+			return
+		}
+		// the keys of the vol are neither persisted nor returned by the diff
+		redactSecrets(settings)
+		s.volSettings[name] = settings
+
+		vol.dataPartitions.Range(func(dp *DataPartition) bool {
+			dp.RLock()
+			s.dataPartitions[dp.PartitionID] = &partitionHosts{volName: name, hosts: copyHosts(dp.Hosts)}
+			dp.RUnlock()
+			return true
+		})
+		for _, mp := range vol.getSortMetaPartitions() {
+			mp.RLock()
+			s.metaPartitions[mp.PartitionID] = &partitionHosts{volName: name, hosts: copyHosts(mp.Hosts)}
+			mp.RUnlock()
+		}
+	}
+	return
+}
+
+func diffNodeSet(from, to map[string]struct{}) (added, removed []string) {
+	added, removed = make([]string, 0), make([]string, 0)
+	for addr := range to {
+		if _, ok := from[addr]; !ok {
+			added = append(added, addr)
+		}
+	}
+	for addr := range from {
+		if _, ok := to[addr]; !ok {
+			removed = append(removed, addr)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return
+}
+
+func sameHosts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]struct{}, len(a))
+	for _, host := range a {
+		set[host] = struct{}{}
+	}
+	for _, host := range b {
+		if _, ok := set[host]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func diffPartitionHosts(from, to map[uint64]*partitionHosts) (added, removed []uint64, moved []*proto.PartitionHostsChange) {
+	added, removed = make([]uint64, 0), make([]uint64, 0)
+	moved = make([]*proto.PartitionHostsChange, 0)
+	for id, ph := range to {
+		old, ok := from[id]
+		if !ok {
+			added = append(added, id)
+			continue
+		}
+		if !sameHosts(old.hosts, ph.hosts) {
+			moved = append(moved, &proto.PartitionHostsChange{
+				PartitionID: id,
+				VolName:     ph.volName,
+				OldHosts:    old.hosts,
+				NewHosts:    ph.hosts,
+			})
+		}
+	}
+	for id := range from {
+		if _, ok := to[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sortPartitionIDs(added)
+	sortPartitionIDs(removed)
+	sort.Slice(moved, func(i, j int) bool { return moved[i].PartitionID < moved[j].PartitionID })
+	return
+}
+
+func diffVolSettings(from, to map[string]map[string]interface{}) (added, removed []string, changed []*proto.VolSettingChange) {
+	added, removed = make([]string, 0), make([]string, 0)
+	changed = make([]*proto.VolSettingChange, 0)
+	for name, settings := range to {
+		old, ok := from[name]
+		if !ok {
+			added = append(added, name)
+			continue
+		}
+		for field, value := range settings {
+			if oldValue := old[field]; !reflect.DeepEqual(oldValue, value) {
+				changed = append(changed, &proto.VolSettingChange{VolName: name, Field: field, Old: oldValue, New: value})
+			}
+		}
+		for field, oldValue := range old {
+			if _, ok := settings[field]; !ok {
+				changed = append(changed, &proto.VolSettingChange{VolName: name, Field: field, Old: oldValue})
+			}
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].VolName != changed[j].VolName {
+			return changed[i].VolName < changed[j].VolName
+		}
+		return changed[i].Field < changed[j].Field
+	})
+	return
+}
+
+func diffClusterSnapshots(from, to *clusterSnapshot) (diff *proto.ClusterDiff) {
+	diff = &proto.ClusterDiff{
+		From:     from.id,
+		To:       to.id,
+		FromTime: from.createTime,
+		ToTime:   to.createTime,
+	}
+	diff.DataNodesAdded, diff.DataNodesRemoved = diffNodeSet(from.dataNodes, to.dataNodes)
+	diff.MetaNodesAdded, diff.MetaNodesRemoved = diffNodeSet(from.metaNodes, to.metaNodes)
+	diff.DataPartitionsAdded, diff.DataPartitionsRemoved, diff.DataPartitionsMoved =
+		diffPartitionHosts(from.dataPartitions, to.dataPartitions)
+	diff.MetaPartitionsAdded, diff.MetaPartitionsRemoved, diff.MetaPartitionsMoved =
+		diffPartitionHosts(from.metaPartitions, to.metaPartitions)
+	diff.VolsAdded, diff.VolsRemoved, diff.VolSettingsChanged = diffVolSettings(from.volSettings, to.volSettings)
+	return
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSnapshots(t *testing.T) {
+	cs := newClusterSnapshots(2)
+	require.Empty(t, cs.add(&clusterSnapshot{id: 1}))
+	require.Empty(t, cs.add(&clusterSnapshot{id: 2}))
+	evicted := cs.add(&clusterSnapshot{id: 3})
+	require.Len(t, evicted, 1)
+	require.EqualValues(t, 1, evicted[0].id)
+	infos := cs.list()
+	require.Len(t, infos, 2)
+	require.EqualValues(t, 2, infos[0].ID)
+	require.EqualValues(t, 3, infos[1].ID)
+	_, err := cs.get(1)
+	require.Error(t, err)
+	s, err := cs.get(3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, s.id)
+
+	// the snapshots loaded by a new leader are kept by their ids
+	evicted = cs.reset([]*clusterSnapshot{{id: 9}, {id: 4}, {id: 7}})
+	require.Len(t, evicted, 1)
+	require.EqualValues(t, 4, evicted[0].id)
+	infos = cs.list()
+	require.EqualValues(t, 7, infos[0].ID)
+	require.EqualValues(t, 9, infos[1].ID)
+}
+
+func TestClusterSnapshotValue(t *testing.T) {
+	s := &clusterSnapshot{
+		id:             5,
+		createTime:     100,
+		parts:          1,
+		dataNodes:      map[string]struct{}{"d1": {}, "d2": {}},
+		metaNodes:      map[string]struct{}{"m1": {}},
+		dataPartitions: map[uint64]*partitionHosts{1: {volName: "v", hosts: []string{"d1", "d2"}}},
+		metaPartitions: map[uint64]*partitionHosts{2: {volName: "v", hosts: []string{"m1"}}},
+		volSettings:    map[string]map[string]interface{}{"v": {"Capacity": float64(10)}},
+	}
+	head, parts := newClusterSnapshotValue(s)
+	require.Equal(t, 1, head.Parts)
+	data, err := json.Marshal(head)
+	require.NoError(t, err)
+	csv := &clusterSnapshotValue{}
+	require.NoError(t, json.Unmarshal(data, csv))
+	data, err = json.Marshal(parts[0])
+	require.NoError(t, err)
+	part := &clusterSnapshotPartValue{}
+	require.NoError(t, json.Unmarshal(data, part))
+	loaded := csv.snapshot([]*clusterSnapshotPartValue{part})
+	require.Equal(t, s, loaded)
+	diff := diffClusterSnapshots(s, loaded)
+	require.Empty(t, diff.DataPartitionsMoved)
+	require.Empty(t, diff.VolSettingsChanged)
+
+	// the partitions are split into the parts of a bounded size
+	s.dataPartitions = make(map[uint64]*partitionHosts)
+	for id := uint64(1); id <= 2*clusterSnapshotPartSize; id++ {
+		s.dataPartitions[id] = &partitionHosts{volName: "v", hosts: []string{"d1"}}
+	}
+	head, parts = newClusterSnapshotValue(s)
+	require.Equal(t, 3, head.Parts)
+	require.Len(t, parts, 3)
+	for seq, part := range parts {
+		require.Equal(t, seq, part.Seq)
+		require.LessOrEqual(t, len(part.DataPartitions)+len(part.MetaPartitions), clusterSnapshotPartSize)
+	}
+	require.Len(t, parts[2].MetaPartitions, 1)
+	s.parts = 3
+	require.Equal(t, s, head.snapshot(parts))
+}
+
+func TestClusterSnapshotRedact(t *testing.T) {
+	vol := newVolFromVolValue(newVolValue(commonVol))
+	vol.OSSAccessKey, vol.OSSSecretKey = "ak", "sk"
+	vol.setMetaAccessKeys([]*proto.MetaAccessKey{{ID: 1, Secret: "secret1"}})
+	data, err := json.Marshal(newVolValue(vol))
+	require.NoError(t, err)
+	settings := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(data, &settings))
+	redactSecrets(settings)
+	data, err = json.Marshal(settings)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret1")
+	require.NotContains(t, string(data), `"sk"`)
+
+	// the keys rotated are told by their ids
+	from := &clusterSnapshot{volSettings: map[string]map[string]interface{}{"v": settings}}
+	vol.setMetaAccessKeys([]*proto.MetaAccessKey{{ID: 2, Secret: "secret2"}})
+	data, err = json.Marshal(newVolValue(vol))
+	require.NoError(t, err)
+	csv := &clusterSnapshotValue{}
+	require.NoError(t, json.Unmarshal([]byte(`{"VolSettings":{"v":`+string(data)+`}}`), csv))
+	to := csv.snapshot(nil)
+	diff := diffClusterSnapshots(from, to)
+	require.Len(t, diff.VolSettingsChanged, 1)
+	require.Equal(t, "MetaAccessKeys", diff.VolSettingsChanged[0].Field)
+	data, err = json.Marshal(diff)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret1")
+	require.NotContains(t, string(data), "secret2")
+}
+
+func TestDiffClusterSnapshots(t *testing.T) {
+	from := &clusterSnapshot{
+		id:        1,
+		dataNodes: map[string]struct{}{"d1": {}, "d2": {}},
+		metaNodes: map[string]struct{}{"m1": {}},
+		dataPartitions: map[uint64]*partitionHosts{
+			1: {volName: "vol", hosts: []string{"d1", "d2"}},
+			2: {volName: "vol", hosts: []string{"d1", "d2"}},
+		},
+		metaPartitions: map[uint64]*partitionHosts{1: {volName: "vol", hosts: []string{"m1"}}},
+		volSettings: map[string]map[string]interface{}{
+			"vol": {"Capacity": float64(10), "AtimeMode": ""},
+			"old": {"Capacity": float64(1)},
+		},
+	}
+	to := &clusterSnapshot{
+		id:        2,
+		dataNodes: map[string]struct{}{"d2": {}, "d3": {}},
+		metaNodes: map[string]struct{}{"m1": {}},
+		dataPartitions: map[uint64]*partitionHosts{
+			1: {volName: "vol", hosts: []string{"d2", "d1"}},
+			2: {volName: "vol", hosts: []string{"d3", "d2"}},
+			3: {volName: "new", hosts: []string{"d3"}},
+		},
+		metaPartitions: map[uint64]*partitionHosts{1: {volName: "vol", hosts: []string{"m1"}}},
+		volSettings: map[string]map[string]interface{}{
+			"vol": {"Capacity": float64(20), "AtimeMode": ""},
+			"new": {"Capacity": float64(1)},
+		},
+	}
+
+	diff := diffClusterSnapshots(from, to)
+	require.EqualValues(t, 1, diff.From)
+	require.EqualValues(t, 2, diff.To)
+	require.Equal(t, []string{"d3"}, diff.DataNodesAdded)
+	require.Equal(t, []string{"d1"}, diff.DataNodesRemoved)
+	require.Empty(t, diff.MetaNodesAdded)
+	require.Empty(t, diff.MetaNodesRemoved)
+	require.Equal(t, []uint64{3}, diff.DataPartitionsAdded)
+	require.Empty(t, diff.DataPartitionsRemoved)
+	require.Len(t, diff.DataPartitionsMoved, 1)
+	require.EqualValues(t, 2, diff.DataPartitionsMoved[0].PartitionID)
+	require.Equal(t, []string{"d3", "d2"}, diff.DataPartitionsMoved[0].NewHosts)
+	require.Empty(t, diff.MetaPartitionsMoved)
+	require.Equal(t, []string{"new"}, diff.VolsAdded)
+	require.Equal(t, []string{"old"}, diff.VolsRemoved)
+	require.Len(t, diff.VolSettingsChanged, 1)
+	require.Equal(t, "Capacity", diff.VolSettingsChanged[0].Field)
+	require.Equal(t, float64(10), diff.VolSettingsChanged[0].Old)
+	require.Equal(t, float64(20), diff.VolSettingsChanged[0].New)
+}
@@ -104,7 +104,9 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 		excludeNodeSets []uint64
 		finalHosts      []string
 		oldHosts        []string
+		excludeHosts    []string
 		zones           []string
+		mediaType       uint32
 	)
 
 	log.LogWarnf("action[migrateMetaPartition],volName[%v], migrate from src[%s] to target[%s],partitionID[%v] begin",
@@ -130,6 +132,14 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 		goto errHandler
 	}
 
+	mediaType = c.getMetaMediaTypeOfVol(mp.volName)
+	if targetAddr != "" {
+		if err = c.checkMetaHostsMediaType([]string{targetAddr}, mediaType); err != nil {
+			goto errHandler
+		}
+	}
+	excludeHosts = c.excludeMetaHostsNotOfMediaType(oldHosts, mediaType)
+
 	if metaNode, err = c.metaNode(srcAddr); err != nil {
 		goto errHandler
 	}
@@ -146,7 +156,7 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 		newPeers = []proto.Peer{{
 			Addr: targetAddr,
 		}}
-	} else if _, newPeers, err = ns.getAvailMetaNodeHosts(oldHosts, 1); err != nil {
+	} else if _, newPeers, err = ns.getAvailMetaNodeHosts(excludeHosts, 1); err != nil {
 		if _, ok := c.vols[mp.volName]; !ok {
 			log.LogWarnf("[migrateMetaPartition] clusterID[%v] partitionID:%v  on node:[%v]",
 				c.Name, mp.PartitionID, mp.Hosts)
@@ -159,7 +169,7 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 		}
 		// choose a meta node in other node set in the same zone
 		excludeNodeSets = append(excludeNodeSets, ns.ID)
-		if _, newPeers, err = zone.getAvailNodeHosts(TypeMetaPartition, excludeNodeSets, oldHosts, 1); err != nil {
+		if _, newPeers, err = zone.getAvailNodeHosts(TypeMetaPartition, excludeNodeSets, excludeHosts, 1); err != nil {
 			zones = mp.getLiveZones(srcAddr)
 			var excludeZone []string
 			if len(zones) == 0 {
@@ -168,7 +178,7 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 				excludeZone = append(excludeZone, zones[0])
 			}
 			// choose a meta node in other zone
-			if _, newPeers, err = c.getHostFromNormalZone(TypeMetaPartition, excludeZone, excludeNodeSets, oldHosts, 1, 1, "", proto.MediaType_Unspecified); err != nil {
+			if _, newPeers, err = c.getHostFromNormalZone(TypeMetaPartition, excludeZone, excludeNodeSets, excludeHosts, 1, 1, "", proto.MediaType_Unspecified); err != nil {
 				goto errHandler
 			}
 		}
@@ -232,6 +242,96 @@ func (c *Cluster) decommissionMetaPartition(nodeAddr string, mp *MetaPartition)
 	return c.migrateMetaPartition(nodeAddr, "", mp)
 }
 
+// hostsOutOfNodeSet returns the hosts of the meta partition which are not in the nodeset.
+func (c *Cluster) hostsOutOfNodeSet(mp *MetaPartition, ns *nodeSet) (hosts []string) {
+	mp.RLock()
+	defer mp.RUnlock()
+	for _, host := range mp.Hosts {
+		if _, ok := ns.metaNodes.Load(host); !ok {
+			hosts = append(hosts, host)
+		}
+	}
+	return
+}
+
+// migrateMetaPartitionToNodeSet moves the replicas of the meta partition out of the nodeset
+// into it, one after another in the background. Each replica is migrated as decommissioned,
+// with the new meta node chosen in the nodeset, and waits for its new replica to recover
+// before the next one is moved. It returns the hosts to be moved.
+func (c *Cluster) migrateMetaPartitionToNodeSet(mp *MetaPartition, nodeSetID uint64) (srcs []string, err error) {
+	if c.ForbidMpDecommission {
+		err = fmt.Errorf("cluster mataPartition decommission switch is disabled")
+		return
+	}
+	ns, err := c.t.getNodeSetByNodeSetId(nodeSetID)
+	if err != nil {
+		return
+	}
+	if srcs = c.hostsOutOfNodeSet(mp, ns); len(srcs) == 0 {
+		err = fmt.Errorf("meta partition[%v] is already in nodeset[%v]", mp.PartitionID, nodeSetID)
+		return
+	}
+	if err = c.validateDecommissionMetaPartition(mp, srcs[0], false); err != nil {
+		return
+	}
+	if ns.metaNodeLen() < int(mp.ReplicaNum) {
+		err = fmt.Errorf("nodeset[%v] has %v meta nodes, less than the %v replicas of meta partition[%v]",
+			nodeSetID, ns.metaNodeLen(), mp.ReplicaNum, mp.PartitionID)
+		return
+	}
+	if target, loaded := c.mpNodeSetMigrations.LoadOrStore(mp.PartitionID, nodeSetID); loaded {
+		err = fmt.Errorf("meta partition[%v] is being migrated to nodeset[%v]", mp.PartitionID, target)
+		return
+	}
+	go func() {
+		defer c.mpNodeSetMigrations.Delete(mp.PartitionID)
+		for _, src := range srcs {
+			if err := c.migrateMetaReplicaToNodeSet(mp, ns, src); err != nil {
+				msg := fmt.Sprintf("action[migrateMetaPartitionToNodeSet] clusterID[%v] vol[%v] meta partition[%v] "+
+					"move replica on [%v] to nodeset[%v] failed: %v", c.Name, mp.volName, mp.PartitionID, src, ns.ID, err)
+				log.LogError(msg)
+				Warn(c.Name, msg)
+				return
+			}
+		}
+		log.LogInfof("action[migrateMetaPartitionToNodeSet] vol[%v] meta partition[%v] moved into nodeset[%v]",
+			mp.volName, mp.PartitionID, ns.ID)
+	}()
+	return
+}
+
+func (c *Cluster) migrateMetaReplicaToNodeSet(mp *MetaPartition, ns *nodeSet, src string) (err error) {
+	mp.RLock()
+	excludeHosts := append([]string{}, mp.Hosts...)
+	mp.RUnlock()
+	excludeHosts = c.excludeMetaHostsNotOfMediaType(excludeHosts, c.getMetaMediaTypeOfVol(mp.volName))
+	_, peers, err := ns.getAvailMetaNodeHosts(excludeHosts, 1)
+	if err != nil {
+		return
+	}
+	if err = c.migrateMetaPartition(src, peers[0].Addr, mp); err != nil {
+		return
+	}
+	ticker := time.NewTicker(mpNodeSetMigrationRecoverCheck)
+	defer ticker.Stop()
+	timeout := time.After(mpNodeSetMigrationRecoverTimeout)
+	for {
+		mp.RLock()
+		recovering := mp.IsRecover
+		mp.RUnlock()
+		if !recovering {
+			return
+		}
+		select {
+		case <-c.stopc:
+			return fmt.Errorf("cluster stopped")
+		case <-timeout:
+			return fmt.Errorf("new replica on [%v] is not recovered in %v", peers[0].Addr, mpNodeSetMigrationRecoverTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
 func (c *Cluster) validateDecommissionMetaPartition(mp *MetaPartition, nodeAddr string, forceDel bool) (err error) {
 	mp.RLock()
 	defer mp.RUnlock()
@@ -565,7 +665,7 @@ func (c *Cluster) addMetaReplica(partition *MetaPartition, addr string) (err err
 }
 
 func (c *Cluster) createMetaReplica(partition *MetaPartition, addPeer proto.Peer) (err error) {
-	task, err := partition.createTaskToCreateReplica(addPeer.Addr)
+	task, err := partition.createTaskToCreateReplica(addPeer.Addr, c.getVolDefaultXAttrs(partition.volName))
 	if err != nil {
 		return
 	}
@@ -843,8 +943,9 @@ errHandler:
 
 func (c *Cluster) dealMetaNodeHeartbeatResp(nodeAddr string, resp *proto.MetaNodeHeartbeatResponse) (err error) {
 	var (
-		metaNode *MetaNode
-		logMsg   string
+		metaNode  *MetaNode
+		logMsg    string
+		unchanged []*proto.MetaPartitionReport
 	)
 
 	log.LogInfof("action[dealMetaNodeHeartbeatResp],clusterID[%v] receive nodeAddr[%v] heartbeat", c.Name, nodeAddr)
@@ -885,13 +986,14 @@ func (c *Cluster) dealMetaNodeHeartbeatResp(nodeAddr string, resp *proto.MetaNod
 
 	// change cpu util and io used
 	metaNode.CpuUtil.Store(resp.CpuUtil)
-	metaNode.updateMetric(resp, c.cfg.MetaNodeThreshold)
+	unchanged = metaNode.updateMetric(resp, c.cfg.MetaNodeThreshold)
 	metaNode.setNodeActive()
 
 	if err = c.t.putMetaNode(metaNode); err != nil {
 		log.LogErrorf("action[dealMetaNodeHeartbeatResp],metaNode[%v] error[%v]", metaNode.Addr, err)
 	}
 	c.updateMetaNode(metaNode, resp.MetaPartitionReports, metaNode.reachesThreshold())
+	c.touchMetaNodeReplicas(metaNode, unchanged)
 	// todo remove, this no need set metaNode.metaPartitionInfos = nil
 	// metaNode.metaPartitionInfos = nil
 	logMsg = fmt.Sprintf("action[dealMetaNodeHeartbeatResp],metaNode:%v,zone[%v], ReportTime:%v  success", metaNode.Addr, metaNode.ZoneName, time.Now().Unix())
@@ -1206,8 +1308,33 @@ func (c *Cluster) updateMetaNode(metaNode *MetaNode, metaPartitions []*proto.Met
 	}
 }
 
+// touchMetaNodeReplicas refreshes the replicas on the meta node left out of a delta report
+// as unchanged.
+func (c *Cluster) touchMetaNodeReplicas(metaNode *MetaNode, metaPartitions []*proto.MetaPartitionReport) {
+	for _, mr := range metaPartitions {
+		var (
+			mp  *MetaPartition
+			err error
+		)
+		if mr.VolName != "" {
+			var vol *Vol
+			if vol, err = c.getVol(mr.VolName); err != nil {
+				continue
+			}
+			mp, err = vol.metaPartition(mr.PartitionID)
+		} else {
+			mp, err = c.getMetaPartitionByID(mr.PartitionID)
+		}
+		if err != nil {
+			continue
+		}
+		mp.touchReplica(metaNode.Addr)
+	}
+}
+
 func (c *Cluster) updateInodeIDUpperBound(mp *MetaPartition, mr *proto.MetaPartitionReport, hasArriveThreshold bool, metaNode *MetaNode) (err error) {
-	if !hasArriveThreshold {
+	hot := !hasArriveThreshold && mp.recordHeat(mr, c.metaPartitionHeatSplitOpRate())
+	if !hasArriveThreshold && !hot {
 		return
 	}
 	var vol *Vol
@@ -1220,6 +1347,10 @@ func (c *Cluster) updateInodeIDUpperBound(mp *MetaPartition, mr *proto.MetaParti
 	if mr.PartitionID < maxPartitionID {
 		return
 	}
+	// split early by heat, once in an interval of the vol not to split the new ones in a storm
+	if hot && (c.cfg.DisableAutoCreate || !vol.takeHeatSplit(c.metaPartitionHeatSplitInterval())) {
+		return
+	}
 	var end uint64
 	metaPartitionInodeIdStep := gConfig.MetaPartitionInodeIdStep
 	if mr.MaxInodeID <= 0 {
@@ -1227,11 +1358,13 @@ func (c *Cluster) updateInodeIDUpperBound(mp *MetaPartition, mr *proto.MetaParti
 	} else {
 		end = mr.MaxInodeID + metaPartitionInodeIdStep
 	}
-	log.LogWarnf("mpId[%v],start[%v],end[%v],addr[%v],used[%v]", mp.PartitionID, mp.Start, mp.End, metaNode.Addr, metaNode.Used)
+	log.LogWarnf("mpId[%v],start[%v],end[%v],addr[%v],used[%v],opRate[%v],hot[%v]", mp.PartitionID, mp.Start, mp.End,
+		metaNode.Addr, metaNode.Used, mr.OpRate, hot)
 	if c.cfg.DisableAutoCreate {
 		log.LogWarnf("updateInodeIDUpperBound: disable auto create meta partition, mp %d", mp.PartitionID)
 		return
 	}
+
 	if err = vol.splitMetaPartition(c, mp, end, metaPartitionInodeIdStep, false); err != nil {
 		log.LogErrorf("mpId[%v], splitMetaPartition err %v", mp.PartitionID, err)
 	}
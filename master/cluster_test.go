@@ -458,3 +458,53 @@ func TestDoCleanEmptyMetaPartition(t *testing.T) {
 	err := server.cluster.DoCleanEmptyMetaPartition(commonVolName)
 	require.NoError(t, err)
 }
+
+func TestMergeEmptyMetaPartition(t *testing.T) {
+	c := server.cluster
+	name := "mergeMpVol"
+	createVol(map[string]interface{}{nameKey: name}, t)
+	vol, err := c.getVol(name)
+	require.NoError(t, err)
+	require.NoError(t, vol.addMetaPartitions(c, 3))
+	for _, mp := range vol.getSortMetaPartitions() {
+		if _, e := mp.getMetaReplicaLeader(); e != nil && len(mp.Replicas) > 0 {
+			mp.Replicas[0].IsLeader = true
+		}
+	}
+
+	reply := process(fmt.Sprintf("%v%v?name=%v", hostAddr, proto.AdminMetaPartitionMergeEmpty, name), t)
+	require.EqualValues(t, true, reply.Data.(map[string]interface{})["dryRun"])
+
+	mps := vol.getSortMetaPartitions()
+	pairs := c.getEmptyMetaPartitionMergePairs(vol, 1)
+	require.Len(t, pairs, 1)
+	lower, higher := pairs[0].lower, pairs[0].higher
+	require.Equal(t, lower.End+1, higher.Start)
+	require.NotEqual(t, vol.maxMetaPartitionID(), higher.PartitionID)
+
+	// the max partition is never merged
+	maxMp, err := vol.metaPartition(vol.maxMetaPartitionID())
+	require.NoError(t, err)
+	require.Error(t, c.mergeEmptyMetaPartition(vol, mps[len(mps)-2], maxMp))
+	require.EqualValues(t, proto.FreezeMetaPartitionInit, maxMp.Freeze)
+
+	// a written partition is taken back
+	higher.Freeze = proto.FreezingMetaPartition
+	higher.InodeCount = 1
+	require.Error(t, c.mergeEmptyMetaPartition(vol, lower, higher))
+	require.EqualValues(t, proto.FreezeMetaPartitionInit, higher.Freeze)
+	require.EqualValues(t, proto.ReadWrite, higher.Status)
+	higher.InodeCount = 0
+
+	end := higher.End
+	higher.Freeze = proto.FreezingMetaPartition
+	require.NoError(t, c.mergeEmptyMetaPartition(vol, lower, higher))
+	require.Equal(t, end, lower.End)
+	for _, mr := range lower.Replicas {
+		require.Equal(t, end, mr.end)
+	}
+	_, err = vol.metaPartition(higher.PartitionID)
+	require.Error(t, err)
+	require.Len(t, vol.getSortMetaPartitions(), len(mps)-1)
+	require.Error(t, c.mergeEmptyMetaPartition(vol, lower, higher))
+}
@@ -63,6 +63,8 @@ const (
 
 	cfgHttpReversePoolSize = "httpReversePoolSize"
 
+	cfgGrpcPort = "grpcPort" // port of the grpc gateway of the admin APIs, disabled if empty
+
 	cfgLegacyDataMediaType = "legacyDataMediaType" // for hybrid cloud upgrade
 
 	cfgRaftPartitionCanUseDifferentPort   = "raftPartitionCanUseDifferentPort"
@@ -70,6 +72,9 @@ const (
 	cfgMetaNodeMemoryHighPer              = "metaNodeMemoryHighPer"
 	cfgMetaNodeMemoryLowPer               = "metaNodeMemoryLowPer"
 	cfgAutoMpMigrate                      = "autoMetaPartitionMigrate"
+	cfgMpApplyLagThreshold                = "mpApplyLagThreshold"
+	cfgMpApplyLagCycles                   = "mpApplyLagCycles"
+	cfgMpApplyLagAutoRepair               = "mpApplyLagAutoRepair"
 	cfgSingleNodeMode                     = "singleNodeMode"
 	cfgMaxWritableDataPartitionCnt        = "maxWritableDataPartitionCnt"
 
@@ -123,6 +128,9 @@ const (
 	defaultFlashNodeHandleReadTimeout   = 1000
 	defaultFlashNodeReadDataNodeTimeout = 3000
 
+	defaultMpApplyLagThreshold = 100000 // apply index lag of a meta replica behind the leader to be alarmed
+	defaultMpApplyLagCycles    = 10     // number of heartbeat cycles the lag lasts before alarming
+
 	defaultMetaNodeGOGC = 100
 	defaultDataNodeGOGC = 100
 )
@@ -153,6 +161,12 @@ type clusterConfig struct {
 	MetaNodeDeleteBatchCount            uint64 // metanode delete batch count
 	DataNodeDeleteLimitRate             uint64 // datanode delete limit rate
 	MetaNodeDeleteWorkerSleepMs         uint64 // metaNode delete worker sleep time with millisecond. if 0 for no sleep
+	MetaSnapshotPersistenceMode         uint32 // persistence mode of the snapshot files of meta partitions
+	MetaSnapshotSendRateMB              uint64 // MB/s of the snapshots sent by a metanode, 0 keeps the one of the node
+	MetaSnapshotRecvRateMB              uint64 // MB/s of the snapshots received by a metanode, 0 keeps the one of the node
+	MetaSnapshotPartitionRateMB         uint64 // MB/s of the snapshot of a meta partition, 0 keeps the one of the node
+	MetaPartitionHeatSplitOpRate        uint64 // write ops/s of the last meta partition of a volume to split it early, 0 disables
+	MetaPartitionHeatSplitInterval      uint64 // seconds between the heat splits of a volume, 0 is the default
 	// MaxDpCntLimit                       uint64 // datanode data partition limit
 	// MaxMpCntLimit                       uint64 // metanode meta partition limit
 	DataNodeAutoRepairLimitRate uint64 // datanode autorepair limit rate
@@ -210,6 +224,10 @@ type clusterConfig struct {
 	AutoMpMigrate      bool
 	SingleNodeMode     bool
 
+	MpApplyLagThreshold  uint64
+	MpApplyLagCycles     int
+	MpApplyLagAutoRepair bool // rebuild the meta replica on other node if it keeps lagging
+
 	MaxWritableDataPartitionCnt int
 }
 
@@ -249,6 +267,8 @@ func newClusterConfig() (cfg *clusterConfig) {
 	cfg.metaNodeMemHighPer = defaultMetaNodeMemHighPer
 	cfg.metaNodeMemLowPer = defaultMetaNodeMemLowPer
 	cfg.metaNodeMemMidPer = defaultMetaNodeMemHighPer
+	cfg.MpApplyLagThreshold = defaultMpApplyLagThreshold
+	cfg.MpApplyLagCycles = defaultMpApplyLagCycles
 	return
 }
 
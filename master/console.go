@@ -0,0 +1,47 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+//go:embed console
+var consoleAssets embed.FS
+
+// registerConsoleRoutes serves the read-only web console. The assets are served by every
+// master, and the JSON APIs the console calls are proxied to the leader as usual.
+func (m *Server) registerConsoleRoutes(router *mux.Router) {
+	assets, err := fs.Sub(consoleAssets, "console")
+	if err != nil {
+		panic(err)
+	}
+	prefix := strings.TrimSuffix(proto.AdminConsole, "/")
+	router.NewRoute().Name(proto.AdminConsole).
+		Methods(http.MethodGet).
+		Path(prefix).
+		Handler(http.RedirectHandler(proto.AdminConsole, http.StatusMovedPermanently))
+	router.NewRoute().Name(proto.AdminConsole).
+		Methods(http.MethodGet).
+		PathPrefix(proto.AdminConsole).
+		Handler(http.StripPrefix(prefix, http.FileServer(http.FS(assets))))
+}
@@ -0,0 +1,183 @@
+// Read-only console of the cluster, built on the JSON APIs of master.
+(function () {
+  'use strict';
+
+  var GB = 1024 * 1024 * 1024;
+
+  function api(path) {
+    return fetch(path).then(function (resp) {
+      return resp.json();
+    }).then(function (reply) {
+      if (reply.code !== 0) {
+        throw new Error(path + ': ' + reply.msg);
+      }
+      return reply.data;
+    });
+  }
+
+  function esc(v) {
+    return String(v === undefined || v === null ? '' : v).replace(/[&<>"']/g, function (c) {
+      return { '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c];
+    });
+  }
+
+  function gb(bytes) {
+    return (bytes / GB).toFixed(2);
+  }
+
+  function bar(used, total) {
+    var ratio = total > 0 ? Math.min(used / total, 1) : 0;
+    return '<span class="bar"><span style="width:' + (ratio * 100).toFixed(1) + '%"></span></span> ' +
+      (ratio * 100).toFixed(1) + '%';
+  }
+
+  function status(ok, good, bad) {
+    return ok ? '<span class="ok">' + good + '</span>' : '<span class="bad">' + bad + '</span>';
+  }
+
+  function table(head, rows) {
+    var html = '<table><tr>' + head.map(function (h) { return '<th>' + esc(h) + '</th>'; }).join('') + '</tr>';
+    rows.forEach(function (row) {
+      html += '<tr>' + row.map(function (cell) { return '<td>' + cell + '</td>'; }).join('') + '</tr>';
+    });
+    if (rows.length === 0) {
+      html += '<tr><td colspan="' + head.length + '">none</td></tr>';
+    }
+    return html + '</table>';
+  }
+
+  function ids(list) {
+    return list && list.length ? esc(list.join(', ')) : '<span class="ok">none</span>';
+  }
+
+  function nodeStat(name, stat) {
+    stat = stat || {};
+    return [esc(name), gb(stat.TotalGB * GB), gb(stat.UsedGB * GB), gb(stat.AvailGB * GB), bar(stat.UsedGB, stat.TotalGB)];
+  }
+
+  function renderOverview(cv) {
+    var active = function (nodes) {
+      return (nodes || []).filter(function (n) { return n.Status; }).length + ' / ' + (nodes || []).length;
+    };
+    document.getElementById('overview').innerHTML =
+      table(['Item', 'Value'], [
+        ['Created', esc(cv.CreateTime)],
+        ['Applied', esc(cv.Applied)],
+        ['Auto allocation', status(!cv.DisableAutoAlloc, 'enabled', 'disabled')],
+        ['Active master nodes', esc(active(cv.MasterNodes))],
+        ['Active meta nodes', esc(active(cv.MetaNodes))],
+        ['Active data nodes', esc(active(cv.DataNodes))],
+        ['Volumes', esc((cv.VolStatInfo || []).length)],
+        ['Bad data partitions', esc((cv.BadPartitionIDs || []).length)],
+        ['Bad meta partitions', esc((cv.BadMetaPartitionIDs || []).length)]
+      ]) +
+      table(['Space', 'Total GB', 'Used GB', 'Available GB', 'Usage'], [
+        nodeStat('Data nodes', cv.DataNodeStatInfo),
+        nodeStat('Meta nodes', cv.MetaNodeStatInfo)
+      ]);
+  }
+
+  function renderVols(cv, vols) {
+    var info = {};
+    (vols || []).forEach(function (v) { info[v.Name] = v; });
+    var rows = (cv.VolStatInfo || []).slice().sort(function (a, b) {
+      return a.Name < b.Name ? -1 : 1;
+    }).map(function (s) {
+      var v = info[s.Name] || {};
+      return [esc(s.Name), esc(v.Owner), status(v.Status === 0, 'normal', 'deleting'),
+        '<span class="num">' + gb(s.TotalSize) + '</span>', '<span class="num">' + gb(s.UsedSize) + '</span>',
+        bar(s.UsedSize, s.TotalSize), '<span class="num">' + esc(s.InodeCount) + '</span>'];
+    });
+    document.getElementById('vols').innerHTML =
+      table(['Name', 'Owner', 'Status', 'Total GB', 'Used GB', 'Usage', 'Inodes'], rows);
+  }
+
+  function renderPartitions(dp, mp) {
+    dp = dp || {};
+    mp = mp || {};
+    document.getElementById('partitions').innerHTML =
+      '<h3>Data partitions</h3>' +
+      table(['Check', 'Partitions'], [
+        ['Inactive data nodes', ids(dp.InactiveDataNodes)],
+        ['Corrupt', ids(dp.CorruptDataPartitionIDs)],
+        ['Lack replica', ids(dp.LackReplicaDataPartitionIDs)],
+        ['Bad replica', ids(dp.BadReplicaDataPartitionIDs)],
+        ['Excess replica', ids(dp.ExcessReplicaDpIDs)],
+        ['Replica file count differs', ids(dp.RepFileCountDifferDpIDs)],
+        ['Replica used size differs', ids(dp.RepUsedSizeDifferDpIDs)]
+      ]) +
+      '<h3>Meta partitions</h3>' +
+      table(['Check', 'Partitions'], [
+        ['Inactive meta nodes', ids(mp.InactiveMetaNodes)],
+        ['Corrupt', ids(mp.CorruptMetaPartitionIDs)],
+        ['Lack replica', ids(mp.LackReplicaMetaPartitionIDs)],
+        ['Bad replica', ids(mp.BadReplicaMetaPartitionIDs)],
+        ['Excess replica', ids(mp.ExcessReplicaMetaPartitionIDs)],
+        ['Inode count differs', ids(mp.InodeCountNotEqualReplicaMetaPartitionIDs)],
+        ['Dentry count differs', ids(mp.DentryCountNotEqualReplicaMetaPartitionIDs)]
+      ]);
+  }
+
+  function renderNodes(cv) {
+    var rows = function (nodes) {
+      return (nodes || []).map(function (n) {
+        return [esc(n.ID), esc(n.Addr), status(n.Status, 'active', 'inactive'), status(n.IsWritable, 'yes', 'no')];
+      });
+    };
+    var head = ['ID', 'Address', 'Status', 'Writable'];
+    document.getElementById('nodes').innerHTML =
+      '<h3>Master nodes</h3>' + table(head, rows(cv.MasterNodes)) +
+      '<h3>Meta nodes</h3>' + table(head, rows(cv.MetaNodes)) +
+      '<h3>Data nodes</h3>' + table(head, rows(cv.DataNodes));
+  }
+
+  var loaded = {};
+
+  function load(tab) {
+    var err = document.getElementById('error');
+    err.textContent = '';
+    var done = api('/admin/getCluster').then(function (cv) {
+      document.getElementById('cluster-name').textContent = cv.Name;
+      document.getElementById('leader').textContent = 'leader ' + cv.LeaderAddr;
+      if (tab === 'overview') {
+        renderOverview(cv);
+      } else if (tab === 'vols') {
+        return api('/vol/list').then(function (vols) { renderVols(cv, vols); });
+      } else if (tab === 'nodes') {
+        renderNodes(cv);
+      } else if (tab === 'partitions') {
+        return Promise.all([api('/dataPartition/diagnose'), api('/metaPartition/diagnose')]).then(function (r) {
+          renderPartitions(r[0], r[1]);
+        });
+      }
+    });
+    done.then(function () {
+      loaded[tab] = true;
+    }, function (e) {
+      err.textContent = e.message;
+    });
+  }
+
+  function show() {
+    var tab = (location.hash || '#overview').slice(1);
+    if (!document.getElementById(tab)) {
+      tab = 'overview';
+    }
+    document.querySelectorAll('section').forEach(function (s) {
+      s.classList.toggle('active', s.id === tab);
+    });
+    document.querySelectorAll('nav a').forEach(function (a) {
+      a.classList.toggle('active', a.dataset.tab === tab);
+    });
+    if (!loaded[tab]) {
+      load(tab);
+    }
+  }
+
+  document.getElementById('refresh').addEventListener('click', function () {
+    loaded = {};
+    show();
+  });
+  window.addEventListener('hashchange', show);
+  show();
+})();
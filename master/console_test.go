@@ -0,0 +1,45 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsole(t *testing.T) {
+	get := func(path string) (int, string) {
+		resp, err := http.Get(hostAddr + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get(strings.TrimSuffix(proto.AdminConsole, "/"))
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, "console.js")
+	code, body = get(proto.AdminConsole + "console.js")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, "/admin/getCluster")
+	code, _ = get(proto.AdminConsole + "noSuchAsset")
+	require.Equal(t, http.StatusNotFound, code)
+}
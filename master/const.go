@@ -26,9 +26,16 @@ import (
 const (
 	addrKey                 = "addr"
 	diskPathKey             = "disk"
+	rangeSizeKey            = "rangeSize"
+	inodeKey                = "ino"
+	indexKey                = "index"
+	fromKey                 = "from"
+	toKey                   = "to"
 	nameKey                 = "name"
 	idKey                   = "id"
 	countKey                = "count"
+	dryRunKey               = "dryRun"
+	fixPlanKey              = "fixPlan"
 	enableKey               = "enable"
 	thresholdKey            = "threshold"
 	volDeletionDelayTimeKey = "volDeletionDelayTime"
@@ -40,6 +47,8 @@ const (
 	metaPartitionCountKey   = "mpCount"
 	dataPartitionCountKey   = "dpCount"
 	volCapacityKey          = "capacity"
+	preWarmKey              = "preWarm"
+	expectedInodesKey       = "expectedInodes"
 	volDeleteLockTimeKey    = "deleteLockTime"
 	volTypeKey              = "volType"
 
@@ -69,6 +78,15 @@ const (
 	nodeDeleteBatchCountKey                = "batchCount"
 	nodeMarkDeleteRateKey                  = "markDeleteRate"
 	nodeDeleteWorkerSleepMs                = "deleteWorkerSleepMs"
+	metaSnapshotPersistenceModeKey         = "metaSnapshotPersistenceMode"
+	metaSnapshotSendRateKey                = "metaSnapshotSendRateMB"
+	metaSnapshotRecvRateKey                = "metaSnapshotRecvRateMB"
+	metaSnapshotPartitionRateKey           = "metaSnapshotPartitionRateMB"
+	metaPartitionHeatSplitOpRateKey        = "mpHeatSplitOpRate"
+	metaPartitionHeatSplitIntervalKey      = "mpHeatSplitInterval"
+	metaCreateOpRateKey                    = "metaCreateOpRate" // the meta op rates of the vol of nameKey
+	metaDeleteOpRateKey                    = "metaDeleteOpRate"
+	metaLookupOpRateKey                    = "metaLookupOpRate"
 	nodeAutoRepairRateKey                  = "autoRepairRate"
 	nodeDpRepairTimeOutKey                 = "dpRepairTimeOut"
 	nodeDpBackupKey                        = "dpBackupTimeout"
@@ -83,6 +101,7 @@ const (
 	nodeTypeKey                            = "nodeType"
 	ratio                                  = "ratio"
 	rdOnlyKey                              = "rdOnly"
+	nodeStateKey                           = "state"
 	srcAddrKey                             = "srcAddr"
 	targetAddrKey                          = "targetAddr"
 	forceKey                               = "force"
@@ -125,6 +144,7 @@ const (
 	enableQuota                            = "enableQuota"
 	dpDiscardKey                           = "dpDiscard"
 	ignoreDiscardKey                       = "ignoreDiscard"
+	formatKey                              = "format"
 	TrashIntervalKey                       = "trashInterval"
 	ClientIDKey                            = "clientIDKey"
 	verSeqKey                              = "verSeq"
@@ -132,6 +152,20 @@ const (
 	DecommissionType                       = "decommissionType"
 	decommissionDiskLimit                  = "decommissionDiskLimit"
 	dpRepairBlockSizeKey                   = "dpRepairBlockSize"
+	defaultXAttrsKey                       = "xattrs"
+	metaWorkerWeightKey                    = "weight"
+	dpPinPathKey                           = "path"
+	windowStartKey                         = "start"
+	windowEndKey                           = "end"
+	windowReasonKey                        = "reason"
+	freezeKey                              = "freeze"
+	rotateKey                              = "rotate"
+	tsKey                                  = "ts"
+	signKey                                = "sign"
+	clientHostKey                          = "host"
+	clientPidKey                           = "pid"
+	clientRoleKey                          = "role"
+	clientMountTimeKey                     = "mountTime"
 	markDiskBrokenThresholdKey             = "markDiskBrokenThreshold"
 	decommissionTypeKey                    = "decommissionType"
 	autoDecommissionDiskKey                = "autoDecommissionDisk"
@@ -144,6 +178,16 @@ const (
 	trashIntervalKey                       = "trashInterval"
 	accessTimeIntervalKey                  = "accessTimeValidInterval"
 	enablePersistAccessTimeKey             = "enablePersistAccessTime"
+	xattrMaxCountKey                       = "xattrMaxCount"
+	xattrMaxKeySizeKey                     = "xattrMaxKeySize"
+	xattrMaxValueSizeKey                   = "xattrMaxValueSize"
+	xattrMaxTotalSizeKey                   = "xattrMaxTotalSize"
+	extentConflictPolicyKey                = "extentConflictPolicy"
+	enableOpAuditKey                       = "enableOpAudit"
+	enableCheckExtentKeyKey                = "enableCheckExtentKey"
+	enableDirStatKey                       = "enableDirStat"
+	mpConcurrencyKey                       = "mpConcurrency"
+	atimeModeKey                           = "atimeMode"
 	mediaTypeKey                           = "mediaType"
 	allowedStorageClassKey                 = "allowedStorageClass"
 	volStorageClassKey                     = "volStorageClass"
@@ -155,6 +199,8 @@ const (
 	quotaClass                             = "quotaClass"
 	quotaOfClass                           = "quotaOfStorageClass"
 	dataMediaTypeKey                       = "dataMediaType"
+	sourceVolKey                           = "sourceVol"
+	syncIntervalKey                        = "syncInterval"
 
 	remoteCacheEnable            = "remoteCacheEnable"
 	remoteCacheAutoPrepare       = "remoteCacheAutoPrepare"
@@ -205,6 +251,9 @@ const (
 	defaultNormalCrossZoneCnt                     = 3
 	defaultInitMetaPartitionCount                 = 3
 	defaultMaxInitMetaPartitionCount              = 100
+	maxVolDefaultXAttrCount                       = 16
+	maxVolDpPinCount                              = 32
+	maxVolReadOnlyWindowCount                     = 32
 	defaultMaxMetaPartitionInodeID         uint64 = 1<<63 - 1
 	defaultMetaPartitionInodeIDStep        uint64 = 1 << 22
 	defaultMetaNodeReservedMem             uint64 = 1 << 30
@@ -239,6 +288,8 @@ const (
 	defaultEnableDpMetaRepair                     = false
 	defaultAutoDpMetaRepairPallarelCnt            = 100
 	defaultAutoDecommissionDiskInterval           = 10 * time.Second
+	mpNodeSetMigrationRecoverCheck                = 10 * time.Second
+	mpNodeSetMigrationRecoverTimeout              = 30 * time.Minute
 	maxMpCreationCount                            = 10
 	defaultVolForbidWriteOpOfProtoVersion0        = true
 	defaultMetaNodeMemHighPer                     = 0.75
@@ -355,6 +406,12 @@ const (
 
 	opSyncAddFlashManualTask    uint32 = 0x72
 	opSyncDeleteFlashManualTask uint32 = 0x73
+
+	opSyncAddClusterSnapshot    uint32 = 0x74
+	opSyncDeleteClusterSnapshot uint32 = 0x75
+
+	opSyncAddVolClient    uint32 = 0x76
+	opSyncDeleteVolClient uint32 = 0x77
 )
 
 func init() {
@@ -425,6 +482,12 @@ func init() {
 
 		opSyncS3QosSet,
 		opSyncS3QosDelete,
+
+		opSyncAddClusterSnapshot,
+		opSyncDeleteClusterSnapshot,
+
+		opSyncAddVolClient,
+		opSyncDeleteVolClient,
 	} {
 		if _, in := set[op]; in {
 			panic(op)
@@ -489,9 +552,12 @@ const (
 	DecommissionDiskAcronym = "dd"
 	DecommissionDiskPrefix  = keySeparator + DecommissionDiskAcronym + keySeparator
 
-	flashNodePrefix       = keySeparator + "fn" + keySeparator
-	flashGroupPrefix      = keySeparator + "fg" + keySeparator
-	flashManualTaskPrefix = keySeparator + "flt" + keySeparator
+	flashNodePrefix           = keySeparator + "fn" + keySeparator
+	flashGroupPrefix          = keySeparator + "fg" + keySeparator
+	flashManualTaskPrefix     = keySeparator + "flt" + keySeparator
+	clusterSnapshotPrefix     = keySeparator + "cs" + keySeparator
+	clusterSnapshotPartPrefix = keySeparator + "csp" + keySeparator
+	volClientPrefix           = keySeparator + "vc" + keySeparator
 
 	balanceTaskKey = keySeparator + "balanceTask"
 )
@@ -520,6 +586,8 @@ const (
 	CleanTaskFreezed   = "freezeDone"
 	CleanTaskBackuping = "backuping"
 	CleanTaskBackuped  = "backupDone"
+	CleanTaskMerging   = "merging"
+	CleanTaskMerged    = "mergeDone"
 
 	PlanTaskInit  = "init"
 	PlanTaskRun   = "running"
@@ -480,6 +480,11 @@ func (partition *DataPartition) convertToDataPartitionResponse() (dpr *proto.Dat
 	dpr.IsRecover = partition.isRecover
 	dpr.IsDiscard = partition.IsDiscard
 	dpr.MediaType = partition.MediaType
+	for _, replica := range partition.Replicas {
+		if replica.dataNode != nil {
+			dpr.Zones = append(dpr.Zones, replica.dataNode.ZoneName)
+		}
+	}
 	return
 }
 
@@ -0,0 +1,222 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	diagFormatJSON = "json"
+	diagFormatTar  = "tar"
+
+	diagAlarmCount  = 128
+	diagRedactedVal = "******"
+)
+
+// the values of the fields named by them are redacted from the diag bundle
+var diagSecretNames = []string{"secret", "password", "passwd", "accesskey", "authkey", "token"}
+
+// alarmHistory keeps the recent alarms raised by master for the diag bundle.
+type alarmHistory struct {
+	sync.Mutex
+	alarms []proto.DiagAlarm
+}
+
+var recentAlarms = &alarmHistory{}
+
+func (h *alarmHistory) add(key, msg string) {
+	h.Lock()
+	defer h.Unlock()
+	if len(h.alarms) >= diagAlarmCount {
+		h.alarms = append(h.alarms[:0], h.alarms[1:]...)
+	}
+	h.alarms = append(h.alarms, proto.DiagAlarm{Time: time.Now().Format(proto.TimeFormat), Key: key, Msg: msg})
+}
+
+// list returns the alarms from the oldest.
+func (h *alarmHistory) list() []proto.DiagAlarm {
+	h.Lock()
+	defer h.Unlock()
+	return append([]proto.DiagAlarm{}, h.alarms...)
+}
+
+func (m *Server) diagBundle() (bundle *proto.DiagBundle) {
+	bundle = &proto.DiagBundle{
+		Cluster:    m.cluster.Name,
+		LeaderAddr: m.leaderInfo.addr,
+		CreateTime: time.Now().Format(proto.TimeFormat),
+		Topology:   m.topologyView(),
+		Vols:       make([]*proto.SimpleVolView, 0),
+		BadDisks:   m.badDisks(),
+		NodeInfo:   m.nodeInfo(),
+		Alarms:     recentAlarms.list(),
+		Errors:     make([]string, 0),
+	}
+	for _, vol := range m.cluster.allVols() {
+		bundle.Vols = append(bundle.Vols, newSimpleView(vol))
+	}
+	sort.Slice(bundle.Vols, func(i, j int) bool { return bundle.Vols[i].Name < bundle.Vols[j].Name })
+
+	var err error
+	if bundle.DataPartitionDiagnosis, err = m.diagnoseDataPartitions(false); err != nil {
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("diagnose data partitions: %v", err))
+	}
+	if bundle.MetaPartitionDiagnosis, err = m.diagnoseMetaPartitions(); err != nil {
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("diagnose meta partitions: %v", err))
+	}
+	return
+}
+
+// redactDiagBundle returns the sections of the bundle with the values of the secrets redacted.
+func redactDiagBundle(bundle *proto.DiagBundle) (sections map[string]interface{}, err error) {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep the ids as they are
+	decoder.UseNumber()
+	if err = decoder.Decode(&sections); err != nil {
+		return
+	}
+	redactSecrets(sections)
+	return
+}
+
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range diagSecretNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactSecrets(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if s, ok := field.(string); ok && s != "" && isSecretName(name) {
+				v[name] = diagRedactedVal
+				continue
+			}
+			redactSecrets(field)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			redactSecrets(elem)
+		}
+	}
+}
+
+// diagBundleTar packs each section of the bundle into a json file of a gzipped tar.
+func diagBundleTar(sections map[string]interface{}) (data []byte, err error) {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	now := time.Now()
+	for _, name := range names {
+		var content []byte
+		if content, err = json.MarshalIndent(sections[name], "", "  "); err != nil {
+			return
+		}
+		hdr := &tar.Header{Name: name + ".json", Mode: 0o644, Size: int64(len(content)), ModTime: now}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return
+		}
+		if _, err = tw.Write(content); err != nil {
+			return
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return
+	}
+	if err = gw.Close(); err != nil {
+		return
+	}
+	return buf.Bytes(), nil
+}
+
+// getDiagBundle gathers the topology, the vols, the partitions diagnosed, the bad disks, the
+// node settings and the recent alarms of the cluster into one json, or a gzipped tar of a
+// json file per section by format=tar.
+func (m *Server) getDiagBundle(w http.ResponseWriter, r *http.Request) {
+	var (
+		err      error
+		format   string
+		sections map[string]interface{}
+		data     []byte
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDiagBundle))
+	defer func() {
+		doStatAndMetric(proto.AdminDiagBundle, metric, err, nil)
+	}()
+
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if format = r.FormValue(formatKey); format == "" {
+		format = diagFormatJSON
+	}
+	if format != diagFormatJSON && format != diagFormatTar {
+		err = fmt.Errorf("format %v should be %v or %v", format, diagFormatJSON, diagFormatTar)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if sections, err = redactDiagBundle(m.diagBundle()); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if format == diagFormatJSON {
+		sendOkReply(w, r, newSuccessHTTPReply(sections))
+		return
+	}
+
+	if data, err = diagBundleTar(sections); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"diag-%v-%v.tar.gz\"", m.cluster.Name, time.Now().Unix()))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if _, err = w.Write(data); err != nil {
+		log.LogErrorf("getDiagBundle: write len[%d] to remoteAddr[%v] err:[%v]", len(data), r.RemoteAddr, err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	sections := map[string]interface{}{
+		"Vols": []interface{}{map[string]interface{}{
+			"Name":        "vol",
+			"OSSSecure":   map[string]interface{}{"AccessKey": "ak", "SecretKey": "sk"},
+			"EnableToken": true,
+			"Password":    "",
+		}},
+	}
+	redactSecrets(sections)
+	vol := sections["Vols"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "vol", vol["Name"])
+	require.Equal(t, map[string]interface{}{"AccessKey": diagRedactedVal, "SecretKey": diagRedactedVal}, vol["OSSSecure"])
+	require.Equal(t, true, vol["EnableToken"])
+	require.Equal(t, "", vol["Password"])
+}
+
+func TestDiagBundle(t *testing.T) {
+	WarnBySpecialKey("diag_bundle_test", "alarm for the diag bundle")
+	bundle, err := mc.AdminAPI().GetDiagBundle()
+	require.NoError(t, err)
+	require.Equal(t, server.cluster.Name, bundle.Cluster)
+	require.NotNil(t, bundle.DataPartitionDiagnosis)
+	require.NotNil(t, bundle.MetaPartitionDiagnosis)
+	require.NotEmpty(t, bundle.Vols)
+	keys := make([]string, 0)
+	for _, alarm := range bundle.Alarms {
+		keys = append(keys, alarm.Key)
+	}
+	require.Contains(t, keys, "diag_bundle_test")
+
+	resp, err := http.Get(fmt.Sprintf("%v%v?%v=%v", hostAddr, proto.AdminDiagBundle, formatKey, diagFormatTar))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/gzip", resp.Header.Get("Content-Type"))
+	gr, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	names := make([]string, 0)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	require.Contains(t, names, "Vols.json")
+	require.Contains(t, names, "Alarms.json")
+
+	reply := processNoCheck(fmt.Sprintf("%v%v?%v=zip", hostAddr, proto.AdminDiagBundle, formatKey), t)
+	require.EqualValues(t, proto.ErrCodeParamError, reply.Code)
+}
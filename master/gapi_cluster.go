@@ -313,6 +313,7 @@ func (m *ClusterService) getTopology(ctx context.Context, args struct{}) (*proto
 					DomainAddr: metaNode.DomainAddr, Status: metaNode.IsActive,
 					IsWritable: metaNode.IsWriteAble(), MediaType: proto.MediaType_Unspecified,
 					Ratio: metaNode.Ratio, SystemRatio: CaculateNodeMemoryRatio(metaNode),
+					NoPlacement: metaNode.NoPlacement,
 				})
 				return true
 			})
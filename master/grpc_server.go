@@ -0,0 +1,485 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMethodAPIs maps the methods of the MasterAdmin service to the HTTP APIs they stand
+// for, the api limiters and metrics of the APIs apply to them as well.
+var grpcMethodAPIs = map[string]string{
+	"/proto.MasterAdmin/GetCluster":                proto.AdminGetCluster,
+	"/proto.MasterAdmin/ListDataNodes":             proto.AdminGetCluster,
+	"/proto.MasterAdmin/ListMetaNodes":             proto.AdminGetCluster,
+	"/proto.MasterAdmin/ListVols":                  proto.AdminListVols,
+	"/proto.MasterAdmin/GetVol":                    proto.AdminGetVol,
+	"/proto.MasterAdmin/CreateVol":                 proto.AdminCreateVol,
+	"/proto.MasterAdmin/DeleteVol":                 proto.AdminDeleteVol,
+	"/proto.MasterAdmin/ListDataPartitions":        proto.ClientDataPartitions,
+	"/proto.MasterAdmin/GetDataPartition":          proto.AdminGetDataPartition,
+	"/proto.MasterAdmin/DecommissionDataPartition": proto.AdminDecommissionDataPartition,
+	"/proto.MasterAdmin/ListMetaPartitions":        proto.ClientMetaPartitions,
+	"/proto.MasterAdmin/GetMetaPartition":          proto.ClientMetaPartition,
+	"/proto.MasterAdmin/DecommissionMetaPartition": proto.AdminDecommissionMetaPartition,
+}
+
+// adminGrpcServer implements proto.MasterAdminServer on the cluster of the leader.
+type adminGrpcServer struct {
+	m *Server
+}
+
+func (m *Server) startGrpcService() {
+	if m.grpcPort == "" {
+		return
+	}
+	if m.cluster.authenticate {
+		log.LogWarnf("action[startGrpcService] grpc gateway is not started, it does not support authentication")
+		return
+	}
+	addr := fmt.Sprintf(":%s", m.grpcPort)
+	if m.bindIp {
+		addr = fmt.Sprintf("%s:%s", m.ip, m.grpcPort)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.LogErrorf("action[startGrpcService] listen on %v failed: err(%v)", addr, err)
+		return
+	}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(m.grpcUnaryInterceptor),
+		grpc.StreamInterceptor(m.grpcStreamInterceptor),
+	)
+	proto.RegisterMasterAdminServer(server, &adminGrpcServer{m: m})
+	go func() {
+		if err := server.Serve(ln); err != nil {
+			log.LogErrorf("action[startGrpcService] serve grpc server failed: err(%v)", err)
+		}
+	}()
+	m.grpcServer = server
+	log.LogInfof("action[startGrpcService] grpc gateway listens on %v", addr)
+}
+
+// grpcCheck rejects the calls on a follower and waits for the limiter of the API.
+func (m *Server) grpcCheck(api string) error {
+	if !m.partition.IsRaftLeader() {
+		return status.Errorf(codes.Unavailable, "not the leader, leader is [%v]", m.leaderInfo.addr)
+	}
+	if err := m.cluster.apiLimiter.Wait(api); err != nil {
+		return status.Errorf(codes.ResourceExhausted, "too many requests for api: %s", api)
+	}
+	return nil
+}
+
+func (m *Server) grpcUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	api := grpcMethodAPIs[info.FullMethod]
+	metric := exporter.NewTPCnt(apiToMetricsName(api))
+	defer func() {
+		doStatAndMetric(api, metric, err, nil)
+	}()
+	if err = m.grpcCheck(api); err != nil {
+		return
+	}
+	return handler(ctx, req)
+}
+
+func (m *Server) grpcStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	api := grpcMethodAPIs[info.FullMethod]
+	metric := exporter.NewTPCnt(apiToMetricsName(api))
+	defer func() {
+		doStatAndMetric(api, metric, err, nil)
+	}()
+	if err = m.grpcCheck(api); err != nil {
+		return
+	}
+	return handler(srv, ss)
+}
+
+// grpcError converts the code of an HTTPReply to the status of grpc.
+func grpcError(code int32, msg string) error {
+	switch code {
+	case proto.ErrCodeSuccess:
+		return nil
+	case proto.ErrCodeParamError:
+		return status.Error(codes.InvalidArgument, msg)
+	case proto.ErrCodeVolNotExists, proto.ErrCodeDataPartitionNotExists, proto.ErrCodeMetaPartitionNotExists:
+		return status.Error(codes.NotFound, msg)
+	case proto.ErrCodeVolAuthKeyNotMatch:
+		return status.Error(codes.PermissionDenied, msg)
+	default:
+		return status.Error(codes.Internal, msg)
+	}
+}
+
+func grpcClusterError(err error) error {
+	reply := newErrHTTPReply(err)
+	return grpcError(reply.Code, reply.Msg)
+}
+
+// grpcReplyWriter keeps the reply of an HTTP handler called by the gateway.
+type grpcReplyWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *grpcReplyWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *grpcReplyWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *grpcReplyWriter) WriteHeader(statusCode int) {}
+
+// serveHTTP runs the HTTP handler of api with the args, so the operations changing the
+// cluster are parsed, checked and audited in one place, and returns the message replied.
+func (s *adminGrpcServer) serveHTTP(ctx context.Context, api string, handler http.HandlerFunc, args url.Values) (msg string, err error) {
+	r, err := http.NewRequest(http.MethodPost, api, strings.NewReader(args.Encode()))
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	w := &grpcReplyWriter{header: make(http.Header)}
+	handler(w, r)
+
+	reply := &proto.HTTPReply{}
+	if err = json.Unmarshal(w.body.Bytes(), reply); err != nil {
+		return "", status.Errorf(codes.Internal, "unmarshal reply of %v failed: %v", api, err)
+	}
+	if err = grpcError(reply.Code, reply.Msg); err != nil {
+		return
+	}
+	if data, ok := reply.Data.(string); ok {
+		msg = data
+	}
+	return
+}
+
+func (s *adminGrpcServer) GetCluster(ctx context.Context, req *proto.GetClusterRequest) (*proto.ClusterInfoReply, error) {
+	c := s.m.cluster
+	info := &proto.ClusterInfoReply{
+		Name:                 c.Name,
+		LeaderAddr:           s.m.leaderInfo.addr,
+		DisableAutoAlloc:     c.DisableAutoAllocate,
+		ForbidMpDecommission: c.ForbidMpDecommission,
+		MetaNodeThreshold:    c.cfg.MetaNodeThreshold,
+		Applied:              s.m.fsm.applied,
+		MaxDataPartitionID:   c.idAlloc.dataPartitionID,
+		MaxMetaPartitionID:   c.idAlloc.metaPartitionID,
+		MaxMetaNodeID:        c.idAlloc.commonID,
+		MasterCount:          uint32(len(c.allMasterNodes())),
+		DataNodeCount:        uint32(len(c.allDataNodes())),
+		MetaNodeCount:        uint32(len(c.allMetaNodes())),
+		VolCount:             uint32(len(c.allVolNames())),
+	}
+	if stat := c.dataNodeStatInfo; stat != nil {
+		info.DataTotalGB, info.DataUsedGB = stat.TotalGB, stat.UsedGB
+	}
+	if stat := c.metaNodeStatInfo; stat != nil {
+		info.MetaTotalGB, info.MetaUsedGB = stat.TotalGB, stat.UsedGB
+	}
+	return info, nil
+}
+
+func (s *adminGrpcServer) ListDataNodes(req *proto.ListNodesRequest, stream proto.MasterAdmin_ListDataNodesServer) (err error) {
+	nodes := make([]*proto.NodeInfoReply, 0)
+	s.m.cluster.dataNodes.Range(func(key, value interface{}) bool {
+		dataNode := value.(*DataNode)
+		if req.ZoneName != "" && dataNode.ZoneName != req.ZoneName {
+			return true
+		}
+		isWritable := dataNode.IsWriteAble()
+		dataNode.RLock()
+		nodes = append(nodes, &proto.NodeInfoReply{
+			ID:             dataNode.ID,
+			Addr:           dataNode.Addr,
+			DomainAddr:     dataNode.DomainAddr,
+			ZoneName:       dataNode.ZoneName,
+			NodeSetID:      dataNode.NodeSetID,
+			IsActive:       dataNode.isActive,
+			IsWritable:     isWritable,
+			Total:          dataNode.Total,
+			Used:           dataNode.Used,
+			PartitionCount: dataNode.DataPartitionCount,
+			MediaType:      dataNode.MediaType,
+		})
+		dataNode.RUnlock()
+		return true
+	})
+	return sendNodeInfos(nodes, stream.Send)
+}
+
+func (s *adminGrpcServer) ListMetaNodes(req *proto.ListNodesRequest, stream proto.MasterAdmin_ListMetaNodesServer) (err error) {
+	nodes := make([]*proto.NodeInfoReply, 0)
+	s.m.cluster.metaNodes.Range(func(key, value interface{}) bool {
+		metaNode := value.(*MetaNode)
+		if req.ZoneName != "" && metaNode.ZoneName != req.ZoneName {
+			return true
+		}
+		isWritable := metaNode.IsWriteAble()
+		metaNode.RLock()
+		nodes = append(nodes, &proto.NodeInfoReply{
+			ID:             metaNode.ID,
+			Addr:           metaNode.Addr,
+			DomainAddr:     metaNode.DomainAddr,
+			ZoneName:       metaNode.ZoneName,
+			NodeSetID:      metaNode.NodeSetID,
+			IsActive:       metaNode.IsActive,
+			IsWritable:     isWritable,
+			Total:          metaNode.Total,
+			Used:           metaNode.Used,
+			PartitionCount: uint32(metaNode.MetaPartitionCount),
+		})
+		metaNode.RUnlock()
+		return true
+	})
+	return sendNodeInfos(nodes, stream.Send)
+}
+
+func sendNodeInfos(nodes []*proto.NodeInfoReply, send func(*proto.NodeInfoReply) error) (err error) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	for _, node := range nodes {
+		if err = send(node); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func newVolInfoReply(vol *Vol) *proto.VolInfoReply {
+	stat := volStat(vol, false)
+	return &proto.VolInfoReply{
+		ID:           vol.ID,
+		Name:         vol.Name,
+		Owner:        vol.Owner,
+		ZoneName:     vol.zoneName,
+		Status:       uint32(vol.status()),
+		VolType:      uint32(vol.VolType),
+		Capacity:     vol.Capacity,
+		TotalSize:    stat.TotalSize,
+		UsedSize:     stat.UsedSize,
+		DpReplicaNum: uint32(vol.dpReplicaNum),
+		MpReplicaNum: uint32(vol.mpReplicaNum),
+		DpCount:      uint32(vol.getDataPartitionsCount()),
+		RwDpCount:    uint32(vol.dataPartitions.readableAndWritableCnt),
+		MpCount:      uint32(len(vol.cloneMetaPartitionMap())),
+		CreateTime:   vol.createTime,
+		CrossZone:    vol.crossZone,
+		FollowerRead: vol.FollowerRead,
+	}
+}
+
+func (s *adminGrpcServer) ListVols(req *proto.ListVolsRequest, stream proto.MasterAdmin_ListVolsServer) (err error) {
+	names := s.m.cluster.allVolNames()
+	sort.Strings(names)
+	for _, name := range names {
+		if !strings.Contains(name, req.Keywords) {
+			continue
+		}
+		vol, err1 := s.m.cluster.getVol(name)
+		if err1 != nil {
+			continue
+		}
+		if err = stream.Send(newVolInfoReply(vol)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *adminGrpcServer) GetVol(ctx context.Context, req *proto.GetVolRequest) (*proto.VolInfoReply, error) {
+	vol, err := s.m.cluster.getVol(req.Name)
+	if err != nil {
+		return nil, grpcClusterError(proto.ErrVolNotExists)
+	}
+	return newVolInfoReply(vol), nil
+}
+
+func (s *adminGrpcServer) CreateVol(ctx context.Context, req *proto.CreateVolRequest) (*proto.VolInfoReply, error) {
+	args := url.Values{}
+	args.Set(nameKey, req.Name)
+	args.Set(volOwnerKey, req.Owner)
+	args.Set(volCapacityKey, strconv.FormatUint(req.Capacity, 10))
+	args.Set(volTypeKey, strconv.FormatUint(uint64(req.VolType), 10))
+	args.Set(replicaNumKey, strconv.FormatUint(uint64(req.DpReplicaNum), 10))
+	args.Set(crossZoneKey, strconv.FormatBool(req.CrossZone))
+	args.Set(followerReadKey, strconv.FormatBool(req.FollowerRead))
+	if req.ZoneName != "" {
+		args.Set(zoneNameKey, req.ZoneName)
+	}
+	if req.MpCount != 0 {
+		args.Set(metaPartitionCountKey, strconv.FormatUint(uint64(req.MpCount), 10))
+	}
+	if req.DpSize != 0 {
+		args.Set(dataPartitionSizeKey, strconv.FormatUint(req.DpSize, 10))
+	}
+	if req.Description != "" {
+		args.Set(descriptionKey, req.Description)
+	}
+	if _, err := s.serveHTTP(ctx, proto.AdminCreateVol, s.m.createVol, args); err != nil {
+		return nil, err
+	}
+	return s.GetVol(ctx, &proto.GetVolRequest{Name: req.Name})
+}
+
+func (s *adminGrpcServer) DeleteVol(ctx context.Context, req *proto.DeleteVolRequest) (*proto.AdminOpReply, error) {
+	args := url.Values{}
+	args.Set(nameKey, req.Name)
+	args.Set(volAuthKey, req.AuthKey)
+	msg, err := s.serveHTTP(ctx, proto.AdminDeleteVol, s.m.markDeleteVol, args)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.AdminOpReply{Msg: msg}, nil
+}
+
+func newDataPartitionReply(dp *DataPartition) *proto.DataPartitionReply {
+	dp.RLock()
+	defer dp.RUnlock()
+	return &proto.DataPartitionReply{
+		PartitionID: dp.PartitionID,
+		VolName:     dp.VolName,
+		Status:      int32(dp.Status),
+		ReplicaNum:  uint32(dp.ReplicaNum),
+		Hosts:       append([]string{}, dp.Hosts...),
+		LeaderAddr:  dp.getLeaderAddr(),
+		Total:       dp.total,
+		Used:        dp.used,
+		IsRecover:   dp.isRecover,
+		IsDiscard:   dp.IsDiscard,
+		MediaType:   dp.MediaType,
+	}
+}
+
+func newMetaPartitionReply(mp *MetaPartition) *proto.MetaPartitionReply {
+	mp.RLock()
+	defer mp.RUnlock()
+	reply := &proto.MetaPartitionReply{
+		PartitionID: mp.PartitionID,
+		VolName:     mp.volName,
+		Status:      int32(mp.Status),
+		ReplicaNum:  uint32(mp.ReplicaNum),
+		Hosts:       append([]string{}, mp.Hosts...),
+		Start:       mp.Start,
+		End:         mp.End,
+		MaxInodeID:  mp.MaxInodeID,
+		InodeCount:  mp.InodeCount,
+		DentryCount: mp.DentryCount,
+		IsRecover:   mp.IsRecover,
+	}
+	if mr, err := mp.getMetaReplicaLeader(); err == nil {
+		reply.LeaderAddr = mr.Addr
+	}
+	return reply
+}
+
+func (s *adminGrpcServer) ListDataPartitions(req *proto.ListPartitionsRequest, stream proto.MasterAdmin_ListDataPartitionsServer) (err error) {
+	vol, err := s.m.cluster.getVol(req.VolName)
+	if err != nil {
+		return grpcClusterError(proto.ErrVolNotExists)
+	}
+	dps := make([]*DataPartition, 0)
+	for _, dp := range vol.cloneDataPartitionMap() {
+		dps = append(dps, dp)
+	}
+	sort.Slice(dps, func(i, j int) bool { return dps[i].PartitionID < dps[j].PartitionID })
+	for _, dp := range dps {
+		if err = stream.Send(newDataPartitionReply(dp)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *adminGrpcServer) GetDataPartition(ctx context.Context, req *proto.GetPartitionRequest) (*proto.DataPartitionReply, error) {
+	dp, err := s.m.cluster.getDataPartitionByID(req.PartitionID)
+	if err != nil {
+		return nil, grpcClusterError(proto.ErrDataPartitionNotExists)
+	}
+	return newDataPartitionReply(dp), nil
+}
+
+func (s *adminGrpcServer) DecommissionDataPartition(ctx context.Context, req *proto.DecommissionPartitionRequest) (*proto.AdminOpReply, error) {
+	args := url.Values{}
+	args.Set(idKey, strconv.FormatUint(req.PartitionID, 10))
+	args.Set(addrKey, req.Addr)
+	msg, err := s.serveHTTP(ctx, proto.AdminDecommissionDataPartition, s.m.decommissionDataPartition, args)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.AdminOpReply{Msg: msg}, nil
+}
+
+func (s *adminGrpcServer) ListMetaPartitions(req *proto.ListPartitionsRequest, stream proto.MasterAdmin_ListMetaPartitionsServer) (err error) {
+	vol, err := s.m.cluster.getVol(req.VolName)
+	if err != nil {
+		return grpcClusterError(proto.ErrVolNotExists)
+	}
+	mps := make([]*MetaPartition, 0)
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		mps = append(mps, mp)
+	}
+	sort.Slice(mps, func(i, j int) bool { return mps[i].PartitionID < mps[j].PartitionID })
+	for _, mp := range mps {
+		if err = stream.Send(newMetaPartitionReply(mp)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *adminGrpcServer) GetMetaPartition(ctx context.Context, req *proto.GetPartitionRequest) (*proto.MetaPartitionReply, error) {
+	mp, err := s.m.cluster.getMetaPartitionByID(req.PartitionID)
+	if err != nil {
+		return nil, grpcClusterError(proto.ErrMetaPartitionNotExists)
+	}
+	return newMetaPartitionReply(mp), nil
+}
+
+func (s *adminGrpcServer) DecommissionMetaPartition(ctx context.Context, req *proto.DecommissionPartitionRequest) (*proto.AdminOpReply, error) {
+	args := url.Values{}
+	args.Set(idKey, strconv.FormatUint(req.PartitionID, 10))
+	args.Set(addrKey, req.Addr)
+	msg, err := s.serveHTTP(ctx, proto.AdminDecommissionMetaPartition, s.m.decommissionMetaPartition, args)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.AdminOpReply{Msg: msg}, nil
+}
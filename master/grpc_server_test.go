@@ -0,0 +1,98 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func newGrpcClientForTest(t *testing.T) proto.MasterAdminClient {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(server.grpcUnaryInterceptor),
+		grpc.StreamInterceptor(server.grpcStreamInterceptor),
+	)
+	proto.RegisterMasterAdminServer(s, &adminGrpcServer{m: server})
+	go s.Serve(ln)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return proto.NewMasterAdminClient(conn)
+}
+
+func TestGrpcGateway(t *testing.T) {
+	client := newGrpcClientForTest(t)
+	ctx := context.Background()
+
+	cluster, err := client.GetCluster(ctx, &proto.GetClusterRequest{})
+	require.NoError(t, err)
+	require.Equal(t, server.cluster.Name, cluster.Name)
+	require.NotZero(t, cluster.DataNodeCount)
+
+	stream, err := client.ListDataNodes(ctx, &proto.ListNodesRequest{ZoneName: testZone1})
+	require.NoError(t, err)
+	for {
+		node, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, testZone1, node.ZoneName)
+	}
+
+	vol, err := client.GetVol(ctx, &proto.GetVolRequest{Name: commonVolName})
+	require.NoError(t, err)
+	require.Equal(t, commonVolName, vol.Name)
+	_, err = client.GetVol(ctx, &proto.GetVolRequest{Name: "grpcNotExist"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	dps, err := client.ListDataPartitions(ctx, &proto.ListPartitionsRequest{VolName: commonVolName})
+	require.NoError(t, err)
+	dp, err := dps.Recv()
+	require.NoError(t, err)
+	require.Equal(t, commonVolName, dp.VolName)
+	got, err := client.GetDataPartition(ctx, &proto.GetPartitionRequest{PartitionID: dp.PartitionID})
+	require.NoError(t, err)
+	require.Equal(t, dp.Hosts, got.Hosts)
+	_, err = client.GetMetaPartition(ctx, &proto.GetPartitionRequest{PartitionID: 1 << 40})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// the mutating calls go through the http handlers
+	name := "grpcVol"
+	created, err := client.CreateVol(ctx, &proto.CreateVolRequest{
+		Name: name, Owner: testOwner, Capacity: 100, ZoneName: testZone2, DpReplicaNum: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, name, created.Name)
+	_, err = client.CreateVol(ctx, &proto.CreateVolRequest{Name: name, Owner: testOwner, Capacity: 100})
+	require.Error(t, err)
+	_, err = client.DeleteVol(ctx, &proto.DeleteVolRequest{Name: name, AuthKey: "wrong"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.DeleteVol(ctx, &proto.DeleteVolRequest{Name: name, AuthKey: buildAuthKey(testOwner)})
+	require.NoError(t, err)
+}
@@ -37,6 +37,7 @@ import (
 func (m *Server) startHTTPService(modulename string, cfg *config.Config) {
 	router := mux.NewRouter().SkipClean(true)
 	m.registerAPIRoutes(router)
+	m.registerConsoleRoutes(router)
 	m.registerAPIMiddleware(router)
 	if m.cluster.authenticate {
 		m.registerAuthenticationMiddleware(router)
@@ -120,7 +121,7 @@ func (m *Server) registerAPIMiddleware(route *mux.Router) {
 
 				log.LogInfof("action[interceptor] request, remote[%v] method[%v] path[%v] query[%v]",
 					r.RemoteAddr, r.Method, r.URL.Path, r.URL.Query())
-				if mux.CurrentRoute(r).GetName() == proto.AdminGetIP {
+				if name := mux.CurrentRoute(r).GetName(); name == proto.AdminGetIP || name == proto.AdminConsole {
 					next.ServeHTTP(w, r)
 					return
 				}
@@ -163,11 +164,12 @@ func (m *Server) registerAPIMiddleware(route *mux.Router) {
 // AuthenticationUri2MsgTypeMap define the mapping from authentication uri to message type
 var AuthenticationUri2MsgTypeMap = map[string]proto.MsgType{
 	// Master API cluster management
-	proto.AdminClusterFreeze: proto.MsgMasterClusterFreezeReq,
-	proto.AddRaftNode:        proto.MsgMasterAddRaftNodeReq,
-	proto.RemoveRaftNode:     proto.MsgMasterRemoveRaftNodeReq,
-	proto.AdminSetNodeInfo:   proto.MsgMasterSetNodeInfoReq,
-	proto.AdminSetNodeRdOnly: proto.MsgMasterSetNodeRdOnlyReq,
+	proto.AdminClusterFreeze:      proto.MsgMasterClusterFreezeReq,
+	proto.AddRaftNode:             proto.MsgMasterAddRaftNodeReq,
+	proto.RemoveRaftNode:          proto.MsgMasterRemoveRaftNodeReq,
+	proto.AdminSetNodeInfo:        proto.MsgMasterSetNodeInfoReq,
+	proto.AdminSetNodeRdOnly:      proto.MsgMasterSetNodeRdOnlyReq,
+	proto.AdminSetNodeStateByAddr: proto.MsgMasterSetNodeStateReq,
 
 	// Master API volume management
 	proto.AdminCreateVol:                 proto.MsgMasterCreateVolReq,
@@ -284,6 +286,15 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.AdminGetCluster).
 		HandlerFunc(m.getCluster)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminCreateClusterSnapshot).
+		HandlerFunc(m.createClusterSnapshot)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListClusterSnapshots).
+		HandlerFunc(m.listClusterSnapshots)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminClusterDiff).
+		HandlerFunc(m.getClusterDiff)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.AdminGetOpLog).
 		HandlerFunc(m.getOpLog)
@@ -323,6 +334,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 		Path(proto.RaftStatus).
 		HandlerFunc(m.getRaftStatus)
 	router.NewRoute().Methods(http.MethodGet).Path(proto.AdminClusterStat).HandlerFunc(m.clusterStat)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminNodeTasks).
+		HandlerFunc(m.getNodeTasks)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminSetCheckDataReplicasEnable).
 		HandlerFunc(m.setCheckDataReplicasEnable)
@@ -416,6 +430,12 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminDeleteVol).
 		HandlerFunc(m.markDeleteVol)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListVolRecycleBin).
+		HandlerFunc(m.listVolRecycleBin)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminPurgeVolRecycleBin).
+		HandlerFunc(m.purgeVolRecycleBin)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminUpdateVol).
 		HandlerFunc(m.updateVol)
@@ -425,6 +445,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolExpand).
 		HandlerFunc(m.volExpand)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminVolPreWarmStatus).
+		HandlerFunc(m.getVolPreWarmStatus)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.ClientVol).
 		HandlerFunc(m.getVol)
@@ -455,6 +478,60 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolSetDpRepairBlockSize).
 		HandlerFunc(m.setVolDpRepairBlockSize)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolSetDefaultXAttrs).
+		HandlerFunc(m.setVolDefaultXAttrs)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolSetMetaWorkerWeight).
+		HandlerFunc(m.setVolMetaWorkerWeight)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolSetMetaMediaType).
+		HandlerFunc(m.setVolMetaMediaType)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminVolCheckMetaMediaType).
+		HandlerFunc(m.checkVolMetaMediaType)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolSetDpPin).
+		HandlerFunc(m.setVolDpPin)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolRemoveDpPin).
+		HandlerFunc(m.removeVolDpPin)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolAddReadOnlyWindow).
+		HandlerFunc(m.addVolReadOnlyWindow)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminVolListReadOnlyWindows).
+		HandlerFunc(m.listVolReadOnlyWindows)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolCancelReadOnlyWindow).
+		HandlerFunc(m.cancelVolReadOnlyWindow)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolMetaFreeze).
+		HandlerFunc(m.setVolMetaFreeze)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolMetaAuth).
+		HandlerFunc(m.setVolMetaAuth)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolPurge).
+		HandlerFunc(m.setVolPurge)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolClientKeepAlive).
+		HandlerFunc(m.volClientKeepAlive)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminVolClients).
+		HandlerFunc(m.getVolClients)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminCreateVolReplica).
+		HandlerFunc(m.createVolReplica)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetVolReplicaStatus).
+		HandlerFunc(m.getVolReplicaStatus)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSyncVolReplica).
+		HandlerFunc(m.syncVolReplica)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListVolReplicas).
+		HandlerFunc(m.listVolReplicas)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.AdminQueryDecommissionFailedDisk).
 		HandlerFunc(m.QueryDecommissionFailedDisk)
@@ -521,6 +598,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminDecommissionMetaPartition).
 		HandlerFunc(m.decommissionMetaPartition)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminMigrateMetaPartitionToNodeSet).
+		HandlerFunc(m.migrateMetaPartitionToNodeSet)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminChangeMetaPartitionLeader).
 		HandlerFunc(m.changeMetaPartitionLeader)
@@ -530,6 +610,12 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.ClientMetaPartitions).
 		HandlerFunc(m.getMetaPartitions)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.ClientNsEventEndpoints).
+		HandlerFunc(m.getNsEventEndpoints)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.ClientMetaToken).
+		HandlerFunc(m.getMetaAccessToken)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.ClientMetaPartition).
 		HandlerFunc(m.getMetaPartition)
@@ -581,12 +667,33 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminMetaPartitionCleanEmpty).
 		HandlerFunc(m.cleanEmptyMetaPartition)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminMetaPartitionMergeEmpty).
+		HandlerFunc(m.mergeEmptyMetaPartition)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminMetaPartitionRemoveBackup).
 		HandlerFunc(m.removeBackupMetaPartition)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.AdminMetaPartitionGetCleanTask).
 		HandlerFunc(m.getCleanMetaPartitionTask)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminMetaPartitionLagInfo).
+		HandlerFunc(m.getMetaPartitionLagInfo)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminNodeBlastRadius).
+		HandlerFunc(m.getNodeBlastRadius)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminDiffMetaPartitionTree).
+		HandlerFunc(m.diffMetaPartitionTree)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminRepairMetaInodeNLink).
+		HandlerFunc(m.repairMetaInodeNLink)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminTruncateMetaRaftLog).
+		HandlerFunc(m.truncateMetaRaftLog)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminMetaPartitionDistribution).
+		HandlerFunc(m.getMetaPartitionDistribution)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.CreateMetaNodeBalanceTask).
 		HandlerFunc(m.createMetaNodeBalancePlan)
@@ -800,6 +907,15 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminGetNodeInfo).
 		HandlerFunc(m.getNodeInfoHandler)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminDiagBundle).
+		HandlerFunc(m.getDiagBundle)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminExportClusterConfig).
+		HandlerFunc(m.exportClusterConfigHandler)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.AdminImportClusterConfig).
+		HandlerFunc(m.importClusterConfigHandler)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminGetIsDomainOn).
 		HandlerFunc(m.getIsDomainOn)
@@ -827,6 +943,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminSetNodeRdOnly).
 		HandlerFunc(m.setNodeRdOnlyHandler)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSetNodeStateByAddr).
+		HandlerFunc(m.setNodeStateByAddrHandler)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminSetDpRdOnly).
 		HandlerFunc(m.setDpRdOnlyHandler)
@@ -879,6 +998,15 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.GetAllZones).
 		HandlerFunc(m.listZone)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.EvacuateZone).
+		HandlerFunc(m.evacuateZone)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.EvacuateZoneStatus).
+		HandlerFunc(m.evacuateZoneStatus)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.EvacuateZoneAbort).
+		HandlerFunc(m.abortEvacuateZone)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.GetAllNodeSets).
 		HandlerFunc(m.listNodeSets)
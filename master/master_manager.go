@@ -299,6 +299,18 @@ func (m *Server) loadMetadata() {
 	}
 	log.LogInfo("action[loadS3QoSInfo] end")
 
+	log.LogInfo("action[loadClusterSnapshots] begin")
+	if err = m.cluster.loadClusterSnapshots(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadClusterSnapshots] end")
+
+	log.LogInfo("action[loadVolClients] begin")
+	if err = m.cluster.loadVolClients(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadVolClients] end")
+
 	m.cluster.checkMediaVaild()
 
 	log.LogInfo("action[loadMetadata] end")
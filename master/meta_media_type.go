@@ -0,0 +1,127 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// A vol with the meta media type has its meta partitions placed on the meta nodes of the media
+// type only, the ones the disk of which is reported of it by heartbeat. The meta nodes of the
+// other media types are excluded when the hosts of a new partition, or of a replica moved by
+// decommission, are selected.
+
+func (c *Cluster) getMetaMediaTypeOfVol(volName string) uint32 {
+	vol, err := c.getVol(volName)
+	if err != nil {
+		return proto.MediaType_Unspecified
+	}
+	return vol.getMetaMediaType()
+}
+
+// excludeMetaHostsNotOfMediaType returns the hosts to exclude by the selection of meta nodes,
+// which are excludeHosts and the meta nodes not of the media type if specified.
+func (c *Cluster) excludeMetaHostsNotOfMediaType(excludeHosts []string, mediaType uint32) (hosts []string) {
+	if mediaType == proto.MediaType_Unspecified {
+		return excludeHosts
+	}
+	hosts = append([]string{}, excludeHosts...)
+	c.metaNodes.Range(func(addr, node interface{}) bool {
+		metaNode := node.(*MetaNode)
+		metaNode.RLock()
+		nodeMediaType := metaNode.MediaType
+		metaNode.RUnlock()
+		if nodeMediaType != mediaType {
+			hosts = append(hosts, addr.(string))
+		}
+		return true
+	})
+	return
+}
+
+// metaHostsNotOfMediaType returns the hosts which are not meta nodes of the media type.
+func (c *Cluster) metaHostsNotOfMediaType(hosts []string, mediaType uint32) (unmatched []string) {
+	if mediaType == proto.MediaType_Unspecified {
+		return
+	}
+	for _, host := range hosts {
+		metaNode, err := c.metaNode(host)
+		if err != nil {
+			unmatched = append(unmatched, host)
+			continue
+		}
+		metaNode.RLock()
+		nodeMediaType := metaNode.MediaType
+		metaNode.RUnlock()
+		if nodeMediaType != mediaType {
+			unmatched = append(unmatched, host)
+		}
+	}
+	return
+}
+
+func (c *Cluster) checkMetaHostsMediaType(hosts []string, mediaType uint32) (err error) {
+	if unmatched := c.metaHostsNotOfMediaType(hosts, mediaType); len(unmatched) > 0 {
+		err = fmt.Errorf("meta nodes %v are not of media type %v", unmatched, proto.MediaTypeString(mediaType))
+	}
+	return
+}
+
+// countWritableMetaNodesOfMediaType returns the count of the meta nodes of the media type new
+// partitions can be created on.
+func (c *Cluster) countWritableMetaNodesOfMediaType(mediaType uint32) (count int) {
+	c.metaNodes.Range(func(_, node interface{}) bool {
+		metaNode := node.(*MetaNode)
+		metaNode.RLock()
+		nodeMediaType := metaNode.MediaType
+		metaNode.RUnlock()
+		if nodeMediaType == mediaType && metaNode.IsWriteAble() {
+			count++
+		}
+		return true
+	})
+	return
+}
+
+// getMetaMediaTypeReport lists the meta partitions of the vol which have replicas on the meta
+// nodes not of the media type, they are moved by decommissioning those replicas.
+func (c *Cluster) getMetaMediaTypeReport(vol *Vol, mediaType uint32) (report *proto.MetaMediaTypeReport) {
+	report = &proto.MetaMediaTypeReport{
+		VolName:    vol.Name,
+		MediaType:  mediaType,
+		Partitions: make([]*proto.MetaPartitionMediaTypeReport, 0),
+	}
+	if mediaType == proto.MediaType_Unspecified {
+		return
+	}
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		mp.RLock()
+		hosts := append([]string{}, mp.Hosts...)
+		mp.RUnlock()
+		if unmatched := c.metaHostsNotOfMediaType(hosts, mediaType); len(unmatched) > 0 {
+			report.Partitions = append(report.Partitions, &proto.MetaPartitionMediaTypeReport{
+				PartitionID: mp.PartitionID,
+				Hosts:       unmatched,
+			})
+		}
+	}
+	sort.Slice(report.Partitions, func(i, j int) bool {
+		return report.Partitions[i].PartitionID < report.Partitions[j].PartitionID
+	})
+	return
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaMediaType(t *testing.T) {
+	c := &Cluster{}
+	for addr, mediaType := range map[string]uint32{
+		"ssd1": proto.MediaType_SSD,
+		"ssd2": proto.MediaType_SSD,
+		"hdd1": proto.MediaType_HDD,
+		"none": proto.MediaType_Unspecified,
+	} {
+		c.metaNodes.Store(addr, &MetaNode{Addr: addr, MediaType: mediaType})
+	}
+
+	require.Equal(t, []string{"ssd1"}, c.excludeMetaHostsNotOfMediaType([]string{"ssd1"}, proto.MediaType_Unspecified))
+	excludeHosts := c.excludeMetaHostsNotOfMediaType([]string{"ssd1"}, proto.MediaType_SSD)
+	require.ElementsMatch(t, []string{"ssd1", "hdd1", "none"}, excludeHosts)
+
+	require.NoError(t, c.checkMetaHostsMediaType([]string{"ssd1", "ssd2"}, proto.MediaType_SSD))
+	require.NoError(t, c.checkMetaHostsMediaType([]string{"hdd1"}, proto.MediaType_Unspecified))
+	require.Error(t, c.checkMetaHostsMediaType([]string{"ssd1", "hdd1"}, proto.MediaType_SSD))
+
+	vol := newVol(volValue{ID: 1, Name: "mediaVol"})
+	require.Zero(t, vol.getMetaMediaType())
+	vol.setMetaMediaType(proto.MediaType_SSD)
+	require.Equal(t, proto.MediaType_SSD, newVolFromVolValue(newVolValue(vol)).getMetaMediaType())
+
+	compliant := newMetaPartition(1, 1, 100, 2, vol.Name, vol.ID, 0)
+	compliant.setHosts([]string{"ssd1", "ssd2"})
+	vol.addMetaPartition(compliant)
+	mixed := newMetaPartition(2, 101, defaultMaxMetaPartitionInodeID, 2, vol.Name, vol.ID, 0)
+	mixed.setHosts([]string{"ssd1", "hdd1"})
+	vol.addMetaPartition(mixed)
+
+	report := c.getMetaMediaTypeReport(vol, proto.MediaType_SSD)
+	require.Len(t, report.Partitions, 1)
+	require.EqualValues(t, 2, report.Partitions[0].PartitionID)
+	require.Equal(t, []string{"hdd1"}, report.Partitions[0].Hosts)
+	require.Empty(t, c.getMetaMediaTypeReport(vol, proto.MediaType_Unspecified).Partitions)
+}
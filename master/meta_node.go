@@ -43,6 +43,7 @@ type MetaNode struct {
 	Threshold                        float32
 	ReportTime                       time.Time
 	metaPartitionInfos               []*proto.MetaPartitionReport
+	reportSeq                        uint64 // seq of metaPartitionInfos, the base of the delta reports
 	MetaPartitionCount               int
 	NodeSetID                        uint64
 	sync.RWMutex                     `graphql:"-"`
@@ -55,6 +56,10 @@ type MetaNode struct {
 	HeartbeatPort                    string             `json:"HeartbeatPort"`
 	ReplicaPort                      string             `json:"ReplicaPort"`
 	ReceivedForbidWriteOpOfProtoVer0 bool
+	Draining                         bool   // no partition is created on a draining node
+	Drained                          bool   // safe to restart
+	NoPlacement                      bool   // set by the operators, no partition is created on the node
+	MediaType                        uint32 // of the disk the partitions are stored on, reported by heartbeat
 }
 
 func newMetaNode(addr, heartbeatPort, replicaPort, zoneName, clusterID string) (node *MetaNode) {
@@ -141,7 +146,7 @@ func (metaNode *MetaNode) IsWriteAble() (ok bool) {
 	defer metaNode.RUnlock()
 	if metaNode.IsActive && metaNode.MaxMemAvailWeight > gConfig.metaNodeReservedMem &&
 		!metaNode.reachesThreshold() && metaNode.MetaPartitionCount < defaultMaxMetaPartitionCountOnEachNode &&
-		!metaNode.RdOnly {
+		!metaNode.RdOnly && !metaNode.Draining && !metaNode.NoPlacement {
 		ok = true
 	}
 	return
@@ -154,12 +159,19 @@ func (metaNode *MetaNode) setNodeActive() {
 	metaNode.IsActive = true
 }
 
-func (metaNode *MetaNode) updateMetric(resp *proto.MetaNodeHeartbeatResponse, threshold float32) {
+// updateMetric updates the node by the heartbeat, it returns the reports of the partitions
+// unchanged since the last heartbeat for a delta report.
+func (metaNode *MetaNode) updateMetric(resp *proto.MetaNodeHeartbeatResponse, threshold float32) (unchanged []*proto.MetaPartitionReport) {
 	metaNode.Lock()
 	defer metaNode.Unlock()
 
 	metaNode.DomainAddr = util.ParseIpAddrToDomainAddr(metaNode.Addr)
-	metaNode.metaPartitionInfos = resp.MetaPartitionReports
+	if resp.DeltaReport {
+		unchanged = metaNode.mergeDeltaReport(resp)
+	} else {
+		metaNode.metaPartitionInfos = resp.MetaPartitionReports
+		metaNode.reportSeq = resp.ReportSeq
+	}
 	metaNode.MetaPartitionCount = len(metaNode.metaPartitionInfos)
 	metaNode.Total = resp.Total
 	metaNode.Used = resp.Used
@@ -178,6 +190,43 @@ func (metaNode *MetaNode) updateMetric(resp *proto.MetaNodeHeartbeatResponse, th
 	metaNode.Threshold = threshold
 	metaNode.NodeMemTotal = resp.NodeMemTotal
 	metaNode.NodeMemUsed = resp.NodeMemUsed
+	if metaNode.Drained != resp.Drained {
+		log.LogWarnf("action[updateMetric] metaNode[%v] draining(%v) drained from %v to %v",
+			metaNode.Addr, resp.Draining, metaNode.Drained, resp.Drained)
+	}
+	metaNode.Draining = resp.Draining
+	metaNode.Drained = resp.Drained
+	metaNode.MediaType = resp.MediaType
+	return
+}
+
+// mergeDeltaReport applies the changed and removed partitions of the delta report to the
+// reports held. If the delta is not based on them, the changed ones are still taken and a
+// full report is asked by the next heartbeat.
+func (metaNode *MetaNode) mergeDeltaReport(resp *proto.MetaNodeHeartbeatResponse) (unchanged []*proto.MetaPartitionReport) {
+	if metaNode.reportSeq == 0 || resp.BaseReportSeq != metaNode.reportSeq {
+		log.LogWarnf("action[mergeDeltaReport] metaNode[%v] delta report base seq[%v] mismatch seq[%v], ask for a full report",
+			metaNode.Addr, resp.BaseReportSeq, metaNode.reportSeq)
+		metaNode.reportSeq = 0
+		return
+	}
+	skip := make(map[uint64]bool, len(resp.MetaPartitionReports)+len(resp.RemovedPartitions))
+	for _, mr := range resp.MetaPartitionReports {
+		skip[mr.PartitionID] = true
+	}
+	for _, id := range resp.RemovedPartitions {
+		skip[id] = true
+	}
+	infos := make([]*proto.MetaPartitionReport, 0, len(metaNode.metaPartitionInfos))
+	for _, mr := range metaNode.metaPartitionInfos {
+		if !skip[mr.PartitionID] {
+			unchanged = append(unchanged, mr)
+			infos = append(infos, mr)
+		}
+	}
+	metaNode.metaPartitionInfos = append(infos, resp.MetaPartitionReports...)
+	metaNode.reportSeq = resp.ReportSeq
+	return
 }
 
 func (metaNode *MetaNode) reachesThreshold() bool {
@@ -199,6 +248,10 @@ func (metaNode *MetaNode) createHeartbeatTask(masterAddr string, fileStatsEnable
 	request.NotifyForbidWriteOpOfProtoVer0 = notifyForbidWriteOpOfProtoVer0
 	request.RaftPartitionCanUsingDifferentPortEnabled = RaftPartitionCanUsingDifferentPortEnabled
 	request.MetaNodeGOGC = metaNodeGOGC
+	request.MetaDeltaReport = true
+	metaNode.RLock()
+	request.MetaReportSeq = metaNode.reportSeq
+	metaNode.RUnlock()
 	task = proto.NewAdminTask(proto.OpMetaNodeHeartbeat, metaNode.Addr, request)
 	return
 }
@@ -0,0 +1,65 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaNodeDeltaReport(t *testing.T) {
+	metaNode := newMetaNode("127.0.0.1:17210", "", "", testZone1, "test")
+	report := func(id, inodes uint64) *proto.MetaPartitionReport {
+		return &proto.MetaPartitionReport{PartitionID: id, InodeCnt: inodes}
+	}
+	ids := func() (ids []uint64) {
+		for _, mr := range metaNode.metaPartitionInfos {
+			ids = append(ids, mr.PartitionID)
+		}
+		return
+	}
+
+	require.Nil(t, metaNode.updateMetric(&proto.MetaNodeHeartbeatResponse{
+		ReportSeq:            10,
+		MetaPartitionReports: []*proto.MetaPartitionReport{report(1, 1), report(2, 1), report(3, 1)},
+	}, 0.75))
+	require.Equal(t, 3, metaNode.MetaPartitionCount)
+	task := metaNode.createHeartbeatTask("", false, nil, false, 0, false)
+	require.EqualValues(t, 10, task.Request.(*proto.HeartBeatRequest).MetaReportSeq)
+
+	unchanged := metaNode.updateMetric(&proto.MetaNodeHeartbeatResponse{
+		DeltaReport:          true,
+		BaseReportSeq:        10,
+		ReportSeq:            11,
+		MetaPartitionReports: []*proto.MetaPartitionReport{report(2, 2), report(4, 1)},
+		RemovedPartitions:    []uint64{3},
+	}, 0.75)
+	require.Len(t, unchanged, 1)
+	require.EqualValues(t, 1, unchanged[0].PartitionID)
+	require.ElementsMatch(t, []uint64{1, 2, 4}, ids())
+	require.Equal(t, 3, metaNode.MetaPartitionCount)
+	require.EqualValues(t, 11, metaNode.reportSeq)
+
+	// a delta not based on the reports held asks for a full report
+	require.Nil(t, metaNode.updateMetric(&proto.MetaNodeHeartbeatResponse{
+		DeltaReport:   true,
+		BaseReportSeq: 10,
+		ReportSeq:     12,
+	}, 0.75))
+	require.ElementsMatch(t, []uint64{1, 2, 4}, ids())
+	require.Zero(t, metaNode.reportSeq)
+}
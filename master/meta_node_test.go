@@ -2,10 +2,13 @@ package master
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetaNode(t *testing.T) {
@@ -31,3 +34,44 @@ func decommissionMetaNode(addr string, t *testing.T) {
 	reqURL := fmt.Sprintf("%v%v?addr=%v", hostAddr, proto.DecommissionMetaNode, addr)
 	process(reqURL, t)
 }
+
+func TestSetMetaNodeNoPlacement(t *testing.T) {
+	metaNode, err := server.cluster.metaNode(mms1Addr)
+	require.NoError(t, err)
+	ns, err := server.cluster.t.getNodeSetByNodeSetId(metaNode.NodeSetID)
+	require.NoError(t, err)
+	setState := func(state string) {
+		reqURL := fmt.Sprintf("%v%v?addr=%v&state=%v", hostAddr, proto.AdminSetNodeStateByAddr, mms1Addr, state)
+		process(reqURL, t)
+	}
+
+	setState(proto.NodeStateNoPlacement)
+	require.True(t, metaNode.NoPlacement)
+	require.False(t, metaNode.IsWriteAble())
+	for i := 0; i < 10; i++ {
+		hosts, _, err := ns.getAvailMetaNodeHosts(nil, 1)
+		if err == nil {
+			require.NotContains(t, hosts, mms1Addr)
+		}
+	}
+	tv := server.topologyView()
+	var found bool
+	for _, zone := range tv.Zones {
+		for _, nsView := range zone.NodeSet {
+			for _, mn := range nsView.MetaNodes {
+				if mn.Addr == mms1Addr {
+					found = true
+					require.True(t, mn.NoPlacement)
+				}
+			}
+		}
+	}
+	require.True(t, found)
+
+	setState(proto.NodeStateNormal)
+	require.False(t, metaNode.NoPlacement)
+
+	r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("%v?addr=%v&state=offline", proto.AdminSetNodeStateByAddr, mms1Addr), nil)
+	_, _, err = parseSetNodeStateParam(r)
+	require.Error(t, err)
+}
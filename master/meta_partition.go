@@ -48,6 +48,12 @@ type MetaReplica struct {
 	StatByMigrateStorageClass []*proto.StatOfStorageClass
 	metaNode                  *MetaNode
 	ReadOnlyReasons           uint32
+	Stat                      *proto.MetaPartitionStat
+	ApplyID                   uint64
+	applyLagCycles            int // continuous heartbeat cycles of lagging behind the leader
+	PurgeBacklogCount         uint64
+	PurgeBacklogBytes         uint64
+	PurgePaused               bool
 }
 
 // MetaPartition defines the structure of a meta partition
@@ -86,6 +92,8 @@ type MetaPartition struct {
 	sync.RWMutex
 
 	LastDelReplicaTime int64
+	lagRepairing       int32
+	hotReports         int // the leader reports hot in a row
 }
 
 func newMetaReplica(start, end uint64, metaNode *MetaNode) (mr *MetaReplica) {
@@ -425,6 +433,25 @@ func (mp *MetaPartition) missingReplicaAddrs() (lackAddrs []string) {
 	return
 }
 
+// touchReplica refreshes the report time of the replica on addr, which is reported unchanged.
+func (mp *MetaPartition) touchReplica(addr string) {
+	if !contains(mp.Hosts, addr) {
+		return
+	}
+	mp.Lock()
+	defer mp.Unlock()
+	mr, err := mp.getMetaReplica(addr)
+	if err != nil {
+		return
+	}
+	mr.setLastReportTime()
+	if mr.IsLeader {
+		mp.LeaderReportTime = time.Now().Unix()
+	}
+	mp.removeMissingReplica(addr)
+	mp.setHeartBeatDone()
+}
+
 func (mp *MetaPartition) updateMetaPartition(mgr *proto.MetaPartitionReport, metaNode *MetaNode, c *Cluster) {
 	if !contains(mp.Hosts, metaNode.Addr) {
 		return
@@ -439,6 +466,7 @@ func (mp *MetaPartition) updateMetaPartition(mgr *proto.MetaPartitionReport, met
 	mr.updateMetric(mgr)
 	if mr.IsLeader {
 		mp.LeaderReportTime = time.Now().Unix()
+		mp.checkApplyLag(c)
 	}
 	mp.setMaxInodeID()
 	mp.setInodeCount()
@@ -646,17 +674,20 @@ func (mp *MetaPartition) replicaCreationTasks(clusterID, volName string) (tasks
 	return
 }
 
-func (mp *MetaPartition) buildNewMetaPartitionTasks(specifyAddrs []string, peers []proto.Peer, volName string) (tasks []*proto.AdminTask) {
+func (mp *MetaPartition) buildNewMetaPartitionTasks(specifyAddrs []string, peers []proto.Peer, volName string,
+	defaultXAttrs map[string]string,
+) (tasks []*proto.AdminTask) {
 	tasks = make([]*proto.AdminTask, 0)
 	var hosts []string
 
 	req := &proto.CreateMetaPartitionRequest{
-		Start:       mp.Start,
-		End:         mp.End,
-		PartitionID: mp.PartitionID,
-		Members:     peers,
-		VolName:     volName,
-		VerSeq:      mp.VerSeq,
+		Start:         mp.Start,
+		End:           mp.End,
+		PartitionID:   mp.PartitionID,
+		Members:       peers,
+		VolName:       volName,
+		VerSeq:        mp.VerSeq,
+		DefaultXAttrs: defaultXAttrs,
 	}
 	if specifyAddrs == nil {
 		hosts = mp.Hosts
@@ -710,14 +741,15 @@ func (mp *MetaPartition) createTaskToTryToChangeLeader(addr string) (task *proto
 	return
 }
 
-func (mp *MetaPartition) createTaskToCreateReplica(host string) (t *proto.AdminTask, err error) {
+func (mp *MetaPartition) createTaskToCreateReplica(host string, defaultXAttrs map[string]string) (t *proto.AdminTask, err error) {
 	req := &proto.CreateMetaPartitionRequest{
-		Start:       mp.Start,
-		End:         mp.End,
-		PartitionID: mp.PartitionID,
-		Members:     mp.Peers,
-		VolName:     mp.volName,
-		VerSeq:      mp.VerSeq,
+		Start:         mp.Start,
+		End:           mp.End,
+		PartitionID:   mp.PartitionID,
+		Members:       mp.Peers,
+		VolName:       mp.volName,
+		VerSeq:        mp.VerSeq,
+		DefaultXAttrs: defaultXAttrs,
 	}
 	t = proto.NewAdminTask(proto.OpCreateMetaPartition, host, req)
 	resetMetaPartitionTaskID(t, mp.PartitionID)
@@ -801,6 +833,13 @@ func (mr *MetaReplica) updateMetric(mgr *proto.MetaPartitionReport) {
 	mr.dataSize = mgr.Size
 	mr.ForbidWriteOpOfProtoVer0 = mgr.ForbidWriteOpOfProtoVer0
 	mr.ReadOnlyReasons = mgr.ReadOnlyReasons
+	mr.ApplyID = mgr.ApplyID
+	mr.PurgeBacklogCount = mgr.PurgeBacklogCount
+	mr.PurgeBacklogBytes = mgr.PurgeBacklogBytes
+	mr.PurgePaused = mgr.PurgePaused
+	if mgr.Stat != nil {
+		mr.Stat = mgr.Stat
+	}
 
 	if mgr.StatByStorageClass != nil {
 		mr.StatByStorageClass = mgr.StatByStorageClass
@@ -0,0 +1,240 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// metaHostZone is a host of a meta partition with the zone and nodeset of its meta node.
+type metaHostZone struct {
+	addr     string
+	zone     string
+	nodeSet  uint64
+	isLeader bool
+	known    bool
+}
+
+// metaZonePolicy is the zone policy of the meta partitions of a volume. The replicas of a volume
+// not cross zone are in one zone, those of a volume cross zone are spread over zoneNum zones, with
+// no more than ceil(replicaNum/zoneNum) in a zone. allowed is empty if any zone is allowed.
+type metaZonePolicy struct {
+	allowed    map[string]bool
+	crossZone  bool
+	zoneNum    int
+	maxPerZone int
+}
+
+func (c *Cluster) metaZonePolicyOf(vol *Vol) (policy metaZonePolicy) {
+	policy.allowed = make(map[string]bool)
+	for _, zone := range strings.Split(vol.zoneName, ",") {
+		if zone != "" {
+			policy.allowed[zone] = true
+		}
+	}
+	replicaNum := int(vol.mpReplicaNum)
+	policy.crossZone = vol.crossZone
+	policy.zoneNum, policy.maxPerZone = 1, replicaNum
+	if !vol.crossZone || replicaNum < 2 {
+		return
+	}
+	zoneCnt := len(policy.allowed)
+	if zoneCnt == 0 {
+		zoneCnt = len(c.t.getAllZones())
+	}
+	policy.zoneNum = replicaNum
+	if policy.zoneNum > zoneCnt {
+		policy.zoneNum = zoneCnt
+	}
+	if policy.zoneNum > defaultReplicaNum {
+		policy.zoneNum = defaultReplicaNum
+	}
+	if policy.zoneNum < 1 {
+		policy.zoneNum = 1
+	}
+	policy.maxPerZone = (replicaNum + policy.zoneNum - 1) / policy.zoneNum
+	return
+}
+
+// check returns the reasons the hosts break the policy and the hosts to decommission to restore
+// it, the followers are moved before the leader.
+func (policy metaZonePolicy) check(hosts []*metaHostZone) (reasons []string, moves map[string]string) {
+	moves = make(map[string]string)
+	addReason := func(reason string) {
+		for _, r := range reasons {
+			if r == reason {
+				return
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+	move := func(h *metaHostZone, reason string) {
+		addReason(reason)
+		moves[h.addr] = reason
+	}
+	hosts = append([]*metaHostZone(nil), hosts...)
+	sort.SliceStable(hosts, func(i, j int) bool { return !hosts[i].isLeader && hosts[j].isLeader })
+
+	kept := make(map[string][]*metaHostZone)
+	for _, h := range hosts {
+		if !h.known {
+			move(h, proto.ZoneViolationUnknownNode)
+			continue
+		}
+		if len(policy.allowed) > 0 && !policy.allowed[h.zone] {
+			move(h, proto.ZoneViolationNotAllowed)
+			continue
+		}
+		kept[h.zone] = append(kept[h.zone], h)
+	}
+	zones := make([]string, 0, len(kept))
+	for zone := range kept {
+		zones = append(zones, zone)
+	}
+	// the zones with more replicas, or the leader, are kept first
+	hasLeader := func(zone string) bool {
+		for _, h := range kept[zone] {
+			if h.isLeader {
+				return true
+			}
+		}
+		return false
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if len(kept[zones[i]]) != len(kept[zones[j]]) {
+			return len(kept[zones[i]]) > len(kept[zones[j]])
+		}
+		if li, lj := hasLeader(zones[i]), hasLeader(zones[j]); li != lj {
+			return li
+		}
+		return zones[i] < zones[j]
+	})
+
+	if !policy.crossZone {
+		for i := 1; i < len(zones); i++ {
+			for _, h := range kept[zones[i]] {
+				move(h, proto.ZoneViolationMultiZone)
+			}
+		}
+		return
+	}
+	if len(zones) >= policy.zoneNum && len(kept[zones[0]]) <= policy.maxPerZone {
+		return
+	}
+	for _, zone := range zones {
+		for i := policy.maxPerZone; i < len(kept[zone]); i++ {
+			move(kept[zone][i-policy.maxPerZone], proto.ZoneViolationConcentrated)
+		}
+	}
+	// the zones are too few but none is over populated, e.g. 2 of 4 replicas in each of 2 zones
+	if len(zones) < policy.zoneNum {
+		addReason(proto.ZoneViolationConcentrated)
+		if len(moves) == 0 && len(zones) > 0 && len(kept[zones[0]]) > 1 {
+			move(kept[zones[0]][0], proto.ZoneViolationConcentrated)
+		}
+	}
+	return
+}
+
+func (c *Cluster) metaHostZones(mp *MetaPartition) (hosts []*metaHostZone) {
+	mp.RLock()
+	defer mp.RUnlock()
+	hosts = make([]*metaHostZone, 0, len(mp.Hosts))
+	for _, addr := range mp.Hosts {
+		h := &metaHostZone{addr: addr}
+		if mr, err := mp.getMetaReplica(addr); err == nil {
+			h.isLeader = mr.IsLeader
+		}
+		if metaNode, err := c.metaNode(addr); err == nil {
+			h.zone, h.nodeSet, h.known = metaNode.ZoneName, metaNode.NodeSetID, true
+		}
+		hosts = append(hosts, h)
+	}
+	return
+}
+
+// getMetaPartitionDistribution counts the replicas of the meta partitions of the volumes in each
+// zone, nodeset and meta node, and finds the partitions breaking the zone policy of their volumes.
+// With fixPlan, the decommissions restoring the policy are returned too.
+func (c *Cluster) getMetaPartitionDistribution(vols []*Vol, fixPlan bool) (dist *proto.MetaPartitionDistribution) {
+	dist = &proto.MetaPartitionDistribution{Vols: make([]*proto.VolMetaPartitionDistribution, 0, len(vols))}
+	for _, vol := range vols {
+		vd := c.volMetaPartitionDistribution(vol, fixPlan)
+		dist.ViolationCount += len(vd.Violations)
+		dist.Vols = append(dist.Vols, vd)
+	}
+	sort.Slice(dist.Vols, func(i, j int) bool {
+		return dist.Vols[i].VolName < dist.Vols[j].VolName
+	})
+	return
+}
+
+func (c *Cluster) volMetaPartitionDistribution(vol *Vol, fixPlan bool) (vd *proto.VolMetaPartitionDistribution) {
+	vd = &proto.VolMetaPartitionDistribution{
+		VolName:    vol.Name,
+		ZoneName:   vol.zoneName,
+		CrossZone:  vol.crossZone,
+		ReplicaNum: vol.mpReplicaNum,
+		Zones:      make(map[string]int),
+		NodeSets:   make(map[uint64]int),
+		MetaNodes:  make(map[string]int),
+		Violations: make([]*proto.MetaPartitionZoneViolation, 0),
+	}
+	if fixPlan {
+		vd.FixPlan = make([]*proto.MetaPartitionFixOp, 0)
+	}
+	policy := c.metaZonePolicyOf(vol)
+	for _, mp := range vol.getSortMetaPartitions() {
+		vd.MetaPartitionCount++
+		hosts := c.metaHostZones(mp)
+		zones := make(map[string]int)
+		for _, h := range hosts {
+			vd.MetaNodes[h.addr]++
+			if !h.known {
+				continue
+			}
+			zones[h.zone]++
+			vd.Zones[h.zone]++
+			vd.NodeSets[h.nodeSet]++
+		}
+		reasons, moves := policy.check(hosts)
+		if len(reasons) == 0 {
+			continue
+		}
+		vd.Violations = append(vd.Violations, &proto.MetaPartitionZoneViolation{
+			PartitionID: mp.PartitionID,
+			Reasons:     reasons,
+			Zones:       zones,
+		})
+		if !fixPlan {
+			continue
+		}
+		for _, h := range hosts {
+			if reason, ok := moves[h.addr]; ok {
+				vd.FixPlan = append(vd.FixPlan, &proto.MetaPartitionFixOp{
+					Op:          proto.AdminDecommissionMetaPartition,
+					PartitionID: mp.PartitionID,
+					Addr:        h.addr,
+					Zone:        h.zone,
+					Reason:      reason,
+				})
+			}
+		}
+	}
+	return
+}
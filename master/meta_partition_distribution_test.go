@@ -0,0 +1,81 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaZonePolicyCheck(t *testing.T) {
+	hosts := func(zones ...string) (hs []*metaHostZone) {
+		for i, zone := range zones {
+			hs = append(hs, &metaHostZone{addr: string(rune('a' + i)), zone: zone, isLeader: i == 0, known: zone != ""})
+		}
+		return
+	}
+
+	single := metaZonePolicy{allowed: map[string]bool{}, zoneNum: 1, maxPerZone: 3}
+	reasons, moves := single.check(hosts("z1", "z1", "z1"))
+	require.Empty(t, reasons)
+	require.Empty(t, moves)
+	// the zone of the leader is kept on a tie
+	reasons, moves = single.check(hosts("z2", "z1", "z3"))
+	require.Equal(t, []string{proto.ZoneViolationMultiZone}, reasons)
+	require.Equal(t, map[string]string{"b": proto.ZoneViolationMultiZone, "c": proto.ZoneViolationMultiZone}, moves)
+
+	cross := metaZonePolicy{allowed: map[string]bool{"z1": true, "z2": true, "z3": true}, crossZone: true, zoneNum: 3, maxPerZone: 1}
+	_, moves = cross.check(hosts("z1", "z2", "z3"))
+	require.Empty(t, moves)
+	// two replicas in one zone, the follower is moved
+	reasons, moves = cross.check(hosts("z1", "z1", "z2"))
+	require.Equal(t, []string{proto.ZoneViolationConcentrated}, reasons)
+	require.Equal(t, map[string]string{"b": proto.ZoneViolationConcentrated}, moves)
+	reasons, moves = cross.check(hosts("z1", "z4", ""))
+	require.ElementsMatch(t, []string{proto.ZoneViolationNotAllowed, proto.ZoneViolationUnknownNode, proto.ZoneViolationConcentrated}, reasons)
+	require.Equal(t, map[string]string{"b": proto.ZoneViolationNotAllowed, "c": proto.ZoneViolationUnknownNode}, moves)
+
+	// 2 zones for 3 replicas allow 2 in a zone
+	twoZones := metaZonePolicy{allowed: map[string]bool{}, crossZone: true, zoneNum: 2, maxPerZone: 2}
+	_, moves = twoZones.check(hosts("z1", "z1", "z2"))
+	require.Empty(t, moves)
+	_, moves = twoZones.check(hosts("z1", "z1", "z1"))
+	require.Equal(t, map[string]string{"b": proto.ZoneViolationConcentrated}, moves)
+}
+
+func TestMetaPartitionDistribution(t *testing.T) {
+	vol, err := server.cluster.getVol(commonVolName)
+	require.NoError(t, err)
+	dist, err := mc.AdminAPI().GetMetaPartitionDistribution(commonVolName, true)
+	require.NoError(t, err)
+	require.Len(t, dist.Vols, 1)
+	vd := dist.Vols[0]
+	require.Equal(t, commonVolName, vd.VolName)
+	require.Equal(t, len(vol.MetaPartitions), vd.MetaPartitionCount)
+	replicas := 0
+	for _, cnt := range vd.MetaNodes {
+		replicas += cnt
+	}
+	require.Equal(t, vd.MetaPartitionCount*int(vol.mpReplicaNum), replicas)
+	require.Equal(t, len(vd.Violations), dist.ViolationCount)
+	for _, op := range vd.FixPlan {
+		require.Equal(t, proto.AdminDecommissionMetaPartition, op.Op)
+	}
+
+	_, err = mc.AdminAPI().GetMetaPartitionDistribution("notExistVol", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultMetaPartitionHeatSplitInterval = 600 // seconds
+	// the leader reports in a row over the op rate to take the partition as hot, not to split
+	// it by a burst
+	metaPartitionHeatSplitHotReports = 3
+)
+
+func (c *Cluster) metaPartitionHeatSplitOpRate() uint64 {
+	return atomic.LoadUint64(&c.cfg.MetaPartitionHeatSplitOpRate)
+}
+
+func (c *Cluster) metaPartitionHeatSplitInterval() uint64 {
+	if interval := atomic.LoadUint64(&c.cfg.MetaPartitionHeatSplitInterval); interval > 0 {
+		return interval
+	}
+	return defaultMetaPartitionHeatSplitInterval
+}
+
+func (c *Cluster) setMetaPartitionHeatSplit(policy *uint64, val uint64) (err error) {
+	oldVal := atomic.LoadUint64(policy)
+	atomic.StoreUint64(policy, val)
+	if err = c.syncPutCluster(); err != nil {
+		log.LogErrorf("action[setMetaPartitionHeatSplit] err[%v]", err)
+		atomic.StoreUint64(policy, oldVal)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	return
+}
+
+// recordHeat counts the reports of the leader in a row with the write ops over opRate, and
+// returns if the partition is hot. It is never hot if opRate is 0.
+func (mp *MetaPartition) recordHeat(mr *proto.MetaPartitionReport, opRate uint64) (hot bool) {
+	if !mr.IsLeader {
+		return false
+	}
+	mp.Lock()
+	defer mp.Unlock()
+	if opRate == 0 || mr.OpRate < opRate {
+		mp.hotReports = 0
+		return false
+	}
+	mp.hotReports++
+	return mp.hotReports >= metaPartitionHeatSplitHotReports
+}
+
+// takeHeatSplit returns if the vol may split its last meta partition by heat now, at most once
+// in the interval.
+func (vol *Vol) takeHeatSplit(interval uint64) bool {
+	now := time.Now().Unix()
+	last := atomic.LoadInt64(&vol.lastHeatSplit)
+	if now-last < int64(interval) {
+		return false
+	}
+	return atomic.CompareAndSwapInt64(&vol.lastHeatSplit, last, now)
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaPartitionHeat(t *testing.T) {
+	mp := &MetaPartition{PartitionID: 1}
+	leader := &proto.MetaPartitionReport{PartitionID: 1, IsLeader: true, OpRate: 500}
+	follower := &proto.MetaPartitionReport{PartitionID: 1, OpRate: 500}
+
+	// disabled by the op rate 0
+	for i := 0; i < metaPartitionHeatSplitHotReports; i++ {
+		require.False(t, mp.recordHeat(leader, 0))
+	}
+	// hot once the leader reports it hot in a row, the followers are not counted
+	require.False(t, mp.recordHeat(leader, 100))
+	require.False(t, mp.recordHeat(follower, 100))
+	require.False(t, mp.recordHeat(leader, 100))
+	require.True(t, mp.recordHeat(leader, 100))
+	// a cool report starts over
+	require.False(t, mp.recordHeat(&proto.MetaPartitionReport{IsLeader: true, OpRate: 10}, 100))
+	require.False(t, mp.recordHeat(leader, 100))
+
+	// a split by heat in an interval of the vol
+	vol := &Vol{Name: "heat"}
+	require.True(t, vol.takeHeatSplit(600))
+	require.False(t, vol.takeHeatSplit(600))
+	require.True(t, vol.takeHeatSplit(0))
+}
+
+func TestSetMetaPartitionHeatSplit(t *testing.T) {
+	reqUrl := fmt.Sprintf("%v%v?dirSizeLimit=0", hostAddr, proto.AdminSetNodeInfo)
+	process(fmt.Sprintf("%v&%v=1000&%v=60", reqUrl, metaPartitionHeatSplitOpRateKey, metaPartitionHeatSplitIntervalKey), t)
+	require.EqualValues(t, 1000, server.cluster.metaPartitionHeatSplitOpRate())
+	require.EqualValues(t, 60, server.cluster.metaPartitionHeatSplitInterval())
+	paras, err := mc.AdminAPI().GetClusterParas()
+	require.NoError(t, err)
+	require.Equal(t, "1000", paras[metaPartitionHeatSplitOpRateKey])
+
+	process(fmt.Sprintf("%v&%v=0&%v=0", reqUrl, metaPartitionHeatSplitOpRateKey, metaPartitionHeatSplitIntervalKey), t)
+	require.Zero(t, atomic.LoadUint64(&server.cluster.cfg.MetaPartitionHeatSplitOpRate))
+	require.EqualValues(t, defaultMetaPartitionHeatSplitInterval, server.cluster.metaPartitionHeatSplitInterval())
+}
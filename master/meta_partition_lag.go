@@ -0,0 +1,97 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// checkApplyLag is called with mp locked each time the leader replica reports, it counts the
+// heartbeat cycles that every follower lags behind the leader by more than the threshold.
+// A follower keeps lagging for MpApplyLagCycles cycles is alarmed, and rebuilt on other
+// meta node if MpApplyLagAutoRepair is enabled.
+func (mp *MetaPartition) checkApplyLag(c *Cluster) {
+	leader, err := mp.getMetaReplicaLeader()
+	if err != nil {
+		return
+	}
+	threshold, cycles := c.cfg.MpApplyLagThreshold, c.cfg.MpApplyLagCycles
+	for _, mr := range mp.Replicas {
+		if mr.IsLeader || mr.ApplyID >= leader.ApplyID || leader.ApplyID-mr.ApplyID <= threshold {
+			mr.applyLagCycles = 0
+			continue
+		}
+		mr.applyLagCycles++
+		if cycles <= 0 || mr.applyLagCycles%cycles != 0 {
+			continue
+		}
+		msg := fmt.Sprintf("action[checkApplyLag] clusterID[%v] vol[%v] mp[%v] replica[%v] applyID[%v] lags behind leader[%v] applyID[%v] for %v cycles",
+			c.Name, mp.volName, mp.PartitionID, mr.Addr, mr.ApplyID, leader.Addr, leader.ApplyID, mr.applyLagCycles)
+		Warn(c.Name, msg)
+		if c.cfg.MpApplyLagAutoRepair && !mp.IsRecover && atomic.CompareAndSwapInt32(&mp.lagRepairing, 0, 1) {
+			go c.repairLaggingMetaReplica(mp, mr.Addr)
+		}
+	}
+}
+
+// repairLaggingMetaReplica rebuilds the lagging replica by migrating it to other meta node,
+// the new replica catches up with the leader by raft snapshot.
+func (c *Cluster) repairLaggingMetaReplica(mp *MetaPartition, addr string) {
+	defer atomic.StoreInt32(&mp.lagRepairing, 0)
+	if err := c.decommissionMetaPartition(addr, mp); err != nil {
+		log.LogErrorf("action[repairLaggingMetaReplica] vol[%v] mp[%v] replica[%v] err[%v]",
+			mp.volName, mp.PartitionID, addr, err)
+		return
+	}
+	log.LogWarnf("action[repairLaggingMetaReplica] vol[%v] mp[%v] lagging replica[%v] is rebuilt on other node",
+		mp.volName, mp.PartitionID, addr)
+}
+
+// getLagInfo returns the apply index lag of the replicas, lagging tells whether any of them
+// lags behind the leader by more than threshold.
+func (mp *MetaPartition) getLagInfo(threshold uint64) (info *proto.MetaPartitionLagInfo, lagging bool) {
+	mp.RLock()
+	defer mp.RUnlock()
+	info = &proto.MetaPartitionLagInfo{
+		PartitionID: mp.PartitionID,
+		VolName:     mp.volName,
+		Replicas:    make([]*proto.MetaReplicaLagInfo, 0, len(mp.Replicas)),
+	}
+	leader, err := mp.getMetaReplicaLeader()
+	if err == nil {
+		info.LeaderAddr = leader.Addr
+		info.LeaderApplyID = leader.ApplyID
+	}
+	for _, mr := range mp.Replicas {
+		ri := &proto.MetaReplicaLagInfo{
+			Addr:      mr.Addr,
+			IsLeader:  mr.IsLeader,
+			ApplyID:   mr.ApplyID,
+			LagCycles: mr.applyLagCycles,
+		}
+		if err == nil && mr.ApplyID < leader.ApplyID {
+			ri.Lag = leader.ApplyID - mr.ApplyID
+		}
+		if ri.Lag > threshold {
+			lagging = true
+		}
+		info.Replicas = append(info.Replicas, ri)
+	}
+	return
+}
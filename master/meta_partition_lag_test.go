@@ -0,0 +1,66 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaPartitionApplyLag(t *testing.T) {
+	c := &Cluster{Name: "lagTest", cfg: newClusterConfig()}
+	c.cfg.MpApplyLagThreshold = 100
+	c.cfg.MpApplyLagCycles = 3
+
+	leader := &MetaReplica{Addr: "192.168.0.1:17210", IsLeader: true, ApplyID: 1000}
+	follower := &MetaReplica{Addr: "192.168.0.2:17210", ApplyID: 990}
+	lagging := &MetaReplica{Addr: "192.168.0.3:17210", ApplyID: 800}
+	mp := newMetaPartition(1, 0, 1000, 3, "lagVol", 1, 0)
+	mp.Replicas = []*MetaReplica{leader, follower, lagging}
+
+	for i := 0; i < 3; i++ {
+		mp.checkApplyLag(c)
+	}
+	require.Equal(t, 0, leader.applyLagCycles)
+	require.Equal(t, 0, follower.applyLagCycles)
+	require.Equal(t, 3, lagging.applyLagCycles)
+
+	info, isLagging := mp.getLagInfo(c.cfg.MpApplyLagThreshold)
+	require.True(t, isLagging)
+	require.Equal(t, leader.Addr, info.LeaderAddr)
+	require.EqualValues(t, 1000, info.LeaderApplyID)
+	require.Len(t, info.Replicas, 3)
+	require.EqualValues(t, 0, info.Replicas[0].Lag)
+	require.EqualValues(t, 10, info.Replicas[1].Lag)
+	require.EqualValues(t, 200, info.Replicas[2].Lag)
+	require.Equal(t, 3, info.Replicas[2].LagCycles)
+
+	// the replica caught up with the leader is reset
+	lagging.ApplyID = 950
+	mp.checkApplyLag(c)
+	require.Equal(t, 0, lagging.applyLagCycles)
+	_, isLagging = mp.getLagInfo(c.cfg.MpApplyLagThreshold)
+	require.False(t, isLagging)
+
+	// no lag is counted without leader
+	leader.IsLeader = false
+	lagging.ApplyID = 0
+	mp.checkApplyLag(c)
+	require.Equal(t, 0, lagging.applyLagCycles)
+	info, isLagging = mp.getLagInfo(c.cfg.MpApplyLagThreshold)
+	require.False(t, isLagging)
+	require.Empty(t, info.LeaderAddr)
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+func (mr *MetaReplica) createTaskToCountDentryRef(partitionID, ino uint64) (t *proto.AdminTask) {
+	req := &proto.MetaDentryRefRequest{PartitionID: partitionID, Inode: ino}
+	t = proto.NewAdminTask(proto.OpMetaCountDentryRef, mr.Addr, req)
+	resetMetaPartitionTaskID(t, partitionID)
+	return
+}
+
+func (mr *MetaReplica) createTaskToRepairNLink(req *proto.MetaRepairNLinkRequest) (t *proto.AdminTask) {
+	t = proto.NewAdminTask(proto.OpMetaRepairNLink, mr.Addr, req)
+	resetMetaPartitionTaskID(t, req.PartitionID)
+	return
+}
+
+// expectedNLink is the link count of the inode by the dentries referring to it, a dir has
+// one more for its "." and one for each child, as metanode counts the children of a dir by it.
+func expectedNLink(inodeType, refs, children uint32) uint32 {
+	if proto.IsDir(inodeType) {
+		return 2 + children
+	}
+	return refs
+}
+
+// repairInodeNLink recounts the link count of the inode from the dentries of all the meta
+// partitions of the vol, and sets it on the partition of the inode if it differs. The link
+// count is only set if it has not changed since it is counted, yet the dentries may, so the
+// repair is meant for the inodes not being linked or unlinked meanwhile.
+func (c *Cluster) repairInodeNLink(vol *Vol, ino uint64, dryRun bool) (report *proto.MetaNLinkRepairReport, err error) {
+	var owner *MetaPartition
+	mps := vol.cloneMetaPartitionMap()
+	for _, mp := range mps {
+		if mp.Start <= ino && ino <= mp.End {
+			owner = mp
+			break
+		}
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("no meta partition of vol %v has ino %v", vol.Name, ino)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		ownerRef *proto.MetaDentryRefResponse
+		countErr error
+	)
+	report = &proto.MetaNLinkRepairReport{VolName: vol.Name, Inode: ino, PartitionID: owner.PartitionID, DryRun: dryRun}
+	for _, mp := range mps {
+		wg.Add(1)
+		go func(mp *MetaPartition) {
+			defer wg.Done()
+			resp, err := c.countDentryRef(mp, ino)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				log.LogWarnf("action[repairInodeNLink] vol[%v] mp[%v] ino[%v] err[%v]", vol.Name, mp.PartitionID, ino, err)
+				countErr = fmt.Errorf("count dentries of mp %v: %v", mp.PartitionID, err)
+				return
+			}
+			report.Refs += resp.Refs
+			report.Children += resp.Children
+			if mp == owner {
+				ownerRef = resp
+			}
+		}(mp)
+	}
+	wg.Wait()
+	if countErr != nil {
+		return nil, countErr
+	}
+	if !ownerRef.HasInode {
+		return nil, fmt.Errorf("ino %v not exist in mp %v", ino, owner.PartitionID)
+	}
+	report.NLink = ownerRef.NLink
+	report.Expected = expectedNLink(ownerRef.Type, report.Refs, report.Children)
+	if report.Expected == report.NLink || dryRun {
+		return
+	}
+	if report.Expected == 0 {
+		return report, fmt.Errorf("no dentry refers to ino %v, it is left to the orphan inode handling", ino)
+	}
+
+	leader, err := owner.getMetaReplicaLeader()
+	if err != nil {
+		return
+	}
+	req := &proto.MetaRepairNLinkRequest{PartitionID: owner.PartitionID, Inode: ino, OldNLink: report.NLink, NLink: report.Expected}
+	if _, err = leader.metaNode.Sender.syncSendAdminTask(leader.createTaskToRepairNLink(req)); err != nil {
+		return
+	}
+	report.Repaired = true
+	log.LogWarnf("action[repairInodeNLink] vol[%v] mp[%v] ino[%v] nlink from %v to %v, refs %v children %v",
+		vol.Name, owner.PartitionID, ino, report.NLink, report.Expected, report.Refs, report.Children)
+	return
+}
+
+// countDentryRef asks the leader of mp for the dentries referring to the inode, the leader has
+// all the dentries committed.
+func (c *Cluster) countDentryRef(mp *MetaPartition, ino uint64) (resp *proto.MetaDentryRefResponse, err error) {
+	mr, err := mp.getMetaReplicaLeader()
+	if err != nil {
+		return
+	}
+	packet, err := mr.metaNode.Sender.syncSendAdminTask(mr.createTaskToCountDentryRef(mp.PartitionID, ino))
+	if err != nil {
+		return
+	}
+	resp = &proto.MetaDentryRefResponse{}
+	err = json.Unmarshal(packet.Data, resp)
+	return
+}
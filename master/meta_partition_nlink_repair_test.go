@@ -0,0 +1,37 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedNLink(t *testing.T) {
+	dirType := uint32(os.ModeDir | 0o755)
+	require.EqualValues(t, 2, expectedNLink(dirType, 1, 0))
+	require.EqualValues(t, 5, expectedNLink(dirType, 1, 3))
+	require.EqualValues(t, 3, expectedNLink(0o644, 3, 0))
+	require.Zero(t, expectedNLink(0o644, 0, 0))
+
+	c := &Cluster{}
+	vol := newVol(volValue{ID: 1, Name: "nlinkVol"})
+	mp := newMetaPartition(1, 1, 100, 3, vol.Name, vol.ID, 0)
+	vol.addMetaPartition(mp)
+	_, err := c.repairInodeNLink(vol, 200, true)
+	require.Error(t, err)
+}
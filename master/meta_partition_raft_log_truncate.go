@@ -0,0 +1,49 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+func (mr *MetaReplica) createTaskToTruncateRaftLog(partitionID, index uint64) (t *proto.AdminTask) {
+	req := &proto.MetaRaftLogTruncateRequest{PartitionID: partitionID, Index: index}
+	t = proto.NewAdminTask(proto.OpMetaRaftLogTruncate, mr.Addr, req)
+	resetMetaPartitionTaskID(t, partitionID)
+	return
+}
+
+// truncateMetaRaftLog asks the leader of mp to truncate its raft log up to index, the leader
+// refuses it unless all the replicas have applied the index and it has stored the snapshot of it.
+func (c *Cluster) truncateMetaRaftLog(mp *MetaPartition, index uint64) (resp *proto.MetaRaftLogTruncateResponse, err error) {
+	mr, err := mp.getMetaReplicaLeader()
+	if err != nil {
+		return
+	}
+	packet, err := mr.metaNode.Sender.syncSendAdminTask(mr.createTaskToTruncateRaftLog(mp.PartitionID, index))
+	if err != nil {
+		return
+	}
+	resp = &proto.MetaRaftLogTruncateResponse{}
+	if err = json.Unmarshal(packet.Data, resp); err != nil {
+		return
+	}
+	log.LogWarnf("action[truncateMetaRaftLog] mp[%v] leader[%v] index[%v] first index[%v] reclaimed[%v]",
+		mp.PartitionID, mr.Addr, index, resp.FirstIndex, resp.ReclaimedBytes)
+	return
+}
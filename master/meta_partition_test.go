@@ -7,6 +7,7 @@ import (
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetaPartition(t *testing.T) {
@@ -104,3 +105,57 @@ func decommissionMetaPartition(vol *Vol, id uint64, t *testing.T) {
 		return
 	}
 }
+
+func TestMigrateMetaPartitionToNodeSet(t *testing.T) {
+	c := server.cluster
+	vol, err := c.getVol(commonVolName)
+	require.NoError(t, err)
+	var mp *MetaPartition
+	vol.mpsLock.RLock()
+	for _, mp = range vol.MetaPartitions {
+		break
+	}
+	vol.mpsLock.RUnlock()
+	require.NotNil(t, mp)
+	metaNode, err := c.metaNode(mp.Hosts[0])
+	require.NoError(t, err)
+	ns, err := c.t.getNodeSetByNodeSetId(metaNode.NodeSetID)
+	require.NoError(t, err)
+	require.NotContains(t, c.hostsOutOfNodeSet(mp, ns), mp.Hosts[0])
+
+	_, err = c.migrateMetaPartitionToNodeSet(mp, 0)
+	require.Error(t, err)
+	if len(c.hostsOutOfNodeSet(mp, ns)) == 0 {
+		_, err = c.migrateMetaPartitionToNodeSet(mp, ns.ID)
+		require.Error(t, err, "already in the nodeset")
+	}
+
+	// a meta partition is moved to one nodeset at a time
+	c.mpNodeSetMigrations.Store(mp.PartitionID, ns.ID)
+	for _, zone := range c.t.getAllZones() {
+		for _, other := range zone.getAllNodeSet() {
+			_, err = c.migrateMetaPartitionToNodeSet(mp, other.ID)
+			require.Error(t, err)
+		}
+	}
+	c.mpNodeSetMigrations.Delete(mp.PartitionID)
+
+	processWithFatalV2(proto.AdminMigrateMetaPartitionToNodeSet, false, map[string]interface{}{"id": mp.PartitionID}, t)
+	processWithFatalV2(proto.AdminMigrateMetaPartitionToNodeSet, false, map[string]interface{}{"id": mp.PartitionID, "nodesetId": 0}, t)
+}
+
+func TestNsEventEndpoints(t *testing.T) {
+	vol, err := server.cluster.getVol(commonVolName)
+	assert.NoError(t, err)
+	views, err := mc.ClientAPI().GetNsEventEndpoints(commonVolName, 0)
+	assert.NoError(t, err)
+	assert.Len(t, views, len(vol.cloneMetaPartitionMap()))
+	views, err = mc.ClientAPI().GetNsEventEndpoints(commonVolName, proto.RootIno)
+	assert.NoError(t, err)
+	if assert.Len(t, views, 1) {
+		assert.True(t, views[0].Start <= proto.RootIno && proto.RootIno <= views[0].End)
+		assert.NotEmpty(t, views[0].Members)
+	}
+	_, err = mc.ClientAPI().GetNsEventEndpoints("notExistVol", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+func (mr *MetaReplica) createTaskToComputeTreeCRC(partitionID, rangeSize uint64) (t *proto.AdminTask) {
+	req := &proto.MetaTreeCRCRequest{PartitionID: partitionID, RangeSize: rangeSize}
+	t = proto.NewAdminTask(proto.OpMetaTreeCRC, mr.Addr, req)
+	resetMetaPartitionTaskID(t, partitionID)
+	return
+}
+
+// diffMetaPartitionTree asks every replica of mp for the CRCs of its trees in ranges of
+// rangeSize inode IDs and reports the ranges that diverge.
+func (c *Cluster) diffMetaPartitionTree(mp *MetaPartition, rangeSize uint64) (diff *proto.MetaPartitionTreeDiff) {
+	if rangeSize == 0 {
+		rangeSize = proto.DefaultMetaTreeCRCRangeSize
+	}
+	mp.RLock()
+	hosts := make([]string, len(mp.Hosts))
+	copy(hosts, mp.Hosts)
+	mp.RUnlock()
+
+	var (
+		wg        sync.WaitGroup
+		lock      sync.Mutex
+		responses = make([]*proto.MetaTreeCRCResponse, 0, len(hosts))
+		failed    = make(map[string]string)
+	)
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			resp, err := c.computeMetaTreeCRC(mp, host, rangeSize)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				log.LogWarnf("action[diffMetaPartitionTree] mp[%v] host[%v] err[%v]", mp.PartitionID, host, err)
+				failed[host] = err.Error()
+				return
+			}
+			responses = append(responses, resp)
+		}(host)
+	}
+	wg.Wait()
+
+	diff = diffMetaTreeCRCs(responses)
+	diff.PartitionID = mp.PartitionID
+	diff.VolName = mp.volName
+	diff.RangeSize = rangeSize
+	diff.FailedHosts = failed
+	return
+}
+
+func (c *Cluster) computeMetaTreeCRC(mp *MetaPartition, host string, rangeSize uint64) (resp *proto.MetaTreeCRCResponse, err error) {
+	mr, err := mp.getMetaReplica(host)
+	if err != nil {
+		return
+	}
+	packet, err := mr.metaNode.Sender.syncSendAdminTask(mr.createTaskToComputeTreeCRC(mp.PartitionID, rangeSize))
+	if err != nil {
+		return
+	}
+	resp = &proto.MetaTreeCRCResponse{}
+	if err = json.Unmarshal(packet.Data, resp); err != nil {
+		return
+	}
+	resp.Addr = host
+	return
+}
+
+// diffMetaTreeCRCs compares the range CRCs of the replicas, a range diverges if any replica
+// has a different count or CRC of it, or has no item in it while the others have.
+func diffMetaTreeCRCs(responses []*proto.MetaTreeCRCResponse) (diff *proto.MetaPartitionTreeDiff) {
+	diff = &proto.MetaPartitionTreeDiff{
+		SameApplyID: true,
+		ApplyIDs:    make(map[string]uint64),
+		Divergent:   make([]*proto.MetaTreeRangeDiff, 0),
+	}
+	for _, resp := range responses {
+		diff.ApplyIDs[resp.Addr] = resp.ApplyID
+		if resp.ApplyID != responses[0].ApplyID {
+			diff.SameApplyID = false
+		}
+	}
+
+	for _, tree := range []string{proto.MetaTreeInode, proto.MetaTreeDentry, proto.MetaTreeExtend} {
+		ranges := make(map[uint64]*proto.MetaTreeRangeDiff)
+		for _, resp := range responses {
+			for _, rc := range resp.Trees[tree] {
+				rd, ok := ranges[rc.Start]
+				if !ok {
+					rd = &proto.MetaTreeRangeDiff{Tree: tree, Start: rc.Start, End: rc.End, Replicas: make(map[string]*proto.MetaTreeRangeCRC)}
+					ranges[rc.Start] = rd
+				}
+				rd.Replicas[resp.Addr] = rc
+			}
+		}
+		divergent := make([]*proto.MetaTreeRangeDiff, 0)
+		for _, rd := range ranges {
+			if isRangeDivergent(rd, len(responses)) {
+				divergent = append(divergent, rd)
+			}
+		}
+		sort.Slice(divergent, func(i, j int) bool { return divergent[i].Start < divergent[j].Start })
+		diff.Divergent = append(diff.Divergent, divergent...)
+	}
+	return
+}
+
+func isRangeDivergent(rd *proto.MetaTreeRangeDiff, replicaNum int) bool {
+	if len(rd.Replicas) != replicaNum {
+		return true
+	}
+	var first *proto.MetaTreeRangeCRC
+	for _, rc := range rd.Replicas {
+		if first == nil {
+			first = rc
+			continue
+		}
+		if rc.Count != first.Count || rc.CRC != first.CRC {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffMetaTreeCRCs(t *testing.T) {
+	newResp := func(addr string, applyID uint64, inodes []*proto.MetaTreeRangeCRC) *proto.MetaTreeCRCResponse {
+		return &proto.MetaTreeCRCResponse{
+			Addr:    addr,
+			ApplyID: applyID,
+			Trees: map[string][]*proto.MetaTreeRangeCRC{
+				proto.MetaTreeInode:  inodes,
+				proto.MetaTreeDentry: {{Start: 0, End: 10, Count: 1, CRC: 7}},
+			},
+		}
+	}
+	r1 := newResp("a", 100, []*proto.MetaTreeRangeCRC{
+		{Start: 0, End: 10, Count: 2, CRC: 1},
+		{Start: 10, End: 20, Count: 1, CRC: 2},
+		{Start: 30, End: 40, Count: 1, CRC: 3},
+	})
+	r2 := newResp("b", 100, []*proto.MetaTreeRangeCRC{
+		{Start: 0, End: 10, Count: 2, CRC: 1},
+		{Start: 10, End: 20, Count: 1, CRC: 5},
+		{Start: 30, End: 40, Count: 1, CRC: 3},
+	})
+	r3 := newResp("c", 100, []*proto.MetaTreeRangeCRC{
+		{Start: 0, End: 10, Count: 2, CRC: 1},
+		{Start: 10, End: 20, Count: 1, CRC: 2},
+	})
+
+	diff := diffMetaTreeCRCs([]*proto.MetaTreeCRCResponse{r1, r2, r3})
+	require.True(t, diff.SameApplyID)
+	require.Len(t, diff.Divergent, 2)
+	require.Equal(t, proto.MetaTreeInode, diff.Divergent[0].Tree)
+	require.EqualValues(t, 10, diff.Divergent[0].Start)
+	require.Len(t, diff.Divergent[0].Replicas, 3)
+	require.EqualValues(t, 30, diff.Divergent[1].Start)
+	require.Len(t, diff.Divergent[1].Replicas, 2)
+	require.NotContains(t, diff.Divergent[1].Replicas, "c")
+
+	r3.ApplyID = 99
+	diff = diffMetaTreeCRCs([]*proto.MetaTreeCRCResponse{r1, r3})
+	require.False(t, diff.SameApplyID)
+	require.EqualValues(t, 99, diff.ApplyIDs["c"])
+
+	r4 := newResp("d", 100, r1.Trees[proto.MetaTreeInode])
+	diff = diffMetaTreeCRCs([]*proto.MetaTreeCRCResponse{r1, r4})
+	require.Empty(t, diff.Divergent)
+}
@@ -44,6 +44,12 @@ type clusterValue struct {
 	DataNodeDeleteLimitRate                uint64
 	MetaNodeDeleteBatchCount               uint64
 	MetaNodeDeleteWorkerSleepMs            uint64
+	MetaSnapshotPersistenceMode            uint32
+	MetaSnapshotSendRateMB                 uint64
+	MetaSnapshotRecvRateMB                 uint64
+	MetaSnapshotPartitionRateMB            uint64
+	MetaPartitionHeatSplitOpRate           uint64
+	MetaPartitionHeatSplitInterval         uint64
 	DataNodeAutoRepairLimitRate            uint64
 	MaxDpCntLimit                          uint64
 	MaxMpCntLimit                          uint64
@@ -82,6 +88,9 @@ type clusterValue struct {
 	AutoMpMigrate                          bool
 	FlashNodeHandleReadTimeout             int
 	FlashNodeReadDataNodeTimeout           int
+	MpApplyLagThreshold                    uint64
+	MpApplyLagCycles                       int
+	MpApplyLagAutoRepair                   bool
 }
 
 func newClusterValue(c *Cluster) (cv *clusterValue) {
@@ -93,6 +102,12 @@ func newClusterValue(c *Cluster) (cv *clusterValue) {
 		DataNodeDeleteLimitRate:                c.cfg.DataNodeDeleteLimitRate,
 		MetaNodeDeleteBatchCount:               c.cfg.MetaNodeDeleteBatchCount,
 		MetaNodeDeleteWorkerSleepMs:            c.cfg.MetaNodeDeleteWorkerSleepMs,
+		MetaSnapshotPersistenceMode:            atomic.LoadUint32(&c.cfg.MetaSnapshotPersistenceMode),
+		MetaSnapshotSendRateMB:                 atomic.LoadUint64(&c.cfg.MetaSnapshotSendRateMB),
+		MetaSnapshotRecvRateMB:                 atomic.LoadUint64(&c.cfg.MetaSnapshotRecvRateMB),
+		MetaSnapshotPartitionRateMB:            atomic.LoadUint64(&c.cfg.MetaSnapshotPartitionRateMB),
+		MetaPartitionHeatSplitOpRate:           atomic.LoadUint64(&c.cfg.MetaPartitionHeatSplitOpRate),
+		MetaPartitionHeatSplitInterval:         atomic.LoadUint64(&c.cfg.MetaPartitionHeatSplitInterval),
 		DataNodeAutoRepairLimitRate:            c.cfg.DataNodeAutoRepairLimitRate,
 		DisableAutoAllocate:                    c.DisableAutoAllocate,
 		ForbidMpDecommission:                   c.ForbidMpDecommission,
@@ -133,6 +148,9 @@ func newClusterValue(c *Cluster) (cv *clusterValue) {
 		AutoMpMigrate:                          c.cfg.AutoMpMigrate,
 		FlashNodeHandleReadTimeout:             c.cfg.flashNodeHandleReadTimeout,
 		FlashNodeReadDataNodeTimeout:           c.cfg.flashNodeReadDataNodeTimeout,
+		MpApplyLagThreshold:                    c.cfg.MpApplyLagThreshold,
+		MpApplyLagCycles:                       c.cfg.MpApplyLagCycles,
+		MpApplyLagAutoRepair:                   c.cfg.MpApplyLagAutoRepair,
 	}
 	return cv
 }
@@ -375,6 +393,12 @@ type volValue struct {
 	DisableAuditLog                                        bool
 	AccessTimeInterval                                     int64
 	EnablePersistAccessTime                                bool
+	AtimeMode                                              string
+	XAttrLimit                                             proto.XAttrLimit
+	ExtentConflictPolicy                                   string
+	EnableOpAudit                                          bool
+	EnableCheckExtentKey                                   bool
+	EnableDirStat                                          bool
 
 	Forbidden            bool
 	DpRepairBlockSize    uint64
@@ -396,6 +420,19 @@ type volValue struct {
 	FlashNodeTimeoutCount        int64
 	RemoteCacheSameZoneTimeout   int64
 	RemoteCacheSameRegionTimeout int64
+
+	DefaultXAttrs    map[string]string
+	DpPins           []*proto.DataPartitionPin
+	MetaWorkerWeight int32                      `json:",omitempty"`
+	MetaMediaType    uint32                     `json:",omitempty"`
+	ReadOnlyWindows  []*proto.VolReadOnlyWindow `json:",omitempty"`
+	MetaFrozen       bool                       `json:",omitempty"`
+	MetaAccessKeys   []*proto.MetaAccessKey     `json:",omitempty"`
+	PurgeCtrl        *proto.VolPurgeCtrl        `json:",omitempty"`
+	MetaOpQuota      *proto.MetaOpQuota         `json:",omitempty"`
+
+	SourceVol           string `json:",omitempty"`
+	ReplicaSyncInterval int64  `json:",omitempty"`
 }
 
 func (v *volValue) Bytes() (raw []byte, err error) {
@@ -469,6 +506,12 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		EnableAutoMetaRepair:    vol.EnableAutoMetaRepair.Load(),
 		AccessTimeInterval:      vol.AccessTimeValidInterval,
 		EnablePersistAccessTime: vol.EnablePersistAccessTime,
+		AtimeMode:               vol.atimeMode,
+		XAttrLimit:              vol.xattrLimit,
+		ExtentConflictPolicy:    vol.extentConflictPolicy,
+		EnableOpAudit:           vol.enableOpAudit,
+		EnableCheckExtentKey:    vol.enableCheckExtentKey,
+		EnableDirStat:           vol.enableDirStat,
 
 		VolStorageClass:          vol.volStorageClass,
 		ForbidWriteOpOfProtoVer0: vol.ForbidWriteOpOfProtoVer0.Load(),
@@ -491,6 +534,18 @@ func newVolValue(vol *Vol) (vv *volValue) {
 	vv.QuotaOfClass = make([]*proto.StatOfStorageClass, len(vol.QuotaByClass))
 	copy(vv.QuotaOfClass, vol.QuotaByClass)
 
+	vv.DefaultXAttrs = vol.getDefaultXAttrs()
+	vv.DpPins = vol.getDpPins()
+	vv.ReadOnlyWindows = vol.getReadOnlyWindows()
+	vv.MetaFrozen = vol.metaFrozen.Load()
+	vv.MetaAccessKeys = vol.getMetaAccessKeys()
+	vv.PurgeCtrl = vol.getPurgeCtrl()
+	vv.MetaOpQuota = vol.getMetaOpQuota()
+	vv.MetaWorkerWeight = vol.getMetaWorkerWeight()
+	vv.MetaMediaType = vol.getMetaMediaType()
+	vv.SourceVol = vol.SourceVol
+	vv.ReplicaSyncInterval = vol.replicaSyncInterval
+
 	return
 }
 
@@ -564,6 +619,7 @@ type metaNodeValue struct {
 	ReplicaPort   string
 	ZoneName      string
 	RdOnly        bool
+	NoPlacement   bool
 	maxMpCntLimit uint64
 }
 
@@ -576,6 +632,7 @@ func newMetaNodeValue(metaNode *MetaNode) *metaNodeValue {
 		ReplicaPort:   metaNode.ReplicaPort,
 		ZoneName:      metaNode.ZoneName,
 		RdOnly:        metaNode.RdOnly,
+		NoPlacement:   metaNode.NoPlacement,
 		maxMpCntLimit: metaNode.MpCntLimit,
 	}
 }
@@ -1142,6 +1199,10 @@ func (c *Cluster) updateMetaNodeDeleteWorkerSleepMs(val uint64) {
 	atomic.StoreUint64(&c.cfg.MetaNodeDeleteWorkerSleepMs, val)
 }
 
+func (c *Cluster) updateMetaSnapshotPersistenceMode(val uint32) {
+	atomic.StoreUint32(&c.cfg.MetaSnapshotPersistenceMode, val)
+}
+
 func (c *Cluster) updateDataPartitionMaxRepairErrCnt(val uint64) {
 	atomic.StoreUint64(&c.cfg.DpMaxRepairErrCnt, val)
 }
@@ -1373,6 +1434,12 @@ func (c *Cluster) loadClusterValue() (err error) {
 		c.updateDirChildrenNumLimit(cv.DirChildrenNumLimit)
 		c.updateMetaNodeDeleteBatchCount(cv.MetaNodeDeleteBatchCount)
 		c.updateMetaNodeDeleteWorkerSleepMs(cv.MetaNodeDeleteWorkerSleepMs)
+		c.updateMetaSnapshotPersistenceMode(cv.MetaSnapshotPersistenceMode)
+		atomic.StoreUint64(&c.cfg.MetaSnapshotSendRateMB, cv.MetaSnapshotSendRateMB)
+		atomic.StoreUint64(&c.cfg.MetaSnapshotRecvRateMB, cv.MetaSnapshotRecvRateMB)
+		atomic.StoreUint64(&c.cfg.MetaSnapshotPartitionRateMB, cv.MetaSnapshotPartitionRateMB)
+		atomic.StoreUint64(&c.cfg.MetaPartitionHeatSplitOpRate, cv.MetaPartitionHeatSplitOpRate)
+		atomic.StoreUint64(&c.cfg.MetaPartitionHeatSplitInterval, cv.MetaPartitionHeatSplitInterval)
 		c.updateDataNodeDeleteLimitRate(cv.DataNodeDeleteLimitRate)
 		c.updateDataNodeAutoRepairLimit(cv.DataNodeAutoRepairLimitRate)
 		c.updateDataPartitionMaxRepairErrCnt(cv.DpMaxRepairErrCnt)
@@ -1421,6 +1488,16 @@ func (c *Cluster) loadClusterValue() (err error) {
 		c.cfg.flashNodeReadDataNodeTimeout = cv.FlashNodeReadDataNodeTimeout
 		log.LogInfof("action[loadClusterValue] flashNodeHandleReadTimeout %v(ms), flashNodeReadDataNodeTimeout%v(ms)",
 			cv.FlashNodeHandleReadTimeout, cv.FlashNodeReadDataNodeTimeout)
+
+		if cv.MpApplyLagThreshold == 0 {
+			cv.MpApplyLagThreshold = defaultMpApplyLagThreshold
+		}
+		c.cfg.MpApplyLagThreshold = cv.MpApplyLagThreshold
+		if cv.MpApplyLagCycles <= 0 {
+			cv.MpApplyLagCycles = defaultMpApplyLagCycles
+		}
+		c.cfg.MpApplyLagCycles = cv.MpApplyLagCycles
+		c.cfg.MpApplyLagAutoRepair = cv.MpApplyLagAutoRepair
 	}
 
 	return
@@ -1705,6 +1782,7 @@ func (c *Cluster) loadMetaNodes() (err error) {
 		metaNode.ID = mnv.ID
 		metaNode.NodeSetID = mnv.NodeSetID
 		metaNode.RdOnly = mnv.RdOnly
+		metaNode.NoPlacement = mnv.NoPlacement
 
 		oldmn, ok := c.metaNodes.Load(metaNode.Addr)
 		if ok {
@@ -2346,3 +2424,83 @@ func (c *Cluster) loadFlashManualTasks() (err error) {
 	}
 	return
 }
+
+func (c *Cluster) loadClusterSnapshots() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(clusterSnapshotPartPrefix))
+	if err != nil {
+		err = fmt.Errorf("action[loadClusterSnapshots],err:%v", err.Error())
+		return err
+	}
+	parts := make(map[uint64][]*clusterSnapshotPartValue)
+	for _, value := range result {
+		part := &clusterSnapshotPartValue{}
+		if err = json.Unmarshal(value, part); err != nil {
+			err = fmt.Errorf("action[loadClusterSnapshots],value:%v,unmarshal err:%v", string(value), err)
+			return
+		}
+		parts[part.ID] = append(parts[part.ID], part)
+	}
+
+	if result, err = c.fsm.store.SeekForPrefix([]byte(clusterSnapshotPrefix)); err != nil {
+		err = fmt.Errorf("action[loadClusterSnapshots],err:%v", err.Error())
+		return err
+	}
+	snapshots := make([]*clusterSnapshot, 0, len(result))
+	for _, value := range result {
+		csv := &clusterSnapshotValue{}
+		if err = json.Unmarshal(value, csv); err != nil {
+			err = fmt.Errorf("action[loadClusterSnapshots],value:%v,unmarshal err:%v", string(value), err)
+			return
+		}
+		s := csv.snapshot(parts[csv.ID])
+		if len(parts[csv.ID]) != csv.Parts {
+			log.LogWarnf("action[loadClusterSnapshots],snapshot[%v] has %v of %v parts, drop it", csv.ID, len(parts[csv.ID]), csv.Parts)
+			c.deleteClusterSnapshots([]*clusterSnapshot{s})
+		} else {
+			snapshots = append(snapshots, s)
+			log.LogInfof("action[loadClusterSnapshots],snapshot[%v]", csv.ID)
+		}
+		delete(parts, csv.ID)
+	}
+	// the parts of the snapshots failed to persist or delete
+	for id, ps := range parts {
+		keys := make([]string, 0, len(ps))
+		for _, part := range ps {
+			keys = append(keys, clusterSnapshotPartKey(id, part.Seq))
+		}
+		if err := c.syncDeleteClusterSnapshotKeys(keys); err != nil {
+			log.LogWarnf("action[loadClusterSnapshots],parts of snapshot[%v] err[%v]", id, err)
+		}
+	}
+	c.deleteClusterSnapshots(c.clusterSnapshots.reset(snapshots))
+	return
+}
+
+func (c *Cluster) loadVolClients() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(volClientPrefix))
+	if err != nil {
+		err = fmt.Errorf("action[loadVolClients],err:%v", err.Error())
+		return err
+	}
+	clients := make(map[string][]*proto.VolClientInfo)
+	for _, value := range result {
+		vcv := &volClientValue{}
+		if err = json.Unmarshal(value, vcv); err != nil {
+			err = fmt.Errorf("action[loadVolClients],value:%v,unmarshal err:%v", string(value), err)
+			return
+		}
+		clients[vcv.VolName] = append(clients[vcv.VolName], vcv.Client)
+	}
+	now := time.Now()
+	for name, cs := range clients {
+		vol, err := c.getVol(name)
+		if err != nil {
+			// the clients of the volume deleted
+			c.syncDeleteVolClients(name, cs)
+			continue
+		}
+		vol.clients.load(cs, now)
+		log.LogInfof("action[loadVolClients],vol[%v] clients[%v]", name, len(cs))
+	}
+	return nil
+}
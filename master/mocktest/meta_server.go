@@ -135,6 +135,15 @@ func (mms *MockMetaServer) serveConn(rc net.Conn) {
 	case proto.OpMetaPartitionTryToLeader:
 		err = mms.handleTryToLeader(conn, req, adminTask)
 		Printf("meta node [%v] try to leader,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
+	case proto.OpSyncMetaReplica:
+		err = mms.handleSyncMetaReplica(conn, req, adminTask)
+		Printf("meta node [%v] sync meta replica,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
+	case proto.OpFreezeEmptyMetaPartition:
+		err = mms.handleFreezeEmptyMetaPartition(conn, req, adminTask)
+		Printf("meta node [%v] freeze empty meta partition,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
+	case proto.OpBackupEmptyMetaPartition:
+		err = mms.handleBackupEmptyMetaPartition(conn, req, adminTask)
+		Printf("meta node [%v] backup empty meta partition,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
 	default:
 		fmt.Printf("unknown code [%v]\n", req.Opcode)
 	}
@@ -150,6 +159,19 @@ func (mms *MockMetaServer) handleRemoveMetaPartitionRaftMember(conn net.Conn, p
 	return
 }
 
+func (mms *MockMetaServer) handleFreezeEmptyMetaPartition(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
+	responseAckOKToMaster(conn, p, nil)
+	return
+}
+
+func (mms *MockMetaServer) handleBackupEmptyMetaPartition(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
+	responseAckOKToMaster(conn, p, nil)
+	mms.Lock()
+	delete(mms.partitions, adminTask.PartitionID)
+	mms.Unlock()
+	return
+}
+
 func (mms *MockMetaServer) handleTryToLeader(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
 	responseAckOKToMaster(conn, p, nil)
 	mms.Lock()
@@ -347,6 +369,32 @@ func (mms *MockMetaServer) handleUpdateMetaPartition(conn net.Conn, p *proto.Pac
 	return mms.postResponseToMaster(adminTask, resp)
 }
 
+func (mms *MockMetaServer) handleSyncMetaReplica(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
+	var data []byte
+	defer func() {
+		if err != nil {
+			responseAckErrToMaster(conn, p, err)
+		} else {
+			responseAckOKToMaster(conn, p, data)
+		}
+	}()
+	req := &proto.SyncMetaReplicaRequest{}
+	reqData, err := json.Marshal(adminTask.Request)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(reqData, req); err != nil {
+		return
+	}
+	resp := &proto.MetaReplicaSyncStatus{
+		PartitionID:       req.PartitionID,
+		SourcePartitionID: req.SourcePartitionID,
+		Syncing:           req.Start,
+	}
+	data, err = json.Marshal(resp)
+	return
+}
+
 func (mms *MockMetaServer) handleLoadMetaPartition(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
 	var data []byte
 	defer func() {
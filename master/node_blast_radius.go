@@ -0,0 +1,171 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sort"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// partitionLoss is the effect of losing a replica on a partition.
+type partitionLoss struct {
+	hosted   bool
+	isLeader bool
+	// live replicas left after the loss
+	remaining int
+	quorum    int
+}
+
+func (l partitionLoss) atQuorum() bool {
+	return l.remaining == l.quorum
+}
+
+func (l partitionLoss) lostQuorum() bool {
+	return l.remaining < l.quorum
+}
+
+func replicaQuorum(replicaNum int) int {
+	return replicaNum/2 + 1
+}
+
+// lossOf estimates the effect of losing the replica on addr, or only the one on diskPath of addr
+// if diskPath is not empty.
+func (partition *DataPartition) lossOf(addr, diskPath string, timeOutSec int64) (loss partitionLoss) {
+	partition.RLock()
+	defer partition.RUnlock()
+	for _, replica := range partition.Replicas {
+		if replica.Addr == addr && (diskPath == "" || replica.DiskPath == diskPath) {
+			loss.hosted = true
+			loss.isLeader = replica.IsLeader
+			continue
+		}
+		if replica.isLive(partition.PartitionID, timeOutSec) && partition.hasHost(replica.Addr) {
+			loss.remaining++
+		}
+	}
+	loss.quorum = replicaQuorum(int(partition.ReplicaNum))
+	return
+}
+
+func (mp *MetaPartition) lossOf(addr string, timeOutSec int64) (loss partitionLoss) {
+	mp.RLock()
+	defer mp.RUnlock()
+	for _, mr := range mp.Replicas {
+		if mr.Addr == addr {
+			loss.hosted = true
+			loss.isLeader = mr.IsLeader
+			continue
+		}
+		if mr.isActive(timeOutSec) {
+			loss.remaining++
+		}
+	}
+	loss.quorum = replicaQuorum(int(mp.ReplicaNum))
+	return
+}
+
+// getBlastRadius estimates the volumes and partitions affected by the loss of the data node or
+// meta node on addr. diskPath limits the data partitions to the ones on the disk of the data node.
+func (c *Cluster) getBlastRadius(addr, diskPath string, isDataNode bool) (radius *proto.BlastRadius) {
+	radius = &proto.BlastRadius{Addr: addr, DiskPath: diskPath, Vols: make([]*proto.VolBlastRadius, 0)}
+	for _, vol := range c.allVols() {
+		var vr *proto.VolBlastRadius
+		if isDataNode {
+			vr = vol.dataBlastRadius(addr, diskPath)
+		} else {
+			vr = vol.metaBlastRadius(addr)
+		}
+		if vr.DataPartitionCount == 0 && vr.MetaPartitionCount == 0 {
+			continue
+		}
+		radius.DataPartitionCount += vr.DataPartitionCount
+		radius.MetaPartitionCount += vr.MetaPartitionCount
+		radius.LeaderCount += vr.DataPartitionLeaderCount + vr.MetaPartitionLeaderCount
+		radius.AtQuorumCount += len(vr.AtQuorumDataPartitions) + len(vr.AtQuorumMetaPartitions)
+		radius.LostQuorumCount += len(vr.LostQuorumDataPartitions) + len(vr.LostQuorumMetaPartitions)
+		radius.DataSize += vr.DataSize
+		radius.InodeCount += vr.InodeCount
+		radius.DentryCount += vr.DentryCount
+		radius.Vols = append(radius.Vols, vr)
+	}
+	sort.Slice(radius.Vols, func(i, j int) bool {
+		return radius.Vols[i].VolName < radius.Vols[j].VolName
+	})
+	return
+}
+
+func newVolBlastRadius(volName string) *proto.VolBlastRadius {
+	return &proto.VolBlastRadius{
+		VolName:                  volName,
+		AtQuorumDataPartitions:   make([]uint64, 0),
+		LostQuorumDataPartitions: make([]uint64, 0),
+		AtQuorumMetaPartitions:   make([]uint64, 0),
+		LostQuorumMetaPartitions: make([]uint64, 0),
+	}
+}
+
+func (vol *Vol) dataBlastRadius(addr, diskPath string) (vr *proto.VolBlastRadius) {
+	vr = newVolBlastRadius(vol.Name)
+	vol.dataPartitions.Range(func(dp *DataPartition) bool {
+		if dp.IsDiscard {
+			return true
+		}
+		loss := dp.lossOf(addr, diskPath, defaultDataPartitionTimeOutSec)
+		if !loss.hosted {
+			return true
+		}
+		vr.DataPartitionCount++
+		vr.DataSize += dp.getMaxUsedSpace()
+		if loss.isLeader {
+			vr.DataPartitionLeaderCount++
+		}
+		if loss.lostQuorum() {
+			vr.LostQuorumDataPartitions = append(vr.LostQuorumDataPartitions, dp.PartitionID)
+		} else if loss.atQuorum() {
+			vr.AtQuorumDataPartitions = append(vr.AtQuorumDataPartitions, dp.PartitionID)
+		}
+		return true
+	})
+	sortPartitionIDs(vr.AtQuorumDataPartitions)
+	sortPartitionIDs(vr.LostQuorumDataPartitions)
+	return
+}
+
+func (vol *Vol) metaBlastRadius(addr string) (vr *proto.VolBlastRadius) {
+	vr = newVolBlastRadius(vol.Name)
+	for _, mp := range vol.getSortMetaPartitions() {
+		loss := mp.lossOf(addr, defaultMetaPartitionTimeOutSec)
+		if !loss.hosted {
+			continue
+		}
+		vr.MetaPartitionCount++
+		vr.InodeCount += mp.InodeCount
+		vr.DentryCount += mp.DentryCount
+		if loss.isLeader {
+			vr.MetaPartitionLeaderCount++
+		}
+		if loss.lostQuorum() {
+			vr.LostQuorumMetaPartitions = append(vr.LostQuorumMetaPartitions, mp.PartitionID)
+		} else if loss.atQuorum() {
+			vr.AtQuorumMetaPartitions = append(vr.AtQuorumMetaPartitions, mp.PartitionID)
+		}
+	}
+	return
+}
+
+func sortPartitionIDs(ids []uint64) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataPartitionLossOf(t *testing.T) {
+	dp := newDataPartition(1, 3, "radiusVol", 1, proto.PartitionTypeNormal, proto.MediaType_HDD)
+	for i, addr := range []string{"192.168.0.1:17310", "192.168.0.2:17310", "192.168.0.3:17310"} {
+		node := &DataNode{Addr: addr, isActive: true}
+		replica := newDataReplica(node)
+		replica.DiskPath = "/data0"
+		replica.Status = proto.ReadWrite
+		replica.IsLeader = i == 0
+		dp.Replicas = append(dp.Replicas, replica)
+		dp.Hosts = append(dp.Hosts, addr)
+	}
+
+	loss := dp.lossOf("192.168.0.1:17310", "", defaultDataPartitionTimeOutSec)
+	require.True(t, loss.hosted)
+	require.True(t, loss.isLeader)
+	require.True(t, loss.atQuorum())
+	require.False(t, loss.lostQuorum())
+
+	// disk of another path is not hosting the partition
+	loss = dp.lossOf("192.168.0.1:17310", "/data1", defaultDataPartitionTimeOutSec)
+	require.False(t, loss.hosted)
+
+	// one of the other replicas is already unavailable
+	dp.Replicas[1].Status = proto.Unavailable
+	loss = dp.lossOf("192.168.0.3:17310", "/data0", defaultDataPartitionTimeOutSec)
+	require.True(t, loss.hosted)
+	require.False(t, loss.isLeader)
+	require.True(t, loss.lostQuorum())
+}
+
+func TestMetaPartitionLossOf(t *testing.T) {
+	mp := newMetaPartition(1, 0, 1000, 3, "radiusVol", 1, 0)
+	now := time.Now().Unix()
+	for i, addr := range []string{"192.168.0.1:17210", "192.168.0.2:17210", "192.168.0.3:17210"} {
+		mp.Replicas = append(mp.Replicas, &MetaReplica{
+			Addr:       addr,
+			IsLeader:   i == 0,
+			Status:     proto.ReadWrite,
+			ReportTime: now,
+			metaNode:   &MetaNode{Addr: addr, IsActive: true},
+		})
+	}
+
+	loss := mp.lossOf("192.168.0.1:17210", defaultMetaPartitionTimeOutSec)
+	require.True(t, loss.hosted)
+	require.True(t, loss.isLeader)
+	require.True(t, loss.atQuorum())
+
+	mp.Replicas[2].metaNode.IsActive = false
+	loss = mp.lossOf("192.168.0.2:17210", defaultMetaPartitionTimeOutSec)
+	require.True(t, loss.hosted)
+	require.False(t, loss.isLeader)
+	require.True(t, loss.lostQuorum())
+
+	require.False(t, mp.lossOf("192.168.0.4:17210", defaultMetaPartitionTimeOutSec).hosted)
+}
@@ -219,6 +219,7 @@ func Warn(clusterID, msg string) {
 func WarnBySpecialKey(key, msg string) {
 	log.LogWarn(msg)
 	exporter.Warning(msg)
+	recentAlarms.add(key, msg)
 }
 
 func keyNotFound(name string) (err error) {
@@ -35,6 +35,7 @@ import (
 	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
 	"github.com/cubefs/cubefs/util/stat"
+	"google.golang.org/grpc"
 )
 
 // configuration keys
@@ -125,6 +126,8 @@ type Server struct {
 	reverseProxy    *httputil.ReverseProxy
 	metaReady       bool
 	apiServer       *http.Server
+	grpcPort        string
+	grpcServer      *grpc.Server
 	cliMgr          *ClientMgr
 	leaderChangeLk  sync.RWMutex
 }
@@ -175,6 +178,7 @@ func (m *Server) Start(cfg *config.Config) (err error) {
 	WarnMetrics = newWarningMetrics(m.cluster)
 	m.cluster.scheduleTask()
 	m.startHTTPService(ModuleName, cfg)
+	m.startGrpcService()
 	exporter.RegistConsul(m.clusterName, ModuleName, cfg)
 	metricsService := newMonitorMetrics(m.cluster)
 	metricsService.start()
@@ -207,6 +211,9 @@ func (m *Server) Shutdown() {
 			log.LogErrorf("action[Shutdown] failed, err: %v", err)
 		}
 	}
+	if m.grpcServer != nil {
+		m.grpcServer.Stop()
+	}
 	stat.CloseStat()
 
 	// stop raftServer first
@@ -234,6 +241,7 @@ func (m *Server) checkConfig(cfg *config.Config) (err error) {
 	m.ip = cfg.GetString(IP)
 	m.bindIp = cfg.GetBool(proto.BindIpKey)
 	m.port = cfg.GetString(proto.ListenPort)
+	m.grpcPort = cfg.GetString(cfgGrpcPort)
 	m.logDir = cfg.GetString(LogDir)
 	m.walDir = cfg.GetString(WalDir)
 	m.bStoreAddr = cfg.GetString(BStoreAddrKey)
@@ -428,6 +436,13 @@ func (m *Server) checkConfig(cfg *config.Config) (err error) {
 	}
 	m.config.metaNodeMemMidPer = (m.config.metaNodeMemHighPer + m.config.metaNodeMemLowPer) / 2.0
 	m.config.AutoMpMigrate = cfg.GetBoolWithDefault(cfgAutoMpMigrate, false)
+	if lag := cfg.GetInt64(cfgMpApplyLagThreshold); lag > 0 {
+		m.config.MpApplyLagThreshold = uint64(lag)
+	}
+	if cycles := cfg.GetInt64(cfgMpApplyLagCycles); cycles > 0 {
+		m.config.MpApplyLagCycles = int(cycles)
+	}
+	m.config.MpApplyLagAutoRepair = cfg.GetBoolWithDefault(cfgMpApplyLagAutoRepair, false)
 
 	m.config.cfgDataMediaType = uint32(cfg.GetInt64(cfgLegacyDataMediaType))
 	if m.config.cfgDataMediaType != 0 && !proto.IsValidMediaType(m.config.cfgDataMediaType) {
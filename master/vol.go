@@ -15,6 +15,8 @@
 package master
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"runtime/debug"
@@ -61,6 +63,12 @@ type VolVarargs struct {
 	enableAutoDpMetaRepair   bool
 	accessTimeValidInterval  int64
 	enablePersistAccessTime  bool
+	atimeMode                string
+	xattrLimit               proto.XAttrLimit
+	extentConflictPolicy     string
+	enableOpAudit            bool
+	enableCheckExtentKey     bool
+	enableDirStat            bool
 	leaderRetryTimeout       int64
 	volStorageClass          uint32
 	allowedStorageClass      []uint32
@@ -175,6 +183,12 @@ type Vol struct {
 	AccessTimeInterval       int64
 	EnablePersistAccessTime  bool
 	AccessTimeValidInterval  int64
+	atimeMode                string
+	xattrLimit               proto.XAttrLimit
+	extentConflictPolicy     string
+	enableOpAudit            bool  // metanode writes the op audit log of namespace mutations
+	enableCheckExtentKey     bool  // metanode checks the extent keys appended against the ones of the inode
+	enableDirStat            bool  // metanode keeps the stats of the subtrees on the dirs
 	LeaderRetryTimeout       int64 // s
 	EnableAutoMetaRepair     atomicutil.Bool
 	ForbidWriteOpOfProtoVer0 atomicutil.Bool
@@ -201,6 +215,39 @@ type Vol struct {
 	StatMigrateStorageClass []*proto.StatOfStorageClass
 	StatByDpMediaType       []*proto.StatOfStorageClass
 	QuotaByClass            []*proto.StatOfStorageClass
+
+	preWarm *volPreWarm
+
+	defaultXAttrsLock sync.RWMutex
+	defaultXAttrs     map[string]string // set to every new inode of the vol by metanode
+
+	metaWorkerWeight int32  // share of the request workers of metanode the vol has, 0 for the default one
+	metaMediaType    uint32 // the meta partitions are placed on the meta nodes of the media type if specified
+
+	dpPinsLock sync.RWMutex
+	dpPins     []*proto.DataPartitionPin // preferred data partitions of path prefixes, honored by client
+
+	readOnlyWindowsLock sync.RWMutex
+	readOnlyWindows     []*proto.VolReadOnlyWindow // the partitions are told to refuse modifications during the windows
+
+	metaFrozen atomicutil.Bool // the meta partitions are told to refuse modifications until unfrozen
+
+	metaAccessKeysLock sync.RWMutex
+	metaAccessKeys     []*proto.MetaAccessKey // the meta partitions are told to refuse modifications without a token signed by one if set
+
+	purgeCtrlLock sync.RWMutex
+	purgeCtrl     proto.VolPurgeCtrl // the meta partitions are told to pause or force the purge of the inodes deleted
+
+	metaOpQuotaLock sync.RWMutex
+	metaOpQuota     proto.MetaOpQuota // the metanodes are told to reject the meta ops over the rates
+
+	lastHeatSplit int64 // unix seconds the last meta partition was split by heat
+
+	clients *volClients
+
+	SourceVol           string // the vol is a read-only replica of SourceVol if set
+	replicaSyncInterval int64  // seconds
+	replica             *volReplicaState
 }
 
 func newVol(vv volValue) (vol *Vol) {
@@ -211,6 +258,10 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.dpReplicaNum = vv.DpReplicaNum
 	vol.mpReplicaNum = vv.ReplicaNum
 	vol.Owner = vv.Owner
+	vol.clients = newVolClients()
+	vol.SourceVol = vv.SourceVol
+	vol.replicaSyncInterval = vv.ReplicaSyncInterval
+	vol.replica = &volReplicaState{}
 
 	vol.dataPartitionSize = vv.DataPartitionSize
 	vol.Capacity = vv.Capacity
@@ -278,6 +329,12 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.TrashInterval = vv.TrashInterval
 	vol.AccessTimeValidInterval = vv.AccessTimeInterval
 	vol.EnablePersistAccessTime = vv.EnablePersistAccessTime
+	vol.atimeMode = vv.AtimeMode
+	vol.xattrLimit = vv.XAttrLimit
+	vol.extentConflictPolicy = vv.ExtentConflictPolicy
+	vol.enableOpAudit = vv.EnableOpAudit
+	vol.enableCheckExtentKey = vv.EnableCheckExtentKey
+	vol.enableDirStat = vv.EnableDirStat
 
 	vol.allowedStorageClass = make([]uint32, len(vv.AllowedStorageClass))
 	copy(vol.allowedStorageClass, vv.AllowedStorageClass)
@@ -330,6 +387,19 @@ func newVolFromVolValue(vv *volValue) (vol *Vol) {
 	}
 	vol.EnableAutoMetaRepair.Store(vv.EnableAutoMetaRepair)
 	vol.EnablePersistAccessTime = vv.EnablePersistAccessTime
+	vol.defaultXAttrs = vv.DefaultXAttrs
+	vol.metaWorkerWeight = vv.MetaWorkerWeight
+	vol.metaMediaType = vv.MetaMediaType
+	vol.dpPins = vv.DpPins
+	vol.readOnlyWindows = vv.ReadOnlyWindows
+	vol.metaFrozen.Store(vv.MetaFrozen)
+	vol.metaAccessKeys = vv.MetaAccessKeys
+	if vv.PurgeCtrl != nil {
+		vol.purgeCtrl = *vv.PurgeCtrl
+	}
+	if vv.MetaOpQuota != nil {
+		vol.metaOpQuota = *vv.MetaOpQuota
+	}
 	vol.AccessTimeValidInterval = vv.AccessTimeInterval
 	if vol.AccessTimeValidInterval == 0 {
 		vol.AccessTimeValidInterval = proto.DefaultAccessTimeValidInterval
@@ -891,7 +961,7 @@ func (vol *Vol) checkMetaPartitions(c *Cluster) {
 
 	for _, mp := range mps {
 		doSplit = mp.checkStatus(c.Name, true, int(vol.mpReplicaNum), maxPartitionID, metaPartitionInodeIdStep, vol.Forbidden, c.getMetaPartitionTimeoutSec())
-		if doSplit && !c.cfg.DisableAutoCreate {
+		if doSplit && !c.cfg.DisableAutoCreate && !vol.isVolReplica() {
 			nextStart := mp.MaxInodeID + metaPartitionInodeIdStep
 			log.LogInfof(c.Name, fmt.Sprintf("cluster[%v],vol[%v],meta partition[%v] splits start[%v] maxinodeid:[%v] default step:[%v],nextStart[%v]",
 				c.Name, vol.Name, mp.PartitionID, mp.Start, mp.MaxInodeID, metaPartitionInodeIdStep, nextStart))
@@ -945,6 +1015,10 @@ func (vol *Vol) checkMetaPartitions(c *Cluster) {
 }
 
 func (vol *Vol) checkSplitMetaPartition(c *Cluster, metaPartitionInodeStep uint64) {
+	// the meta partitions of a replica follow the ones of the source vol
+	if vol.isVolReplica() {
+		return
+	}
 	maxPartitionID := vol.maxMetaPartitionID()
 	maxMP, err := vol.metaPartition(maxPartitionID)
 	if err != nil {
@@ -1142,7 +1216,7 @@ func (vol *Vol) checkAutoDataPartitionCreation(c *Cluster) {
 	vol.setStatus(proto.VolStatusNormal)
 	log.LogInfof("[checkAutoDataPartitionCreation] before autoCreateDataPartitions, vol[%v] clusterDisableAutoAllocate[%v] vol.Forbidden[%v]",
 		vol.Name, c.DisableAutoAllocate, vol.Forbidden)
-	if !c.DisableAutoAllocate && !vol.Forbidden {
+	if !c.DisableAutoAllocate && !vol.Forbidden && !vol.isVolReplica() {
 		vol.autoCreateDataPartitions(c)
 	}
 }
@@ -1656,6 +1730,10 @@ func (vol *Vol) splitMetaPartition(c *Cluster, mp *MetaPartition, end uint64, me
 		err = errors.NewErrorf("volume %v is forbidden", vol.Name)
 		return
 	}
+	if vol.isVolReplica() {
+		err = errors.NewErrorf("volume %v is a replica of %v, its meta partitions follow the source", vol.Name, vol.SourceVol)
+		return
+	}
 
 	vol.createMpMutex.Lock()
 	defer vol.createMpMutex.Unlock()
@@ -1698,6 +1776,7 @@ func (vol *Vol) doCreateMetaPartition(c *Cluster, start, end uint64) (mp *MetaPa
 
 	errChannel := make(chan error, vol.mpReplicaNum)
 
+	mediaType := vol.getMetaMediaType()
 	if c.isFaultDomain(vol) {
 		if hosts, peers, err = c.getHostFromDomainZone(vol.domainId, TypeMetaPartition, vol.mpReplicaNum, proto.StorageClass_Unspecified); err != nil {
 			log.LogErrorf("action[doCreateMetaPartition] getHostFromDomainZone err[%v]", err)
@@ -1706,12 +1785,18 @@ func (vol *Vol) doCreateMetaPartition(c *Cluster, start, end uint64) (mp *MetaPa
 	} else {
 		var excludeZone []string
 		zoneNum := c.decideZoneNum(vol, proto.StorageClass_Unspecified)
-		if hosts, peers, err = c.getHostFromNormalZone(TypeMetaPartition, excludeZone, nil, nil,
+		excludeHosts := c.excludeMetaHostsNotOfMediaType(nil, mediaType)
+		if hosts, peers, err = c.getHostFromNormalZone(TypeMetaPartition, excludeZone, nil, excludeHosts,
 			int(vol.mpReplicaNum), zoneNum, vol.zoneName, proto.StorageClass_Unspecified); err != nil {
 			log.LogErrorf("action[doCreateMetaPartition] getHostFromNormalZone err[%v]", err)
 			return nil, errors.NewError(err)
 		}
 	}
+	// the hosts selected in the fault domain are not filtered by the media type
+	if err = c.checkMetaHostsMediaType(hosts, mediaType); err != nil {
+		log.LogErrorf("action[doCreateMetaPartition] vol[%v] err[%v]", vol.Name, err)
+		return nil, errors.NewError(err)
+	}
 
 	if err = c.checkMultipleReplicasOnSameMachine(hosts); err != nil {
 		return nil, err
@@ -1814,6 +1899,12 @@ func setVolFromArgs(args *VolVarargs, vol *Vol) {
 	vol.AccessTimeInterval = args.accessTimeInterval
 	vol.EnableAutoMetaRepair.Store(args.enableAutoDpMetaRepair)
 	vol.EnablePersistAccessTime = args.enablePersistAccessTime
+	vol.atimeMode = args.atimeMode
+	vol.xattrLimit = args.xattrLimit
+	vol.extentConflictPolicy = args.extentConflictPolicy
+	vol.enableOpAudit = args.enableOpAudit
+	vol.enableCheckExtentKey = args.enableCheckExtentKey
+	vol.enableDirStat = args.enableDirStat
 	vol.volStorageClass = args.volStorageClass
 	vol.allowedStorageClass = append([]uint32{}, args.allowedStorageClass...)
 	vol.ForbidWriteOpOfProtoVer0.Store(args.forbidWriteOpOfProtoVer0)
@@ -1878,6 +1969,12 @@ func getVolVarargs(vol *Vol) *VolVarargs {
 		accessTimeValidInterval:  vol.AccessTimeValidInterval,
 		trashInterval:            vol.TrashInterval,
 		enablePersistAccessTime:  vol.EnablePersistAccessTime,
+		atimeMode:                vol.atimeMode,
+		xattrLimit:               vol.xattrLimit,
+		extentConflictPolicy:     vol.extentConflictPolicy,
+		enableOpAudit:            vol.enableOpAudit,
+		enableCheckExtentKey:     vol.enableCheckExtentKey,
+		enableDirStat:            vol.enableDirStat,
 		enableAutoDpMetaRepair:   vol.EnableAutoMetaRepair.Load(),
 		volStorageClass:          vol.volStorageClass,
 		allowedStorageClass:      append([]uint32{}, vol.allowedStorageClass...),
@@ -1972,6 +2069,293 @@ func (vol *Vol) isStorageClassInAllowed(storageClass uint32) (in bool) {
 	return in
 }
 
+func (vol *Vol) getDefaultXAttrs() (xattrs map[string]string) {
+	vol.defaultXAttrsLock.RLock()
+	defer vol.defaultXAttrsLock.RUnlock()
+	if len(vol.defaultXAttrs) == 0 {
+		return nil
+	}
+	xattrs = make(map[string]string, len(vol.defaultXAttrs))
+	for k, v := range vol.defaultXAttrs {
+		xattrs[k] = v
+	}
+	return
+}
+
+func (vol *Vol) setDefaultXAttrs(xattrs map[string]string) {
+	vol.defaultXAttrsLock.Lock()
+	defer vol.defaultXAttrsLock.Unlock()
+	vol.defaultXAttrs = xattrs
+}
+
+func (vol *Vol) getMetaWorkerWeight() int32 {
+	return atomic.LoadInt32(&vol.metaWorkerWeight)
+}
+
+func (vol *Vol) setMetaWorkerWeight(weight int32) {
+	atomic.StoreInt32(&vol.metaWorkerWeight, weight)
+}
+
+func (vol *Vol) getMetaMediaType() uint32 {
+	return atomic.LoadUint32(&vol.metaMediaType)
+}
+
+func (vol *Vol) setMetaMediaType(mediaType uint32) {
+	atomic.StoreUint32(&vol.metaMediaType, mediaType)
+}
+
+func (vol *Vol) getDpPins() (pins []*proto.DataPartitionPin) {
+	vol.dpPinsLock.RLock()
+	defer vol.dpPinsLock.RUnlock()
+	if len(vol.dpPins) == 0 {
+		return nil
+	}
+	pins = make([]*proto.DataPartitionPin, len(vol.dpPins))
+	copy(pins, vol.dpPins)
+	return
+}
+
+func (vol *Vol) setDpPins(pins []*proto.DataPartitionPin) {
+	vol.dpPinsLock.Lock()
+	defer vol.dpPinsLock.Unlock()
+	vol.dpPins = pins
+}
+
+// putDpPin adds the pin or replaces the one with the same path. The files are written in the
+// storage class of the vol, so a pin to another media type would never be taken.
+func (vol *Vol) putDpPin(pin *proto.DataPartitionPin) (err error) {
+	if mediaType := proto.GetMediaTypeByStorageClass(vol.volStorageClass); pin.MediaType != proto.MediaType_Unspecified &&
+		pin.MediaType != mediaType {
+		return fmt.Errorf("media type %v of the pin mismatches the storage class %v of vol %v",
+			proto.MediaTypeString(pin.MediaType), proto.StorageClassString(vol.volStorageClass), vol.Name)
+	}
+	vol.dpPinsLock.Lock()
+	defer vol.dpPinsLock.Unlock()
+	pins := make([]*proto.DataPartitionPin, 0, len(vol.dpPins)+1)
+	for _, old := range vol.dpPins {
+		if old.Path != pin.Path {
+			pins = append(pins, old)
+		}
+	}
+	if len(pins) >= maxVolDpPinCount {
+		return fmt.Errorf("too many dp pins of vol %v, max %v", vol.Name, maxVolDpPinCount)
+	}
+	vol.dpPins = append(pins, pin)
+	return
+}
+
+func (vol *Vol) removeDpPin(path string) (err error) {
+	vol.dpPinsLock.Lock()
+	defer vol.dpPinsLock.Unlock()
+	pins := make([]*proto.DataPartitionPin, 0, len(vol.dpPins))
+	for _, old := range vol.dpPins {
+		if old.Path != path {
+			pins = append(pins, old)
+		}
+	}
+	if len(pins) == len(vol.dpPins) {
+		return fmt.Errorf("dp pin of path %v not found in vol %v", path, vol.Name)
+	}
+	vol.dpPins = pins
+	return
+}
+
+func (vol *Vol) getReadOnlyWindows() (windows []*proto.VolReadOnlyWindow) {
+	vol.readOnlyWindowsLock.RLock()
+	defer vol.readOnlyWindowsLock.RUnlock()
+	if len(vol.readOnlyWindows) == 0 {
+		return nil
+	}
+	windows = make([]*proto.VolReadOnlyWindow, len(vol.readOnlyWindows))
+	copy(windows, vol.readOnlyWindows)
+	return
+}
+
+func (vol *Vol) setReadOnlyWindows(windows []*proto.VolReadOnlyWindow) {
+	vol.readOnlyWindowsLock.Lock()
+	defer vol.readOnlyWindowsLock.Unlock()
+	vol.readOnlyWindows = windows
+}
+
+// addReadOnlyWindow adds the window with the id after the largest one, the windows ended
+// before now are dropped.
+func (vol *Vol) addReadOnlyWindow(window *proto.VolReadOnlyWindow, now int64) (err error) {
+	if window.End <= window.Start {
+		return fmt.Errorf("end %v of the window should be after start %v", window.End, window.Start)
+	}
+	if window.End <= now {
+		return fmt.Errorf("the window ends at %v, before now %v", window.End, now)
+	}
+	vol.readOnlyWindowsLock.Lock()
+	defer vol.readOnlyWindowsLock.Unlock()
+	windows := make([]*proto.VolReadOnlyWindow, 0, len(vol.readOnlyWindows)+1)
+	for _, old := range vol.readOnlyWindows {
+		if old.ID >= window.ID {
+			window.ID = old.ID + 1
+		}
+		if old.End > now {
+			windows = append(windows, old)
+		}
+	}
+	if len(windows) >= maxVolReadOnlyWindowCount {
+		return fmt.Errorf("too many read-only windows of vol %v, max %v", vol.Name, maxVolReadOnlyWindowCount)
+	}
+	if window.ID == 0 {
+		window.ID = 1
+	}
+	vol.readOnlyWindows = append(windows, window)
+	return
+}
+
+func (vol *Vol) cancelReadOnlyWindow(id uint64) (err error) {
+	vol.readOnlyWindowsLock.Lock()
+	defer vol.readOnlyWindowsLock.Unlock()
+	windows := make([]*proto.VolReadOnlyWindow, 0, len(vol.readOnlyWindows))
+	for _, old := range vol.readOnlyWindows {
+		if old.ID != id {
+			windows = append(windows, old)
+		}
+	}
+	if len(windows) == len(vol.readOnlyWindows) {
+		return fmt.Errorf("read-only window %v not found in vol %v", id, vol.Name)
+	}
+	vol.readOnlyWindows = windows
+	return
+}
+
+// inReadOnlyWindow checks whether the time of unix seconds is in any read-only window of the vol.
+func (vol *Vol) inReadOnlyWindow(now int64) bool {
+	vol.readOnlyWindowsLock.RLock()
+	defer vol.readOnlyWindowsLock.RUnlock()
+	for _, window := range vol.readOnlyWindows {
+		if window.InWindow(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (vol *Vol) getMetaAccessKeys() (keys []*proto.MetaAccessKey) {
+	vol.metaAccessKeysLock.RLock()
+	defer vol.metaAccessKeysLock.RUnlock()
+	if len(vol.metaAccessKeys) == 0 {
+		return nil
+	}
+	keys = make([]*proto.MetaAccessKey, len(vol.metaAccessKeys))
+	copy(keys, vol.metaAccessKeys)
+	return
+}
+
+func (vol *Vol) setMetaAccessKeys(keys []*proto.MetaAccessKey) {
+	vol.metaAccessKeysLock.Lock()
+	defer vol.metaAccessKeysLock.Unlock()
+	vol.metaAccessKeys = keys
+}
+
+func (vol *Vol) metaAuthEnabled() bool {
+	vol.metaAccessKeysLock.RLock()
+	defer vol.metaAccessKeysLock.RUnlock()
+	return len(vol.metaAccessKeys) > 0
+}
+
+// currentMetaAccessKey returns the key the tokens are signed by, nil if the vol does not
+// enforce the meta auth.
+func (vol *Vol) currentMetaAccessKey() *proto.MetaAccessKey {
+	vol.metaAccessKeysLock.RLock()
+	defer vol.metaAccessKeysLock.RUnlock()
+	if len(vol.metaAccessKeys) == 0 {
+		return nil
+	}
+	return vol.metaAccessKeys[len(vol.metaAccessKeys)-1]
+}
+
+// rotateMetaAccessKey returns the keys of the vol with a new one to sign the tokens by, with
+// the id after the last one, the keys beyond proto.MetaAccessKeyCount are dropped from the
+// oldest. The key rotated out keeps verifying the tokens signed by it until they expire, so
+// it is to be rotated again no sooner than proto.MetaAccessTokenTTL.
+func (vol *Vol) rotateMetaAccessKey(now int64) (keys []*proto.MetaAccessKey, err error) {
+	secret := make([]byte, proto.MetaAccessKeyLen)
+	if _, err = rand.Read(secret); err != nil {
+		return
+	}
+	vol.metaAccessKeysLock.RLock()
+	defer vol.metaAccessKeysLock.RUnlock()
+	key := &proto.MetaAccessKey{ID: 1, Secret: hex.EncodeToString(secret), CreateTime: now}
+	if n := len(vol.metaAccessKeys); n > 0 {
+		key.ID = vol.metaAccessKeys[n-1].ID + 1
+	}
+	keys = append(keys, vol.metaAccessKeys...)
+	keys = append(keys, key)
+	if len(keys) > proto.MetaAccessKeyCount {
+		keys = keys[len(keys)-proto.MetaAccessKeyCount:]
+	}
+	return
+}
+
+// getPurgeCtrl returns the purge control of the vol, nil if the purge is never paused or forced.
+func (vol *Vol) getPurgeCtrl() *proto.VolPurgeCtrl {
+	vol.purgeCtrlLock.RLock()
+	defer vol.purgeCtrlLock.RUnlock()
+	if vol.purgeCtrl == (proto.VolPurgeCtrl{}) {
+		return nil
+	}
+	ctrl := vol.purgeCtrl
+	return &ctrl
+}
+
+func (vol *Vol) setPurgeCtrl(ctrl proto.VolPurgeCtrl) {
+	vol.purgeCtrlLock.Lock()
+	defer vol.purgeCtrlLock.Unlock()
+	vol.purgeCtrl = ctrl
+}
+
+// getMetaOpQuota returns the quota of the meta op rates of the vol, nil if none is set.
+func (vol *Vol) getMetaOpQuota() *proto.MetaOpQuota {
+	vol.metaOpQuotaLock.RLock()
+	defer vol.metaOpQuotaLock.RUnlock()
+	if vol.metaOpQuota == (proto.MetaOpQuota{}) {
+		return nil
+	}
+	quota := vol.metaOpQuota
+	return &quota
+}
+
+func (vol *Vol) setMetaOpQuota(quota proto.MetaOpQuota) {
+	vol.metaOpQuotaLock.Lock()
+	defer vol.metaOpQuotaLock.Unlock()
+	vol.metaOpQuota = quota
+}
+
+// getPurgeStatus returns the purge control of the vol and the backlog of the purge reported by
+// the leaders of the meta partitions.
+func (vol *Vol) getPurgeStatus() (status *proto.VolPurgeStatus) {
+	vol.purgeCtrlLock.RLock()
+	status = &proto.VolPurgeStatus{
+		VolName:    vol.Name,
+		Paused:     vol.purgeCtrl.Paused,
+		ForceSeq:   vol.purgeCtrl.ForceSeq,
+		Partitions: make([]*proto.MetaPartitionPurgeStatus, 0),
+	}
+	vol.purgeCtrlLock.RUnlock()
+	for _, mp := range vol.getSortMetaPartitions() {
+		mp.RLock()
+		mr, err := mp.getMetaReplicaLeader()
+		mp.RUnlock()
+		if err != nil {
+			continue
+		}
+		status.Partitions = append(status.Partitions, &proto.MetaPartitionPurgeStatus{
+			PartitionID:  mp.PartitionID,
+			Paused:       mr.PurgePaused,
+			BacklogCount: mr.PurgeBacklogCount,
+			BacklogBytes: mr.PurgeBacklogBytes,
+		})
+		status.BacklogCount += mr.PurgeBacklogCount
+		status.BacklogBytes += mr.PurgeBacklogBytes
+	}
+	return
+}
+
 func (vol *Vol) getSortMetaPartitions() (mps []*MetaPartition) {
 	vol.mpsLock.RLock()
 	mps = make([]*MetaPartition, 0, len(vol.MetaPartitions))
@@ -0,0 +1,214 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// a client is considered unmounted if no keepalive is received for defaultVolClientTTL,
+// clients send keepalive every minute.
+const defaultVolClientTTL = 5 * time.Minute
+
+// volClients records the clients mounting the volume by their keepalive. A client is persisted
+// through raft once it registers by its first keepalive and deleted once it expires, while its
+// keepalives are kept in memory only, not to propose a raft log for each keepalive of every
+// client. The clients loaded by a new leader are given a whole ttl to keep alive again.
+type volClients struct {
+	sync.RWMutex
+	ttl     time.Duration
+	clients map[string]*proto.VolClientInfo // host:pid -> client
+	expires volClientExpires
+}
+
+// volClientExpire is the expire of a client once it is pushed, the client is pushed again with
+// its new expire if it keeps alive in the meantime, so that each client is in the heap once.
+type volClientExpire struct {
+	key string
+	at  int64
+}
+
+type volClientExpires []volClientExpire
+
+func (h volClientExpires) Len() int            { return len(h) }
+func (h volClientExpires) Less(i, j int) bool  { return h[i].at < h[j].at }
+func (h volClientExpires) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *volClientExpires) Push(x interface{}) { *h = append(*h, x.(volClientExpire)) }
+
+func (h *volClientExpires) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+func newVolClients() *volClients {
+	return &volClients{
+		ttl:     defaultVolClientTTL,
+		clients: make(map[string]*proto.VolClientInfo),
+	}
+}
+
+func volClientKey(client *proto.VolClientInfo) string {
+	return fmt.Sprintf("%v:%v", client.Host, client.Pid)
+}
+
+// keepAlive records the keepalive of the client, it returns if the client registers by it and
+// the clients expired at now.
+func (vc *volClients) keepAlive(client *proto.VolClientInfo, now time.Time) (registered bool, expired []*proto.VolClientInfo) {
+	vc.Lock()
+	defer vc.Unlock()
+	client.LastKeepAlive = now.Unix()
+	key := volClientKey(client)
+	if _, ok := vc.clients[key]; !ok {
+		registered = true
+		heap.Push(&vc.expires, volClientExpire{key: key, at: vc.expireAt(client)})
+	}
+	vc.clients[key] = client
+	return registered, vc.expire(now)
+}
+
+// remove drops the client failed to register.
+func (vc *volClients) remove(client *proto.VolClientInfo) {
+	vc.Lock()
+	defer vc.Unlock()
+	delete(vc.clients, volClientKey(client))
+}
+
+// load sets the clients loaded by the leader alive at now.
+func (vc *volClients) load(clients []*proto.VolClientInfo, now time.Time) {
+	vc.Lock()
+	defer vc.Unlock()
+	for _, client := range clients {
+		client.LastKeepAlive = now.Unix()
+		key := volClientKey(client)
+		if _, ok := vc.clients[key]; !ok {
+			heap.Push(&vc.expires, volClientExpire{key: key, at: vc.expireAt(client)})
+		}
+		vc.clients[key] = client
+	}
+}
+
+// removeExpired drops the clients expired at now and returns them.
+func (vc *volClients) removeExpired(now time.Time) []*proto.VolClientInfo {
+	vc.Lock()
+	defer vc.Unlock()
+	return vc.expire(now)
+}
+
+// expire pops the clients expired at now, the lock must be held.
+func (vc *volClients) expire(now time.Time) (expired []*proto.VolClientInfo) {
+	for len(vc.expires) > 0 && vc.expires[0].at < now.Unix() {
+		e := heap.Pop(&vc.expires).(volClientExpire)
+		client, ok := vc.clients[e.key]
+		if !ok {
+			continue
+		}
+		if !vc.isExpired(client, now) {
+			heap.Push(&vc.expires, volClientExpire{key: e.key, at: vc.expireAt(client)})
+			continue
+		}
+		delete(vc.clients, e.key)
+		expired = append(expired, client)
+	}
+	return
+}
+
+func (vc *volClients) expireAt(client *proto.VolClientInfo) int64 {
+	return client.LastKeepAlive + int64(vc.ttl/time.Second)
+}
+
+func (vc *volClients) isExpired(client *proto.VolClientInfo, now time.Time) bool {
+	return now.Sub(time.Unix(client.LastKeepAlive, 0)) > vc.ttl
+}
+
+// list returns the alive clients ordered by host and pid.
+func (vc *volClients) list(now time.Time) (clients []*proto.VolClientInfo) {
+	vc.RLock()
+	defer vc.RUnlock()
+	clients = make([]*proto.VolClientInfo, 0, len(vc.clients))
+	for _, c := range vc.clients {
+		if !vc.isExpired(c, now) {
+			clients = append(clients, c)
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Host != clients[j].Host {
+			return clients[i].Host < clients[j].Host
+		}
+		return clients[i].Pid < clients[j].Pid
+	})
+	return
+}
+
+// volClientValue is the client of a volume persisted.
+type volClientValue struct {
+	VolName string
+	Client  *proto.VolClientInfo
+}
+
+func volClientPersistKey(volName string, client *proto.VolClientInfo) string {
+	return volClientPrefix + volName + keySeparator + volClientKey(client)
+}
+
+func (c *Cluster) syncPutVolClient(volName string, client *proto.VolClientInfo) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opSyncAddVolClient
+	metadata.K = volClientPersistKey(volName, client)
+	metadata.V, err = json.Marshal(&volClientValue{VolName: volName, Client: client})
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+func (c *Cluster) syncDeleteVolClients(volName string, clients []*proto.VolClientInfo) {
+	for _, client := range clients {
+		metadata := &RaftCmd{Op: opSyncDeleteVolClient, K: volClientPersistKey(volName, client)}
+		if err := c.submit(metadata); err != nil {
+			log.LogWarnf("action[syncDeleteVolClients] vol[%v] client[%v] err[%v]", volName, volClientKey(client), err)
+		}
+	}
+}
+
+// volClientKeepAlive records the keepalive of the client of the volume, and persists the
+// client if it registers by it.
+func (c *Cluster) volClientKeepAlive(vol *Vol, client *proto.VolClientInfo) (err error) {
+	registered, expired := vol.clients.keepAlive(client, time.Now())
+	c.syncDeleteVolClients(vol.Name, expired)
+	if !registered {
+		return
+	}
+	if err = c.syncPutVolClient(vol.Name, client); err != nil {
+		vol.clients.remove(client)
+	}
+	return
+}
+
+// listVolClients returns the alive clients of the volume and deletes those expired.
+func (c *Cluster) listVolClients(vol *Vol) []*proto.VolClientInfo {
+	now := time.Now()
+	c.syncDeleteVolClients(vol.Name, vol.clients.removeExpired(now))
+	return vol.clients.list(now)
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolClients(t *testing.T) {
+	vc := newVolClients()
+	now := time.Now()
+	require.Empty(t, vc.list(now))
+
+	registered, _ := vc.keepAlive(&proto.VolClientInfo{Host: "192.168.0.2", Pid: 10, MountTime: now.Unix()}, now)
+	require.True(t, registered)
+	vc.keepAlive(&proto.VolClientInfo{Host: "192.168.0.1", Pid: 20}, now)
+	vc.keepAlive(&proto.VolClientInfo{Host: "192.168.0.1", Pid: 10}, now)
+	// keepalive again replaces the old one without registering it again
+	registered, _ = vc.keepAlive(&proto.VolClientInfo{Host: "192.168.0.2", Pid: 10, Version: "v2"}, now.Add(time.Minute))
+	require.False(t, registered)
+
+	clients := vc.list(now.Add(time.Minute))
+	require.Len(t, clients, 3)
+	require.Equal(t, "192.168.0.1", clients[0].Host)
+	require.Equal(t, 10, clients[0].Pid)
+	require.Equal(t, 20, clients[1].Pid)
+	require.Equal(t, "v2", clients[2].Version)
+
+	// the clients without keepalive for ttl are not listed and cleaned by the next keepalive
+	later := now.Add(defaultVolClientTTL + 30*time.Second)
+	clients = vc.list(later)
+	require.Len(t, clients, 1)
+	require.Equal(t, "192.168.0.2", clients[0].Host)
+	registered, expired := vc.keepAlive(&proto.VolClientInfo{Host: "192.168.0.3", Pid: 1}, later)
+	require.True(t, registered)
+	require.Len(t, expired, 2)
+	require.Len(t, vc.clients, 2)
+
+	// the clients loaded by a new leader are given a whole ttl
+	vc = newVolClients()
+	vc.load([]*proto.VolClientInfo{{Host: "192.168.0.1", Pid: 10}}, later)
+	require.Empty(t, vc.removeExpired(later.Add(defaultVolClientTTL)))
+	require.Len(t, vc.removeExpired(later.Add(defaultVolClientTTL+time.Second)), 1)
+	require.Empty(t, vc.clients)
+}
+
+func TestParseVolClientInfo(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/vol/clientKeepAlive?name=vol&pid=12&role=client&version=3.4&mountTime=100", nil)
+	require.NoError(t, err)
+	r.RemoteAddr = "10.0.0.1:17010"
+	client, err := parseVolClientInfo(r)
+	require.NoError(t, err)
+	require.Equal(t, &proto.VolClientInfo{Host: "10.0.0.1", Pid: 12, Role: "client", Version: "3.4", MountTime: 100}, client)
+
+	r, err = http.NewRequest(http.MethodGet, "/vol/clientKeepAlive?name=vol&host=10.0.0.2&pid=x", nil)
+	require.NoError(t, err)
+	_, err = parseVolClientInfo(r)
+	require.Error(t, err)
+}
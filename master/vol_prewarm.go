@@ -0,0 +1,191 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	preWarmDpBatchCount  = 10
+	preWarmRetryInterval = 5 * time.Second
+	preWarmMaxRetry      = 60
+)
+
+// volPreWarm records the progress of creating the initial partitions of a new volume in background.
+// It is kept in the memory of the leader only and not replicated by raft: the task stops and its
+// status is lost once the leader changes, the partitions created so far are kept and the rest could
+// be added by the usual ways of expanding the volume.
+type volPreWarm struct {
+	sync.RWMutex
+	status proto.VolPreWarmStatus
+}
+
+func newVolPreWarm(name string, targetMpCount, targetDpCount int) *volPreWarm {
+	return &volPreWarm{
+		status: proto.VolPreWarmStatus{
+			Name:          name,
+			Status:        proto.VolPreWarmRunning,
+			TargetMpCount: targetMpCount,
+			TargetDpCount: targetDpCount,
+			StartTime:     time.Now().Unix(),
+		},
+	}
+}
+
+func (p *volPreWarm) update(mpCount, dpCount int) {
+	p.Lock()
+	p.status.MpCount = mpCount
+	p.status.DpCount = dpCount
+	p.Unlock()
+}
+
+func (p *volPreWarm) finish(err error) {
+	p.Lock()
+	defer p.Unlock()
+	p.status.EndTime = time.Now().Unix()
+	if err != nil {
+		p.status.Status = proto.VolPreWarmFailed
+		p.status.ErrMsg = err.Error()
+		return
+	}
+	p.status.Status = proto.VolPreWarmDone
+}
+
+func (p *volPreWarm) getStatus() *proto.VolPreWarmStatus {
+	p.RLock()
+	defer p.RUnlock()
+	status := p.status
+	return &status
+}
+
+// computePreWarmTarget sizes the initial meta partition count by the expected inode count
+// and the data partition count by the capacity of the volume.
+func computePreWarmTarget(expectedInodes, capacityGB, dpSizeGB uint64, inodeIdStep uint64) (mpCount, dpCount int) {
+	mpCount = defaultInitMetaPartitionCount
+	if inodeIdStep > 0 && expectedInodes > 0 {
+		mpCount = int((expectedInodes + inodeIdStep - 1) / inodeIdStep)
+	}
+	if mpCount < defaultInitMetaPartitionCount {
+		mpCount = defaultInitMetaPartitionCount
+	}
+	if mpCount > defaultMaxInitMetaPartitionCount {
+		mpCount = defaultMaxInitMetaPartitionCount
+	}
+
+	dpCount = defaultInitDataPartitionCnt
+	if dpSizeGB > 0 && capacityGB > 0 {
+		dpCount = int((capacityGB + dpSizeGB - 1) / dpSizeGB)
+	}
+	if dpCount < defaultInitDataPartitionCnt {
+		dpCount = defaultInitDataPartitionCnt
+	}
+	if dpCount > maxInitDataPartitionCnt {
+		dpCount = maxInitDataPartitionCnt
+	}
+	return
+}
+
+func (vol *Vol) getMetaPartitionCount() int {
+	vol.mpsLock.RLock()
+	defer vol.mpsLock.RUnlock()
+	return len(vol.MetaPartitions)
+}
+
+func (vol *Vol) getPreWarmStatus() (status *proto.VolPreWarmStatus, err error) {
+	vol.volLock.RLock()
+	preWarm := vol.preWarm
+	vol.volLock.RUnlock()
+	if preWarm == nil {
+		err = fmt.Errorf("vol[%v] has no pre-warm task, it is lost if the master leader changed after it started", vol.Name)
+		return
+	}
+	return preWarm.getStatus(), nil
+}
+
+// startPreWarm creates the meta and data partitions of vol until the targets are reached.
+// It runs in background, the progress could be queried by getPreWarmStatus.
+func (c *Cluster) startPreWarm(vol *Vol, targetMpCount, targetDpCount int) {
+	preWarm := newVolPreWarm(vol.Name, targetMpCount, targetDpCount)
+	vol.volLock.Lock()
+	vol.preWarm = preWarm
+	vol.volLock.Unlock()
+
+	go func() {
+		err := c.doPreWarm(vol, preWarm)
+		preWarm.finish(err)
+		if err != nil {
+			log.LogErrorf("action[startPreWarm] vol[%v] pre-warm failed: %v", vol.Name, err)
+			return
+		}
+		log.LogInfof("action[startPreWarm] vol[%v] pre-warm done, status %v", vol.Name, preWarm.getStatus())
+	}()
+}
+
+func (c *Cluster) doPreWarm(vol *Vol, preWarm *volPreWarm) (err error) {
+	status := preWarm.getStatus()
+	mediaType := proto.GetMediaTypeByStorageClass(vol.volStorageClass)
+	needDp := proto.IsStorageClassReplica(vol.volStorageClass) && vol.Capacity > 0
+	dpCount := func() int {
+		return vol.dataPartitions.getDataPartitionsCountOfMediaType(mediaType)
+	}
+
+	retry := 0
+	for vol.getMetaPartitionCount() < status.TargetMpCount {
+		if vol.status() == proto.VolStatusMarkDelete {
+			return fmt.Errorf("vol[%v] is marked delete", vol.Name)
+		}
+		// the rear meta partition can be split only after it reports its max inode id and leader
+		if err = vol.addMetaPartitions(c, 1); err != nil {
+			if retry++; retry > preWarmMaxRetry {
+				return fmt.Errorf("add meta partition failed: %v", err)
+			}
+			log.LogWarnf("action[doPreWarm] vol[%v] add meta partition failed, retry[%v]: %v", vol.Name, retry, err)
+			time.Sleep(preWarmRetryInterval)
+			continue
+		}
+		retry = 0
+		preWarm.update(vol.getMetaPartitionCount(), dpCount())
+	}
+
+	for needDp && dpCount() < status.TargetDpCount {
+		if vol.status() == proto.VolStatusMarkDelete {
+			return fmt.Errorf("vol[%v] is marked delete", vol.Name)
+		}
+		count := status.TargetDpCount - dpCount()
+		if count > preWarmDpBatchCount {
+			count = preWarmDpBatchCount
+		}
+		before := dpCount()
+		if err = c.batchCreateDataPartition(vol, count, false, mediaType); err != nil && dpCount() == before {
+			if retry++; retry > preWarmMaxRetry {
+				return fmt.Errorf("create data partition failed: %v", err)
+			}
+			log.LogWarnf("action[doPreWarm] vol[%v] create data partition failed, retry[%v]: %v", vol.Name, retry, err)
+			time.Sleep(preWarmRetryInterval)
+			continue
+		}
+		retry = 0
+		preWarm.update(vol.getMetaPartitionCount(), dpCount())
+	}
+	err = nil
+	preWarm.update(vol.getMetaPartitionCount(), dpCount())
+	return
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputePreWarmTarget(t *testing.T) {
+	step := uint64(1 << 24)
+	mpCount, dpCount := computePreWarmTarget(0, 0, 120, step)
+	require.Equal(t, defaultInitMetaPartitionCount, mpCount)
+	require.Equal(t, defaultInitDataPartitionCnt, dpCount)
+
+	mpCount, dpCount = computePreWarmTarget(10*step+1, 1200, 120, step)
+	require.Equal(t, 11, mpCount)
+	require.Equal(t, 10, dpCount)
+
+	mpCount, dpCount = computePreWarmTarget(1000*step, 1000*1200, 120, step)
+	require.Equal(t, defaultMaxInitMetaPartitionCount, mpCount)
+	require.Equal(t, maxInitDataPartitionCnt, dpCount)
+}
+
+func TestVolPreWarmStatus(t *testing.T) {
+	p := newVolPreWarm("vol", 5, 10)
+	require.Equal(t, proto.VolPreWarmRunning, p.getStatus().Status)
+	p.update(4, 8)
+	status := p.getStatus()
+	require.Equal(t, 4, status.MpCount)
+	require.Equal(t, 8, status.DpCount)
+	p.finish(fmt.Errorf("no leader"))
+	status = p.getStatus()
+	require.Equal(t, proto.VolPreWarmFailed, status.Status)
+	require.Equal(t, "no leader", status.ErrMsg)
+	require.NotZero(t, status.EndTime)
+}
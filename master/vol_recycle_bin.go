@@ -0,0 +1,90 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// getVolRecycleBin returns the volumes deleted with delay. They keep their metadata and
+// partitions until the deletion is executed, and are restored by undeleting them.
+func (c *Cluster) getVolRecycleBin() (bin *proto.VolRecycleBin) {
+	c.deleteVolMutex.RLock()
+	infos := make([]*delayDeleteVolInfo, len(c.delayDeleteVolsInfo))
+	copy(infos, c.delayDeleteVolsInfo)
+	c.deleteVolMutex.RUnlock()
+
+	bin = &proto.VolRecycleBin{Vols: make([]*proto.VolRecycleBinEntry, 0, len(infos))}
+	for _, info := range infos {
+		vol, err := c.getVol(info.volName)
+		if err != nil {
+			continue
+		}
+		entry := &proto.VolRecycleBinEntry{
+			Name:               vol.Name,
+			Owner:              vol.Owner,
+			DeleteExecTime:     info.execTime.Unix(),
+			Capacity:           vol.capacity(),
+			UsedSize:           vol.totalUsedSpace(),
+			DataPartitionCount: vol.getDataPartitionsCount(),
+			MetaPartitionCount: len(vol.cloneMetaPartitionMap()),
+		}
+		bin.Capacity += entry.Capacity
+		bin.UsedSize += entry.UsedSize
+		bin.Vols = append(bin.Vols, entry)
+	}
+	sort.Slice(bin.Vols, func(i, j int) bool { return bin.Vols[i].DeleteExecTime < bin.Vols[j].DeleteExecTime })
+	return
+}
+
+// purgeVolFromRecycleBin ends the retention of the volume in the recycle bin, the volume is
+// deleted by scheduleToCheckDelayDeleteVols at its next round.
+func (c *Cluster) purgeVolFromRecycleBin(name, authKey string) (err error) {
+	vol, err := c.getVol(name)
+	if err != nil {
+		return proto.ErrVolNotExists
+	}
+	if !matchKey(vol.Owner, authKey) {
+		return proto.ErrVolAuthKeyNotMatch
+	}
+
+	c.deleteVolMutex.Lock()
+	defer c.deleteVolMutex.Unlock()
+	var info *delayDeleteVolInfo
+	for _, value := range c.delayDeleteVolsInfo {
+		if value.volName == name {
+			info = value
+			break
+		}
+	}
+	if info == nil {
+		return fmt.Errorf("vol[%v] is not in the recycle bin", name)
+	}
+
+	oldDeleteExecTime := vol.DeleteExecTime
+	vol.DeleteExecTime = time.Now()
+	if err = c.syncUpdateVol(vol); err != nil {
+		vol.DeleteExecTime = oldDeleteExecTime
+		return proto.ErrPersistenceByRaft
+	}
+	info.execTime = vol.DeleteExecTime
+	log.LogWarnf("action[purgeVolFromRecycleBin] vol[%v] retention until[%v] is ended", name, oldDeleteExecTime)
+	return
+}
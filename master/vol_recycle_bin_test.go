@@ -0,0 +1,55 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolRecycleBin(t *testing.T) {
+	name := "recycleBinVol"
+	createVol(map[string]interface{}{nameKey: name}, t)
+	vol, err := server.cluster.getVol(name)
+	require.NoError(t, err)
+
+	execTime := time.Now().Add(time.Hour)
+	vol.DeleteExecTime = execTime
+	server.cluster.deleteVolMutex.Lock()
+	server.cluster.delayDeleteVolsInfo = append(server.cluster.delayDeleteVolsInfo,
+		&delayDeleteVolInfo{volName: name, authKey: buildAuthKey(testOwner), execTime: execTime, user: server.user})
+	server.cluster.deleteVolMutex.Unlock()
+
+	var entry *proto.VolRecycleBinEntry
+	bin := server.cluster.getVolRecycleBin()
+	for _, e := range bin.Vols {
+		if e.Name == name {
+			entry = e
+		}
+	}
+	require.NotNil(t, entry)
+	require.Equal(t, execTime.Unix(), entry.DeleteExecTime)
+	require.EqualValues(t, 300, entry.Capacity)
+	require.Equal(t, vol.getDataPartitionsCount(), entry.DataPartitionCount)
+	require.True(t, bin.Capacity >= entry.Capacity)
+
+	require.Error(t, server.cluster.purgeVolFromRecycleBin(name, buildAuthKey("other")))
+	require.Error(t, server.cluster.purgeVolFromRecycleBin(commonVolName, buildAuthKey(testOwner)))
+	require.NoError(t, server.cluster.purgeVolFromRecycleBin(name, buildAuthKey(testOwner)))
+	require.False(t, vol.DeleteExecTime.After(time.Now()))
+}
@@ -0,0 +1,354 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/compressor"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultVolReplicaSyncInterval = 3600 // seconds
+	minVolReplicaSyncInterval     = 60
+	volReplicaViewCacheTTL        = 5 * time.Second
+)
+
+// volReplicaState holds the in-memory state of a read-only replica vol. A replica has
+// the meta partitions of its own, which mirror the inode ranges of the source vol and
+// are synced from the snapshots of the source partitions, and reads the data partitions
+// of the source vol.
+type volReplicaState struct {
+	sync.Mutex
+	lastSync     int64
+	view         []byte
+	viewCompress []byte
+	viewTime     time.Time
+}
+
+func (vol *Vol) isVolReplica() bool {
+	return vol.SourceVol != ""
+}
+
+func (vol *Vol) getReplicaSyncInterval() int64 {
+	if vol.replicaSyncInterval <= 0 {
+		return defaultVolReplicaSyncInterval
+	}
+	return vol.replicaSyncInterval
+}
+
+// volReplicas returns the names of the replicas of the source vol.
+func (c *Cluster) volReplicas(source string) (names []string) {
+	names = make([]string, 0)
+	for _, vol := range c.copyVols() {
+		if vol.SourceVol == source && vol.Status != proto.VolStatusMarkDelete {
+			names = append(names, vol.Name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+func (c *Cluster) checkVolReplicasBeforeDelete(vol *Vol) (err error) {
+	if replicas := c.volReplicas(vol.Name); len(replicas) != 0 {
+		err = fmt.Errorf("vol[%v] is the source of the replicas %v, delete them first", vol.Name, replicas)
+	}
+	return
+}
+
+func (c *Cluster) createVolReplica(name, owner, source string, interval int64) (vol *Vol, err error) {
+	if c.DisableAutoAllocate {
+		return nil, fmt.Errorf("the cluster is frozen, can not create volume")
+	}
+	if interval != 0 && interval < minVolReplicaSyncInterval {
+		return nil, fmt.Errorf("sync interval must be at least %v seconds", minVolReplicaSyncInterval)
+	}
+	var src *Vol
+	if src, err = c.getVol(source); err != nil {
+		return nil, proto.ErrVolNotExists
+	}
+	if src.Status == proto.VolStatusMarkDelete {
+		return nil, fmt.Errorf("source vol[%v] is being deleted", source)
+	}
+	if src.isVolReplica() {
+		return nil, fmt.Errorf("source vol[%v] is a replica of %v", source, src.SourceVol)
+	}
+	if !proto.IsHot(src.VolType) {
+		return nil, fmt.Errorf("source vol[%v] is not a hot vol", source)
+	}
+
+	req := &createVolReq{
+		name:                    name,
+		owner:                   owner,
+		dpSize:                  int(src.dataPartitionSize / util.GB),
+		dpReplicaNum:            src.dpReplicaNum,
+		capacity:                int(src.Capacity),
+		crossZone:               src.crossZone,
+		domainId:                src.domainId,
+		zoneName:                src.zoneName,
+		description:             fmt.Sprintf("read-only replica of %v", source),
+		volType:                 proto.VolumeTypeHot,
+		enableTransaction:       src.enableTransaction,
+		txTimeout:               src.txTimeout,
+		txConflictRetryNum:      src.txConflictRetryNum,
+		txConflictRetryInterval: src.txConflictRetryInterval,
+		qosLimitArgs:            &qosArgs{},
+		volStorageClass:         src.volStorageClass,
+		allowedStorageClass:     append([]uint32{}, src.allowedStorageClass...),
+		sourceVol:               source,
+		replicaSyncInterval:     interval,
+	}
+	if req.zoneName, err = c.checkZoneName(req.name, req.crossZone, req.normalZonesFirst, req.zoneName, req.domainId); err != nil {
+		return
+	}
+	if vol, err = c.doCreateVol(req); err != nil {
+		return
+	}
+
+	vol.aclMgr.init(c, vol)
+	vol.initUidSpaceManager(c)
+	vol.initQuotaManager(c)
+	if err = vol.VersionMgr.init(c); err != nil {
+		log.LogError("init dataPartition error in verMgr init", err.Error())
+	}
+
+	if err = c.mirrorVolReplicaLayout(vol, src); err != nil {
+		vol.Status = proto.VolStatusMarkDelete
+		if e := vol.deleteVolFromStore(c); e != nil {
+			log.LogErrorf("action[createVolReplica] deleteVolFromStore failed, vol[%v] err[%v]", vol.Name, e)
+		}
+		c.deleteVol(req.name)
+		err = fmt.Errorf("action[createVolReplica] mirror meta partitions failed, vol[%v] err[%v]", vol.Name, err)
+		return
+	}
+	vol.updateViewCache(c)
+	log.LogInfof("action[createVolReplica] vol[%v] replica of [%v] created", name, source)
+	return
+}
+
+// mirrorVolReplicaLayout makes the meta partitions of the replica cover the same inode
+// ranges as the ones of the source, by creating them for a new replica or by splitting
+// the last one of the replica as the source has split since.
+func (c *Cluster) mirrorVolReplicaLayout(vol, src *Vol) (err error) {
+	vol.createMpMutex.Lock()
+	defer vol.createMpMutex.Unlock()
+
+	srcMps := src.getSortMetaPartitions()
+	if len(srcMps) == 0 {
+		return fmt.Errorf("source vol[%v] has no meta partition", src.Name)
+	}
+	mps := vol.getSortMetaPartitions()
+	if len(mps) == 0 {
+		for _, smp := range srcMps {
+			if err = vol.createMetaPartition(c, smp.Start, smp.End); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	for i := 0; i < len(srcMps); i++ {
+		if i >= len(mps) {
+			last := mps[len(mps)-1]
+			var nextMp *MetaPartition
+			if nextMp, err = vol.doSplitMetaPartition(c, last, srcMps[i].Start-1, gConfig.MetaPartitionInodeIdStep, true); err != nil {
+				return
+			}
+			vol.addMetaPartition(nextMp)
+			log.LogWarnf("action[mirrorVolReplicaLayout] vol[%v] split mp[%v] at[%v] as source[%v]",
+				vol.Name, last.PartitionID, last.End, src.Name)
+			mps = append(mps, nextMp)
+		}
+		if mps[i].Start != srcMps[i].Start {
+			return fmt.Errorf("mp[%v] start[%v] of vol[%v] mismatches mp[%v] start[%v] of source[%v]",
+				mps[i].PartitionID, mps[i].Start, vol.Name, srcMps[i].PartitionID, srcMps[i].Start, src.Name)
+		}
+	}
+	return
+}
+
+func (mr *MetaReplica) createTaskToSyncMetaReplica(req *proto.SyncMetaReplicaRequest) (t *proto.AdminTask) {
+	t = proto.NewAdminTask(proto.OpSyncMetaReplica, mr.Addr, req)
+	resetMetaPartitionTaskID(t, req.PartitionID)
+	return
+}
+
+// syncVolReplica asks the leader of every meta partition of the replica for its sync
+// status, and to start syncing from the source partition of the same range if start.
+func (c *Cluster) syncVolReplica(vol *Vol, start bool) (status *proto.VolReplicaStatus, err error) {
+	var src *Vol
+	if src, err = c.getVol(vol.SourceVol); err != nil {
+		return nil, proto.ErrVolNotExists
+	}
+	if start {
+		if err = c.mirrorVolReplicaLayout(vol, src); err != nil {
+			return
+		}
+		vol.replica.Lock()
+		vol.replica.lastSync = time.Now().Unix()
+		vol.replica.Unlock()
+	}
+
+	srcMps := make(map[uint64]*MetaPartition)
+	for _, smp := range src.getSortMetaPartitions() {
+		srcMps[smp.Start] = smp
+	}
+	mps := vol.getSortMetaPartitions()
+
+	status = &proto.VolReplicaStatus{
+		Name:         vol.Name,
+		SourceVol:    vol.SourceVol,
+		SyncInterval: vol.getReplicaSyncInterval(),
+		Partitions:   make([]*proto.MetaReplicaSyncStatus, len(mps)),
+	}
+	vol.replica.Lock()
+	status.LastSyncTime = vol.replica.lastSync
+	vol.replica.Unlock()
+
+	var wg sync.WaitGroup
+	for i, mp := range mps {
+		wg.Add(1)
+		go func(i int, mp *MetaPartition) {
+			defer wg.Done()
+			ps, e := c.syncMetaReplica(mp, srcMps[mp.Start], vol.SourceVol, start)
+			if e != nil {
+				log.LogWarnf("action[syncVolReplica] vol[%v] mp[%v] err[%v]", vol.Name, mp.PartitionID, e)
+				ps = &proto.MetaReplicaSyncStatus{PartitionID: mp.PartitionID, LastError: e.Error()}
+			}
+			status.Partitions[i] = ps
+		}(i, mp)
+	}
+	wg.Wait()
+	return
+}
+
+func (c *Cluster) syncMetaReplica(mp, smp *MetaPartition, source string, start bool) (status *proto.MetaReplicaSyncStatus, err error) {
+	if smp == nil {
+		return nil, fmt.Errorf("no meta partition of source[%v] starts at %v", source, mp.Start)
+	}
+	req := &proto.SyncMetaReplicaRequest{
+		PartitionID:       mp.PartitionID,
+		SourceVol:         source,
+		SourcePartitionID: smp.PartitionID,
+		Start:             start,
+	}
+	smp.RLock()
+	if leader, e := smp.getMetaReplicaLeader(); e == nil {
+		req.SourceHosts = append(req.SourceHosts, leader.Addr)
+	}
+	for _, host := range smp.Hosts {
+		if len(req.SourceHosts) == 0 || host != req.SourceHosts[0] {
+			req.SourceHosts = append(req.SourceHosts, host)
+		}
+	}
+	smp.RUnlock()
+
+	mp.RLock()
+	mr, err := mp.getMetaReplicaLeader()
+	mp.RUnlock()
+	if err != nil {
+		return
+	}
+	packet, err := mr.metaNode.Sender.syncSendAdminTask(mr.createTaskToSyncMetaReplica(req))
+	if err != nil {
+		return
+	}
+	status = &proto.MetaReplicaSyncStatus{}
+	if err = json.Unmarshal(packet.Data, status); err != nil {
+		return nil, errors.NewErrorf("unmarshal sync status of mp[%v] err[%v]", mp.PartitionID, err)
+	}
+	return
+}
+
+func (c *Cluster) scheduleToSyncVolReplicas() {
+	c.runTask(
+		&cTask{
+			tickTime: time.Minute,
+			name:     "scheduleToSyncVolReplicas",
+			function: func() (fin bool) {
+				if c.partition.IsRaftLeader() {
+					c.syncVolReplicas()
+				}
+				return
+			},
+		})
+}
+
+func (c *Cluster) syncVolReplicas() {
+	now := time.Now().Unix()
+	for _, vol := range c.copyVols() {
+		if !vol.isVolReplica() || vol.Status == proto.VolStatusMarkDelete {
+			continue
+		}
+		vol.replica.Lock()
+		due := now-vol.replica.lastSync >= vol.getReplicaSyncInterval()
+		vol.replica.Unlock()
+		if !due {
+			continue
+		}
+		if _, err := c.syncVolReplica(vol, true); err != nil {
+			log.LogWarnf("action[syncVolReplicas] vol[%v] source[%v] err[%v]", vol.Name, vol.SourceVol, err)
+		}
+	}
+}
+
+// getVolDataPartitionsView returns the data partitions view of the vol for clients,
+// a replica reads the data partitions of its source and is always read-only.
+func (c *Cluster) getVolDataPartitionsView(vol *Vol, compress bool) (body []byte, err error) {
+	if !vol.isVolReplica() {
+		if compress {
+			return vol.getDataPartitionViewCompress()
+		}
+		return vol.getDataPartitionsView()
+	}
+
+	vol.replica.Lock()
+	defer vol.replica.Unlock()
+	if vol.replica.view == nil || time.Since(vol.replica.viewTime) > volReplicaViewCacheTTL {
+		var src *Vol
+		if src, err = c.getVol(vol.SourceVol); err != nil {
+			return nil, proto.ErrVolNotExists
+		}
+		cv := proto.NewDataPartitionsView()
+		cv.DataPartitions = src.dataPartitions.getDataPartitionsView(0)
+		if len(cv.DataPartitions) == 0 {
+			return nil, proto.ErrNoAvailDataPartition
+		}
+		cv.VolReadOnly = true
+		if body, err = json.Marshal(newSuccessHTTPReply(cv)); err != nil {
+			return nil, proto.ErrMarshalData
+		}
+		vol.replica.view = body
+		vol.replica.viewCompress = nil
+		vol.replica.viewTime = time.Now()
+	}
+	if !compress {
+		return vol.replica.view, nil
+	}
+	if vol.replica.viewCompress == nil {
+		if vol.replica.viewCompress, err = compressor.New(compressor.EncodingGzip).Compress(vol.replica.view); err != nil {
+			return nil, proto.ErrCompressFailed
+		}
+	}
+	return vol.replica.viewCompress, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolReplica(t *testing.T) {
+	c := server.cluster
+	src, err := c.getVol(commonVolName)
+	require.NoError(t, err)
+
+	name := "commonVolReplica"
+	_, err = c.createVolReplica(name, testOwner, "noSuchVol", 0)
+	require.Error(t, err)
+	_, err = c.createVolReplica(name, testOwner, commonVolName, 10)
+	require.Error(t, err)
+	vol, err := c.createVolReplica(name, testOwner, commonVolName, 0)
+	require.NoError(t, err)
+	require.True(t, vol.isVolReplica())
+	require.Equal(t, []string{name}, c.volReplicas(commonVolName))
+	require.Equal(t, commonVolName, newSimpleView(vol).SourceVol)
+	_, err = c.createVolReplica("chainedReplica", testOwner, name, 0)
+	require.Error(t, err)
+
+	srcMps, mps := src.getSortMetaPartitions(), vol.getSortMetaPartitions()
+	require.Equal(t, len(srcMps), len(mps))
+	for i := range mps {
+		require.Equal(t, srcMps[i].Start, mps[i].Start)
+		require.Equal(t, srcMps[i].End, mps[i].End)
+	}
+	require.Error(t, vol.splitMetaPartition(c, mps[len(mps)-1], mps[len(mps)-1].Start+1, gConfig.MetaPartitionInodeIdStep, true))
+
+	// the replica reads the data partitions of the source
+	body, err := c.getVolDataPartitionsView(vol, false)
+	require.NoError(t, err)
+	reply := &struct{ Data *proto.DataPartitionsView }{}
+	require.NoError(t, json.Unmarshal(body, reply))
+	require.True(t, reply.Data.VolReadOnly)
+	require.Len(t, reply.Data.DataPartitions, len(src.dataPartitions.getDataPartitionsView(0)))
+	_, err = c.getVolDataPartitionsView(vol, true)
+	require.NoError(t, err)
+
+	for _, mp := range mps {
+		if _, e := mp.getMetaReplicaLeader(); e != nil && len(mp.Replicas) > 0 {
+			mp.Replicas[0].IsLeader = true
+		}
+	}
+	status, err := c.syncVolReplica(vol, true)
+	require.NoError(t, err)
+	require.NotZero(t, status.LastSyncTime)
+	require.Len(t, status.Partitions, len(mps))
+	for i, ps := range status.Partitions {
+		require.Empty(t, ps.LastError)
+		require.Equal(t, mps[i].PartitionID, ps.PartitionID)
+		require.Equal(t, srcMps[i].PartitionID, ps.SourcePartitionID)
+	}
+
+	require.Error(t, c.checkVolReplicasBeforeDelete(src))
+	require.Error(t, c.markDeleteVol(commonVolName, buildAuthKey(testOwner), false, true))
+	require.NoError(t, c.markDeleteVol(name, buildAuthKey(testOwner), false, true))
+	require.Empty(t, c.volReplicas(commonVolName))
+}
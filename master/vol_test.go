@@ -3,6 +3,7 @@ package master
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -416,3 +417,308 @@ func TestConcurrentReadWriteDataPartitionMap(t *testing.T) {
 		vol.updateViewCache(server.cluster)
 	}
 }
+
+func TestParseDefaultXAttrs(t *testing.T) {
+	xattrs, err := parseDefaultXAttrs("tier:cold, owner:lifecycle,url:http://a")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"tier": "cold", "owner": "lifecycle", "url": "http://a"}, xattrs)
+
+	xattrs, err = parseDefaultXAttrs("")
+	require.NoError(t, err)
+	require.Empty(t, xattrs)
+
+	_, err = parseDefaultXAttrs("tier")
+	require.Error(t, err)
+	_, err = parseDefaultXAttrs(":cold")
+	require.Error(t, err)
+	_, err = parseDefaultXAttrs(proto.QuotaKey + ":1")
+	require.Error(t, err)
+	_, err = parseDefaultXAttrs(proto.InnerXAttrPrefix + "dir_lock_key:1")
+	require.Error(t, err)
+	_, err = parseDefaultXAttrs(`tier:cold\`)
+	require.Error(t, err)
+
+	// the separators are kept in the keys and the values if escaped
+	pair := proto.EscapeXAttrPair("a:b,c") + ":" + proto.EscapeXAttrPair(`x,y\z`)
+	xattrs, err = parseDefaultXAttrs(pair + ",tier:cold")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a:b,c": `x,y\z`, "tier": "cold"}, xattrs)
+
+	pairs := make([]string, 0, maxVolDefaultXAttrCount+1)
+	for i := 0; i <= maxVolDefaultXAttrCount; i++ {
+		pairs = append(pairs, fmt.Sprintf("k%v:v", i))
+	}
+	_, err = parseDefaultXAttrs(strings.Join(pairs, ","))
+	require.Error(t, err)
+}
+
+func TestXAttrLimitCheck(t *testing.T) {
+	xattrs := map[string]string{"tier": "cold", "owner": "lifecycle"}
+	require.NoError(t, proto.XAttrLimit{}.Check(xattrs))
+	require.NoError(t, proto.XAttrLimit{MaxCount: 2, MaxKeySize: 5, MaxValueSize: 9, MaxTotalSize: 22}.Check(xattrs))
+	require.Error(t, proto.XAttrLimit{MaxCount: 1}.Check(xattrs))
+	require.Error(t, proto.XAttrLimit{MaxKeySize: 4}.Check(xattrs))
+	require.Error(t, proto.XAttrLimit{MaxValueSize: 8}.Check(xattrs))
+	require.Error(t, proto.XAttrLimit{MaxTotalSize: 21}.Check(xattrs))
+}
+
+func TestVolDefaultXAttrs(t *testing.T) {
+	vol := newVol(volValue{ID: 1, Name: "xattrVol"})
+	require.Nil(t, vol.getDefaultXAttrs())
+
+	vol.setDefaultXAttrs(map[string]string{"tier": "cold"})
+	xattrs := vol.getDefaultXAttrs()
+	xattrs["tier"] = "hot"
+	require.Equal(t, "cold", vol.getDefaultXAttrs()["tier"])
+
+	vv := newVolValue(vol)
+	require.Equal(t, map[string]string{"tier": "cold"}, newVolFromVolValue(vv).getDefaultXAttrs())
+}
+
+func TestVolMetaWorkerWeight(t *testing.T) {
+	vol := newVol(volValue{ID: 1, Name: "weightVol"})
+	require.Zero(t, vol.getMetaWorkerWeight())
+	vol.setMetaWorkerWeight(5)
+	require.EqualValues(t, 5, newVolFromVolValue(newVolValue(vol)).getMetaWorkerWeight())
+}
+
+func TestVolDpPins(t *testing.T) {
+	vol := newVol(volValue{ID: 1, Name: "pinVol", VolStorageClass: proto.StorageClass_Replica_SSD})
+	require.Nil(t, vol.getDpPins())
+	// the files of the vol are never written to hdd
+	require.Error(t, vol.putDpPin(&proto.DataPartitionPin{Path: "/hot", MediaType: proto.MediaType_HDD}))
+
+	require.NoError(t, vol.putDpPin(&proto.DataPartitionPin{Path: "/hot", MediaType: proto.MediaType_SSD}))
+	require.NoError(t, vol.putDpPin(&proto.DataPartitionPin{Path: "/hot", ZoneName: "ssdZone"}))
+	pins := vol.getDpPins()
+	require.Len(t, pins, 1)
+	require.Equal(t, "ssdZone", pins[0].ZoneName)
+
+	vv := newVolValue(vol)
+	require.Equal(t, pins, newVolFromVolValue(vv).getDpPins())
+
+	require.Error(t, vol.removeDpPin("/cold"))
+	require.NoError(t, vol.removeDpPin("/hot"))
+	require.Empty(t, vol.getDpPins())
+
+	for i := 0; i < maxVolDpPinCount; i++ {
+		require.NoError(t, vol.putDpPin(&proto.DataPartitionPin{Path: fmt.Sprintf("/d%v", i), MediaType: proto.MediaType_SSD}))
+	}
+	require.Error(t, vol.putDpPin(&proto.DataPartitionPin{Path: "/more", MediaType: proto.MediaType_SSD}))
+	require.NoError(t, vol.putDpPin(&proto.DataPartitionPin{Path: "/d0", ZoneName: "ssdZone"}))
+}
+
+func TestVolReadOnlyWindows(t *testing.T) {
+	vol := newVol(volValue{ID: 1, Name: "windowVol"})
+	require.Nil(t, vol.getReadOnlyWindows())
+	require.False(t, vol.inReadOnlyWindow(100))
+
+	require.Error(t, vol.addReadOnlyWindow(&proto.VolReadOnlyWindow{Start: 200, End: 200}, 100))
+	require.Error(t, vol.addReadOnlyWindow(&proto.VolReadOnlyWindow{Start: 10, End: 50}, 100))
+
+	first := &proto.VolReadOnlyWindow{Start: 100, End: 200, Reason: "backup"}
+	require.NoError(t, vol.addReadOnlyWindow(first, 100))
+	second := &proto.VolReadOnlyWindow{Start: 300, End: 400}
+	require.NoError(t, vol.addReadOnlyWindow(second, 100))
+	require.EqualValues(t, 1, first.ID)
+	require.EqualValues(t, 2, second.ID)
+	require.True(t, vol.inReadOnlyWindow(150))
+	require.False(t, vol.inReadOnlyWindow(200))
+	require.True(t, vol.inReadOnlyWindow(300))
+
+	vv := newVolValue(vol)
+	require.Equal(t, vol.getReadOnlyWindows(), newVolFromVolValue(vv).getReadOnlyWindows())
+
+	// the ended window is dropped
+	third := &proto.VolReadOnlyWindow{Start: 500, End: 600}
+	require.NoError(t, vol.addReadOnlyWindow(third, 250))
+	require.EqualValues(t, 3, third.ID)
+	require.Equal(t, []*proto.VolReadOnlyWindow{second, third}, vol.getReadOnlyWindows())
+
+	require.Error(t, vol.cancelReadOnlyWindow(1))
+	require.NoError(t, vol.cancelReadOnlyWindow(2))
+	require.False(t, vol.inReadOnlyWindow(350))
+	require.Len(t, vol.getReadOnlyWindows(), 1)
+}
+
+func TestVolMetaFreeze(t *testing.T) {
+	vol, err := server.cluster.getVol(commonVolName)
+	require.NoError(t, err)
+	defer vol.metaFrozen.Store(false)
+
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaFreeze(commonVolName, true))
+	require.True(t, vol.metaFrozen.Load())
+	require.True(t, newVolFromVolValue(newVolValue(vol)).metaFrozen.Load())
+	view, err := mc.AdminAPI().GetVolumeSimpleInfo(commonVolName)
+	require.NoError(t, err)
+	require.True(t, view.MetaFrozen)
+
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaFreeze(commonVolName, false))
+	require.False(t, vol.metaFrozen.Load())
+	reply := processNoCheck(fmt.Sprintf("%v%v?name=%v&freeze=maybe", hostAddr, proto.AdminVolMetaFreeze, commonVolName), t)
+	require.EqualValues(t, proto.ErrCodeParamError, reply.Code)
+}
+
+func TestVolMetaAuth(t *testing.T) {
+	// a vol and owner of its own, the shared ones are transferred by the other tests
+	name, owner := "metaAuthVol", "metaAuthOwner"
+	createVol(map[string]interface{}{nameKey: name, volOwnerKey: owner}, t)
+	defer func() {
+		reqURL := fmt.Sprintf("%v%v?name=%v&authKey=%v", hostAddr, proto.AdminDeleteVol, name, buildAuthKey(owner))
+		process(reqURL, t)
+	}()
+	vol, err := server.cluster.getVol(name)
+	require.NoError(t, err)
+	user, err := server.user.getUserInfo(owner)
+	require.NoError(t, err)
+
+	// no token is issued if the vol does not enforce the meta auth
+	reply, err := mc.ClientAPI().GetMetaAccessToken(name, user.AccessKey, user.SecretKey)
+	require.NoError(t, err)
+	require.Empty(t, reply.Token)
+
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaAuth(name, true, false))
+	keys := vol.getMetaAccessKeys()
+	require.Len(t, keys, 1)
+	require.EqualValues(t, 1, keys[0].ID)
+	require.Equal(t, keys, newVolFromVolValue(newVolValue(vol)).getMetaAccessKeys())
+	view, err := mc.AdminAPI().GetVolumeSimpleInfo(name)
+	require.NoError(t, err)
+	require.True(t, view.MetaAuth)
+	// enabled again, the key is kept
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaAuth(name, true, false))
+	require.Equal(t, keys, vol.getMetaAccessKeys())
+
+	reply, err = mc.ClientAPI().GetMetaAccessToken(name, user.AccessKey, user.SecretKey)
+	require.NoError(t, err)
+	token, err := proto.DecodeMetaAccessToken([]byte(reply.Token))
+	require.NoError(t, err)
+	require.Equal(t, user.UserID, token.UserID)
+	require.Equal(t, reply.Expire, token.Expire)
+	require.NoError(t, token.Verify(name, keys, time.Now()))
+
+	_, err = mc.ClientAPI().GetMetaAccessToken(name, user.AccessKey, "wrong secret key")
+	require.Error(t, err)
+	ts := time.Now().Unix() - 2*proto.MetaTokenRequestWindow
+	reply2 := processNoCheck(fmt.Sprintf("%v%v?name=%v&ak=%v&ts=%v&sign=%v", hostAddr, proto.ClientMetaToken, name,
+		user.AccessKey, ts, proto.MetaTokenRequestSign(user.SecretKey, name, user.AccessKey, ts)), t)
+	require.EqualValues(t, proto.ErrCodeParamError, reply2.Code)
+
+	// the key rotated out is kept to verify the tokens signed by it
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaAuth(name, true, true))
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaAuth(name, true, true))
+	rotated := vol.getMetaAccessKeys()
+	require.Len(t, rotated, proto.MetaAccessKeyCount)
+	require.EqualValues(t, 2, rotated[0].ID)
+	require.EqualValues(t, 3, rotated[1].ID)
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, token.Verify(name, rotated, time.Now()))
+
+	require.NoError(t, mc.AdminAPI().SetVolumeMetaAuth(name, false, false))
+	require.Empty(t, vol.getMetaAccessKeys())
+}
+
+func TestParseDpPin(t *testing.T) {
+	parse := func(query string) (*proto.DataPartitionPin, error) {
+		r, err := http.NewRequest(http.MethodGet, "/vol/dpPin/set?"+query, nil)
+		require.NoError(t, err)
+		return parseAndExtractDpPin(r)
+	}
+	pin, err := parse("path=/a/b/&mediaType=1")
+	require.NoError(t, err)
+	require.Equal(t, &proto.DataPartitionPin{Path: "/a/b", MediaType: proto.MediaType_SSD}, pin)
+
+	pin, err = parse("path=/a&zoneName=z1")
+	require.NoError(t, err)
+	require.Equal(t, "z1", pin.ZoneName)
+
+	_, err = parse("path=/a")
+	require.Error(t, err)
+	_, err = parse("path=a&mediaType=1")
+	require.Error(t, err)
+	_, err = parse("mediaType=1")
+	require.Error(t, err)
+	_, err = parse("path=/a&mediaType=5")
+	require.Error(t, err)
+}
+
+func TestExtractXAttrLimit(t *testing.T) {
+	old := proto.XAttrLimit{MaxCount: 10, MaxTotalSize: 1024}
+	r, err := http.NewRequest(http.MethodGet, "/vol/update?name=vol&xattrMaxCount=20&xattrMaxValueSize=256", nil)
+	require.NoError(t, err)
+	limit, err := extractXAttrLimit(r, old)
+	require.NoError(t, err)
+	require.Equal(t, proto.XAttrLimit{MaxCount: 20, MaxValueSize: 256, MaxTotalSize: 1024}, limit)
+
+	r, err = http.NewRequest(http.MethodGet, "/vol/update?name=vol&xattrMaxKeySize=-1", nil)
+	require.NoError(t, err)
+	_, err = extractXAttrLimit(r, old)
+	require.Error(t, err)
+}
+
+func TestExtractExtentConflictPolicy(t *testing.T) {
+	extract := func(query string) (string, error) {
+		r, err := http.NewRequest(http.MethodGet, "/vol/update?name=vol"+query, nil)
+		require.NoError(t, err)
+		require.NoError(t, r.ParseForm())
+		return extractExtentConflictPolicy(r, proto.ExtentConflictPolicyReject)
+	}
+	policy, err := extract("")
+	require.NoError(t, err)
+	require.Equal(t, proto.ExtentConflictPolicyReject, policy)
+	policy, err = extract("&extentConflictPolicy=lww")
+	require.NoError(t, err)
+	require.Equal(t, proto.ExtentConflictPolicyLWW, policy)
+	policy, err = extract("&extentConflictPolicy=")
+	require.NoError(t, err)
+	require.Equal(t, proto.ExtentConflictPolicyNone, policy)
+	_, err = extract("&extentConflictPolicy=invalid")
+	require.Error(t, err)
+}
+
+func TestVolPurge(t *testing.T) {
+	vol, err := server.cluster.getVol(commonVolName)
+	require.NoError(t, err)
+	defer vol.setPurgeCtrl(proto.VolPurgeCtrl{})
+
+	status, err := mc.AdminAPI().SetVolumePurge(commonVolName, "")
+	require.NoError(t, err)
+	require.False(t, status.Paused)
+	require.Nil(t, vol.getPurgeCtrl())
+
+	status, err = mc.AdminAPI().SetVolumePurge(commonVolName, proto.PurgeOpPause)
+	require.NoError(t, err)
+	require.True(t, status.Paused)
+	require.Equal(t, vol.getPurgeCtrl(), newVolFromVolValue(newVolValue(vol)).getPurgeCtrl())
+
+	// a force resumes the purge
+	status, err = mc.AdminAPI().SetVolumePurge(commonVolName, proto.PurgeOpForce)
+	require.NoError(t, err)
+	require.False(t, status.Paused)
+	require.EqualValues(t, 1, status.ForceSeq)
+
+	_, err = mc.AdminAPI().SetVolumePurge(commonVolName, "unknown")
+	require.Error(t, err)
+	require.EqualValues(t, 1, vol.getPurgeCtrl().ForceSeq)
+}
+
+func TestVolMetaOpQuota(t *testing.T) {
+	vol, err := server.cluster.getVol(commonVolName)
+	require.NoError(t, err)
+	defer vol.setMetaOpQuota(proto.MetaOpQuota{})
+
+	require.NoError(t, mc.AdminAPI().SetVolMetaOpQuota(commonVolName, proto.MetaOpQuota{CreateOps: 100, LookupOps: 1000}))
+	require.Equal(t, &proto.MetaOpQuota{CreateOps: 100, LookupOps: 1000}, vol.getMetaOpQuota())
+	require.Equal(t, vol.getMetaOpQuota(), newVolFromVolValue(newVolValue(vol)).getMetaOpQuota())
+
+	// the rates not set are kept
+	reqUrl := fmt.Sprintf("%v%v?%v=%v&%v=50", hostAddr, proto.AdminSetNodeInfo, nameKey, commonVolName, metaDeleteOpRateKey)
+	process(reqUrl, t)
+	require.Equal(t, &proto.MetaOpQuota{CreateOps: 100, DeleteOps: 50, LookupOps: 1000}, vol.getMetaOpQuota())
+
+	// the rates are of a vol
+	reply := processNoCheck(fmt.Sprintf("%v%v?%v=50", hostAddr, proto.AdminSetNodeInfo, metaDeleteOpRateKey), t)
+	require.NotEqual(t, proto.ErrCodeSuccess, reply.Code)
+
+	require.NoError(t, mc.AdminAPI().SetVolMetaOpQuota(commonVolName, proto.MetaOpQuota{}))
+	require.Nil(t, vol.getMetaOpQuota())
+}
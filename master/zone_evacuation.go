@@ -0,0 +1,319 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	ZoneEvacuationRunning  = "running"
+	ZoneEvacuationFinished = "finished"
+	ZoneEvacuationFailed   = "failed"
+	ZoneEvacuationAborted  = "aborted"
+
+	defaultEvacuateMpConcurrency = 3
+	zoneEvacuationRecoverCheck   = 10 * time.Second
+)
+
+// zoneEvacuation moves all the partitions out of a zone. The data partitions are moved by
+// the decommission of the data nodes, paced by the decommission tokens of the nodesets as
+// usual. The meta partitions are migrated to nodes of the other zones, mpConcurrency at a
+// time, each one waits for its new replica to recover before the next one starts. The zone is unavailable for new partitions meanwhile.
+// The progress is not persisted, evacuating the zone again after a leader change resumes it.
+type zoneEvacuation struct {
+	sync.RWMutex
+	zoneName       string
+	status         string
+	startTime      time.Time
+	endTime        time.Time
+	prevZoneStatus int
+	mpConcurrency  int
+	dataNodes      []string
+	metaNodes      []string
+	dpTotal        int
+	mpTotal        int
+	mpFailed       map[uint64]string
+	metaDone       bool
+	stopC          chan struct{}
+}
+
+func (e *zoneEvacuation) isStopped() bool {
+	select {
+	case <-e.stopC:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Cluster) getZoneEvacuation(zoneName string) (e *zoneEvacuation, err error) {
+	value, ok := c.zoneEvacuations.Load(zoneName)
+	if !ok {
+		return nil, fmt.Errorf("zone[%v] is not evacuated", zoneName)
+	}
+	return value.(*zoneEvacuation), nil
+}
+
+// zoneNodes returns the sorted addresses of the data and meta nodes of the zone.
+func zoneNodes(zone *Zone) (dataNodes, metaNodes []string) {
+	zone.dataNodes.Range(func(key, value interface{}) bool {
+		dataNodes = append(dataNodes, key.(string))
+		return true
+	})
+	zone.metaNodes.Range(func(key, value interface{}) bool {
+		metaNodes = append(metaNodes, key.(string))
+		return true
+	})
+	sort.Strings(dataNodes)
+	sort.Strings(metaNodes)
+	return
+}
+
+// zoneMetaPartitions returns the meta partitions with replicas on the nodes, and the
+// replicas to move of each one.
+func (c *Cluster) zoneMetaPartitions(metaNodes []string) (mps []*MetaPartition, hosts map[uint64][]string) {
+	hosts = make(map[uint64][]string)
+	for _, addr := range metaNodes {
+		for _, mp := range c.getAllMetaPartitionByMetaNode(addr) {
+			if _, ok := hosts[mp.PartitionID]; !ok {
+				mps = append(mps, mp)
+			}
+			hosts[mp.PartitionID] = append(hosts[mp.PartitionID], addr)
+		}
+	}
+	sort.Slice(mps, func(i, j int) bool { return mps[i].PartitionID < mps[j].PartitionID })
+	return
+}
+
+func (c *Cluster) evacuateZone(zoneName string, mpConcurrency, weight int) (view *proto.ZoneEvacuationView, err error) {
+	zone, err := c.t.getZone(zoneName)
+	if err != nil {
+		return
+	}
+	if value, ok := c.zoneEvacuations.Load(zoneName); ok {
+		old := value.(*zoneEvacuation)
+		old.RLock()
+		running := old.status == ZoneEvacuationRunning
+		old.RUnlock()
+		if running {
+			return nil, fmt.Errorf("zone[%v] is being evacuated", zoneName)
+		}
+	}
+	if c.ForbidMpDecommission {
+		return nil, fmt.Errorf("cluster mataPartition decommission switch is disabled")
+	}
+	if mpConcurrency <= 0 {
+		mpConcurrency = defaultEvacuateMpConcurrency
+	}
+
+	e := &zoneEvacuation{
+		zoneName:       zoneName,
+		status:         ZoneEvacuationRunning,
+		startTime:      time.Now(),
+		prevZoneStatus: zone.getStatus(),
+		mpConcurrency:  mpConcurrency,
+		mpFailed:       make(map[uint64]string),
+		stopC:          make(chan struct{}),
+	}
+	e.dataNodes, e.metaNodes = zoneNodes(zone)
+	for _, addr := range e.dataNodes {
+		e.dpTotal += len(c.getAllDataPartitionByDataNode(addr))
+	}
+	mps, _ := c.zoneMetaPartitions(e.metaNodes)
+	e.mpTotal = len(mps)
+
+	zone.setStatus(unavailableZone)
+	for _, addr := range e.dataNodes {
+		if err = c.migrateDataNode(addr, "", false, 0, weight); err != nil {
+			log.LogWarnf("action[evacuateZone] zone[%v] decommission data node[%v] failed: %v", zoneName, addr, err)
+			err = nil
+		}
+	}
+	c.zoneEvacuations.Store(zoneName, e)
+	go c.evacuateZoneMetaPartitions(e)
+	log.LogWarnf("action[evacuateZone] zone[%v] start, dataNodes[%v] dps[%v] metaNodes[%v] mps[%v] mpConcurrency[%v]",
+		zoneName, len(e.dataNodes), e.dpTotal, len(e.metaNodes), e.mpTotal, mpConcurrency)
+	return c.zoneEvacuationView(e), nil
+}
+
+func (c *Cluster) evacuateZoneMetaPartitions(e *zoneEvacuation) {
+	defer func() {
+		e.Lock()
+		e.metaDone = true
+		e.Unlock()
+	}()
+	mps, hosts := c.zoneMetaPartitions(e.metaNodes)
+	tokens := make(chan struct{}, e.mpConcurrency)
+	var wg sync.WaitGroup
+	for _, mp := range mps {
+		select {
+		case <-e.stopC:
+			wg.Wait()
+			return
+		case tokens <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(mp *MetaPartition, srcs []string) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			for _, src := range srcs {
+				if e.isStopped() {
+					return
+				}
+				if err := c.evacuateMetaReplica(e, mp, src); err != nil {
+					e.Lock()
+					e.mpFailed[mp.PartitionID] = err.Error()
+					e.Unlock()
+					log.LogWarnf("action[evacuateZoneMetaPartitions] zone[%v] mp[%v] move replica on [%v] failed: %v",
+						e.zoneName, mp.PartitionID, src, err)
+					return
+				}
+			}
+		}(mp, hosts[mp.PartitionID])
+	}
+	wg.Wait()
+}
+
+// evacuateMetaReplica moves the replica of mp on src to a meta node out of the zone and
+// waits for the new replica to recover.
+func (c *Cluster) evacuateMetaReplica(e *zoneEvacuation, mp *MetaPartition, src string) (err error) {
+	mp.RLock()
+	excludeHosts := append([]string{}, mp.Hosts...)
+	mp.RUnlock()
+	excludeHosts = c.excludeMetaHostsNotOfMediaType(excludeHosts, c.getMetaMediaTypeOfVol(mp.volName))
+	_, peers, err := c.getHostFromNormalZone(TypeMetaPartition, []string{e.zoneName}, nil, excludeHosts, 1, 1, "", proto.MediaType_Unspecified)
+	if err != nil {
+		return
+	}
+	if err = c.migrateMetaPartition(src, peers[0].Addr, mp); err != nil {
+		return
+	}
+	ticker := time.NewTicker(zoneEvacuationRecoverCheck)
+	defer ticker.Stop()
+	for {
+		mp.RLock()
+		recovering := mp.IsRecover
+		mp.RUnlock()
+		if !recovering {
+			return
+		}
+		select {
+		case <-e.stopC:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// abortZoneEvacuation stops migrating meta partitions, pauses the decommission of the data
+// nodes and restores the status of the zone. The partitions already moved are not moved back.
+func (c *Cluster) abortZoneEvacuation(zoneName string) (err error) {
+	e, err := c.getZoneEvacuation(zoneName)
+	if err != nil {
+		return
+	}
+	e.Lock()
+	if e.status != ZoneEvacuationRunning {
+		status := e.status
+		e.Unlock()
+		return fmt.Errorf("evacuation of zone[%v] is %v", zoneName, status)
+	}
+	e.status = ZoneEvacuationAborted
+	e.endTime = time.Now()
+	close(e.stopC)
+	e.Unlock()
+
+	for _, addr := range e.dataNodes {
+		dataNode, err1 := c.dataNode(addr)
+		if err1 != nil {
+			continue
+		}
+		if !dataNode.CanBePaused() {
+			continue
+		}
+		if err1, failed := c.decommissionDataNodePause(dataNode); err1 != nil || len(failed) != 0 {
+			log.LogWarnf("action[abortZoneEvacuation] zone[%v] pause data node[%v] err[%v] failed dps[%v]",
+				zoneName, addr, err1, failed)
+		}
+	}
+	if zone, err1 := c.t.getZone(zoneName); err1 == nil {
+		zone.setStatus(e.prevZoneStatus)
+	}
+	log.LogWarnf("action[abortZoneEvacuation] zone[%v] aborted", zoneName)
+	return nil
+}
+
+// zoneEvacuationView reports the partitions left on the nodes of the zone. Once nothing is
+// moving any more, the evacuation is finished if no partition is left, or failed otherwise.
+func (c *Cluster) zoneEvacuationView(e *zoneEvacuation) (view *proto.ZoneEvacuationView) {
+	view = &proto.ZoneEvacuationView{
+		Zone:      e.zoneName,
+		DataNodes: make([]*proto.ZoneEvacuationNode, 0, len(e.dataNodes)),
+		MetaNodes: make([]*proto.ZoneEvacuationNode, 0, len(e.metaNodes)),
+	}
+	dataMoving := false
+	for _, addr := range e.dataNodes {
+		node := &proto.ZoneEvacuationNode{Addr: addr, Remaining: len(c.getAllDataPartitionByDataNode(addr))}
+		if dataNode, err := c.dataNode(addr); err == nil {
+			status := dataNode.GetDecommissionStatus()
+			node.Status = GetDecommissionStatusMessage(status)
+			if status == markDecommission || status == DecommissionPrepare || status == DecommissionRunning {
+				dataMoving = true
+			}
+		}
+		view.DataPartitionRemaining += node.Remaining
+		view.DataNodes = append(view.DataNodes, node)
+	}
+	for _, addr := range e.metaNodes {
+		node := &proto.ZoneEvacuationNode{Addr: addr, Remaining: len(c.getAllMetaPartitionByMetaNode(addr))}
+		view.MetaNodes = append(view.MetaNodes, node)
+	}
+	mps, _ := c.zoneMetaPartitions(e.metaNodes)
+	view.MetaPartitionRemaining = len(mps)
+
+	e.Lock()
+	defer e.Unlock()
+	if e.status == ZoneEvacuationRunning && e.metaDone && !dataMoving {
+		e.status = ZoneEvacuationFinished
+		if view.DataPartitionRemaining != 0 || view.MetaPartitionRemaining != 0 {
+			e.status = ZoneEvacuationFailed
+		}
+		e.endTime = time.Now()
+		log.LogWarnf("action[zoneEvacuationView] zone[%v] evacuation %v, dps left[%v] mps left[%v]",
+			e.zoneName, e.status, view.DataPartitionRemaining, view.MetaPartitionRemaining)
+	}
+	view.Status = e.status
+	view.StartTime = e.startTime.Unix()
+	if !e.endTime.IsZero() {
+		view.EndTime = e.endTime.Unix()
+	}
+	view.DataPartitionTotal = e.dpTotal
+	view.MetaPartitionTotal = e.mpTotal
+	view.MetaPartitionConcurrency = e.mpConcurrency
+	view.MetaPartitionFailed = make(map[uint64]string, len(e.mpFailed))
+	for id, msg := range e.mpFailed {
+		view.MetaPartitionFailed[id] = msg
+	}
+	return
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZoneEvacuation(t *testing.T) {
+	c := server.cluster
+	_, err := c.evacuateZone("noSuchZone", 0, 0)
+	require.Error(t, err)
+	_, err = c.getZoneEvacuation("noSuchZone")
+	require.Error(t, err)
+	require.Error(t, c.abortZoneEvacuation("noSuchZone"))
+
+	zone, err := c.t.getZone(testZone1)
+	require.NoError(t, err)
+	dataNodes, metaNodes := zoneNodes(zone)
+	require.NotEmpty(t, dataNodes)
+	require.NotEmpty(t, metaNodes)
+	mps, hosts := c.zoneMetaPartitions(metaNodes)
+	for _, mp := range mps {
+		require.NotEmpty(t, hosts[mp.PartitionID])
+	}
+
+	// an evacuation without nodes is finished once the meta partitions are done
+	name := "evacuatedZone"
+	e := &zoneEvacuation{
+		zoneName:      name,
+		status:        ZoneEvacuationRunning,
+		startTime:     time.Now(),
+		mpConcurrency: defaultEvacuateMpConcurrency,
+		mpFailed:      make(map[uint64]string),
+		stopC:         make(chan struct{}),
+	}
+	c.zoneEvacuations.Store(name, e)
+	defer c.zoneEvacuations.Delete(name)
+	require.Equal(t, ZoneEvacuationRunning, c.zoneEvacuationView(e).Status)
+	c.evacuateZoneMetaPartitions(e)
+	view := c.zoneEvacuationView(e)
+	require.Equal(t, ZoneEvacuationFinished, view.Status)
+	require.NotZero(t, view.EndTime)
+	require.Error(t, c.abortZoneEvacuation(name))
+
+	e.status = ZoneEvacuationRunning
+	e.metaDone = false
+	require.NoError(t, c.abortZoneEvacuation(name))
+	require.True(t, e.isStopped())
+	require.Equal(t, ZoneEvacuationAborted, c.zoneEvacuationView(e).Status)
+}
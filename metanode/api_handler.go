@@ -25,6 +25,7 @@ import (
 	"path"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cubefs/cubefs/cmd/common"
@@ -95,9 +96,33 @@ func (m *MetaNode) registerAPIHandler() (err error) {
 	http.HandleFunc("/setGOGC", m.setGOGCHandler)
 	http.HandleFunc("/getGOGC", m.getGOGCHandler)
 	http.HandleFunc("/reloadMp", m.reloadMpHandler)
+	http.HandleFunc("/restartFailedMp", m.restartFailedMpHandler)
 	http.HandleFunc("/setQosEnable", m.setQosEnableHandler)
 	http.HandleFunc("/setMetaQos", m.setMetaQosHandler)
 	http.HandleFunc("/getMetaQos", m.getMetaQosHandler)
+	http.HandleFunc("/failOverLeader", m.failOverLeaderHandler)
+	http.HandleFunc("/getOrphanSnapshotDirs", m.getOrphanSnapshotDirsHandler)
+	http.HandleFunc("/getVolConfig", m.getVolConfigHandler)
+	http.HandleFunc("/reloadVolConfig", m.reloadVolConfigHandler)
+	http.HandleFunc("/setPurge", m.setPurgeHandler)
+	http.HandleFunc("/getPurge", m.getPurgeHandler)
+	http.HandleFunc("/rebuildFreeList", m.rebuildFreeListHandler)
+	http.HandleFunc("/getOpAudit", m.getOpAuditHandler)
+	http.HandleFunc("/getUniqChecker", m.getUniqCheckerHandler)
+	http.HandleFunc("/setUniqChecker", m.setUniqCheckerHandler)
+	http.HandleFunc("/getExtentFragmentation", m.getExtentFragmentationHandler)
+	http.HandleFunc("/setSnapshotSendRate", m.setSnapshotSendRateHandler)
+	http.HandleFunc("/getSnapshotSendRate", m.getSnapshotSendRateHandler)
+	http.HandleFunc("/getSnapshotRates", m.getSnapshotRatesHandler)
+	http.HandleFunc("/getStoreSchema", m.getStoreSchemaHandler)
+	http.HandleFunc("/getVolWorkerPools", m.getVolWorkerPoolsHandler)
+	http.HandleFunc("/getVolOpQuotas", m.getVolOpQuotasHandler)
+	http.HandleFunc("/getPriorityScheduler", m.getPrioritySchedulerHandler)
+	http.HandleFunc("/getApplyScheduler", m.getApplySchedulerHandler)
+	http.HandleFunc("/drain", m.drainHandler)
+	http.HandleFunc("/undrain", m.undrainHandler)
+	http.HandleFunc("/getDrainStatus", m.getDrainStatusHandler)
+	http.HandleFunc("/getTraces", m.getTracesHandler)
 	return
 }
 
@@ -169,6 +194,9 @@ func (m *MetaNode) getPartitionByIDHandler(w http.ResponseWriter, r *http.Reques
 	msg["peers"] = conf.Peers
 	msg["nodeId"] = conf.NodeId
 	msg["cursor"] = conf.Cursor
+	msg["proposal_stat"] = mp.GetProposalStat()
+	msg["apply_failure"] = mp.GetApplyFailure()
+	msg["store_schema"] = mp.GetStoreSchema()
 	resp.Data = msg
 	resp.Code = http.StatusOK
 	resp.Msg = http.StatusText(http.StatusOK)
@@ -1254,6 +1282,10 @@ func (m *MetaNode) setGOGCHandler(w http.ResponseWriter, r *http.Request) {
 		err = fmt.Errorf("metadataManager is nil")
 		return
 	}
+	if m.gcTuner.enabled() {
+		err = fmt.Errorf("GOGC is managed by adaptiveGOGCMemRatio")
+		return
+	}
 	m.metadataManager.(*metadataManager).useLocalGOGC = true
 	if m.metadataManager.(*metadataManager).gogcValue != gogcValue {
 		oldGOGC := m.metadataManager.(*metadataManager).gogcValue
@@ -1314,6 +1346,27 @@ func (m *MetaNode) reloadMpHandler(w http.ResponseWriter, r *http.Request) {
 	err = m.metadataManager.ReloadPartition(id)
 }
 
+func (m *MetaNode) restartFailedMpHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[restartFailedMpHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if err := m.metadataManager.RestartFailedPartition(pid.V); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
 func (m *MetaNode) setQosEnableHandler(w http.ResponseWriter, r *http.Request) {
 	const (
 		paramEnable = "enable"
@@ -1423,3 +1476,574 @@ func (m *MetaNode) getMetaQosHandler(w http.ResponseWriter, r *http.Request) {
 
 	resp.Data = metaQos
 }
+
+func (m *MetaNode) failOverLeaderHandler(w http.ResponseWriter, r *http.Request) {
+	const (
+		paramVolName = "vol"
+		paramPids    = "pids"
+		paramForce   = "force"
+	)
+	var err error
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		if err != nil {
+			resp.Msg = err.Error()
+			resp.Code = http.StatusBadRequest
+		}
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[failOverLeaderHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		err = fmt.Errorf("metadataManager is nil")
+		return
+	}
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+
+	pids := make([]uint64, 0)
+	if pidsStr := r.FormValue(paramPids); pidsStr != "" {
+		for _, v := range strings.Split(pidsStr, ",") {
+			var pid uint64
+			if pid, err = strconv.ParseUint(strings.TrimSpace(v), 10, 64); err != nil {
+				err = fmt.Errorf("parse param %v fail: %v", paramPids, err)
+				return
+			}
+			pids = append(pids, pid)
+		}
+	}
+	force := false
+	if forceStr := r.FormValue(paramForce); forceStr != "" {
+		if force, err = strconv.ParseBool(forceStr); err != nil {
+			err = fmt.Errorf("parse param %v fail: %v", paramForce, err)
+			return
+		}
+	}
+
+	transferred, err := m.metadataManager.FailOverLeaderMp(r.FormValue(paramVolName), pids, force)
+	if err != nil {
+		return
+	}
+	resp.Data = transferred
+}
+
+func (m *MetaNode) getOrphanSnapshotDirsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getOrphanSnapshotDirsHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.GetSnapshotJanitorStatus()
+}
+
+func (m *MetaNode) getVolConfigHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getVolConfigHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	p, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	mp, ok := p.(*metaPartition)
+	if !ok {
+		resp.Msg = fmt.Sprintf("unexpected partition type of %v", pid.V)
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = mp.GetVolConfig()
+}
+
+func (m *MetaNode) reloadVolConfigHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		if err != nil {
+			resp.Msg = err.Error()
+			resp.Code = http.StatusBadRequest
+		}
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[reloadVolConfigHandler] response %s", err)
+		}
+	}()
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	volName := r.FormValue("vol")
+	if volName == "" {
+		err = fmt.Errorf("param vol is required")
+		return
+	}
+	reloaded, err := m.metadataManager.ReloadVolConfig(volName)
+	if err != nil {
+		return
+	}
+	resp.Data = reloaded
+}
+
+// setPurgeHandler pauses, resumes or forces the purge of the inodes deleted of the partition by
+// the op, which is one of pause, resume and force.
+func (m *MetaNode) setPurgeHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[setPurgeHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	var op common.String
+	if err := parseArgs(r, pid.PID(), op.Key("op")); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	if err = mp.SetPurge(op.V); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = mp.GetPurgeStatus()
+}
+
+// rebuildFreeListHandler rebuilds the free lists of the replicas of the partition from the inodes
+// marked deleted, it is served by the leader.
+func (m *MetaNode) rebuildFreeListHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[rebuildFreeListHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	result, err := mp.RebuildFreeList()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = result
+}
+
+// getPurgeHandler returns the purge of the inodes deleted of the partition and the backlog of it.
+func (m *MetaNode) getPurgeHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getPurgeHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = mp.GetPurgeStatus()
+}
+
+// getOpAuditHandler returns the last lines of the op audit log of the partition on this node.
+func (m *MetaNode) getOpAuditHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getOpAuditHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	var lines common.Int
+	if err := parseArgs(r, pid.PID(), lines.Key("lines").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	n := int(lines.V)
+	if n <= 0 {
+		n = defaultOpAuditTailLines
+	}
+	if n > maxOpAuditTailLines {
+		n = maxOpAuditTailLines
+	}
+	p, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	mp, ok := p.(*metaPartition)
+	if !ok {
+		resp.Msg = fmt.Sprintf("unexpected partition type of %v", pid.V)
+		return
+	}
+	tail, err := mp.tailOpAudit(n)
+	if err != nil {
+		resp.Code = http.StatusInternalServerError
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = tail
+}
+
+// getUniqCheckerHandler returns the request dedup table of the partition on this node.
+func (m *MetaNode) getUniqCheckerHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getUniqCheckerHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.getMetaPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = mp.uniqChecker.stat()
+}
+
+// setUniqCheckerHandler tunes the request dedup window of the partition on this node until
+// it restarts. The leader decides the records to evict, so hot partitions should be tuned
+// on all the replicas to keep the window after the leader changes.
+func (m *MetaNode) setUniqCheckerHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[setUniqCheckerHandler] response %s", err)
+		}
+	}()
+	var (
+		pid      common.Uint
+		keepTime common.Int
+		keepOps  common.Int
+	)
+	if err := parseArgs(r, pid.PID(), keepTime.Key("keepTime").OmitEmpty(), keepOps.Key("keepOps").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if keepTime.V < 0 || keepOps.V < 0 || keepTime.V == 0 && keepOps.V == 0 {
+		resp.Msg = "keepTime or keepOps should be positive"
+		return
+	}
+	mp, err := m.getMetaPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	mp.uniqChecker.setKeep(keepTime.V, int(keepOps.V))
+	log.LogWarnf("[setUniqCheckerHandler] mp(%v) keepTime(%v) keepOps(%v)", pid.V, keepTime.V, keepOps.V)
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = mp.uniqChecker.stat()
+}
+
+func (m *MetaNode) getMetaPartition(pid uint64) (mp *metaPartition, err error) {
+	p, err := m.metadataManager.GetPartition(pid)
+	if err != nil {
+		return
+	}
+	mp, ok := p.(*metaPartition)
+	if !ok {
+		err = fmt.Errorf("unexpected partition type of %v", pid)
+	}
+	return
+}
+
+func (m *MetaNode) getExtentFragmentationHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getExtentFragmentationHandler] response %s", err)
+		}
+	}()
+	if err := r.ParseForm(); err != nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = err.Error()
+		return
+	}
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.GetExtentFragmentation(r.FormValue("vol"))
+}
+
+func (m *MetaNode) setSnapshotSendRateHandler(w http.ResponseWriter, r *http.Request) {
+	const (
+		paramRateMB = "rateMB"
+	)
+	var (
+		rateMB int
+		err    error
+	)
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		if err != nil {
+			resp.Msg = err.Error()
+			resp.Code = http.StatusBadRequest
+		} else {
+			resp.Data = "set snapshot send rate success"
+		}
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[setSnapshotSendRateHandler] response %s", err)
+		}
+	}()
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if rateMB, err = strconv.Atoi(r.FormValue(paramRateMB)); err != nil {
+		err = fmt.Errorf("parse param %v fail: %v", paramRateMB, err)
+		return
+	}
+	if rateMB < 0 {
+		err = fmt.Errorf("%v must not be negative", paramRateMB)
+		return
+	}
+	if m.metadataManager == nil {
+		err = fmt.Errorf("metadataManager is nil")
+		return
+	}
+	m.metadataManager.(*metadataManager).SetSnapshotSendRate(rateMB)
+	log.LogWarnf("[setSnapshotSendRate] snapshot send rate %v MB/s", rateMB)
+}
+
+func (m *MetaNode) getSnapshotSendRateHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		if err != nil {
+			resp.Msg = err.Error()
+			resp.Code = http.StatusBadRequest
+		}
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getSnapshotSendRateHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		err = fmt.Errorf("metadataManager is nil")
+		return
+	}
+	resp.Data = m.metadataManager.(*metadataManager).GetSnapshotSendRate()
+}
+
+func (m *MetaNode) getSnapshotRatesHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getSnapshotRatesHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.(*metadataManager).GetSnapshotRates()
+}
+
+func (m *MetaNode) getStoreSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getStoreSchemaHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.GetStoreSchemas()
+}
+
+func (m *MetaNode) getVolWorkerPoolsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getVolWorkerPoolsHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.(*metadataManager).GetVolWorkerPools()
+}
+
+func (m *MetaNode) getVolOpQuotasHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getVolOpQuotasHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.(*metadataManager).GetVolOpQuotas()
+}
+
+func (m *MetaNode) getPrioritySchedulerHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getPrioritySchedulerHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.(*metadataManager).GetPriorityScheduler()
+}
+
+func (m *MetaNode) getApplySchedulerHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getApplySchedulerHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	resp.Data = m.metadataManager.(*metadataManager).GetApplyScheduler()
+}
+
+func (m *MetaNode) drainHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[drainHandler] response %s", err)
+		}
+	}()
+	if m.metadataManager == nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = "metadataManager is nil"
+		return
+	}
+	m.startDrain()
+	resp.Data = m.getDrainStatus()
+}
+
+func (m *MetaNode) undrainHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[undrainHandler] response %s", err)
+		}
+	}()
+	m.stopDrain()
+	resp.Data = m.getDrainStatus()
+}
+
+func (m *MetaNode) getDrainStatusHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	resp.Data = m.getDrainStatus()
+	data, _ := resp.Marshal()
+	if _, err := w.Write(data); err != nil {
+		log.LogErrorf("[getDrainStatusHandler] response %s", err)
+	}
+}
+
+func (m *MetaNode) getTracesHandler(w http.ResponseWriter, r *http.Request) {
+	const paramTraceID = "traceId"
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getTracesHandler] response %s", err)
+		}
+	}()
+	var traceID uint64
+	if err := r.ParseForm(); err != nil {
+		resp.Code = http.StatusBadRequest
+		resp.Msg = err.Error()
+		return
+	}
+	if v := r.FormValue(paramTraceID); v != "" {
+		var err error
+		if traceID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			resp.Code = http.StatusBadRequest
+			resp.Msg = fmt.Sprintf("parse param %v fail: %v", paramTraceID, err)
+			return
+		}
+	}
+	resp.Data = m.tracer.Recent(traceID)
+}
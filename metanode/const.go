@@ -61,10 +61,12 @@ type (
 	ReadDirReq      = proto.ReadDirRequest
 	ReadDirOnlyReq  = proto.ReadDirOnlyRequest
 	ReadDirLimitReq = proto.ReadDirLimitRequest
+	ReadDirPlusReq  = proto.ReadDirPlusRequest
 	// MetaNode -> Client read dir response
 	ReadDirResp      = proto.ReadDirResponse
 	ReadDirOnlyResp  = proto.ReadDirOnlyResponse
 	ReadDirLimitResp = proto.ReadDirLimitResponse
+	ReadDirPlusResp  = proto.ReadDirPlusResponse
 
 	// MetaNode -> Client lookup
 	LookupReq = proto.LookupRequest
@@ -82,6 +84,8 @@ type (
 	UpdatePartitionResp = proto.UpdateMetaPartitionResponse
 	// Client -> MetaNode
 	ExtentsTruncateReq = proto.TruncateRequest
+	// Client -> MetaNode
+	ExtentsPreAllocReq = proto.ExtentsPreAllocRequest
 
 	// Client -> MetaNode
 	EvictInodeReq = proto.EvictInodeRequest
@@ -89,6 +93,20 @@ type (
 	BatchEvictInodeReq = proto.BatchEvictInodeRequest
 	// Client -> MetaNode
 	SetattrRequest = proto.SetAttrRequest
+	// Client -> MetaNode
+	UpdateLinkTargetRequest = proto.UpdateLinkTargetRequest
+	// Client -> MetaNode
+	BatchCreateDentryInodeReq = proto.BatchCreateDentryInodeRequest
+	// MetaNode -> Client
+	BatchCreateDentryInodeResp = proto.BatchCreateDentryInodeResponse
+	// Client -> MetaNode
+	DeleteSubtreeReq = proto.DeleteSubtreeRequest
+	// MetaNode -> Client
+	DeleteSubtreeResp = proto.DeleteSubtreeResponse
+	// Client -> MetaNode
+	DeleteSubtreeStatusReq = proto.DeleteSubtreeStatusRequest
+	// MetaNode Follower -> MetaNode Leader
+	SnapshotProgressReq = proto.SnapshotProgressRequest
 
 	// Client -> MetaNode
 	GetUniqIDResp = proto.GetUniqIDResponse
@@ -209,6 +227,60 @@ const (
 
 	// freeze meta partition
 	opFSMSetFreeze = 92
+
+	// create inode with the default xattrs of volume
+	opFSMCreateInodeWithXAttr = 93
+
+	opFSMUpdateLinkTarget = 94
+
+	// create inodes and dentries of a bulk import in one command
+	opFSMBatchCreateDentryInode = 95
+
+	// reserve the size of an inode before it is written
+	opFSMExtentsPreAlloc = 96
+
+	// sync the metadata of a replica volume from the snapshot of the source partition
+	opFSMReplicaSyncBegin = 97
+	opFSMReplicaSyncBatch = 98
+	opFSMReplicaSyncEnd   = 99
+
+	// merge the contiguous extent keys of a fragmented inode
+	opFSMMergeExtents = 100
+
+	// snapshot items since SnapFormatVersion_2, they cut the items into checksummed chunks
+	opFSMSnapResume = 101
+	opFSMSnapChunk  = 102
+
+	// set the link count of an inode recounted from the dentries of the volume by master
+	opFSMRepairNLink = 103
+
+	// the file locks kept in the extends of the inodes
+	opFSMFileLock       = 104
+	opFSMFileLockLease  = 105
+	opFSMFileLockExpire = 106
+
+	// set the stats of the directories recounted by the leader
+	opFSMRepairDirStat = 107
+
+	// rebuild the free list from the inodes marked deleted by the inode tree
+	opFSMRebuildFreeList = 108
+
+	// create the inode of a transaction with the default xattrs of volume
+	opFSMTxCreateInodeWithXAttr = 109
+
+	// append the extents rejected if they overlap other extents of the inode
+	opFSMExtentsAddRejectConflict = 110
+
+	// the staging of a running replica sync in the snapshots, followed by the staged items
+	opFSMReplicaSyncStaging     = 111
+	opFSMReplicaSyncStagingItem = 112
+
+	// append the extents under lww policy, the stale ones overlapping other extents are refused
+	opFSMExtentsAddLWW = 113
+
+	// append the extent with discard check under reject or lww policy
+	opFSMExtentsAddWithCheckRejectConflict = 114
+	opFSMExtentsAddWithCheckLWW            = 115
 )
 
 // new inode opCode
@@ -249,8 +321,45 @@ const (
 	cfgServiceIDKey              = "serviceIDKey"
 	cfgEnableGcTimer             = "enableGcTimer" // bool
 	CfgGcRecyclePercent          = "gcRecyclePercent"
-	cfsQosEnable                 = "qosEnable"   // bool
-	cfgReadDirIops               = "readDirIops" // int
+	cfsQosEnable                 = "qosEnable"                // bool
+	cfgReadDirIops               = "readDirIops"              // int
+	cfgClientOpWorkers           = "clientOpWorkers"          // int, max client ops handled concurrently, 0 is unlimited
+	cfgAdminOpWorkers            = "adminOpWorkers"           // int, max master admin tasks handled concurrently
+	cfgTraceSampleRate           = "traceSampleRate"          // float, ratio in [0, 1] of the client ops sampled here, the ops traced by the client are always traced
+	cfgMediaType                 = "mediaType"                // int, media type of the disk of metadataDir, 1 for ssd and 2 for hdd, unspecified if not set
+	cfgMemFreezeHighWatermark    = "memFreezeHighWatermark"   // float, ratio of totalMem to freeze the partitions, 0 disables it
+	cfgMemFreezeLowWatermark     = "memFreezeLowWatermark"    // float, ratio of totalMem to unfreeze the partitions
+	cfgAdaptiveGOGCMemRatio      = "adaptiveGOGCMemRatio"     // float, ratio of totalMem the heap target is kept under by GOGC, 0 disables it
+	cfgGCBallastMB               = "gcBallastMB"              // int, size in MB of the gc memory ballast, 0 disables it
+	cfgOpAuditDir                = "opAuditDir"               // string, dir of the op audit logs of volumes, default metadataDir/op_audit
+	cfgOpAuditLogMaxSize         = "opAuditLogMaxSize"        // int, bytes of an op audit log file before it is rotated
+	cfgLeaderTransferPerSec      = "leaderTransferPerSec"     // int, max leader transfers per second of failover
+	cfgFailOverWindow            = "failOverWindow"           // string, HH:MM-HH:MM, failover is allowed only in it
+	cfgOrphanSnapshotExpireSec   = "orphanSnapshotExpireSec"  // int, snapshot temp/backup dirs older than it are orphans
+	cfgRemoveOrphanSnapshotDirs  = "removeOrphanSnapshotDirs" // bool, remove orphan snapshot dirs, default true
+	cfgXAttrMaxCount             = "xattrMaxCount"            // int, max number of xattrs of an inode, 0 is unlimited
+	cfgXAttrMaxKeySize           = "xattrMaxKeySize"          // int, max bytes of a xattr key, 0 is unlimited
+	cfgXAttrMaxValueSize         = "xattrMaxValueSize"        // int, max bytes of a xattr value, 0 is unlimited
+	cfgXAttrMaxTotalSize         = "xattrMaxTotalSize"        // int, max bytes of all the xattrs of an inode, 0 is unlimited
+	cfgHbFullReportInterval      = "hbFullReportInterval"     // int, every Nth heartbeat reports all the partitions and the others only the changed ones, 1 disables delta reports
+	cfgExtentMergeThreshold      = "extentMergeThreshold"     // int, files with more extent keys than it are fragmented
+	cfgExtentMergeConcurrency    = "extentMergeConcurrency"   // int, partitions merging extents at the same time, 0 disables the merge
+	cfgSnapshotSendRateMB        = "snapshotSendRateMB"       // int, MB/s the snapshots are sent with by the node, 0 is unlimited
+	cfgSnapshotRecvRateMB        = "snapshotRecvRateMB"       // int, MB/s the snapshots are received with by the node, 0 is unlimited
+	cfgSnapshotPartRateMB        = "snapshotPartRateMB"       // int, MB/s the snapshot of a partition is sent or received with, 0 is unlimited
+	cfgPacketCompressCodec       = "packetCompressCodec"      // string, none, gzip or zstd, codec of the responses to the clients accepting it, default zstd
+	cfgPacketCompressThreshold   = "packetCompressThreshold"  // int, bytes of the response data it is compressed above
+	cfgVolWorkerPoolSize         = "volWorkerPoolSize"        // int, request workers of the node shared by the volumes by weight, 0 disables the pools
+	cfgPriorityWorkerPoolSize    = "priorityWorkerPoolSize"   // int, request workers of the node shared by the priority classes, 0 disables the scheduling
+	cfgInteractiveWeight         = "interactiveWeight"        // int, weight of the interactive requests to the background ones of weight 1, default 4
+	cfgApplyRateMB               = "applyRateMB"              // int, MB/s the raft logs are proposed, and the snapshots and the logs catching up applied with by the node, 0 is unlimited
+	cfgApplyRecoveryReserve      = "applyRecoveryReserve"     // int, percent of the apply rate reserved for the replicas recovering, default 20
+	cfgApplyRecoveryCap          = "applyRecoveryCap"         // int, percent of the apply rate the replicas recovering are capped to, default 50
+	cfgFollowerReadMaxLag        = "followerReadMaxLag"       // int, raft logs a follower may lag behind the leader to serve the follower reads, 0 is unbounded
+	cfgFollowerReadMaxLagMs      = "followerReadMaxLagMs"     // int, ms a follower may lag behind the leader to serve the follower reads, 0 is unbounded
+	cfgNsEventRingSize           = "nsEventRingSize"          // int, namespace events kept by each partition for the watchers, 0 disables them
+	cfgExtentDeleteBatchSize     = "extentDeleteBatchSize"    // int, max extents of a data partition sent to the datanode in a delete packet
+	cfgExtentDeleteFlushMs       = "extentDeleteFlushMs"      // int, ms the extents deleted are batched for before they are sent
 
 	metaNodeDeleteBatchCountKey = "batchCount"
 	configNameResolveInterval   = "nameResolveInterval" // int
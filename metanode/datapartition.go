@@ -50,7 +50,6 @@ func NewDataPartitionsView() *DataPartitionsView {
 type Vol struct {
 	sync.RWMutex
 	dataPartitionView map[uint64]*DataPartition
-	volDeleteLockTime int64
 	info              *proto.SimpleVolView
 }
 
@@ -0,0 +1,116 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the leaderships taken back by the partitions of a draining node are transferred again
+var drainCheckInterval = 10 * time.Second
+
+// nodeDrainer drains the node for a rolling upgrade. A draining node transfers the leaderships
+// of its partitions to their followers, and closes a client connection once the requests in
+// flight on it are answered, the packets from master are still handled. The node is drained
+// once it leads no partition and has no client request in flight, master is told by the
+// heartbeat that it is safe to restart.
+type nodeDrainer struct {
+	sync.Mutex
+	draining  int32
+	startTime time.Time
+	stopC     chan struct{}
+}
+
+// DrainStatus is the drain state of the node.
+type DrainStatus struct {
+	Draining  bool      `json:"draining"`
+	Drained   bool      `json:"drained"`
+	StartTime time.Time `json:"startTime"`
+	Leaders   int       `json:"leaders"`
+	Inflight  int64     `json:"inflight"`
+}
+
+func (m *MetaNode) isDraining() bool {
+	return atomic.LoadInt32(&m.drainer.draining) == 1
+}
+
+// drainRejects tells whether the packet read from a client connection is refused, the
+// connection is closed then for the client to retry on the other nodes.
+func (m *MetaNode) drainRejects(p *Packet) bool {
+	return m.isDraining() && !p.IsMasterOp()
+}
+
+// startDrain drains the node, it is a no-op if the node is already draining.
+func (m *MetaNode) startDrain() {
+	m.drainer.Lock()
+	defer m.drainer.Unlock()
+	if m.isDraining() {
+		return
+	}
+	m.drainer.startTime = time.Now()
+	m.drainer.stopC = make(chan struct{})
+	atomic.StoreInt32(&m.drainer.draining, 1)
+	log.LogWarnf("[startDrain] metanode(%v) starts draining", m.localAddr)
+	go m.transferLeadersWhileDraining(m.drainer.stopC)
+}
+
+// stopDrain resumes the node drained by startDrain.
+func (m *MetaNode) stopDrain() {
+	m.drainer.Lock()
+	defer m.drainer.Unlock()
+	if !m.isDraining() {
+		return
+	}
+	atomic.StoreInt32(&m.drainer.draining, 0)
+	close(m.drainer.stopC)
+	log.LogWarnf("[stopDrain] metanode(%v) stops draining, drained for %v",
+		m.localAddr, time.Since(m.drainer.startTime))
+}
+
+func (m *MetaNode) transferLeadersWhileDraining(stopC chan struct{}) {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	for {
+		if m.metadataManager != nil && len(m.metadataManager.GetLeaderPartitions()) > 0 {
+			if _, err := m.metadataManager.FailOverLeaderMp("", nil, true); err != nil {
+				log.LogWarnf("[transferLeadersWhileDraining] transfer leaders failed: %v", err)
+			}
+		}
+		select {
+		case <-stopC:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *MetaNode) getDrainStatus() (status DrainStatus) {
+	m.drainer.Lock()
+	status.Draining = m.isDraining()
+	status.StartTime = m.drainer.startTime
+	m.drainer.Unlock()
+	if m.metadataManager != nil {
+		status.Leaders = len(m.metadataManager.GetLeaderPartitions())
+	}
+	if m.clientLane != nil {
+		status.Inflight = m.clientLane.runningCount()
+	}
+	status.Drained = status.Draining && status.Leaders == 0 && status.Inflight == 0
+	return
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	m := &MetaNode{
+		clientLane: newOpLane(laneClient, 0),
+		adminLane:  newOpLane(laneAdmin, 0),
+		metadataManager: &metadataManager{
+			partitions:      make(map[uint64]MetaPartition),
+			failOverLimiter: newFailOverLimiter(0),
+		},
+	}
+	client, master := &Packet{}, &Packet{}
+	client.Opcode = proto.OpMetaCreateInode
+	master.Opcode = proto.OpMetaNodeHeartbeat
+	require.False(t, m.drainRejects(client))
+	require.False(t, m.getDrainStatus().Drained)
+
+	m.startDrain()
+	m.startDrain()
+	require.True(t, m.drainRejects(client))
+	require.False(t, m.drainRejects(master))
+
+	// a client request in flight is finished before the node is drained
+	release := m.clientLane.acquire()
+	status := m.getDrainStatus()
+	require.True(t, status.Draining)
+	require.False(t, status.Drained)
+	require.EqualValues(t, 1, status.Inflight)
+	release()
+	require.True(t, m.getDrainStatus().Drained)
+
+	m.stopDrain()
+	m.stopDrain()
+	require.False(t, m.drainRejects(client))
+	status = m.getDrainStatus()
+	require.False(t, status.Draining)
+	require.False(t, status.Drained)
+}
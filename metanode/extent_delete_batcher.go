@@ -0,0 +1,262 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/xtaci/smux"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultExtentDeleteBatchSize     = 1024
+	defaultExtentDeleteFlushInterval = 100 * time.Millisecond
+	extentDeleteRetryCount           = 3
+	extentDeleteRetryBackoff         = 200 * time.Millisecond
+	extentDeleteMaxRetryBackoff      = 5 * time.Second
+)
+
+// extentDeleter batches the extents deleted by the partitions of the node, nil sends them
+// with a stream each.
+var extentDeleter *extentDeleteBatcher
+
+type extentDeleteKey struct {
+	addr string // of the leader of the data partition
+	dpID uint64
+}
+
+type extentDeleteResult struct {
+	code uint8
+	err  error
+}
+
+// extentDeleteReq is the extents of a data partition deleted by a partition, it is told the
+// result of the batch carrying them.
+type extentDeleteReq struct {
+	dp   *DataPartition
+	exts []*proto.DelExtentParam
+	done chan extentDeleteResult
+}
+
+// extentDeleteSession sends the batches of a datanode over a stream of it.
+type extentDeleteSession interface {
+	send(dp *DataPartition, exts []*proto.DelExtentParam) (code uint8, err error)
+	close(failed bool)
+}
+
+// extentDeleteBatcher merges the extents deleted of a data partition by the partitions of the
+// node into the packets of up to batchSize extents. The batches are sent once full or every
+// interval, those of a datanode are sent in turn over a stream, and each is retried with
+// backoff before its failure is told to the partitions.
+type extentDeleteBatcher struct {
+	sync.Mutex
+	batchSize  int
+	interval   time.Duration
+	pending    map[extentDeleteKey][]*extentDeleteReq
+	sending    map[string]bool // datanodes sending their batches
+	backlog    int64           // extents queued or being sent
+	kickC      chan struct{}
+	stopC      chan struct{}
+	newSession func(addr string) extentDeleteSession
+}
+
+func newExtentDeleteBatcher(batchSize int, interval time.Duration) *extentDeleteBatcher {
+	if batchSize <= 0 {
+		batchSize = defaultExtentDeleteBatchSize
+	}
+	if interval <= 0 {
+		interval = defaultExtentDeleteFlushInterval
+	}
+	b := &extentDeleteBatcher{
+		batchSize:  batchSize,
+		interval:   interval,
+		pending:    make(map[extentDeleteKey][]*extentDeleteReq),
+		sending:    make(map[string]bool),
+		kickC:      make(chan struct{}, 1),
+		stopC:      make(chan struct{}),
+		newSession: newSmuxExtentDeleteSession,
+	}
+	go b.run()
+	return b
+}
+
+func (b *extentDeleteBatcher) stop() {
+	close(b.stopC)
+}
+
+// Backlog returns the extents queued or being sent to the datanodes.
+func (b *extentDeleteBatcher) Backlog() int64 {
+	return atomic.LoadInt64(&b.backlog)
+}
+
+// delete deletes the extents of the data partition and waits for the result of the batch.
+func (b *extentDeleteBatcher) delete(dp *DataPartition, exts []*proto.DelExtentParam) (code uint8, err error) {
+	req := &extentDeleteReq{dp: dp, exts: exts, done: make(chan extentDeleteResult, 1)}
+	key := extentDeleteKey{addr: util.ShiftAddrPort(dp.Hosts[0], smuxPortShift), dpID: dp.PartitionID}
+	b.Lock()
+	b.pending[key] = append(b.pending[key], req)
+	full := extentDeleteCount(b.pending[key]) >= b.batchSize
+	b.Unlock()
+	atomic.AddInt64(&b.backlog, int64(len(exts)))
+	if full {
+		b.kick()
+	}
+	select {
+	case res := <-req.done:
+		return res.code, res.err
+	case <-b.stopC:
+		return 0, fmt.Errorf("extent delete batcher is stopped")
+	}
+}
+
+func (b *extentDeleteBatcher) kick() {
+	select {
+	case b.kickC <- struct{}{}:
+	default:
+	}
+}
+
+func (b *extentDeleteBatcher) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stopC:
+			return
+		case <-ticker.C:
+			b.flush(false)
+		case <-b.kickC:
+			b.flush(true)
+		}
+	}
+}
+
+// flush sends the batches of the datanodes not sending, the full ones only if fullOnly.
+func (b *extentDeleteBatcher) flush(fullOnly bool) {
+	b.Lock()
+	defer b.Unlock()
+	batches := make(map[string]map[extentDeleteKey][]*extentDeleteReq)
+	for key, reqs := range b.pending {
+		if b.sending[key.addr] || (fullOnly && extentDeleteCount(reqs) < b.batchSize) {
+			continue
+		}
+		if batches[key.addr] == nil {
+			batches[key.addr] = make(map[extentDeleteKey][]*extentDeleteReq)
+		}
+		batches[key.addr][key] = reqs
+		delete(b.pending, key)
+	}
+	for addr, dpBatches := range batches {
+		b.sending[addr] = true
+		go b.send(addr, dpBatches)
+	}
+}
+
+func (b *extentDeleteBatcher) send(addr string, batches map[extentDeleteKey][]*extentDeleteReq) {
+	session := b.newSession(addr)
+	for _, reqs := range batches {
+		exts := make([]*proto.DelExtentParam, 0, extentDeleteCount(reqs))
+		for _, req := range reqs {
+			exts = append(exts, req.exts...)
+		}
+		var res extentDeleteResult
+		for i := 0; i < len(exts) && res.err == nil; i += b.batchSize {
+			end := i + b.batchSize
+			if end > len(exts) {
+				end = len(exts)
+			}
+			res, session = b.sendWithRetry(addr, session, reqs[0].dp, exts[i:end])
+		}
+		atomic.AddInt64(&b.backlog, -int64(len(exts)))
+		for _, req := range reqs {
+			req.done <- res
+		}
+	}
+	session.close(false)
+
+	b.Lock()
+	delete(b.sending, addr)
+	b.Unlock()
+	b.kick()
+}
+
+// sendWithRetry sends the batch until it succeeds or the retries are used up, the session is
+// renewed after each failure.
+func (b *extentDeleteBatcher) sendWithRetry(addr string, session extentDeleteSession, dp *DataPartition,
+	exts []*proto.DelExtentParam,
+) (res extentDeleteResult, _ extentDeleteSession) {
+	backoff := extentDeleteRetryBackoff
+	for i := 0; ; i++ {
+		res.code, res.err = session.send(dp, exts)
+		if res.err == nil || res.code == proto.OpTryOtherAddr {
+			return res, session
+		}
+		session.close(true)
+		session = b.newSession(addr)
+		if i >= extentDeleteRetryCount {
+			return res, session
+		}
+		log.LogWarnf("[extentDeleteBatcher] datanode(%v) dp(%v) delete %v extents failed, retry %v after %v, err(%v)",
+			addr, dp.PartitionID, len(exts), i+1, backoff, res.err)
+		select {
+		case <-b.stopC:
+			return res, session
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > extentDeleteMaxRetryBackoff {
+			backoff = extentDeleteMaxRetryBackoff
+		}
+	}
+}
+
+func extentDeleteCount(reqs []*extentDeleteReq) (cnt int) {
+	for _, req := range reqs {
+		cnt += len(req.exts)
+	}
+	return
+}
+
+type smuxExtentDeleteSession struct {
+	addr string
+	conn *smux.Stream
+}
+
+func newSmuxExtentDeleteSession(addr string) extentDeleteSession {
+	return &smuxExtentDeleteSession{addr: addr}
+}
+
+func (s *smuxExtentDeleteSession) send(dp *DataPartition, exts []*proto.DelExtentParam) (code uint8, err error) {
+	if s.conn == nil {
+		if s.conn, err = smuxPool.GetConnect(s.addr); err != nil {
+			s.conn = nil
+			return 0, errors.NewErrorf("get conn from pool %s, extents partitionId=%d", err.Error(), dp.PartitionID)
+		}
+	}
+	return sendBatchDeleteExtents(s.conn, dp, exts)
+}
+
+func (s *smuxExtentDeleteSession) close(failed bool) {
+	if s.conn != nil {
+		smuxPool.PutConnect(s.conn, failed)
+		s.conn = nil
+	}
+}
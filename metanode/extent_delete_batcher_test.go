@@ -0,0 +1,115 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExtentDeleteNode struct {
+	sync.Mutex
+	sessions int
+	packets  map[uint64][]int // extents of the packets sent to each dp
+	failures map[uint64]int   // packets of each dp to fail
+	code     uint8
+}
+
+type fakeExtentDeleteSession struct {
+	node *fakeExtentDeleteNode
+}
+
+func (s *fakeExtentDeleteSession) send(dp *DataPartition, exts []*proto.DelExtentParam) (uint8, error) {
+	n := s.node
+	n.Lock()
+	defer n.Unlock()
+	if n.failures[dp.PartitionID] > 0 {
+		n.failures[dp.PartitionID]--
+		return n.code, fmt.Errorf("dp %v failed", dp.PartitionID)
+	}
+	n.packets[dp.PartitionID] = append(n.packets[dp.PartitionID], len(exts))
+	return proto.OpOk, nil
+}
+
+func (s *fakeExtentDeleteSession) close(failed bool) {}
+
+func newTestExtentDeleteParams(dpID uint64, cnt int) []*proto.DelExtentParam {
+	exts := make([]*proto.DelExtentParam, 0, cnt)
+	for i := 0; i < cnt; i++ {
+		exts = append(exts, &proto.DelExtentParam{ExtentKey: &proto.ExtentKey{PartitionId: dpID, ExtentId: uint64(i + 1)}})
+	}
+	return exts
+}
+
+func TestExtentDeleteBatcher(t *testing.T) {
+	node := &fakeExtentDeleteNode{packets: make(map[uint64][]int), failures: make(map[uint64]int)}
+	b := newExtentDeleteBatcher(4, 50*time.Millisecond)
+	defer b.stop()
+	b.newSession = func(addr string) extentDeleteSession {
+		node.Lock()
+		node.sessions++
+		node.Unlock()
+		return &fakeExtentDeleteSession{node: node}
+	}
+	dp1 := &DataPartition{PartitionID: 1, Hosts: []string{"127.0.0.1:17310"}}
+	dp2 := &DataPartition{PartitionID: 2, Hosts: []string{"127.0.0.1:17310"}}
+
+	// the extents of a dp deleted by the partitions are merged and split by the batch size
+	var wg sync.WaitGroup
+	for _, req := range []struct {
+		dp  *DataPartition
+		cnt int
+	}{{dp1, 2}, {dp1, 3}, {dp2, 1}} {
+		wg.Add(1)
+		go func(dp *DataPartition, cnt int) {
+			defer wg.Done()
+			_, err := b.delete(dp, newTestExtentDeleteParams(dp.PartitionID, cnt))
+			require.NoError(t, err)
+		}(req.dp, req.cnt)
+	}
+	wg.Wait()
+	require.Zero(t, b.Backlog())
+	node.Lock()
+	sent := 0
+	for _, cnt := range node.packets[1] {
+		sent += cnt
+	}
+	require.Equal(t, 5, sent)
+	require.LessOrEqual(t, len(node.packets[1]), 2)
+	require.Equal(t, []int{1}, node.packets[2])
+	require.LessOrEqual(t, node.sessions, 2)
+	node.failures[1] = 1
+	node.code = proto.OpErr
+	node.Unlock()
+
+	// a failure is retried with a new session
+	_, err := b.delete(dp1, newTestExtentDeleteParams(1, 1))
+	require.NoError(t, err)
+
+	// the dp deleted of a cold vol is not retried
+	node.Lock()
+	node.failures[2] = 1
+	node.code = proto.OpTryOtherAddr
+	node.Unlock()
+	code, err := b.delete(dp2, newTestExtentDeleteParams(2, 1))
+	require.Error(t, err)
+	require.Equal(t, proto.OpTryOtherAddr, code)
+	require.Zero(t, b.Backlog())
+}
@@ -65,6 +65,25 @@ func (fl *freeList) Remove(ino uint64) {
 	}
 }
 
+// Has returns if the inode is on the list.
+func (fl *freeList) Has(ino uint64) bool {
+	fl.Lock()
+	defer fl.Unlock()
+	_, ok := fl.index[ino]
+	return ok
+}
+
+// Inodes returns the inodes on the list.
+func (fl *freeList) Inodes() (inodes []uint64) {
+	fl.Lock()
+	defer fl.Unlock()
+	inodes = make([]uint64, 0, len(fl.index))
+	for ino := range fl.index {
+		inodes = append(inodes, ino)
+	}
+	return
+}
+
 func (fl *freeList) Len() int {
 	fl.Lock()
 	defer fl.Unlock()
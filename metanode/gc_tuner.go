@@ -0,0 +1,151 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const gcTunerInterval = 10 * time.Second
+
+var gcPauseQuantiles = []struct {
+	label string
+	q     float64
+}{
+	{"p50", 0.5},
+	{"p90", 0.9},
+	{"p99", 0.99},
+	{"max", 1},
+}
+
+// gcTuner adapts GOGC to the memory left to the partitions: the heap of the node is mostly
+// the inode and dentry trees, so the GC target is kept under memRatio of configTotalMem and
+// GC runs more often as the partitions grow. The ballast raises the heap target of a small
+// heap without being resident, it replaces GOMEMLIMIT which is not available in go1.18.
+type gcTuner struct {
+	memRatio    float64
+	ballastSize uint64
+	ballast     []byte
+	stopC       chan struct{}
+}
+
+func newGCTuner(memRatio float64, ballastSize uint64) *gcTuner {
+	return &gcTuner{memRatio: memRatio, ballastSize: ballastSize, stopC: make(chan struct{})}
+}
+
+func (t *gcTuner) enabled() bool {
+	return t != nil && t.memRatio > 0
+}
+
+// adaptiveGOGC returns the GOGC which keeps the next heap target of heapAlloc, ballast not
+// included, under limit. The ballast counts in the heap target but is never resident.
+func adaptiveGOGC(heapAlloc, ballast, limit uint64) int {
+	if heapAlloc <= ballast || limit == 0 {
+		return defaultGOGCUpperLimit
+	}
+	live := heapAlloc - ballast
+	if live >= limit {
+		return defaultGOGCLowerLimit
+	}
+	gogc := int((float64(limit+ballast)/float64(heapAlloc) - 1) * 100)
+	if gogc < defaultGOGCLowerLimit {
+		return defaultGOGCLowerLimit
+	}
+	if gogc > defaultGOGCUpperLimit {
+		return defaultGOGCUpperLimit
+	}
+	return gogc
+}
+
+// pauseQuantile returns the q quantile of the sorted pauses.
+func pauseQuantile(sorted []uint64, q float64) uint64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// recentGCPauses returns the sorted pauses of the last GC cycles kept by the runtime.
+func recentGCPauses(stats *runtime.MemStats) []uint64 {
+	n := int(stats.NumGC)
+	if n > len(stats.PauseNs) {
+		n = len(stats.PauseNs)
+	}
+	pauses := make([]uint64, n)
+	copy(pauses, stats.PauseNs[:n])
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i] < pauses[j] })
+	return pauses
+}
+
+func (m *MetaNode) startGCTuner() {
+	if m.gcTuner.ballastSize > 0 {
+		m.gcTuner.ballast = make([]byte, m.gcTuner.ballastSize)
+		log.LogInfof("[startGCTuner] allocate gc ballast of %v", m.gcTuner.ballastSize)
+	}
+	if !m.gcTuner.enabled() {
+		log.LogInfo("[startGCTuner] adaptive GOGC is disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(gcTunerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.gcTuner.stopC:
+				log.LogInfo("[startGCTuner] stopped")
+				return
+			case <-ticker.C:
+				manager, ok := m.metadataManager.(*metadataManager)
+				if !ok {
+					continue
+				}
+				var stats runtime.MemStats
+				runtime.ReadMemStats(&stats)
+				limit := uint64(float64(configTotalMem) * m.gcTuner.memRatio)
+				gogc := adaptiveGOGC(stats.HeapAlloc, m.gcTuner.ballastSize, limit)
+				if gogc == manager.gogcValue {
+					continue
+				}
+				oldGOGC := manager.gogcValue
+				debug.SetGCPercent(gogc)
+				manager.gogcValue = gogc
+				log.LogWarnf("[startGCTuner] change GOGC, old(%v) new(%v) heapAlloc(%v) limit(%v)",
+					oldGOGC, gogc, stats.HeapAlloc, limit)
+			}
+		}
+	}()
+}
+
+func (m *MetaNode) stopGCTuner() {
+	if m.gcTuner == nil {
+		return
+	}
+	if m.gcTuner.enabled() {
+		close(m.gcTuner.stopC)
+	}
+	m.gcTuner.ballast = nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveGOGC(t *testing.T) {
+	require.Equal(t, defaultGOGCUpperLimit, adaptiveGOGC(10, 0, 100))
+	require.Equal(t, 60, adaptiveGOGC(50, 0, 80))
+	require.Equal(t, defaultGOGCLowerLimit, adaptiveGOGC(70, 0, 80))
+	require.Equal(t, defaultGOGCLowerLimit, adaptiveGOGC(90, 0, 80))
+	// the ballast is part of the heap target but not of the limit
+	require.Equal(t, 30, adaptiveGOGC(100, 50, 80))
+	require.Equal(t, defaultGOGCUpperLimit, adaptiveGOGC(50, 50, 80))
+	require.Equal(t, defaultGOGCUpperLimit, adaptiveGOGC(50, 0, 0))
+
+	require.False(t, (*gcTuner)(nil).enabled())
+	require.False(t, newGCTuner(0, 0).enabled())
+	require.True(t, newGCTuner(0.7, 0).enabled())
+}
+
+func TestGCPauseQuantile(t *testing.T) {
+	require.EqualValues(t, 0, pauseQuantile(nil, 0.5))
+
+	stats := &runtime.MemStats{NumGC: 4}
+	copy(stats.PauseNs[:], []uint64{40, 10, 30, 20, 99})
+	pauses := recentGCPauses(stats)
+	require.Equal(t, []uint64{10, 20, 30, 40}, pauses)
+	require.EqualValues(t, 20, pauseQuantile(pauses, 0.5))
+	require.EqualValues(t, 40, pauseQuantile(pauses, 0.99))
+	require.EqualValues(t, 40, pauseQuantile(pauses, 1))
+	require.EqualValues(t, 10, pauseQuantile(pauses, 0))
+}
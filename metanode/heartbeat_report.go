@@ -0,0 +1,88 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+const defaultHeartbeatFullReportInterval = 10
+
+// heartbeatReporter makes the meta partition reports of the heartbeats. If master accepts
+// delta reports and holds the reports last sent, only the partitions changed since them
+// are reported, and a full report is sent every fullInterval heartbeats to correct the drift.
+type heartbeatReporter struct {
+	sync.Mutex
+	fullInterval int
+	seq          uint64
+	deltas       int // delta reports sent since the last full one
+	reports      map[uint64]*proto.MetaPartitionReport
+}
+
+func newHeartbeatReporter(fullInterval int) *heartbeatReporter {
+	if fullInterval <= 0 {
+		fullInterval = defaultHeartbeatFullReportInterval
+	}
+	// seq starts from the time so that master never takes the reports of a restarted node
+	// as the base of its delta reports
+	return &heartbeatReporter{
+		fullInterval: fullInterval,
+		seq:          uint64(time.Now().UnixNano()),
+	}
+}
+
+func (r *heartbeatReporter) report(req *proto.HeartBeatRequest, resp *proto.MetaNodeHeartbeatResponse,
+	reports []*proto.MetaPartitionReport,
+) {
+	if r == nil {
+		resp.MetaPartitionReports = reports
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	last := r.reports
+	r.reports = make(map[uint64]*proto.MetaPartitionReport, len(reports))
+	for _, mpr := range reports {
+		r.reports[mpr.PartitionID] = mpr
+	}
+	delta := req.MetaDeltaReport && last != nil && req.MetaReportSeq == r.seq && r.deltas+1 < r.fullInterval
+	resp.BaseReportSeq = r.seq
+	r.seq++
+	resp.ReportSeq = r.seq
+	if !delta {
+		r.deltas = 0
+		resp.BaseReportSeq = 0
+		resp.MetaPartitionReports = reports
+		return
+	}
+
+	r.deltas++
+	resp.DeltaReport = true
+	resp.MetaPartitionReports = make([]*proto.MetaPartitionReport, 0)
+	for _, mpr := range reports {
+		if old, ok := last[mpr.PartitionID]; !ok || !reflect.DeepEqual(old, mpr) {
+			resp.MetaPartitionReports = append(resp.MetaPartitionReports, mpr)
+		}
+	}
+	for id := range last {
+		if _, ok := r.reports[id]; !ok {
+			resp.RemovedPartitions = append(resp.RemovedPartitions, id)
+		}
+	}
+}
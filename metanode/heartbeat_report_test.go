@@ -0,0 +1,69 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatDeltaReport(t *testing.T) {
+	r := newHeartbeatReporter(3)
+	reports := func(inodes ...uint64) (mprs []*proto.MetaPartitionReport) {
+		for i, cnt := range inodes {
+			mprs = append(mprs, &proto.MetaPartitionReport{PartitionID: uint64(i + 1), InodeCnt: cnt})
+		}
+		return
+	}
+	beat := func(req *proto.HeartBeatRequest, mprs []*proto.MetaPartitionReport) *proto.MetaNodeHeartbeatResponse {
+		resp := &proto.MetaNodeHeartbeatResponse{}
+		r.report(req, resp, mprs)
+		return resp
+	}
+
+	// the first report and the reports to an old master are full ones
+	resp := beat(&proto.HeartBeatRequest{MetaDeltaReport: true}, reports(1, 1, 1))
+	require.False(t, resp.DeltaReport)
+	require.Len(t, resp.MetaPartitionReports, 3)
+	resp = beat(&proto.HeartBeatRequest{MetaReportSeq: resp.ReportSeq}, reports(1, 1, 1))
+	require.False(t, resp.DeltaReport)
+
+	req := &proto.HeartBeatRequest{MetaDeltaReport: true, MetaReportSeq: resp.ReportSeq}
+	resp = beat(req, reports(1, 2))
+	require.True(t, resp.DeltaReport)
+	require.Equal(t, req.MetaReportSeq, resp.BaseReportSeq)
+	require.Len(t, resp.MetaPartitionReports, 1)
+	require.EqualValues(t, 2, resp.MetaPartitionReports[0].PartitionID)
+	require.Equal(t, []uint64{3}, resp.RemovedPartitions)
+
+	// a full report every 3 heartbeats
+	resp = beat(&proto.HeartBeatRequest{MetaDeltaReport: true, MetaReportSeq: resp.ReportSeq}, reports(1, 2))
+	require.True(t, resp.DeltaReport)
+	require.Empty(t, resp.MetaPartitionReports)
+	resp = beat(&proto.HeartBeatRequest{MetaDeltaReport: true, MetaReportSeq: resp.ReportSeq}, reports(1, 2))
+	require.False(t, resp.DeltaReport)
+	require.Len(t, resp.MetaPartitionReports, 2)
+
+	// master missed the last report
+	resp = beat(&proto.HeartBeatRequest{MetaDeltaReport: true, MetaReportSeq: resp.ReportSeq - 1}, reports(1, 2))
+	require.False(t, resp.DeltaReport)
+
+	r = newHeartbeatReporter(1)
+	resp = beat(&proto.HeartBeatRequest{MetaDeltaReport: true}, reports(1))
+	resp = beat(&proto.HeartBeatRequest{MetaDeltaReport: true, MetaReportSeq: resp.ReportSeq}, reports(1))
+	require.False(t, resp.DeltaReport)
+}
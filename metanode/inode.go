@@ -38,6 +38,7 @@ const (
 	DeleteMarkFlag               = 1 << 0
 	InodeDelTop                  = 1 << 1
 	DeleteMigrationExtentKeyFlag = 1 << 2 // only delete migration ek by delay
+	InodePreAllocFlag            = 1 << 3 // size reserved by fallocate, maybe beyond the written extents
 )
 
 const (
@@ -2413,6 +2414,14 @@ func (i *Inode) NeedDeleteMigrationExtentKey() bool {
 	return i.Flag&DeleteMigrationExtentKeyFlag == DeleteMigrationExtentKeyFlag
 }
 
+// IsPreAllocated returns if the size of the inode has been reserved by fallocate.
+func (i *Inode) IsPreAllocated() (ok bool) {
+	i.RLock()
+	ok = i.Flag&InodePreAllocFlag == InodePreAllocFlag
+	i.RUnlock()
+	return
+}
+
 // ShouldDelete returns if the inode has been marked as deleted.
 func (i *Inode) ShouldDelete() (ok bool) {
 	i.RLock()
@@ -69,7 +69,13 @@ type MetadataManager interface {
 	GetAllVolumes() (volumes *util.Set)
 	checkVolVerList() (err error)
 	ReloadPartition(id int) (err error)
+	RestartFailedPartition(id uint64) (err error)
 	UpdateQosLimit()
+	FailOverLeaderMp(volName string, pids []uint64, force bool) (transferred []uint64, err error)
+	GetSnapshotJanitorStatus() *SnapshotJanitorStatus
+	GetExtentFragmentation(volName string) *ExtentFragmentation
+	GetStoreSchemas() []StoreSchemaStatus
+	ReloadVolConfig(volName string) (reloaded []uint64, err error)
 }
 
 // MetadataManagerConfig defines the configures in the metadata manager.
@@ -80,6 +86,39 @@ type MetadataManagerConfig struct {
 	EnableGcTimer    bool
 	GcRecyclePercent float64
 	RaftStore        raftstore.RaftStore
+
+	LeaderTransferPerSec int
+	FailOverWindow       *failOverWindow
+
+	OrphanSnapshotExpire     time.Duration
+	RemoveOrphanSnapshotDirs bool
+	XAttrLimit               proto.XAttrLimit
+	OpAuditDir               string
+	OpAuditLogMaxSize        int64
+
+	HeartbeatFullReportInterval int
+
+	ExtentMergeThreshold   int
+	ExtentMergeConcurrency int
+
+	SnapshotSendRateMB      int
+	SnapshotRecvRateMB      int
+	SnapshotPartitionRateMB int
+
+	PacketCompressCodec     uint8
+	PacketCompressThreshold int
+
+	VolWorkerPoolSize int
+
+	PriorityWorkerPoolSize    int
+	InteractivePriorityWeight int
+
+	ApplyRateMB          int
+	ApplyRecoveryReserve int
+	ApplyRecoveryCap     int
+
+	FollowerReadMaxLag   uint64
+	FollowerReadMaxLagMs int64
 }
 
 type verOp2Phase struct {
@@ -114,6 +153,26 @@ type metadataManager struct {
 	gcRecyclePercent     float64
 	gcTimer              *util.RecycleTimer
 	limitFactor          map[uint32]*rate.Limiter
+	failOverLimiter      *rate.Limiter
+	failOverWindow       *failOverWindow
+	snapshotJanitor      snapshotJanitor
+	xattrLimit           proto.XAttrLimit // default xattr limit of volumes
+	opAuditDir           string
+	opAuditLogMaxSize    int64
+	hbReporter           *heartbeatReporter
+	extentMerger         extentMerger
+	snapSendLimiter      *rate.Limiter // of the bandwidth the snapshots are sent with
+	snapRecvLimiter      *rate.Limiter // of the bandwidth the snapshots are received with
+	snapRates            snapshotRates // of the limiters, set on the node and by master
+	storeSchemaUpgrading sync.Map      // partition id -> *metaPartition upgrading its snapshot schema
+	packetCompress       packetCompress
+	volWorkers           *volWorkerPools    // of the requests of each volume
+	priorities           *priorityScheduler // of the requests of each priority class
+	applies              *applyScheduler    // of the raft applies of each class
+	metaAuth             metaAuth           // of the vols enforcing the meta auth
+	volWriteStates       volWriteStates     // of the vols read-only or frozen
+	opQuotas             *volOpQuotas       // of the meta op rates of each volume
+	followerReads        followerReadBound  // of the lag the followers serve the reads within
 }
 
 func (m *metadataManager) GetAllVolumes() (volumes *util.Set) {
@@ -235,6 +294,33 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 
 	metric := exporter.NewTPCnt(p.GetOpMsg())
 	labels := m.getPacketLabels(p)
+	scheduled := !p.AdminOp() && !p.IsMasterOp() && !isLongPollOp(p.Opcode)
+	if vol := labels[exporter.Vol]; vol != "" && scheduled {
+		if err = m.opQuotas.allow(vol, p.Opcode, p.Data); err != nil {
+			log.LogWarnf("HandleMetadataOperation (%s), vol(%v), remote %s, err %s", p.String(), vol, remoteAddr, err.Error())
+			p.PacketErrorWithBody(proto.OpMetaOpRateLimited, []byte(err.Error()))
+			m.respondToClient(conn, p)
+			return
+		}
+		var release func()
+		if release, err = m.volWorkers.acquire(vol); err != nil {
+			log.LogWarnf("HandleMetadataOperation (%s), vol(%v), remote %s, err %s", p.String(), vol, remoteAddr, err.Error())
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			m.respondToClient(conn, p)
+			return
+		}
+		defer release()
+	}
+	if scheduled {
+		var release func()
+		if release, err = m.priorities.acquire(p.GetPriority()); err != nil {
+			log.LogWarnf("HandleMetadataOperation (%s), priority(%v), remote %s, err %s", p.String(), p.GetPriority(), remoteAddr, err.Error())
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			m.respondToClient(conn, p)
+			return
+		}
+		defer release()
+	}
 	defer func() {
 		metric.SetWithLabels(err, labels)
 		if err != nil {
@@ -251,6 +337,14 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		}
 	}()
 
+	if mp, inodes := m.attrLeasesChanged(p); len(inodes) > 0 {
+		defer func() {
+			if p.ResultCode == proto.OpOk {
+				mp.invalidateAttrLeases(inodes)
+			}
+		}()
+	}
+
 	switch p.Opcode {
 	case proto.OpMetaCreateInode:
 		err = m.opCreateInode(conn, p, remoteAddr)
@@ -270,12 +364,24 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opBatchMetaEvictInode(conn, p, remoteAddr)
 	case proto.OpMetaSetattr:
 		err = m.opSetAttr(conn, p, remoteAddr)
+	case proto.OpMetaUpdateLinkTarget:
+		err = m.opUpdateLinkTarget(conn, p, remoteAddr)
+	case proto.OpMetaBatchCreateDentryInode:
+		err = m.opBatchCreateDentryInode(conn, p, remoteAddr)
 	case proto.OpMetaCreateDentry:
 		err = m.opCreateDentry(conn, p, remoteAddr)
 	case proto.OpMetaDeleteDentry:
 		err = m.opDeleteDentry(conn, p, remoteAddr)
 	case proto.OpMetaBatchDeleteDentry:
 		err = m.opBatchDeleteDentry(conn, p, remoteAddr)
+	case proto.OpMetaDeleteSubtree:
+		err = m.opDeleteSubtree(conn, p, remoteAddr)
+	case proto.OpMetaDeleteSubtreeStatus:
+		err = m.opDeleteSubtreeStatus(conn, p, remoteAddr)
+	case proto.OpMetaWatchEvents:
+		err = m.opMetaWatchEvents(conn, p, remoteAddr)
+	case proto.OpMetaSnapshotProgress:
+		err = m.opSnapshotProgress(conn, p, remoteAddr)
 	case proto.OpMetaUpdateDentry:
 		err = m.opUpdateDentry(conn, p, remoteAddr)
 	case proto.OpMetaReadDir:
@@ -284,6 +390,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opReadDirOnly(conn, p, remoteAddr)
 	case proto.OpMetaReadDirLimit:
 		err = m.opReadDirLimit(conn, p, remoteAddr)
+	case proto.OpMetaReadDirPlus:
+		err = m.opReadDirPlus(conn, p, remoteAddr)
 	case proto.OpCreateMetaPartition:
 		err = m.opCreateMetaPartition(conn, p, remoteAddr)
 	case proto.OpMetaNodeHeartbeat:
@@ -300,6 +408,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaExtentsDel(conn, p, remoteAddr)
 	case proto.OpMetaTruncate:
 		err = m.opMetaExtentsTruncate(conn, p, remoteAddr)
+	case proto.OpMetaExtentsPreAlloc:
+		err = m.opMetaExtentsPreAlloc(conn, p, remoteAddr)
 	case proto.OpMetaLookup:
 		err = m.opMetaLookup(conn, p, remoteAddr)
 	case proto.OpDeleteMetaPartition:
@@ -308,6 +418,18 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opUpdateMetaPartition(conn, p, remoteAddr)
 	case proto.OpLoadMetaPartition:
 		err = m.opLoadMetaPartition(conn, p, remoteAddr)
+	case proto.OpMetaTreeCRC:
+		err = m.opMetaTreeCRC(conn, p, remoteAddr)
+	case proto.OpMetaCountDentryRef:
+		err = m.opMetaCountDentryRef(conn, p, remoteAddr)
+	case proto.OpMetaRepairNLink:
+		err = m.opMetaRepairNLink(conn, p, remoteAddr)
+	case proto.OpMetaRaftLogTruncate:
+		err = m.opMetaRaftLogTruncate(conn, p, remoteAddr)
+	case proto.OpSyncMetaReplica:
+		err = m.opSyncMetaReplica(conn, p, remoteAddr)
+	case proto.OpMetaReadSnapshot:
+		err = m.opMetaReadSnapshot(conn, p, remoteAddr)
 	case proto.OpDecommissionMetaPartition:
 		err = m.opDecommissionMetaPartition(conn, p, remoteAddr)
 	case proto.OpAddMetaPartitionRaftMember:
@@ -347,6 +469,10 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaGetAllXAttr(conn, p, remoteAddr)
 	case proto.OpMetaBatchGetXAttr:
 		err = m.opMetaBatchGetXAttr(conn, p, remoteAddr)
+	case proto.OpMetaScanXAttr:
+		err = m.opMetaScanXAttr(conn, p, remoteAddr)
+	case proto.OpMetaAttrLease:
+		err = m.opMetaAttrLease(conn, p, remoteAddr)
 	case proto.OpMetaRemoveXAttr:
 		err = m.opMetaRemoveXAttr(conn, p, remoteAddr)
 	case proto.OpMetaListXAttr:
@@ -356,6 +482,10 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 	// operation for dir lock
 	case proto.OpMetaLockDir:
 		err = m.opMetaLockDir(conn, p, remoteAddr)
+	case proto.OpMetaFileLock:
+		err = m.opMetaFileLock(conn, p, remoteAddr)
+	case proto.OpMetaFileLockLease:
+		err = m.opMetaFileLockLease(conn, p, remoteAddr)
 	// operations for multipart session
 	case proto.OpCreateMultipart:
 		err = m.opCreateMultipart(conn, p, remoteAddr)
@@ -542,6 +672,8 @@ func (m *metadataManager) startSnapshotVersionPromote() {
 func (m *metadataManager) onStart() (err error) {
 	m.connPool = util.NewConnectPool()
 	m.initFileStatsConfig()
+	m.metaAuth.load(m.rootDir)
+	m.volWriteStates.load(m.rootDir)
 	err = m.loadPartitions()
 	if err != nil {
 		return
@@ -552,6 +684,8 @@ func (m *metadataManager) onStart() (err error) {
 	m.startSnapshotVersionPromote()
 	m.startUpdateVolumes()
 	m.startGcTimer()
+	m.startSnapshotJanitor()
+	m.startExtentMerger()
 	return
 }
 
@@ -595,6 +729,23 @@ func (m *metadataManager) ReloadPartition(id int) error {
 	return m.loadPartition(partitionPrefix + strconv.Itoa(id))
 }
 
+// RestartFailedPartition reloads a partition failed by a panic of apply, it is called by the
+// admin after the investigation.
+func (m *metadataManager) RestartFailedPartition(id uint64) (err error) {
+	mp, err := m.getPartition(id)
+	if err != nil {
+		return
+	}
+	failure := mp.GetApplyFailure()
+	if failure == nil {
+		return fmt.Errorf("meta partition %v is not failed", id)
+	}
+	log.LogWarnf("action[RestartFailedPartition] restart mp(%v) failed at op(%v) index(%v): %v",
+		id, failure.Op, failure.Index, failure.Reason)
+	mp.Stop()
+	return m.loadPartition(partitionPrefix + strconv.FormatUint(id, 10))
+}
+
 func (m *metadataManager) loadPartition(fileName string) (err error) {
 	log.LogInfof("action[loadPartitions] load partition filename %s", fileName)
 	defer func() {
@@ -789,6 +940,7 @@ func (m *metadataManager) createPartition(request *proto.CreateMetaPartitionRequ
 	}
 
 	partition := NewMetaPartition(mpc, m)
+	partition.SetDefaultXAttrs(request.DefaultXAttrs)
 
 	if err = partition.RenameStaleMetadata(); err != nil {
 		log.LogErrorf("[createPartition]->%s", err.Error())
@@ -923,8 +1075,38 @@ func NewMetadataManager(conf MetadataManagerConfig, metaNode *MetaNode) Metadata
 		enableGcTimer:        conf.EnableGcTimer,
 		gcRecyclePercent:     conf.GcRecyclePercent,
 		limitFactor:          make(map[uint32]*rate.Limiter),
+		failOverLimiter:      newFailOverLimiter(conf.LeaderTransferPerSec),
+		failOverWindow:       conf.FailOverWindow,
+		snapshotJanitor: snapshotJanitor{
+			expire:     conf.OrphanSnapshotExpire,
+			autoRemove: conf.RemoveOrphanSnapshotDirs,
+		},
+		xattrLimit:        conf.XAttrLimit,
+		opAuditDir:        conf.OpAuditDir,
+		opAuditLogMaxSize: conf.OpAuditLogMaxSize,
+		hbReporter:        newHeartbeatReporter(conf.HeartbeatFullReportInterval),
+		extentMerger: extentMerger{
+			threshold:   conf.ExtentMergeThreshold,
+			concurrency: conf.ExtentMergeConcurrency,
+		},
+		snapSendLimiter: newSnapSendLimiter(conf.SnapshotSendRateMB),
+		snapRecvLimiter: newSnapSendLimiter(conf.SnapshotRecvRateMB),
+		packetCompress: packetCompress{
+			codec:     conf.PacketCompressCodec,
+			threshold: conf.PacketCompressThreshold,
+		},
+		volWorkers: newVolWorkerPools(conf.VolWorkerPoolSize),
+		opQuotas:   newVolOpQuotas(),
+		priorities: newPriorityScheduler(conf.PriorityWorkerPoolSize, conf.InteractivePriorityWeight),
+		applies:    newApplyScheduler(conf.ApplyRateMB, conf.ApplyRecoveryReserve, conf.ApplyRecoveryCap),
+		followerReads: followerReadBound{
+			maxLag:   conf.FollowerReadMaxLag,
+			maxLagMs: conf.FollowerReadMaxLagMs,
+		},
 	}
 	m.limitFactor[readDirIops] = rate.NewLimiter(rate.Limit(metaNode.readDirIops), metaNode.readDirIops/2)
+	m.snapRates.local = [snapRateCount]int{conf.SnapshotSendRateMB, conf.SnapshotRecvRateMB, conf.SnapshotPartitionRateMB}
+	m.snapRates.partitionRate = int64(conf.SnapshotPartitionRateMB)
 
 	return m
 }
@@ -0,0 +1,197 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/exporter"
+	"golang.org/x/time/rate"
+)
+
+// the classes of the raft applies on the node
+const (
+	applyClassClient   = iota // the logs proposed by the leaders on the node
+	applyClassRecovery        // the snapshots and the logs applied by the replicas catching up
+	applyClassCount
+)
+
+const (
+	defaultApplyRecoveryReserve = 20 // percent of the apply rate
+	defaultApplyRecoveryCap     = 50 // percent of the apply rate
+)
+
+// the reserve of recovery is kept for it until it is idle for it
+var applyRecoveryIdle = time.Second
+
+// the logs applied by a follower this far behind the committed index are catching up
+const applyCatchUpEntries = 1024
+
+var applyClassNames = [applyClassCount]string{"client", "recovery"}
+
+// applyScheduler shares the apply throughput of the node by the classes of the applies. While
+// any replica is recovering, the client applies are limited to the rate less the reserve of
+// recovery, so that the reserve is guaranteed to it, and recovery is limited to its cap so
+// that the client applies are not starved.
+type applyScheduler struct {
+	rateMB   int           // of all the applies of the node, 0 disables the scheduler
+	total    *rate.Limiter // of all the applies
+	client   *rate.Limiter // of the client applies while recovering
+	recovery *rate.Limiter // of the recovery applies
+	// unix nano of the last recovery apply
+	lastRecovery int64
+	applied      [applyClassCount]uint64 // bytes
+	waited       [applyClassCount]int64  // nanoseconds
+}
+
+// ApplyClassStat is the applies of a class on the node.
+type ApplyClassStat struct {
+	Class    string `json:"class"`
+	RateMB   int    `json:"rateMB"` // the limit of the class, 0 is unlimited
+	Applied  uint64 `json:"applied"`
+	WaitedMs int64  `json:"waitedMs"`
+}
+
+// ApplySchedulerStat is the apply scheduler of the node.
+type ApplySchedulerStat struct {
+	RateMB     int              `json:"rateMB"`
+	Recovering bool             `json:"recovering"`
+	Classes    []ApplyClassStat `json:"classes"`
+}
+
+func newApplyScheduler(rateMB, reserve, capPercent int) *applyScheduler {
+	s := &applyScheduler{rateMB: rateMB}
+	if rateMB <= 0 {
+		return s
+	}
+	if reserve <= 0 || reserve >= 100 {
+		reserve = defaultApplyRecoveryReserve
+	}
+	if capPercent < reserve || capPercent > 100 {
+		capPercent = defaultApplyRecoveryCap
+		if capPercent < reserve {
+			capPercent = reserve
+		}
+	}
+	s.total = newSnapSendLimiter(rateMB)
+	s.client = newApplyLimiter(rateMB * (100 - reserve))
+	s.recovery = newApplyLimiter(rateMB * capPercent)
+	return s
+}
+
+// newApplyLimiter limits the rate of percent MB/s in hundredths.
+func newApplyLimiter(percentMB int) *rate.Limiter {
+	limit := percentMB * util.MB / 100
+	return rate.NewLimiter(rate.Limit(limit), limit)
+}
+
+func (s *applyScheduler) enabled() bool {
+	return s != nil && s.rateMB > 0
+}
+
+func (s *applyScheduler) recovering() bool {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&s.lastRecovery))) < applyRecoveryIdle
+}
+
+// wait blocks until n bytes of the class can be applied.
+func (s *applyScheduler) wait(class int, n int) {
+	if !s.enabled() {
+		return
+	}
+	start := time.Now()
+	if class == applyClassRecovery {
+		atomic.StoreInt64(&s.lastRecovery, start.UnixNano())
+		waitSnapSendQuota(s.recovery, n)
+	} else if s.recovering() {
+		waitSnapSendQuota(s.client, n)
+	}
+	waitSnapSendQuota(s.total, n)
+
+	waited := time.Since(start)
+	if class == applyClassRecovery {
+		atomic.StoreInt64(&s.lastRecovery, time.Now().UnixNano())
+	}
+	atomic.AddUint64(&s.applied[class], uint64(n))
+	atomic.AddInt64(&s.waited[class], int64(waited))
+	labels := map[string]string{"class": applyClassNames[class]}
+	exporter.NewCounter(MetricApplyBytes).AddWithLabels(int64(n), labels)
+	exporter.NewCounter(MetricApplyWaitMs).AddWithLabels(waited.Milliseconds(), labels)
+}
+
+func (s *applyScheduler) stats() (stat ApplySchedulerStat) {
+	stat.Classes = make([]ApplyClassStat, 0, applyClassCount)
+	if s == nil {
+		return
+	}
+	stat.RateMB = s.rateMB
+	stat.Recovering = s.enabled() && s.recovering()
+	for class := 0; class < applyClassCount; class++ {
+		classStat := ApplyClassStat{
+			Class:    applyClassNames[class],
+			Applied:  atomic.LoadUint64(&s.applied[class]),
+			WaitedMs: time.Duration(atomic.LoadInt64(&s.waited[class])).Milliseconds(),
+		}
+		if s.enabled() {
+			limiter := s.client
+			if class == applyClassRecovery {
+				limiter = s.recovery
+			}
+			classStat.RateMB = int(limiter.Limit()) / util.MB
+		}
+		stat.Classes = append(stat.Classes, classStat)
+	}
+	return
+}
+
+// GetApplyScheduler returns the applies of the classes on the node.
+func (m *metadataManager) GetApplyScheduler() ApplySchedulerStat {
+	return m.applies.stats()
+}
+
+// waitProposalQuota blocks the proposal of a log of n bytes until the node has the throughput
+// for it. The logs are throttled before they are submitted, not to block the raft applies of
+// the partition.
+func (mp *metaPartition) waitProposalQuota(n int) {
+	if mp.manager == nil || !mp.manager.applies.enabled() {
+		return
+	}
+	mp.manager.applies.wait(applyClassClient, n)
+}
+
+// waitCatchUpQuota blocks the apply of a log of n bytes by a follower catching up as recovery,
+// the logs applied at the pace of the leader are throttled by their proposals already.
+func (mp *metaPartition) waitCatchUpQuota(index uint64, n int) {
+	if mp.manager == nil || !mp.manager.applies.enabled() || mp.raftPartition == nil {
+		return
+	}
+	if mp.raftPartition.CommittedIndex() < index+applyCatchUpEntries {
+		return
+	}
+	if _, ok := mp.IsLeader(); ok {
+		return
+	}
+	mp.manager.applies.wait(applyClassRecovery, n)
+}
+
+// waitSnapshotQuota blocks the apply of n bytes of a snapshot received. The snapshot is limited
+// by the recovery class of the scheduler if it is enabled, and by the receive rates of the
+// snapshots of the node and the partition.
+func (mp *metaPartition) waitSnapshotQuota(partLimiter *snapPartitionLimiter, n int) {
+	mp.manager.applies.wait(applyClassRecovery, n)
+	waitSnapSendQuota(mp.manager.snapRecvLimiter, n)
+	partLimiter.wait(n)
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyScheduler(t *testing.T) {
+	old := applyRecoveryIdle
+	applyRecoveryIdle = 200 * time.Millisecond
+	defer func() { applyRecoveryIdle = old }()
+
+	// disabled scheduler applies at once
+	var nilScheduler *applyScheduler
+	nilScheduler.wait(applyClassRecovery, util.GB)
+	newApplyScheduler(0, 0, 0).wait(applyClassRecovery, util.GB)
+	require.Empty(t, newApplyScheduler(0, 0, 0).stats().Classes[applyClassRecovery].RateMB)
+
+	s := newApplyScheduler(10, 20, 50)
+	stat := s.stats()
+	require.Equal(t, 8, stat.Classes[applyClassClient].RateMB)
+	require.Equal(t, 5, stat.Classes[applyClassRecovery].RateMB)
+	require.False(t, stat.Recovering)
+
+	// recovery is capped to its share
+	s.wait(applyClassRecovery, 5*util.MB)
+	start := time.Now()
+	s.wait(applyClassRecovery, util.MB)
+	require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	require.True(t, s.stats().Recovering)
+
+	// the client applies leave the reserve to recovery while it is recovering
+	s.wait(applyClassClient, 3*util.MB)
+	require.Less(t, s.client.Tokens(), float64(6*util.MB))
+	require.Eventually(t, func() bool { return !s.stats().Recovering }, time.Second, 10*time.Millisecond)
+	tokens := s.client.Tokens()
+	s.wait(applyClassClient, util.MB)
+	require.InDelta(t, tokens, s.client.Tokens(), float64(util.MB)/2)
+	stat = s.stats()
+	require.EqualValues(t, 6*util.MB, stat.Classes[applyClassRecovery].Applied)
+	require.EqualValues(t, 4*util.MB, stat.Classes[applyClassClient].Applied)
+
+	// the logs are throttled by their proposals and the snapshots by the recovery class and
+	// the receive rate of the node both
+	mp := &metaPartition{manager: &metadataManager{
+		applies:         newApplyScheduler(1000, 0, 0),
+		snapRecvLimiter: newSnapSendLimiter(1),
+	}}
+	mp.waitProposalQuota(10)
+	mp.waitCatchUpQuota(1, 10)
+	start = time.Now()
+	mp.waitSnapshotQuota(nil, 2*util.MB)
+	require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	stat = mp.manager.applies.stats()
+	require.EqualValues(t, 10, stat.Classes[applyClassClient].Applied)
+	require.EqualValues(t, 2*util.MB, stat.Classes[applyClassRecovery].Applied)
+
+	// the proposals are not throttled without the scheduler
+	mp = &metaPartition{manager: &metadataManager{applies: newApplyScheduler(0, 0, 0)}}
+	mp.waitProposalQuota(util.GB)
+}
+
+func TestApplyRecoveryReserve(t *testing.T) {
+	s := newApplyScheduler(10, 20, 50)
+	// drain the bursts
+	now := time.Now()
+	s.total.AllowN(now, s.total.Burst())
+	s.client.AllowN(now, s.client.Burst())
+	s.recovery.AllowN(now, s.recovery.Burst())
+
+	const chunk = 64 * util.KB
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	s.wait(applyClassRecovery, chunk)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					done <- struct{}{}
+					return
+				default:
+				}
+				s.wait(applyClassClient, chunk)
+			}
+		}()
+	}
+	start := time.Now()
+	for time.Since(start) < time.Second {
+		s.wait(applyClassRecovery, chunk)
+	}
+	close(stop)
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+
+	// recovery keeps the reserve of 2MB/s under the client load, which gets no more than 8MB/s
+	stat := s.stats()
+	require.GreaterOrEqual(t, stat.Classes[applyClassRecovery].Applied, uint64(3*util.MB/2))
+	require.LessOrEqual(t, stat.Classes[applyClassClient].Applied, uint64(19*util.MB/2))
+	require.Greater(t, stat.Classes[applyClassClient].Applied, uint64(4*util.MB))
+}
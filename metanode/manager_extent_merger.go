@@ -0,0 +1,281 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultExtentMergeThreshold   = 256
+	defaultExtentMergeConcurrency = 2
+	extentMergeInterval           = 30 * time.Minute
+	maxMergeInodesPerScan         = 1024 // of a partition
+)
+
+// FragmentationStat is the extent fragmentation of the files in a partition found by the last scan.
+type FragmentationStat struct {
+	PartitionID   uint64 `json:"pid"`
+	VolName       string `json:"vol"`
+	Leader        bool   `json:"leader"`
+	Files         uint64 `json:"files"`
+	Extents       uint64 `json:"extents"`
+	MaxExtents    int    `json:"maxExtents"`
+	MaxExtentsIno uint64 `json:"maxExtentsIno"`
+	Fragmented    uint64 `json:"fragmented"`    // files with more extent keys than the threshold
+	Mergeable     uint64 `json:"mergeable"`     // fragmented files with contiguous extent keys
+	Merged        uint64 `json:"merged"`        // files merged by the last scan
+	MergedExtents uint64 `json:"mergedExtents"` // extent keys removed by the last scan
+}
+
+// VolFragmentation sums the fragmentation of the partitions of a volume on the node.
+type VolFragmentation struct {
+	VolName       string  `json:"vol"`
+	Partitions    int     `json:"partitions"`
+	Files         uint64  `json:"files"`
+	Extents       uint64  `json:"extents"`
+	AvgExtents    float64 `json:"avgExtents"`
+	MaxExtents    int     `json:"maxExtents"`
+	MaxExtentsIno uint64  `json:"maxExtentsIno"`
+	Fragmented    uint64  `json:"fragmented"`
+	Mergeable     uint64  `json:"mergeable"`
+	Merged        uint64  `json:"merged"`
+	MergedExtents uint64  `json:"mergedExtents"`
+}
+
+// ExtentFragmentation is the result of the last scan.
+type ExtentFragmentation struct {
+	Threshold   int                  `json:"threshold"`
+	Concurrency int                  `json:"concurrency"`
+	LastScan    time.Time            `json:"lastScan"`
+	Vols        []*VolFragmentation  `json:"vols"`
+	Partitions  []*FragmentationStat `json:"partitions"`
+}
+
+type extentMerger struct {
+	sync.RWMutex
+	threshold   int
+	concurrency int
+	lastScan    time.Time
+	stats       []*FragmentationStat
+}
+
+// hasSnapshotRefs returns if the extents of the inode are referred by snapshots, their keys
+// are counted one by one and must not be merged.
+func (i *Inode) hasSnapshotRefs() (has bool) {
+	if i.multiSnap == nil {
+		return
+	}
+	if i.multiSnap.verSeq != 0 || len(i.multiSnap.multiVersions) > 0 {
+		return true
+	}
+	if i.multiSnap.ekRefMap != nil {
+		i.multiSnap.ekRefMap.Range(func(_, _ interface{}) bool {
+			has = true
+			return false
+		})
+	}
+	return
+}
+
+// scanFragmentation counts the extent keys of the files in the partition and returns the
+// files with more keys than threshold which can be merged.
+func (mp *metaPartition) scanFragmentation(threshold int) (stat *FragmentationStat, inos []uint64) {
+	stat = &FragmentationStat{
+		PartitionID: mp.config.PartitionId,
+		VolName:     mp.config.VolName,
+	}
+	_, stat.Leader = mp.IsLeader()
+	mp.GetInodeTree().Ascend(func(item BtreeItem) bool {
+		ino := item.(*Inode)
+		if !proto.IsRegular(ino.Type) || !proto.IsStorageClassReplica(ino.StorageClass) || ino.ShouldDelete() {
+			return true
+		}
+		ino.RLock()
+		extents := ino.GetExtents()
+		count := extents.Len()
+		mergeable := count > threshold && !ino.hasSnapshotRefs() && extents.SequentialCount() > 0
+		ino.RUnlock()
+
+		stat.Files++
+		stat.Extents += uint64(count)
+		if count > stat.MaxExtents {
+			stat.MaxExtents, stat.MaxExtentsIno = count, ino.Inode
+		}
+		if count <= threshold {
+			return true
+		}
+		stat.Fragmented++
+		if mergeable {
+			stat.Mergeable++
+			if len(inos) < maxMergeInodesPerScan {
+				inos = append(inos, ino.Inode)
+			}
+		}
+		return true
+	})
+	return
+}
+
+// mergeExtents merges the contiguous extent keys of the inodes one by one while the
+// partition is the leader.
+func (mp *metaPartition) mergeExtents(inos []uint64) (merged, mergedExtents uint64) {
+	extentsLen := func(ino uint64) int {
+		item := mp.inodeTree.Get(NewInode(ino, 0))
+		if item == nil {
+			return 0
+		}
+		return item.(*Inode).GetExtents().Len()
+	}
+	for _, ino := range inos {
+		if _, ok := mp.IsLeader(); !ok {
+			return
+		}
+		before := extentsLen(ino)
+		val, err := NewInode(ino, 0).Marshal()
+		if err != nil {
+			return
+		}
+		resp, err := mp.submit(opFSMMergeExtents, val)
+		if err != nil {
+			log.LogWarnf("[mergeExtents] mp(%v) ino(%v) submit failed: %v", mp.config.PartitionId, ino, err)
+			return
+		}
+		if resp.(*InodeResponse).Status != proto.OpOk {
+			continue
+		}
+		if after := extentsLen(ino); after < before {
+			merged++
+			mergedExtents += uint64(before - after)
+		}
+	}
+	if merged > 0 {
+		log.LogInfof("[mergeExtents] mp(%v) merged(%v) files, removed(%v) extent keys",
+			mp.config.PartitionId, merged, mergedExtents)
+	}
+	return
+}
+
+// scanExtentFragmentation scans the partitions on the node one after another, the leaders
+// with fragmented files merge them with at most concurrency partitions at the same time.
+func (m *metadataManager) scanExtentFragmentation() {
+	m.extentMerger.RLock()
+	threshold, concurrency := m.extentMerger.threshold, m.extentMerger.concurrency
+	m.extentMerger.RUnlock()
+
+	partitions := make([]*metaPartition, 0)
+	m.Range(true, func(_ uint64, p MetaPartition) bool {
+		if mp, ok := p.(*metaPartition); ok {
+			partitions = append(partitions, mp)
+		}
+		return true
+	})
+
+	type mergeJob struct {
+		mp   *metaPartition
+		stat *FragmentationStat
+		inos []uint64
+	}
+	jobs := make(chan *mergeJob)
+	wg := sync.WaitGroup{}
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.stat.Merged, job.stat.MergedExtents = job.mp.mergeExtents(job.inos)
+			}
+		}()
+	}
+	stats := make([]*FragmentationStat, 0, len(partitions))
+	for _, mp := range partitions {
+		stat, inos := mp.scanFragmentation(threshold)
+		stats = append(stats, stat)
+		if concurrency > 0 && stat.Leader && len(inos) > 0 {
+			jobs <- &mergeJob{mp: mp, stat: stat, inos: inos}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	m.extentMerger.Lock()
+	m.extentMerger.lastScan = time.Now()
+	m.extentMerger.stats = stats
+	m.extentMerger.Unlock()
+}
+
+func (m *metadataManager) startExtentMerger() {
+	go func() {
+		ticker := time.NewTicker(extentMergeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopC:
+				return
+			case <-ticker.C:
+				m.scanExtentFragmentation()
+			}
+		}
+	}()
+}
+
+// GetExtentFragmentation returns the fragmentation of the partitions of the volume found by
+// the last scan, or of all the partitions on the node if volName is empty.
+func (m *metadataManager) GetExtentFragmentation(volName string) *ExtentFragmentation {
+	m.extentMerger.RLock()
+	defer m.extentMerger.RUnlock()
+	result := &ExtentFragmentation{
+		Threshold:   m.extentMerger.threshold,
+		Concurrency: m.extentMerger.concurrency,
+		LastScan:    m.extentMerger.lastScan,
+		Vols:        make([]*VolFragmentation, 0),
+		Partitions:  make([]*FragmentationStat, 0),
+	}
+	vols := make(map[string]*VolFragmentation)
+	for _, stat := range m.extentMerger.stats {
+		if volName != "" && stat.VolName != volName {
+			continue
+		}
+		result.Partitions = append(result.Partitions, stat)
+		vol, ok := vols[stat.VolName]
+		if !ok {
+			vol = &VolFragmentation{VolName: stat.VolName}
+			vols[stat.VolName] = vol
+			result.Vols = append(result.Vols, vol)
+		}
+		vol.Partitions++
+		vol.Files += stat.Files
+		vol.Extents += stat.Extents
+		if stat.MaxExtents > vol.MaxExtents {
+			vol.MaxExtents, vol.MaxExtentsIno = stat.MaxExtents, stat.MaxExtentsIno
+		}
+		vol.Fragmented += stat.Fragmented
+		vol.Mergeable += stat.Mergeable
+		vol.Merged += stat.Merged
+		vol.MergedExtents += stat.MergedExtents
+	}
+	for _, vol := range result.Vols {
+		if vol.Files > 0 {
+			vol.AvgExtents = float64(vol.Extents) / float64(vol.Files)
+		}
+	}
+	sort.Slice(result.Vols, func(i, j int) bool { return result.Vols[i].VolName < result.Vols[j].VolName })
+	return result
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtentMerger(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.config.NodeId = 1
+
+	newFile := func(ino uint64, eks []proto.ExtentKey) *Inode {
+		file := NewInode(ino, FileModeType)
+		file.StorageClass = proto.StorageClass_Replica_SSD
+		file.HybridCloudExtents.sortedEks = NewSortedExtentsFromEks(eks)
+		mp.inodeTree.ReplaceOrInsert(file, true)
+		return file
+	}
+	getExtents := func(ino uint64) []proto.ExtentKey {
+		return mp.inodeTree.Get(NewInode(ino, 0)).(*Inode).GetExtents().CopyExtents()
+	}
+	// 8 contiguous keys of extent 1 and 2 keys of other extents
+	eks := make([]proto.ExtentKey, 0)
+	for i := uint64(0); i < 8; i++ {
+		eks = append(eks, proto.ExtentKey{FileOffset: i * 100, PartitionId: 1, ExtentId: 1, ExtentOffset: i * 100, Size: 100})
+	}
+	eks = append(eks, proto.ExtentKey{FileOffset: 800, PartitionId: 1, ExtentId: 2, Size: 100})
+	eks = append(eks, proto.ExtentKey{FileOffset: 900, PartitionId: 2, ExtentId: 1, Size: 100})
+	newFile(2, eks)
+	// fragmented, but no key continues another one
+	scattered := make([]proto.ExtentKey, 0)
+	for i := uint64(0); i < 6; i++ {
+		scattered = append(scattered, proto.ExtentKey{FileOffset: i * 100, PartitionId: 1, ExtentId: 10 + i, Size: 100})
+	}
+	newFile(3, scattered)
+	newFile(4, eks[:2])
+
+	m := &metadataManager{
+		partitions:   map[uint64]MetaPartition{mp.config.PartitionId: mp},
+		extentMerger: extentMerger{threshold: 4, concurrency: 1},
+	}
+	m.scanExtentFragmentation()
+	frag := m.GetExtentFragmentation(VolNameForTest)
+	require.Len(t, frag.Partitions, 1)
+	stat := frag.Partitions[0]
+	require.True(t, stat.Leader)
+	require.EqualValues(t, 3, stat.Files)
+	require.EqualValues(t, 18, stat.Extents)
+	require.Equal(t, 10, stat.MaxExtents)
+	require.EqualValues(t, 2, stat.MaxExtentsIno)
+	require.EqualValues(t, 2, stat.Fragmented)
+	require.EqualValues(t, 1, stat.Mergeable)
+	require.EqualValues(t, 1, stat.Merged)
+	require.EqualValues(t, 7, stat.MergedExtents)
+	require.Len(t, frag.Vols, 1)
+	require.Equal(t, 10, frag.Vols[0].MaxExtents)
+	require.EqualValues(t, 6, frag.Vols[0].AvgExtents)
+
+	merged := getExtents(2)
+	require.Len(t, merged, 3)
+	require.Equal(t, proto.ExtentKey{PartitionId: 1, ExtentId: 1, Size: 800}, merged[0])
+	require.Equal(t, eks[8:], merged[1:])
+	require.Equal(t, scattered, getExtents(3))
+	// the file under the threshold is not merged
+	require.Len(t, getExtents(4), 2)
+	require.Empty(t, m.GetExtentFragmentation("other").Partitions)
+
+	// a merged file is not fragmented any more, and a disabled merger only reports
+	m.extentMerger.concurrency = 0
+	m.scanExtentFragmentation()
+	stat = m.GetExtentFragmentation("").Partitions[0]
+	require.EqualValues(t, 1, stat.Fragmented)
+	require.Zero(t, stat.Mergeable)
+	require.Zero(t, stat.Merged)
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultLeaderTransferPerSec = 10
+	failOverWindowLayout        = "15:04"
+)
+
+// failOverWindow is a daily time range, e.g. "01:00-05:00", during which
+// leader failover is allowed. A range crossing midnight is supported.
+type failOverWindow struct {
+	start time.Duration // offset from midnight
+	end   time.Duration
+}
+
+func parseFailOverWindow(s string) (w *failOverWindow, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	arr := strings.Split(s, "-")
+	if len(arr) != 2 {
+		return nil, fmt.Errorf("invalid failover window %v, expect HH:MM-HH:MM", s)
+	}
+	w = &failOverWindow{}
+	for i, v := range arr {
+		var t time.Time
+		if t, err = time.Parse(failOverWindowLayout, strings.TrimSpace(v)); err != nil {
+			return nil, fmt.Errorf("invalid failover window %v: %v", s, err)
+		}
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if i == 0 {
+			w.start = offset
+		} else {
+			w.end = offset
+		}
+	}
+	return
+}
+
+func (w *failOverWindow) contains(now time.Time) bool {
+	if w == nil {
+		return true
+	}
+	offset := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	if w.start <= w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+func (w *failOverWindow) String() string {
+	if w == nil {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", int(w.start.Hours()), int(w.start.Minutes())%60,
+		int(w.end.Hours()), int(w.end.Minutes())%60)
+}
+
+func newFailOverLimiter(perSec int) *rate.Limiter {
+	if perSec <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(perSec), 1)
+}
+
+// FailOverLeaderMp transfers the leadership of the leader partitions on this node to one of their followers.
+// If volName or pids is given, only the matched partitions are transferred. The transfers are paced by
+// leaderTransferPerSec and refused outside of the configured failover window unless force is set.
+func (m *metadataManager) FailOverLeaderMp(volName string, pids []uint64, force bool) (transferred []uint64, err error) {
+	if !force && !m.failOverWindow.contains(time.Now()) {
+		err = fmt.Errorf("now is out of failover window %v", m.failOverWindow)
+		return
+	}
+
+	var filter map[uint64]struct{}
+	if len(pids) > 0 {
+		filter = make(map[uint64]struct{}, len(pids))
+		for _, pid := range pids {
+			filter[pid] = struct{}{}
+		}
+	}
+
+	candidates := make([]MetaPartition, 0)
+	for id, mp := range m.GetLeaderPartitions() {
+		if volName != "" && mp.GetBaseConfig().VolName != volName {
+			continue
+		}
+		if filter != nil {
+			if _, ok := filter[id]; !ok {
+				continue
+			}
+		}
+		candidates = append(candidates, mp)
+	}
+
+	transferred = make([]uint64, 0, len(candidates))
+	for _, mp := range candidates {
+		if err = m.failOverLimiter.Wait(context.Background()); err != nil {
+			return
+		}
+		pid := mp.GetBaseConfig().PartitionId
+		if e := m.transferLeader(mp); e != nil {
+			log.LogWarnf("[FailOverLeaderMp] mp(%v) transfer leader failed: %v", pid, e)
+			continue
+		}
+		transferred = append(transferred, pid)
+	}
+	log.LogWarnf("[FailOverLeaderMp] vol(%v) pids(%v) candidates(%v) transferred(%v)",
+		volName, pids, len(candidates), transferred)
+	return
+}
+
+// transferLeader asks the first reachable follower to campaign for the leadership.
+func (m *metadataManager) transferLeader(mp MetaPartition) (err error) {
+	conf := mp.GetBaseConfig()
+	for _, peer := range conf.Peers {
+		if peer.ID == m.nodeId {
+			continue
+		}
+		if err = m.sendTryToLeader(peer.Addr, conf.PartitionId); err == nil {
+			return
+		}
+		log.LogWarnf("[transferLeader] mp(%v) try peer(%v) failed: %v", conf.PartitionId, peer.Addr, err)
+	}
+	if err == nil {
+		err = fmt.Errorf("mp(%v) has no follower", conf.PartitionId)
+	}
+	return
+}
+
+func (m *metadataManager) sendTryToLeader(addr string, pid uint64) (err error) {
+	p := &Packet{}
+	p.Magic = proto.ProtoMagic
+	p.Opcode = proto.OpMetaPartitionTryToLeader
+	p.PartitionID = pid
+	p.ReqID = proto.GenerateRequestID()
+
+	conn, err := m.connPool.GetConnect(addr)
+	if err != nil {
+		return
+	}
+	if err = p.WriteToConn(conn); err != nil {
+		m.connPool.PutConnect(conn, ForceClosedConnect)
+		return
+	}
+	if err = p.ReadFromConn(conn, proto.ReadDeadlineTime); err != nil {
+		m.connPool.PutConnect(conn, ForceClosedConnect)
+		return
+	}
+	m.connPool.PutConnect(conn, NoClosedConnect)
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("%v", p.GetResultMsg())
+	}
+	return
+}
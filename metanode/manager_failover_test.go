@@ -0,0 +1,65 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFailOverWindow(t *testing.T) {
+	w, err := parseFailOverWindow("")
+	require.NoError(t, err)
+	require.Nil(t, w)
+	require.True(t, w.contains(time.Now()))
+
+	_, err = parseFailOverWindow("01:00")
+	require.Error(t, err)
+	_, err = parseFailOverWindow("25:00-02:00")
+	require.Error(t, err)
+
+	w, err = parseFailOverWindow("01:30-05:00")
+	require.NoError(t, err)
+	require.Equal(t, "01:30-05:00", w.String())
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	require.False(t, w.contains(day.Add(time.Hour)))
+	require.True(t, w.contains(day.Add(2*time.Hour)))
+	require.False(t, w.contains(day.Add(5*time.Hour)))
+
+	// crossing midnight
+	w, err = parseFailOverWindow("23:00-02:00")
+	require.NoError(t, err)
+	require.True(t, w.contains(day.Add(23*time.Hour+30*time.Minute)))
+	require.True(t, w.contains(day.Add(time.Hour)))
+	require.False(t, w.contains(day.Add(12*time.Hour)))
+}
+
+func TestFailOverLeaderMpOutOfWindow(t *testing.T) {
+	now := time.Now()
+	start := time.Duration((now.Hour()+2)%24) * time.Hour
+	m := &metadataManager{
+		partitions:      make(map[uint64]MetaPartition),
+		failOverLimiter: newFailOverLimiter(0),
+		failOverWindow:  &failOverWindow{start: start, end: start + time.Hour},
+	}
+	_, err := m.FailOverLeaderMp("", nil, false)
+	require.Error(t, err)
+
+	transferred, err := m.FailOverLeaderMp("", nil, true)
+	require.NoError(t, err)
+	require.Empty(t, transferred)
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+)
+
+// followerReadBound is the lag behind the leader a follower serves the follower reads within,
+// the reads of a follower beyond it are proxied to the leader. Without a bound the follower
+// reads are served by a follower only if the leader is lost or failed.
+type followerReadBound struct {
+	maxLag   uint64 // raft logs committed but not applied, 0 is unbounded
+	maxLagMs int64  // ms the follower has been behind or without a leader, 0 is unbounded
+}
+
+func (b *followerReadBound) enabled() bool {
+	return b.maxLag > 0 || b.maxLagMs > 0
+}
+
+// allow returns if the follower is within the bound to serve the follower reads of mp.
+func (b *followerReadBound) allow(mp MetaPartition, hasLeader bool) bool {
+	if !b.enabled() {
+		return true
+	}
+	entries, behind := mp.GetFollowerLag(hasLeader)
+	if b.maxLag > 0 && entries > b.maxLag || b.maxLagMs > 0 && behind > time.Duration(b.maxLagMs)*time.Millisecond {
+		exporter.NewCounter(MetricFollowerReadStale).AddWithLabels(1,
+			map[string]string{exporter.Vol: mp.GetVolName()})
+		return false
+	}
+	return true
+}
+
+// followerLag is how stale the data of a follower is. The follower marks the index committed
+// it learns from the leader while it is behind, the time it learns it is how long its data
+// has been stale once the mark is applied, and it takes the new committed index then.
+type followerLag struct {
+	sync.Mutex
+	index        uint64 // committed but not applied when it is learned, 0 if the follower is not behind
+	since        int64  // unix nano the index is learned
+	leaderLostAt int64  // unix nano of the raft without a leader, 0 if it has one
+}
+
+// observe returns how long the follower has been behind with the committed and applied index.
+func (l *followerLag) observe(committed, applied uint64, now int64) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+	if l.index != 0 && applied >= l.index {
+		l.index, l.since = 0, 0
+	}
+	if l.index == 0 && committed > applied {
+		l.index, l.since = committed, now
+	}
+	if l.index == 0 {
+		return 0
+	}
+	return time.Duration(now - l.since)
+}
+
+// markFollowerLag records if the follower is behind the leader after the apply of the log of
+// index, the committed index of a follower is learned from the heartbeats of the leader.
+func (mp *metaPartition) markFollowerLag(index uint64) {
+	if mp.manager == nil || mp.manager.followerReads.maxLagMs <= 0 || mp.raftPartition == nil {
+		return
+	}
+	mp.followerLag.observe(mp.raftPartition.CommittedIndex(), index, time.Now().UnixNano())
+}
+
+// GetFollowerLag returns the raft logs committed but not applied by the partition and how long
+// its data has been stale, or it has been without a leader.
+func (mp *metaPartition) GetFollowerLag(hasLeader bool) (entries uint64, behind time.Duration) {
+	if mp.raftPartition == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	if hasLeader {
+		atomic.StoreInt64(&mp.followerLag.leaderLostAt, 0)
+	} else {
+		atomic.CompareAndSwapInt64(&mp.followerLag.leaderLostAt, 0, now)
+		behind = time.Duration(now - atomic.LoadInt64(&mp.followerLag.leaderLostAt))
+	}
+	committed, applied := mp.raftPartition.CommittedIndex(), mp.getApplyID()
+	if committed > applied {
+		entries = committed - applied
+	}
+	if d := mp.followerLag.observe(committed, applied, now); d > behind {
+		behind = d
+	}
+	return
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	raftstoremock "github.com/cubefs/cubefs/util/mocktest/raftstore"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowerReadBound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	raft := raftstoremock.NewMockPartition(mockCtrl)
+	committed := uint64(100)
+	raft.EXPECT().CommittedIndex().DoAndReturn(func() uint64 { return atomic.LoadUint64(&committed) }).AnyTimes()
+	mp := &metaPartition{
+		config:        &MetaPartitionConfig{VolName: "vol"},
+		manager:       &metadataManager{followerReads: followerReadBound{maxLag: 10, maxLagMs: 200}},
+		raftPartition: raft,
+	}
+	bound := &mp.manager.followerReads
+
+	// unbounded follower reads are always allowed
+	require.True(t, (&followerReadBound{}).allow(mp, false))
+
+	// the follower caught up with the leader
+	mp.uploadApplyID(100)
+	mp.markFollowerLag(100)
+	require.True(t, bound.allow(mp, true))
+
+	// the follower behind by the logs
+	mp.uploadApplyID(95)
+	mp.markFollowerLag(95)
+	require.True(t, bound.allow(mp, true))
+	mp.uploadApplyID(80)
+	require.False(t, bound.allow(mp, true))
+
+	// the follower behind for too long
+	mp.uploadApplyID(99)
+	mp.markFollowerLag(99)
+	entries, _ := mp.GetFollowerLag(true)
+	require.EqualValues(t, 1, entries)
+	require.Eventually(t, func() bool { return !bound.allow(mp, true) }, time.Second, 10*time.Millisecond)
+	mp.uploadApplyID(100)
+	mp.markFollowerLag(100)
+	require.True(t, bound.allow(mp, true))
+
+	// the follower never caught up under the steady writes is not stale while it keeps applying
+	for i := 0; i < 30; i++ {
+		applied := atomic.AddUint64(&committed, 5) - 2
+		mp.uploadApplyID(applied)
+		mp.markFollowerLag(applied)
+		require.True(t, bound.allow(mp, true))
+		time.Sleep(10 * time.Millisecond)
+	}
+	mp.uploadApplyID(atomic.LoadUint64(&committed))
+	mp.markFollowerLag(atomic.LoadUint64(&committed))
+
+	// the follower without a leader for too long
+	require.True(t, bound.allow(mp, false))
+	require.Eventually(t, func() bool { return !bound.allow(mp, false) }, time.Second, 10*time.Millisecond)
+	require.True(t, bound.allow(mp, true))
+}
@@ -0,0 +1,285 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/fileutil"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	// the tokens verified kept at most, the expired ones are dropped once it is reached
+	maxVerifiedMetaTokens = 65536
+	// the vols enforcing the meta auth at the last heartbeat, the keys are not kept on disk
+	metaAuthVolsFile = "meta_auth_vols"
+)
+
+var ErrMetaAccessKeysNotLoaded = errors.New("meta access keys are not loaded from master yet")
+
+// metaAuth keeps the keys of the vols enforcing the meta auth told by master, and the tokens
+// verified by them so each token is verified once until it expires. After a restart the vols
+// enforcing it before refuse the modifications until the keys come with the first heartbeat,
+// all the vols do if the file of them is there but can not be read. No vol enforces it if
+// there is no such file, e.g. the node never had a vol enforcing it.
+type metaAuth struct {
+	sync.RWMutex
+	keys     map[string][]*proto.MetaAccessKey
+	gen      uint64 // changed with the keys, a token verified by the keys of before is not kept
+	verified map[string]*verifiedMetaToken
+
+	dir        string          // where the vols enforcing it are kept, empty if not kept
+	pending    map[string]bool // the vols enforcing it before the restart, until the keys are loaded
+	pendingAll bool            // the vols enforcing it before the restart can not be read
+}
+
+type verifiedMetaToken struct {
+	vol    string
+	expire int64
+}
+
+// load takes the vols enforcing the meta auth before the restart from dir.
+func (a *metaAuth) load(dir string) {
+	a.Lock()
+	defer a.Unlock()
+	a.dir, a.pending, a.pendingAll = dir, nil, false
+	data, err := os.ReadFile(path.Join(dir, metaAuthVolsFile))
+	if os.IsNotExist(err) {
+		return
+	}
+	a.pendingAll = true
+	if err != nil {
+		log.LogErrorf("[metaAuth] load the vols enforcing the meta auth, err(%v)", err)
+		return
+	}
+	var vols []string
+	if err = json.Unmarshal(data, &vols); err != nil {
+		log.LogErrorf("[metaAuth] load the vols enforcing the meta auth, err(%v)", err)
+		return
+	}
+	a.pending = make(map[string]bool, len(vols))
+	for _, vol := range vols {
+		a.pending[vol] = true
+	}
+	a.pendingAll = false
+	log.LogWarnf("[metaAuth] vols(%v) refuse the modifications until the meta access keys are loaded", vols)
+}
+
+// persist keeps the vols enforcing the meta auth of keys. The lock must be held.
+func (a *metaAuth) persist(keys map[string][]*proto.MetaAccessKey) (err error) {
+	vols := make([]string, 0, len(keys))
+	for vol := range keys {
+		vols = append(vols, vol)
+	}
+	sort.Strings(vols)
+	data, err := json.Marshal(vols)
+	if err != nil {
+		return
+	}
+	name := path.Join(a.dir, metaAuthVolsFile)
+	if err = fileutil.WriteFileWithSync(name+".tmp", data, 0o644); err != nil {
+		return
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+func (a *metaAuth) update(keys map[string][]*proto.MetaAccessKey) {
+	a.Lock()
+	defer a.Unlock()
+	loading := a.pendingAll || a.pending != nil
+	if !loading && reflect.DeepEqual(a.keys, keys) {
+		return
+	}
+	if a.dir != "" && (loading || !sameMetaAuthVols(a.keys, keys)) {
+		if err := a.persist(keys); err != nil {
+			log.LogErrorf("[metaAuth] persist the vols enforcing the meta auth, err(%v)", err)
+		}
+	}
+	a.pending, a.pendingAll = nil, false
+	if reflect.DeepEqual(a.keys, keys) {
+		return
+	}
+	for vol := range keys {
+		if _, ok := a.keys[vol]; !ok {
+			log.LogWarnf("[metaAuth] vol(%v) enforces the meta auth", vol)
+		}
+	}
+	for vol := range a.keys {
+		if _, ok := keys[vol]; !ok {
+			log.LogWarnf("[metaAuth] vol(%v) no longer enforces the meta auth", vol)
+		}
+	}
+	a.keys = keys
+	a.gen++
+	a.verified = nil
+}
+
+func sameMetaAuthVols(a, b map[string][]*proto.MetaAccessKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for vol := range a {
+		if _, ok := b[vol]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// verify checks the token is issued for the vol if it enforces the meta auth.
+func (a *metaAuth) verify(vol string, token []byte, now time.Time) (err error) {
+	a.RLock()
+	keys, gen := a.keys[vol], a.gen
+	verified := a.verified[string(token)]
+	pending := a.pendingAll || a.pending[vol]
+	a.RUnlock()
+	if pending {
+		return ErrMetaAccessKeysNotLoaded
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if len(token) == 0 {
+		return proto.ErrMetaAccessTokenMissing
+	}
+	if verified != nil && verified.vol == vol {
+		if now.Unix() > verified.expire {
+			return proto.ErrMetaAccessTokenExpired
+		}
+		return nil
+	}
+
+	t, err := proto.DecodeMetaAccessToken(token)
+	if err != nil {
+		return
+	}
+	if err = t.Verify(vol, keys, now); err != nil {
+		return
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	if a.gen != gen {
+		return
+	}
+	if len(a.verified) >= maxVerifiedMetaTokens {
+		for k, v := range a.verified {
+			if now.Unix() > v.expire {
+				delete(a.verified, k)
+			}
+		}
+		if len(a.verified) >= maxVerifiedMetaTokens {
+			a.verified = nil
+		}
+	}
+	if a.verified == nil {
+		a.verified = make(map[string]*verifiedMetaToken)
+	}
+	a.verified[string(token)] = &verifiedMetaToken{vol: vol, expire: t.Expire}
+	return
+}
+
+// isMetaAuthWriteOp returns if the op of the clients modifies the vol and requires a token of
+// the meta auth. The ops among the metanodes, e.g. the commits of the tx to the RMs, and the
+// admin tasks of master are not served to the clients and need none. The vols read-only or
+// frozen refuse the same ops, see IsForbiddenOp.
+func isMetaAuthWriteOp(op uint8) bool {
+	switch op {
+	case
+		// dentry
+		proto.OpMetaCreateDentry,
+		proto.OpMetaTxCreateDentry,
+		proto.OpQuotaCreateDentry,
+		proto.OpMetaDeleteDentry,
+		proto.OpMetaTxDeleteDentry,
+		proto.OpMetaBatchDeleteDentry,
+		proto.OpMetaDeleteSubtree,
+		proto.OpMetaUpdateDentry,
+		proto.OpMetaTxUpdateDentry,
+		proto.OpMetaLockDir,
+		// extend
+		proto.OpMetaUpdateXAttr,
+		proto.OpMetaSetXAttr,
+		proto.OpMetaBatchSetXAttr,
+		proto.OpMetaRemoveXAttr,
+		// extent
+		proto.OpMetaTruncate,
+		proto.OpMetaExtentsPreAlloc,
+		proto.OpMetaExtentsAdd,
+		proto.OpMetaExtentAddWithCheck,
+		proto.OpMetaObjExtentAdd,
+		proto.OpMetaBatchObjExtentsAdd,
+		proto.OpMetaBatchExtentsAdd,
+		proto.OpMetaExtentsDel,
+		proto.OpMetaUpdateExtentKeyAfterMigration,
+		proto.OpDeleteMigrationExtentKey,
+		proto.OpMetaRenewalForbiddenMigration,
+		// inode
+		proto.OpMetaCreateInode,
+		proto.OpQuotaCreateInode,
+		proto.OpMetaTxCreateInode,
+		proto.OpMetaUnlinkInode,
+		proto.OpMetaTxUnlinkInode,
+		proto.OpMetaBatchUnlinkInode,
+		proto.OpMetaLinkInode,
+		proto.OpMetaTxLinkInode,
+		proto.OpMetaEvictInode,
+		proto.OpMetaBatchEvictInode,
+		proto.OpMetaSetattr,
+		proto.OpMetaUpdateInodeMeta,
+		proto.OpMetaUpdateLinkTarget,
+		proto.OpMetaBatchCreateDentryInode,
+		proto.OpMetaDeleteInode,
+		proto.OpMetaBatchDeleteInode,
+		proto.OpMetaClearInodeCache,
+		proto.OpMetaRepairNLink,
+		proto.OpMetaGetUniqID,
+		// tx
+		proto.OpMetaTxCreate,
+		proto.OpTxCommit,
+		proto.OpTxRollback,
+		// file lock
+		proto.OpMetaFileLock,
+		proto.OpMetaFileLockLease,
+		// multipart
+		proto.OpCreateMultipart,
+		proto.OpAddMultipartPart,
+		proto.OpRemoveMultipart,
+		// quota
+		proto.OpMetaBatchSetInodeQuota,
+		proto.OpMetaBatchDeleteInodeQuota:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkMetaAccessToken refuses the modifying op on a partition of a vol enforcing the meta
+// auth without a valid token.
+func (m *metadataManager) checkMetaAccessToken(mp MetaPartition, p *Packet) (err error) {
+	if !isMetaAuthWriteOp(p.Opcode) {
+		return nil
+	}
+	return m.metaAuth.verify(mp.GetVolName(), p.AccessToken(), time.Now())
+}
@@ -81,6 +81,38 @@ func (m *metadataManager) isVolForbidWriteOpOfProtoVer0(volName string) (forbid
 	return
 }
 
+func (m *metadataManager) checkReadOnlyWindowVolume(volNames []string, partition MetaPartition) {
+	volName := partition.GetVolName()
+	readOnly := false
+	for _, name := range volNames {
+		if name == volName {
+			readOnly = true
+			break
+		}
+	}
+	if partition.IsReadOnlyWindow() != readOnly {
+		log.LogWarnf("[checkReadOnlyWindowVolume] vol(%v) mpId(%v) read-only window change to %v",
+			volName, partition.GetBaseConfig().PartitionId, readOnly)
+		partition.SetReadOnlyWindow(readOnly)
+	}
+}
+
+func (m *metadataManager) checkMetaFrozenVolume(volNames []string, partition MetaPartition) {
+	volName := partition.GetVolName()
+	frozen := false
+	for _, name := range volNames {
+		if name == volName {
+			frozen = true
+			break
+		}
+	}
+	if partition.IsMetaFrozen() != frozen {
+		log.LogWarnf("[checkMetaFrozenVolume] vol(%v) mpId(%v) meta frozen change to %v",
+			volName, partition.GetBaseConfig().PartitionId, frozen)
+		partition.SetMetaFrozen(frozen)
+	}
+}
+
 func (m *metadataManager) checkForbiddenVolume(volNames []string, partition MetaPartition) {
 	volName := partition.GetVolName()
 	for _, name := range volNames {
@@ -119,6 +151,8 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 		volsForbidWriteOpOfProtoVer0 = make(map[string]struct{})
 		fileStatsEnableChange        bool
 		thresholdsChange             bool
+		reports                      []*proto.MetaPartitionReport
+		vols                         = make(map[string]struct{})
 	)
 	start := time.Now()
 	go func() {
@@ -129,7 +163,8 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 			resp.Result = err.Error()
 			goto end
 		}
-		if !m.useLocalGOGC {
+		// GOGC set locally or tuned by the node itself is not overridden by the master
+		if !m.useLocalGOGC && (m.metaNode == nil || !m.metaNode.gcTuner.enabled()) {
 			if m.gogcValue != req.MetaNodeGOGC && req.MetaNodeGOGC >= defaultGOGCLowerLimit && req.MetaNodeGOGC <= defaultGOGCUpperLimit {
 				oldGOGC := m.gogcValue
 				debug.SetGCPercent(req.MetaNodeGOGC)
@@ -193,10 +228,15 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 		resp.CpuUtil = m.cpuUtil.Load()
 
 		m.Range(true, func(id uint64, partition MetaPartition) bool {
+			vols[partition.GetVolName()] = struct{}{}
 			m.checkFollowerRead(req.FLReadVols, partition)
 			m.checkForbiddenVolume(req.ForbiddenVols, partition)
+			m.checkReadOnlyWindowVolume(req.ReadOnlyVols, partition)
+			m.checkMetaFrozenVolume(req.MetaFrozenVols, partition)
 			m.checkVolForbidWriteOpOfProtoVer0(partition)
 			m.checkDisableAuditLogVolume(req.DisableAuditVols, partition)
+			partition.SetDefaultXAttrs(req.VolDefaultXAttrs[partition.GetVolName()])
+			partition.SetVolPurgeCtrl(req.VolPurgeCtrls[partition.GetVolName()])
 			partition.SetUidLimit(req.UidLimitInfo)
 			partition.SetTxInfo(req.TxInfo)
 			partition.setQuotaHbInfo(req.QuotaHbInfos)
@@ -219,11 +259,16 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 				QuotaReportInfos:          partition.getQuotaReportInfos(),
 				StatByStorageClass:        partition.GetStatByStorageClass(),
 				StatByMigrateStorageClass: partition.GetMigrateStatByStorageClass(),
+				Stat:                      partition.GetStat(),
 				ForbidWriteOpOfProtoVer0:  mpForbidWriteVer0,
 				LocalPeers:                mConf.Peers,
 				ReadOnlyReasons:           0,
+				ApplyID:                   partition.GetAppliedID(),
+				OpRate:                    partition.takeProposalRate(),
 			}
 			mpr.TxCnt, mpr.TxRbInoCnt, mpr.TxRbDenCnt = partition.TxGetCnt()
+			purge := partition.GetPurgeStatus()
+			mpr.PurgeBacklogCount, mpr.PurgeBacklogBytes, mpr.PurgePaused = purge.BacklogCount, purge.BacklogBytes, purge.Paused
 
 			if mConf.Cursor >= mConf.End {
 				mpr.Status = proto.ReadOnly
@@ -233,6 +278,14 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 				mpr.Status = proto.ReadOnly
 				mpr.ReadOnlyReasons |= proto.MetaMemUseLimit
 			}
+			if partition.IsMemFrozen() {
+				mpr.Status = proto.ReadOnly
+				mpr.ReadOnlyReasons |= proto.MetaMemFrozen
+			}
+			if partition.IsApplyFailed() {
+				mpr.Status = proto.ReadOnly
+				mpr.ReadOnlyReasons |= proto.MpApplyFailed
+			}
 
 			addr, isLeader := partition.IsLeader()
 			if addr == "" {
@@ -240,11 +293,20 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 			}
 			mpr.IsLeader = isLeader
 
-			resp.MetaPartitionReports = append(resp.MetaPartitionReports, mpr)
+			reports = append(reports, mpr)
 			return true
 		})
+		m.volWorkers.update(req.VolMetaWorkerWeights, vols)
+		m.opQuotas.update(req.VolMetaOpQuotas)
+		m.metaAuth.update(req.VolMetaAccessKeys)
+		m.volWriteStates.update(req.ReadOnlyVols, req.MetaFrozenVols)
+		m.hbReporter.report(req, resp, reports)
 		resp.ZoneName = m.zoneName
+		resp.MediaType = m.metaNode.mediaType
 		resp.ReceivedForbidWriteOpOfProtoVer0 = m.metaNode.nodeForbidWriteOpOfProtoVer0
+		if drain := m.metaNode.getDrainStatus(); drain.Draining {
+			resp.Draining, resp.Drained = true, drain.Drained
+		}
 		resp.Status = proto.TaskSucceeds
 	end:
 		adminTask.Request = nil
@@ -846,6 +908,103 @@ func (m *metadataManager) opBatchDeleteDentry(conn net.Conn, p *Packet,
 	return
 }
 
+func (m *metadataManager) opDeleteSubtree(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &DeleteSubtreeReq{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	err = mp.DeleteSubtree(req, p, remoteAddr)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opDeleteSubtree] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+// opDeleteSubtreeStatus is not forwarded to the leader, the job is on the node started it.
+func (m *metadataManager) opDeleteSubtreeStatus(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &DeleteSubtreeStatusReq{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+
+	err = mp.DeleteSubtreeStatus(req, p)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opDeleteSubtreeStatus] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+// opMetaWatchEvents is served by any replica, the events are kept on each of them.
+func (m *metadataManager) opMetaWatchEvents(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.WatchEventsRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+
+	err = mp.WatchEvents(req, p)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opMetaWatchEvents] req: %d - %v, resp: %v", remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
+// opSnapshotProgress is sent by a follower to the leader it received the snapshot from.
+func (m *metadataManager) opSnapshotProgress(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &SnapshotProgressReq{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+
+	err = mp.SnapshotProgress(req, p)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opSnapshotProgress] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
 func (m *metadataManager) opTxUpdateDentry(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.TxUpdateDentryRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
@@ -1109,6 +1268,33 @@ func (m *metadataManager) opReadDirLimit(conn net.Conn, p *Packet,
 	return
 }
 
+func (m *metadataManager) opReadDirPlus(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &proto.ReadDirPlusRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	err = mp.ReadDirPlus(req, p)
+	m.respondToClient(conn, p)
+	log.LogDebugf("%s [%v]req: %v , resp: %v, size: %v", remoteAddr,
+		p.GetReqID(), req, p.GetResultMsg(), p.Size)
+	return
+}
+
 func (m *metadataManager) opMetaInodeGet(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &InodeGetReq{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
@@ -1260,6 +1446,80 @@ func (m *metadataManager) opSetAttr(conn net.Conn, p *Packet,
 	return
 }
 
+func (m *metadataManager) opUpdateLinkTarget(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &UpdateLinkTargetRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	if err = mp.UpdateLinkTarget(req, p); err != nil {
+		err = errors.NewErrorf("[opUpdateLinkTarget] req: %v, error: %s", req, err.Error())
+	}
+	m.updatePackRspSeq(mp, p)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opUpdateLinkTarget] req: %d - %v, resp: %v, body: %s", remoteAddr,
+		p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+func (m *metadataManager) opBatchCreateDentryInode(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &BatchCreateDentryInodeReq{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	if err = mp.BatchCreateDentryInode(req, p, remoteAddr); err != nil {
+		err = errors.NewErrorf("[opBatchCreateDentryInode] req: %v, error: %s", req.PartitionID, err.Error())
+	}
+	m.updatePackRspSeq(mp, p)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opBatchCreateDentryInode] req: %d - mp(%v) count(%v), resp: %v", remoteAddr,
+		p.GetReqID(), req.PartitionID, len(req.Items), p.GetResultMsg())
+	return
+}
+
 // Lookup request
 func (m *metadataManager) opMetaLookup(conn net.Conn, p *Packet,
 	remoteAddr string,
@@ -1510,6 +1770,43 @@ func (m *metadataManager) opMetaExtentsTruncate(conn net.Conn, p *Packet,
 	return
 }
 
+func (m *metadataManager) opMetaExtentsPreAlloc(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &ExtentsPreAllocReq{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+
+	if err = mp.ExtentsPreAlloc(req, p, remoteAddr); err != nil {
+		log.LogErrorf("[opMetaExtentsPreAlloc] mpId(%v) ino(%v) err: %v", req.PartitionID, req.Inode, err)
+	}
+
+	m.updatePackRspSeq(mp, p)
+	m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opMetaExtentsPreAlloc] req: %d - %v, resp: %v", remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
 // Delete a meta partition.
 func (m *metadataManager) opDeleteMetaPartition(conn net.Conn,
 	p *Packet, remoteAddr string,
@@ -1628,6 +1925,226 @@ func (m *metadataManager) opLoadMetaPartition(conn net.Conn, p *Packet,
 	return
 }
 
+func (m *metadataManager) opMetaTreeCRC(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &proto.MetaTreeCRCRequest{}
+	adminTask := &proto.AdminTask{
+		Request: req,
+	}
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	resp, err := mp.ComputeTreeCRC(req.RangeSize)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		return
+	}
+	p.PacketOkWithBody(data)
+	m.respondToClient(conn, p)
+	log.LogInfof("%s [opMetaTreeCRC] req[%v], applyID[%v], response status[%s]", remoteAddr, req,
+		resp.ApplyID, p.GetResultMsg())
+	return
+}
+
+func (m *metadataManager) opSyncMetaReplica(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &proto.SyncMetaReplicaRequest{}
+	adminTask := &proto.AdminTask{
+		Request: req,
+	}
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	status, err := mp.SyncReplica(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		return
+	}
+	p.PacketOkWithBody(data)
+	m.respondToClient(conn, p)
+	log.LogInfof("%s [opSyncMetaReplica] req[%v], status[%+v], response status[%s]", remoteAddr, req,
+		status, p.GetResultMsg())
+	return
+}
+
+// opMetaReadSnapshot replies the snapshot of a partition to the leader of a replica partition
+// syncing from it, in packets of frames that end with an empty one.
+func (m *metadataManager) opMetaReadSnapshot(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	mp, err := m.getPartition(p.PartitionID)
+	if err == nil {
+		err = mp.ReadSnapshot(func(frames []byte) error {
+			p.PacketOkWithBody(frames)
+			return m.respondToClient(conn, p)
+		})
+	}
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] mp(%v) from %v: %v", p.GetOpMsgWithReqAndResult(), p.PartitionID, remoteAddr, err)
+		return
+	}
+	p.PacketOkReply()
+	m.respondToClient(conn, p)
+	log.LogInfof("%s [opMetaReadSnapshot] mp(%v) snapshot sent", remoteAddr, p.PartitionID)
+	return
+}
+
+func (m *metadataManager) opMetaCountDentryRef(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &proto.MetaDentryRefRequest{}
+	adminTask := &proto.AdminTask{
+		Request: req,
+	}
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	resp := mp.CountDentryRefs(req.Inode)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		return
+	}
+	p.PacketOkWithBody(data)
+	m.respondToClient(conn, p)
+	log.LogInfof("%s [opMetaCountDentryRef] req[%v], refs[%v] children[%v], response status[%s]", remoteAddr, req,
+		resp.Refs, resp.Children, p.GetResultMsg())
+	return
+}
+
+func (m *metadataManager) opMetaRepairNLink(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &proto.MetaRepairNLinkRequest{}
+	adminTask := &proto.AdminTask{
+		Request: req,
+	}
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if err = mp.RepairNLink(req, p, remoteAddr); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	p.PacketOkReply()
+	m.respondToClient(conn, p)
+	log.LogInfof("%s [opMetaRepairNLink] req[%v], response status[%s]", remoteAddr, req, p.GetResultMsg())
+	return
+}
+
+func (m *metadataManager) opMetaRaftLogTruncate(conn net.Conn, p *Packet,
+	remoteAddr string,
+) (err error) {
+	req := &proto.MetaRaftLogTruncateRequest{}
+	adminTask := &proto.AdminTask{
+		Request: req,
+	}
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	resp, err := mp.TruncateRaftLog(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		return
+	}
+	p.PacketOkWithBody(data)
+	m.respondToClient(conn, p)
+	log.LogWarnf("%s [opMetaRaftLogTruncate] req[%v], first index[%v], reclaimed[%v], response status[%s]", remoteAddr,
+		req, resp.FirstIndex, resp.ReclaimedBytes, p.GetResultMsg())
+	return
+}
+
 func (m *metadataManager) opDecommissionMetaPartition(conn net.Conn,
 	p *Packet, remoteAddr string,
 ) (err error) {
@@ -2093,6 +2610,90 @@ func (m *metadataManager) opMetaLockDir(conn net.Conn, p *Packet, remoteAddr str
 	return
 }
 
+func (m *metadataManager) opMetaFileLock(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.FileLockRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	start := time.Now()
+	if mp.IsEnableAuditLog() && !req.Test {
+		defer func() {
+			err1 := fmt.Errorf("data(%s)_err(%v)_status(%s)", p.Data, err, p.GetResultMsg())
+			auditlog.LogInodeOp(remoteAddr, "", p.GetOpMsg(), fmt.Sprintf("%+v", req.Lock), err1, time.Since(start).Milliseconds(), req.Inode, 0)
+		}()
+	}
+
+	err = mp.FileLock(req, p)
+	_ = m.respondToClient(conn, p)
+	log.LogDebugf("%s [opMetaFileLock] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+func (m *metadataManager) opMetaFileLockLease(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.FileLockLeaseRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	err = mp.FileLockLease(req, p)
+	_ = m.respondToClient(conn, p)
+	log.LogDebugf("%s [opMetaFileLockLease] req: %d - %v, resp: %v", remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
+func (m *metadataManager) opMetaAttrLease(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.AttrLeaseRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	err = mp.AttrLease(req, p)
+	_ = m.respondToClient(conn, p)
+	log.LogDebugf("%s [opMetaAttrLease] req: %d - %v, resp: %v", remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
 func (m *metadataManager) opMetaGetAllXAttr(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.GetAllXAttrRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
@@ -2143,6 +2744,31 @@ func (m *metadataManager) opMetaBatchGetXAttr(conn net.Conn, p *Packet, remoteAd
 	return
 }
 
+func (m *metadataManager) opMetaScanXAttr(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.ScanXAttrRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	err = mp.ScanXAttr(req, p)
+	_ = m.respondToClient(conn, p)
+	log.LogDebugf("%s [opMetaScanXAttr] req: %d - %v, resp: %v",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
 func (m *metadataManager) opMetaRemoveXAttr(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.RemoveXAttrRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
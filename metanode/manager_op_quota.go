@@ -0,0 +1,191 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
+)
+
+// the classes of the meta ops limited by the quotas of the vols
+const (
+	metaOpClassCreate = iota
+	metaOpClassDelete
+	metaOpClassLookup
+	metaOpClassCount
+)
+
+var metaOpClassNames = [metaOpClassCount]string{"create", "delete", "lookup"}
+
+var metaOpClasses = map[uint8]int{
+	proto.OpMetaCreateInode:            metaOpClassCreate,
+	proto.OpQuotaCreateInode:           metaOpClassCreate,
+	proto.OpMetaTxCreateInode:          metaOpClassCreate,
+	proto.OpMetaCreateDentry:           metaOpClassCreate,
+	proto.OpMetaTxCreateDentry:         metaOpClassCreate,
+	proto.OpMetaBatchCreateDentryInode: metaOpClassCreate,
+	proto.OpMetaUnlinkInode:            metaOpClassDelete,
+	proto.OpMetaTxUnlinkInode:          metaOpClassDelete,
+	proto.OpMetaBatchUnlinkInode:       metaOpClassDelete,
+	proto.OpMetaEvictInode:             metaOpClassDelete,
+	proto.OpMetaBatchEvictInode:        metaOpClassDelete,
+	proto.OpMetaDeleteDentry:           metaOpClassDelete,
+	proto.OpMetaTxDeleteDentry:         metaOpClassDelete,
+	proto.OpMetaBatchDeleteDentry:      metaOpClassDelete,
+	proto.OpMetaLookup:                 metaOpClassLookup,
+}
+
+// metaOpBatches are the ops charged by the items of their requests instead of by the packets.
+var metaOpBatches = map[uint8]bool{
+	proto.OpMetaBatchCreateDentryInode: true,
+	proto.OpMetaBatchUnlinkInode:       true,
+	proto.OpMetaBatchEvictInode:        true,
+	proto.OpMetaBatchDeleteDentry:      true,
+}
+
+// metaOpBatchItems are the items of the requests of all the batch ops, by their json keys.
+type metaOpBatchItems struct {
+	Inodes  []json.RawMessage `json:"inos"`
+	Dentrys []json.RawMessage `json:"dens"`
+	Items   []json.RawMessage `json:"items"`
+}
+
+// batchItems returns the items of the request of a batch op in data, 1 if it is not a batch.
+func batchItems(op uint8, data []byte) int {
+	if !metaOpBatches[op] {
+		return 1
+	}
+	items := &metaOpBatchItems{}
+	if err := json.Unmarshal(data, items); err != nil {
+		return 1
+	}
+	if n := len(items.Inodes) + len(items.Dentrys) + len(items.Items); n > 0 {
+		return n
+	}
+	return 1
+}
+
+var ErrVolOpRateLimited = errors.New("meta op rate of the volume is over its quota")
+
+// volOpQuotas limits the requests per second of the meta ops of each vol on the node by the
+// quota of the vol set on master, a request over it is rejected for the client to retry. The
+// partitions of a vol on the node share its limiters, while each node limits the vol on its own,
+// so the vol takes up to the quota times the nodes its partitions are on.
+type volOpQuotas struct {
+	sync.RWMutex
+	quotas   map[string]proto.MetaOpQuota
+	limiters map[string]*[metaOpClassCount]*rate.Limiter // nil of the classes unlimited
+}
+
+// VolOpQuotaStat is the quota of the meta op rates of a vol on the node.
+type VolOpQuotaStat struct {
+	VolName string `json:"vol"`
+	proto.MetaOpQuota
+}
+
+func newVolOpQuotas() *volOpQuotas {
+	return &volOpQuotas{
+		quotas:   make(map[string]proto.MetaOpQuota),
+		limiters: make(map[string]*[metaOpClassCount]*rate.Limiter),
+	}
+}
+
+func newOpRateLimiter(ops uint64) *rate.Limiter {
+	if ops == 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(ops), int(ops))
+}
+
+// update sets the quotas of the vols from master, the limiters of the quotas unchanged are kept.
+func (q *volOpQuotas) update(quotas map[string]*proto.MetaOpQuota) {
+	if q == nil {
+		return
+	}
+	q.Lock()
+	defer q.Unlock()
+	for vol := range q.quotas {
+		if _, ok := quotas[vol]; !ok {
+			log.LogWarnf("[volOpQuotas] vol(%v) meta op quota removed", vol)
+			delete(q.quotas, vol)
+			delete(q.limiters, vol)
+		}
+	}
+	for vol, quota := range quotas {
+		if quota == nil || q.quotas[vol] == *quota {
+			continue
+		}
+		log.LogWarnf("[volOpQuotas] vol(%v) meta op quota from %+v to %+v", vol, q.quotas[vol], *quota)
+		q.quotas[vol] = *quota
+		q.limiters[vol] = &[metaOpClassCount]*rate.Limiter{
+			newOpRateLimiter(quota.CreateOps),
+			newOpRateLimiter(quota.DeleteOps),
+			newOpRateLimiter(quota.LookupOps),
+		}
+	}
+}
+
+// allow returns ErrVolOpRateLimited if the op of the vol with the request in data is over the
+// quota of its class. A batch op takes a token per item, up to the burst of the limiter.
+func (q *volOpQuotas) allow(vol string, op uint8, data []byte) error {
+	class, ok := metaOpClasses[op]
+	if !ok || q == nil {
+		return nil
+	}
+	q.RLock()
+	limiters := q.limiters[vol]
+	q.RUnlock()
+	if limiters == nil || limiters[class] == nil {
+		return nil
+	}
+	limiter := limiters[class]
+	n := batchItems(op, data)
+	if n > limiter.Burst() {
+		n = limiter.Burst()
+	}
+	if limiter.AllowN(time.Now(), n) {
+		return nil
+	}
+	exporter.NewCounter(MetricVolOpRateLimited).AddWithLabels(1,
+		map[string]string{exporter.Vol: vol, "class": metaOpClassNames[class]})
+	return ErrVolOpRateLimited
+}
+
+func (q *volOpQuotas) stats() (stats []VolOpQuotaStat) {
+	stats = make([]VolOpQuotaStat, 0)
+	if q == nil {
+		return
+	}
+	q.RLock()
+	defer q.RUnlock()
+	for vol, quota := range q.quotas {
+		stats = append(stats, VolOpQuotaStat{VolName: vol, MetaOpQuota: quota})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].VolName < stats[j].VolName })
+	return
+}
+
+// GetVolOpQuotas returns the quotas of the meta op rates of the vols on the node.
+func (m *metadataManager) GetVolOpQuotas() []VolOpQuotaStat {
+	return m.opQuotas.stats()
+}
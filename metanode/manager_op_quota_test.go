@@ -0,0 +1,60 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolOpQuotas(t *testing.T) {
+	q := newVolOpQuotas()
+	q.update(map[string]*proto.MetaOpQuota{"vol": {CreateOps: 2}})
+
+	// the ops over the quota of their class are rejected, the others are not limited
+	require.NoError(t, q.allow("vol", proto.OpMetaCreateInode, nil))
+	require.NoError(t, q.allow("vol", proto.OpMetaCreateDentry, nil))
+	require.Equal(t, ErrVolOpRateLimited, q.allow("vol", proto.OpMetaCreateInode, nil))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, q.allow("vol", proto.OpMetaLookup, nil))
+		require.NoError(t, q.allow("vol", proto.OpMetaInodeGet, nil))
+		require.NoError(t, q.allow("other", proto.OpMetaCreateInode, nil))
+	}
+
+	// the limiters of the quotas unchanged are kept
+	q.update(map[string]*proto.MetaOpQuota{"vol": {CreateOps: 2}, "other": {DeleteOps: 1}})
+	require.Equal(t, ErrVolOpRateLimited, q.allow("vol", proto.OpMetaCreateInode, nil))
+	require.NoError(t, q.allow("other", proto.OpMetaDeleteDentry, nil))
+	require.Equal(t, ErrVolOpRateLimited, q.allow("other", proto.OpMetaUnlinkInode, nil))
+	require.Len(t, q.stats(), 2)
+
+	// the batch ops take a token per item
+	q.update(map[string]*proto.MetaOpQuota{"vol": {DeleteOps: 5}})
+	data, err := json.Marshal(&proto.BatchUnlinkInodeRequest{Inodes: []uint64{1, 2, 3, 4}})
+	require.NoError(t, err)
+	require.NoError(t, q.allow("vol", proto.OpMetaBatchUnlinkInode, data))
+	require.Equal(t, ErrVolOpRateLimited, q.allow("vol", proto.OpMetaBatchUnlinkInode, data))
+	require.NoError(t, q.allow("vol", proto.OpMetaTxDeleteDentry, nil))
+	require.Equal(t, ErrVolOpRateLimited, q.allow("vol", proto.OpMetaEvictInode, nil))
+	require.Equal(t, 2, batchItems(proto.OpMetaBatchDeleteDentry, []byte(`{"dens":[{},{}]}`)))
+	require.Equal(t, 1, batchItems(proto.OpMetaDeleteDentry, []byte(`{"dens":[{},{}]}`)))
+
+	q.update(nil)
+	require.NoError(t, q.allow("vol", proto.OpMetaCreateInode, nil))
+	require.Empty(t, q.stats())
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+)
+
+const (
+	defaultInteractivePriorityWeight = 4
+	// the requests of a class waiting for the workers are bounded to it times the workers
+	priorityQueueFactor = 4
+)
+
+var (
+	// the request waits for a worker of its class up to it, the client retries it after
+	priorityWaitTimeout = 3 * time.Second
+
+	ErrPriorityQueueFull = errors.New("request queue of the priority class is full")
+	ErrPriorityWorkers   = errors.New("request workers of the priority class are busy")
+)
+
+// priorityScheduler shares the request workers of the node by the priority classes of the
+// packets, so that the background batch jobs do not inflate the latency of the interactive
+// ops. Each class waits in a bounded queue of its own, and a released worker is handed to the
+// queues by smooth weighted round robin.
+type priorityScheduler struct {
+	sync.Mutex
+	workers int // of the node, 0 disables the scheduler
+	running int
+	weights [proto.PacketPriorityCount]int
+	credits [proto.PacketPriorityCount]int
+	queues  [proto.PacketPriorityCount][]chan struct{}
+}
+
+// PriorityClassStat is the requests of a priority class on the node.
+type PriorityClassStat struct {
+	Priority uint8 `json:"priority"`
+	Weight   int   `json:"weight"`
+	Waiting  int   `json:"waiting"`
+}
+
+// PrioritySchedulerStat is the priority scheduler of the node.
+type PrioritySchedulerStat struct {
+	Workers int                 `json:"workers"`
+	Running int                 `json:"running"`
+	Classes []PriorityClassStat `json:"classes"`
+}
+
+func newPriorityScheduler(workers, interactiveWeight int) *priorityScheduler {
+	if interactiveWeight <= 0 {
+		interactiveWeight = defaultInteractivePriorityWeight
+	}
+	s := &priorityScheduler{workers: workers}
+	s.weights[proto.PacketPriorityInteractive] = interactiveWeight
+	s.weights[proto.PacketPriorityBackground] = 1
+	return s
+}
+
+func (s *priorityScheduler) waitingLocked() (waiting int) {
+	for _, queue := range s.queues {
+		waiting += len(queue)
+	}
+	return
+}
+
+// nextLocked picks the queue the worker is handed to, nil if none is waiting.
+func (s *priorityScheduler) nextLocked() (next chan struct{}) {
+	chosen, total := -1, 0
+	for priority, queue := range s.queues {
+		if len(queue) == 0 {
+			continue
+		}
+		s.credits[priority] += s.weights[priority]
+		total += s.weights[priority]
+		if chosen < 0 || s.credits[priority] > s.credits[chosen] {
+			chosen = priority
+		}
+	}
+	if chosen < 0 {
+		return nil
+	}
+	s.credits[chosen] -= total
+	next = s.queues[chosen][0]
+	s.queues[chosen] = s.queues[chosen][1:]
+	return
+}
+
+func (s *priorityScheduler) release() {
+	s.Lock()
+	defer s.Unlock()
+	if next := s.nextLocked(); next != nil {
+		// the worker is kept running for the next request
+		close(next)
+		return
+	}
+	s.running--
+}
+
+// removeLocked drops the request given up from the queue, false if it is handed a worker.
+func (s *priorityScheduler) removeLocked(priority uint8, wait chan struct{}) bool {
+	queue := s.queues[priority]
+	for i, c := range queue {
+		if c == wait {
+			s.queues[priority] = append(queue[:i:i], queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// acquire waits for a worker for the request of the priority class, release is called once
+// the request is processed.
+func (s *priorityScheduler) acquire(priority uint8) (release func(), err error) {
+	if s == nil || s.workers <= 0 {
+		return func() {}, nil
+	}
+	if int(priority) >= proto.PacketPriorityCount {
+		priority = proto.PacketPriorityBackground
+	}
+	s.Lock()
+	if s.running < s.workers && s.waitingLocked() == 0 {
+		s.running++
+		s.Unlock()
+		return s.release, nil
+	}
+	if len(s.queues[priority]) >= s.workers*priorityQueueFactor {
+		s.Unlock()
+		return nil, ErrPriorityQueueFull
+	}
+	wait := make(chan struct{})
+	s.queues[priority] = append(s.queues[priority], wait)
+	s.Unlock()
+
+	timer := time.NewTimer(priorityWaitTimeout)
+	defer timer.Stop()
+	select {
+	case <-wait:
+		return s.release, nil
+	case <-timer.C:
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.removeLocked(priority, wait) {
+		return nil, ErrPriorityWorkers
+	}
+	// handed a worker meanwhile
+	return s.release, nil
+}
+
+func (s *priorityScheduler) stats() (stat PrioritySchedulerStat) {
+	stat.Classes = make([]PriorityClassStat, 0, proto.PacketPriorityCount)
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	stat.Workers = s.workers
+	stat.Running = s.running
+	for priority, queue := range s.queues {
+		stat.Classes = append(stat.Classes, PriorityClassStat{
+			Priority: uint8(priority),
+			Weight:   s.weights[priority],
+			Waiting:  len(queue),
+		})
+	}
+	return
+}
+
+// GetPriorityScheduler returns the requests of the priority classes on the node.
+func (m *metadataManager) GetPriorityScheduler() PrioritySchedulerStat {
+	return m.priorities.stats()
+}
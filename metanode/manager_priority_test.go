@@ -0,0 +1,85 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityScheduler(t *testing.T) {
+	old := priorityWaitTimeout
+	priorityWaitTimeout = 10 * time.Millisecond
+	defer func() { priorityWaitTimeout = old }()
+
+	// disabled scheduler takes every request
+	release, err := newPriorityScheduler(0, 0).acquire(proto.PacketPriorityBackground)
+	require.NoError(t, err)
+	release()
+	var nilScheduler *priorityScheduler
+	_, err = nilScheduler.acquire(proto.PacketPriorityInteractive)
+	require.NoError(t, err)
+
+	s := newPriorityScheduler(1, 2)
+	release, err = s.acquire(proto.PacketPriorityBackground)
+	require.NoError(t, err)
+	_, err = s.acquire(proto.PacketPriorityInteractive)
+	require.ErrorIs(t, err, ErrPriorityWorkers)
+	require.Zero(t, s.stats().Classes[proto.PacketPriorityInteractive].Waiting)
+
+	// the released worker is handed to the queues by weight
+	priorityWaitTimeout = time.Minute
+	order := make(chan uint8, 6)
+	queue := func(priority uint8, count int) {
+		for i := 0; i < count; i++ {
+			go func() {
+				release, err := s.acquire(priority)
+				require.NoError(t, err)
+				order <- priority
+				release()
+			}()
+			require.Eventually(t, func() bool {
+				return s.stats().Classes[priority].Waiting == i+1
+			}, time.Second, time.Millisecond)
+		}
+	}
+	queue(proto.PacketPriorityBackground, 2)
+	queue(proto.PacketPriorityInteractive, 4)
+	release()
+	got := make([]uint8, 0, 6)
+	for i := 0; i < 6; i++ {
+		got = append(got, <-order)
+	}
+	i, b := proto.PacketPriorityInteractive, proto.PacketPriorityBackground
+	require.Equal(t, []uint8{i, b, i, i, b, i}, got)
+	require.Zero(t, s.stats().Running)
+
+	// the queue of a class is bounded
+	s = newPriorityScheduler(1, 0)
+	release, err = s.acquire(proto.PacketPriorityInteractive)
+	require.NoError(t, err)
+	for i := 0; i < priorityQueueFactor; i++ {
+		go s.acquire(proto.PacketPriorityBackground)
+	}
+	require.Eventually(t, func() bool {
+		return s.stats().Classes[proto.PacketPriorityBackground].Waiting == priorityQueueFactor
+	}, time.Second, time.Millisecond)
+	_, err = s.acquire(proto.PacketPriorityBackground)
+	require.ErrorIs(t, err, ErrPriorityQueueFull)
+	release()
+}
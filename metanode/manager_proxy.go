@@ -17,6 +17,7 @@ package metanode
 import (
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/cubefs/cubefs/datanode/storage"
 
@@ -30,60 +31,16 @@ const (
 )
 
 func (m *metadataManager) IsForbiddenOp(mp MetaPartition, reqOp uint8) bool {
-	if !mp.IsForbidden() {
-		return false
+	if mp.IsForbidden() {
+		return reqOp == proto.OpMetaLookup || isMetaAuthWriteOp(reqOp)
 	}
-	switch reqOp {
-	case
-		// dentry
-		proto.OpMetaCreateDentry,
-		proto.OpMetaTxCreateDentry,
-		proto.OpQuotaCreateDentry,
-		proto.OpMetaDeleteDentry,
-		proto.OpMetaTxDeleteDentry,
-		proto.OpMetaBatchDeleteDentry,
-		proto.OpMetaUpdateDentry,
-		proto.OpMetaTxUpdateDentry,
-		// extend
-		proto.OpMetaUpdateXAttr,
-		proto.OpMetaSetXAttr,
-		proto.OpMetaBatchSetXAttr,
-		proto.OpMetaRemoveXAttr,
-		// extent
-		proto.OpMetaTruncate,
-		proto.OpMetaExtentsAdd,
-		proto.OpMetaExtentAddWithCheck,
-		proto.OpMetaObjExtentAdd,
-		proto.OpMetaBatchObjExtentsAdd,
-		proto.OpMetaBatchExtentsAdd,
-		proto.OpMetaExtentsDel,
-		// inode
-		proto.OpMetaCreateInode,
-		proto.OpQuotaCreateInode,
-		proto.OpMetaTxUnlinkInode,
-		proto.OpMetaUnlinkInode,
-		proto.OpMetaBatchUnlinkInode,
-		proto.OpMetaTxLinkInode,
-		proto.OpMetaLinkInode,
-		proto.OpMetaEvictInode,
-		proto.OpMetaBatchEvictInode,
-		proto.OpMetaSetattr,
-		proto.OpMetaBatchDeleteInode,
-		proto.OpMetaClearInodeCache,
-		proto.OpMetaTxCreateInode,
-		proto.OpMetaLookup,
-		// multipart
-		proto.OpAddMultipartPart,
-		proto.OpRemoveMultipart,
-		proto.OpCreateMultipart,
-		// quota
-		proto.OpMetaBatchSetInodeQuota,
-		proto.OpMetaBatchDeleteInodeQuota:
-
-		return true
-	default:
-		return false
+	// a replica volume is read-only, so is a volume in a read-only window or frozen, all the
+	// modifying ops of the clients are refused as the meta auth does
+	if mp.IsVolReplica() || mp.IsReadOnlyWindow() || mp.IsMetaFrozen() ||
+		m.volWriteStates.isPending(mp.GetVolName()) {
+		return isMetaAuthWriteOp(reqOp)
 	}
+	return false
 }
 
 // The proxy is used during the leader change. When a leader of a partition changes, the proxy forwards the request to
@@ -106,20 +63,53 @@ func (m *metadataManager) serveProxy(conn net.Conn, mp MetaPartition,
 		m.respondToClient(conn, p)
 		return false
 	}
+	if err = m.checkMetaAccessToken(mp, p); err != nil {
+		status := proto.OpNotPerm
+		if err == ErrMetaAccessKeysNotLoaded {
+			// the client retries once the keys come with the heartbeat of master
+			status = proto.OpAgain
+		}
+		p.PacketErrorWithBody(status, []byte(err.Error()))
+		m.respondToClient(conn, p)
+		return false
+	}
+	if mp.IsMemFrozen() && isMemGrowingOp(reqOp) {
+		err = ErrMemFrozen
+		status := proto.OpNoSpaceErr
+		if isCreateInodeOp(reqOp) {
+			// the inode is created on another partition
+			status = proto.OpPartitionFrozen
+		}
+		p.PacketErrorWithBody(status, []byte(err.Error()))
+		m.respondToClient(conn, p)
+		return false
+	}
 
 	followerRead := func() bool {
-		if !p.IsReadMetaPkt() {
+		if !p.IsReadMetaPkt() || mp.IsApplyFailed() {
 			return false
 		}
 
-		if p.ProtoVersion == proto.PacketProtoVersion0 && mp.IsFollowerRead() {
-			return true
+		if !(p.ProtoVersion == proto.PacketProtoVersion0 && mp.IsFollowerRead()) && !p.IsFollowerReadMetaPkt() {
+			return false
 		}
 
-		return p.IsFollowerReadMetaPkt()
+		return m.followerReads.allow(mp, leaderAddr != "")
 	}
 
 	if leaderAddr, ok = mp.IsLeader(); ok {
+		if mp.IsApplyFailed() {
+			// the raft is being stopped, let the client retry on the new leader
+			err = ErrApplyFailed
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			m.respondToClient(conn, p)
+			return false
+		}
+		// the client has given up the op waiting in the schedulers, don't propose it
+		if p.expired() {
+			p.dropExpired("schedule")
+			return false
+		}
 		return
 	}
 
@@ -134,6 +124,18 @@ func (m *metadataManager) serveProxy(conn net.Conn, mp MetaPartition,
 		goto end
 	}
 
+	if m.followerReads.enabled() && followerRead() {
+		// the follower is within the lag bound, the leader is not to serve it
+		log.LogDebugf("read from follower within lag: p(%v), arg(%v)", p, mp.GetBaseConfig().PartitionId)
+		return true
+	}
+
+	// the leader waits for the op only for the time the client still waits
+	if !p.forwardDeadline(time.Now()) {
+		p.dropExpired("proxy")
+		return false
+	}
+
 	mConn, err = m.connPool.GetConnect(leaderAddr)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
@@ -141,6 +143,11 @@ func (m *metadataManager) serveProxy(conn net.Conn, mp MetaPartition,
 		goto end
 	}
 
+	// the span of the leader is a child of the one here, if the client traces the op
+	if p.span != nil && p.HasTraceContext() {
+		p.SetTraceContext(p.span.IDs())
+	}
+	p.span.Event("proxyToLeader")
 	// send to master connection
 	if err = p.WriteToConn(mConn); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
@@ -0,0 +1,193 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaFrozenVolume(t *testing.T) {
+	m := &metadataManager{}
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+
+	m.checkMetaFrozenVolume([]string{"other", "vol"}, mp)
+	require.True(t, mp.IsMetaFrozen())
+	require.True(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+	require.True(t, m.IsForbiddenOp(mp, proto.OpMetaExtentsAdd))
+	require.True(t, m.IsForbiddenOp(mp, proto.OpMetaDeleteInode))
+	require.True(t, m.IsForbiddenOp(mp, proto.OpMetaTxCreate))
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaLookup))
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaInodeGet))
+
+	m.checkMetaFrozenVolume([]string{"other"}, mp)
+	require.False(t, mp.IsMetaFrozen())
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+}
+
+func TestMetaAccessTokenCheck(t *testing.T) {
+	m := &metadataManager{}
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	packet := func(op uint8, token string) *Packet {
+		p := &Packet{}
+		p.Opcode = op
+		p.SetAccessToken(token)
+		return p
+	}
+	sign := func(key *proto.MetaAccessKey, expire int64) string {
+		token := &proto.MetaAccessToken{Vol: "vol", UserID: "user", Expire: expire}
+		token.SignBy(key)
+		s, err := token.Encode()
+		require.NoError(t, err)
+		return s
+	}
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, "")))
+
+	key1 := &proto.MetaAccessKey{ID: 1, Secret: "secret1"}
+	m.metaAuth.update(map[string][]*proto.MetaAccessKey{"vol": {key1}})
+	require.Equal(t, proto.ErrMetaAccessTokenMissing, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, "")))
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaLookup, "")))
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaInodeGet, "")))
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, "bad")))
+	require.Equal(t, proto.ErrMetaAccessTokenExpired,
+		m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, sign(key1, time.Now().Unix()-1))))
+
+	token1 := sign(key1, time.Now().Unix()+60)
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, token1)))
+	require.Len(t, m.metaAuth.verified, 1)
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaExtentsAdd, token1)))
+	require.Len(t, m.metaAuth.verified, 1)
+
+	// the tokens of the key rotated out are valid until the key is dropped
+	key2 := &proto.MetaAccessKey{ID: 2, Secret: "secret2"}
+	m.metaAuth.update(map[string][]*proto.MetaAccessKey{"vol": {key1, key2}})
+	require.Empty(t, m.metaAuth.verified)
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, token1)))
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, sign(key2, time.Now().Unix()+60))))
+	m.metaAuth.update(map[string][]*proto.MetaAccessKey{"vol": {key2}})
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, token1)))
+
+	m.metaAuth.update(nil)
+	require.NoError(t, m.checkMetaAccessToken(mp, packet(proto.OpMetaCreateInode, "")))
+}
+
+func TestMetaAuthWriteOps(t *testing.T) {
+	m := &metadataManager{}
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	m.metaAuth.update(map[string][]*proto.MetaAccessKey{"vol": {{ID: 1, Secret: "secret1"}}})
+
+	metaOp := regexp.MustCompile(`^Op(Meta|Quota|Tx)|Multipart|MigrationExtentKey`)
+	writeOp := regexp.MustCompile(`Create|Unlink|Delete|Del$|Update|Truncate|Link|Evict|Set|Remove|Add|Lock|Repair|Renewal|Commit|Rollback|PreAlloc|Clear`)
+	notClientOps := map[uint8]bool{
+		proto.OpTxCommitRM:              true, // sent by the TM
+		proto.OpTxRollbackRM:            true,
+		proto.OpMetaRaftLogTruncate:     true, // admin task of master
+		proto.OpMetaDeleteSubtreeStatus: true, // a read of the status
+	}
+	walked := 0
+	for op := 0; op <= 0xFF; op++ {
+		p := &Packet{}
+		p.Opcode = uint8(op)
+		name := p.GetOpMsg()
+		if !metaOp.MatchString(name) {
+			continue
+		}
+		walked++
+		write := writeOp.MatchString(name) && !notClientOps[p.Opcode]
+		require.Equal(t, write, isMetaAuthWriteOp(p.Opcode), name)
+		if write {
+			require.Equal(t, proto.ErrMetaAccessTokenMissing, m.checkMetaAccessToken(mp, p), name)
+		} else {
+			require.NoError(t, m.checkMetaAccessToken(mp, p), name)
+		}
+	}
+	require.Greater(t, walked, 60)
+}
+
+func TestMetaAuthLoad(t *testing.T) {
+	dir := t.TempDir()
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	other := &metaPartition{config: &MetaPartitionConfig{PartitionId: 2, VolName: "other"}}
+	packet := &Packet{}
+	packet.Opcode = proto.OpMetaCreateInode
+
+	// no vol enforces it without the file
+	m := &metadataManager{}
+	m.metaAuth.load(dir)
+	require.NoError(t, m.checkMetaAccessToken(other, packet))
+	require.NoError(t, m.checkMetaAccessToken(mp, packet))
+	m.metaAuth.update(map[string][]*proto.MetaAccessKey{"vol": {{ID: 1, Secret: "secret1"}}})
+	require.NoError(t, m.checkMetaAccessToken(other, packet))
+	require.Equal(t, proto.ErrMetaAccessTokenMissing, m.checkMetaAccessToken(mp, packet))
+
+	// restarted, the vol enforcing it refuses the writes until the keys are loaded
+	m = &metadataManager{}
+	m.metaAuth.load(dir)
+	require.Equal(t, ErrMetaAccessKeysNotLoaded, m.checkMetaAccessToken(mp, packet))
+	require.NoError(t, m.checkMetaAccessToken(other, packet))
+	m.metaAuth.update(nil)
+	require.NoError(t, m.checkMetaAccessToken(mp, packet))
+
+	m = &metadataManager{}
+	m.metaAuth.load(dir)
+	require.NoError(t, m.checkMetaAccessToken(mp, packet))
+
+	// all the vols refuse the writes if the file can not be read
+	require.NoError(t, os.WriteFile(path.Join(dir, metaAuthVolsFile), []byte("{"), 0o644))
+	m = &metadataManager{}
+	m.metaAuth.load(dir)
+	require.Equal(t, ErrMetaAccessKeysNotLoaded, m.checkMetaAccessToken(other, packet))
+}
+
+func TestVolWriteStatesLoad(t *testing.T) {
+	dir := t.TempDir()
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	other := &metaPartition{config: &MetaPartitionConfig{PartitionId: 2, VolName: "other"}}
+
+	// no vol is read-only without the file
+	m := &metadataManager{}
+	m.volWriteStates.load(dir)
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+	m.volWriteStates.update(nil, []string{"vol"})
+	m.checkMetaFrozenVolume([]string{"vol"}, mp)
+	require.True(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+
+	// restarted, the vol frozen refuses the writes until the first heartbeat
+	m = &metadataManager{}
+	mp = &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	m.volWriteStates.load(dir)
+	require.True(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaLookup))
+	require.False(t, m.IsForbiddenOp(other, proto.OpMetaCreateInode))
+	m.volWriteStates.update(nil, nil)
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+
+	m = &metadataManager{}
+	m.volWriteStates.load(dir)
+	require.False(t, m.IsForbiddenOp(mp, proto.OpMetaCreateInode))
+
+	// all the vols refuse the writes if the file can not be read
+	require.NoError(t, os.WriteFile(path.Join(dir, volWriteStatesFile), []byte("{"), 0o644))
+	m = &metadataManager{}
+	m.volWriteStates.load(dir)
+	require.True(t, m.IsForbiddenOp(other, proto.OpMetaCreateInode))
+}
@@ -41,8 +41,29 @@ func (m *metadataManager) respondToMaster(task *proto.AdminTask) (err error) {
 	return
 }
 
+// packetCompress is how the data of the responses to the clients accepting it is compressed.
+type packetCompress struct {
+	codec     uint8
+	threshold int // of the data size
+}
+
+func (m *metadataManager) compressResponse(p *Packet) {
+	if !p.AcceptCompress() || m.packetCompress.codec == proto.PacketCompressNone ||
+		int(p.Size) < m.packetCompress.threshold {
+		return
+	}
+	if err := p.CompressData(m.packetCompress.codec); err != nil {
+		log.LogWarnf("compress response failed, request[%s], response packet[%s], err(%v)",
+			p.GetOpMsg(), p.GetResultMsg(), err)
+	}
+}
+
 // Reply data through tcp connection to the client.
 func (m *metadataManager) respondToClientWithVer(conn net.Conn, p *Packet) (err error) {
+	if p.expired() {
+		p.dropExpired("response")
+		return
+	}
 	// Handle panic
 	defer func() {
 		if r := recover(); r != nil {
@@ -59,7 +80,9 @@ func (m *metadataManager) respondToClientWithVer(conn net.Conn, p *Packet) (err
 	if p.VerSeq > 0 {
 		p.ExtentType |= proto.MultiVersionFlag
 	}
+	m.compressResponse(p)
 	err = p.WriteToConn(conn)
+	p.span.Event("responded")
 	if err != nil {
 		log.LogErrorf("response to client[%s], "+
 			"request[%s], response packet[%s]",
@@ -70,6 +93,10 @@ func (m *metadataManager) respondToClientWithVer(conn net.Conn, p *Packet) (err
 
 // Reply data through tcp connection to the client.
 func (m *metadataManager) respondToClient(conn net.Conn, p *Packet) (err error) {
+	if p.expired() {
+		p.dropExpired("response")
+		return
+	}
 	// Handle panic
 	defer func() {
 		if r := recover(); r != nil {
@@ -83,7 +110,9 @@ func (m *metadataManager) respondToClient(conn net.Conn, p *Packet) (err error)
 	}()
 
 	// process data and send reply though specified tcp connection.
+	m.compressResponse(p)
 	err = p.WriteToConn(conn)
+	p.span.Event("responded")
 	if err != nil {
 		log.LogErrorf("response to client[%s], "+
 			"request[%s], response packet[%s]",
@@ -0,0 +1,163 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultOrphanSnapshotExpire = time.Hour
+	snapshotJanitorInterval     = 10 * time.Minute
+)
+
+// OrphanSnapshotDir is a snapshot temp or backup directory left by a failed store.
+type OrphanSnapshotDir struct {
+	PartitionID uint64    `json:"pid"`
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	Removed     bool      `json:"removed"`
+	Reason      string    `json:"reason,omitempty"` // why it is kept
+}
+
+type snapshotJanitor struct {
+	sync.RWMutex
+	expire     time.Duration
+	autoRemove bool
+	lastScan   time.Time
+	orphans    []*OrphanSnapshotDir
+}
+
+// SnapshotJanitorStatus is the result of the last scan.
+type SnapshotJanitorStatus struct {
+	Expire     string               `json:"expire"`
+	AutoRemove bool                 `json:"autoRemove"`
+	LastScan   time.Time            `json:"lastScan"`
+	Orphans    []*OrphanSnapshotDir `json:"orphans"`
+}
+
+func dirSize(dir string) (size int64) {
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return
+}
+
+// cleanOrphanSnapshotDirs finds the snapshot temp and backup directories of the partition
+// which are not modified for expire and removes them if remove is true. They are only
+// orphans when no store is running, and the backup is removed only if the snapshot is intact
+// as it is the last copy of the metadata otherwise.
+func (mp *metaPartition) cleanOrphanSnapshotDirs(expire time.Duration, remove bool) (orphans []*OrphanSnapshotDir) {
+	if !mp.snapshotDirLock.TryLock() {
+		return
+	}
+	defer mp.snapshotDirLock.Unlock()
+
+	now := time.Now()
+	for _, name := range []string{snapshotDirTmp, snapshotBackup} {
+		dir := path.Join(mp.config.RootDir, name)
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() || now.Sub(info.ModTime()) < expire {
+			continue
+		}
+		orphan := &OrphanSnapshotDir{
+			PartitionID: mp.config.PartitionId,
+			Path:        dir,
+			Size:        dirSize(dir),
+			ModTime:     info.ModTime(),
+		}
+		orphans = append(orphans, orphan)
+		if name == snapshotBackup {
+			if _, err = mp.parseCrcFromFile(); err != nil {
+				orphan.Reason = "snapshot is not intact: " + err.Error()
+				log.LogWarnf("[cleanOrphanSnapshotDirs] mp(%v) keep backup dir %v, %v", mp.config.PartitionId, dir, orphan.Reason)
+				continue
+			}
+		}
+		if !remove {
+			orphan.Reason = "auto remove is disabled"
+			continue
+		}
+		if err = os.RemoveAll(dir); err != nil {
+			orphan.Reason = "remove failed: " + err.Error()
+			log.LogErrorf("[cleanOrphanSnapshotDirs] mp(%v) remove %v failed: %v", mp.config.PartitionId, dir, err)
+			continue
+		}
+		orphan.Removed = true
+		log.LogWarnf("[cleanOrphanSnapshotDirs] mp(%v) remove orphan dir %v size(%v) modTime(%v)",
+			mp.config.PartitionId, dir, orphan.Size, orphan.ModTime)
+	}
+	return
+}
+
+func (m *metadataManager) scanOrphanSnapshotDirs() {
+	m.snapshotJanitor.RLock()
+	expire, autoRemove := m.snapshotJanitor.expire, m.snapshotJanitor.autoRemove
+	m.snapshotJanitor.RUnlock()
+
+	partitions := make([]*metaPartition, 0)
+	m.Range(true, func(_ uint64, p MetaPartition) bool {
+		if mp, ok := p.(*metaPartition); ok {
+			partitions = append(partitions, mp)
+		}
+		return true
+	})
+	orphans := make([]*OrphanSnapshotDir, 0)
+	for _, mp := range partitions {
+		orphans = append(orphans, mp.cleanOrphanSnapshotDirs(expire, autoRemove)...)
+	}
+
+	m.snapshotJanitor.Lock()
+	m.snapshotJanitor.lastScan = time.Now()
+	m.snapshotJanitor.orphans = orphans
+	m.snapshotJanitor.Unlock()
+}
+
+func (m *metadataManager) startSnapshotJanitor() {
+	go func() {
+		ticker := time.NewTicker(snapshotJanitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopC:
+				return
+			case <-ticker.C:
+				m.scanOrphanSnapshotDirs()
+			}
+		}
+	}()
+}
+
+// GetSnapshotJanitorStatus returns the orphan snapshot directories found by the last scan.
+func (m *metadataManager) GetSnapshotJanitorStatus() *SnapshotJanitorStatus {
+	m.snapshotJanitor.RLock()
+	defer m.snapshotJanitor.RUnlock()
+	return &SnapshotJanitorStatus{
+		Expire:     m.snapshotJanitor.expire.String(),
+		AutoRemove: m.snapshotJanitor.autoRemove,
+		LastScan:   m.snapshotJanitor.lastScan,
+		Orphans:    m.snapshotJanitor.orphans,
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanOrphanSnapshotDirs(t *testing.T) {
+	rootDir := t.TempDir()
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, RootDir: rootDir}}
+
+	old := time.Now().Add(-2 * time.Hour)
+	mkdir := func(name string) string {
+		dir := path.Join(rootDir, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(path.Join(dir, inodeFile), []byte("data"), 0o644))
+		require.NoError(t, os.Chtimes(dir, old, old))
+		return dir
+	}
+	tmpDir := mkdir(snapshotDirTmp)
+	backupDir := mkdir(snapshotBackup)
+
+	// backup is the last copy without an intact snapshot
+	orphans := mp.cleanOrphanSnapshotDirs(time.Hour, true)
+	require.Len(t, orphans, 2)
+	require.True(t, orphans[0].Removed)
+	require.EqualValues(t, 4, orphans[0].Size)
+	require.False(t, orphans[1].Removed)
+	require.NotEmpty(t, orphans[1].Reason)
+	require.NoDirExists(t, tmpDir)
+	require.DirExists(t, backupDir)
+
+	snapshot := path.Join(rootDir, snapshotDir)
+	require.NoError(t, os.MkdirAll(snapshot, 0o755))
+	require.NoError(t, os.WriteFile(path.Join(snapshot, SnapshotSign), []byte("1 2 3 4"), 0o644))
+
+	require.Empty(t, mp.cleanOrphanSnapshotDirs(3*time.Hour, true))
+
+	orphans = mp.cleanOrphanSnapshotDirs(time.Hour, false)
+	require.Len(t, orphans, 1)
+	require.False(t, orphans[0].Removed)
+	require.DirExists(t, backupDir)
+
+	// a store is running
+	mp.snapshotDirLock.Lock()
+	require.Empty(t, mp.cleanOrphanSnapshotDirs(time.Hour, true))
+	mp.snapshotDirLock.Unlock()
+
+	orphans = mp.cleanOrphanSnapshotDirs(time.Hour, true)
+	require.Len(t, orphans, 1)
+	require.True(t, orphans[0].Removed)
+	require.NoDirExists(t, backupDir)
+	require.DirExists(t, snapshot)
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/cubefs/cubefs/util/fileutil"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the vols in a read-only window or frozen at the last heartbeat
+const volWriteStatesFile = "vol_write_states"
+
+type volWriteStatesInfo struct {
+	ReadOnlyVols   []string `json:"read_only_vols"`
+	MetaFrozenVols []string `json:"meta_frozen_vols"`
+}
+
+// volWriteStates keeps the vols in a read-only window or frozen told by master. The flags of
+// the partitions are set by the heartbeats only, so after a restart the vols read-only or
+// frozen before refuse the modifications until the first heartbeat, all the vols do if the
+// file of them is there but can not be read.
+type volWriteStates struct {
+	sync.RWMutex
+	dir        string
+	info       volWriteStatesInfo
+	pending    map[string]bool // the vols read-only or frozen before the restart, until the first heartbeat
+	pendingAll bool            // the vols read-only or frozen before the restart can not be read
+}
+
+// load takes the vols read-only or frozen before the restart from dir.
+func (s *volWriteStates) load(dir string) {
+	s.Lock()
+	defer s.Unlock()
+	s.dir, s.pending, s.pendingAll = dir, nil, false
+	data, err := os.ReadFile(path.Join(dir, volWriteStatesFile))
+	if os.IsNotExist(err) {
+		return
+	}
+	s.pendingAll = true
+	if err != nil {
+		log.LogErrorf("[volWriteStates] load the vols read-only or frozen, err(%v)", err)
+		return
+	}
+	var info volWriteStatesInfo
+	if err = json.Unmarshal(data, &info); err != nil {
+		log.LogErrorf("[volWriteStates] load the vols read-only or frozen, err(%v)", err)
+		return
+	}
+	s.info = info
+	s.pending = make(map[string]bool, len(info.ReadOnlyVols)+len(info.MetaFrozenVols))
+	for _, vol := range info.ReadOnlyVols {
+		s.pending[vol] = true
+	}
+	for _, vol := range info.MetaFrozenVols {
+		s.pending[vol] = true
+	}
+	s.pendingAll = false
+	if len(s.pending) > 0 {
+		log.LogWarnf("[volWriteStates] vols(%v) refuse the modifications until the first heartbeat", info)
+	}
+}
+
+// persist keeps the vols of info. The lock must be held.
+func (s *volWriteStates) persist(info volWriteStatesInfo) (err error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	name := path.Join(s.dir, volWriteStatesFile)
+	if err = fileutil.WriteFileWithSync(name+".tmp", data, 0o644); err != nil {
+		return
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// update takes the vols read-only or frozen told by the heartbeat of master.
+func (s *volWriteStates) update(readOnlyVols, metaFrozenVols []string) {
+	info := volWriteStatesInfo{
+		ReadOnlyVols:   append([]string{}, readOnlyVols...),
+		MetaFrozenVols: append([]string{}, metaFrozenVols...),
+	}
+	sort.Strings(info.ReadOnlyVols)
+	sort.Strings(info.MetaFrozenVols)
+
+	s.Lock()
+	defer s.Unlock()
+	loading := s.pendingAll || s.pending != nil
+	if !loading && reflect.DeepEqual(s.info, info) {
+		return
+	}
+	if s.dir != "" {
+		if err := s.persist(info); err != nil {
+			log.LogErrorf("[volWriteStates] persist the vols read-only or frozen, err(%v)", err)
+		}
+	}
+	s.info, s.pending, s.pendingAll = info, nil, false
+}
+
+// isPending returns if the vol was read-only or frozen before the restart and the first
+// heartbeat has not come yet.
+func (s *volWriteStates) isPending(vol string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.pendingAll || s.pending[vol]
+}
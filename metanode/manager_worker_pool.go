@@ -0,0 +1,178 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+var (
+	// the request waits for a worker of its volume up to it, the client retries it after
+	volWorkerWaitTimeout = 3 * time.Second
+
+	ErrVolWorkersBusy = errors.New("request workers of the volume are busy")
+)
+
+// volWorkerPools bounds the requests of each volume processed at the same time, so that a hot
+// volume does not starve the others. The workers of the node are shared by the volumes of the
+// partitions on it by their weights set on master.
+type volWorkerPools struct {
+	sync.RWMutex
+	size    int                      // workers of the node, 0 disables the pools
+	weights map[string]int32         // of the volumes not of proto.DefaultMetaWorkerWeight
+	pools   map[string]chan struct{} // of the volumes, a request holds a slot while processed
+}
+
+// VolWorkerPoolStat is the worker pool of a volume on the node.
+type VolWorkerPoolStat struct {
+	VolName string `json:"vol"`
+	Weight  int32  `json:"weight"`
+	Workers int    `json:"workers"`
+	Running int    `json:"running"`
+}
+
+func newVolWorkerPools(size int) *volWorkerPools {
+	return &volWorkerPools{
+		size:    size,
+		weights: make(map[string]int32),
+		pools:   make(map[string]chan struct{}),
+	}
+}
+
+func (w *volWorkerPools) weightLocked(vol string) int32 {
+	if weight, ok := w.weights[vol]; ok && weight > 0 {
+		return weight
+	}
+	return proto.DefaultMetaWorkerWeight
+}
+
+// resizeLocked shares the workers by the weights of the volumes with pools. The requests
+// processed keep the slots of the pool they are in, a resized pool takes new requests only.
+func (w *volWorkerPools) resizeLocked() {
+	var sum int64
+	for vol := range w.pools {
+		sum += int64(w.weightLocked(vol))
+	}
+	for vol, pool := range w.pools {
+		workers := int(int64(w.size) * int64(w.weightLocked(vol)) / sum)
+		if workers < 1 {
+			workers = 1
+		}
+		if cap(pool) != workers {
+			w.pools[vol] = make(chan struct{}, workers)
+		}
+	}
+}
+
+func (w *volWorkerPools) getPool(vol string) chan struct{} {
+	w.RLock()
+	pool, ok := w.pools[vol]
+	w.RUnlock()
+	if ok {
+		return pool
+	}
+	w.Lock()
+	defer w.Unlock()
+	if pool, ok = w.pools[vol]; !ok {
+		w.pools[vol] = nil
+		w.resizeLocked()
+		pool = w.pools[vol]
+	}
+	return pool
+}
+
+// acquire waits for a worker of the volume, release is called once the request is processed.
+func (w *volWorkerPools) acquire(vol string) (release func(), err error) {
+	if w == nil || w.size <= 0 {
+		return func() {}, nil
+	}
+	pool := w.getPool(vol)
+	select {
+	case pool <- struct{}{}:
+		return func() { <-pool }, nil
+	default:
+	}
+	timer := time.NewTimer(volWorkerWaitTimeout)
+	defer timer.Stop()
+	select {
+	case pool <- struct{}{}:
+		return func() { <-pool }, nil
+	case <-timer.C:
+		return nil, ErrVolWorkersBusy
+	}
+}
+
+// update sets the weights of the volumes from master, and drops the pools of the volumes not
+// in vols any more.
+func (w *volWorkerPools) update(weights map[string]int32, vols map[string]struct{}) {
+	if w == nil || w.size <= 0 {
+		return
+	}
+	w.Lock()
+	defer w.Unlock()
+	changed := false
+	for vol := range w.pools {
+		if _, ok := vols[vol]; !ok {
+			delete(w.pools, vol)
+			changed = true
+		}
+	}
+	if len(weights) != len(w.weights) {
+		changed = true
+	}
+	for vol, weight := range weights {
+		if w.weights[vol] != weight {
+			log.LogInfof("[volWorkerPools] vol(%v) weight from %v to %v", vol, w.weights[vol], weight)
+			changed = true
+		}
+	}
+	if weights == nil {
+		weights = make(map[string]int32)
+	}
+	w.weights = weights
+	if changed {
+		w.resizeLocked()
+	}
+}
+
+func (w *volWorkerPools) stats() (stats []VolWorkerPoolStat) {
+	stats = make([]VolWorkerPoolStat, 0)
+	if w == nil {
+		return
+	}
+	w.RLock()
+	defer w.RUnlock()
+	for vol, pool := range w.pools {
+		stats = append(stats, VolWorkerPoolStat{
+			VolName: vol,
+			Weight:  w.weightLocked(vol),
+			Workers: cap(pool),
+			Running: len(pool),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].VolName < stats[j].VolName })
+	return
+}
+
+// GetVolWorkerPools returns the worker pools of the volumes on the node.
+func (m *metadataManager) GetVolWorkerPools() []VolWorkerPoolStat {
+	return m.volWorkers.stats()
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolWorkerPools(t *testing.T) {
+	old := volWorkerWaitTimeout
+	volWorkerWaitTimeout = 10 * time.Millisecond
+	defer func() { volWorkerWaitTimeout = old }()
+
+	// disabled pools take every request
+	release, err := newVolWorkerPools(0).acquire("vol")
+	require.NoError(t, err)
+	release()
+	var nilPools *volWorkerPools
+	_, err = nilPools.acquire("vol")
+	require.NoError(t, err)
+
+	w := newVolWorkerPools(10)
+	workers := func() map[string]int {
+		m := make(map[string]int)
+		for _, stat := range w.stats() {
+			m[stat.VolName] = stat.Workers
+		}
+		return m
+	}
+	release, err = w.acquire("cold")
+	require.NoError(t, err)
+	hot := make([]func(), 0)
+	for i := 0; i < 5; i++ {
+		release, err := w.acquire("hot")
+		require.NoError(t, err)
+		hot = append(hot, release)
+	}
+	require.Equal(t, map[string]int{"hot": 5, "cold": 5}, workers())
+	// the hot volume has all its workers busy, the cold one still has workers
+	_, err = w.acquire("hot")
+	require.ErrorIs(t, err, ErrVolWorkersBusy)
+	release()
+	for _, release := range hot {
+		release()
+	}
+
+	vols := map[string]struct{}{"hot": {}, "cold": {}, "other": {}}
+	w.getPool("other")
+	w.update(map[string]int32{"hot": 3}, vols)
+	require.Equal(t, map[string]int{"hot": 6, "cold": 2, "other": 2}, workers())
+	stats := w.stats()
+	require.Equal(t, "hot", stats[1].VolName)
+	require.EqualValues(t, 3, stats[1].Weight)
+
+	// the pools of the volumes no longer on the node are dropped
+	delete(vols, "other")
+	w.update(nil, vols)
+	require.Equal(t, map[string]int{"hot": 5, "cold": 5}, workers())
+}
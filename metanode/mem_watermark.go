@@ -0,0 +1,160 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultMemFreezeHighWatermark = 0.95
+	defaultMemFreezeLowWatermark  = 0.90
+	memWatermarkCheckInterval     = 5 * time.Second
+)
+
+// memWatermark freezes the partitions of the node when the memory used by the process reaches
+// high of configTotalMem, and unfreezes them after it drops under low.
+type memWatermark struct {
+	high   float64
+	low    float64
+	frozen int32
+	stopC  chan struct{}
+}
+
+func newMemWatermark(high, low float64) *memWatermark {
+	return &memWatermark{high: high, low: low, stopC: make(chan struct{})}
+}
+
+func (w *memWatermark) isFrozen() bool {
+	return atomic.LoadInt32(&w.frozen) == 1
+}
+
+// check updates the freeze state by the used memory of total and returns it.
+func (w *memWatermark) check(used, total uint64) bool {
+	if w.high <= 0 || total == 0 {
+		return false
+	}
+	ratio := float64(used) / float64(total)
+	frozen := w.isFrozen()
+	if !frozen && ratio >= w.high {
+		log.LogWarnf("[memWatermark] freeze partitions, used mem %v of total %v reaches high watermark %v", used, total, w.high)
+		atomic.StoreInt32(&w.frozen, 1)
+		return true
+	}
+	if frozen && ratio < w.low {
+		log.LogWarnf("[memWatermark] unfreeze partitions, used mem %v of total %v drops under low watermark %v", used, total, w.low)
+		atomic.StoreInt32(&w.frozen, 0)
+		return false
+	}
+	return frozen
+}
+
+func (m *MetaNode) startMemWatermark() {
+	if m.memWatermark.high <= 0 {
+		log.LogInfo("[startMemWatermark] memory watermark freeze is disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(memWatermarkCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.memWatermark.stopC:
+				log.LogInfo("[startMemWatermark] stopped")
+				return
+			case <-ticker.C:
+				used, err := util.GetProcessMemory(os.Getpid())
+				if err != nil {
+					log.LogErrorf("[startMemWatermark] get process memory failed: %v", err)
+					continue
+				}
+				frozen := m.memWatermark.check(used, configTotalMem)
+				manager, ok := m.metadataManager.(*metadataManager)
+				if !ok {
+					continue
+				}
+				manager.Range(true, func(_ uint64, mp MetaPartition) bool {
+					mp.SetMemFrozen(frozen)
+					return true
+				})
+			}
+		}
+	}()
+}
+
+func (m *MetaNode) stopMemWatermark() {
+	if m.memWatermark != nil && m.memWatermark.high > 0 {
+		close(m.memWatermark.stopC)
+	}
+}
+
+// isMemGrowingOp returns whether the op adds items to the trees of a partition, they are
+// rejected while the partition is frozen by the memory watermark.
+func isMemGrowingOp(op uint8) bool {
+	switch op {
+	case proto.OpMetaCreateDentry,
+		proto.OpMetaTxCreateDentry,
+		proto.OpQuotaCreateDentry,
+		proto.OpMetaCreateInode,
+		proto.OpQuotaCreateInode,
+		proto.OpMetaTxCreateInode,
+		proto.OpMetaBatchCreateDentryInode,
+		proto.OpMetaLinkInode,
+		proto.OpMetaTxLinkInode,
+		proto.OpMetaExtentsAdd,
+		proto.OpMetaExtentAddWithCheck,
+		proto.OpMetaObjExtentAdd,
+		proto.OpMetaBatchObjExtentsAdd,
+		proto.OpMetaBatchExtentsAdd,
+		proto.OpMetaSetXAttr,
+		proto.OpMetaBatchSetXAttr,
+		proto.OpMetaUpdateXAttr,
+		proto.OpCreateMultipart,
+		proto.OpAddMultipartPart,
+		proto.OpMetaTxCreate:
+		return true
+	default:
+		return false
+	}
+}
+
+func isCreateInodeOp(op uint8) bool {
+	switch op {
+	case proto.OpMetaCreateInode, proto.OpQuotaCreateInode, proto.OpMetaTxCreateInode:
+		return true
+	default:
+		return false
+	}
+}
+
+func (mp *metaPartition) IsMemFrozen() bool {
+	return atomic.LoadInt32(&mp.memFrozen) == 1
+}
+
+func (mp *metaPartition) SetMemFrozen(frozen bool) {
+	var v int32
+	if frozen {
+		v = 1
+	}
+	if atomic.SwapInt32(&mp.memFrozen, v) != v {
+		log.LogWarnf("[SetMemFrozen] mp(%v) vol(%v) memory frozen(%v)", mp.config.PartitionId, mp.config.VolName, frozen)
+	}
+}
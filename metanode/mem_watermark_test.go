@@ -0,0 +1,49 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemWatermark(t *testing.T) {
+	w := newMemWatermark(0.9, 0.8)
+	require.False(t, w.check(85, 100))
+	require.True(t, w.check(90, 100))
+	// keep frozen until the used memory drops under the low watermark
+	require.True(t, w.check(85, 100))
+	require.False(t, w.check(79, 100))
+	require.False(t, w.check(85, 100))
+
+	require.False(t, w.check(90, 0))
+	require.False(t, newMemWatermark(0, 0).check(100, 100))
+}
+
+func TestMetaPartitionMemFrozen(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	require.False(t, mp.IsMemFrozen())
+	mp.SetMemFrozen(true)
+	require.True(t, mp.IsMemFrozen())
+	mp.SetMemFrozen(false)
+	require.False(t, mp.IsMemFrozen())
+
+	require.True(t, isMemGrowingOp(proto.OpMetaCreateInode))
+	require.True(t, isMemGrowingOp(proto.OpMetaExtentsAdd))
+	require.False(t, isMemGrowingOp(proto.OpMetaUnlinkInode))
+	require.False(t, isMemGrowingOp(proto.OpMetaInodeGet))
+}
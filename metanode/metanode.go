@@ -18,6 +18,7 @@ import (
 	"fmt"
 	syslog "log"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -34,6 +35,7 @@ import (
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/tracing"
 )
 
 var (
@@ -90,6 +92,13 @@ type MetaNode struct {
 	VolsForbidWriteOpOfProtoVer0       map[string]struct{} // whether forbid by volume granularity,
 	qosEnable                          bool
 	readDirIops                        int
+	clientLane                         *opLane
+	adminLane                          *opLane
+	memWatermark                       *memWatermark
+	gcTuner                            *gcTuner
+	drainer                            nodeDrainer
+	tracer                             *tracing.Tracer
+	mediaType                          uint32 // of the disk of metadataDir, master places the partitions of a vol by it
 
 	control common.Control
 }
@@ -175,6 +184,8 @@ func doStart(s common.Server, cfg *config.Config) (err error) {
 	}
 
 	go m.startUpdateNodeInfo()
+	m.startMemWatermark()
+	m.startGCTuner()
 
 	m.startStat()
 
@@ -196,6 +207,9 @@ func doShutdown(s common.Server) {
 		return
 	}
 	m.stopUpdateNodeInfo()
+	m.stopMemWatermark()
+	m.stopGCTuner()
+	m.stopDrain()
 	// shutdown node and release the resource
 	m.stopStat()
 	m.stopServer()
@@ -283,6 +297,64 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 	syslog.Printf("conf qosEnable=%v readDirIops=%v", m.qosEnable, m.readDirIops)
 	log.LogInfof("[parseConfig] qosEnable[%v] readDirIops[%v]", m.qosEnable, m.readDirIops)
 
+	clientOpWorkers := cfg.GetIntWithDefault(cfgClientOpWorkers, defaultClientOpWorkers)
+	if clientOpWorkers < 0 {
+		clientOpWorkers = defaultClientOpWorkers
+	}
+	adminOpWorkers := cfg.GetIntWithDefault(cfgAdminOpWorkers, defaultAdminOpWorkers)
+	if adminOpWorkers <= 0 {
+		adminOpWorkers = defaultAdminOpWorkers
+	}
+	m.clientLane = newOpLane(laneClient, clientOpWorkers)
+	m.adminLane = newOpLane(laneAdmin, adminOpWorkers)
+	syslog.Printf("conf clientOpWorkers=%v adminOpWorkers=%v", clientOpWorkers, adminOpWorkers)
+	log.LogInfof("[parseConfig] clientOpWorkers[%v] adminOpWorkers[%v]", clientOpWorkers, adminOpWorkers)
+
+	traceSampleRate := cfg.GetFloat(cfgTraceSampleRate)
+	m.tracer = tracing.NewTracer("metanode", traceSampleRate, tracing.DefaultRecentSpans)
+	syslog.Printf("conf traceSampleRate=%v", m.tracer.SampleRate())
+	log.LogInfof("[parseConfig] traceSampleRate[%v]", m.tracer.SampleRate())
+
+	if cfg.HasKey(cfgMediaType) {
+		var mediaType uint32
+		if err, mediaType = cfg.GetUint32(cfgMediaType); err != nil {
+			return fmt.Errorf("parseConfig: parse configKey[%v] err: %v", cfgMediaType, err.Error())
+		}
+		if !proto.IsValidMediaType(mediaType) {
+			return fmt.Errorf("parseConfig: invalid mediaType(%v)", mediaType)
+		}
+		m.mediaType = mediaType
+	}
+	syslog.Printf("conf mediaType=%v", proto.MediaTypeString(m.mediaType))
+	log.LogInfof("[parseConfig] mediaType[%v]", proto.MediaTypeString(m.mediaType))
+
+	memFreezeHigh := defaultMemFreezeHighWatermark
+	if cfg.HasKey(cfgMemFreezeHighWatermark) {
+		memFreezeHigh = cfg.GetFloat(cfgMemFreezeHighWatermark)
+	}
+	memFreezeLow := defaultMemFreezeLowWatermark
+	if cfg.HasKey(cfgMemFreezeLowWatermark) {
+		memFreezeLow = cfg.GetFloat(cfgMemFreezeLowWatermark)
+	}
+	if memFreezeLow > memFreezeHigh {
+		memFreezeLow = memFreezeHigh
+	}
+	m.memWatermark = newMemWatermark(memFreezeHigh, memFreezeLow)
+	syslog.Printf("conf memFreezeHighWatermark=%v memFreezeLowWatermark=%v", memFreezeHigh, memFreezeLow)
+	log.LogInfof("[parseConfig] memFreezeHighWatermark[%v] memFreezeLowWatermark[%v]", memFreezeHigh, memFreezeLow)
+
+	adaptiveGOGCMemRatio := cfg.GetFloat(cfgAdaptiveGOGCMemRatio)
+	if adaptiveGOGCMemRatio < 0 || adaptiveGOGCMemRatio > 1 {
+		return fmt.Errorf("bad adaptiveGOGCMemRatio config, must be in [0, 1]")
+	}
+	gcBallastMB := cfg.GetInt64(cfgGCBallastMB)
+	if gcBallastMB < 0 {
+		gcBallastMB = 0
+	}
+	m.gcTuner = newGCTuner(adaptiveGOGCMemRatio, uint64(gcBallastMB)*util.MB)
+	syslog.Printf("conf adaptiveGOGCMemRatio=%v gcBallastMB=%v", adaptiveGOGCMemRatio, gcBallastMB)
+	log.LogInfof("[parseConfig] adaptiveGOGCMemRatio[%v] gcBallastMB[%v]", adaptiveGOGCMemRatio, gcBallastMB)
+
 	raftRetainLogs := cfg.GetString(cfgRetainLogs)
 	if raftRetainLogs != "" {
 		if m.raftRetainLogs, err = strconv.ParseUint(raftRetainLogs, 10, 64); err != nil {
@@ -297,7 +369,7 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 
 	if cfg.HasKey(cfgRaftSyncSnapFormatVersion) {
 		raftSyncSnapFormatVersion := uint32(cfg.GetInt64(cfgRaftSyncSnapFormatVersion))
-		if raftSyncSnapFormatVersion > SnapFormatVersion_1 {
+		if raftSyncSnapFormatVersion > SnapFormatVersion_2 {
 			m.raftSyncSnapFormatVersion = SnapFormatVersion_1
 			log.LogInfof("invalid config raftSyncSnapFormatVersion, using default[%v]", m.raftSyncSnapFormatVersion)
 		} else {
@@ -337,6 +409,12 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 		smuxPool = util.NewSmuxConnectPool(smuxPoolCfg)
 	}
 
+	extentDeleteBatchSize := cfg.GetIntWithDefault(cfgExtentDeleteBatchSize, defaultExtentDeleteBatchSize)
+	extentDeleteFlushMs := cfg.GetIntWithDefault(cfgExtentDeleteFlushMs, int(defaultExtentDeleteFlushInterval.Milliseconds()))
+	extentDeleter = newExtentDeleteBatcher(extentDeleteBatchSize, time.Duration(extentDeleteFlushMs)*time.Millisecond)
+	syslog.Printf("conf extentDeleteBatchSize=%v extentDeleteFlushMs=%v", extentDeleter.batchSize, extentDeleter.interval.Milliseconds())
+	log.LogInfof("[parseConfig] extentDeleteBatchSize[%v] extentDeleteFlushMs[%v]", extentDeleter.batchSize, extentDeleter.interval.Milliseconds())
+
 	addrs := cfg.GetSlice(proto.MasterAddr)
 	masters := make([]string, 0, len(addrs))
 	for _, addr := range addrs {
@@ -462,6 +540,88 @@ func (m *MetaNode) newMetaManager(cfg *config.Config) (err error) {
 		}
 	}
 
+	failOverWindow, err := parseFailOverWindow(cfg.GetString(cfgFailOverWindow))
+	if err != nil {
+		log.LogError(err.Error())
+		return err
+	}
+	leaderTransferPerSec := defaultLeaderTransferPerSec
+	if cfg.HasKey(cfgLeaderTransferPerSec) {
+		leaderTransferPerSec = cfg.GetInt(cfgLeaderTransferPerSec)
+	}
+	log.LogInfof("[newMetaManager] leaderTransferPerSec[%v] failOverWindow[%v]", leaderTransferPerSec, failOverWindow)
+
+	orphanSnapshotExpire := defaultOrphanSnapshotExpire
+	if sec := cfg.GetInt64(cfgOrphanSnapshotExpireSec); sec > 0 {
+		orphanSnapshotExpire = time.Duration(sec) * time.Second
+	}
+	removeOrphanSnapshotDirs := cfg.GetBoolWithDefault(cfgRemoveOrphanSnapshotDirs, true)
+	log.LogInfof("[newMetaManager] orphanSnapshotExpire[%v] removeOrphanSnapshotDirs[%v]",
+		orphanSnapshotExpire, removeOrphanSnapshotDirs)
+
+	xattrLimit := proto.XAttrLimit{
+		MaxCount:     uint32(cfg.GetInt64(cfgXAttrMaxCount)),
+		MaxKeySize:   uint32(cfg.GetInt64(cfgXAttrMaxKeySize)),
+		MaxValueSize: uint32(cfg.GetInt64(cfgXAttrMaxValueSize)),
+		MaxTotalSize: uint64(cfg.GetInt64(cfgXAttrMaxTotalSize)),
+	}
+	log.LogInfof("[newMetaManager] xattrLimit[%+v]", xattrLimit)
+
+	opAuditDir := cfg.GetString(cfgOpAuditDir)
+	if opAuditDir == "" {
+		opAuditDir = path.Join(m.metadataDir, defaultOpAuditDirName)
+	}
+	opAuditLogMaxSize := cfg.GetInt64(cfgOpAuditLogMaxSize)
+	if opAuditLogMaxSize <= 0 {
+		opAuditLogMaxSize = defaultOpAuditLogMaxSize
+	}
+	log.LogInfof("[newMetaManager] opAuditDir[%v] opAuditLogMaxSize[%v]", opAuditDir, opAuditLogMaxSize)
+	heartbeatFullReportInterval := int(cfg.GetInt64(cfgHbFullReportInterval))
+	log.LogInfof("[newMetaManager] heartbeatFullReportInterval[%v]", heartbeatFullReportInterval)
+	extentMergeThreshold := cfg.GetIntWithDefault(cfgExtentMergeThreshold, defaultExtentMergeThreshold)
+	if extentMergeThreshold <= 0 {
+		extentMergeThreshold = defaultExtentMergeThreshold
+	}
+	extentMergeConcurrency := defaultExtentMergeConcurrency
+	if cfg.HasKey(cfgExtentMergeConcurrency) {
+		extentMergeConcurrency = cfg.GetInt(cfgExtentMergeConcurrency)
+	}
+	log.LogInfof("[newMetaManager] extentMergeThreshold[%v] extentMergeConcurrency[%v]",
+		extentMergeThreshold, extentMergeConcurrency)
+	snapshotSendRateMB := int(cfg.GetInt64(cfgSnapshotSendRateMB))
+	snapshotRecvRateMB := int(cfg.GetInt64(cfgSnapshotRecvRateMB))
+	snapshotPartitionRateMB := int(cfg.GetInt64(cfgSnapshotPartRateMB))
+	log.LogInfof("[newMetaManager] snapshotSendRateMB[%v]", snapshotSendRateMB)
+	packetCompressCodec := proto.PacketCompressZstd
+	if cfg.HasKey(cfgPacketCompressCodec) {
+		if packetCompressCodec, err = proto.ParsePacketCompressCodec(cfg.GetString(cfgPacketCompressCodec)); err != nil {
+			return
+		}
+	}
+	packetCompressThreshold := int(cfg.GetInt64(cfgPacketCompressThreshold))
+	if packetCompressThreshold <= 0 {
+		packetCompressThreshold = proto.DefaultPacketCompressThreshold
+	}
+	log.LogInfof("[newMetaManager] packetCompressCodec[%v] packetCompressThreshold[%v]",
+		packetCompressCodec, packetCompressThreshold)
+	volWorkerPoolSize := int(cfg.GetInt64(cfgVolWorkerPoolSize))
+	log.LogInfof("[newMetaManager] volWorkerPoolSize[%v]", volWorkerPoolSize)
+	priorityWorkerPoolSize := int(cfg.GetInt64(cfgPriorityWorkerPoolSize))
+	interactivePriorityWeight := int(cfg.GetInt64(cfgInteractiveWeight))
+	log.LogInfof("[newMetaManager] priorityWorkerPoolSize[%v] interactivePriorityWeight[%v]",
+		priorityWorkerPoolSize, interactivePriorityWeight)
+	applyRateMB := int(cfg.GetInt64(cfgApplyRateMB))
+	applyRecoveryReserve := int(cfg.GetInt64(cfgApplyRecoveryReserve))
+	applyRecoveryCap := int(cfg.GetInt64(cfgApplyRecoveryCap))
+	log.LogInfof("[newMetaManager] applyRateMB[%v] applyRecoveryReserve[%v] applyRecoveryCap[%v]",
+		applyRateMB, applyRecoveryReserve, applyRecoveryCap)
+	followerReadMaxLag := uint64(cfg.GetInt64(cfgFollowerReadMaxLag))
+	followerReadMaxLagMs := cfg.GetInt64(cfgFollowerReadMaxLagMs)
+	log.LogInfof("[newMetaManager] followerReadMaxLag[%v] followerReadMaxLagMs[%v]",
+		followerReadMaxLag, followerReadMaxLagMs)
+	atomic.StoreUint32(&nsEventRingSize, uint32(cfg.GetInt64(cfgNsEventRingSize)))
+	log.LogInfof("[newMetaManager] nsEventRingSize[%v]", atomic.LoadUint32(&nsEventRingSize))
+
 	// load metadataManager
 	conf := MetadataManagerConfig{
 		NodeID:           m.nodeId,
@@ -470,6 +630,39 @@ func (m *MetaNode) newMetaManager(cfg *config.Config) (err error) {
 		ZoneName:         m.zoneName,
 		EnableGcTimer:    cfg.GetBoolWithDefault(cfgEnableGcTimer, false),
 		GcRecyclePercent: gcRecyclePercent,
+
+		LeaderTransferPerSec: leaderTransferPerSec,
+		FailOverWindow:       failOverWindow,
+
+		OrphanSnapshotExpire:     orphanSnapshotExpire,
+		RemoveOrphanSnapshotDirs: removeOrphanSnapshotDirs,
+		XAttrLimit:               xattrLimit,
+		OpAuditDir:               opAuditDir,
+		OpAuditLogMaxSize:        opAuditLogMaxSize,
+
+		HeartbeatFullReportInterval: heartbeatFullReportInterval,
+
+		ExtentMergeThreshold:   extentMergeThreshold,
+		ExtentMergeConcurrency: extentMergeConcurrency,
+
+		SnapshotSendRateMB:      snapshotSendRateMB,
+		SnapshotRecvRateMB:      snapshotRecvRateMB,
+		SnapshotPartitionRateMB: snapshotPartitionRateMB,
+
+		PacketCompressCodec:     packetCompressCodec,
+		PacketCompressThreshold: packetCompressThreshold,
+
+		VolWorkerPoolSize: volWorkerPoolSize,
+
+		PriorityWorkerPoolSize:    priorityWorkerPoolSize,
+		InteractivePriorityWeight: interactivePriorityWeight,
+
+		ApplyRateMB:          applyRateMB,
+		ApplyRecoveryReserve: applyRecoveryReserve,
+		ApplyRecoveryCap:     applyRecoveryCap,
+
+		FollowerReadMaxLag:   followerReadMaxLag,
+		FollowerReadMaxLagMs: followerReadMaxLagMs,
 	}
 	m.metadataManager = NewMetadataManager(conf, m)
 	return
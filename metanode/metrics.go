@@ -15,6 +15,9 @@
 package metanode
 
 import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/cubefs/cubefs/util"
@@ -24,12 +27,27 @@ import (
 // metrics
 const (
 	StatPeriod = time.Minute * time.Duration(1)
+	// the pending proposals pile up and drain within seconds, so they are sampled
+	// more often than the other partition metrics
+	ProposalStatPeriod = time.Second * time.Duration(5)
 
 	MetricMetaFailedPartition      = "meta_failed_partition"
 	MetricMetaPartitionInodeCount  = "mpInodeCount"
 	MetricMetaPartitionDentryCount = "mpDentryCount"
 	MetricConnectionCount          = "connectionCnt"
 	MetricFileStats                = "fileStats"
+	MetricProposalPending          = "mpProposalPending"
+	MetricLaneQueued               = "laneQueued"
+	MetricLaneRunning              = "laneRunning"
+	MetricGCPause                  = "gcPauseNs"
+	MetricGOGC                     = "gogc"
+	MetricExpiredDropped           = "expiredReqDropped"
+	MetricExtentDeleteBacklog      = "extentDeleteBacklog"
+	MetricCrossVolumeLink          = "crossVolumeLink"
+	MetricApplyBytes               = "applyBytes"
+	MetricApplyWaitMs              = "applyWaitMs"
+	MetricVolOpRateLimited         = "volOpRateLimited"
+	MetricFollowerReadStale        = "followerReadStale"
 )
 
 type MetaNodeMetrics struct {
@@ -38,6 +56,12 @@ type MetaNodeMetrics struct {
 	MetricMetaPartitionInodeCount  *exporter.GaugeVec
 	MetricMetaPartitionDentryCount *exporter.GaugeVec
 	MetricFileStats                *exporter.GaugeVec
+	MetricProposalPending          *exporter.GaugeVec
+	MetricLaneQueued               *exporter.GaugeVec
+	MetricLaneRunning              *exporter.GaugeVec
+	MetricGCPause                  *exporter.GaugeVec
+	MetricGOGC                     *exporter.Gauge
+	MetricExtentDeleteBacklog      *exporter.Gauge
 
 	metricStopCh chan struct{}
 }
@@ -51,6 +75,12 @@ func (m *MetaNode) startStat() {
 		MetricMetaPartitionInodeCount:  exporter.NewGaugeVec(MetricMetaPartitionInodeCount, "", []string{"volName"}),
 		MetricMetaPartitionDentryCount: exporter.NewGaugeVec(MetricMetaPartitionDentryCount, "", []string{"volName"}),
 		MetricFileStats:                exporter.NewGaugeVec(MetricFileStats, "", []string{"volName", "sizeRange"}),
+		MetricProposalPending:          exporter.NewGaugeVec(MetricProposalPending, "", []string{"volName", "partid"}),
+		MetricLaneQueued:               exporter.NewGaugeVec(MetricLaneQueued, "", []string{"lane"}),
+		MetricLaneRunning:              exporter.NewGaugeVec(MetricLaneRunning, "", []string{"lane"}),
+		MetricGCPause:                  exporter.NewGaugeVec(MetricGCPause, "", []string{"quantile"}),
+		MetricGOGC:                     exporter.NewGauge(MetricGOGC),
+		MetricExtentDeleteBacklog:      exporter.NewGauge(MetricExtentDeleteBacklog),
 	}
 
 	go m.collectPartitionMetrics()
@@ -90,8 +120,29 @@ func (m *MetaNode) updatePartitionMetrics() {
 	}
 }
 
+func (m *MetaNode) updateProposalMetrics() {
+	m.metrics.MetricProposalPending.Reset()
+
+	manager, ok := m.metadataManager.(*metadataManager)
+	if !ok {
+		return
+	}
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	for _, p := range manager.partitions {
+		mp, ok := p.(*metaPartition)
+		if !ok {
+			continue
+		}
+		m.metrics.MetricProposalPending.SetWithLabelValues(float64(atomic.LoadInt64(&mp.proposalStat.pending)),
+			mp.config.VolName, strconv.FormatUint(mp.config.PartitionId, 10))
+	}
+}
+
 func (m *MetaNode) collectPartitionMetrics() {
 	ticker := time.NewTicker(StatPeriod)
+	proposalTicker := time.NewTicker(ProposalStatPeriod)
 	fileStatTicker := time.NewTicker(fileStatsCheckPeriod)
 	for {
 		select {
@@ -100,12 +151,41 @@ func (m *MetaNode) collectPartitionMetrics() {
 		case <-ticker.C:
 			m.updatePartitionMetrics()
 			m.metrics.MetricConnectionCount.Set(float64(m.connectionCnt))
+			m.updateLaneMetrics()
+			m.updateGCMetrics()
+			if extentDeleter != nil {
+				m.metrics.MetricExtentDeleteBacklog.Set(float64(extentDeleter.Backlog()))
+			}
+		case <-proposalTicker.C:
+			m.updateProposalMetrics()
 		case <-fileStatTicker.C:
 			m.updateFileStatsMetrics()
 		}
 	}
 }
 
+func (m *MetaNode) updateLaneMetrics() {
+	for _, lane := range []*opLane{m.clientLane, m.adminLane} {
+		if lane == nil {
+			continue
+		}
+		m.metrics.MetricLaneQueued.SetWithLabelValues(float64(lane.queuedCount()), lane.name)
+		m.metrics.MetricLaneRunning.SetWithLabelValues(float64(lane.runningCount()), lane.name)
+	}
+}
+
+func (m *MetaNode) updateGCMetrics() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	pauses := recentGCPauses(&stats)
+	for _, q := range gcPauseQuantiles {
+		m.metrics.MetricGCPause.SetWithLabelValues(float64(pauseQuantile(pauses, q.q)), q.label)
+	}
+	if manager, ok := m.metadataManager.(*metadataManager); ok {
+		m.metrics.MetricGOGC.Set(float64(manager.gogcValue))
+	}
+}
+
 func (m *MetaNode) updateFileStatsMetrics() {
 	m.metrics.MetricFileStats.Reset()
 	volFileRange := make(map[string][]int64)
@@ -168,3 +168,39 @@ func TestMultipartExtend_Bytes(t *testing.T) {
 		t.Fatalf("result mismatch:\n\tme1:%v\n\tme2:%v", me, me2)
 	}
 }
+
+func TestListMultipartWithPrefixAndMarker(t *testing.T) {
+	mp := &metaPartition{multipartTree: NewBtree()}
+	for _, key := range []string{"a/1", "b/1", "b/2", "b/2", "b/3", "c/1"} {
+		mp.multipartTree.ReplaceOrInsert(&Multipart{key: key, id: util.RandomString(8, util.Numeric)}, true)
+	}
+	mp.multipartTree.ReplaceOrInsert(&Multipart{key: "b/2", id: "00000000"}, true)
+	keys := func(matches []*Multipart) (ks []string) {
+		for _, m := range matches {
+			ks = append(ks, m.key)
+		}
+		return
+	}
+
+	matches := mp.listMultipart("b/", "", "", 10)
+	if !reflect.DeepEqual([]string{"b/1", "b/2", "b/2", "b/2", "b/3"}, keys(matches)) {
+		t.Fatalf("list with prefix: %v", keys(matches))
+	}
+	if matches = mp.listMultipart("b/", "", "", 2); len(matches) != 2 {
+		t.Fatalf("list with limit: %v", keys(matches))
+	}
+	// the marker is excluded
+	if matches = mp.listMultipart("b/", "b/2", "", 10); !reflect.DeepEqual([]string{"b/3"}, keys(matches)) {
+		t.Fatalf("list with key marker: %v", keys(matches))
+	}
+	matches = mp.listMultipart("", "b/2", "00000000", 10)
+	if !reflect.DeepEqual([]string{"b/2", "b/2", "b/3", "c/1"}, keys(matches)) {
+		t.Fatalf("list with upload id marker: %v", keys(matches))
+	}
+	if matches = mp.listMultipart("c/", "a/1", "", 10); !reflect.DeepEqual([]string{"c/1"}, keys(matches)) {
+		t.Fatalf("list with marker before prefix: %v", keys(matches))
+	}
+	if matches = mp.listMultipart("b/", "c/1", "", 10); len(matches) != 0 {
+		t.Fatalf("list with marker after prefix: %v", keys(matches))
+	}
+}
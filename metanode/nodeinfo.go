@@ -22,6 +22,8 @@ var (
 	nodeInfoStopC              = make(chan struct{})
 	deleteWorkerSleepMs uint64 = 0
 	dirChildrenNumLimit uint32 = proto.DefaultDirChildrenNumLimit
+
+	snapshotPersistenceMode = proto.PersistenceModeWriteThrough
 )
 
 func DeleteBatchCount() uint64 {
@@ -47,6 +49,22 @@ func DeleteWorkerSleepMs() {
 	}
 }
 
+func updateSnapshotPersistenceMode(mode uint32) {
+	if mode != proto.PersistenceModeWriteThrough && mode != proto.PersistenceModeWriteBack {
+		log.LogWarnf("updateNodeInfo: unknown snapshot persistence mode(%v), keep %v", mode,
+			proto.PersistenceModeString(atomic.LoadUint32(&snapshotPersistenceMode)))
+		return
+	}
+	if old := atomic.SwapUint32(&snapshotPersistenceMode, mode); old != mode {
+		log.LogWarnf("updateNodeInfo: snapshot persistence mode from %v to %v",
+			proto.PersistenceModeString(old), proto.PersistenceModeString(mode))
+	}
+}
+
+func snapshotWriteBack() bool {
+	return atomic.LoadUint32(&snapshotPersistenceMode) == proto.PersistenceModeWriteBack
+}
+
 func (m *MetaNode) startUpdateNodeInfo() {
 	ticker := time.NewTicker(UpdateNodeInfoTicket)
 	defer ticker.Stop()
@@ -76,6 +94,11 @@ func (m *MetaNode) updateNodeInfo() {
 	}
 	updateDeleteBatchCount(clusterInfo.MetaNodeDeleteBatchCount)
 	updateDeleteWorkerSleepMs(clusterInfo.MetaNodeDeleteWorkerSleepMs)
+	updateSnapshotPersistenceMode(clusterInfo.MetaSnapshotPersistenceMode)
+	if manager, ok := m.metadataManager.(*metadataManager); ok {
+		manager.updateSnapshotRates(clusterInfo.MetaSnapshotSendRateMB, clusterInfo.MetaSnapshotRecvRateMB,
+			clusterInfo.MetaSnapshotPartitionRateMB)
+	}
 
 	if clusterInfo.DirChildrenNumLimit < proto.MinDirChildrenNumLimit {
 		log.LogWarnf("updateNodeInfo: DirChildrenNumLimit probably not enabled on master, set to default value(%v)",
@@ -16,15 +16,20 @@ package metanode
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/cubefs/cubefs/datanode/storage"
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/tracing"
 )
 
 type Packet struct {
 	proto.Packet
+	deadline time.Time     // the client stops waiting for the response after it, zero if never
+	span     *tracing.Span // of the traced client op, nil if not traced
 }
 
 // NewPacketToDeleteExtent returns a new packet to delete the extent.
@@ -128,3 +133,64 @@ func (p *Packet) AdminOp() bool {
 		p.Opcode == proto.OpMetaPartitionTryToLeader ||
 		p.Opcode == proto.OpDeleteMetaPartition
 }
+
+// IsMasterOp returns whether the packet is an admin task sent by the master.
+func (p *Packet) IsMasterOp() bool {
+	switch p.Opcode {
+	case proto.OpMetaNodeHeartbeat,
+		proto.OpCreateMetaPartition,
+		proto.OpDeleteMetaPartition,
+		proto.OpUpdateMetaPartition,
+		proto.OpLoadMetaPartition,
+		proto.OpMetaTreeCRC,
+		proto.OpMetaCountDentryRef,
+		proto.OpMetaRepairNLink,
+		proto.OpMetaRaftLogTruncate,
+		proto.OpDecommissionMetaPartition,
+		proto.OpAddMetaPartitionRaftMember,
+		proto.OpRemoveMetaPartitionRaftMember,
+		proto.OpMetaPartitionTryToLeader,
+		proto.OpFreezeEmptyMetaPartition,
+		proto.OpBackupEmptyMetaPartition,
+		proto.OpRemoveBackupMetaPartition,
+		proto.OpIsRaftStatusOk,
+		proto.OpVersionOperation:
+		return true
+	default:
+		return false
+	}
+}
+
+// setDeadline sets the deadline of the client op received at now from the timeout the
+// client waits for it. Taking the timeout from the receive time rather than an absolute
+// deadline keeps the check free of the clock skew between the client and the node.
+func (p *Packet) setDeadline(now time.Time) {
+	if timeout := p.MetaTimeout(); timeout > 0 && !p.IsMasterOp() {
+		p.deadline = now.Add(timeout)
+	}
+}
+
+func (p *Packet) expired() bool {
+	return !p.deadline.IsZero() && time.Now().After(p.deadline)
+}
+
+// forwardDeadline sets the timeout of the op proxied to the leader at now to the time the
+// client still waits for it. It returns false if the client has stopped waiting.
+func (p *Packet) forwardDeadline(now time.Time) bool {
+	if p.deadline.IsZero() {
+		return true
+	}
+	remaining := p.deadline.Sub(now)
+	if remaining <= 0 {
+		return false
+	}
+	// rounded up to ms, a zero timeout never expires
+	p.SetMetaTimeout(remaining + time.Millisecond - 1)
+	return true
+}
+
+// dropExpired counts the op dropped at the stage for the client has stopped waiting.
+func (p *Packet) dropExpired(stage string) {
+	log.LogWarnf("drop expired packet(%v) at %v, deadline(%v)", p.GetUniqueLogId(), stage, p.deadline)
+	exporter.NewCounter(MetricExpiredDropped).AddWithLabels(1, map[string]string{"stage": stage, "op": p.GetOpMsg()})
+}
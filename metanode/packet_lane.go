@@ -0,0 +1,96 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+)
+
+const (
+	laneClient = "client"
+	laneAdmin  = "admin"
+
+	defaultClientOpWorkers = 0
+	defaultAdminOpWorkers  = 32
+)
+
+// opLane bounds the number of packets handled concurrently, the packets over the capacity
+// wait in the lane. Master admin tasks and client ops go through separate lanes so the
+// admin tasks are never queued behind client traffic.
+type opLane struct {
+	name    string
+	tokens  chan struct{}
+	queued  int64
+	running int64
+}
+
+// newOpLane returns a lane of workers packets running concurrently, 0 means unlimited.
+func newOpLane(name string, workers int) *opLane {
+	l := &opLane{name: name}
+	if workers > 0 {
+		l.tokens = make(chan struct{}, workers)
+	}
+	return l
+}
+
+// acquire waits for a worker of the lane, the returned func must be called to release it.
+func (l *opLane) acquire() (release func()) {
+	if l.tokens != nil {
+		tp := exporter.NewTP("laneWait_" + l.name)
+		atomic.AddInt64(&l.queued, 1)
+		l.tokens <- struct{}{}
+		atomic.AddInt64(&l.queued, -1)
+		tp.Set()
+	}
+	atomic.AddInt64(&l.running, 1)
+	return func() {
+		atomic.AddInt64(&l.running, -1)
+		if l.tokens != nil {
+			<-l.tokens
+		}
+	}
+}
+
+func (l *opLane) capacity() int {
+	return cap(l.tokens)
+}
+
+func (l *opLane) queuedCount() int64 {
+	return atomic.LoadInt64(&l.queued)
+}
+
+func (l *opLane) runningCount() int64 {
+	return atomic.LoadInt64(&l.running)
+}
+
+// isLongPollOp returns if the op waits for the changes up to its timeout. It takes no worker
+// of the lanes and the schedulers, or the waits would starve the other ops.
+func isLongPollOp(op uint8) bool {
+	return op == proto.OpMetaWatchEvents || op == proto.OpMetaAttrLease
+}
+
+// laneOf returns the lane the packet is handled in, nil if it takes none.
+func (m *MetaNode) laneOf(p *Packet) *opLane {
+	if isLongPollOp(p.Opcode) {
+		return nil
+	}
+	if p.IsMasterOp() {
+		return m.adminLane
+	}
+	return m.clientLane
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpLane(t *testing.T) {
+	lane := newOpLane(laneClient, 1)
+	release := lane.acquire()
+	require.EqualValues(t, 1, lane.runningCount())
+
+	acquired := make(chan struct{})
+	go func() {
+		lane.acquire()()
+		close(acquired)
+	}()
+	require.Eventually(t, func() bool { return lane.queuedCount() == 1 }, time.Second, time.Millisecond)
+
+	release()
+	<-acquired
+	require.EqualValues(t, 0, lane.queuedCount())
+	require.EqualValues(t, 0, lane.runningCount())
+
+	unlimited := newOpLane(laneClient, 0)
+	for i := 0; i < 8; i++ {
+		defer unlimited.acquire()()
+	}
+	require.EqualValues(t, 8, unlimited.runningCount())
+}
+
+func TestLaneOfPacket(t *testing.T) {
+	m := &MetaNode{clientLane: newOpLane(laneClient, 1), adminLane: newOpLane(laneAdmin, 1)}
+
+	// a full client lane does not block the admin tasks
+	release := m.clientLane.acquire()
+	defer release()
+
+	p := &Packet{}
+	p.Opcode = proto.OpCreateMetaPartition
+	require.Equal(t, m.adminLane, m.laneOf(p))
+	m.laneOf(p).acquire()()
+
+	p.Opcode = proto.OpMetaCreateInode
+	require.Equal(t, m.clientLane, m.laneOf(p))
+
+	// the long polls take no lane
+	p.Opcode = proto.OpMetaAttrLease
+	require.Nil(t, m.laneOf(p))
+}
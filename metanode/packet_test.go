@@ -0,0 +1,106 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketDeadline(t *testing.T) {
+	req := proto.NewPacketReqID()
+	req.Opcode = proto.OpMetaInodeGet
+	req.ExtentType |= proto.PacketProtocolVersionFlag
+	req.SetMetaTimeout(2 * time.Second)
+	header := make([]byte, req.CalcPacketHeaderSize())
+	req.MarshalHeader(header)
+
+	p := &Packet{}
+	require.NoError(t, p.UnmarshalHeader(header))
+	require.Equal(t, 2*time.Second, p.MetaTimeout())
+
+	p.setDeadline(time.Now())
+	require.False(t, p.expired())
+	p.setDeadline(time.Now().Add(-3 * time.Second))
+	require.True(t, p.expired())
+
+	// an expired op is dropped before it reaches the partition
+	conn, _ := net.Pipe()
+	defer conn.Close()
+	m := &MetaNode{clientLane: newOpLane(laneClient, 1), adminLane: newOpLane(laneAdmin, 1)}
+	require.NoError(t, m.handlePacket(conn, p, "client"))
+	require.NoError(t, (&metadataManager{}).respondToClient(conn, p))
+
+	// master ops and the ops of old clients never expire
+	admin := &Packet{}
+	admin.Opcode = proto.OpCreateMetaPartition
+	admin.SetMetaTimeout(time.Millisecond)
+	admin.setDeadline(time.Now().Add(-time.Second))
+	require.False(t, admin.expired())
+	old := &Packet{}
+	old.Opcode = proto.OpMetaInodeGet
+	old.setDeadline(time.Now().Add(-time.Second))
+	require.False(t, old.expired())
+	require.True(t, old.forwardDeadline(time.Now()))
+	require.Zero(t, old.MetaTimeout())
+
+	// the op proxied to the leader takes the time the client still waits
+	now := time.Now()
+	p.setDeadline(now)
+	require.True(t, p.forwardDeadline(now.Add(1500*time.Millisecond)))
+	require.Equal(t, 500*time.Millisecond, p.MetaTimeout())
+	require.True(t, p.forwardDeadline(now.Add(2*time.Second-time.Microsecond)))
+	require.Equal(t, time.Millisecond, p.MetaTimeout())
+	require.False(t, p.forwardDeadline(now.Add(2*time.Second)))
+}
+
+func TestPacketSpan(t *testing.T) {
+	m := &MetaNode{tracer: tracing.NewTracer("metanode", 0, 8)}
+	p := &Packet{}
+	p.Opcode = proto.OpMetaLookup
+	m.startPacketSpan(p, "client")
+	require.Nil(t, p.span)
+	m.finishPacketSpan(p)
+
+	// the op traced by the client is traced here too
+	p.SetTraceContext(1, 2)
+	m.startPacketSpan(p, "client")
+	require.NotNil(t, p.span)
+	p.span.Event("queued")
+	m.finishPacketSpan(p)
+	spans := m.tracer.Recent(1)
+	require.Len(t, spans, 1)
+	require.EqualValues(t, 2, spans[0].ParentID)
+	require.Equal(t, "client", spans[0].Attrs["remote"])
+
+	// the admin tasks of master are not traced
+	m.tracer.SetSampleRate(1)
+	admin := &Packet{}
+	admin.Opcode = proto.OpMetaNodeHeartbeat
+	m.startPacketSpan(admin, "master")
+	require.Nil(t, admin.span)
+	sampled := &Packet{}
+	sampled.Opcode = proto.OpMetaInodeGet
+	m.startPacketSpan(sampled, "client")
+	require.NotNil(t, sampled.span)
+	m.finishPacketSpan(sampled)
+	traceID, _ := sampled.span.IDs()
+	require.Zero(t, m.tracer.Recent(traceID)[0].ParentID)
+}
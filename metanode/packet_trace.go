@@ -0,0 +1,47 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"strconv"
+)
+
+// startPacketSpan starts the span of the client op in the trace of the client, or in a new
+// trace if the op is sampled here. The span is exported once the op is handled.
+func (m *MetaNode) startPacketSpan(p *Packet, remoteAddr string) {
+	if p.IsMasterOp() {
+		return
+	}
+	if p.HasTraceContext() {
+		traceID, spanID := p.TraceContext()
+		p.span = m.tracer.StartChildSpan(p.GetOpMsg(), traceID, spanID)
+	} else {
+		p.span = m.tracer.StartSpan(p.GetOpMsg())
+	}
+	if p.span == nil {
+		return
+	}
+	p.span.SetAttr("mp", strconv.FormatUint(p.PartitionID, 10))
+	p.span.SetAttr("reqId", strconv.FormatInt(p.ReqID, 10))
+	p.span.SetAttr("remote", remoteAddr)
+}
+
+func (m *MetaNode) finishPacketSpan(p *Packet) {
+	if p.span == nil {
+		return
+	}
+	p.span.SetAttr("result", p.GetResultMsg())
+	p.span.Finish()
+}
@@ -38,8 +38,9 @@ import (
 	"github.com/cubefs/cubefs/sdk/data/blobstore"
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/atomicutil"
+	"github.com/cubefs/cubefs/util/auditlog"
 	"github.com/cubefs/cubefs/util/errors"
-	"github.com/cubefs/cubefs/util/fileutil"
+	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
 )
 
@@ -56,6 +57,8 @@ var (
 // Errors
 var (
 	ErrInodeIDOutOfRange = errors.New("inode ID out of range")
+	ErrMemFrozen         = errors.New("meta partition is frozen by memory watermark")
+	ErrApplyFailed       = errors.New("meta partition is failed by a panic of apply")
 )
 
 type sortedPeers []proto.Peer
@@ -100,8 +103,11 @@ type MetaPartitionConfig struct {
 	RaftStore                raftstore.RaftStore `json:"-"`
 	ConnPool                 *util.ConnectPool   `json:"-"`
 	Forbidden                bool                `json:"-"`
+	ReadOnlyWindow           bool                `json:"-"` // the vol is in a read-only window told by master, see volWriteStates
+	MetaFrozen               bool                `json:"-"` // the metadata of the vol is frozen by master, see volWriteStates
 	ForbidWriteOpOfProtoVer0 bool                `json:"ForbidWriteOpOfProtoVer0"`
 	Freeze                   bool                `json:"freeze"`
+	SourceVol                string              `json:"source_vol,omitempty"` // set if the vol is a replica of SourceVol
 }
 
 func (c *MetaPartitionConfig) checkMeta() (err error) {
@@ -154,6 +160,8 @@ type OpInode interface {
 	SetCreateTime(req *SetCreateTimeRequest, reqData []byte, p *Packet) (err error) // for debugging
 	DeleteMigrationExtentKey(req *proto.DeleteMigrationExtentKeyRequest, p *Packet, remoteAddr string) (err error)
 	UpdateInodeMeta(req *proto.UpdateInodeMetaRequest, p *Packet) (err error)
+	UpdateLinkTarget(req *UpdateLinkTargetRequest, p *Packet) (err error)
+	BatchCreateDentryInode(req *BatchCreateDentryInodeReq, p *Packet, remoteAddr string) (err error)
 }
 
 type OpExtend interface {
@@ -162,10 +170,14 @@ type OpExtend interface {
 	GetXAttr(req *proto.GetXAttrRequest, p *Packet) (err error)
 	GetAllXAttr(req *proto.GetAllXAttrRequest, p *Packet) (err error)
 	BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packet) (err error)
+	ScanXAttr(req *proto.ScanXAttrRequest, p *Packet) (err error)
 	RemoveXAttr(req *proto.RemoveXAttrRequest, p *Packet) (err error)
 	ListXAttr(req *proto.ListXAttrRequest, p *Packet) (err error)
 	UpdateXAttr(req *proto.UpdateXAttrRequest, p *Packet) (err error)
 	LockDir(req *proto.LockDirRequest, p *Packet) (err error)
+	FileLock(req *proto.FileLockRequest, p *Packet) (err error)
+	FileLockLease(req *proto.FileLockLeaseRequest, p *Packet) (err error)
+	AttrLease(req *proto.AttrLeaseRequest, p *Packet) (err error)
 }
 
 // OpDentry defines the interface for the dentry operations.
@@ -176,6 +188,10 @@ type OpDentry interface {
 	UpdateDentry(req *UpdateDentryReq, p *Packet, remoteAddr string) (err error)
 	ReadDir(req *ReadDirReq, p *Packet) (err error)
 	ReadDirLimit(req *ReadDirLimitReq, p *Packet) (err error)
+	ReadDirPlus(req *ReadDirPlusReq, p *Packet) (err error)
+	DeleteSubtree(req *DeleteSubtreeReq, p *Packet, remoteAddr string) (err error)
+	DeleteSubtreeStatus(req *DeleteSubtreeStatusReq, p *Packet) (err error)
+	SnapshotProgress(req *SnapshotProgressReq, p *Packet) (err error)
 	ReadDirOnly(req *ReadDirOnlyReq, p *Packet) (err error)
 	Lookup(req *LookupReq, p *Packet) (err error)
 	GetDentryTree() *BTree
@@ -205,6 +221,7 @@ type OpExtent interface {
 	ExtentsList(req *proto.GetExtentsRequest, p *Packet) (err error)
 	ObjExtentsList(req *proto.GetExtentsRequest, p *Packet) (err error)
 	ExtentsTruncate(req *ExtentsTruncateReq, p *Packet, remoteAddr string) (err error)
+	ExtentsPreAlloc(req *ExtentsPreAllocReq, p *Packet, remoteAddr string) (err error)
 	BatchExtentAppend(req *proto.AppendExtentKeysRequest, p *Packet) (err error)
 	// ExtentsDelete(req *proto.DelExtentKeyRequest, p *Packet) (err error)
 }
@@ -258,8 +275,16 @@ type OpPartition interface {
 	GetUniqId() uint64
 	IsFollowerRead() bool
 	SetFollowerRead(bool)
+	GetFollowerLag(hasLeader bool) (entries uint64, behind time.Duration)
 	GetBaseConfig() MetaPartitionConfig
 	ResponseLoadMetaPartition(p *Packet) (err error)
+	ComputeTreeCRC(rangeSize uint64) (resp *proto.MetaTreeCRCResponse, err error)
+	CountDentryRefs(ino uint64) (resp *proto.MetaDentryRefResponse)
+	RepairNLink(req *proto.MetaRepairNLinkRequest, p *Packet, remoteAddr string) (err error)
+	TruncateRaftLog(req *proto.MetaRaftLogTruncateRequest) (resp *proto.MetaRaftLogTruncateResponse, err error)
+	ReadSnapshot(send func(frames []byte) error) (err error)
+	SyncReplica(req *proto.SyncMetaReplicaRequest) (status *proto.MetaReplicaSyncStatus, err error)
+	IsVolReplica() bool
 	PersistMetadata() (err error)
 	RenameStaleMetadata() (err error)
 	ChangeMember(changeType raftproto.ConfChangeType, peer raftproto.Peer, context []byte) (resp interface{}, err error)
@@ -272,6 +297,10 @@ type OpPartition interface {
 	IsEquareCreateMetaPartitionRequst(request *proto.CreateMetaPartitionRequest) (err error)
 	GetUniqID(p *Packet, num uint32) (err error)
 	CloseAndBackupRaft() error
+	GetProposalStat() *ProposalStatInfo
+	takeProposalRate() uint64
+	GetStoreSchema() StoreSchemaStatus
+	WatchEvents(req *proto.WatchEventsRequest, p *Packet) (err error)
 }
 
 // MetaPartition defines the interface for the meta partition operations.
@@ -280,19 +309,34 @@ type MetaPartition interface {
 	Stop()
 	DataSize() uint64
 	GetFreeListLen() int
+	SetVolPurgeCtrl(ctrl *proto.VolPurgeCtrl)
+	SetPurge(op string) (err error)
+	GetPurgeStatus() *proto.MetaPartitionPurgeStatus
+	RebuildFreeList() (result *proto.MetaFreeListRebuildResult, err error)
 	OpMeta
 	LoadSnapshot(path string) error
 	ForceSetMetaPartitionToLoadding()
 	ForceSetMetaPartitionToFininshLoad()
 	IsForbidden() bool
 	SetForbidden(status bool)
+	IsReadOnlyWindow() bool
+	SetReadOnlyWindow(status bool)
+	IsMetaFrozen() bool
+	SetMetaFrozen(status bool)
+	IsMemFrozen() bool
+	SetMemFrozen(frozen bool)
+	IsApplyFailed() bool
+	GetApplyFailure() *ApplyFailure
 	IsForbidWriteOpOfProtoVer0() bool
 	SetForbidWriteOpOfProtoVer0(status bool)
 	IsEnableAuditLog() bool
 	SetEnableAuditLog(status bool)
+	GetDefaultXAttrs() map[string]string
+	SetDefaultXAttrs(xattrs map[string]string)
 	UpdateVolumeView(dataView *proto.DataPartitionsView, volumeView *proto.SimpleVolView)
 	GetStatByStorageClass() []*proto.StatOfStorageClass
 	GetMigrateStatByStorageClass() []*proto.StatOfStorageClass
+	GetStat() *proto.MetaPartitionStat
 	SetFreeze(req *proto.FreezeMetaPartitionRequest) (err error)
 }
 
@@ -517,11 +561,32 @@ type metaPartition struct {
 	verUpdateChan             chan []byte
 	enableAuditLog            bool
 	recycleInodeDelFileFlag   atomicutil.Flag
-	enablePersistAccessTime   bool
-	accessTimeValidInterval   uint64
+	volConfigLock             sync.Mutex
+	volConfig                 atomic.Value // *VolConfig
 	statByStorageClass        []*proto.StatOfStorageClass
 	statByMigrateStorageClass []*proto.StatOfStorageClass
+	stat                      *proto.MetaPartitionStat
+	purge                     purgeCtrl
+	fileLocks                 fileLockIndex
 	syncAtimeCh               chan uint64
+	proposalStat              proposalStat
+	defaultXAttrsLock         sync.RWMutex
+	defaultXAttrs             map[string]string
+	snapshotDirLock           sync.Mutex   // held when storing snapshot or cleaning orphan snapshot dirs
+	memFrozen                 int32        // set by the memory watermark of the node
+	applyFailure              atomic.Value // *ApplyFailure, set once an apply panicked
+	replicaStaging            *replicaSyncStaging
+	replicaSyncing            int32
+	replicaSyncStatus         atomic.Value // *proto.MetaReplicaSyncStatus
+	opAuditLock               sync.RWMutex
+	opAudit                   *auditlog.Audit // op audit log, opened if enableOpAudit of the volume is on
+	subtreeJobs               subtreeJobs
+	truncatedIndex            uint64 // the raft log is truncated up to it
+	snapResume                snapResume
+	schemaMigration           storeSchemaMigration
+	nsEvents                  *nsEventRing // the namespace events for the watchers
+	attrLeases                *attrLeases  // the inodes the clients cache the attrs of, on the leader
+	followerLag               followerLag  // since when the follower is behind the leader
 }
 
 // IsLeader returns the raft leader address and if the current meta partition is the leader.
@@ -557,6 +622,22 @@ func (mp *metaPartition) SetForbidden(status bool) {
 	mp.config.Forbidden = status
 }
 
+func (mp *metaPartition) IsReadOnlyWindow() bool {
+	return mp.config.ReadOnlyWindow
+}
+
+func (mp *metaPartition) SetReadOnlyWindow(status bool) {
+	mp.config.ReadOnlyWindow = status
+}
+
+func (mp *metaPartition) IsMetaFrozen() bool {
+	return mp.config.MetaFrozen
+}
+
+func (mp *metaPartition) SetMetaFrozen(status bool) {
+	mp.config.MetaFrozen = status
+}
+
 func (mp *metaPartition) GetVolStorageClass() uint32 {
 	return mp.vol.GetVolView().VolStorageClass
 }
@@ -626,64 +707,17 @@ func (mp *metaPartition) updateSize() {
 		for {
 			select {
 			case <-timer.C:
-				size := uint64(0)
-				migrateSize := uint64(0)
-				migrateInodeCnt := uint32(0)
-
-				statStorageClassMap := make(map[uint32]*proto.StatOfStorageClass)
-				var statStorageClass *proto.StatOfStorageClass
-				statByMigStorageClassMap := make(map[uint32]*proto.StatOfStorageClass)
-				var statMigStorageClass *proto.StatOfStorageClass
-				var ok bool
-
-				mp.inodeTree.GetTree().Ascend(func(item BtreeItem) bool {
-					inode := item.(*Inode)
-					size += inode.Size
-
-					// stat normal Extents
-					if statStorageClass, ok = statStorageClassMap[inode.StorageClass]; !ok {
-						statStorageClass = proto.NewStatOfStorageClass(inode.StorageClass)
-						statStorageClassMap[inode.StorageClass] = statStorageClass
-					}
-					statStorageClass.InodeCount++
-					statStorageClass.UsedSizeBytes += inode.Size
-
-					// stat migration Extents
-					if inode.HybridCloudExtentsMigration == nil ||
-						inode.HybridCloudExtentsMigration.sortedEks == nil ||
-						!proto.IsValidStorageClass(inode.HybridCloudExtentsMigration.storageClass) {
-						return true
-					}
-					migrateStorageClass := inode.HybridCloudExtentsMigration.storageClass
-					if statMigStorageClass, ok = statByMigStorageClassMap[migrateStorageClass]; !ok {
-						statMigStorageClass = proto.NewStatOfStorageClass(migrateStorageClass)
-						statByMigStorageClassMap[migrateStorageClass] = statMigStorageClass
-					}
-					migrateInodeCnt += 1
-					migrateSize += inode.Size
-					statMigStorageClass.InodeCount++
-					statMigStorageClass.UsedSizeBytes += inode.Size
-
-					return true
-				})
-				mp.size = size
-
-				normalToSlice := make([]*proto.StatOfStorageClass, 0)
-				for _, stat := range statStorageClassMap {
-					normalToSlice = append(normalToSlice, stat)
-				}
-				mp.statByStorageClass = normalToSlice
-
-				migrateToSlice := make([]*proto.StatOfStorageClass, 0)
-				for _, migStat := range statByMigStorageClassMap {
-					migrateToSlice = append(migrateToSlice, migStat)
-				}
-				mp.statByMigrateStorageClass = migrateToSlice
+				accounting := mp.collectAccounting()
+				stat := accounting.stat
+				mp.size = stat.Size
+				mp.statByStorageClass = statOfStorageClassSlice(accounting.byStorageClass)
+				mp.statByMigrateStorageClass = statOfStorageClassSlice(accounting.byMigrateStorageClass)
+				mp.stat = &stat
 
 				log.LogDebugf("[updateSize] update mp(%d) size(%d) success, inodeCount(%d), dentryCount(%d), "+
-					"migrateInodeCount(%v) migrateSize(%v)",
-					mp.config.PartitionId, size, mp.inodeTree.Len(), mp.dentryTree.Len(),
-					migrateInodeCnt, migrateSize)
+					"delInodeCount(%v) xattrCount(%v) xattrBytes(%v)",
+					mp.config.PartitionId, stat.Size, mp.inodeTree.Len(), mp.dentryTree.Len(),
+					stat.DelInodeCount, stat.XAttrCount, stat.XAttrBytes)
 			case <-mp.stopC:
 				log.LogDebugf("[updateSize] stop update mp[%v] size, inodeCount(%d), dentryCount(%d)",
 					mp.config.PartitionId, mp.inodeTree.Len(), mp.dentryTree.Len())
@@ -798,6 +832,10 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 			mp.config.PartitionId, err.Error())
 		return
 	}
+	// the events after the snapshot loaded are appended again as the raft log is replayed
+	if mp.nsEvents != nil {
+		mp.nsEvents.reset(mp.applyID)
+	}
 	mp.startScheduleTask()
 
 	retryCnt := 0
@@ -823,7 +861,7 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 		mp.config.VolName, mp.config.PartitionId, retryCnt)
 
 	volInfo := mp.vol.GetVolView()
-	mp.vol.volDeleteLockTime = volInfo.DeleteLockTime
+	mp.reloadVolConfig(volInfo)
 	if mp.manager.metaNode.clusterEnableSnapshot {
 		go mp.runVersionOp()
 	}
@@ -868,6 +906,8 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 	}
 
 	go mp.startCheckerEvict()
+	go mp.startFileLockExpire()
+	go mp.startDirStatCheck()
 
 	log.LogWarnf("[before raft] get mp[%v] applied(%d),inodeCount(%d),dentryCount(%d)", mp.config.PartitionId, mp.applyID, mp.inodeTree.Len(), mp.dentryTree.Len())
 
@@ -895,6 +935,7 @@ func (mp *metaPartition) startScheduleTask() {
 func (mp *metaPartition) onStop() {
 	mp.stopRaft()
 	mp.stop()
+	mp.closeOpAudit()
 	if mp.delInodeFp != nil {
 		mp.delInodeFp.Sync()
 		mp.delInodeFp.Close()
@@ -1000,6 +1041,8 @@ func NewMetaPartition(conf *MetaPartitionConfig, manager *metadataManager) MetaP
 			TemporaryVerMap: make(map[uint64]*proto.VolVersionInfo),
 		},
 		enableAuditLog: true,
+		nsEvents:       newNsEventRing(),
+		attrLeases:     newAttrLeases(),
 	}
 
 	if mp.manager != nil && mp.manager.metaNode.raftPartitionCanUsingDifferentPort {
@@ -1132,32 +1175,33 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 		mp.loadMultipart,
 	}
 
-	crc_count := len(crcs)
-	if crc_count != CRC_COUNT_BASIC && crc_count != CRC_COUNT_TX_STUFF && crc_count != CRC_COUNT_UINQ_STUFF && crc_count != CRC_COUNT_MULTI_VER {
-		log.LogErrorf("action[LoadSnapshot] crc array length %d not match", len(crcs))
-		return ErrSnapshotCrcMismatch
+	version, err := loadStoreSchema(snapshotPath, len(crcs))
+	if err != nil {
+		log.LogErrorf("action[LoadSnapshot] mp(%v) load snapshot schema: %v", mp.config.PartitionId, err)
+		return err
 	}
+	mp.updateSchemaStatus(func(status *StoreSchemaStatus) {
+		status.Version, status.LoadedVersion = version, version
+	})
 
-	// handle compatibility in upgrade scenarios
+	// handle compatibility in upgrade scenarios, the snapshot of an old schema is upgraded once loaded
 	needLoadTxStuff := false
 	needLoadUniqStuff := false
-	if crc_count >= CRC_COUNT_TX_STUFF {
+	if version >= StoreSchemaVersion_1 {
 		needLoadTxStuff = true
 		loadFuncs = append(loadFuncs, mp.loadTxInfo)
 		loadFuncs = append(loadFuncs, mp.loadTxRbInode)
 		loadFuncs = append(loadFuncs, mp.loadTxRbDentry)
 	}
-	if crc_count >= CRC_COUNT_UINQ_STUFF {
+	if version >= StoreSchemaVersion_2 {
 		needLoadUniqStuff = true
 		loadFuncs = append(loadFuncs, mp.loadUniqChecker)
 	}
 
-	if crc_count == CRC_COUNT_MULTI_VER {
+	if version >= StoreSchemaVersion_3 {
 		if err = mp.loadMultiVer(snapshotPath, crcs[CRC_COUNT_MULTI_VER-1]); err != nil {
 			return
 		}
-	} else {
-		mp.storeMultiVersion(snapshotPath, &storeMsg{multiVerList: mp.multiVersionList.VerList})
 	}
 
 	errs := make([]error, len(loadFuncs))
@@ -1214,6 +1258,12 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 	if err = mp.loadApplyID(snapshotPath); err != nil {
 		return
 	}
+	if err = mp.loadReplicaStaging(snapshotPath); err != nil {
+		return
+	}
+	if version < currentStoreSchemaVersion {
+		return mp.upgradeStoreSchema(version)
+	}
 	return
 }
 
@@ -1263,7 +1313,11 @@ func (mp *metaPartition) doFileStats(thresholds []uint64) {
 
 func (mp *metaPartition) store(sm *storeMsg) (err error) {
 	log.LogWarnf("metaPartition %d store apply %v", mp.config.PartitionId, sm.applyIndex)
+	mp.snapshotDirLock.Lock()
+	defer mp.snapshotDirLock.Unlock()
+	tp := exporter.NewTP(MetricStore)
 	defer func() {
+		mp.recordStore(tp)
 		log.LogWarnf("metaPartition %d store apply %v finish", mp.config.PartitionId, sm.applyIndex)
 	}()
 
@@ -1314,9 +1368,15 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 	if err = mp.storeUniqID(tmpDir, sm); err != nil {
 		return
 	}
+	if err = mp.storeReplicaStaging(tmpDir, sm); err != nil {
+		return
+	}
 
+	if err = storeStoreSchema(tmpDir); err != nil {
+		return
+	}
 	// write crc to file
-	if err = fileutil.WriteFileWithSync(path.Join(tmpDir, SnapshotSign), crcBuffer.Bytes(), 0o775); err != nil {
+	if err = writeSnapshotFile(path.Join(tmpDir, SnapshotSign), crcBuffer.Bytes(), 0o775); err != nil {
 		return
 	}
 	snapshotDir := path.Join(mp.config.RootDir, snapshotDir)
@@ -1347,6 +1407,7 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 	}
 
 	mp.storedApplyId = sm.applyIndex
+	mp.updateSchemaStatus(func(status *StoreSchemaStatus) { status.Version = currentStoreSchemaVersion })
 	return
 }
 
@@ -1377,6 +1438,20 @@ func (mp *metaPartition) nextInodeID() (inodeId uint64, err error) {
 	}
 }
 
+// allocInodeID returns a new inode ID to create an inode of the request p with. If the partition
+// takes no new inodes, p is replied with the result code the client switches partition on.
+func (mp *metaPartition) allocInodeID(p *Packet) (inodeId uint64, err error) {
+	if mp.IsMemFrozen() {
+		err = ErrMemFrozen
+		p.PacketErrorWithBody(proto.OpPartitionFrozen, []byte(err.Error()))
+		return
+	}
+	if inodeId, err = mp.nextInodeID(); err != nil {
+		p.PacketErrorWithBody(proto.OpCursorExhausted, []byte(err.Error()))
+	}
+	return
+}
+
 // ChangeMember changes the raft member with the specified one.
 func (mp *metaPartition) ChangeMember(changeType raftproto.ConfChangeType, peer raftproto.Peer, context []byte) (resp interface{}, err error) {
 	resp, err = mp.raftPartition.ChangeMember(changeType, peer, context)
@@ -1762,11 +1837,7 @@ func (mp *metaPartition) GetVolName() (volName string) {
 }
 
 func (mp *metaPartition) GetAccessTimeValidInterval() time.Duration {
-	interval := atomic.LoadUint64(&mp.accessTimeValidInterval)
-	if interval == 0 {
-		return proto.DefaultAccessTimeValidInterval
-	}
-	return time.Duration(interval)
+	return time.Duration(mp.GetVolConfig().AccessTimeValidInterval)
 }
 
 func (mp *metaPartition) GetStatByStorageClass() []*proto.StatOfStorageClass {
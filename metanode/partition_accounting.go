@@ -0,0 +1,125 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"github.com/cubefs/cubefs/proto"
+)
+
+// accountingTree is a tree of a meta partition that is accounted by partitionAccounting.
+type accountingTree interface {
+	Ascend(fn func(i BtreeItem) bool)
+}
+
+// partitionAccounting collects the size and count accounting of the trees of a meta partition,
+// all the counters reported by the partition are computed here in one place.
+type partitionAccounting struct {
+	stat                  proto.MetaPartitionStat
+	byStorageClass        map[uint32]*proto.StatOfStorageClass
+	byMigrateStorageClass map[uint32]*proto.StatOfStorageClass
+}
+
+func newPartitionAccounting() *partitionAccounting {
+	return &partitionAccounting{
+		byStorageClass:        make(map[uint32]*proto.StatOfStorageClass),
+		byMigrateStorageClass: make(map[uint32]*proto.StatOfStorageClass),
+	}
+}
+
+func (a *partitionAccounting) addInode(ino *Inode) {
+	a.stat.Size += ino.Size
+	if ino.ShouldDelete() {
+		a.stat.DelInodeCount++
+		a.stat.DelInodeSize += ino.Size
+	}
+
+	stat, ok := a.byStorageClass[ino.StorageClass]
+	if !ok {
+		stat = proto.NewStatOfStorageClass(ino.StorageClass)
+		a.byStorageClass[ino.StorageClass] = stat
+	}
+	stat.InodeCount++
+	stat.UsedSizeBytes += ino.Size
+
+	if ino.HybridCloudExtentsMigration == nil ||
+		ino.HybridCloudExtentsMigration.sortedEks == nil ||
+		!proto.IsValidStorageClass(ino.HybridCloudExtentsMigration.storageClass) {
+		return
+	}
+	migrateStorageClass := ino.HybridCloudExtentsMigration.storageClass
+	if stat, ok = a.byMigrateStorageClass[migrateStorageClass]; !ok {
+		stat = proto.NewStatOfStorageClass(migrateStorageClass)
+		a.byMigrateStorageClass[migrateStorageClass] = stat
+	}
+	stat.InodeCount++
+	stat.UsedSizeBytes += ino.Size
+}
+
+func (a *partitionAccounting) addDentry(dentry *Dentry) {
+	switch {
+	case proto.IsRegular(dentry.Type):
+		a.stat.FileDentryCount++
+	case proto.IsDir(dentry.Type):
+		a.stat.DirDentryCount++
+	case proto.IsSymlink(dentry.Type):
+		a.stat.SymlinkDentryCount++
+	default:
+		a.stat.OtherDentryCount++
+	}
+}
+
+func (a *partitionAccounting) addExtend(extend *Extend) {
+	extend.Range(func(key, value []byte) bool {
+		a.stat.XAttrCount++
+		a.stat.XAttrBytes += uint64(len(key) + len(value))
+		return true
+	})
+}
+
+func (a *partitionAccounting) account(inodeTree, dentryTree, extendTree accountingTree) {
+	inodeTree.Ascend(func(i BtreeItem) bool {
+		a.addInode(i.(*Inode))
+		return true
+	})
+	dentryTree.Ascend(func(i BtreeItem) bool {
+		a.addDentry(i.(*Dentry))
+		return true
+	})
+	extendTree.Ascend(func(i BtreeItem) bool {
+		a.addExtend(i.(*Extend))
+		return true
+	})
+}
+
+func statOfStorageClassSlice(stats map[uint32]*proto.StatOfStorageClass) []*proto.StatOfStorageClass {
+	slice := make([]*proto.StatOfStorageClass, 0, len(stats))
+	for _, stat := range stats {
+		slice = append(slice, stat)
+	}
+	return slice
+}
+
+// collectAccounting accounts the snapshots of the trees of the partition.
+func (mp *metaPartition) collectAccounting() *partitionAccounting {
+	a := newPartitionAccounting()
+	a.account(mp.inodeTree.GetTree(), mp.dentryTree.GetTree(), mp.extendTree.GetTree())
+	return a
+}
+
+// GetStat returns the accounting of the partition collected last time, it is nil until the
+// first collection is done.
+func (mp *metaPartition) GetStat() *proto.MetaPartitionStat {
+	return mp.stat
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectAccounting(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	for id, size := range map[uint64]uint64{10: 100, 11: 200, 12: 300} {
+		ino := NewInode(id, FileModeType)
+		ino.Size = size
+		if id == 12 {
+			ino.SetDeleteMark()
+		}
+		mp.inodeTree.ReplaceOrInsert(ino, true)
+	}
+	dentries := []*Dentry{
+		{ParentId: 1, Name: "file", Inode: 10, Type: FileModeType},
+		{ParentId: 1, Name: "dir", Inode: 13, Type: proto.Mode(os.ModeDir | 0o755)},
+		{ParentId: 1, Name: "link", Inode: 14, Type: proto.Mode(os.ModeSymlink | 0o777)},
+		{ParentId: 1, Name: "fifo", Inode: 15, Type: proto.Mode(os.ModeNamedPipe | 0o644)},
+	}
+	for _, dentry := range dentries {
+		mp.dentryTree.ReplaceOrInsert(dentry, true)
+	}
+	extend := NewExtend(10)
+	extend.Put([]byte("key"), []byte("value"), 0)
+	extend.Put([]byte("k2"), []byte("v2"), 0)
+	mp.extendTree.ReplaceOrInsert(extend, true)
+
+	require.Nil(t, mp.GetStat())
+	accounting := mp.collectAccounting()
+	stat := accounting.stat
+	require.EqualValues(t, 600, stat.Size)
+	require.EqualValues(t, 1, stat.DelInodeCount)
+	require.EqualValues(t, 300, stat.DelInodeSize)
+	require.EqualValues(t, 1, stat.FileDentryCount)
+	require.EqualValues(t, 1, stat.DirDentryCount)
+	require.EqualValues(t, 1, stat.SymlinkDentryCount)
+	require.EqualValues(t, 1, stat.OtherDentryCount)
+	require.EqualValues(t, 2, stat.XAttrCount)
+	require.EqualValues(t, 12, stat.XAttrBytes)
+
+	byStorageClass := statOfStorageClassSlice(accounting.byStorageClass)
+	require.Len(t, byStorageClass, 1)
+	require.EqualValues(t, 3, byStorageClass[0].InodeCount)
+	require.EqualValues(t, 600, byStorageClass[0].UsedSizeBytes)
+	require.Empty(t, statOfStorageClassSlice(accounting.byMigrateStorageClass))
+}
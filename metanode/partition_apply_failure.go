@@ -0,0 +1,66 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// ApplyFailure records the raft log entry whose apply panicked.
+type ApplyFailure struct {
+	Op     uint32    `json:"op"`
+	Index  uint64    `json:"index"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+func (mp *metaPartition) IsApplyFailed() bool {
+	return mp.applyFailure.Load() != nil
+}
+
+func (mp *metaPartition) GetApplyFailure() *ApplyFailure {
+	if v := mp.applyFailure.Load(); v != nil {
+		return v.(*ApplyFailure)
+	}
+	return nil
+}
+
+// markApplyFailed isolates the partition after the apply of the entry at index panicked, it
+// is called by the recover of Apply. The in-memory state may be half modified, so nothing is
+// applied or stored any more and the raft of the partition is stopped to hand the leadership
+// over to the other replicas. The partition keeps failed until it's restarted by the admin,
+// which reloads it from the last snapshot and replays the raft log.
+func (mp *metaPartition) markApplyFailed(op uint32, index uint64, r interface{}) {
+	if mp.IsApplyFailed() {
+		return
+	}
+	mp.applyFailure.Store(&ApplyFailure{
+		Op:     op,
+		Index:  index,
+		Reason: fmt.Sprintf("%v", r),
+		Time:   time.Now(),
+	})
+	msg := fmt.Sprintf("[markApplyFailed] mpId(%v) vol(%v) apply op(%v) index(%v) panic: %v",
+		mp.config.PartitionId, mp.config.VolName, op, index, r)
+	log.LogErrorf("%v\n%s", msg, debug.Stack())
+	exporter.Warning(msg)
+	// stop the raft asynchronously, it waits for the apply goroutine we are running on
+	go mp.stopRaft()
+}
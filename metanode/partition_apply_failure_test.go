@@ -0,0 +1,59 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPanicIsolation(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+	createInode := func(ino uint64) []byte {
+		data, err := NewInode(ino, FileModeType).Marshal()
+		require.NoError(t, err)
+		cmd, err := NewMetaItem(opFSMCreateInode, nil, data).MarshalJson()
+		require.NoError(t, err)
+		return cmd
+	}
+
+	_, err := mp.Apply(createInode(10), 1)
+	require.NoError(t, err)
+	require.False(t, mp.IsApplyFailed())
+	require.Nil(t, mp.GetApplyFailure())
+
+	// a panic of apply fails the partition instead of the process
+	inodeTree := mp.inodeTree
+	mp.inodeTree = nil
+	_, err = mp.Apply(createInode(11), 2)
+	require.ErrorIs(t, err, ErrApplyFailed)
+	require.True(t, mp.IsApplyFailed())
+	failure := mp.GetApplyFailure()
+	require.EqualValues(t, opFSMCreateInode, failure.Op)
+	require.EqualValues(t, 2, failure.Index)
+	require.EqualValues(t, 1, mp.GetAppliedID())
+
+	// nothing is applied any more
+	mp.inodeTree = inodeTree
+	_, err = mp.Apply(createInode(12), 3)
+	require.ErrorIs(t, err, ErrApplyFailed)
+	require.Nil(t, mp.inodeTree.Get(NewInode(12, 0)))
+	require.EqualValues(t, 1, mp.GetAppliedID())
+	_, err = mp.Snapshot()
+	require.ErrorIs(t, err, ErrApplyFailed)
+	require.EqualValues(t, 2, mp.GetApplyFailure().Index)
+}
@@ -0,0 +1,44 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"github.com/cubefs/cubefs/proto"
+)
+
+func (mp *metaPartition) GetAtimeMode() string {
+	return mp.GetVolConfig().AtimeMode
+}
+
+// needUpdateAccessTime decides whether an access at now should update the atime of ino. The mode
+// only covers the updates made by the accesses, the atime set by a setattr is always applied.
+// A vol without a mode updates it once the valid interval has passed, as it did before the
+// modes.
+func (mp *metaPartition) needUpdateAccessTime(ino *Inode, now int64) bool {
+	atime := ino.AccessTime
+	if now <= atime {
+		return false
+	}
+	switch mp.GetAtimeMode() {
+	case proto.AtimeModeNoatime:
+		return false
+	case proto.AtimeModeStrict:
+		return true
+	case proto.AtimeModeRelatime:
+		return atime < ino.ModifyTime || now-atime >= int64(mp.GetAccessTimeValidInterval())
+	default:
+		return now-atime >= int64(mp.GetAccessTimeValidInterval())
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeedUpdateAccessTime(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}}
+	require.Empty(t, mp.GetAtimeMode())
+	setAtimeMode := func(mode string) {
+		mp.reloadVolConfig(&proto.SimpleVolView{AtimeMode: mode})
+	}
+	setAtimeMode("")
+	interval := int64(proto.MinAccessTimeValidInterval)
+
+	now := int64(1000000)
+	ino := NewInode(1, FileModeType)
+	ino.ModifyTime = now - 5
+	ino.AccessTime = now - 10
+
+	// no mode: only the valid interval, whatever the mtime
+	require.False(t, mp.needUpdateAccessTime(ino, now))
+	ino.AccessTime = now - interval
+	require.True(t, mp.needUpdateAccessTime(ino, now))
+
+	setAtimeMode(proto.AtimeModeRelatime)
+	ino.ModifyTime = now - 2*interval
+	ino.AccessTime = now - interval/2
+
+	// relatime: coalesce within the interval unless atime is older than mtime
+	require.False(t, mp.needUpdateAccessTime(ino, now))
+	ino.AccessTime = now - interval
+	require.True(t, mp.needUpdateAccessTime(ino, now))
+	ino.AccessTime = now - 10
+	ino.ModifyTime = now - 5
+	require.True(t, mp.needUpdateAccessTime(ino, now))
+
+	setAtimeMode(proto.AtimeModeStrict)
+	ino.ModifyTime = now - 2*interval
+	require.True(t, mp.needUpdateAccessTime(ino, now))
+	require.False(t, mp.needUpdateAccessTime(ino, ino.AccessTime))
+
+	setAtimeMode(proto.AtimeModeNoatime)
+	ino.AccessTime = 0
+	require.False(t, mp.needUpdateAccessTime(ino, now))
+
+	// invalid mode is ignored
+	setAtimeMode("invalid")
+	require.Equal(t, proto.AtimeModeNoatime, mp.GetAtimeMode())
+	setAtimeMode("")
+	require.Empty(t, mp.GetAtimeMode())
+}
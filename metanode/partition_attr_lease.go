@@ -0,0 +1,257 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// the attr lease of a client expires unless it polls within it
+var attrLeaseTTL = 10 * time.Second
+
+// attrLeaseTarget has the inodes changed by the modifying requests of the clients, see
+// isMetaAuthWriteOp.
+type attrLeaseTarget struct {
+	Inode    uint64            `json:"ino"`
+	ParentID uint64            `json:"pino"`
+	Inodes   []uint64          `json:"inos"`
+	Items    []attrLeaseTarget `json:"items"` // of OpMetaBatchCreateDentryInode
+	Request  *struct {
+		Inode uint64
+	} // of the admin tasks, e.g. OpMetaRepairNLink
+}
+
+func (t *attrLeaseTarget) inodes() (inodes []uint64) {
+	inodes = append(inodes, t.Inodes...)
+	if t.Inode != 0 {
+		inodes = append(inodes, t.Inode)
+	}
+	if t.ParentID != 0 {
+		inodes = append(inodes, t.ParentID)
+	}
+	for i := range t.Items {
+		inodes = append(inodes, t.Items[i].inodes()...)
+	}
+	if t.Request != nil && t.Request.Inode != 0 {
+		inodes = append(inodes, t.Request.Inode)
+	}
+	return
+}
+
+// txAttrLeaseInodes returns the inodes of the partition changed by the commit of the tx.
+func txAttrLeaseInodes(tx *proto.TransactionInfo, pid uint64) (inodes []uint64) {
+	for _, ifo := range tx.TxInodeInfos {
+		if ifo.MpID == pid {
+			inodes = append(inodes, ifo.Ino)
+		}
+	}
+	for _, ifo := range tx.TxDentryInfos {
+		if ifo.MpID == pid {
+			inodes = append(inodes, ifo.ParentId)
+		}
+	}
+	return
+}
+
+// attrLeases are the inodes the clients cache the attrs of, kept in the memory of the leader
+// of the partition. The clients are told the inodes changed since their last polls, and with
+// a new epoch to drop all once the leader is changed.
+type attrLeases struct {
+	sync.Mutex
+	epoch   uint64
+	held    int32                          // of the holders, read without the lock
+	holders map[uint64]map[uint64]struct{} // inode -> clients
+	clients map[uint64]*attrLeaseClient
+}
+
+type attrLeaseClient struct {
+	inodes  map[uint64]struct{}
+	pending map[uint64]struct{} // the inodes invalidated for the next poll
+	expire  time.Time
+	notify  chan struct{}
+}
+
+func newAttrLeases() *attrLeases {
+	l := &attrLeases{}
+	l.reset()
+	return l
+}
+
+// reset drops all the leases with a new epoch.
+func (l *attrLeases) reset() {
+	l.Lock()
+	defer l.Unlock()
+	for _, c := range l.clients {
+		close(c.notify)
+	}
+	l.epoch = uint64(time.Now().UnixNano())
+	l.holders = make(map[uint64]map[uint64]struct{})
+	l.clients = make(map[uint64]*attrLeaseClient)
+	atomic.StoreInt32(&l.held, 0)
+}
+
+func (l *attrLeases) hasHolders() bool {
+	return l != nil && atomic.LoadInt32(&l.held) > 0
+}
+
+func (l *attrLeases) removeLocked(id uint64, ino uint64) {
+	if clients, ok := l.holders[ino]; ok {
+		delete(clients, id)
+		if len(clients) == 0 {
+			delete(l.holders, ino)
+		}
+	}
+}
+
+func (l *attrLeases) dropClientLocked(id uint64, c *attrLeaseClient) {
+	for ino := range c.inodes {
+		l.removeLocked(id, ino)
+	}
+	delete(l.clients, id)
+	atomic.StoreInt32(&l.held, int32(len(l.holders)))
+}
+
+// poll renews the lease of the client with the changes of req, and returns the inodes invalidated
+// since its last poll, and the channel closed once one is invalidated.
+func (l *attrLeases) poll(req *proto.AttrLeaseRequest, now time.Time) (resp *proto.AttrLeaseResponse, notify <-chan struct{}) {
+	l.Lock()
+	defer l.Unlock()
+	resp = &proto.AttrLeaseResponse{Epoch: l.epoch, LeaseMs: attrLeaseTTL.Milliseconds(), Invalidated: make([]uint64, 0)}
+	c, ok := l.clients[req.ClientID]
+	if ok && c.expire.Before(now) {
+		l.dropClientLocked(req.ClientID, c)
+		ok = false
+	}
+	if req.Epoch != l.epoch || !ok && req.Epoch != 0 {
+		// the leases the client has acquired are lost
+		resp.All = true
+		if ok {
+			l.dropClientLocked(req.ClientID, c)
+			ok = false
+		}
+	}
+	if !ok {
+		c = &attrLeaseClient{
+			inodes:  make(map[uint64]struct{}),
+			pending: make(map[uint64]struct{}),
+			notify:  make(chan struct{}),
+		}
+		l.clients[req.ClientID] = c
+	}
+	c.expire = now.Add(attrLeaseTTL)
+	for _, ino := range req.Release {
+		delete(c.inodes, ino)
+		delete(c.pending, ino)
+		l.removeLocked(req.ClientID, ino)
+	}
+	for _, ino := range req.Inodes {
+		c.inodes[ino] = struct{}{}
+		if l.holders[ino] == nil {
+			l.holders[ino] = make(map[uint64]struct{})
+		}
+		l.holders[ino][req.ClientID] = struct{}{}
+	}
+	atomic.StoreInt32(&l.held, int32(len(l.holders)))
+	if !resp.All {
+		for ino := range c.pending {
+			resp.Invalidated = append(resp.Invalidated, ino)
+		}
+	}
+	c.pending = make(map[uint64]struct{})
+	return resp, c.notify
+}
+
+// invalidate queues the inodes changed to the clients holding their leases.
+func (l *attrLeases) invalidate(inodes []uint64, now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	for _, ino := range inodes {
+		for id := range l.holders[ino] {
+			c := l.clients[id]
+			if c.expire.Before(now) {
+				l.dropClientLocked(id, c)
+				continue
+			}
+			c.pending[ino] = struct{}{}
+			close(c.notify)
+			c.notify = make(chan struct{})
+		}
+	}
+}
+
+// attrLeasesChanged returns the partition and the inodes with the attr leases the request changes
+// if it succeeds.
+func (m *metadataManager) attrLeasesChanged(p *Packet) (mp *metaPartition, inodes []uint64) {
+	if !isMetaAuthWriteOp(p.Opcode) {
+		return
+	}
+	partition, err := m.getPartition(p.PartitionID)
+	if err != nil {
+		return
+	}
+	if mp, _ = partition.(*metaPartition); mp == nil || !mp.attrLeases.hasHolders() {
+		return nil, nil
+	}
+	target := &attrLeaseTarget{}
+	if err = json.Unmarshal(p.Data, target); err != nil {
+		return nil, nil
+	}
+	return mp, target.inodes()
+}
+
+func (mp *metaPartition) invalidateAttrLeases(inodes []uint64) {
+	if mp.attrLeases.hasHolders() {
+		mp.attrLeases.invalidate(inodes, time.Now())
+	}
+}
+
+// AttrLease renews the attr lease of the client and replies the inodes invalidated since its
+// last poll, waiting for them up to the timeout of the request if there are none yet.
+func (mp *metaPartition) AttrLease(req *proto.AttrLeaseRequest, p *Packet) (err error) {
+	if mp.attrLeases == nil {
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte("attr leases are not enabled on the metanode"))
+		return
+	}
+	timeout := time.Duration(req.TimeoutMs) * time.Millisecond
+	if timeout > maxWatchEventsTimeout {
+		timeout = maxWatchEventsTimeout
+	}
+	resp, notify := mp.attrLeases.poll(req, time.Now())
+	if len(resp.Invalidated) == 0 && !resp.All && timeout > 0 {
+		timer := time.NewTimer(timeout)
+		select {
+		case <-notify:
+			// the next poll is sent right after, the lease is renewed by it
+			renew := &proto.AttrLeaseRequest{ClientID: req.ClientID, Epoch: resp.Epoch}
+			resp, _ = mp.attrLeases.poll(renew, time.Now())
+		case <-timer.C:
+		case <-mp.stopC:
+		}
+		timer.Stop()
+	}
+
+	var reply []byte
+	if reply, err = json.Marshal(resp); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
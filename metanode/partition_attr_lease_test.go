@@ -0,0 +1,113 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttrLease(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}, attrLeases: newAttrLeases(), stopC: make(chan bool)}
+	m := &metadataManager{partitions: map[uint64]MetaPartition{1: mp}}
+	poll := func(req *proto.AttrLeaseRequest) *proto.AttrLeaseResponse {
+		p := &Packet{}
+		require.NoError(t, mp.AttrLease(req, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+		resp := &proto.AttrLeaseResponse{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		return resp
+	}
+	change := func(op uint8, req interface{}) {
+		p := &Packet{}
+		p.Opcode = op
+		p.PartitionID = 1
+		require.NoError(t, p.MarshalData(req))
+		changed, inodes := m.attrLeasesChanged(p)
+		require.NotNil(t, changed)
+		changed.invalidateAttrLeases(inodes)
+	}
+
+	// the first polls acquire the leases
+	require.False(t, mp.attrLeases.hasHolders())
+	resp := poll(&proto.AttrLeaseRequest{ClientID: 1, Inodes: []uint64{10, 11}})
+	require.True(t, resp.All)
+	epoch := resp.Epoch
+	require.Equal(t, epoch, poll(&proto.AttrLeaseRequest{ClientID: 2, Inodes: []uint64{10}}).Epoch)
+	require.True(t, mp.attrLeases.hasHolders())
+
+	// the holders are told the inodes changed
+	change(proto.OpMetaSetattr, &proto.SetAttrRequest{PartitionID: 1, Inode: 10})
+	change(proto.OpMetaCreateDentry, &proto.CreateDentryRequest{PartitionID: 1, ParentID: 11, Inode: 100, Name: "f"})
+	resp = poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch})
+	require.False(t, resp.All)
+	require.ElementsMatch(t, []uint64{10, 11}, resp.Invalidated)
+	require.Equal(t, []uint64{10}, poll(&proto.AttrLeaseRequest{ClientID: 2, Epoch: epoch}).Invalidated)
+	require.Empty(t, poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch}).Invalidated)
+
+	// so are they by the batched creations, the admin tasks and the commits of the txs
+	change(proto.OpMetaBatchCreateDentryInode, &proto.BatchCreateDentryInodeRequest{PartitionID: 1,
+		Items: []*proto.CreateDentryInodeItem{{ParentID: 11, Name: "g"}}})
+	change(proto.OpMetaRepairNLink, &proto.AdminTask{Request: &proto.MetaRepairNLinkRequest{PartitionID: 1, Inode: 10}})
+	require.ElementsMatch(t, []uint64{10, 11}, poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch}).Invalidated)
+	require.Equal(t, []uint64{10}, poll(&proto.AttrLeaseRequest{ClientID: 2, Epoch: epoch}).Invalidated)
+	tx := &proto.TransactionInfo{
+		TxInodeInfos:  map[uint64]*proto.TxInodeInfo{10: {Ino: 10, MpID: 1}, 20: {Ino: 20, MpID: 2}},
+		TxDentryInfos: map[string]*proto.TxDentryInfo{"11_f": {ParentId: 11, Name: "f", MpID: 1}},
+	}
+	require.ElementsMatch(t, []uint64{10, 11}, txAttrLeaseInodes(tx, 1))
+
+	p := &Packet{}
+	p.Opcode = proto.OpMetaInodeGet
+	p.PartitionID = 1
+	require.NoError(t, p.MarshalData(&proto.InodeGetRequest{PartitionID: 1, Inode: 10}))
+	_, inodes := m.attrLeasesChanged(p)
+	require.Empty(t, inodes)
+
+	// a poll waits for the invalidations
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mp.invalidateAttrLeases([]uint64{11})
+	}()
+	start := time.Now()
+	resp = poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch, TimeoutMs: 2000})
+	require.Equal(t, []uint64{11}, resp.Invalidated)
+	require.Less(t, time.Since(start), time.Second)
+
+	// the leases released or expired are not invalidated
+	poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch, Release: []uint64{11}})
+	old := attrLeaseTTL
+	attrLeaseTTL = time.Millisecond
+	poll(&proto.AttrLeaseRequest{ClientID: 2, Epoch: epoch})
+	attrLeaseTTL = old
+	time.Sleep(5 * time.Millisecond)
+	mp.invalidateAttrLeases([]uint64{10, 11})
+	require.Equal(t, []uint64{10}, poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch}).Invalidated)
+	require.True(t, poll(&proto.AttrLeaseRequest{ClientID: 2, Epoch: epoch}).All)
+
+	// the leases are lost with the leader, the waiting polls are woken up
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mp.attrLeases.reset()
+	}()
+	resp = poll(&proto.AttrLeaseRequest{ClientID: 1, Epoch: epoch, TimeoutMs: 2000})
+	require.True(t, resp.All)
+	require.NotEqual(t, epoch, resp.Epoch)
+	require.False(t, mp.attrLeases.hasHolders())
+}
@@ -0,0 +1,318 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const maxBatchCreateDentryInodeItems = 4096
+
+type dentryInode struct {
+	inode  *Inode
+	dentry *Dentry
+}
+
+// batchCreateDentryInodeCmd is the raft command of BatchCreateDentryInode, the inode IDs
+// are allocated by the leader before proposing it.
+type batchCreateDentryInodeCmd struct {
+	uniqID uint64
+	items  []*dentryInode
+	// the default xattrs of the inodes created, they are encoded after the items so that
+	// the commands without them are still decoded
+	xattrs []*Extend
+}
+
+func (cmd *batchCreateDentryInodeCmd) Marshal() (result []byte, err error) {
+	buff := bytes.NewBuffer(make([]byte, 0))
+	if err = binary.Write(buff, binary.BigEndian, cmd.uniqID); err != nil {
+		return
+	}
+	if err = binary.Write(buff, binary.BigEndian, uint32(len(cmd.items))); err != nil {
+		return
+	}
+	var data []byte
+	for _, item := range cmd.items {
+		if data, err = item.inode.Marshal(); err != nil {
+			return
+		}
+		if err = binary.Write(buff, binary.BigEndian, uint32(len(data))); err != nil {
+			return
+		}
+		buff.Write(data)
+		if data, err = item.dentry.Marshal(); err != nil {
+			return
+		}
+		if err = binary.Write(buff, binary.BigEndian, uint32(len(data))); err != nil {
+			return
+		}
+		buff.Write(data)
+	}
+	if len(cmd.xattrs) > 0 {
+		if err = binary.Write(buff, binary.BigEndian, uint32(len(cmd.xattrs))); err != nil {
+			return
+		}
+		for _, extend := range cmd.xattrs {
+			if data, err = extend.Bytes(); err != nil {
+				return
+			}
+			if err = binary.Write(buff, binary.BigEndian, uint32(len(data))); err != nil {
+				return
+			}
+			buff.Write(data)
+		}
+	}
+	result = buff.Bytes()
+	return
+}
+
+func readBatchCreateField(buff *bytes.Buffer) (data []byte, err error) {
+	var dataLen uint32
+	if err = binary.Read(buff, binary.BigEndian, &dataLen); err != nil {
+		return
+	}
+	if dataLen > proto.MaxBufferSize || int(dataLen) > buff.Len() {
+		return nil, proto.ErrBufferSizeExceedMaximum
+	}
+	return buff.Next(int(dataLen)), nil
+}
+
+func (cmd *batchCreateDentryInodeCmd) Unmarshal(raw []byte) (err error) {
+	buff := bytes.NewBuffer(raw)
+	if err = binary.Read(buff, binary.BigEndian, &cmd.uniqID); err != nil {
+		return
+	}
+	var count uint32
+	if err = binary.Read(buff, binary.BigEndian, &count); err != nil {
+		return
+	}
+	if count > maxBatchCreateDentryInodeItems {
+		return fmt.Errorf("batch create count %v exceeds %v", count, maxBatchCreateDentryInodeItems)
+	}
+	cmd.items = make([]*dentryInode, 0, count)
+	var data []byte
+	for i := uint32(0); i < count; i++ {
+		item := &dentryInode{inode: NewInode(0, 0), dentry: &Dentry{}}
+		if data, err = readBatchCreateField(buff); err != nil {
+			return
+		}
+		if err = item.inode.Unmarshal(data); err != nil {
+			return
+		}
+		if data, err = readBatchCreateField(buff); err != nil {
+			return
+		}
+		if err = item.dentry.Unmarshal(data); err != nil {
+			return
+		}
+		cmd.items = append(cmd.items, item)
+	}
+	if buff.Len() == 0 {
+		return
+	}
+	if err = binary.Read(buff, binary.BigEndian, &count); err != nil {
+		return
+	}
+	if count > maxBatchCreateDentryInodeItems {
+		return fmt.Errorf("batch create xattrs count %v exceeds %v", count, maxBatchCreateDentryInodeItems)
+	}
+	cmd.xattrs = make([]*Extend, 0, count)
+	for i := uint32(0); i < count; i++ {
+		if data, err = readBatchCreateField(buff); err != nil {
+			return
+		}
+		var extend *Extend
+		if extend, err = NewExtendFromBytes(data); err != nil {
+			return
+		}
+		cmd.xattrs = append(cmd.xattrs, extend)
+	}
+	return
+}
+
+// nextInodeIDs allocates count inode IDs at once and returns the first of them.
+func (mp *metaPartition) nextInodeIDs(count uint64) (start uint64, err error) {
+	for {
+		cur := atomic.LoadUint64(&mp.config.Cursor)
+		end := mp.config.End
+		if cur >= end || end-cur < count {
+			log.LogWarnf("nextInodeIDs: can't create %v inodes again, cur %d, end %d", count, cur, end)
+			return 0, ErrInodeIDOutOfRange
+		}
+		if atomic.CompareAndSwapUint64(&mp.config.Cursor, cur, cur+count) {
+			return cur + 1, nil
+		}
+	}
+}
+
+func (mp *metaPartition) newImportedInode(inoID uint64, item *proto.CreateDentryInodeItem, storageClass uint32) (ino *Inode, err error) {
+	ino = NewInode(inoID, item.Mode)
+	ino.Uid = item.Uid
+	ino.Gid = item.Gid
+	ino.setVer(mp.verSeq)
+	ino.LinkTarget = item.Target
+	ino.StorageClass = storageClass
+	if proto.IsStorageClassReplica(storageClass) {
+		eks := NewSortedExtents()
+		for _, ek := range item.Extents {
+			eks.Append(ek)
+		}
+		ino.HybridCloudExtents.sortedEks = eks
+	} else if storageClass == proto.StorageClass_BlobStore && len(item.Extents) == 0 {
+		ino.HybridCloudExtents.sortedEks = NewSortedObjExtents()
+	} else {
+		return nil, fmt.Errorf("storage type %v not support extents", proto.StorageClassString(storageClass))
+	}
+	if proto.IsRegular(item.Mode) {
+		ino.Size = item.Size
+	}
+	return
+}
+
+// BatchCreateDentryInode creates the inodes and dentries of the items in one raft command
+// for bulk imports. The inode IDs are allocated in one step, and the request is deduplicated
+// by its UniqID, a replayed request returns the inodes created the first time.
+func (mp *metaPartition) BatchCreateDentryInode(req *BatchCreateDentryInodeReq, p *Packet, remoteAddr string) (err error) {
+	var resp *BatchCreateDentryInodeResp
+	defer func() {
+		if resp == nil {
+			mp.auditOp(remoteAddr, p, 0, 0, "", err)
+			return
+		}
+		for _, res := range resp.Results {
+			mp.auditOpWithResult(remoteAddr, p, res.Inode, res.ParentID, res.Name, proto.GetStatusStr(res.Status), nil)
+		}
+	}()
+	count := len(req.Items)
+	if count == 0 || count > maxBatchCreateDentryInodeItems {
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(fmt.Sprintf("batch create count %v should be in [1, %v]", count, maxBatchCreateDentryInodeItems)))
+		return
+	}
+	for _, item := range req.Items {
+		if item.Name == "" || item.ParentID < mp.config.Start || item.ParentID > mp.config.End {
+			p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(fmt.Sprintf("invalid item parent(%v) name(%v) of mp(%v)", item.ParentID, item.Name, mp.config.PartitionId)))
+			return
+		}
+	}
+	storageClass, err := mp.checkCreateInoStorageClassForCompatibility(req.StorageType, 0)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+
+	start, err := mp.nextInodeIDs(uint64(count))
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpInodeFullErr, []byte(err.Error()))
+		return
+	}
+	cmd := &batchCreateDentryInodeCmd{uniqID: req.UniqID, items: make([]*dentryInode, 0, count)}
+	for i, item := range req.Items {
+		var ino *Inode
+		if ino, err = mp.newImportedInode(start+uint64(i), item, storageClass); err != nil {
+			p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+			return
+		}
+		dentry := &Dentry{
+			ParentId:  item.ParentID,
+			Name:      item.Name,
+			Inode:     ino.Inode,
+			Type:      item.Mode,
+			multiSnap: NewDentrySnap(mp.GetVerSeq()),
+		}
+		cmd.items = append(cmd.items, &dentryInode{inode: ino, dentry: dentry})
+		if extend := mp.newDefaultXAttrs(ino.Inode); extend != nil {
+			cmd.xattrs = append(cmd.xattrs, extend)
+		}
+	}
+	val, err := cmd.Marshal()
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	r, err := mp.submit(opFSMBatchCreateDentryInode, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	resp = r.(*BatchCreateDentryInodeResp)
+	reply, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+func (mp *metaPartition) fsmBatchCreateDentryInode(cmd *batchCreateDentryInodeCmd) (resp *BatchCreateDentryInodeResp) {
+	resp = &BatchCreateDentryInodeResp{Results: make([]*proto.CreateDentryInodeResult, 0, len(cmd.items))}
+	repeated := !mp.uniqChecker.legalIn(cmd.uniqID)
+	if repeated {
+		log.LogWarnf("fsmBatchCreateDentryInode repeated, mp[%v] uniqID %v", mp.config.PartitionId, cmd.uniqID)
+	}
+	created := make(map[uint64]bool, len(cmd.items))
+	for _, item := range cmd.items {
+		result := &proto.CreateDentryInodeResult{
+			ParentID: item.dentry.ParentId,
+			Name:     item.dentry.Name,
+			Inode:    item.inode.Inode,
+		}
+		if repeated {
+			result.Status = proto.OpNotExistErr
+			if d, ok := mp.dentryTree.Get(item.dentry).(*Dentry); ok && !d.isDeleted() {
+				result.Inode = d.Inode
+				result.Status = proto.OpOk
+			}
+		} else {
+			result.Status = mp.fsmCreateDentryInode(item, result)
+			created[item.inode.Inode] = result.Status == proto.OpOk
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	for _, extend := range cmd.xattrs {
+		if created[extend.GetInode()] {
+			mp.fsmSetDefaultXAttrs(extend)
+		}
+	}
+	return
+}
+
+func (mp *metaPartition) fsmCreateDentryInode(item *dentryInode, result *proto.CreateDentryInodeResult) (status uint8) {
+	if d, ok := mp.dentryTree.Get(item.dentry).(*Dentry); ok && !d.isDeleted() {
+		result.Inode = d.Inode
+		return proto.OpExistErr
+	}
+	parent, ok := mp.inodeTree.CopyGet(NewInode(item.dentry.ParentId, 0)).(*Inode)
+	if !ok || parent.ShouldDelete() {
+		return proto.OpNotExistErr
+	}
+	if !proto.IsDir(parent.Type) {
+		return proto.OpArgMismatchErr
+	}
+	if status = mp.fsmCreateInode(item.inode); status != proto.OpOk {
+		return
+	}
+	if status = mp.fsmCreateDentry(item.dentry, false); status != proto.OpOk {
+		mp.inodeTree.Delete(item.inode)
+	}
+	return
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCreateDentryInode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.config.Start = 1
+	mp.config.End = 1 << 20
+	mp.config.Cursor = 10
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+	mp.inodeTree.ReplaceOrInsert(NewInode(1, DirModeType), true)
+	mp.inodeTree.ReplaceOrInsert(NewInode(2, FileModeType), true)
+
+	batchCreate := func(uniqID uint64, items ...*proto.CreateDentryInodeItem) (uint8, []*proto.CreateDentryInodeResult) {
+		p := &Packet{}
+		req := &BatchCreateDentryInodeReq{UniqID: uniqID, Items: items, StorageType: proto.StorageClass_Replica_SSD}
+		require.NoError(t, mp.BatchCreateDentryInode(req, p, ""))
+		if p.ResultCode != proto.OpOk {
+			return p.ResultCode, nil
+		}
+		resp := &BatchCreateDentryInodeResp{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		return p.ResultCode, resp.Results
+	}
+
+	ek := proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1, Size: 4096}
+	items := []*proto.CreateDentryInodeItem{
+		{ParentID: 1, Name: "a", Mode: FileModeType, Size: 4096, Extents: []proto.ExtentKey{ek}},
+		{ParentID: 1, Name: "dir", Mode: DirModeType},
+		{ParentID: 2, Name: "b", Mode: FileModeType},
+		{ParentID: 3, Name: "c", Mode: FileModeType},
+	}
+	status, results := batchCreate(100, items...)
+	require.Equal(t, proto.OpOk, status)
+	require.Len(t, results, 4)
+	require.Equal(t, proto.OpOk, results[0].Status)
+	require.EqualValues(t, 11, results[0].Inode)
+	require.Equal(t, proto.OpOk, results[1].Status)
+	require.EqualValues(t, 12, results[1].Inode)
+	require.Equal(t, proto.OpArgMismatchErr, results[2].Status)
+	require.Equal(t, proto.OpNotExistErr, results[3].Status)
+	require.EqualValues(t, 14, mp.config.Cursor)
+
+	ino := mp.inodeTree.Get(NewInode(11, 0)).(*Inode)
+	require.EqualValues(t, 4096, ino.Size)
+	require.Equal(t, 1, ino.GetExtents().Len())
+	dentry := mp.dentryTree.Get(&Dentry{ParentId: 1, Name: "a"}).(*Dentry)
+	require.EqualValues(t, 11, dentry.Inode)
+	require.EqualValues(t, 4, mp.inodeTree.Get(NewInode(1, 0)).(*Inode).GetNLink())
+
+	// a replayed request returns the inodes created the first time
+	status, results = batchCreate(100, items[:2]...)
+	require.Equal(t, proto.OpOk, status)
+	require.EqualValues(t, 11, results[0].Inode)
+	require.EqualValues(t, 12, results[1].Inode)
+	require.Nil(t, mp.inodeTree.Get(NewInode(15, 0)))
+
+	// an existing name is not created again
+	status, results = batchCreate(101, items[0])
+	require.Equal(t, proto.OpOk, status)
+	require.Equal(t, proto.OpExistErr, results[0].Status)
+	require.EqualValues(t, 11, results[0].Inode)
+
+	status, _ = batchCreate(102, &proto.CreateDentryInodeItem{ParentID: 1 << 21, Name: "d", Mode: FileModeType})
+	require.Equal(t, proto.OpArgMismatchErr, status)
+	status, _ = batchCreate(103)
+	require.Equal(t, proto.OpArgMismatchErr, status)
+
+	// the default xattrs of the volume are set to the inodes created only
+	mp.SetDefaultXAttrs(map[string]string{"tier": "cold"})
+	status, results = batchCreate(104,
+		&proto.CreateDentryInodeItem{ParentID: 1, Name: "e", Mode: FileModeType},
+		&proto.CreateDentryInodeItem{ParentID: 1, Name: "a", Mode: FileModeType})
+	require.Equal(t, proto.OpOk, status)
+	require.Equal(t, proto.OpOk, results[0].Status)
+	require.Equal(t, proto.OpExistErr, results[1].Status)
+	item := mp.extendTree.Get(NewExtend(results[0].Inode))
+	require.NotNil(t, item)
+	value, ok := item.(*Extend).Get([]byte("tier"))
+	require.True(t, ok)
+	require.Equal(t, "cold", string(value))
+	require.Nil(t, mp.extendTree.Get(NewExtend(11)))
+}
+
+func TestBatchCreateDentryInodeCmdMarshal(t *testing.T) {
+	cmd := &batchCreateDentryInodeCmd{uniqID: 1, items: []*dentryInode{
+		{inode: NewInode(10, FileModeType), dentry: &Dentry{ParentId: 1, Name: "a", Inode: 10, Type: FileModeType}},
+	}}
+	raw, err := cmd.Marshal()
+	require.NoError(t, err)
+	got := &batchCreateDentryInodeCmd{}
+	require.NoError(t, got.Unmarshal(raw))
+	require.Len(t, got.items, 1)
+	require.Empty(t, got.xattrs)
+
+	extend := NewExtend(10)
+	extend.Put([]byte("tier"), []byte("cold"), 0)
+	cmd.xattrs = []*Extend{extend}
+	raw, err = cmd.Marshal()
+	require.NoError(t, err)
+	got = &batchCreateDentryInodeCmd{}
+	require.NoError(t, got.Unmarshal(raw))
+	require.Len(t, got.items, 1)
+	require.Len(t, got.xattrs, 1)
+	require.EqualValues(t, 10, got.xattrs[0].GetInode())
+}
@@ -0,0 +1,208 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// InodeWithXAttr creates an inode together with its xattrs in one raft command,
+// so that the default xattrs of the volume are visible as soon as the inode is.
+type InodeWithXAttr struct {
+	qinode *MetaQuotaInode
+	extend *Extend
+}
+
+func (i *InodeWithXAttr) Marshal() (result []byte, err error) {
+	var inodeBytes []byte
+	if inodeBytes, err = i.qinode.Marshal(); err != nil {
+		return
+	}
+	return marshalWithXAttr(inodeBytes, i.extend)
+}
+
+func (i *InodeWithXAttr) Unmarshal(raw []byte) (err error) {
+	var inodeBytes []byte
+	if inodeBytes, i.extend, err = unmarshalWithXAttr(raw); err != nil {
+		return
+	}
+	i.qinode = &MetaQuotaInode{}
+	return i.qinode.Unmarshal(inodeBytes)
+}
+
+// TxInodeWithXAttr is InodeWithXAttr of the inode created by a transaction.
+type TxInodeWithXAttr struct {
+	qinode *TxMetaQuotaInode
+	extend *Extend
+}
+
+func (i *TxInodeWithXAttr) Marshal() (result []byte, err error) {
+	var inodeBytes []byte
+	if inodeBytes, err = i.qinode.Marshal(); err != nil {
+		return
+	}
+	return marshalWithXAttr(inodeBytes, i.extend)
+}
+
+func (i *TxInodeWithXAttr) Unmarshal(raw []byte) (err error) {
+	var inodeBytes []byte
+	if inodeBytes, i.extend, err = unmarshalWithXAttr(raw); err != nil {
+		return
+	}
+	i.qinode = &TxMetaQuotaInode{}
+	return i.qinode.Unmarshal(inodeBytes)
+}
+
+func marshalWithXAttr(inodeBytes []byte, extend *Extend) (result []byte, err error) {
+	var extendBytes []byte
+	if extendBytes, err = extend.Bytes(); err != nil {
+		return
+	}
+	buff := bytes.NewBuffer(make([]byte, 0, 4+len(inodeBytes)+len(extendBytes)))
+	if err = binary.Write(buff, binary.BigEndian, uint32(len(inodeBytes))); err != nil {
+		return
+	}
+	buff.Write(inodeBytes)
+	buff.Write(extendBytes)
+	result = buff.Bytes()
+	return
+}
+
+func unmarshalWithXAttr(raw []byte) (inodeBytes []byte, extend *Extend, err error) {
+	var inodeLen uint32
+	buff := bytes.NewBuffer(raw)
+	if err = binary.Read(buff, binary.BigEndian, &inodeLen); err != nil {
+		return
+	}
+	if inodeLen > proto.MaxBufferSize || int(inodeLen) > buff.Len() {
+		err = proto.ErrBufferSizeExceedMaximum
+		return
+	}
+	inodeBytes = buff.Next(int(inodeLen))
+	extend, err = NewExtendFromBytes(buff.Bytes())
+	return
+}
+
+// SetDefaultXAttrs updates the xattrs that are set to every inode created for the clients.
+func (mp *metaPartition) SetDefaultXAttrs(xattrs map[string]string) {
+	mp.defaultXAttrsLock.Lock()
+	defer mp.defaultXAttrsLock.Unlock()
+	if len(xattrs) == 0 && len(mp.defaultXAttrs) == 0 {
+		return
+	}
+	if len(xattrs) == len(mp.defaultXAttrs) {
+		same := true
+		for k, v := range xattrs {
+			if old, ok := mp.defaultXAttrs[k]; !ok || old != v {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+	log.LogInfof("[SetDefaultXAttrs] mp(%v) vol(%v) default xattrs from (%v) to (%v)",
+		mp.config.PartitionId, mp.config.VolName, mp.defaultXAttrs, xattrs)
+	mp.defaultXAttrs = xattrs
+}
+
+func (mp *metaPartition) GetDefaultXAttrs() map[string]string {
+	mp.defaultXAttrsLock.RLock()
+	defer mp.defaultXAttrsLock.RUnlock()
+	return mp.defaultXAttrs
+}
+
+// newDefaultXAttrs returns the default xattrs of the volume to be set to the inode created,
+// nil if there are none. Every path creating inodes for the clients attaches them.
+func (mp *metaPartition) newDefaultXAttrs(ino uint64) (extend *Extend) {
+	xattrs := mp.GetDefaultXAttrs()
+	if len(xattrs) == 0 {
+		return
+	}
+	extend = NewExtend(ino)
+	for k, v := range xattrs {
+		extend.Put([]byte(k), []byte(v), mp.verSeq)
+	}
+	return
+}
+
+// submitCreateInode proposes the command of creating ino, the default xattrs of the
+// volume are attached to it if there are any.
+func (mp *metaPartition) submitCreateInode(op uint32, val []byte, ino *Inode, quotaIds []uint32) (resp interface{}, err error) {
+	extend := mp.newDefaultXAttrs(ino.Inode)
+	if extend == nil {
+		return mp.submit(op, val)
+	}
+	cmd := &InodeWithXAttr{
+		qinode: &MetaQuotaInode{inode: ino, quotaIds: quotaIds},
+		extend: extend,
+	}
+	if val, err = cmd.Marshal(); err != nil {
+		return
+	}
+	return mp.submit(opFSMCreateInodeWithXAttr, val)
+}
+
+// submitTxCreateInode is submitCreateInode of the inode created by a transaction.
+func (mp *metaPartition) submitTxCreateInode(op uint32, val []byte, txIno *TxInode, quotaIds []uint32) (resp interface{}, err error) {
+	extend := mp.newDefaultXAttrs(txIno.Inode.Inode)
+	if extend == nil {
+		return mp.submit(op, val)
+	}
+	cmd := &TxInodeWithXAttr{
+		qinode: &TxMetaQuotaInode{txinode: txIno, quotaIds: quotaIds},
+		extend: extend,
+	}
+	if val, err = cmd.Marshal(); err != nil {
+		return
+	}
+	return mp.submit(opFSMTxCreateInodeWithXAttr, val)
+}
+
+func (mp *metaPartition) fsmCreateInodeWithXAttr(cmd *InodeWithXAttr) (status uint8) {
+	ino := cmd.qinode.inode
+	if len(cmd.qinode.quotaIds) > 0 {
+		mp.setInodeQuota(cmd.qinode.quotaIds, ino.Inode)
+	}
+	if status = mp.fsmCreateInode(ino); status != proto.OpOk {
+		return
+	}
+	mp.fsmSetDefaultXAttrs(cmd.extend)
+	return
+}
+
+func (mp *metaPartition) fsmTxCreateInodeWithXAttr(cmd *TxInodeWithXAttr) (status uint8) {
+	ino := cmd.qinode.txinode.Inode
+	if len(cmd.qinode.quotaIds) > 0 {
+		mp.setInodeQuota(cmd.qinode.quotaIds, ino.Inode)
+	}
+	if status = mp.fsmTxCreateInode(cmd.qinode.txinode, cmd.qinode.quotaIds); status != proto.OpOk {
+		return
+	}
+	mp.fsmSetDefaultXAttrs(cmd.extend)
+	return
+}
+
+func (mp *metaPartition) fsmSetDefaultXAttrs(extend *Extend) {
+	if err := mp.fsmSetXAttr(extend); err != nil {
+		log.LogErrorf("[fsmSetDefaultXAttrs] mp(%v) ino(%v) set xattr failed: %v",
+			mp.config.PartitionId, extend.GetInode(), err)
+	}
+}
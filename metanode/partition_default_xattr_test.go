@@ -0,0 +1,121 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInodeWithXAttrMarshal(t *testing.T) {
+	extend := NewExtend(10)
+	extend.Put([]byte("tier"), []byte("cold"), 0)
+	cmd := &InodeWithXAttr{
+		qinode: &MetaQuotaInode{inode: NewInode(10, FileModeType), quotaIds: []uint32{1, 2}},
+		extend: extend,
+	}
+	raw, err := cmd.Marshal()
+	require.NoError(t, err)
+
+	got := &InodeWithXAttr{}
+	require.NoError(t, got.Unmarshal(raw))
+	require.EqualValues(t, 10, got.qinode.inode.Inode)
+	require.Equal(t, []uint32{1, 2}, got.qinode.quotaIds)
+	value, ok := got.extend.Get([]byte("tier"))
+	require.True(t, ok)
+	require.Equal(t, "cold", string(value))
+}
+
+func TestCreateInodeWithDefaultXAttrs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+
+	create := func(id uint64) {
+		ino := NewInode(id, FileModeType)
+		val, err := ino.Marshal()
+		require.NoError(t, err)
+		resp, err := mp.submitCreateInode(opFSMCreateInode, val, ino, nil)
+		require.NoError(t, err)
+		require.EqualValues(t, proto.OpOk, resp)
+		require.NotNil(t, mp.inodeTree.Get(NewInode(id, 0)))
+	}
+
+	create(100)
+	require.Nil(t, mp.extendTree.Get(NewExtend(100)))
+
+	mp.SetDefaultXAttrs(map[string]string{"tier": "cold", "owner": "lifecycle"})
+	create(101)
+	item := mp.extendTree.Get(NewExtend(101))
+	require.NotNil(t, item)
+	value, ok := item.(*Extend).Get([]byte("tier"))
+	require.True(t, ok)
+	require.Equal(t, "cold", string(value))
+	value, ok = item.(*Extend).Get([]byte("owner"))
+	require.True(t, ok)
+	require.Equal(t, "lifecycle", string(value))
+	require.True(t, mp.config.Cursor >= 101)
+
+	mp.SetDefaultXAttrs(nil)
+	create(102)
+	require.Nil(t, mp.extendTree.Get(NewExtend(102)))
+}
+
+func TestTxCreateInodeWithDefaultXAttrs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+	mp.config.Start = 1
+	mp.config.End = 1 << 20
+	mp.config.Cursor = 200
+
+	txCreate := func() uint64 {
+		txInfo := proto.NewTransactionInfo(5, proto.TxTypeCreate)
+		txInfo.TmID = int64(mp.config.PartitionId)
+		txInfo.TxInodeInfos[proto.InitInode] = proto.NewTxInodeInfo("", proto.InitInode, mp.config.PartitionId)
+		req := &proto.TxCreateInodeRequest{
+			VolName:     VolNameForTest,
+			PartitionID: mp.config.PartitionId,
+			Mode:        FileModeType,
+			TxInfo:      txInfo,
+			StorageType: proto.StorageClass_Replica_SSD,
+		}
+		p := &Packet{}
+		require.NoError(t, mp.TxCreateInode(req, p, ""))
+		require.Equal(t, proto.OpOk, p.ResultCode, string(p.Data))
+		resp := &proto.TxCreateInodeResponse{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		require.NotNil(t, mp.inodeTree.Get(NewInode(resp.Info.Inode, 0)))
+		return resp.Info.Inode
+	}
+
+	ino := txCreate()
+	require.Nil(t, mp.extendTree.Get(NewExtend(ino)))
+
+	mp.SetDefaultXAttrs(map[string]string{"tier": "cold"})
+	ino = txCreate()
+	item := mp.extendTree.Get(NewExtend(ino))
+	require.NotNil(t, item)
+	value, ok := item.(*Extend).Get([]byte("tier"))
+	require.True(t, ok)
+	require.Equal(t, "cold", string(value))
+	require.True(t, mp.config.Cursor >= ino)
+}
@@ -0,0 +1,400 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	deleteSubtreeBatch      = 1024
+	deleteSubtreeRetry      = 3
+	maxRunningSubtreeJobs   = 4
+	subtreeJobKeepTime      = time.Hour
+	deleteSubtreeRetryDelay = time.Second
+)
+
+var errSubtreeJobCanceled = errors.New("subtree deletion canceled")
+
+// subtreeJobs are the subtree deletions started on the partition, they are kept in memory
+// only, so the progress is lost if the node restarts.
+type subtreeJobs struct {
+	sync.Mutex
+	lastID uint64
+	jobs   map[uint64]*deleteSubtreeJob
+}
+
+// deleteSubtreeJob removes a subtree bottom-up like rm -rf: the children of a directory are read
+// by batches, the subdirectories of a batch are emptied first, then the dentries of the batch are
+// deleted and their inodes unlinked and evicted by the batch ops, one raft command each, on the
+// leaders of the partitions they belong to. The unlinked inodes go to the free list of their
+// partitions, which deletes the extents in background. An interrupted job leaves a smaller but
+// consistent tree behind.
+type deleteSubtreeJob struct {
+	metaRouter
+	lock     sync.RWMutex
+	info     proto.DeleteSubtreeJob
+	canceled int32
+	// request sends an op to the partition of view, replaced in tests
+	request func(view *proto.MetaPartitionView, op uint8, req, resp interface{}) (status uint8, err error)
+}
+
+// metaRouter sends the ops of the jobs of a partition to the leaders of the partitions of the
+// volume, by the partition map from master.
+type metaRouter struct {
+	mp    *metaPartition
+	views []*proto.MetaPartitionView
+}
+
+// DeleteSubtree starts a job to delete the entry of the request and everything below it.
+func (mp *metaPartition) DeleteSubtree(req *DeleteSubtreeReq, p *Packet, remoteAddr string) (err error) {
+	defer func() {
+		mp.auditOp(remoteAddr, p, 0, req.ParentID, req.Name, err)
+	}()
+	if mp.GetVerSeq() > 0 {
+		err = fmt.Errorf("vol %v has snapshots, delete the subtree by the client", mp.config.VolName)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+	dentry, status := mp.getDentry(&Dentry{ParentId: req.ParentID, Name: req.Name})
+	if status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+
+	job, err := mp.newDeleteSubtreeJob(req.ParentID, dentry)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	go job.run(proto.Dentry{Name: dentry.Name, Inode: dentry.Inode, Type: dentry.Type})
+
+	reply, err := json.Marshal(&DeleteSubtreeResp{JobID: job.info.JobID})
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	log.LogInfof("action[DeleteSubtree] mp(%v) job(%v) starts to delete %v/%v ino(%v) from %v",
+		mp.config.PartitionId, job.info.JobID, req.ParentID, req.Name, dentry.Inode, remoteAddr)
+	p.PacketOkWithBody(reply)
+	return
+}
+
+// DeleteSubtreeStatus replies the progress of a job, after canceling it if asked to.
+func (mp *metaPartition) DeleteSubtreeStatus(req *DeleteSubtreeStatusReq, p *Packet) (err error) {
+	mp.subtreeJobs.Lock()
+	job := mp.subtreeJobs.jobs[req.JobID]
+	mp.subtreeJobs.Unlock()
+	if job == nil {
+		p.PacketErrorWithBody(proto.OpNotExistErr, []byte(fmt.Sprintf("no subtree job %v", req.JobID)))
+		return
+	}
+	if req.Cancel {
+		atomic.StoreInt32(&job.canceled, 1)
+	}
+	reply, err := json.Marshal(job.stat())
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+func (mp *metaPartition) newDeleteSubtreeJob(parentID uint64, root *Dentry) (job *deleteSubtreeJob, err error) {
+	now := time.Now()
+	table := &mp.subtreeJobs
+	table.Lock()
+	defer table.Unlock()
+	if table.jobs == nil {
+		table.jobs = make(map[uint64]*deleteSubtreeJob)
+	}
+	running := 0
+	for id, j := range table.jobs {
+		info := j.stat()
+		if info.Status == proto.DeleteSubtreeRunning {
+			running++
+		} else if now.Sub(time.Unix(info.EndTime, 0)) > subtreeJobKeepTime {
+			delete(table.jobs, id)
+		}
+	}
+	if running >= maxRunningSubtreeJobs {
+		return nil, fmt.Errorf("mp(%v) has %v subtree jobs running", mp.config.PartitionId, running)
+	}
+
+	// start from the time, so the IDs are not reused after the node restarts
+	if id := uint64(now.UnixNano()); id > table.lastID {
+		table.lastID = id
+	} else {
+		table.lastID++
+	}
+	job = &deleteSubtreeJob{
+		metaRouter: metaRouter{mp: mp},
+		info: proto.DeleteSubtreeJob{
+			JobID:     table.lastID,
+			ParentID:  parentID,
+			Name:      root.Name,
+			Inode:     root.Inode,
+			Status:    proto.DeleteSubtreeRunning,
+			StartTime: now.Unix(),
+		},
+	}
+	job.request = job.sendToLeader
+	table.jobs[job.info.JobID] = job
+	return
+}
+
+func (j *deleteSubtreeJob) stat() proto.DeleteSubtreeJob {
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+	return j.info
+}
+
+func (j *deleteSubtreeJob) run(root proto.Dentry) {
+	var (
+		left uint64
+		err  error
+	)
+	if proto.IsDir(root.Type) {
+		left, err = j.emptyDir(root.Inode)
+	}
+	if err == nil && left == 0 {
+		err = j.deleteEntries(j.info.ParentID, []proto.Dentry{root})
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.info.EndTime = time.Now().Unix()
+	switch {
+	case err == errSubtreeJobCanceled:
+		j.info.Status = proto.DeleteSubtreeCanceled
+	case err != nil:
+		j.info.Status = proto.DeleteSubtreeFailed
+		j.info.Error = err.Error()
+	case j.info.Skipped > 0:
+		j.info.Status = proto.DeleteSubtreeFailed
+		j.info.Error = fmt.Sprintf("%v entries are not deleted", j.info.Skipped)
+	default:
+		j.info.Status = proto.DeleteSubtreeDone
+	}
+	log.LogInfof("action[deleteSubtree] mp(%v) job(%v) %v/%v ends: %+v",
+		j.mp.config.PartitionId, j.info.JobID, j.info.ParentID, j.info.Name, j.info)
+}
+
+// emptyDir deletes the children of dir and returns how many of them are left.
+func (j *deleteSubtreeJob) emptyDir(dir uint64) (left uint64, err error) {
+	marker := ""
+	for {
+		if atomic.LoadInt32(&j.canceled) != 0 {
+			return left, errSubtreeJobCanceled
+		}
+		var children []proto.Dentry
+		if children, err = j.readDir(dir, marker); err != nil {
+			return
+		}
+		// the marker is read again if it was not deleted
+		if marker != "" && len(children) > 0 && children[0].Name == marker {
+			children = children[1:]
+		}
+		if len(children) == 0 {
+			return
+		}
+		marker = children[len(children)-1].Name
+
+		deletable := make([]proto.Dentry, 0, len(children))
+		for _, child := range children {
+			if proto.IsDir(child.Type) {
+				var n uint64
+				if n, err = j.emptyDir(child.Inode); err != nil {
+					return
+				}
+				if n > 0 {
+					left++
+					continue
+				}
+			}
+			deletable = append(deletable, child)
+		}
+		before := j.stat().Skipped
+		if err = j.deleteEntries(dir, deletable); err != nil {
+			return
+		}
+		left += j.stat().Skipped - before
+	}
+}
+
+func (j *deleteSubtreeJob) readDir(dir uint64, marker string) ([]proto.Dentry, error) {
+	req := &ReadDirLimitReq{VolName: j.mp.config.VolName, ParentID: dir, Marker: marker, Limit: deleteSubtreeBatch}
+	resp := &ReadDirLimitResp{}
+	if err := j.send(dir, proto.OpMetaReadDirLimit, req, &req.PartitionID, resp); err != nil {
+		return nil, err
+	}
+	return resp.Children, nil
+}
+
+// deleteEntries deletes the dentries of parent, then unlinks and evicts the inodes of the ones
+// deleted, the entries failed to delete are counted as skipped.
+func (j *deleteSubtreeJob) deleteEntries(parent uint64, dens []proto.Dentry) (err error) {
+	if len(dens) == 0 {
+		return
+	}
+	req := &BatchDeleteDentryReq{VolName: j.mp.config.VolName, ParentID: parent, Dens: dens}
+	resp := &BatchDeleteDentryResp{}
+	if err = j.send(parent, proto.OpMetaBatchDeleteDentry, req, &req.PartitionID, resp); err != nil {
+		return
+	}
+	inodes := make(map[uint64][]uint64)
+	var views []*proto.MetaPartitionView
+	deleted := uint64(0)
+	for _, item := range resp.Items {
+		if item.Status != proto.OpOk {
+			continue
+		}
+		deleted++
+		var view *proto.MetaPartitionView
+		if view, err = j.partitionOf(item.Inode); err != nil {
+			return
+		}
+		if _, ok := inodes[view.PartitionID]; !ok {
+			views = append(views, view)
+		}
+		inodes[view.PartitionID] = append(inodes[view.PartitionID], item.Inode)
+	}
+	j.lock.Lock()
+	j.info.DeletedDentries += deleted
+	j.info.Skipped += uint64(len(dens)) - deleted
+	j.lock.Unlock()
+
+	for _, view := range views {
+		ulReq := &BatchUnlinkInoReq{VolName: j.mp.config.VolName, Inodes: inodes[view.PartitionID]}
+		ulResp := &BatchUnlinkInoResp{}
+		// a failed item leaves an orphan inode, like rm -rf interrupted between the two ops
+		if err = j.send(view.Start, proto.OpMetaBatchUnlinkInode, ulReq, &ulReq.PartitionID, ulResp); err != nil {
+			return
+		}
+		evict := make([]uint64, 0, len(ulResp.Items))
+		for i, item := range ulResp.Items {
+			if item.Status == proto.OpOk && i < len(ulReq.Inodes) {
+				evict = append(evict, ulReq.Inodes[i])
+			}
+		}
+		if len(evict) == 0 {
+			continue
+		}
+		evReq := &BatchEvictInodeReq{VolName: j.mp.config.VolName, Inodes: evict}
+		if err = j.send(view.Start, proto.OpMetaBatchEvictInode, evReq, &evReq.PartitionID, nil); err != nil {
+			return
+		}
+		j.lock.Lock()
+		j.info.DeletedInodes += uint64(len(evict))
+		j.lock.Unlock()
+	}
+	return
+}
+
+// send sends the request to the partition of ino and retries with a new partition map if fails.
+// The partial results of batch ops are in resp, so only the status OpAgain is retried.
+func (j *deleteSubtreeJob) send(ino uint64, op uint8, req interface{}, pid *uint64, resp interface{}) (err error) {
+	var status uint8
+	for i := 0; i < deleteSubtreeRetry; i++ {
+		if i > 0 {
+			time.Sleep(deleteSubtreeRetryDelay)
+			j.views = nil
+		}
+		var view *proto.MetaPartitionView
+		if view, err = j.partitionOf(ino); err != nil {
+			continue
+		}
+		*pid = view.PartitionID
+		status, err = j.request(view, op, req, resp)
+		if err == nil && status != proto.OpAgain {
+			break
+		}
+	}
+	if err != nil {
+		return
+	}
+	switch op {
+	case proto.OpMetaBatchDeleteDentry, proto.OpMetaBatchUnlinkInode, proto.OpMetaBatchEvictInode:
+		// the status of the items is checked by the caller
+		if status != proto.OpAgain {
+			return
+		}
+	}
+	if status != proto.OpOk {
+		err = fmt.Errorf("%v of ino(%v) failed: status %v", (&proto.Packet{Opcode: op}).GetOpMsg(), ino, proto.GetStatusStr(status))
+	}
+	return
+}
+
+func (j *metaRouter) partitionOf(ino uint64) (*proto.MetaPartitionView, error) {
+	if j.views == nil {
+		views, err := masterClient.ClientAPI().GetMetaPartitions(j.mp.config.VolName)
+		if err != nil {
+			return nil, err
+		}
+		j.views = views
+	}
+	for _, view := range j.views {
+		if ino >= view.Start && ino <= view.End {
+			return view, nil
+		}
+	}
+	j.views = nil
+	return nil, fmt.Errorf("no partition of ino(%v) in vol %v", ino, j.mp.config.VolName)
+}
+
+func (j *metaRouter) sendToLeader(view *proto.MetaPartitionView, op uint8, req, resp interface{}) (status uint8, err error) {
+	addr := view.LeaderAddr
+	if addr == "" && len(view.Members) > 0 {
+		// the ops are forwarded to the leader by the proxy of the members
+		addr = view.Members[0]
+	}
+	p := proto.NewPacketReqID()
+	p.Opcode = op
+	p.PartitionID = view.PartitionID
+	if err = p.MarshalData(req); err != nil {
+		return
+	}
+	conn, err := j.mp.config.ConnPool.GetConnect(addr)
+	if err != nil {
+		return
+	}
+	defer func() {
+		j.mp.config.ConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	status = p.ResultCode
+	if resp != nil && len(p.Data) > 0 {
+		// the batch ops reply the results of the items with a failed status too
+		if e := p.UnmarshalData(resp); e != nil && status == proto.OpOk {
+			err = e
+		}
+	}
+	return
+}
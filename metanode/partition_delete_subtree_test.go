@@ -0,0 +1,127 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteSubtree(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.config.Start = 1
+	mp.config.End = 1 << 20
+	mp.config.Cursor = 10
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+	mp.inodeTree.ReplaceOrInsert(NewInode(1, DirModeType), true)
+
+	uniqID := uint64(100)
+	create := func(parent uint64, name string, mode uint32) uint64 {
+		uniqID++
+		p := &Packet{}
+		item := &proto.CreateDentryInodeItem{ParentID: parent, Name: name, Mode: mode}
+		req := &BatchCreateDentryInodeReq{
+			UniqID:      uniqID,
+			Items:       []*proto.CreateDentryInodeItem{item},
+			StorageType: proto.StorageClass_Replica_SSD,
+		}
+		require.NoError(t, mp.BatchCreateDentryInode(req, p, ""))
+		resp := &BatchCreateDentryInodeResp{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		require.Equal(t, proto.OpOk, resp.Results[0].Status)
+		return resp.Results[0].Inode
+	}
+	build := func(name string) (inodes []uint64) {
+		dir := create(1, name, DirModeType)
+		sub := create(dir, "sub", DirModeType)
+		return append(inodes, dir, create(dir, "f1", FileModeType), create(dir, "f2", FileModeType),
+			sub, create(sub, "g", FileModeType))
+	}
+
+	local := func(view *proto.MetaPartitionView, op uint8, req, resp interface{}) (uint8, error) {
+		require.Equal(t, mp.config.PartitionId, view.PartitionID)
+		p := &Packet{}
+		switch op {
+		case proto.OpMetaReadDirLimit:
+			require.NoError(t, mp.ReadDirLimit(req.(*ReadDirLimitReq), p))
+		case proto.OpMetaBatchDeleteDentry:
+			require.NoError(t, mp.DeleteDentryBatch(req.(*BatchDeleteDentryReq), p, ""))
+		case proto.OpMetaBatchUnlinkInode:
+			require.NoError(t, mp.UnlinkInodeBatch(req.(*BatchUnlinkInoReq), p, ""))
+		case proto.OpMetaBatchEvictInode:
+			require.NoError(t, mp.EvictInodeBatch(req.(*BatchEvictInodeReq), p, ""))
+		}
+		if resp != nil && len(p.Data) > 0 {
+			require.NoError(t, json.Unmarshal(p.Data, resp))
+		}
+		return p.ResultCode, nil
+	}
+	newJob := func(name string) *deleteSubtreeJob {
+		dentry, status := mp.getDentry(&Dentry{ParentId: 1, Name: name})
+		require.Equal(t, proto.OpOk, status)
+		job, err := mp.newDeleteSubtreeJob(1, dentry)
+		require.NoError(t, err)
+		job.views = []*proto.MetaPartitionView{{PartitionID: mp.config.PartitionId, Start: 1, End: 1 << 20}}
+		job.request = local
+		return job
+	}
+	jobStatus := func(jobID uint64, cancel bool) *proto.DeleteSubtreeJob {
+		p := &Packet{}
+		require.NoError(t, mp.DeleteSubtreeStatus(&DeleteSubtreeStatusReq{JobID: jobID, Cancel: cancel}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+		job := &proto.DeleteSubtreeJob{}
+		require.NoError(t, json.Unmarshal(p.Data, job))
+		return job
+	}
+
+	inodes := build("a")
+	job := newJob("a")
+	require.Equal(t, proto.DeleteSubtreeRunning, jobStatus(job.info.JobID, false).Status)
+	job.run(proto.Dentry{Name: "a", Inode: inodes[0], Type: DirModeType})
+	info := jobStatus(job.info.JobID, false)
+	require.Equal(t, proto.DeleteSubtreeDone, info.Status, info.Error)
+	require.EqualValues(t, 5, info.DeletedDentries)
+	require.EqualValues(t, 5, info.DeletedInodes)
+	require.Zero(t, info.Skipped)
+	require.EqualValues(t, 0, mp.dentryTree.Len())
+	for _, ino := range inodes {
+		if item := mp.inodeTree.Get(NewInode(ino, 0)); item != nil {
+			require.True(t, item.(*Inode).ShouldDelete(), "ino %v", ino)
+		}
+	}
+
+	// a canceled job stops before deleting anything
+	inodes = build("b")
+	job = newJob("b")
+	jobStatus(job.info.JobID, true)
+	job.run(proto.Dentry{Name: "b", Inode: inodes[0], Type: DirModeType})
+	info = jobStatus(job.info.JobID, false)
+	require.Equal(t, proto.DeleteSubtreeCanceled, info.Status)
+	require.Zero(t, info.DeletedDentries)
+	require.EqualValues(t, 5, mp.dentryTree.Len())
+
+	p := &Packet{}
+	require.NoError(t, mp.DeleteSubtree(&DeleteSubtreeReq{ParentID: 1, Name: "none"}, p, ""))
+	require.Equal(t, proto.OpNotExistErr, p.ResultCode)
+	p = &Packet{}
+	require.NoError(t, mp.DeleteSubtreeStatus(&DeleteSubtreeStatusReq{JobID: 1}, p))
+	require.Equal(t, proto.OpNotExistErr, p.ResultCode)
+}
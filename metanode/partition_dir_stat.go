@@ -0,0 +1,360 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/btree"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	innerDirStatKey = "cfs_inner_xattr_dir_stat_key"
+	dirStatSize     = 32
+
+	dirStatCheckInterval = 10 * time.Minute
+	dirStatCheckBatch    = 128  // the dirs recounted by a raft command
+	dirStatGetBatch      = 1024 // the inodes got from another partition by a request
+)
+
+// dirStatRepair sets the stat of a directory recounted by the leader, it is done only if the
+// stat is still the one the leader has counted from.
+type dirStatRepair struct {
+	Inode uint64        `json:"ino"`
+	Old   proto.DirStat `json:"old"`
+	New   proto.DirStat `json:"new"`
+}
+
+type dirStatRepairRequest struct {
+	Items []*dirStatRepair `json:"items"`
+}
+
+func encodeDirStat(st *proto.DirStat) []byte {
+	val := make([]byte, dirStatSize)
+	binary.BigEndian.PutUint64(val[0:8], st.Children)
+	binary.BigEndian.PutUint64(val[8:16], st.Files)
+	binary.BigEndian.PutUint64(val[16:24], st.Dirs)
+	binary.BigEndian.PutUint64(val[24:32], st.Size)
+	return val
+}
+
+func decodeDirStat(val []byte) (st proto.DirStat) {
+	if len(val) < dirStatSize {
+		return
+	}
+	st.Children = binary.BigEndian.Uint64(val[0:8])
+	st.Files = binary.BigEndian.Uint64(val[8:16])
+	st.Dirs = binary.BigEndian.Uint64(val[16:24])
+	st.Size = binary.BigEndian.Uint64(val[24:32])
+	return
+}
+
+func dirStatOf(extendTree *BTree, ino uint64) (st proto.DirStat) {
+	if item := extendTree.Get(NewExtend(ino)); item != nil {
+		if val, ok := item.(*Extend).Get([]byte(innerDirStatKey)); ok {
+			st = decodeDirStat(val)
+		}
+	}
+	return
+}
+
+// getDirStat returns the stat of the directory, zero if nothing is counted on it yet.
+func (mp *metaPartition) getDirStat(ino uint64) proto.DirStat {
+	return dirStatOf(mp.extendTree, ino)
+}
+
+// replyDirStat returns the stat of the directory replied to the clients, nil if the vol does
+// not enable the dir stats.
+func (mp *metaPartition) replyDirStat(ino uint64) *proto.DirStat {
+	if !mp.GetVolConfig().EnableDirStat {
+		return nil
+	}
+	st := mp.getDirStat(ino)
+	return &st
+}
+
+// storeDirStat keeps the stat of the directory in its extend. The xattrLock must be held.
+func (mp *metaPartition) storeDirStat(ino uint64, st *proto.DirStat) {
+	item := mp.extendTree.CopyGet(NewExtend(ino))
+	if *st == (proto.DirStat{}) {
+		if item != nil {
+			item.(*Extend).Remove([]byte(innerDirStatKey))
+		}
+		return
+	}
+	newExtend := NewExtend(ino)
+	newExtend.Put([]byte(innerDirStatKey), encodeDirStat(st), 0)
+	if item == nil {
+		mp.extendTree.ReplaceOrInsert(newExtend, true)
+		return
+	}
+	item.(*Extend).Merge(newExtend, true)
+}
+
+func decreaseBy(v, delta uint64) uint64 {
+	if v < delta {
+		return 0
+	}
+	return v - delta
+}
+
+// updateDirStat counts the dentry created, or deleted if not add, in the stat of its parent.
+// The usage of the child is known only if its inode is in the partition too, the others are
+// counted by the reconciliation. Nothing is counted unless the vol enables the dir stats.
+//
+// The stats are eventually consistent. Each replica counts the dentry ops it applies under its
+// own view of the switch of the vol, and the size of a file is taken when its dentry is made.
+// The drift is corrected by the recount of the leader, so a stat may lag behind the subtree
+// by the rounds of the recount, and after the switch is turned on until the first round.
+func (mp *metaPartition) updateDirStat(dentry *Dentry, add bool) {
+	if !mp.GetVolConfig().EnableDirStat {
+		return
+	}
+	var child proto.DirStat
+	if proto.IsDir(dentry.Type) {
+		child = mp.getDirStat(dentry.Inode)
+		child.Dirs++
+	} else {
+		child.Files++
+		if item := mp.inodeTree.Get(NewInode(dentry.Inode, 0)); item != nil {
+			ino := item.(*Inode)
+			ino.RLock()
+			child.Size = ino.Size
+			ino.RUnlock()
+		}
+	}
+
+	mp.xattrLock.Lock()
+	defer mp.xattrLock.Unlock()
+	st := mp.getDirStat(dentry.ParentId)
+	if add {
+		st.Children++
+		st.Files += child.Files
+		st.Dirs += child.Dirs
+		st.Size += child.Size
+	} else {
+		st.Children = decreaseBy(st.Children, 1)
+		st.Files = decreaseBy(st.Files, child.Files)
+		st.Dirs = decreaseBy(st.Dirs, child.Dirs)
+		st.Size = decreaseBy(st.Size, child.Size)
+	}
+	mp.storeDirStat(dentry.ParentId, &st)
+}
+
+func (mp *metaPartition) fsmRepairDirStats(req *dirStatRepairRequest) (repaired int) {
+	mp.xattrLock.Lock()
+	defer mp.xattrLock.Unlock()
+	for _, item := range req.Items {
+		if mp.getDirStat(item.Inode) != item.Old {
+			// changed since the leader counted the children
+			continue
+		}
+		if ino := mp.inodeTree.Get(NewInode(item.Inode, 0)); ino == nil || ino.(*Inode).ShouldDelete() {
+			continue
+		}
+		mp.storeDirStat(item.Inode, &item.New)
+		repaired++
+	}
+	return
+}
+
+// startDirStatCheck recounts the stats of the directories of the partition on the leader,
+// to correct the drift of the counters and sum the usage of the subtrees up.
+func (mp *metaPartition) startDirStatCheck() {
+	timer := time.NewTimer(dirStatCheckInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if _, ok := mp.IsLeader(); ok && mp.GetVerSeq() == 0 && mp.GetVolConfig().EnableDirStat {
+				c := newDirStatChecker(mp)
+				if repaired, err := c.run(); err != nil || repaired > 0 {
+					log.LogInfof("[startDirStatCheck] mp(%v) repaired %v dirs, err %v", mp.config.PartitionId, repaired, err)
+				}
+			}
+			timer.Reset(dirStatCheckInterval)
+		case <-mp.stopC:
+			return
+		}
+	}
+}
+
+// dirStatChecker recounts the stats of the directories on a snapshot of the trees. The files
+// and the stats of the subdirectories in other partitions are got from their leaders, so the
+// usage of a subtree is summed up by a level of partitions each round.
+type dirStatChecker struct {
+	metaRouter
+	// request sends an op to the partition of view, replaced in tests
+	request    func(view *proto.MetaPartitionView, op uint8, req, resp interface{}) (status uint8, err error)
+	inodeTree  *BTree
+	dentryTree *BTree
+	extendTree *BTree
+	// the stats recounted in the round, the children are mostly recounted before the parents
+	counted map[uint64]proto.DirStat
+}
+
+func newDirStatChecker(mp *metaPartition) *dirStatChecker {
+	c := &dirStatChecker{metaRouter: metaRouter{mp: mp}, counted: make(map[uint64]proto.DirStat)}
+	c.request = c.sendToLeader
+	return c
+}
+
+func (c *dirStatChecker) run() (repaired int, err error) {
+	mp := c.mp
+	mp.nonIdempotent.Lock()
+	c.inodeTree = mp.inodeTree.GetTree()
+	c.dentryTree = mp.dentryTree.GetTree()
+	c.extendTree = mp.extendTree.GetTree()
+	mp.nonIdempotent.Unlock()
+
+	// the children mostly have larger IDs than the parents
+	var dirs []uint64
+	c.inodeTree.tree.Descend(func(i btree.Item) bool {
+		ino := i.(*Inode)
+		if proto.IsDir(ino.Type) && !ino.ShouldDelete() {
+			dirs = append(dirs, ino.Inode)
+		}
+		return true
+	})
+	for start := 0; start < len(dirs); start += dirStatCheckBatch {
+		if _, ok := mp.IsLeader(); !ok {
+			return
+		}
+		end := start + dirStatCheckBatch
+		if end > len(dirs) {
+			end = len(dirs)
+		}
+		var items []*dirStatRepair
+		if items, err = c.recount(dirs[start:end]); err != nil {
+			return
+		}
+		if len(items) == 0 {
+			continue
+		}
+		var val []byte
+		if val, err = json.Marshal(&dirStatRepairRequest{Items: items}); err != nil {
+			return
+		}
+		var resp interface{}
+		if resp, err = mp.submit(opFSMRepairDirStat, val); err != nil {
+			return
+		}
+		repaired += resp.(int)
+	}
+	return
+}
+
+// recount counts the stats of the dirs and returns the ones changed.
+func (c *dirStatChecker) recount(dirs []uint64) (items []*dirStatRepair, err error) {
+	children := make(map[uint64][]*Dentry, len(dirs))
+	var remote []uint64
+	for _, dir := range dirs {
+		c.dentryTree.AscendRange(&Dentry{ParentId: dir}, &Dentry{ParentId: dir + 1}, func(i BtreeItem) bool {
+			dentry := i.(*Dentry)
+			if dentry.isDeleted() {
+				return true
+			}
+			children[dir] = append(children[dir], dentry)
+			if dentry.Inode < c.mp.config.Start || dentry.Inode > c.mp.config.End {
+				remote = append(remote, dentry.Inode)
+			}
+			return true
+		})
+	}
+	infos, err := c.getInodes(remote)
+	if err != nil {
+		return
+	}
+
+	for _, dir := range dirs {
+		st := proto.DirStat{Children: uint64(len(children[dir]))}
+		for _, dentry := range children[dir] {
+			local := dentry.Inode >= c.mp.config.Start && dentry.Inode <= c.mp.config.End
+			if proto.IsDir(dentry.Type) {
+				var sub proto.DirStat
+				if !local {
+					if info := infos[dentry.Inode]; info != nil && info.DirStat != nil {
+						sub = *info.DirStat
+					}
+				} else if counted, ok := c.counted[dentry.Inode]; ok {
+					sub = counted
+				} else {
+					sub = dirStatOf(c.extendTree, dentry.Inode)
+				}
+				st.Dirs += 1 + sub.Dirs
+				st.Files += sub.Files
+				st.Size += sub.Size
+				continue
+			}
+			st.Files++
+			if !local {
+				if info := infos[dentry.Inode]; info != nil {
+					st.Size += info.Size
+				}
+			} else if item := c.inodeTree.Get(NewInode(dentry.Inode, 0)); item != nil {
+				ino := item.(*Inode)
+				ino.RLock()
+				st.Size += ino.Size
+				ino.RUnlock()
+			}
+		}
+		c.counted[dir] = st
+		if old := dirStatOf(c.extendTree, dir); old != st {
+			items = append(items, &dirStatRepair{Inode: dir, Old: old, New: st})
+		}
+	}
+	return
+}
+
+// getInodes gets the inodes of other partitions from their leaders.
+func (c *dirStatChecker) getInodes(inodes []uint64) (infos map[uint64]*proto.InodeInfo, err error) {
+	infos = make(map[uint64]*proto.InodeInfo, len(inodes))
+	groups := make(map[uint64][]uint64)
+	views := make(map[uint64]*proto.MetaPartitionView)
+	for _, ino := range inodes {
+		var view *proto.MetaPartitionView
+		if view, err = c.partitionOf(ino); err != nil {
+			return
+		}
+		views[view.PartitionID] = view
+		groups[view.PartitionID] = append(groups[view.PartitionID], ino)
+	}
+	for pid, group := range groups {
+		for start := 0; start < len(group); start += dirStatGetBatch {
+			end := start + dirStatGetBatch
+			if end > len(group) {
+				end = len(group)
+			}
+			req := &InodeGetReqBatch{VolName: c.mp.config.VolName, PartitionID: pid, Inodes: group[start:end], InnerReq: true}
+			resp := &proto.BatchInodeGetResponse{}
+			var status uint8
+			if status, err = c.request(views[pid], proto.OpMetaBatchInodeGet, req, resp); err != nil {
+				return
+			}
+			if status != proto.OpOk {
+				return nil, fmt.Errorf("get inodes of mp(%v) failed: status %v", pid, proto.GetStatusStr(status))
+			}
+			for _, info := range resp.Infos {
+				infos[info.Inode] = info
+			}
+		}
+	}
+	return
+}
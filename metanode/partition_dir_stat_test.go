@@ -0,0 +1,126 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirStat(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.config.Start = 1
+	mp.config.End = 1000
+	mp.config.Cursor = 10
+	mp.config.NodeId = 1
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+	mp.inodeTree.ReplaceOrInsert(NewInode(1, DirModeType), true)
+	mp.reloadVolConfig(&proto.SimpleVolView{EnableDirStat: true})
+
+	uniqID := uint64(100)
+	create := func(parent uint64, name string, mode uint32) uint64 {
+		uniqID++
+		p := &Packet{}
+		item := &proto.CreateDentryInodeItem{ParentID: parent, Name: name, Mode: mode}
+		req := &BatchCreateDentryInodeReq{
+			UniqID:      uniqID,
+			Items:       []*proto.CreateDentryInodeItem{item},
+			StorageType: proto.StorageClass_Replica_SSD,
+		}
+		require.NoError(t, mp.BatchCreateDentryInode(req, p, ""))
+		resp := &BatchCreateDentryInodeResp{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		require.Equal(t, proto.OpOk, resp.Results[0].Status)
+		return resp.Results[0].Inode
+	}
+	dirStat := func(ino uint64) proto.DirStat {
+		p := &Packet{}
+		require.NoError(t, mp.InodeGet(&InodeGetReq{Inode: ino}, p))
+		resp := &proto.InodeGetResponse{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		require.NotNil(t, resp.Info.DirStat)
+		return *resp.Info.DirStat
+	}
+
+	// the dentry ops count the direct children
+	dir := create(1, "a", DirModeType)
+	f1 := create(dir, "f1", FileModeType)
+	f2 := create(dir, "f2", FileModeType)
+	sub := create(dir, "sub", DirModeType)
+	create(sub, "g", FileModeType)
+	require.Equal(t, proto.DirStat{Children: 3, Files: 2, Dirs: 1}, dirStat(dir))
+	require.Equal(t, proto.DirStat{Children: 1, Files: 1}, dirStat(sub))
+	require.Equal(t, proto.DirStat{Children: 1, Dirs: 1}, dirStat(1))
+	resp := mp.fsmDeleteDentry(&Dentry{ParentId: dir, Name: "f2", Inode: f2}, true)
+	require.Equal(t, proto.OpOk, resp.Status)
+	require.Equal(t, proto.DirStat{Children: 2, Files: 1, Dirs: 1}, dirStat(dir))
+
+	// the reconciliation sums the subtrees up, with the inodes of other partitions
+	mp.inodeTree.Get(NewInode(f1, 0)).(*Inode).Size = 100
+	require.Equal(t, proto.OpOk, mp.fsmCreateDentry(&Dentry{ParentId: dir, Name: "rf", Inode: 5000, Type: FileModeType}, false))
+	require.Equal(t, proto.OpOk, mp.fsmCreateDentry(&Dentry{ParentId: dir, Name: "rd", Inode: 6000, Type: DirModeType}, false))
+	c := newDirStatChecker(mp)
+	c.views = []*proto.MetaPartitionView{
+		{PartitionID: mp.config.PartitionId, Start: 1, End: 1000},
+		{PartitionID: 2, Start: 1001, End: 10000},
+	}
+	c.request = func(view *proto.MetaPartitionView, op uint8, req, resp interface{}) (uint8, error) {
+		require.EqualValues(t, 2, view.PartitionID)
+		require.Equal(t, proto.OpMetaBatchInodeGet, op)
+		require.ElementsMatch(t, []uint64{5000, 6000}, req.(*InodeGetReqBatch).Inodes)
+		resp.(*proto.BatchInodeGetResponse).Infos = []*proto.InodeInfo{
+			{Inode: 5000, Mode: FileModeType, Size: 50},
+			{Inode: 6000, Mode: DirModeType, DirStat: &proto.DirStat{Children: 2, Files: 3, Dirs: 1, Size: 300}},
+		}
+		return proto.OpOk, nil
+	}
+	repaired, err := c.run()
+	require.NoError(t, err)
+	require.Equal(t, 2, repaired)
+	require.Equal(t, proto.DirStat{Children: 4, Files: 6, Dirs: 3, Size: 450}, dirStat(dir))
+	require.Equal(t, proto.DirStat{Children: 1, Files: 6, Dirs: 4, Size: 450}, dirStat(1))
+	require.Equal(t, proto.DirStat{Children: 1, Files: 1}, dirStat(sub))
+
+	// the stats changed since counted are left to the next round
+	req := &dirStatRepairRequest{Items: []*dirStatRepair{
+		{Inode: sub, Old: proto.DirStat{Children: 2}, New: proto.DirStat{}},
+		{Inode: sub, Old: proto.DirStat{Children: 1, Files: 1}, New: proto.DirStat{Children: 1, Files: 1, Size: 10}},
+	}}
+	require.Equal(t, 1, mp.fsmRepairDirStats(req))
+	require.Equal(t, proto.DirStat{Children: 1, Files: 1, Size: 10}, dirStat(sub))
+
+	// the stats are inner xattrs
+	p := &Packet{}
+	require.NoError(t, mp.ListXAttr(&proto.ListXAttrRequest{Inode: dir}, p))
+	list := &proto.ListXAttrResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, list))
+	require.Empty(t, list.XAttrs)
+
+	// nothing is counted nor replied unless the vol enables it
+	mp.reloadVolConfig(&proto.SimpleVolView{})
+	create(sub, "h", FileModeType)
+	p = &Packet{}
+	require.NoError(t, mp.InodeGet(&InodeGetReq{Inode: sub}, p))
+	getResp := &proto.InodeGetResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, getResp))
+	require.Nil(t, getResp.Info.DirStat)
+	require.Equal(t, proto.DirStat{Children: 1, Files: 1, Size: 10}, mp.getDirStat(sub))
+}
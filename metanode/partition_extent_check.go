@@ -0,0 +1,92 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// malformedExtentKey returns why the key could not be written by a sane client, empty if
+// it could.
+func malformedExtentKey(ek *proto.ExtentKey) string {
+	switch {
+	case ek.PartitionId == 0:
+		return "no partition"
+	case ek.ExtentId == 0:
+		return "no extent"
+	case ek.Size == 0:
+		return "zero size"
+	case ek.FileOffset+uint64(ek.Size) < ek.FileOffset:
+		return "file range overflows"
+	case ek.ExtentOffset+uint64(ek.Size) < ek.ExtentOffset:
+		return "extent range overflows"
+	}
+	return ""
+}
+
+// aliasedExtents returns the keys of the inode sharing a range of the extent data of ek at
+// another file offset. The data of an extent is written once, so a range of it is mapped to
+// one file offset only, unlike the keys overwritten or split which keep the same mapping.
+func (i *Inode) aliasedExtents(ek *proto.ExtentKey) (extents []proto.ExtentKey) {
+	i.RLock()
+	defer i.RUnlock()
+	if i.HybridCloudExtents.sortedEks == nil {
+		return
+	}
+	se, ok := i.HybridCloudExtents.sortedEks.(*SortedExtents)
+	if !ok {
+		return
+	}
+	end := ek.ExtentOffset + uint64(ek.Size)
+	se.Range(func(_ int, key proto.ExtentKey) bool {
+		if key.PartitionId != ek.PartitionId || key.ExtentId != ek.ExtentId ||
+			key.ExtentOffset >= end || key.ExtentOffset+uint64(key.Size) <= ek.ExtentOffset {
+			return true
+		}
+		if key.FileOffset-key.ExtentOffset != ek.FileOffset-ek.ExtentOffset {
+			extents = append(extents, key)
+		}
+		return true
+	})
+	return
+}
+
+// checkExtentKeys rejects the keys appended to the inode if any of them is malformed or
+// aliases the data of another key under the check of the volume, listing the offending
+// ranges in the error.
+func (mp *metaPartition) checkExtentKeys(ino *Inode, eks ...proto.ExtentKey) (err error) {
+	if !mp.GetVolConfig().EnableCheckExtentKey {
+		return
+	}
+	problems := make([]string, 0)
+	for idx := range eks {
+		ek := &eks[idx]
+		if reason := malformedExtentKey(ek); reason != "" {
+			problems = append(problems, fmt.Sprintf("ek(%v) %v", *ek, reason))
+			continue
+		}
+		for _, key := range ino.aliasedExtents(ek) {
+			problems = append(problems, fmt.Sprintf("file[%v, %v) of ek(%v) aliases file[%v, %v) of ek(%v)",
+				ek.FileOffset, ek.FileOffset+uint64(ek.Size), *ek, key.FileOffset, key.FileOffset+uint64(key.Size), key))
+		}
+	}
+	if len(problems) > 0 {
+		err = fmt.Errorf("inode(%v) invalid extent keys: %v", ino.Inode, strings.Join(problems, "; "))
+	}
+	return
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckExtentKeys(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}}
+	ino := newConflictTestInode()
+	aliased := proto.ExtentKey{FileOffset: 5000, Size: 100, PartitionId: 1, ExtentId: 2, ExtentOffset: 900}
+	malformed := proto.ExtentKey{FileOffset: 3000, Size: 0, PartitionId: 1, ExtentId: 4}
+
+	// not checked unless enabled
+	require.NoError(t, mp.checkExtentKeys(ino, aliased, malformed))
+
+	mp.reloadVolConfig(&proto.SimpleVolView{EnableCheckExtentKey: true})
+	// overwriting or appending keeps the mapping of the extents
+	require.NoError(t, mp.checkExtentKeys(ino,
+		proto.ExtentKey{FileOffset: 1500, Size: 1000, PartitionId: 1, ExtentId: 2, ExtentOffset: 500},
+		proto.ExtentKey{FileOffset: 3000, Size: 1000, PartitionId: 1, ExtentId: 4}))
+
+	require.Empty(t, ino.aliasedExtents(&proto.ExtentKey{FileOffset: 5000, Size: 100, PartitionId: 1, ExtentId: 2, ExtentOffset: 1000}))
+	require.Len(t, ino.aliasedExtents(&aliased), 1)
+	err := mp.checkExtentKeys(ino, aliased, malformed)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "file[5000, 5100)")
+	require.Contains(t, err.Error(), "file[1000, 2000)")
+	require.Contains(t, err.Error(), "zero size")
+
+	for _, ek := range []proto.ExtentKey{
+		{Size: 1, ExtentId: 1},
+		{Size: 1, PartitionId: 1},
+		{FileOffset: ^uint64(0), Size: 2, PartitionId: 1, ExtentId: 1},
+		{ExtentOffset: ^uint64(0), Size: 2, PartitionId: 1, ExtentId: 1},
+	} {
+		require.NotEmpty(t, malformedExtentKey(&ek), ek)
+	}
+}
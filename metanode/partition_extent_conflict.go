@@ -0,0 +1,139 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// overlappedExtents returns the keys of other extents in the inode overlapped by eks, they are
+// checked against the extents before any of eks is appended, as eks come from one writer.
+func (i *Inode) overlappedExtents(eks []proto.ExtentKey) (extents []proto.ExtentKey) {
+	i.RLock()
+	defer i.RUnlock()
+	if i.HybridCloudExtents.sortedEks == nil {
+		return
+	}
+	se, ok := i.HybridCloudExtents.sortedEks.(*SortedExtents)
+	if !ok {
+		return
+	}
+	for idx := range eks {
+		extents = append(extents, se.OverlappedExtents(&eks[idx])...)
+	}
+	return
+}
+
+// checkExtentConflict decides the append of eks by the writer whose extents are of generation
+// gen under policy. The append overlapping no other extents, or of the writer based on the
+// current extents such as the fallback of its own overwrites, goes on. Otherwise reject refuses
+// it, and lww refuses the stale one for the writer to refresh the extents and retry, the one
+// without generation is left to the discard check as before.
+func (i *Inode) checkExtentConflict(eks []proto.ExtentKey, gen uint64, policy string) (overlapped []proto.ExtentKey, status uint8) {
+	status = proto.OpOk
+	if policy == proto.ExtentConflictPolicyNone {
+		return
+	}
+	if overlapped = i.overlappedExtents(eks); len(overlapped) == 0 {
+		return
+	}
+	i.RLock()
+	cur := i.Generation
+	i.RUnlock()
+	if gen != 0 && gen == cur {
+		return
+	}
+	switch policy {
+	case proto.ExtentConflictPolicyReject:
+		status = proto.OpConflictExtentsErr
+	case proto.ExtentConflictPolicyLWW:
+		if gen != 0 {
+			status = proto.OpStaleExtentsErr
+		}
+	}
+	return
+}
+
+// extentConflictPolicyOf returns the extent conflict policy carried by the fsm op of an append.
+func extentConflictPolicyOf(op uint32) string {
+	switch op {
+	case opFSMExtentsAddRejectConflict, opFSMExtentsAddWithCheckRejectConflict:
+		return proto.ExtentConflictPolicyReject
+	case opFSMExtentsAddLWW, opFSMExtentsAddWithCheckLWW:
+		return proto.ExtentConflictPolicyLWW
+	default:
+		return proto.ExtentConflictPolicyNone
+	}
+}
+
+// extentsAddOp returns the fsm op of the appends without discard check. The conflicts are checked
+// by the fsm, so that concurrent appends are checked in the order they are applied, and the policy
+// is carried by the op for all the replicas to apply the same way.
+func (mp *metaPartition) extentsAddOp() uint32 {
+	switch mp.GetVolConfig().ExtentConflictPolicy {
+	case proto.ExtentConflictPolicyReject:
+		return opFSMExtentsAddRejectConflict
+	case proto.ExtentConflictPolicyLWW:
+		return opFSMExtentsAddLWW
+	default:
+		return opFSMExtentsAdd
+	}
+}
+
+// extentsAddWithCheckOp is extentsAddOp of the appends with discard check.
+func (mp *metaPartition) extentsAddWithCheckOp() uint32 {
+	switch mp.GetVolConfig().ExtentConflictPolicy {
+	case proto.ExtentConflictPolicyReject:
+		return opFSMExtentsAddWithCheckRejectConflict
+	case proto.ExtentConflictPolicyLWW:
+		return opFSMExtentsAddWithCheckLWW
+	default:
+		return opFSMExtentsAddWithCheck
+	}
+}
+
+// fsmAppendExtentsWithConflictCheck checks the extent appended to ino against the other extents
+// under policy, before the discard check of the append. ino carries the generation of the writer.
+func (mp *metaPartition) fsmAppendExtentsWithConflictCheck(ino *Inode, policy string) (status uint8) {
+	item := mp.inodeTree.Get(ino)
+	if item != nil && ino.HybridCloudExtents.sortedEks != nil {
+		eks := ino.HybridCloudExtents.sortedEks.(*SortedExtents).CopyExtents()
+		if len(eks) > 0 {
+			overlapped, st := item.(*Inode).checkExtentConflict(eks[:1], ino.Generation, policy)
+			if st != proto.OpOk {
+				log.LogWarnf("fsmAppendExtentsWithConflictCheck: mp[%v] inode[%v] gen(%v) ek(%v) conflict with extents(%v) under policy(%v)",
+					mp.config.PartitionId, ino.Inode, ino.Generation, eks[0], overlapped, policy)
+				return st
+			}
+		}
+	}
+	return mp.fsmAppendExtentsWithCheck(ino, false)
+}
+
+// appendExtentKeyReply returns the reply of the append with check to the inode.
+func (mp *metaPartition) appendExtentKeyReply(inode uint64) []byte {
+	resp := &proto.AppendExtentKeyResponse{}
+	if item := mp.inodeTree.Get(NewInode(inode, 0)); item != nil {
+		ino := item.(*Inode)
+		ino.RLock()
+		resp.Generation = ino.Generation
+		ino.RUnlock()
+	}
+	reply, _ := json.Marshal(resp)
+	return reply
+}
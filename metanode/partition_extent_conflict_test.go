@@ -0,0 +1,184 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func newConflictTestInode() *Inode {
+	ino := NewInode(10, FileModeType)
+	se := NewSortedExtents()
+	se.AppendWithCheck(ino.Inode, proto.ExtentKey{FileOffset: 0, Size: 1000, PartitionId: 1, ExtentId: 1}, nil, nil)
+	se.AppendWithCheck(ino.Inode, proto.ExtentKey{FileOffset: 1000, Size: 1000, PartitionId: 1, ExtentId: 2}, nil, nil)
+	se.AppendWithCheck(ino.Inode, proto.ExtentKey{FileOffset: 2000, Size: 1000, PartitionId: 1, ExtentId: 3}, nil, nil)
+	ino.HybridCloudExtents.sortedEks = se
+	return ino
+}
+
+func TestOverlappedExtents(t *testing.T) {
+	ino := newConflictTestInode()
+
+	// appending to the tail overlaps nothing
+	require.Empty(t, ino.overlappedExtents([]proto.ExtentKey{{FileOffset: 3000, Size: 1000, PartitionId: 1, ExtentId: 4}}))
+	// the writer extending its own extent overlaps nothing
+	require.Empty(t, ino.overlappedExtents([]proto.ExtentKey{{FileOffset: 2000, Size: 2000, PartitionId: 1, ExtentId: 3}}))
+
+	// partially overlapped keys are returned as well
+	overlapped := ino.overlappedExtents([]proto.ExtentKey{{FileOffset: 500, Size: 1000, PartitionId: 1, ExtentId: 4}})
+	require.Len(t, overlapped, 2)
+	require.EqualValues(t, 1, overlapped[0].ExtentId)
+	require.EqualValues(t, 2, overlapped[1].ExtentId)
+	// the same extent mapped to another offset is written by another writer
+	overlapped = ino.overlappedExtents([]proto.ExtentKey{{FileOffset: 1500, Size: 100, PartitionId: 1, ExtentId: 1}})
+	require.Len(t, overlapped, 1)
+	require.EqualValues(t, 2, overlapped[0].ExtentId)
+
+	// the keys of a batch are checked against the extents before the batch
+	overlapped = ino.overlappedExtents([]proto.ExtentKey{
+		{FileOffset: 3000, Size: 1000, PartitionId: 1, ExtentId: 4},
+		{FileOffset: 3500, Size: 1000, PartitionId: 1, ExtentId: 5},
+	})
+	require.Empty(t, overlapped)
+}
+
+func TestCheckExtentConflict(t *testing.T) {
+	ino := newConflictTestInode()
+	ino.Generation = 5
+	ek := []proto.ExtentKey{{FileOffset: 500, Size: 1000, PartitionId: 1, ExtentId: 4}}
+	tail := []proto.ExtentKey{{FileOffset: 3000, Size: 1000, PartitionId: 1, ExtentId: 4}}
+
+	for _, policy := range []string{proto.ExtentConflictPolicyNone, proto.ExtentConflictPolicyReject, proto.ExtentConflictPolicyLWW} {
+		// the appends overlapping nothing, or of the writer based on the current extents, go on
+		_, status := ino.checkExtentConflict(tail, 0, policy)
+		require.Equal(t, proto.OpOk, status)
+		_, status = ino.checkExtentConflict(ek, 5, policy)
+		require.Equal(t, proto.OpOk, status)
+	}
+	_, status := ino.checkExtentConflict(ek, 4, proto.ExtentConflictPolicyNone)
+	require.Equal(t, proto.OpOk, status)
+
+	overlapped, status := ino.checkExtentConflict(ek, 4, proto.ExtentConflictPolicyReject)
+	require.Equal(t, proto.OpConflictExtentsErr, status)
+	require.Len(t, overlapped, 2)
+	_, status = ino.checkExtentConflict(ek, 0, proto.ExtentConflictPolicyReject)
+	require.Equal(t, proto.OpConflictExtentsErr, status)
+
+	// the stale writer retries on the current extents, the one without generation is left to the discard check
+	_, status = ino.checkExtentConflict(ek, 4, proto.ExtentConflictPolicyLWW)
+	require.Equal(t, proto.OpStaleExtentsErr, status)
+	_, status = ino.checkExtentConflict(ek, 0, proto.ExtentConflictPolicyLWW)
+	require.Equal(t, proto.OpOk, status)
+}
+
+func TestExtentConflictPolicy(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}}
+	require.EqualValues(t, opFSMExtentsAdd, mp.extentsAddOp())
+	require.EqualValues(t, opFSMExtentsAddWithCheck, mp.extentsAddWithCheckOp())
+	mp.reloadVolConfig(&proto.SimpleVolView{ExtentConflictPolicy: proto.ExtentConflictPolicyReject})
+	require.EqualValues(t, opFSMExtentsAddRejectConflict, mp.extentsAddOp())
+	require.EqualValues(t, opFSMExtentsAddWithCheckRejectConflict, mp.extentsAddWithCheckOp())
+	mp.reloadVolConfig(&proto.SimpleVolView{ExtentConflictPolicy: proto.ExtentConflictPolicyLWW})
+	require.EqualValues(t, opFSMExtentsAddLWW, mp.extentsAddOp())
+	require.EqualValues(t, opFSMExtentsAddWithCheckLWW, mp.extentsAddWithCheckOp())
+	for op, policy := range map[uint32]string{
+		opFSMExtentsAdd:                        proto.ExtentConflictPolicyNone,
+		opFSMExtentsAddRejectConflict:          proto.ExtentConflictPolicyReject,
+		opFSMExtentsAddLWW:                     proto.ExtentConflictPolicyLWW,
+		opFSMExtentsAddWithCheck:               proto.ExtentConflictPolicyNone,
+		opFSMExtentsAddWithCheckRejectConflict: proto.ExtentConflictPolicyReject,
+		opFSMExtentsAddWithCheckLWW:            proto.ExtentConflictPolicyLWW,
+	} {
+		require.Equal(t, policy, extentConflictPolicyOf(op))
+	}
+
+	// invalid policy is ignored
+	mp.reloadVolConfig(&proto.SimpleVolView{ExtentConflictPolicy: "invalid"})
+	require.Equal(t, proto.ExtentConflictPolicyLWW, mp.GetVolConfig().ExtentConflictPolicy)
+}
+
+func newConflictTestPartition(t *testing.T) (mp *metaPartition, ino *Inode) {
+	mp = newPartition(&MetaPartitionConfig{PartitionId: 1, VolName: "test"}, nil)
+	ino = NewInode(10, FileModeType)
+	ino.StorageClass = proto.StorageClass_Replica_HDD
+	ino.HybridCloudExtents.sortedEks = NewSortedExtents()
+	require.Equal(t, proto.OpOk, mp.fsmCreateInode(ino))
+	return
+}
+
+func TestFsmAppendExtentsRejectConflict(t *testing.T) {
+	mp, ino := newConflictTestPartition(t)
+	appendExtents := func(policy string, eks ...proto.ExtentKey) uint8 {
+		req := NewInode(ino.Inode, 0)
+		req.Generation = 0
+		se := NewSortedExtents()
+		for _, ek := range eks {
+			se.Append(ek)
+		}
+		req.HybridCloudExtents.sortedEks = se
+		return mp.fsmAppendExtents(req, policy)
+	}
+	reject := proto.ExtentConflictPolicyReject
+	require.Equal(t, proto.OpOk, appendExtents(reject, proto.ExtentKey{FileOffset: 0, Size: 1000, PartitionId: 1, ExtentId: 4}))
+	// the writer extends its own extent
+	require.Equal(t, proto.OpOk, appendExtents(reject, proto.ExtentKey{FileOffset: 0, Size: 2000, PartitionId: 1, ExtentId: 4}))
+	// another writer partially overlapping the extent is rejected, as it is applied later
+	require.Equal(t, proto.OpConflictExtentsErr, appendExtents(reject, proto.ExtentKey{FileOffset: 1500, Size: 1000, PartitionId: 1, ExtentId: 5}))
+	require.Equal(t, proto.OpOk, appendExtents(reject,
+		proto.ExtentKey{FileOffset: 2000, Size: 1000, PartitionId: 1, ExtentId: 5},
+		proto.ExtentKey{FileOffset: 2500, Size: 1000, PartitionId: 1, ExtentId: 6}))
+	// the overlap is allowed without policy, and under lww without generation
+	require.Equal(t, proto.OpOk, appendExtents(proto.ExtentConflictPolicyLWW, proto.ExtentKey{FileOffset: 1500, Size: 1000, PartitionId: 1, ExtentId: 7}))
+	require.Equal(t, proto.OpOk, appendExtents(proto.ExtentConflictPolicyNone, proto.ExtentKey{FileOffset: 1500, Size: 1000, PartitionId: 1, ExtentId: 8}))
+}
+
+func TestFsmAppendExtentsWithConflictCheck(t *testing.T) {
+	mp, ino := newConflictTestPartition(t)
+	generation := func() uint64 {
+		return mp.inodeTree.Get(NewInode(ino.Inode, 0)).(*Inode).Generation
+	}
+	appendExtent := func(policy string, gen uint64, ek proto.ExtentKey, discard ...proto.ExtentKey) uint8 {
+		req := NewInode(ino.Inode, 0)
+		req.Generation = gen
+		req.StorageClass = ino.StorageClass
+		se := NewSortedExtents()
+		se.Append(ek)
+		se.eks = append(se.eks, discard...)
+		req.HybridCloudExtents.sortedEks = se
+		return mp.fsmAppendExtentsWithConflictCheck(req, policy)
+	}
+	reject, lww := proto.ExtentConflictPolicyReject, proto.ExtentConflictPolicyLWW
+	first := proto.ExtentKey{FileOffset: 0, Size: 1000, PartitionId: 1, ExtentId: 4}
+	gen := generation()
+	require.Equal(t, proto.OpOk, appendExtent(reject, gen, first))
+	require.Equal(t, gen+1, generation())
+
+	// the fallback of the writer overwriting its own extent goes on, as it is based on the current extents
+	row := proto.ExtentKey{FileOffset: 0, Size: 1000, PartitionId: 1, ExtentId: 5}
+	require.Equal(t, proto.OpOk, appendExtent(reject, gen+1, row, first))
+	// another writer based on the extents before is rejected
+	other := proto.ExtentKey{FileOffset: 500, Size: 1000, PartitionId: 1, ExtentId: 6}
+	require.Equal(t, proto.OpConflictExtentsErr, appendExtent(reject, gen+1, other))
+	require.Equal(t, proto.OpConflictExtentsErr, appendExtent(reject, 0, other))
+
+	// under lww the stale writer is told to retry, and wins on the current extents
+	require.Equal(t, proto.OpStaleExtentsErr, appendExtent(lww, gen+1, other))
+	require.Equal(t, proto.OpOk, appendExtent(lww, generation(), other))
+	// the appends overlapping nothing go on whatever the generation is
+	require.Equal(t, proto.OpOk, appendExtent(reject, 1, proto.ExtentKey{FileOffset: 2000, Size: 1000, PartitionId: 1, ExtentId: 7}))
+}
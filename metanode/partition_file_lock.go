@@ -0,0 +1,516 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	innerFileLockKey = "cfs_inner_xattr_file_lock_key"
+
+	defaultFileLockLease   = 30              // unit seconds
+	maxFileLockWait        = 3 * time.Second // under the read deadline of the clients, not to grant a lock given up on
+	fileLockExpireInterval = 5 * time.Second
+)
+
+// fileLockExpireRequest releases the locks whose leases expire before Time.
+type fileLockExpireRequest struct {
+	Time int64 `json:"time"`
+}
+
+// fileLockIndex indexes the inodes of the partition having file locks by the first expire of
+// their locks and by the clients owning them, the locks themselves are kept in the extends of
+// the inodes and go through raft and the snapshots with them. The index is rebuilt once the
+// extend tree is replaced, e.g. by a snapshot. The waiters of an inode are woken once its locks
+// change.
+type fileLockIndex struct {
+	sync.Mutex
+	tree    *BTree
+	expires map[uint64]int64
+	owners  map[proto.FileLockOwner]map[uint64]struct{}
+	waiters map[uint64]chan struct{}
+}
+
+func (idx *fileLockIndex) waiter(ino uint64) <-chan struct{} {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.waiters == nil {
+		idx.waiters = make(map[uint64]chan struct{})
+	}
+	c, ok := idx.waiters[ino]
+	if !ok {
+		c = make(chan struct{})
+		idx.waiters[ino] = c
+	}
+	return c
+}
+
+// rebuild builds the index from the tree if it is not the one indexed. The index lock must
+// be held.
+func (idx *fileLockIndex) rebuild(tree *BTree) {
+	if idx.tree == tree {
+		return
+	}
+	idx.tree = tree
+	idx.expires = make(map[uint64]int64)
+	idx.owners = make(map[proto.FileLockOwner]map[uint64]struct{})
+	tree.Ascend(func(i BtreeItem) bool {
+		e := i.(*Extend)
+		idx.set(e.inode, decodeFileLocks(e))
+		return true
+	})
+}
+
+// set indexes the locks of the inode. The index lock must be held.
+func (idx *fileLockIndex) set(ino uint64, locks []*proto.FileLock) {
+	for owner, inos := range idx.owners {
+		if _, ok := inos[ino]; !ok {
+			continue
+		}
+		delete(inos, ino)
+		if len(inos) == 0 {
+			delete(idx.owners, owner)
+		}
+	}
+	if len(locks) == 0 {
+		delete(idx.expires, ino)
+		return
+	}
+	idx.expires[ino] = firstFileLockExpire(locks)
+	for _, lk := range locks {
+		inos, ok := idx.owners[lk.FileLockOwner]
+		if !ok {
+			inos = make(map[uint64]struct{})
+			idx.owners[lk.FileLockOwner] = inos
+		}
+		inos[ino] = struct{}{}
+	}
+}
+
+// inodes returns the inodes having locks.
+func (idx *fileLockIndex) inodes(tree *BTree) (inos []uint64) {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.rebuild(tree)
+	inos = make([]uint64, 0, len(idx.expires))
+	for ino := range idx.expires {
+		inos = append(inos, ino)
+	}
+	return
+}
+
+// ownerInodes returns the inodes having locks of the owner.
+func (idx *fileLockIndex) ownerInodes(tree *BTree, owner proto.FileLockOwner) (inos []uint64) {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.rebuild(tree)
+	inos = make([]uint64, 0, len(idx.owners[owner]))
+	for ino := range idx.owners[owner] {
+		inos = append(inos, ino)
+	}
+	return
+}
+
+func (idx *fileLockIndex) update(ino uint64, locks []*proto.FileLock) {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.expires != nil {
+		idx.set(ino, locks)
+	}
+	if c, ok := idx.waiters[ino]; ok {
+		close(c)
+		delete(idx.waiters, ino)
+	}
+}
+
+// hasExpired returns if any lock has expired at now, false if the index is not built yet.
+func (idx *fileLockIndex) hasExpired(now int64) bool {
+	idx.Lock()
+	defer idx.Unlock()
+	for _, expire := range idx.expires {
+		if expire < now {
+			return true
+		}
+	}
+	return false
+}
+
+func firstFileLockExpire(locks []*proto.FileLock) (expire int64) {
+	expire = math.MaxInt64
+	for _, lk := range locks {
+		if lk.Expire < expire {
+			expire = lk.Expire
+		}
+	}
+	return
+}
+
+func decodeFileLocks(e *Extend) (locks []*proto.FileLock) {
+	val, _ := e.Get([]byte(innerFileLockKey))
+	if len(val) == 0 {
+		return
+	}
+	if err := json.Unmarshal(val, &locks); err != nil {
+		log.LogErrorf("decodeFileLocks: ino(%v) parse locks failed, val %s, err %v", e.inode, val, err)
+		return nil
+	}
+	return
+}
+
+func fileLockOverlap(a, b *proto.FileLock) bool {
+	return a.Start < b.End && b.Start < a.End
+}
+
+func fileLockSameOwner(a, b *proto.FileLock) bool {
+	return a.FileLockOwner == b.FileLockOwner && a.Owner == b.Owner
+}
+
+// fileLockConflict returns the lock conflicting with lk. The flocks and the POSIX locks do not
+// conflict with each other, as the local file systems.
+func fileLockConflict(locks []*proto.FileLock, lk *proto.FileLock) *proto.FileLock {
+	if lk.Type == proto.FileLockUnlock {
+		return nil
+	}
+	for _, l := range locks {
+		if l.Flock != lk.Flock || fileLockSameOwner(l, lk) || !fileLockOverlap(l, lk) {
+			continue
+		}
+		if l.Type == proto.FileLockWrite || lk.Type == proto.FileLockWrite {
+			return l
+		}
+	}
+	return nil
+}
+
+// applyFileLock sets the range of lk of its owner, the locks of the owner over the range are
+// replaced by lk or removed if it is an unlock.
+func applyFileLock(locks []*proto.FileLock, lk *proto.FileLock) (newLocks []*proto.FileLock) {
+	newLocks = make([]*proto.FileLock, 0, len(locks)+2)
+	for _, l := range locks {
+		if l.Flock != lk.Flock || !fileLockSameOwner(l, lk) || !fileLockOverlap(l, lk) {
+			newLocks = append(newLocks, l)
+			continue
+		}
+		if l.Start < lk.Start {
+			left := *l
+			left.End = lk.Start
+			newLocks = append(newLocks, &left)
+		}
+		if lk.End < l.End {
+			right := *l
+			right.Start = lk.End
+			newLocks = append(newLocks, &right)
+		}
+	}
+	if lk.Type != proto.FileLockUnlock {
+		newLocks = append(newLocks, lk)
+	}
+	return
+}
+
+// liveFileLocks drops the locks expired at now.
+func liveFileLocks(locks []*proto.FileLock, now int64) (live []*proto.FileLock) {
+	live = locks[:0]
+	for _, lk := range locks {
+		if lk.Expire >= now {
+			live = append(live, lk)
+		}
+	}
+	return
+}
+
+// normalizeFileLock sets a flock over the whole file, and the end 0 to the end of file.
+func normalizeFileLock(lk *proto.FileLock) {
+	if lk.Flock {
+		lk.Start, lk.End = 0, math.MaxUint64
+	} else if lk.End == 0 {
+		lk.End = math.MaxUint64
+	}
+}
+
+// loadFileLocks returns the extend of the inode in the tree and the locks unexpired at now,
+// the extend is nil if the inode has none. The xattrLock must be held.
+func (mp *metaPartition) loadFileLocks(ino uint64, now int64) (e *Extend, locks []*proto.FileLock) {
+	item := mp.extendTree.CopyGet(NewExtend(ino))
+	if item == nil {
+		return
+	}
+	e = item.(*Extend)
+	return e, liveFileLocks(decodeFileLocks(e), now)
+}
+
+// storeFileLocks keeps the locks of the inode in its extend. The xattrLock must be held.
+func (mp *metaPartition) storeFileLocks(ino uint64, e *Extend, locks []*proto.FileLock) {
+	defer mp.fileLocks.update(ino, locks)
+	if len(locks) == 0 {
+		if e != nil {
+			e.Remove([]byte(innerFileLockKey))
+		}
+		return
+	}
+	val, err := json.Marshal(locks)
+	if err != nil {
+		log.LogErrorf("storeFileLocks: mp(%v) ino(%v) marshal locks failed, err %v", mp.config.PartitionId, ino, err)
+		return
+	}
+	newExtend := NewExtend(ino)
+	newExtend.Put([]byte(innerFileLockKey), val, 0)
+	if e == nil {
+		mp.extendTree.ReplaceOrInsert(newExtend, true)
+		return
+	}
+	e.Merge(newExtend, true)
+}
+
+func (mp *metaPartition) fsmFileLock(req *proto.FileLockRequest) (resp *proto.FileLockResponse) {
+	mp.xattrLock.Lock()
+	defer mp.xattrLock.Unlock()
+
+	resp = &proto.FileLockResponse{Status: proto.OpOk}
+	if ino, ok := mp.inodeTree.Get(NewInode(req.Inode, 0)).(*Inode); !ok || ino.ShouldDelete() {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	now := req.SubmitTime.Unix()
+	e, locks := mp.loadFileLocks(req.Inode, now)
+	lk := req.Lock
+	normalizeFileLock(&lk)
+	lk.Expire = now + int64(req.Lease)
+	if conflict := fileLockConflict(locks, &lk); conflict != nil {
+		resp.Status = proto.OpExistErr
+		resp.Conflict = conflict
+		return
+	}
+	mp.storeFileLocks(req.Inode, e, applyFileLock(locks, &lk))
+	log.LogDebugf("fsmFileLock: mp(%v) ino(%v) lock %+v", mp.config.PartitionId, req.Inode, lk)
+	return
+}
+
+// fsmFileLockLease renews the leases of the locks of the client, or releases them. The locks of
+// the owners not holding them any more in the client are not renewed.
+func (mp *metaPartition) fsmFileLockLease(req *proto.FileLockLeaseRequest) (status uint8) {
+	mp.xattrLock.Lock()
+	defer mp.xattrLock.Unlock()
+
+	var holders map[proto.FileLockHolder]struct{}
+	if len(req.Holders) > 0 {
+		holders = make(map[proto.FileLockHolder]struct{}, len(req.Holders))
+		for _, h := range req.Holders {
+			holders[h] = struct{}{}
+		}
+	}
+	now := req.SubmitTime.Unix()
+	for _, ino := range mp.fileLocks.ownerInodes(mp.extendTree, req.FileLockOwner) {
+		e, locks := mp.loadFileLocks(ino, now)
+		kept := make([]*proto.FileLock, 0, len(locks))
+		owned := false
+		for _, lk := range locks {
+			if lk.FileLockOwner == req.FileLockOwner {
+				if req.Release {
+					owned = true
+					continue
+				}
+				if _, ok := holders[proto.FileLockHolder{Inode: ino, Owner: lk.Owner}]; ok || holders == nil {
+					owned = true
+					lk.Expire = now + int64(req.Lease)
+				}
+			}
+			kept = append(kept, lk)
+		}
+		if owned {
+			mp.storeFileLocks(ino, e, kept)
+		}
+	}
+	return proto.OpOk
+}
+
+// fsmFileLockExpire releases the locks of the clients whose leases have expired, e.g. of the
+// clients crashed.
+func (mp *metaPartition) fsmFileLockExpire(req *fileLockExpireRequest) {
+	mp.xattrLock.Lock()
+	defer mp.xattrLock.Unlock()
+
+	for _, ino := range mp.fileLocks.inodes(mp.extendTree) {
+		item := mp.extendTree.CopyGet(NewExtend(ino))
+		if item == nil {
+			mp.fileLocks.update(ino, nil)
+			continue
+		}
+		e := item.(*Extend)
+		locks := decodeFileLocks(e)
+		cnt := len(locks)
+		if locks = liveFileLocks(locks, req.Time); len(locks) == cnt {
+			continue
+		}
+		log.LogInfof("fsmFileLockExpire: mp(%v) ino(%v) release %v expired locks", mp.config.PartitionId, ino, cnt-len(locks))
+		mp.storeFileLocks(ino, e, locks)
+	}
+}
+
+// FileLock acquires or releases the lock of the inode through raft, a blocking request waits on
+// the leader for the conflicting locks to be released until its wait is up, and is proposed only
+// once it does not conflict. A test request finds the conflicting lock on the leader without raft.
+func (mp *metaPartition) FileLock(req *proto.FileLockRequest, p *Packet) (err error) {
+	if req.Lease == 0 {
+		req.Lease = defaultFileLockLease
+	}
+	if req.Test {
+		return mp.testFileLock(req, p)
+	}
+	wait := time.Duration(req.WaitMs) * time.Millisecond
+	if wait <= 0 || wait > maxFileLockWait {
+		wait = maxFileLockWait
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	var resp *proto.FileLockResponse
+	for {
+		// taken before the check, not to miss the releases in between
+		waiter := mp.fileLocks.waiter(req.Inode)
+		// a blocking lock conflicting on the leader waits for the locks to change, instead of
+		// proposing to raft each time
+		if req.Block {
+			if resp = mp.checkFileLock(req); resp.Status == proto.OpExistErr {
+				select {
+				case <-waiter:
+					continue
+				case <-timer.C:
+				case <-mp.stopC:
+				}
+				break
+			}
+		}
+		req.SubmitTime = time.Now()
+		var val []byte
+		if val, err = json.Marshal(req); err != nil {
+			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+			return
+		}
+		var r interface{}
+		if r, err = mp.submit(opFSMFileLock, val); err != nil {
+			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+			return
+		}
+		resp = r.(*proto.FileLockResponse)
+		if resp.Status != proto.OpExistErr || !req.Block {
+			break
+		}
+		select {
+		case <-waiter:
+			continue
+		case <-timer.C:
+		case <-mp.stopC:
+		}
+		break
+	}
+	mp.replyFileLock(resp, p)
+	return
+}
+
+func (mp *metaPartition) testFileLock(req *proto.FileLockRequest, p *Packet) (err error) {
+	mp.replyFileLock(mp.checkFileLock(req), p)
+	return
+}
+
+// checkFileLock finds the lock conflicting with the one of req on the leader without raft.
+func (mp *metaPartition) checkFileLock(req *proto.FileLockRequest) (resp *proto.FileLockResponse) {
+	mp.xattrLock.Lock()
+	defer mp.xattrLock.Unlock()
+
+	_, locks := mp.loadFileLocks(req.Inode, time.Now().Unix())
+	lk := req.Lock
+	normalizeFileLock(&lk)
+	resp = &proto.FileLockResponse{Status: proto.OpOk}
+	if ino, ok := mp.inodeTree.Get(NewInode(req.Inode, 0)).(*Inode); !ok || ino.ShouldDelete() {
+		resp.Status = proto.OpNotExistErr
+	} else if conflict := fileLockConflict(locks, &lk); conflict != nil {
+		copied := *conflict
+		resp.Status, resp.Conflict = proto.OpExistErr, &copied
+	}
+	return
+}
+
+func (mp *metaPartition) replyFileLock(resp *proto.FileLockResponse, p *Packet) {
+	status := resp.Status
+	reply, err := json.Marshal(resp)
+	if err != nil {
+		status = proto.OpErr
+		reply = []byte(err.Error())
+	}
+	p.PacketErrorWithBody(status, reply)
+}
+
+// FileLockLease renews the leases of the locks of the client in the partition, or releases
+// them once the client unmounts.
+func (mp *metaPartition) FileLockLease(req *proto.FileLockLeaseRequest, p *Packet) (err error) {
+	if req.Lease == 0 {
+		req.Lease = defaultFileLockLease
+	}
+	req.SubmitTime = time.Now()
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	r, err := mp.submit(opFSMFileLockLease, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketErrorWithBody(r.(uint8), nil)
+	return
+}
+
+// startFileLockExpire releases the expired locks through raft on the leader.
+func (mp *metaPartition) startFileLockExpire() {
+	timer := time.NewTimer(fileLockExpireInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if _, ok := mp.IsLeader(); ok {
+				mp.expireFileLocks()
+			}
+			timer.Reset(fileLockExpireInterval)
+		case <-mp.stopC:
+			return
+		}
+	}
+}
+
+func (mp *metaPartition) expireFileLocks() {
+	mp.xattrLock.Lock()
+	mp.fileLocks.inodes(mp.extendTree)
+	mp.xattrLock.Unlock()
+	now := time.Now().Unix()
+	if !mp.fileLocks.hasExpired(now) {
+		return
+	}
+	val, err := json.Marshal(&fileLockExpireRequest{Time: now})
+	if err != nil {
+		return
+	}
+	if _, err = mp.submit(opFSMFileLockExpire, val); err != nil {
+		log.LogWarnf("[expireFileLocks] mp(%v) submit failed, err %v", mp.config.PartitionId, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFileLock(t *testing.T) {
+	owner := proto.FileLockOwner{ClientID: 1}
+	locks := applyFileLock(nil, &proto.FileLock{FileLockOwner: owner, Type: proto.FileLockWrite, Start: 0, End: 100})
+	// unlocking the middle splits the lock
+	locks = applyFileLock(locks, &proto.FileLock{FileLockOwner: owner, Type: proto.FileLockUnlock, Start: 40, End: 60})
+	require.Len(t, locks, 2)
+	require.Equal(t, [2]uint64{0, 40}, [2]uint64{locks[0].Start, locks[0].End})
+	require.Equal(t, [2]uint64{60, 100}, [2]uint64{locks[1].Start, locks[1].End})
+
+	other := &proto.FileLock{FileLockOwner: proto.FileLockOwner{ClientID: 2}, Type: proto.FileLockRead, Start: 40, End: 60}
+	require.Nil(t, fileLockConflict(locks, other))
+	other.End = 61
+	require.Equal(t, locks[1], fileLockConflict(locks, other))
+	// the flocks do not conflict with the POSIX locks
+	flock := &proto.FileLock{FileLockOwner: proto.FileLockOwner{ClientID: 2}, Type: proto.FileLockWrite, Flock: true}
+	normalizeFileLock(flock)
+	require.Equal(t, uint64(math.MaxUint64), flock.End)
+	require.Nil(t, fileLockConflict(locks, flock))
+
+	// the shared locks are compatible, the own locks never conflict
+	shared := applyFileLock(nil, &proto.FileLock{FileLockOwner: owner, Type: proto.FileLockRead, End: 10})
+	require.Nil(t, fileLockConflict(shared, &proto.FileLock{Type: proto.FileLockRead, End: 10}))
+	require.NotNil(t, fileLockConflict(shared, &proto.FileLock{Type: proto.FileLockWrite, End: 10}))
+	require.Nil(t, fileLockConflict(shared, &proto.FileLock{FileLockOwner: owner, Type: proto.FileLockWrite, End: 10}))
+}
+
+func TestFsmFileLock(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		inodeTree:  NewBtree(),
+		extendTree: NewBtree(),
+	}
+	mp.inodeTree.ReplaceOrInsert(NewInode(10, FileModeType), true)
+	now := time.Now()
+	c1 := proto.FileLockOwner{ClientID: 1, ClientIP: "10.0.0.1"}
+	c2 := proto.FileLockOwner{ClientID: 2, ClientIP: "10.0.0.2"}
+	lockReq := func(owner proto.FileLockOwner, typ uint8, at time.Time) *proto.FileLockRequest {
+		return &proto.FileLockRequest{
+			Inode:      10,
+			Lock:       proto.FileLock{FileLockOwner: owner, Owner: 7, Type: typ, Flock: true},
+			Lease:      30,
+			SubmitTime: at,
+		}
+	}
+
+	// the inode to lock must exist
+	missing := lockReq(c1, proto.FileLockWrite, now)
+	missing.Inode = 11
+	require.Equal(t, proto.OpNotExistErr, mp.fsmFileLock(missing).Status)
+
+	require.Equal(t, proto.OpOk, mp.fsmFileLock(lockReq(c1, proto.FileLockWrite, now)).Status)
+	resp := mp.fsmFileLock(lockReq(c2, proto.FileLockRead, now))
+	require.Equal(t, proto.OpExistErr, resp.Status)
+	require.Equal(t, c1, resp.Conflict.FileLockOwner)
+	require.Equal(t, []uint64{10}, mp.fileLocks.inodes(mp.extendTree))
+	require.Equal(t, []uint64{10}, mp.fileLocks.ownerInodes(mp.extendTree, c1))
+	require.Empty(t, mp.fileLocks.ownerInodes(mp.extendTree, c2))
+
+	// the waiters are woken by the release
+	waiter := mp.fileLocks.waiter(10)
+	require.Equal(t, proto.OpOk, mp.fsmFileLockLease(&proto.FileLockLeaseRequest{FileLockOwner: c1, Release: true, SubmitTime: now}))
+	select {
+	case <-waiter:
+	default:
+		t.Fatal("waiter is not woken")
+	}
+	require.Empty(t, mp.fileLocks.inodes(mp.extendTree))
+
+	// the locks of a client not renewing its lease are released once it expires
+	require.Equal(t, proto.OpOk, mp.fsmFileLock(lockReq(c1, proto.FileLockWrite, now)).Status)
+	later := now.Add(20 * time.Second)
+	require.Equal(t, proto.OpOk, mp.fsmFileLockLease(&proto.FileLockLeaseRequest{FileLockOwner: c1, Lease: 30, SubmitTime: later}))
+	require.False(t, mp.fileLocks.hasExpired(now.Add(40*time.Second).Unix()))
+	require.True(t, mp.fileLocks.hasExpired(later.Add(31*time.Second).Unix()))
+	mp.fsmFileLockExpire(&fileLockExpireRequest{Time: later.Add(31 * time.Second).Unix()})
+	require.Empty(t, mp.fileLocks.inodes(mp.extendTree))
+	require.Equal(t, proto.OpOk, mp.fsmFileLock(lockReq(c2, proto.FileLockWrite, later.Add(31*time.Second))).Status)
+
+	// the index is rebuilt from the locks kept in the extends, e.g. after a snapshot
+	tree := mp.extendTree.GetTree()
+	mp.extendTree = tree
+	require.Equal(t, []uint64{10}, mp.fileLocks.inodes(mp.extendTree))
+	require.Equal(t, []uint64{10}, mp.fileLocks.ownerInodes(mp.extendTree, c2))
+	require.Empty(t, mp.fileLocks.ownerInodes(mp.extendTree, c1))
+	resp = mp.fsmFileLock(lockReq(c1, proto.FileLockWrite, later.Add(32*time.Second)))
+	require.Equal(t, proto.OpExistErr, resp.Status)
+	require.Equal(t, c2, resp.Conflict.FileLockOwner)
+}
+
+func TestFileLockHolders(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		inodeTree:  NewBtree(),
+		extendTree: NewBtree(),
+	}
+	mp.inodeTree.ReplaceOrInsert(NewInode(10, FileModeType), true)
+	now := time.Now()
+	c1 := proto.FileLockOwner{ClientID: 1, ClientIP: "10.0.0.1"}
+	lock := func(owner uint64, start, end uint64) *proto.FileLockRequest {
+		return &proto.FileLockRequest{
+			Inode:      10,
+			Lock:       proto.FileLock{FileLockOwner: c1, Owner: owner, Type: proto.FileLockWrite, Start: start, End: end},
+			Lease:      30,
+			SubmitTime: now,
+		}
+	}
+	require.Equal(t, proto.OpOk, mp.fsmFileLock(lock(7, 0, 10)).Status)
+	require.Equal(t, proto.OpOk, mp.fsmFileLock(lock(8, 10, 20)).Status)
+
+	// only the locks of the owners still holding them in the client are renewed
+	later := now.Add(20 * time.Second)
+	require.Equal(t, proto.OpOk, mp.fsmFileLockLease(&proto.FileLockLeaseRequest{
+		FileLockOwner: c1, Holders: []proto.FileLockHolder{{Inode: 10, Owner: 7}}, Lease: 30, SubmitTime: later,
+	}))
+	mp.fsmFileLockExpire(&fileLockExpireRequest{Time: now.Add(31 * time.Second).Unix()})
+	_, locks := mp.loadFileLocks(10, now.Add(31*time.Second).Unix())
+	require.Len(t, locks, 1)
+	require.EqualValues(t, 7, locks[0].Owner)
+}
+
+func TestFileLockBlockWait(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		inodeTree:  NewBtree(),
+		extendTree: NewBtree(),
+	}
+	mp.inodeTree.ReplaceOrInsert(NewInode(10, FileModeType), true)
+	c1 := proto.FileLockOwner{ClientID: 1}
+	c2 := proto.FileLockOwner{ClientID: 2}
+	require.Equal(t, proto.OpOk, mp.fsmFileLock(&proto.FileLockRequest{
+		Inode: 10, Lock: proto.FileLock{FileLockOwner: c1, Type: proto.FileLockWrite, Flock: true},
+		Lease: 30, SubmitTime: time.Now(),
+	}).Status)
+
+	// the conflicting blocking lock waits on the leader and is not proposed, the partition
+	// has no raft to propose to
+	p := &Packet{}
+	req := &proto.FileLockRequest{
+		Inode: 10, Lock: proto.FileLock{FileLockOwner: c2, Type: proto.FileLockWrite, Flock: true},
+		Block: true, WaitMs: 50,
+	}
+	start := time.Now()
+	require.NoError(t, mp.FileLock(req, p))
+	require.Equal(t, proto.OpExistErr, p.ResultCode)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
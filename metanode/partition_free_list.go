@@ -22,9 +22,9 @@ import (
 	"sort"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
+	"github.com/cubefs/cubefs/depends/xtaci/smux"
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/sdk/data/blobstore"
 	"github.com/cubefs/cubefs/util"
@@ -104,12 +104,7 @@ func (mp *metaPartition) UpdateVolumeView(dataView *proto.DataPartitionsView, vo
 	}
 	mp.vol.UpdatePartitions(convert(dataView))
 	mp.vol.SetVolView(volumeView)
-	mp.vol.volDeleteLockTime = volumeView.DeleteLockTime
-	mp.enablePersistAccessTime = volumeView.EnablePersistAccessTime
-	if volumeView.AccessTimeInterval <= proto.MinAccessTimeValidInterval {
-		volumeView.AccessTimeInterval = proto.MinAccessTimeValidInterval
-	}
-	atomic.StoreUint64(&mp.accessTimeValidInterval, uint64(volumeView.AccessTimeInterval))
+	mp.reloadVolConfig(volumeView)
 }
 
 func (mp *metaPartition) checkHybridMigrationInode() {
@@ -189,7 +184,12 @@ func (mp *metaPartition) deleteWorker() {
 		// add sleep time value
 		DeleteWorkerSleepMs()
 
-		isForceDeleted := sleepCnt%MaxSleepCnt == 0
+		if mp.purgePaused() {
+			time.Sleep(AsyncDeleteInterval)
+			continue
+		}
+
+		isForceDeleted := sleepCnt%MaxSleepCnt == 0 || mp.purge.forced.Load()
 		if !isForceDeleted && mp.freeList.Len() < MinDeleteBatchCounts {
 			time.Sleep(AsyncDeleteInterval)
 			sleepCnt++
@@ -198,6 +198,7 @@ func (mp *metaPartition) deleteWorker() {
 
 		// do nothing.
 		if mp.freeList.Len() == 0 {
+			mp.purge.forced.Store(false)
 			log.LogInfof("[deleteWorker] vol(%v) mp(%v) skip, free list is empty", mp.config.VolName, mp.config.PartitionId)
 			time.Sleep(time.Minute)
 			continue
@@ -611,6 +612,12 @@ func (mp *metaPartition) syncToRaftFollowersFreeInodeMigrationExtentKey(hasDelet
 }
 
 func (mp *metaPartition) doBatchDeleteExtentsByPartition(partitionID uint64, exts []*proto.DelExtentParam) (err error) {
+	// the extents of a replica volume belong to the source volume
+	if mp.IsVolReplica() {
+		log.LogWarnf("[doBatchDeleteExtentsByPartition] vol(%v) mp(%v) is a replica, skip deleting %v extents of dp(%v)",
+			mp.config.VolName, mp.config.PartitionId, len(exts), partitionID)
+		return
+	}
 	// get the data node view
 	dp := mp.vol.GetPartition(partitionID)
 	if dp == nil {
@@ -644,6 +651,20 @@ func (mp *metaPartition) doBatchDeleteExtentsByPartition(partitionID uint64, ext
 		err = errors.NewErrorf("dp id(%v) is invalid", partitionID)
 		return
 	}
+	var code uint8
+	if extentDeleter != nil {
+		code, err = extentDeleter.delete(dp, exts)
+	} else {
+		code, err = mp.sendBatchDeleteExtents(dp, exts)
+	}
+	if code == proto.OpTryOtherAddr && proto.IsCold(mp.volType) {
+		log.LogInfof("[doBatchDeleteExtentsByPartition] deleteOp retrun tryOtherAddr code means dp is deleted for LF vol, dp(%d)", partitionID)
+		return nil
+	}
+	return
+}
+
+func (mp *metaPartition) sendBatchDeleteExtents(dp *DataPartition, exts []*proto.DelExtentParam) (code uint8, err error) {
 	addr := util.ShiftAddrPort(dp.Hosts[0], smuxPortShift)
 	conn, err := smuxPool.GetConnect(addr)
 	log.LogInfof("[doBatchDeleteExtentsByPartition] mp(%v) GetConnect (%v)", mp.config.PartitionId, addr)
@@ -656,9 +677,15 @@ func (mp *metaPartition) doBatchDeleteExtentsByPartition(partitionID uint64, ext
 	if err != nil {
 		err = errors.NewErrorf("get conn from pool %s, "+
 			"extents partitionId=%d",
-			err.Error(), partitionID)
+			err.Error(), dp.PartitionID)
 		return
 	}
+	return sendBatchDeleteExtents(conn, dp, exts)
+}
+
+// sendBatchDeleteExtents sends the extents to delete of the data partition over the stream, an
+// error is returned with the result code unless it is ok.
+func sendBatchDeleteExtents(conn *smux.Stream, dp *DataPartition, exts []*proto.DelExtentParam) (code uint8, err error) {
 	p := NewPacketToBatchDeleteExtent(dp, exts)
 	if err = p.WriteToConn(conn); err != nil {
 		err = errors.NewErrorf("write to dataNode %s, %s", p.GetUniqueLogId(),
@@ -671,13 +698,8 @@ func (mp *metaPartition) doBatchDeleteExtentsByPartition(partitionID uint64, ext
 		return
 	}
 
-	ResultCode := p.ResultCode
-	if ResultCode == proto.OpTryOtherAddr && proto.IsCold(mp.volType) {
-		log.LogInfof("[doBatchDeleteExtentsByPartition] deleteOp retrun tryOtherAddr code means dp is deleted for LF vol, dp(%d)", partitionID)
-		return
-	}
-
-	if p.ResultCode != proto.OpOk {
+	code = p.ResultCode
+	if code != proto.OpOk {
 		err = errors.NewErrorf("[deleteMarkedInodes] %s response: %s", p.GetUniqueLogId(),
 			p.GetResultMsg())
 	}
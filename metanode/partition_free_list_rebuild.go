@@ -0,0 +1,161 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the inodes of the free lists fixed by a raft log of the rebuild at most
+const freeListRebuildBatch = 10000
+
+// freeListRebuildRequest is the fixes of the free lists the leader found on a snapshot of the
+// inode tree, each is checked again against the inode tree by the fsm.
+type freeListRebuildRequest struct {
+	Add       []uint64 `json:"add"`       // the inodes to free missing from the free list
+	AddHybrid []uint64 `json:"addHybrid"` // the inodes to delete the migration keys of missing from the hybrid list
+	Remove    []uint64 `json:"remove"`    // the entries of the free lists not to free
+}
+
+func (req *freeListRebuildRequest) len() int {
+	return len(req.Add) + len(req.AddHybrid) + len(req.Remove)
+}
+
+// freeListOf returns the free list the inode is to be kept in, nil if none.
+func (mp *metaPartition) freeListOf(ino *Inode) *freeList {
+	if proto.IsDir(ino.Type) {
+		return nil
+	}
+	if ino.ShouldDelete() || ino.IsTempFile() {
+		return mp.freeList
+	}
+	if ino.ShouldDeleteMigrationExtentKey(true) {
+		return mp.freeHybridList
+	}
+	return nil
+}
+
+// RebuildFreeList rebuilds the free lists of the replicas from the inodes marked deleted. The
+// leader scans a snapshot of the inode tree for the fixes of its free lists and submits them
+// by batches through raft, it returns the fixes applied to the ones of the leader.
+func (mp *metaPartition) RebuildFreeList() (result *proto.MetaFreeListRebuildResult, err error) {
+	if leader, ok := mp.IsLeader(); !ok {
+		return nil, fmt.Errorf("mp %v is not the leader, leader is %v", mp.config.PartitionId, leader)
+	}
+	result = &proto.MetaFreeListRebuildResult{PartitionID: mp.config.PartitionId}
+	toFree := make(map[uint64]*freeList)
+	mp.inodeTree.GetTree().Ascend(func(item BtreeItem) bool {
+		ino := item.(*Inode)
+		result.Scanned++
+		if list := mp.freeListOf(ino); list != nil {
+			toFree[ino.Inode] = list
+		}
+		return true
+	})
+
+	req := &freeListRebuildRequest{}
+	submit := func(force bool) error {
+		if req.len() == 0 || !force && req.len() < freeListRebuildBatch {
+			return nil
+		}
+		val, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		resp, err := mp.submit(opFSMRebuildFreeList, val)
+		if err != nil {
+			return err
+		}
+		fixed, ok := resp.(*proto.MetaFreeListRebuildResult)
+		if !ok {
+			return fmt.Errorf("rebuild free list of mp %v: unexpected result %v", mp.config.PartitionId, resp)
+		}
+		result.Added += fixed.Added
+		result.HybridAdded += fixed.HybridAdded
+		result.Removed += fixed.Removed
+		req = &freeListRebuildRequest{}
+		return nil
+	}
+	for _, list := range []*freeList{mp.freeList, mp.freeHybridList} {
+		for _, ino := range list.Inodes() {
+			if toFree[ino] != list {
+				req.Remove = append(req.Remove, ino)
+				if err = submit(false); err != nil {
+					return
+				}
+			}
+		}
+	}
+	for ino, list := range toFree {
+		if list.Has(ino) {
+			continue
+		}
+		if list == mp.freeList {
+			req.Add = append(req.Add, ino)
+		} else {
+			req.AddHybrid = append(req.AddHybrid, ino)
+		}
+		if err = submit(false); err != nil {
+			return
+		}
+	}
+	if err = submit(true); err != nil {
+		return
+	}
+	result.Total = uint64(mp.freeList.Len())
+	result.HybridTotal = uint64(mp.freeHybridList.Len())
+	log.LogWarnf("action[RebuildFreeList] mp(%v) scanned(%v) added(%v) hybridAdded(%v) removed(%v) total(%v) hybridTotal(%v)",
+		mp.config.PartitionId, result.Scanned, result.Added, result.HybridAdded, result.Removed, result.Total, result.HybridTotal)
+	return
+}
+
+// fsmRebuildFreeList applies the fixes of the free lists that still hold for the inode tree,
+// the inodes changed since the leader scanned its snapshot are left to the fsm ops on them.
+func (mp *metaPartition) fsmRebuildFreeList(req *freeListRebuildRequest) (result *proto.MetaFreeListRebuildResult) {
+	result = &proto.MetaFreeListRebuildResult{PartitionID: mp.config.PartitionId}
+	listOf := func(ino uint64) *freeList {
+		if item := mp.inodeTree.Get(NewInode(ino, 0)); item != nil {
+			return mp.freeListOf(item.(*Inode))
+		}
+		return nil
+	}
+	for _, ino := range req.Remove {
+		for _, list := range []*freeList{mp.freeList, mp.freeHybridList} {
+			if list.Has(ino) && listOf(ino) != list {
+				list.Remove(ino)
+				result.Removed++
+			}
+		}
+	}
+	for _, ino := range req.Add {
+		if !mp.freeList.Has(ino) && listOf(ino) == mp.freeList {
+			mp.freeList.Push(ino)
+			result.Added++
+		}
+	}
+	for _, ino := range req.AddHybrid {
+		if !mp.freeHybridList.Has(ino) && listOf(ino) == mp.freeHybridList {
+			mp.freeHybridList.Push(ino)
+			result.HybridAdded++
+		}
+	}
+	result.Total = uint64(mp.freeList.Len())
+	result.HybridTotal = uint64(mp.freeHybridList.Len())
+	return
+}
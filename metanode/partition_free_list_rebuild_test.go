@@ -0,0 +1,74 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebuildFreeList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	mp.config.NodeId = 1
+
+	deleted := NewInode(10, FileModeType)
+	deleted.SetDeleteMark()
+	unlinked := NewInode(11, FileModeType)
+	unlinked.NLink = 0
+	live := NewInode(12, FileModeType)
+	dir := NewInode(13, DirModeType)
+	dir.SetDeleteMark()
+	migrated := NewInode(14, FileModeType)
+	migrated.Flag |= DeleteMigrationExtentKeyFlag
+	for _, ino := range []*Inode{deleted, unlinked, live, dir, migrated} {
+		mp.inodeTree.ReplaceOrInsert(ino, true)
+	}
+	// the free list diverged: the deleted inode is lost, the live and the gone ones are stale
+	mp.freeList.Push(unlinked.Inode)
+	mp.freeList.Push(live.Inode)
+	mp.freeList.Push(100)
+	mp.freeHybridList.Push(101)
+	accessTime := unlinked.AccessTime
+
+	result, err := mp.RebuildFreeList()
+	require.NoError(t, err)
+	require.Equal(t, mp.config.PartitionId, result.PartitionID)
+	require.EqualValues(t, 5, result.Scanned)
+	require.EqualValues(t, 1, result.Added)
+	require.EqualValues(t, 1, result.HybridAdded)
+	require.EqualValues(t, 3, result.Removed)
+	require.EqualValues(t, 2, result.Total)
+	require.EqualValues(t, 1, result.HybridTotal)
+	require.ElementsMatch(t, []uint64{deleted.Inode, unlinked.Inode}, mp.freeList.Inodes())
+	require.Equal(t, []uint64{migrated.Inode}, mp.freeHybridList.Inodes())
+	require.Equal(t, accessTime, unlinked.AccessTime)
+
+	// the fixes no longer holding for the inode tree are skipped by the fsm
+	fixed := mp.fsmRebuildFreeList(&freeListRebuildRequest{Add: []uint64{live.Inode}, Remove: []uint64{deleted.Inode}})
+	require.Zero(t, fixed.Added)
+	require.Zero(t, fixed.Removed)
+
+	// the free list in sync is kept
+	result, err = mp.RebuildFreeList()
+	require.NoError(t, err)
+	require.Zero(t, result.Added)
+	require.Zero(t, result.HybridAdded)
+	require.Zero(t, result.Removed)
+	require.EqualValues(t, 2, result.Total)
+}
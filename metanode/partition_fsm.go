@@ -37,22 +37,28 @@ import (
 // Apply applies the given operational commands.
 func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{}, err error) {
 	msg := &MetaItem{}
+	tp := exporter.NewTP(MetricApply)
 	defer func() {
+		mp.recordApply(tp)
 		if r := recover(); r != nil {
-			panicMsg := fmt.Sprintf("[metaPartition.Apply] mpId(%v) op(%v) occurred panic, err(%v), ",
-				mp.config.PartitionId, msg.Op, r)
-			log.LogWarn(panicMsg)
-			panic(panicMsg)
+			mp.markApplyFailed(msg.Op, index, r)
+			resp, err = nil, ErrApplyFailed
+			return
 		}
 
 		if err == nil {
 			mp.uploadApplyID(index)
+			mp.markFollowerLag(index)
 		}
 	}()
+	if mp.IsApplyFailed() {
+		err = ErrApplyFailed
+		return
+	}
 	if err = msg.UnmarshalJson(command); err != nil {
 		return
 	}
-
+	mp.waitCatchUpQuota(index, len(command))
 	mp.nonIdempotent.Lock()
 	defer mp.nonIdempotent.Unlock()
 
@@ -79,6 +85,41 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			mp.setInodeQuota(qinode.quotaIds, ino.Inode)
 		}
 		resp = mp.fsmCreateInode(ino)
+	case opFSMUpdateLinkTarget:
+		req := &UpdateLinkTargetRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmUpdateLinkTarget(req)
+	case opFSMBatchCreateDentryInode:
+		cmd := &batchCreateDentryInodeCmd{}
+		if err = cmd.Unmarshal(msg.V); err != nil {
+			return
+		}
+		for _, item := range cmd.items {
+			if mp.config.Cursor < item.inode.Inode {
+				mp.config.Cursor = item.inode.Inode
+			}
+		}
+		resp = mp.fsmBatchCreateDentryInode(cmd)
+	case opFSMCreateInodeWithXAttr:
+		cmd := &InodeWithXAttr{}
+		if err = cmd.Unmarshal(msg.V); err != nil {
+			return
+		}
+		if mp.config.Cursor < cmd.qinode.inode.Inode {
+			mp.config.Cursor = cmd.qinode.inode.Inode
+		}
+		resp = mp.fsmCreateInodeWithXAttr(cmd)
+	case opFSMTxCreateInodeWithXAttr:
+		cmd := &TxInodeWithXAttr{}
+		if err = cmd.Unmarshal(msg.V); err != nil {
+			return
+		}
+		if mp.config.Cursor < cmd.qinode.txinode.Inode.Inode {
+			mp.config.Cursor = cmd.qinode.txinode.Inode.Inode
+		}
+		resp = mp.fsmTxCreateInodeWithXAttr(cmd)
 	case opFSMUnlinkInode:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -117,6 +158,24 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		resp = mp.fsmExtentsTruncate(ino)
+	case opFSMExtentsPreAlloc:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			return
+		}
+		resp = mp.fsmExtentsPreAlloc(ino)
+	case opFSMRepairNLink:
+		req := &proto.MetaRepairNLinkRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmRepairNLink(req)
+	case opFSMMergeExtents:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			return
+		}
+		resp = mp.fsmMergeExtents(ino)
 	case opFSMCreateLinkInode:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -164,7 +223,11 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		if err != nil {
 			return
 		}
-		err = mp.fsmSetAttr(req)
+		if err = mp.fsmSetAttr(req); err == nil {
+			mp.appendNsEvents(&proto.NamespaceEvent{
+				Index: index, Type: proto.NamespaceEventSetAttr, Inode: req.Inode, Mode: req.Mode, Time: time.Now().Unix(),
+			})
+		}
 	case opFSMCreateDentry:
 		den := &Dentry{}
 		if err = den.Unmarshal(msg.V); err != nil {
@@ -177,7 +240,11 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 
-		resp = mp.fsmCreateDentry(den, false)
+		status = mp.fsmCreateDentry(den, false)
+		if status == proto.OpOk {
+			mp.appendNsEvents(newDentryEvent(index, proto.NamespaceEventCreate, den))
+		}
+		resp = status
 	case opFSMDeleteDentry:
 		den := &Dentry{}
 		if err = den.Unmarshal(msg.V); err != nil {
@@ -192,13 +259,25 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 
-		resp = mp.fsmDeleteDentry(den, false)
+		dresp := mp.fsmDeleteDentry(den, false)
+		if dresp.Status == proto.OpOk && dresp.Msg != nil {
+			mp.appendNsEvents(newDentryEvent(index, proto.NamespaceEventUnlink, dresp.Msg))
+		}
+		resp = dresp
 	case opFSMDeleteDentryBatch:
 		db, err := DentryBatchUnmarshal(msg.V)
 		if err != nil {
 			return nil, err
 		}
-		resp = mp.fsmBatchDeleteDentry(db)
+		dresps := mp.fsmBatchDeleteDentry(db)
+		events := make([]*proto.NamespaceEvent, 0, len(dresps))
+		for _, dresp := range dresps {
+			if dresp.Status == proto.OpOk && dresp.Msg != nil {
+				events = append(events, newDentryEvent(index, proto.NamespaceEventUnlink, dresp.Msg))
+			}
+		}
+		mp.appendNsEvents(events...)
+		resp = dresps
 	case opFSMUpdateDentry:
 		den := &Dentry{}
 		if err = den.Unmarshal(msg.V); err != nil {
@@ -211,25 +290,39 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 
-		resp = mp.fsmUpdateDentry(den)
+		ino := den.Inode
+		dresp := mp.fsmUpdateDentry(den)
+		// the dentry is changed only if it had another inode, which is in Msg then
+		if dresp.Status == proto.OpOk && dresp.Msg != nil {
+			event := newDentryEvent(index, proto.NamespaceEventUpdate, dresp.Msg)
+			event.Inode, event.OldInode = ino, dresp.Msg.Inode
+			mp.appendNsEvents(event)
+		}
+		resp = dresp
 	case opFSMUpdatePartition:
 		req := &UpdatePartitionReq{}
 		if err = json.Unmarshal(msg.V, req); err != nil {
 			return
 		}
 		resp, err = mp.fsmUpdatePartition(req.End)
-	case opFSMExtentsAdd:
+	case opFSMExtentsAdd, opFSMExtentsAddRejectConflict, opFSMExtentsAddLWW:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
 			return
 		}
-		resp = mp.fsmAppendExtents(ino)
+		resp = mp.fsmAppendExtents(ino, extentConflictPolicyOf(msg.Op))
 	case opFSMExtentsAddWithCheck:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
 			return
 		}
 		resp = mp.fsmAppendExtentsWithCheck(ino, false)
+	case opFSMExtentsAddWithCheckRejectConflict, opFSMExtentsAddWithCheckLWW:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			return
+		}
+		resp = mp.fsmAppendExtentsWithConflictCheck(ino, extentConflictPolicyOf(msg.Op))
 	case opFSMExtentSplit:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -275,6 +368,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			uniqId:         uniqId,
 			uniqChecker:    uniqChecker,
 			multiVerList:   mp.GetAllVerList(),
+			replicaStaging: mp.replicaStaging.clone(),
 		}
 		log.LogDebugf("opFSMStoreTick: quotaRebuild [%v] uidRebuild [%v]", quotaRebuild, uidRebuild)
 		mp.storeChan <- msg
@@ -310,6 +404,38 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		resp = mp.fsmLockDir(req)
+	case opFSMFileLock:
+		req := &proto.FileLockRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmFileLock(req)
+	case opFSMFileLockLease:
+		req := &proto.FileLockLeaseRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmFileLockLease(req)
+	case opFSMFileLockExpire:
+		req := &fileLockExpireRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		mp.fsmFileLockExpire(req)
+	case opFSMRepairDirStat:
+		req := &dirStatRepairRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmRepairDirStats(req)
+	case opFSMRebuildFreeList:
+		req := &freeListRebuildRequest{}
+		if len(msg.V) > 0 {
+			if err = json.Unmarshal(msg.V, req); err != nil {
+				return
+			}
+		}
+		resp = mp.fsmRebuildFreeList(req)
 	case opFSMCreateMultipart:
 		var multipart *Multipart
 		multipart = MultipartFromBytes(msg.V)
@@ -495,6 +621,20 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		resp, err = mp.fsmSetFreeze(req.Freeze)
+	case opFSMReplicaSyncBegin:
+		begin := &replicaSyncBegin{}
+		if err = json.Unmarshal(msg.V, begin); err != nil {
+			return
+		}
+		resp = mp.fsmReplicaSyncBegin(begin)
+	case opFSMReplicaSyncBatch:
+		resp = mp.fsmReplicaSyncBatch(msg.V)
+	case opFSMReplicaSyncEnd:
+		end := &replicaSyncEnd{}
+		if err = json.Unmarshal(msg.V, end); err != nil {
+			return
+		}
+		resp = mp.fsmReplicaSyncEnd(end)
 	default:
 		// do nothing
 	case opFSMSyncInodeAccessTime:
@@ -629,6 +769,9 @@ func (mp *metaPartition) fsmVersionOp(reqData []byte) (err error) {
 
 // ApplyMemberChange  apply changes to the raft member.
 func (mp *metaPartition) ApplyMemberChange(confChange *raftproto.ConfChange, index uint64) (resp interface{}, err error) {
+	if mp.IsApplyFailed() {
+		return nil, ErrApplyFailed
+	}
 	mp.nonIdempotent.Lock()
 	defer mp.nonIdempotent.Unlock()
 
@@ -674,6 +817,9 @@ func (mp *metaPartition) ApplyMemberChange(confChange *raftproto.ConfChange, ind
 
 // Snapshot returns the snapshot of the current meta partition.
 func (mp *metaPartition) Snapshot() (snap raftproto.Snapshot, err error) {
+	if mp.IsApplyFailed() {
+		return nil, ErrApplyFailed
+	}
 	snap, err = newMetaItemIterator(mp)
 	return
 }
@@ -695,6 +841,10 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 		txRbDentryTree = NewBtree()
 		uniqChecker    = newUniqChecker()
 		verList        []*proto.VolVersionInfo
+		replicaStaging *replicaSyncStaging
+		verifier       snapVerifier
+		received       *snapReceived // of the last verified chunk
+		receivedBase   uint32        // chunks of the snapshot received by the last attempt
 	)
 
 	blockUntilStoreSnapshot := func() {
@@ -738,6 +888,9 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 	defer func() {
 		if err == io.EOF {
 			mp.applyID = appIndexID
+			if mp.nsEvents != nil {
+				mp.nsEvents.reset(appIndexID)
+			}
 			mp.config.UniqId = uniqID
 			mp.txProcessor.txManager.txIdAlloc.setTransactionID(txID)
 			mp.inodeTree = inodeTree
@@ -748,12 +901,18 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			mp.txProcessor.txManager.txTree = txTree
 			mp.txProcessor.txResource.txRbInodeTree = txRbInodeTree
 			mp.txProcessor.txResource.txRbDentryTree = txRbDentryTree
+			// the window tuned on this node survives the snapshot
+			uniqChecker.setKeep(mp.uniqChecker.getKeep())
 			mp.uniqChecker = uniqChecker
+			mp.replicaStaging = replicaStaging
 			mp.multiVersionList.VerList = make([]*proto.VolVersionInfo, len(verList))
 			copy(mp.multiVersionList.VerList, verList)
 			mp.verSeq = mp.multiVersionList.GetLastVer()
 			log.LogInfof("mp[%v] updateVerList (%v) seq [%v]", mp.config.PartitionId, mp.multiVersionList.VerList, mp.verSeq)
 			err = nil
+			if verifier.started {
+				go mp.reportSnapshotProgress(appIndexID, verifier.seq, true)
+			}
 			// store message
 			mp.storeChan <- &storeMsg{
 				command:        opFSMStoreTick,
@@ -769,6 +928,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				uniqId:         mp.GetUniqId(),
 				uniqChecker:    uniqChecker.clone(),
 				multiVerList:   mp.GetVerList(),
+				replicaStaging: replicaStaging.clone(),
 			}
 			select {
 			case mp.extReset <- struct{}{}:
@@ -783,16 +943,26 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			}
 		}
 		log.LogErrorf("ApplySnapshot: stop with error: partitionID(%v) err(%v)", mp.config.PartitionId, err)
+		if verifier.started {
+			var chunks uint32
+			if received != nil {
+				mp.keepSnapReceived(received)
+				chunks = received.chunks
+			}
+			go mp.reportSnapshotProgress(appIndexID, chunks, false)
+		}
 	}()
 
 	var leaderSnapFormatVer uint32
 	leaderSnapFormatVer = math.MaxUint32
 
+	partLimiter := mp.manager.newSnapPartitionLimiter()
 	for {
 		data, err = iter.Next()
 		if err != nil {
 			return
 		}
+		mp.waitSnapshotQuota(partLimiter, len(data))
 
 		if index == 0 {
 			appIndexID = binary.BigEndian.Uint64(data)
@@ -837,7 +1007,58 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 		}
 
 		index++
+		if verifier.started && snap.Op != opFSMSnapChunk {
+			verifier.add(data)
+		}
 		switch snap.Op {
+		case opFSMSnapResume:
+			var (
+				resumeApplyID uint64
+				skip          uint32
+				recv          *snapReceived
+			)
+			if resumeApplyID, skip, err = decodeSnapResume(snap.V); err != nil {
+				return
+			}
+			if recv, err = mp.takeSnapReceived(resumeApplyID, skip); err != nil {
+				return
+			}
+			if recv != nil {
+				// the items of the chunks resent are inserted again
+				inodeTree, dentryTree, extendTree, multipartTree = recv.inodeTree, recv.dentryTree, recv.extendTree, recv.multipartTree
+				txTree, txRbInodeTree, txRbDentryTree = recv.txTree, recv.txRbInodeTree, recv.txRbDentryTree
+				uniqChecker = recv.uniqChecker
+				replicaStaging = recv.replicaStaging
+				if cursor < recv.cursor {
+					cursor = recv.cursor
+				}
+				receivedBase, received = recv.chunks, recv
+				log.LogWarnf("ApplySnapshot: partitionID(%v) resume snapshot(%v) from chunk(%v), received(%v)",
+					mp.config.PartitionId, resumeApplyID, skip, recv.chunks)
+			}
+			verifier.start(skip)
+		case opFSMSnapChunk:
+			if err = verifier.check(snap.V); err != nil {
+				log.LogErrorf("ApplySnapshot: partitionID(%v) %v", mp.config.PartitionId, err)
+				return
+			}
+			if verifier.files || verifier.seq <= receivedBase {
+				continue
+			}
+			received = &snapReceived{
+				applyID:        appIndexID,
+				chunks:         verifier.seq,
+				cursor:         cursor,
+				inodeTree:      inodeTree.GetTree(),
+				dentryTree:     dentryTree.GetTree(),
+				extendTree:     extendTree.GetTree(),
+				multipartTree:  multipartTree.GetTree(),
+				txTree:         txTree.GetTree(),
+				txRbInodeTree:  txRbInodeTree.GetTree(),
+				txRbDentryTree: txRbDentryTree.GetTree(),
+				uniqChecker:    uniqChecker.clone(),
+				replicaStaging: replicaStaging.clone(),
+			}
 		case opFSMApplyId:
 			appIndexID = binary.BigEndian.Uint64(snap.V)
 			log.LogDebugf("ApplySnapshot: partitionID(%v) appIndexID:%v", mp.config.PartitionId, appIndexID)
@@ -902,6 +1123,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			json.Unmarshal(snap.V, &verList)
 			log.LogDebugf("ApplySnapshot: create verList: partitionID(%v) snap.V(%v) verList(%v)", mp.config.PartitionId, snap.V, verList)
 		case opExtentFileSnapshot:
+			verifier.files = true
 			fileName := string(snap.K)
 			fileName = path.Join(mp.config.RootDir, fileName)
 			if err = os.WriteFile(fileName, snap.V, 0o644); err != nil {
@@ -916,6 +1138,19 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				return
 			}
 			log.LogDebugf("ApplySnapshot: write snap uniqChecker")
+		case opFSMReplicaSyncStaging:
+			if replicaStaging, err = replicaStaging.restore(snap); err != nil {
+				return
+			}
+			log.LogDebugf("ApplySnapshot: partitionID(%v) replica sync(%v) staging", mp.config.PartitionId, replicaStaging.id)
+		case opFSMReplicaSyncStagingItem:
+			item := NewMetaItem(0, nil, nil)
+			if err = item.UnmarshalBinary(snap.V); err != nil {
+				return
+			}
+			if replicaStaging, err = replicaStaging.restore(item); err != nil {
+				return
+			}
 
 		default:
 			if leaderSnapFormatVer != math.MaxUint32 && leaderSnapFormatVer > mp.manager.metaNode.raftSyncSnapFormatVersion {
@@ -941,6 +1176,10 @@ func (mp *metaPartition) HandleFatalEvent(err *raft.FatalError) {
 func (mp *metaPartition) HandleLeaderChange(leader uint64) {
 	exporter.Warning(fmt.Sprintf("metaPartition(%v) changeLeader to (%v)", mp.config.PartitionId, leader))
 	log.LogDebugf(fmt.Sprintf("metaPartition(%v) changeLeader to (%v)", mp.config.PartitionId, leader))
+	if mp.attrLeases != nil {
+		// the clients drop the attrs cached under the leases of the last leader
+		mp.attrLeases.reset()
+	}
 	if mp.config.NodeId == leader {
 		localIp := mp.manager.metaNode.localAddr
 		if localIp == "" {
@@ -1005,7 +1244,10 @@ func (mp *metaPartition) submit(op uint32, data []byte) (resp interface{}, err e
 	}
 
 	// submit to the raft store
+	mp.waitProposalQuota(len(cmd))
+	tp := mp.beginProposal()
 	resp, err = mp.raftPartition.Submit(cmd)
+	mp.endProposal(tp, op, err)
 	log.LogDebugf("submit. op [%v] done", op)
 	return
 }
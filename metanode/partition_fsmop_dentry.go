@@ -123,6 +123,7 @@ func (mp *metaPartition) fsmCreateDentry(dentry *Dentry,
 			if !forceUpdate {
 				parIno.IncNLink(mp.verSeq)
 				parIno.SetMtime()
+				mp.updateDirStat(dentry, true)
 			}
 			return
 		} else if proto.OsModeType(dentry.Type) != proto.OsModeType(d.Type) && !proto.IsSymlink(dentry.Type) && !proto.IsSymlink(d.Type) {
@@ -142,6 +143,7 @@ func (mp *metaPartition) fsmCreateDentry(dentry *Dentry,
 	if !forceUpdate {
 		parIno.IncNLink(mp.verSeq)
 		parIno.SetMtime()
+		mp.updateDirStat(dentry, true)
 	}
 	return
 }
@@ -289,6 +291,7 @@ func (mp *metaPartition) fsmDeleteDentry(denParm *Dentry, checkInode bool) (resp
 		log.LogErrorf("action[fsmDeleteDentry] mp[%v] not found dentry %v", mp.config.PartitionId, denParm)
 		return
 	} else {
+		unlinked := false
 		mp.inodeTree.CopyFind(NewInode(denParm.ParentId, 0),
 			func(item BtreeItem) {
 				if item != nil { // no matter
@@ -297,12 +300,16 @@ func (mp *metaPartition) fsmDeleteDentry(denParm *Dentry, checkInode bool) (resp
 						log.LogDebugf("action[fsmDeleteDentry] mp[%v] den  %v delete parent's link", mp.config.PartitionId, denParm)
 						if denParm.getSeqFiled() == 0 {
 							item.(*Inode).DecNLink()
+							unlinked = true
 						}
 						log.LogDebugf("action[fsmDeleteDentry] mp[%v] inode[%v] be unlinked by child name %v", mp.config.PartitionId, item.(*Inode).Inode, denParm.Name)
 						item.(*Inode).SetMtime()
 					}
 				}
 			})
+		if unlinked {
+			mp.updateDirStat(denFound, false)
+		}
 	}
 	resp.Msg = denFound
 	return
@@ -493,3 +500,86 @@ func (mp *metaPartition) readDirLimit(req *ReadDirLimitReq) (resp *ReadDirLimitR
 	log.LogDebugf("action[readDirLimit] mp[%v] resp %v", mp.config.PartitionId, resp)
 	return
 }
+
+// lookupSiblings returns up to limit dentries following name in the parent, with the inodes of
+// them in the partition, for the client to prefetch.
+func (mp *metaPartition) lookupSiblings(parentID uint64, name string, limit uint32) (siblings []proto.LookupSibling) {
+	if limit > proto.MaxLookupSiblings {
+		limit = proto.MaxLookupSiblings
+	}
+	siblings = make([]proto.LookupSibling, 0, limit)
+	startDentry := &Dentry{
+		ParentId: parentID,
+		Name:     name,
+	}
+	endDentry := &Dentry{
+		ParentId: parentID + 1,
+	}
+	mp.dentryTree.AscendRange(startDentry, endDentry, func(i BtreeItem) bool {
+		d := mp.getDentryByVerSeq(i.(*Dentry), 0)
+		if d == nil || d.Name == name {
+			return true
+		}
+		siblings = append(siblings, proto.LookupSibling{
+			Name:  d.Name,
+			Inode: d.Inode,
+			Type:  d.Type,
+			Info:  mp.inodeInfo(d.Inode),
+		})
+		return uint32(len(siblings)) < limit
+	})
+	return
+}
+
+// inodeInfo returns the info of the inode in the partition, nil if it is not there.
+func (mp *metaPartition) inodeInfo(ino uint64) *proto.InodeInfo {
+	retMsg := mp.getInode(NewInode(ino, 0), false)
+	if retMsg.Status != proto.OpOk {
+		return nil
+	}
+	var quotaInfos map[uint32]*proto.MetaQuotaInfo
+	var err error
+	if mp.mqMgr.EnableQuota() {
+		if quotaInfos, err = mp.getInodeQuotaInfos(ino); err != nil {
+			return nil
+		}
+	}
+	info := &proto.InodeInfo{}
+	if !replyInfo(info, retMsg.Msg, quotaInfos) {
+		return nil
+	}
+	return info
+}
+
+// readDirPlus returns up to req.Limit dentries of the dir from req.Marker, with the inodes of
+// them in the range of the partition. The ones out of it are listed in Remote.
+func (mp *metaPartition) readDirPlus(req *ReadDirPlusReq) (resp *ReadDirPlusResp) {
+	limit := req.Limit
+	if limit == 0 || limit > proto.MaxReadDirPlusLimit {
+		limit = proto.MaxReadDirPlusLimit
+	}
+	resp = &ReadDirPlusResp{Children: make([]proto.DirentPlus, 0)}
+	startDentry := &Dentry{
+		ParentId: req.ParentID,
+		Name:     req.Marker,
+	}
+	endDentry := &Dentry{
+		ParentId: req.ParentID + 1,
+	}
+	start, end := mp.config.Start, mp.config.End
+	mp.dentryTree.AscendRange(startDentry, endDentry, func(i BtreeItem) bool {
+		d := mp.getDentryByVerSeq(i.(*Dentry), 0)
+		if d == nil {
+			return true
+		}
+		child := proto.DirentPlus{Dentry: proto.Dentry{Name: d.Name, Inode: d.Inode, Type: d.Type}}
+		if d.Inode >= start && d.Inode <= end {
+			child.Info = mp.inodeInfo(d.Inode)
+		} else {
+			resp.Remote = append(resp.Remote, d.Inode)
+		}
+		resp.Children = append(resp.Children, child)
+		return uint64(len(resp.Children)) < limit
+	})
+	return
+}
@@ -464,7 +464,7 @@ func (mp *metaPartition) internalDeleteInode(ino *Inode) {
 	mp.extendTree.Delete(&Extend{inode: ino.Inode}) // Also delete extend attribute.
 }
 
-func (mp *metaPartition) fsmAppendExtents(ino *Inode) (status uint8) {
+func (mp *metaPartition) fsmAppendExtents(ino *Inode, conflictPolicy string) (status uint8) {
 	item := mp.inodeTree.CopyGet(ino)
 	if item == nil {
 		status = proto.OpNotExistErr
@@ -482,6 +482,12 @@ func (mp *metaPartition) fsmAppendExtents(ino *Inode) (status uint8) {
 	}
 
 	eks := ino.HybridCloudExtents.sortedEks.(*SortedExtents).CopyExtents()
+	if overlapped, st := ino2.checkExtentConflict(eks, ino.Generation, conflictPolicy); st != proto.OpOk {
+		log.LogWarnf("fsmAppendExtents: mp[%v] inode[%v] gen(%v) eks(%v) conflict with extents(%v) under policy(%v)",
+			mp.config.PartitionId, ino2.Inode, ino.Generation, eks, overlapped, conflictPolicy)
+		status = st
+		return
+	}
 	if status = mp.uidManager.addUidSpace(ino2.Uid, ino2.Inode, eks); status != proto.OpOk {
 		return
 	}
@@ -696,6 +702,7 @@ func (mp *metaPartition) fsmExtentsTruncate(ino *Inode) (resp *InodeResponse) {
 		return
 	}
 
+	i.Flag &^= InodePreAllocFlag
 	delExtents := i.ExtentsTruncate(ino.Size, ino.ModifyTime, insertSplitKey)
 	if len(delExtents) == 0 {
 		return
@@ -711,6 +718,67 @@ func (mp *metaPartition) fsmExtentsTruncate(ino *Inode) (resp *InodeResponse) {
 	return
 }
 
+// fsmExtentsPreAlloc marks the inode preallocated and extends its size, a size decreased by
+// a truncate or extended by a write meanwhile is never shrunk.
+func (mp *metaPartition) fsmExtentsPreAlloc(ino *Inode) (resp *InodeResponse) {
+	resp = NewInodeResponse()
+	resp.Status = proto.OpOk
+	item := mp.inodeTree.CopyGet(ino)
+	if item == nil {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	i := item.(*Inode)
+	if i.ShouldDelete() {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	if proto.IsDir(i.Type) || !proto.IsStorageClassReplica(i.StorageClass) {
+		resp.Status = proto.OpArgMismatchErr
+		return
+	}
+	i.Lock()
+	defer i.Unlock()
+	i.Flag |= InodePreAllocFlag
+	if ino.Size > i.Size {
+		i.Size = ino.Size
+		i.Generation++
+	}
+	i.ModifyTime = ino.ModifyTime
+	log.LogDebugf("fsmExtentsPreAlloc: mp(%v) ino(%v) size(%v)", mp.config.PartitionId, i.Inode, i.Size)
+	return
+}
+
+// fsmMergeExtents merges the contiguous extent keys of the inode, an inode referred by
+// snapshots is left as it is.
+func (mp *metaPartition) fsmMergeExtents(ino *Inode) (resp *InodeResponse) {
+	resp = NewInodeResponse()
+	resp.Status = proto.OpOk
+	item := mp.inodeTree.CopyGet(ino)
+	if item == nil {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	i := item.(*Inode)
+	if i.ShouldDelete() {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	if !proto.IsRegular(i.Type) || !proto.IsStorageClassReplica(i.StorageClass) {
+		resp.Status = proto.OpArgMismatchErr
+		return
+	}
+	i.Lock()
+	defer i.Unlock()
+	if i.hasSnapshotRefs() {
+		resp.Status = proto.OpArgMismatchErr
+		return
+	}
+	merged := i.GetExtents().MergeSequential()
+	log.LogDebugf("fsmMergeExtents: mp(%v) ino(%v) merged(%v)", mp.config.PartitionId, i.Inode, merged)
+	return
+}
+
 func (mp *metaPartition) fsmEvictInode(ino *Inode) (resp *InodeResponse) {
 	resp = NewInodeResponse()
 	log.LogDebugf("action[fsmEvictInode] inode[%v]", ino)
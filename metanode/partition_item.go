@@ -28,6 +28,7 @@ import (
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
 )
 
 // MetaItem defines the structure of the metadata operations.
@@ -131,6 +132,9 @@ const (
 
 	// version since transaction feature, added formatVersion, txId and cursor in MetaItemIterator struct
 	SnapFormatVersion_1
+
+	// version since resumable snapshot, the items are cut into checksummed chunks
+	SnapFormatVersion_2
 )
 
 // MetaItemIterator defines the iterator of the MetaItem.
@@ -150,6 +154,10 @@ type MetaItemIterator struct {
 	txRbDentryTree    *BTree
 	uniqChecker       *uniqChecker
 	verList           []*proto.VolVersionInfo
+	replicaStaging    *replicaSyncStaging
+	chunker           snapChunker
+	limiter           *rate.Limiter
+	partLimiter       *snapPartitionLimiter
 
 	filenames []string
 
@@ -191,21 +199,29 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 	si = new(MetaItemIterator)
 	si.fileRootDir = mp.config.RootDir
 	si.SnapFormatVersion = mp.manager.metaNode.raftSyncSnapFormatVersion
-	mp.nonIdempotent.Lock()
-	si.applyID = mp.getApplyID()
-	si.txId = mp.txProcessor.txManager.txIdAlloc.getTransactionID()
-	si.cursor = mp.GetCursor()
-	si.uniqID = mp.GetUniqId()
-	si.inodeTree = mp.inodeTree.GetTree()
-	si.dentryTree = mp.dentryTree.GetTree()
-	si.extendTree = mp.extendTree.GetTree()
-	si.multipartTree = mp.multipartTree.GetTree()
-	si.txTree = mp.txProcessor.txManager.txTree.GetTree()
-	si.txRbInodeTree = mp.txProcessor.txResource.txRbInodeTree.GetTree()
-	si.txRbDentryTree = mp.txProcessor.txResource.txRbDentryTree.GetTree()
-	si.uniqChecker = mp.uniqChecker.clone()
-	si.verList = mp.GetAllVerList()
-	mp.nonIdempotent.Unlock()
+	si.limiter = mp.manager.snapSendLimiter
+	si.partLimiter = mp.manager.newSnapPartitionLimiter()
+	var src *snapSource
+	if si.SnapFormatVersion >= SnapFormatVersion_2 {
+		src, si.chunker.skip = mp.resumableSnapSource()
+	} else {
+		src = mp.newSnapSource()
+	}
+	si.applyID = src.applyID
+	si.txId = src.txId
+	si.cursor = src.cursor
+	si.uniqID = src.uniqID
+	si.inodeTree = src.inodeTree
+	si.dentryTree = src.dentryTree
+	si.extendTree = src.extendTree
+	si.multipartTree = src.multipartTree
+	si.txTree = src.txTree
+	si.txRbInodeTree = src.txRbInodeTree
+	si.txRbDentryTree = src.txRbDentryTree
+	si.uniqChecker = src.uniqChecker
+	si.verList = src.verList
+	si.replicaStaging = src.replicaStaging
+	si.chunker.applyID = src.applyID
 
 	si.dataCh = make(chan interface{})
 	si.errorCh = make(chan error, 1)
@@ -256,13 +272,28 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 				return false
 			}
 		}
+		chunked := si.SnapFormatVersion >= SnapFormatVersion_2
+		chunkItems := 0
+		produceChunked := func(item interface{}) (success bool) {
+			if !produceItem(item) {
+				return false
+			}
+			if !chunked {
+				return true
+			}
+			if chunkItems++; chunkItems == snapChunkItems {
+				chunkItems = 0
+				return produceItem(snapChunkEnd{})
+			}
+			return true
+		}
 
 		if si.SnapFormatVersion == SnapFormatVersion_0 {
 			// process index ID
 			produceItem(si.applyID)
 			log.LogDebugf("newMetaItemIterator: SnapFormatVersion_0, partitionId(%v), applyID(%v)",
 				mp.config.PartitionId, si.applyID)
-		} else if si.SnapFormatVersion == SnapFormatVersion_1 {
+		} else if si.SnapFormatVersion == SnapFormatVersion_1 || si.SnapFormatVersion == SnapFormatVersion_2 {
 			// process snapshot format version
 			snapFormatVerWrapper := SnapItemWrapper{SiwKeySnapFormatVer, si.SnapFormatVersion}
 			produceItem(snapFormatVerWrapper)
@@ -290,68 +321,95 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 				uniqIdWrapper := SnapItemWrapper{SiwKeyUniqId, si.uniqID}
 				produceItem(uniqIdWrapper)
 			}
+			if chunked {
+				produceItem(snapChunkStart{})
+			}
 		} else {
 			panic(fmt.Sprintf("invalid raftSyncSnapFormatVersione: %v", si.SnapFormatVersion))
 		}
 
 		// process inodes
 		iter.inodeTree.Ascend(func(i BtreeItem) bool {
-			return produceItem(i)
+			return produceChunked(i)
 		})
 		if checkClose() {
 			return
 		}
 		// process dentries
 		iter.dentryTree.Ascend(func(i BtreeItem) bool {
-			return produceItem(i)
+			return produceChunked(i)
 		})
 		if checkClose() {
 			return
 		}
 		// process extends
 		iter.extendTree.Ascend(func(i BtreeItem) bool {
-			return produceItem(i)
+			return produceChunked(i)
 		})
 		if checkClose() {
 			return
 		}
 		// process multiparts
 		iter.multipartTree.Ascend(func(i BtreeItem) bool {
-			return produceItem(i)
+			return produceChunked(i)
 		})
 		if checkClose() {
 			return
 		}
 
-		if si.SnapFormatVersion == SnapFormatVersion_1 {
+		if si.SnapFormatVersion >= SnapFormatVersion_1 {
 			iter.txTree.Ascend(func(i BtreeItem) bool {
-				return produceItem(i)
+				return produceChunked(i)
 			})
 			if checkClose() {
 				return
 			}
 
 			iter.txRbInodeTree.Ascend(func(i BtreeItem) bool {
-				return produceItem(i)
+				return produceChunked(i)
 			})
 			if checkClose() {
 				return
 			}
 
 			iter.txRbDentryTree.Ascend(func(i BtreeItem) bool {
-				return produceItem(i)
+				return produceChunked(i)
 			})
 			if checkClose() {
 				return
 			}
 
 			if si.uniqID != 0 {
-				produceItem(si.uniqChecker)
+				produceChunked(si.uniqChecker)
+				if checkClose() {
+					return
+				}
+			}
+
+			if iter.replicaStaging != nil {
+				if err := iter.replicaStaging.rangeItems(func(item *MetaItem) bool {
+					if item.Op == opFSMReplicaSyncStaging {
+						return produceChunked(item)
+					}
+					raw, err := item.MarshalBinary()
+					if err != nil {
+						produceError(err)
+						return false
+					}
+					return produceChunked(NewMetaItem(opFSMReplicaSyncStagingItem, nil, raw))
+				}); err != nil {
+					produceError(err)
+					return
+				}
 				if checkClose() {
 					return
 				}
 			}
 		}
+		// the chunks of the trees end before the files, they are the ones resumed
+		if chunked && !produceItem(snapChunkEnd{}) {
+			return
+		}
 
 		// process extent del files
 		var err error
@@ -361,10 +419,13 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 				produceError(err)
 				return
 			}
-			if !produceItem(&fileData{filename: filename, data: raw}) {
+			if !produceChunked(&fileData{filename: filename, data: raw}) {
 				return
 			}
 		}
+		if chunked {
+			produceItem(snapChunkEnd{})
+		}
 	}(si)
 
 	return
@@ -382,8 +443,26 @@ func (si *MetaItemIterator) Close() {
 	})
 }
 
-// Next returns the next item.
+// Next returns the next item, and the chunks cut by the chunker since SnapFormatVersion_2.
+// The data sent is limited by the snapshot send rate of the node and the one of a partition.
 func (si *MetaItemIterator) Next() (data []byte, err error) {
+	for data == nil {
+		var marker interface{}
+		if data, marker, err = si.nextItem(); err != nil {
+			return
+		}
+		if data, err = si.chunker.next(data, marker); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+	}
+	waitSnapSendQuota(si.limiter, len(data))
+	si.partLimiter.wait(len(data))
+	return
+}
+
+func (si *MetaItemIterator) nextItem() (data []byte, marker interface{}, err error) {
 	if si.err != nil {
 		err = si.err
 		return
@@ -407,6 +486,9 @@ func (si *MetaItemIterator) Next() (data []byte, err error) {
 
 	var snap *MetaItem
 	switch typedItem := item.(type) {
+	case snapChunkStart, snapChunkEnd:
+		marker = item
+		return
 	case uint64:
 		applyIDBuf := make([]byte, 8)
 		binary.BigEndian.PutUint64(applyIDBuf, si.applyID)
@@ -476,6 +558,8 @@ func (si *MetaItemIterator) Next() (data []byte, err error) {
 		snap = NewMetaItem(opFSMTxRbDentrySnapshot, []byte(typedItem.txDentryInfo.GetKey()), val)
 	case *fileData:
 		snap = NewMetaItem(opExtentFileSnapshot, []byte(typedItem.filename), typedItem.data)
+	case *MetaItem:
+		snap = typedItem
 	case *uniqChecker:
 		var raw []byte
 		if raw, _, err = typedItem.Marshal(); err != nil {
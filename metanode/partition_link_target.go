@@ -0,0 +1,70 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/timeutil"
+)
+
+// UpdateLinkTarget rewrites the target of a symlink in place, so that the inode number,
+// the link count and the xattrs of the symlink are kept.
+func (mp *metaPartition) UpdateLinkTarget(req *UpdateLinkTargetRequest, p *Packet) (err error) {
+	if len(req.Target) == 0 {
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte("empty link target"))
+		return
+	}
+	req.ModifyTime = timeutil.GetCurrentTimeUnix()
+	req.VerSeq = mp.GetVerSeq()
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMUpdateLinkTarget, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	status := resp.(uint8)
+	log.LogDebugf("action[UpdateLinkTarget] mp(%v) ino(%v) status(%v)", mp.config.PartitionId, req.Inode, status)
+	p.PacketErrorWithBody(status, nil)
+	return
+}
+
+func (mp *metaPartition) fsmUpdateLinkTarget(req *UpdateLinkTargetRequest) (status uint8) {
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	ino := item.(*Inode)
+	if ino.ShouldDelete() {
+		return proto.OpNotExistErr
+	}
+	if !proto.IsSymlink(ino.Type) {
+		return proto.OpArgMismatchErr
+	}
+	if req.VerSeq != ino.getVer() {
+		ino.CreateVer(req.VerSeq)
+	}
+	ino.DoWriteFunc(func() {
+		ino.LinkTarget = req.Target
+		ino.ModifyTime = req.ModifyTime
+	})
+	return proto.OpOk
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateLinkTarget(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+
+	symlink := NewInode(100, proto.Mode(os.ModeSymlink|0o777))
+	symlink.LinkTarget = []byte("/old/target")
+	symlink.NLink = 2
+	mp.inodeTree.ReplaceOrInsert(symlink, true)
+	mp.inodeTree.ReplaceOrInsert(NewInode(101, FileModeType), true)
+
+	update := func(ino uint64, target string) uint8 {
+		p := &Packet{}
+		require.NoError(t, mp.UpdateLinkTarget(&UpdateLinkTargetRequest{Inode: ino, Target: []byte(target)}, p))
+		return p.ResultCode
+	}
+
+	require.Equal(t, proto.OpOk, update(100, "/new/target"))
+	item := mp.inodeTree.Get(NewInode(100, 0)).(*Inode)
+	require.Equal(t, "/new/target", string(item.LinkTarget))
+	require.EqualValues(t, 2, item.GetNLink())
+
+	require.Equal(t, proto.OpArgMismatchErr, update(100, ""))
+	require.Equal(t, proto.OpArgMismatchErr, update(101, "/new/target"))
+	require.Equal(t, proto.OpNotExistErr, update(102, "/new/target"))
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// CountDentryRefs counts the dentries of the partition referring to the inode, and the
+// children of it, on a snapshot of the trees. Master sums the counts of the partitions of
+// the volume to recount the link count of the inode.
+func (mp *metaPartition) CountDentryRefs(ino uint64) (resp *proto.MetaDentryRefResponse) {
+	mp.nonIdempotent.Lock()
+	applyID := mp.getApplyID()
+	inodeTree := mp.inodeTree.GetTree()
+	dentryTree := mp.dentryTree.GetTree()
+	mp.nonIdempotent.Unlock()
+
+	resp = &proto.MetaDentryRefResponse{PartitionID: mp.config.PartitionId, ApplyID: applyID}
+	if item := inodeTree.Get(NewInode(ino, 0)); item != nil {
+		if i := item.(*Inode); !i.ShouldDelete() {
+			resp.HasInode = true
+			resp.NLink = i.GetNLink()
+			resp.Type = i.Type
+		}
+	}
+	dentryTree.Ascend(func(i BtreeItem) bool {
+		dentry := i.(*Dentry)
+		if dentry.isDeleted() {
+			return true
+		}
+		if dentry.Inode == ino {
+			resp.Refs++
+		}
+		if dentry.ParentId == ino {
+			resp.Children++
+		}
+		return true
+	})
+	return
+}
+
+// RepairNLink sets the link count of the inode recounted by master through raft, it is done
+// only if the link count is still the one master has seen.
+func (mp *metaPartition) RepairNLink(req *proto.MetaRepairNLinkRequest, p *Packet, remoteAddr string) (err error) {
+	defer func() {
+		mp.auditOpWithResult(remoteAddr, p, req.Inode, 0, "",
+			fmt.Sprintf("nlink %v -> %v", req.OldNLink, req.NLink), err)
+	}()
+	if leader, ok := mp.IsLeader(); !ok {
+		return fmt.Errorf("mp %v is not the leader, leader is %v", mp.config.PartitionId, leader)
+	}
+	if mp.GetVerSeq() > 0 {
+		return fmt.Errorf("vol %v has snapshots, the link counts are shared with them", mp.config.VolName)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	resp, err := mp.submit(opFSMRepairNLink, data)
+	if err != nil {
+		return
+	}
+	if status := resp.(uint8); status != proto.OpOk {
+		return fmt.Errorf("repair nlink of ino %v from %v to %v: %v", req.Inode, req.OldNLink, req.NLink,
+			proto.GetStatusStr(status))
+	}
+	log.LogWarnf("action[RepairNLink] mp(%v) ino(%v) nlink from %v to %v by %v",
+		mp.config.PartitionId, req.Inode, req.OldNLink, req.NLink, remoteAddr)
+	return
+}
+
+func (mp *metaPartition) fsmRepairNLink(req *proto.MetaRepairNLinkRequest) (status uint8) {
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	i := item.(*Inode)
+	if i.ShouldDelete() {
+		return proto.OpNotExistErr
+	}
+	i.Lock()
+	defer i.Unlock()
+	if i.NLink != req.OldNLink {
+		// changed since master counted the dentries
+		return proto.OpAgain
+	}
+	i.NLink = req.NLink
+	return proto.OpOk
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairNLink(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	dir := NewInode(2, DirModeType)
+	dir.NLink = 5
+	mp.inodeTree.ReplaceOrInsert(dir, true)
+	file := NewInode(3, FileModeType)
+	file.NLink = 1
+	mp.inodeTree.ReplaceOrInsert(file, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "d", Inode: 2, Type: DirModeType}, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 2, Name: "sub", Inode: 4, Type: DirModeType}, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 2, Name: "f", Inode: 3, Type: FileModeType}, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "hardlink", Inode: 3, Type: FileModeType}, true)
+
+	resp := mp.CountDentryRefs(2)
+	require.True(t, resp.HasInode)
+	require.EqualValues(t, 5, resp.NLink)
+	require.EqualValues(t, 1, resp.Refs)
+	require.EqualValues(t, 2, resp.Children)
+
+	resp = mp.CountDentryRefs(3)
+	require.EqualValues(t, 1, resp.NLink)
+	require.EqualValues(t, 2, resp.Refs)
+	require.Zero(t, resp.Children)
+	require.False(t, mp.CountDentryRefs(10).HasInode)
+
+	// the link count changed since it is counted is not repaired
+	require.Equal(t, proto.OpAgain, mp.fsmRepairNLink(&proto.MetaRepairNLinkRequest{Inode: 3, OldNLink: 3, NLink: 2}))
+	require.Equal(t, proto.OpOk, mp.fsmRepairNLink(&proto.MetaRepairNLinkRequest{Inode: 3, OldNLink: 1, NLink: 2}))
+	require.EqualValues(t, 2, mp.CountDentryRefs(3).NLink)
+	require.Equal(t, proto.OpNotExistErr, mp.fsmRepairNLink(&proto.MetaRepairNLinkRequest{Inode: 10, NLink: 1}))
+}
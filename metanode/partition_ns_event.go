@@ -0,0 +1,161 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+const (
+	defaultWatchEventsLimit = 1024
+	// within the read deadline of the clients
+	maxWatchEventsTimeout = 3 * time.Second
+)
+
+// the namespace events kept by each partition for the watchers, 0 disables them
+var nsEventRingSize uint32
+
+// nsEventRing keeps the last namespace events applied to the partition in the order of their
+// raft indexes.
+type nsEventRing struct {
+	sync.Mutex
+	events []*proto.NamespaceEvent
+	head   int    // the oldest event once the ring is full
+	floor  uint64 // the events up to it are not kept
+	notify chan struct{}
+}
+
+func newNsEventRing() *nsEventRing {
+	return &nsEventRing{notify: make(chan struct{})}
+}
+
+// reset drops the events kept, the partition is loaded from the snapshot of index.
+func (r *nsEventRing) reset(index uint64) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = nil
+	r.head = 0
+	r.floor = index
+}
+
+func (r *nsEventRing) append(size int, events ...*proto.NamespaceEvent) {
+	r.Lock()
+	defer r.Unlock()
+	for _, event := range events {
+		if len(r.events) < size {
+			r.events = append(r.events, event)
+			continue
+		}
+		r.floor = r.events[r.head].Index
+		r.events[r.head] = event
+		r.head = (r.head + 1) % len(r.events)
+	}
+	close(r.notify)
+	r.notify = make(chan struct{})
+}
+
+func (r *nsEventRing) at(i int) *proto.NamespaceEvent {
+	return r.events[(r.head+i)%len(r.events)]
+}
+
+// after returns up to limit events after from, and the channel closed once an event is
+// appended. The events of a raft entry are returned together even beyond limit.
+func (r *nsEventRing) after(from uint64, limit int) (resp *proto.WatchEventsResponse, notify <-chan struct{}) {
+	r.Lock()
+	defer r.Unlock()
+	resp = &proto.WatchEventsResponse{Events: make([]*proto.NamespaceEvent, 0), Next: from}
+	if from < r.floor {
+		resp.Lost = true
+	}
+	n := len(r.events)
+	for i := sort.Search(n, func(i int) bool { return r.at(i).Index > from }); i < n; i++ {
+		event := r.at(i)
+		if len(resp.Events) >= limit && event.Index != resp.Next {
+			break
+		}
+		resp.Events = append(resp.Events, event)
+		resp.Next = event.Index
+	}
+	return resp, r.notify
+}
+
+func (mp *metaPartition) appendNsEvents(events ...*proto.NamespaceEvent) {
+	size := int(atomic.LoadUint32(&nsEventRingSize))
+	if size == 0 || mp.nsEvents == nil || len(events) == 0 {
+		return
+	}
+	mp.nsEvents.append(size, events...)
+}
+
+func newDentryEvent(index uint64, typ string, dentry *Dentry) *proto.NamespaceEvent {
+	return &proto.NamespaceEvent{
+		Index:    index,
+		Type:     typ,
+		ParentID: dentry.ParentId,
+		Name:     dentry.Name,
+		Inode:    dentry.Inode,
+		Mode:     dentry.Type,
+		Time:     time.Now().Unix(),
+	}
+}
+
+// WatchEvents replies the namespace events after req.From, waiting for them up to the
+// timeout of the request if there are none yet.
+func (mp *metaPartition) WatchEvents(req *proto.WatchEventsRequest, p *Packet) (err error) {
+	if atomic.LoadUint32(&nsEventRingSize) == 0 || mp.nsEvents == nil {
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte("namespace events are not enabled on the metanode"))
+		return
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > defaultWatchEventsLimit {
+		limit = defaultWatchEventsLimit
+	}
+	timeout := time.Duration(req.TimeoutMs) * time.Millisecond
+	if timeout > maxWatchEventsTimeout {
+		timeout = maxWatchEventsTimeout
+	}
+
+	var resp *proto.WatchEventsResponse
+	if req.From == 0 {
+		resp = &proto.WatchEventsResponse{Events: make([]*proto.NamespaceEvent, 0), Next: mp.GetAppliedID()}
+	} else {
+		var notify <-chan struct{}
+		resp, notify = mp.nsEvents.after(req.From, limit)
+		if len(resp.Events) == 0 && !resp.Lost && timeout > 0 {
+			timer := time.NewTimer(timeout)
+			select {
+			case <-notify:
+				resp, _ = mp.nsEvents.after(req.From, limit)
+			case <-timer.C:
+			case <-mp.stopC:
+			}
+			timer.Stop()
+		}
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(data)
+	return
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func watchEvents(t *testing.T, mp *metaPartition, req *proto.WatchEventsRequest) (resp *proto.WatchEventsResponse) {
+	p := &Packet{}
+	require.NoError(t, mp.WatchEvents(req, p))
+	require.Equal(t, proto.OpOk, p.ResultCode, string(p.Data))
+	resp = &proto.WatchEventsResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, resp))
+	return
+}
+
+func TestNsEvents(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.uidManager = NewUidMgr(VolNameForTest, mp.config.PartitionId)
+	mp.inodeTree.ReplaceOrInsert(NewInode(1, DirModeType), true)
+	apply := func(op uint32, v interface{ Marshal() ([]byte, error) }, index uint64) {
+		data, err := v.Marshal()
+		require.NoError(t, err)
+		cmd, err := NewMetaItem(op, nil, data).MarshalJson()
+		require.NoError(t, err)
+		_, err = mp.Apply(cmd, index)
+		require.NoError(t, err)
+	}
+
+	p := &Packet{}
+	require.NoError(t, mp.WatchEvents(&proto.WatchEventsRequest{}, p))
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+
+	old := atomic.SwapUint32(&nsEventRingSize, 3)
+	defer atomic.StoreUint32(&nsEventRingSize, old)
+
+	apply(opFSMCreateDentry, &Dentry{ParentId: 1, Name: "a", Inode: 10, Type: FileModeType}, 1)
+	apply(opFSMCreateDentry, &Dentry{ParentId: 1, Name: "b", Inode: 11, Type: FileModeType}, 2)
+	apply(opFSMUpdateDentry, &Dentry{ParentId: 1, Name: "b", Inode: 10, Type: FileModeType}, 3)
+	// a dentry not there has no event
+	apply(opFSMDeleteDentry, &Dentry{ParentId: 1, Name: "c"}, 4)
+
+	resp := watchEvents(t, mp, &proto.WatchEventsRequest{From: 1})
+	require.False(t, resp.Lost)
+	require.Len(t, resp.Events, 2)
+	require.Equal(t, proto.NamespaceEventCreate, resp.Events[0].Type)
+	require.Equal(t, "b", resp.Events[0].Name)
+	require.Equal(t, proto.NamespaceEventUpdate, resp.Events[1].Type)
+	require.EqualValues(t, 10, resp.Events[1].Inode)
+	require.EqualValues(t, 11, resp.Events[1].OldInode)
+	require.EqualValues(t, 3, resp.Next)
+
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 1, Limit: 1})
+	require.Len(t, resp.Events, 1)
+	require.EqualValues(t, 2, resp.Next)
+
+	// the watch waits for the next event
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		apply(opFSMDeleteDentry, &Dentry{ParentId: 1, Name: "a"}, 5)
+	}()
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 3, TimeoutMs: 2000})
+	require.Len(t, resp.Events, 1)
+	require.Equal(t, proto.NamespaceEventUnlink, resp.Events[0].Type)
+	require.EqualValues(t, 10, resp.Events[0].Inode)
+	require.EqualValues(t, 5, resp.Next)
+
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 5, TimeoutMs: 10})
+	require.Empty(t, resp.Events)
+	require.EqualValues(t, 5, resp.Next)
+
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 0})
+	require.EqualValues(t, mp.GetAppliedID(), resp.Next)
+
+	// the ring is full, the event after 1 is dropped by the next one
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 1})
+	require.False(t, resp.Lost)
+	apply(opFSMCreateDentry, &Dentry{ParentId: 1, Name: "c", Inode: 12, Type: FileModeType}, 6)
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 1})
+	require.True(t, resp.Lost)
+	require.Len(t, resp.Events, 3)
+	require.EqualValues(t, 3, resp.Events[0].Index)
+
+	// the events before the snapshot are not kept
+	mp.nsEvents.reset(6)
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 5})
+	require.True(t, resp.Lost)
+	require.Empty(t, resp.Events)
+	resp = watchEvents(t, mp, &proto.WatchEventsRequest{From: 6})
+	require.False(t, resp.Lost)
+}
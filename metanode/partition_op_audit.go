@@ -0,0 +1,187 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/cubefs/cubefs/util/auditlog"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultOpAuditDirName     = "op_audit"
+	defaultOpAuditLogMaxSize  = 256 * 1024 * 1024
+	defaultOpAuditTailLines   = 100
+	maxOpAuditTailLines       = 10000
+	opAuditTailReadChunkBytes = 64 * 1024
+)
+
+// opAuditEntry is a line of the op audit log of a partition.
+type opAuditEntry struct {
+	Time   string `json:"ts"`
+	Client string `json:"client"`
+	ReqID  int64  `json:"reqId"`
+	Op     string `json:"op"`
+	Inode  uint64 `json:"ino"`
+	Parent uint64 `json:"parent,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Result string `json:"result"`
+	Err    string `json:"err,omitempty"`
+}
+
+// opAuditModule is the dir of the op audit log of the partition under opAuditDir/vol.
+func (mp *metaPartition) opAuditModule() string {
+	return fmt.Sprintf("mp_%d", mp.config.PartitionId)
+}
+
+func (mp *metaPartition) opAuditFile() string {
+	return path.Join(mp.manager.opAuditDir, mp.config.VolName, mp.opAuditModule(), auditlog.Audit_Module+".log")
+}
+
+// getOpAudit returns the op audit log of the partition, it is opened at the first op after
+// enableOpAudit of the volume is turned on.
+func (mp *metaPartition) getOpAudit() (a *auditlog.Audit, err error) {
+	mp.opAuditLock.RLock()
+	a = mp.opAudit
+	mp.opAuditLock.RUnlock()
+	if a != nil {
+		return
+	}
+
+	mp.opAuditLock.Lock()
+	defer mp.opAuditLock.Unlock()
+	if mp.opAudit != nil {
+		return mp.opAudit, nil
+	}
+	if mp.manager == nil || mp.manager.opAuditDir == "" {
+		return nil, fmt.Errorf("op audit dir is not configured")
+	}
+	if mp.opAudit, err = auditlog.NewAudit(path.Join(mp.manager.opAuditDir, mp.config.VolName),
+		mp.opAuditModule(), mp.manager.opAuditLogMaxSize); err != nil {
+		return
+	}
+	log.LogInfof("[getOpAudit] mp(%v) vol(%v) open op audit log", mp.config.PartitionId, mp.config.VolName)
+	return mp.opAudit, nil
+}
+
+// closeOpAudit stops the op audit log of the partition, the written files are kept.
+func (mp *metaPartition) closeOpAudit() {
+	mp.opAuditLock.Lock()
+	defer mp.opAuditLock.Unlock()
+	if mp.opAudit == nil {
+		return
+	}
+	mp.opAudit.Stop()
+	mp.opAudit = nil
+	log.LogInfof("[closeOpAudit] mp(%v) vol(%v) close op audit log", mp.config.PartitionId, mp.config.VolName)
+}
+
+// auditOp appends the namespace mutation handled by p to the op audit log of the partition
+// if the volume enables it. It is called on the leader after the op is done.
+func (mp *metaPartition) auditOp(remoteAddr string, p *Packet, ino, parent uint64, name string, err error) {
+	mp.auditOpWithResult(remoteAddr, p, ino, parent, name, p.GetResultMsg(), err)
+}
+
+// auditOpWithResult is auditOp for the items of batch ops which have their own results.
+func (mp *metaPartition) auditOpWithResult(remoteAddr string, p *Packet, ino, parent uint64, name, result string, err error) {
+	if !mp.GetVolConfig().EnableOpAudit {
+		return
+	}
+	entry := &opAuditEntry{
+		Time:   time.Now().Format(time.RFC3339Nano),
+		Client: remoteAddr,
+		ReqID:  p.ReqID,
+		Op:     p.GetOpMsg(),
+		Inode:  ino,
+		Parent: parent,
+		Name:   name,
+		Result: result,
+	}
+	if err != nil {
+		entry.Err = err.Error()
+	}
+	data, _ := json.Marshal(entry)
+
+	a, openErr := mp.getOpAudit()
+	if openErr != nil {
+		log.LogErrorf("[auditOp] mp(%v) open op audit log failed: %v, entry: %s", mp.config.PartitionId, openErr, data)
+		return
+	}
+	mp.opAuditLock.RLock()
+	if mp.opAudit == a {
+		a.AddLog(string(data))
+	}
+	mp.opAuditLock.RUnlock()
+}
+
+// tailOpAudit returns the last n lines of the current op audit log of the partition.
+func (mp *metaPartition) tailOpAudit(n int) (lines []string, err error) {
+	f, err := os.Open(mp.opAuditFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return
+	}
+	defer f.Close()
+	return tailLines(f, n)
+}
+
+// tailLines reads the last n lines of f backwards by chunks.
+func tailLines(f *os.File, n int) (lines []string, err error) {
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	var (
+		offset = info.Size()
+		tail   []byte
+	)
+	for offset > 0 && bytes.Count(tail, []byte{'\n'}) <= n {
+		size := int64(opAuditTailReadChunkBytes)
+		if size > offset {
+			size = offset
+		}
+		offset -= size
+		buf := make([]byte, size)
+		if _, err = f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return
+		}
+		tail = append(buf, tail...)
+	}
+	err = nil
+	all := bytes.Split(bytes.TrimRight(tail, "\n"), []byte{'\n'})
+	if offset > 0 && len(all) > 0 {
+		// the first line may be cut by the chunk
+		all = all[1:]
+	}
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	lines = make([]string, 0, len(all))
+	for _, line := range all {
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpAudit(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.manager = &metadataManager{opAuditDir: t.TempDir(), opAuditLogMaxSize: defaultOpAuditLogMaxSize}
+	p := &Packet{}
+	p.Opcode = proto.OpMetaCreateDentry
+	p.ReqID = 7
+	p.ResultCode = proto.OpOk
+
+	// disabled by default
+	mp.auditOp("127.0.0.1:1000", p, 2, 1, "a", nil)
+	require.Nil(t, mp.opAudit)
+
+	mp.reloadVolConfig(&proto.SimpleVolView{EnableOpAudit: true})
+	mp.auditOp("127.0.0.1:1000", p, 2, 1, "a", nil)
+	p.ResultCode = proto.OpExistErr
+	mp.auditOp("127.0.0.1:1000", p, 3, 1, "b", fmt.Errorf("exists"))
+
+	var lines []string
+	require.Eventually(t, func() bool {
+		var err error
+		lines, err = mp.tailOpAudit(10)
+		require.NoError(t, err)
+		return len(lines) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	entry := &opAuditEntry{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), entry))
+	require.Equal(t, "127.0.0.1:1000", entry.Client)
+	require.EqualValues(t, 7, entry.ReqID)
+	require.Equal(t, p.GetOpMsg(), entry.Op)
+	require.EqualValues(t, 2, entry.Inode)
+	require.EqualValues(t, 1, entry.Parent)
+	require.Equal(t, "a", entry.Name)
+	require.Equal(t, proto.GetStatusStr(proto.OpOk), entry.Result)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), entry))
+	require.Equal(t, "exists", entry.Err)
+
+	lines, err := mp.tailOpAudit(1)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], `"name":"b"`)
+
+	mp.reloadVolConfig(&proto.SimpleVolView{})
+	require.Nil(t, mp.opAudit)
+	mp.auditOp("127.0.0.1:1000", p, 4, 1, "c", nil)
+	require.Nil(t, mp.opAudit)
+}
+
+func TestTailLines(t *testing.T) {
+	file := path.Join(t.TempDir(), "audit.log")
+	var sb strings.Builder
+	for i := 0; i < 3000; i++ {
+		sb.WriteString(fmt.Sprintf("line-%04d-%s\n", i, strings.Repeat("x", 50)))
+	}
+	require.NoError(t, os.WriteFile(file, []byte(sb.String()), 0o644))
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	lines, err := tailLines(f, 2000)
+	require.NoError(t, err)
+	require.Len(t, lines, 2000)
+	require.True(t, strings.HasPrefix(lines[0], "line-1000-"))
+	require.True(t, strings.HasPrefix(lines[1999], "line-2999-"))
+
+	lines, err = tailLines(f, 5000)
+	require.NoError(t, err)
+	require.Len(t, lines, 3000)
+	require.True(t, strings.HasPrefix(lines[0], "line-0000-"))
+}
@@ -38,6 +38,9 @@ func (mp *metaPartition) TxCreateDentry(req *proto.TxCreateDentryRequest, p *Pac
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), opMsg, req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, req.ParentID, req.Name, err)
+	}()
 	if req.ParentID == req.Inode {
 		err = fmt.Errorf("parentId is equal inodeId")
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
@@ -95,6 +98,9 @@ func (mp *metaPartition) CreateDentry(req *CreateDentryReq, p *Packet, remoteAdd
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, req.ParentID)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, req.ParentID, req.Name, err)
+	}()
 	if req.ParentID == req.Inode {
 		err = fmt.Errorf("parentId is equal inodeId")
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
@@ -143,6 +149,9 @@ func (mp *metaPartition) QuotaCreateDentry(req *proto.QuotaCreateDentryRequest,
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, req.ParentID)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, req.ParentID, req.Name, err)
+	}()
 	if req.ParentID == req.Inode {
 		err = fmt.Errorf("parentId is equal inodeId")
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
@@ -204,6 +213,9 @@ func (mp *metaPartition) TxDeleteDentry(req *proto.TxDeleteDentryRequest, p *Pac
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), opMsg, req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Ino, req.ParentID)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Ino, req.ParentID, req.Name, err)
+	}()
 	txInfo := req.TxInfo.GetCopy()
 	den := &Dentry{
 		ParentId: req.ParentID,
@@ -285,10 +297,13 @@ func (mp *metaPartition) DeleteDentry(req *DeleteDentryReq, p *Packet, remoteAdd
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), dentry.Inode, req.ParentID)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, dentry.Inode, req.ParentID, req.Name, err)
+	}()
 	if req.InodeCreateTime > 0 {
-		if mp.vol.volDeleteLockTime > 0 && req.InodeCreateTime+mp.vol.volDeleteLockTime*60*60 > time.Now().Unix() {
+		if deleteLockTime := mp.GetVolConfig().DeleteLockTime; deleteLockTime > 0 && req.InodeCreateTime+deleteLockTime*60*60 > time.Now().Unix() {
 			err = errors.NewErrorf("the current Inode[%v] is still locked for deletion", req.Name)
-			log.LogDebugf("DeleteDentry: the current Inode is still locked for deletion, inode[%v] createTime(%v) mw.volDeleteLockTime(%v) now(%v)", req.Name, req.InodeCreateTime, mp.vol.volDeleteLockTime, time.Now().Unix())
+			log.LogDebugf("DeleteDentry: the current Inode is still locked for deletion, inode[%v] createTime(%v) mw.volDeleteLockTime(%v) now(%v)", req.Name, req.InodeCreateTime, deleteLockTime, time.Now().Unix())
 			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
 			return
 		}
@@ -349,6 +364,11 @@ func (mp *metaPartition) DeleteDentryBatch(req *BatchDeleteDentryReq, p *Packet,
 			}()
 		}
 	}
+	defer func() {
+		for _, d := range req.Dens {
+			mp.auditOp(remoteAddr, p, d.Inode, req.ParentID, d.Name, err)
+		}
+	}()
 
 	val, err := db.Marshal()
 	if err != nil {
@@ -411,6 +431,9 @@ func (mp *metaPartition) TxUpdateDentry(req *proto.TxUpdateDentryRequest, p *Pac
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), opMsg, req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, req.ParentID)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, req.ParentID, req.Name, err)
+	}()
 	if req.ParentID == req.Inode {
 		err = fmt.Errorf("parentId is equal inodeId")
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
@@ -482,6 +505,9 @@ func (mp *metaPartition) UpdateDentry(req *UpdateDentryReq, p *Packet, remoteAdd
 			auditlog.LogDentryOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.Name, req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, req.ParentID)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, req.ParentID, req.Name, err)
+	}()
 	if req.ParentID == req.Inode {
 		err = fmt.Errorf("parentId is equal inodeId")
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
@@ -552,6 +578,18 @@ func (mp *metaPartition) ReadDirLimit(req *ReadDirLimitReq, p *Packet) (err erro
 	return
 }
 
+// ReadDirPlus replies the dentries of the dir with the inodes of them in the partition.
+func (mp *metaPartition) ReadDirPlus(req *ReadDirPlusReq, p *Packet) (err error) {
+	resp := mp.readDirPlus(req)
+	reply, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
 // Lookup looks up the given dentry from the request.
 func (mp *metaPartition) Lookup(req *LookupReq, p *Packet) (err error) {
 	dentry := &Dentry{
@@ -575,6 +613,10 @@ func (mp *metaPartition) Lookup(req *LookupReq, p *Packet) (err error) {
 				VerSeq: dentry.getSeqFiled(),
 				LayAll: denList,
 			}
+			// the snapshots are not prefetched
+			if req.Siblings > 0 && req.VerSeq == 0 && !req.VerAll {
+				resp.Siblings = mp.lookupSiblings(req.ParentID, req.Name, req.Siblings)
+			}
 		} else {
 			resp = &LookupResp{
 				Inode:  0,
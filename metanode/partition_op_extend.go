@@ -16,19 +16,48 @@ package metanode
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
 )
 
+const (
+	defaultScanXAttrLimit = 1000
+	maxScanXAttrLimit     = 10000
+	// the extends visited by a scan at most, so that it returns in time if few match the keys
+	maxScanXAttrExtends = 10 * maxScanXAttrLimit
+
+	innerXAttrPrefix = proto.InnerXAttrPrefix
+)
+
 func (mp *metaPartition) UpdateXAttr(req *proto.UpdateXAttrRequest, p *Packet) (err error) {
 	log.LogWarnf("UpdateXAttr not supported in new version, value=%s", req.Value)
 	p.PacketErrorWithBody(proto.OpErr, []byte("not supported in new version"))
 	return
 }
 
+// ErrInnerXAttr is returned for the xattrs of the users named with the prefix of the inner ones.
+var ErrInnerXAttr = errors.New("xattr prefix " + innerXAttrPrefix + " is reserved")
+
+func checkUserXAttrKey(key string) error {
+	if strings.HasPrefix(key, innerXAttrPrefix) {
+		return ErrInnerXAttr
+	}
+	return nil
+}
+
 func (mp *metaPartition) SetXAttr(req *proto.SetXAttrRequest, p *Packet) (err error) {
+	if err = checkUserXAttrKey(req.Key); err != nil {
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+		return
+	}
+	if err = mp.checkXAttrLimit(req.Inode, map[string]string{req.Key: req.Value}); err != nil {
+		p.PacketErrorWithBody(proto.OpXAttrLimitExceeded, []byte(err.Error()))
+		return
+	}
 	extend := NewExtend(req.Inode)
 	extend.Put([]byte(req.Key), []byte(req.Value), mp.verSeq)
 	if _, err = mp.putExtend(opFSMSetXAttr, extend); err != nil {
@@ -40,6 +69,16 @@ func (mp *metaPartition) SetXAttr(req *proto.SetXAttrRequest, p *Packet) (err er
 }
 
 func (mp *metaPartition) BatchSetXAttr(req *proto.BatchSetXAttrRequest, p *Packet) (err error) {
+	for key := range req.Attrs {
+		if err = checkUserXAttrKey(key); err != nil {
+			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+			return
+		}
+	}
+	if err = mp.checkXAttrLimit(req.Inode, req.Attrs); err != nil {
+		p.PacketErrorWithBody(proto.OpXAttrLimitExceeded, []byte(err.Error()))
+		return
+	}
 	extend := NewExtend(req.Inode)
 	for key, val := range req.Attrs {
 		extend.Put([]byte(key), []byte(val), mp.verSeq)
@@ -61,7 +100,7 @@ func (mp *metaPartition) GetXAttr(req *proto.GetXAttrRequest, p *Packet) (err er
 		Key:         req.Key,
 	}
 	treeItem := mp.extendTree.Get(NewExtend(req.Inode))
-	if treeItem != nil {
+	if treeItem != nil && checkUserXAttrKey(req.Key) == nil {
 		if extend := treeItem.(*Extend).GetExtentByVersion(req.VerSeq); extend != nil {
 			if value, exist := extend.Get([]byte(req.Key)); exist {
 				response.Value = string(value)
@@ -88,9 +127,12 @@ func (mp *metaPartition) GetAllXAttr(req *proto.GetAllXAttrRequest, p *Packet) (
 	treeItem := mp.extendTree.Get(NewExtend(req.Inode))
 	if treeItem != nil {
 		if extend := treeItem.(*Extend).GetExtentByVersion(req.VerSeq); extend != nil {
-			for key, val := range extend.dataMap {
-				response.Attrs[key] = string(val)
-			}
+			extend.Range(func(key, value []byte) bool {
+				if !strings.HasPrefix(string(key), innerXAttrPrefix) {
+					response.Attrs[string(key)] = string(value)
+				}
+				return true
+			})
 		}
 	}
 	var encoded []byte
@@ -120,6 +162,9 @@ func (mp *metaPartition) BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packe
 			var extend *Extend
 			if extend = treeItem.(*Extend).GetExtentByVersion(req.VerSeq); extend != nil {
 				for _, key := range req.Keys {
+					if checkUserXAttrKey(key) != nil {
+						continue
+					}
 					if val, exist := extend.Get([]byte(key)); exist {
 						info.XAttrs[key] = string(val)
 					}
@@ -137,7 +182,65 @@ func (mp *metaPartition) BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packe
 	return
 }
 
+// ScanXAttr returns the xattrs of the inodes of the partition from the marker in the order of
+// the inodes, the inner xattrs of the metanode are skipped. The scan is resumed from the next
+// marker of the response.
+func (mp *metaPartition) ScanXAttr(req *proto.ScanXAttrRequest, p *Packet) (err error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultScanXAttrLimit
+	} else if limit > maxScanXAttrLimit {
+		limit = maxScanXAttrLimit
+	}
+	keys := make(map[string]bool, len(req.Keys))
+	for _, key := range req.Keys {
+		keys[key] = true
+	}
+	response := &proto.ScanXAttrResponse{
+		VolName:     req.VolName,
+		PartitionId: req.PartitionId,
+		XAttrs:      make([]*proto.XAttrInfo, 0),
+	}
+	visited := 0
+	mp.extendTree.AscendGreaterOrEqual(NewExtend(req.Marker), func(i BtreeItem) bool {
+		e := i.(*Extend)
+		if len(response.XAttrs) >= limit || visited >= maxScanXAttrExtends {
+			response.NextMarker = e.GetInode()
+			return false
+		}
+		visited++
+		extend := e.GetExtentByVersion(req.VerSeq)
+		if extend == nil {
+			return true
+		}
+		info := &proto.XAttrInfo{Inode: e.GetInode(), XAttrs: make(map[string]string)}
+		extend.Range(func(key, value []byte) bool {
+			k := string(key)
+			if strings.HasPrefix(k, innerXAttrPrefix) || (len(keys) > 0 && !keys[k]) || !strings.HasPrefix(k, req.Prefix) {
+				return true
+			}
+			info.XAttrs[k] = string(value)
+			return true
+		})
+		if len(info.XAttrs) > 0 {
+			response.XAttrs = append(response.XAttrs, info)
+		}
+		return true
+	})
+	var encoded []byte
+	if encoded, err = json.Marshal(response); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(encoded)
+	return
+}
+
 func (mp *metaPartition) RemoveXAttr(req *proto.RemoveXAttrRequest, p *Packet) (err error) {
+	if err = checkUserXAttrKey(req.Key); err != nil {
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+		return
+	}
 	extend := NewExtend(req.Inode)
 	extend.Put([]byte(req.Key), nil, req.VerSeq)
 	if _, err = mp.putExtend(opFSMRemoveXAttr, extend); err != nil {
@@ -159,7 +262,9 @@ func (mp *metaPartition) ListXAttr(req *proto.ListXAttrRequest, p *Packet) (err
 	if treeItem != nil {
 		if extend := treeItem.(*Extend).GetExtentByVersion(req.VerSeq); extend != nil {
 			extend.Range(func(key, value []byte) bool {
-				response.XAttrs = append(response.XAttrs, string(key))
+				if !strings.HasPrefix(string(key), innerXAttrPrefix) {
+					response.XAttrs = append(response.XAttrs, string(key))
+				}
 				return true
 			})
 		}
@@ -0,0 +1,129 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanXAttr(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		extendTree: NewBtree(),
+	}
+	for ino := uint64(1); ino <= 10; ino++ {
+		extend := NewExtend(ino)
+		extend.Put([]byte("user.tag"), []byte(fmt.Sprint(ino)), 0)
+		if ino%2 == 0 {
+			extend.Put([]byte("user.tier"), []byte("cold"), 0)
+		}
+		extend.Put([]byte(innerDirLockKey), []byte("lock"), 0)
+		mp.extendTree.ReplaceOrInsert(extend, true)
+	}
+	scan := func(req *proto.ScanXAttrRequest) *proto.ScanXAttrResponse {
+		p := &Packet{}
+		require.NoError(t, mp.ScanXAttr(req, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+		resp := &proto.ScanXAttrResponse{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		return resp
+	}
+
+	// the pages are resumed from the markers, without the inner xattrs
+	var inodes []uint64
+	req := &proto.ScanXAttrRequest{Limit: 3}
+	for pages := 1; ; pages++ {
+		resp := scan(req)
+		for _, info := range resp.XAttrs {
+			inodes = append(inodes, info.Inode)
+			require.Equal(t, fmt.Sprint(info.Inode), info.XAttrs["user.tag"])
+			require.NotContains(t, info.XAttrs, innerDirLockKey)
+		}
+		if resp.NextMarker == 0 {
+			require.Equal(t, 4, pages)
+			break
+		}
+		req.Marker = resp.NextMarker
+	}
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, inodes)
+
+	// the inodes with none of the keys are skipped
+	resp := scan(&proto.ScanXAttrRequest{Marker: 5, Keys: []string{"user.tier"}})
+	require.Len(t, resp.XAttrs, 3)
+	require.Equal(t, uint64(6), resp.XAttrs[0].Inode)
+	require.Equal(t, map[string]string{"user.tier": "cold"}, resp.XAttrs[0].XAttrs)
+	require.Zero(t, resp.NextMarker)
+	resp = scan(&proto.ScanXAttrRequest{Prefix: "user.ti"})
+	require.Len(t, resp.XAttrs, 5)
+	resp = scan(&proto.ScanXAttrRequest{Prefix: "cfs_inner_"})
+	require.Empty(t, resp.XAttrs)
+}
+
+func TestUserXAttrInnerPrefix(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		extendTree: NewBtree(),
+	}
+
+	// the inner xattrs are not set or removed by the users
+	p := &Packet{}
+	require.Equal(t, ErrInnerXAttr, mp.SetXAttr(&proto.SetXAttrRequest{Inode: 1, Key: innerFileLockKey, Value: "x"}, p))
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+	p = &Packet{}
+	require.Equal(t, ErrInnerXAttr, mp.BatchSetXAttr(&proto.BatchSetXAttrRequest{
+		Inode: 1, Attrs: map[string]string{"user.tag": "x", innerDirStatKey: "x"},
+	}, p))
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+	p = &Packet{}
+	require.Equal(t, ErrInnerXAttr, mp.RemoveXAttr(&proto.RemoveXAttrRequest{Inode: 1, Key: innerDirLockKey}, p))
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+	require.Zero(t, mp.extendTree.Len())
+}
+
+func TestUserXAttrHidesInner(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		extendTree: NewBtree(),
+	}
+	extend := NewExtend(1)
+	extend.Put([]byte("user.tag"), []byte("x"), 0)
+	extend.Put([]byte(innerDirStatKey), []byte("stat"), 0)
+	mp.extendTree.ReplaceOrInsert(extend, true)
+
+	// the inner xattrs are not read by the users
+	p := &Packet{}
+	require.NoError(t, mp.GetXAttr(&proto.GetXAttrRequest{Inode: 1, Key: innerDirStatKey}, p))
+	getResp := &proto.GetXAttrResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, getResp))
+	require.Empty(t, getResp.Value)
+
+	p = &Packet{}
+	require.NoError(t, mp.BatchGetXAttr(&proto.BatchGetXAttrRequest{Inodes: []uint64{1}, Keys: []string{"user.tag", innerDirStatKey}}, p))
+	batchResp := &proto.BatchGetXAttrResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, batchResp))
+	require.Len(t, batchResp.XAttrs, 1)
+	require.Equal(t, map[string]string{"user.tag": "x"}, batchResp.XAttrs[0].XAttrs)
+
+	p = &Packet{}
+	require.NoError(t, mp.ListXAttr(&proto.ListXAttrRequest{Inode: 1}, p))
+	listResp := &proto.ListXAttrResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, listResp))
+	require.Equal(t, []string{"user.tag"}, listResp.XAttrs)
+}
@@ -73,18 +73,26 @@ func (mp *metaPartition) ExtentAppend(req *proto.AppendExtentKeyRequest, p *Pack
 		return
 	}
 	ino := NewInode(req.Inode, 0)
-	if _, _, err = mp.CheckQuota(req.Inode, p); err != nil {
+	var inode *Inode
+	if _, inode, err = mp.CheckQuota(req.Inode, p); err != nil {
 		log.LogErrorf("ExtentAppend fail status [%v]", err)
 		return
 	}
+	if err = mp.checkExtentKeys(inode, req.Extent); err != nil {
+		log.LogWarnf("ExtentAppend mp(%v) %v", mp.config.PartitionId, err)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
 	ext := req.Extent
 	ino.GetExtents().Append(ext)
+	// the writer is not known to be based on the current extents
+	ino.Generation = 0
 	val, err := ino.Marshal()
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	resp, err := mp.submit(opFSMExtentsAdd, val)
+	resp, err := mp.submit(mp.extentsAddOp(), val)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return
@@ -111,8 +119,8 @@ func (mp *metaPartition) ExtentAppendWithCheck(req *proto.AppendExtentKeyWithChe
 		p.PacketErrorWithBody(status, reply)
 		return
 	}
-	var inoParm *Inode
-	if inoParm, _, err = mp.CheckQuota(req.Inode, p); err != nil {
+	var inoParm, inode *Inode
+	if inoParm, inode, err = mp.CheckQuota(req.Inode, p); err != nil {
 		log.LogErrorf("ExtentAppendWithCheck CheckQuota fail err [%v]", err)
 		return
 	}
@@ -153,11 +161,21 @@ func (mp *metaPartition) ExtentAppendWithCheck(req *proto.AppendExtentKeyWithChe
 		}()
 	}
 
+	if !req.IsMigration {
+		if err = mp.checkExtentKeys(inode, req.Extent); err != nil {
+			log.LogWarnf("ExtentAppendWithCheck mp(%v) %v", mp.config.PartitionId, err)
+			p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+			return
+		}
+	}
+
 	ext := req.Extent
 
 	// extent key verSeq not set value since marshal will not include verseq
 	// use inode verSeq instead
 	inoParm.setVer(mp.verSeq)
+	// the generation of the writer decides the conflicts with other extents in fsm
+	inoParm.Generation = req.Generation
 	if !req.IsCache {
 		inoParm.StorageClass = req.StorageClass
 	}
@@ -192,6 +210,8 @@ func (mp *metaPartition) ExtentAppendWithCheck(req *proto.AppendExtentKeyWithChe
 	var opFlag uint32 = opFSMExtentsAddWithCheck
 	if req.IsSplit {
 		opFlag = opFSMExtentSplit
+	} else if !req.IsMigration {
+		opFlag = mp.extentsAddWithCheckOp()
 	}
 	resp, err := mp.submit(opFlag, val)
 	if err != nil {
@@ -207,6 +227,10 @@ func (mp *metaPartition) ExtentAppendWithCheck(req *proto.AppendExtentKeyWithChe
 		p.ExtentType |= proto.MultiVersionFlag
 		p.VerSeq = mp.verSeq
 	}
+	if resp.(uint8) == proto.OpOk && !req.IsSplit && !req.IsMigration {
+		p.PacketOkWithBody(mp.appendExtentKeyReply(req.Inode))
+		return
+	}
 	p.PacketErrorWithBody(resp.(uint8), nil)
 	return
 }
@@ -625,6 +649,68 @@ func (mp *metaPartition) ExtentsTruncate(req *ExtentsTruncateReq, p *Packet, rem
 	return
 }
 
+// ExtentsPreAlloc reserves a range of an inode before it is written, the size of the inode
+// is extended to the end of the range unless KeepSize is set.
+func (mp *metaPartition) ExtentsPreAlloc(req *ExtentsPreAllocReq, p *Packet, remoteAddr string) (err error) {
+	if !proto.IsHot(mp.volType) {
+		err = fmt.Errorf("only support hot vol")
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	fileSize := uint64(0)
+	start := time.Now()
+	if mp.IsEnableAuditLog() {
+		defer func() {
+			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, fileSize)
+		}()
+	}
+	if req.Size == 0 || req.Offset+req.Size < req.Offset {
+		err = fmt.Errorf("invalid range offset(%v) size(%v)", req.Offset, req.Size)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		err = fmt.Errorf("inode[%v] is not exist", req.Inode)
+		p.PacketErrorWithBody(proto.OpNotExistErr, []byte(err.Error()))
+		return
+	}
+	i := item.(*Inode)
+	if proto.IsDir(i.Type) || !proto.IsStorageClassReplica(i.StorageClass) {
+		err = fmt.Errorf("inode %v type(%v) storageClass(%v) do not support preAlloc operation", req.Inode, i.Type, i.StorageClass)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+
+	ino := NewInode(req.Inode, i.Type)
+	ino.Size = i.Size
+	if end := req.Offset + req.Size; !req.KeepSize && end > ino.Size {
+		ino.Size = end
+	}
+	if status := mp.isOverQuota(req.Inode, ino.Size > i.Size, false); status != 0 {
+		err = errors.New("ExtentsPreAlloc is over quota")
+		p.PacketErrorWithBody(status, []byte(err.Error()))
+		return
+	}
+	fileSize = ino.Size
+	ino.StorageClass = i.StorageClass
+	val, err := ino.Marshal()
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMExtentsPreAlloc, val)
+	if err != nil {
+		log.LogErrorf("[ExtentsPreAlloc] mpId(%v) ino(%v) submit fsm return err: %v",
+			mp.config.PartitionId, req.Inode, err)
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	msg := resp.(*InodeResponse)
+	p.PacketErrorWithBody(msg.Status, nil)
+	return
+}
+
 func (mp *metaPartition) BatchExtentAppend(req *proto.AppendExtentKeysRequest, p *Packet) (err error) {
 	if !proto.IsHot(mp.volType) {
 		err = fmt.Errorf("only support hot vol")
@@ -632,8 +718,8 @@ func (mp *metaPartition) BatchExtentAppend(req *proto.AppendExtentKeysRequest, p
 		return
 	}
 
-	var ino *Inode
-	if ino, _, err = mp.CheckQuota(req.Inode, p); err != nil {
+	var ino, inode *Inode
+	if ino, inode, err = mp.CheckQuota(req.Inode, p); err != nil {
 		log.LogErrorf("BatchExtentAppend fail err [%v]", err)
 		return
 	}
@@ -651,16 +737,23 @@ func (mp *metaPartition) BatchExtentAppend(req *proto.AppendExtentKeysRequest, p
 	}
 
 	extents := req.Extents
+	if err = mp.checkExtentKeys(inode, extents...); err != nil {
+		log.LogWarnf("BatchExtentAppend mp(%v) %v", mp.config.PartitionId, err)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
 	ino.HybridCloudExtents.sortedEks = NewSortedExtents()
 	for _, extent := range extents {
 		ino.HybridCloudExtents.sortedEks.(*SortedExtents).Append(extent)
 	}
+	// the writer is not known to be based on the current extents
+	ino.Generation = 0
 	val, err := ino.Marshal()
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	resp, err := mp.submit(opFSMExtentsAdd, val)
+	resp, err := mp.submit(mp.extentsAddOp(), val)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return
@@ -771,7 +864,7 @@ func (mp *metaPartition) sendExtentsToChan(eks []proto.ExtentKey) (err error) {
 }
 
 func (mp *metaPartition) persistInodeAccessTime(inode uint64, p *Packet) {
-	if !mp.enablePersistAccessTime {
+	if !mp.GetVolConfig().EnablePersistAccessTime {
 		return
 	}
 
@@ -785,11 +878,10 @@ func (mp *metaPartition) persistInodeAccessTime(inode uint64, p *Packet) {
 	ino := item.(*Inode)
 	ctime := timeutil.GetCurrentTimeUnix()
 	atime := ino.AccessTime
-	interval := mp.GetAccessTimeValidInterval()
 
-	if ctime <= atime || ctime-atime < int64(interval) {
-		log.LogDebugf("persistInodeAccessTime: no need to persit atime, ino %d, ctime %d, atime %d, interval %d",
-			inode, ctime, atime, interval)
+	if !mp.needUpdateAccessTime(ino, ctime) {
+		log.LogDebugf("persistInodeAccessTime: no need to persit atime, ino %d, ctime %d, atime %d, mode %v",
+			inode, ctime, atime, mp.GetAtimeMode())
 		return
 	}
 
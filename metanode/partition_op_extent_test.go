@@ -0,0 +1,75 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtentsPreAlloc(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForTest(mockCtrl)
+	dir := NewInode(1, DirModeType)
+	dir.StorageClass = proto.StorageClass_Replica_SSD
+	mp.inodeTree.ReplaceOrInsert(dir, true)
+	file := NewInode(2, FileModeType)
+	file.StorageClass = proto.StorageClass_Replica_SSD
+	file.Size = 100
+	file.HybridCloudExtents.sortedEks = NewSortedExtents()
+	mp.inodeTree.ReplaceOrInsert(file, true)
+
+	preAlloc := func(ino, offset, size uint64, keepSize bool) uint8 {
+		p := &Packet{}
+		req := &ExtentsPreAllocReq{Inode: ino, Offset: offset, Size: size, KeepSize: keepSize}
+		mp.ExtentsPreAlloc(req, p, "")
+		return p.ResultCode
+	}
+	getInode := func(ino uint64) *Inode {
+		return mp.inodeTree.Get(NewInode(ino, 0)).(*Inode)
+	}
+
+	require.Equal(t, proto.OpOk, preAlloc(2, 0, 4096, true))
+	require.True(t, getInode(2).IsPreAllocated())
+	require.EqualValues(t, 100, getInode(2).Size)
+
+	gen := getInode(2).Generation
+	require.Equal(t, proto.OpOk, preAlloc(2, 1024, 4096, false))
+	require.EqualValues(t, 5120, getInode(2).Size)
+	require.Equal(t, gen+1, getInode(2).Generation)
+
+	// a range under the size never shrinks the inode
+	require.Equal(t, proto.OpOk, preAlloc(2, 0, 10, false))
+	require.EqualValues(t, 5120, getInode(2).Size)
+
+	info := &proto.InodeInfo{}
+	require.True(t, replyInfo(info, getInode(2), nil))
+	require.True(t, info.PreAllocated)
+
+	require.Equal(t, proto.OpArgMismatchErr, preAlloc(1, 0, 4096, false))
+	require.Equal(t, proto.OpArgMismatchErr, preAlloc(2, 0, 0, false))
+	require.Equal(t, proto.OpNotExistErr, preAlloc(3, 0, 4096, false))
+
+	// truncate drops the reservation
+	p := &Packet{}
+	require.NoError(t, mp.ExtentsTruncate(&ExtentsTruncateReq{Inode: 2, Size: 8192}, p, ""))
+	require.Equal(t, proto.OpOk, p.ResultCode)
+	require.False(t, getInode(2).IsPreAllocated())
+	require.EqualValues(t, 8192, getInode(2).Size)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/auditlog"
 	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
 	"github.com/cubefs/cubefs/util/timeutil"
 )
@@ -51,6 +52,7 @@ func replyInfoNoCheck(info *proto.InodeInfo, ino *Inode) bool {
 	info.MigrationStorageClass = ino.HybridCloudExtentsMigration.storageClass
 	info.LeaseExpireTime = ino.LeaseExpireTime
 	info.ForbiddenLc = ino.LeaseNotExpire()
+	info.PreAllocated = ino.Flag&InodePreAllocFlag == InodePreAllocFlag
 	return true
 }
 
@@ -79,6 +81,7 @@ func replyInfo(info *proto.InodeInfo, ino *Inode, quotaInfos map[uint32]*proto.M
 	info.StorageClass = ino.StorageClass
 	info.LeaseExpireTime = ino.LeaseExpireTime
 	info.ForbiddenLc = ino.LeaseNotExpire()
+	info.PreAllocated = ino.Flag&InodePreAllocFlag == InodePreAllocFlag
 
 	info.MigrationStorageClass = ino.HybridCloudExtentsMigration.storageClass
 	if ino.HybridCloudExtentsMigration.sortedEks != nil {
@@ -171,14 +174,15 @@ func (mp *metaPartition) CreateInode(req *CreateInoReq, p *Packet, remoteAddr st
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), inoID, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, inoID, 0, "", err)
+	}()
 	if requiredStorageClass, err = mp.checkCreateInoStorageClassForCompatibility(req.StorageType, inoID); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		log.LogErrorf("[CreateInode] %v, req(%+v)", err.Error(), req)
 		return
 	}
-	inoID, err = mp.nextInodeID()
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpInodeFullErr, []byte(err.Error()))
+	if inoID, err = mp.allocInodeID(p); err != nil {
 		return
 	}
 	ino := NewInode(inoID, req.Mode)
@@ -202,7 +206,7 @@ func (mp *metaPartition) CreateInode(req *CreateInoReq, p *Packet, remoteAddr st
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return err
 	}
-	resp, err = mp.submit(opFSMCreateInode, val)
+	resp, err = mp.submitCreateInode(opFSMCreateInode, val, ino, nil)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return err
@@ -241,15 +245,16 @@ func (mp *metaPartition) QuotaCreateInode(req *proto.QuotaCreateInodeRequest, p
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), inoID, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, inoID, 0, "", err)
+	}()
 	if requiredStorageClass, err = mp.checkCreateInoStorageClassForCompatibility(req.StorageType, inoID); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		log.LogErrorf("[QuotaCreateInode] %v, req(%+v)", err.Error(), req)
 		return
 	}
 
-	inoID, err = mp.nextInodeID()
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpInodeFullErr, []byte(err.Error()))
+	if inoID, err = mp.allocInodeID(p); err != nil {
 		return
 	}
 	ino := NewInode(inoID, req.Mode)
@@ -276,7 +281,7 @@ func (mp *metaPartition) QuotaCreateInode(req *proto.QuotaCreateInodeRequest, p
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return err
 	}
-	resp, err = mp.submit(opFSMCreateInodeQuota, val)
+	resp, err = mp.submitCreateInode(opFSMCreateInodeQuota, val, ino, req.QuotaIds)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return err
@@ -313,6 +318,9 @@ func (mp *metaPartition) TxUnlinkInode(req *proto.TxUnlinkInodeRequest, p *Packe
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, 0, "", err)
+	}()
 	txInfo := req.TxInfo.GetCopy()
 	var status uint8
 	var respIno *Inode
@@ -357,7 +365,7 @@ func (mp *metaPartition) TxUnlinkInode(req *proto.TxUnlinkInodeRequest, p *Packe
 
 	respIno = inoResp.Msg
 	createTime := respIno.CreateTime
-	deleteLockTime := mp.vol.volDeleteLockTime * 60 * 60
+	deleteLockTime := mp.GetVolConfig().DeleteLockTime * 60 * 60
 	if deleteLockTime > 0 && createTime+deleteLockTime > time.Now().Unix() {
 		err = fmt.Errorf("the current Inode[%v] is still locked for deletion", req.Inode)
 		log.LogDebugf("TxUnlinkInode: the current Inode is still locked for deletion, inode[%v] createTime(%v) mw.volDeleteLockTime(%v) now(%v)", respIno.Inode, createTime, deleteLockTime, time.Now())
@@ -405,6 +413,9 @@ func (mp *metaPartition) UnlinkInode(req *UnlinkInoReq, p *Packet, remoteAddr st
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, 0, "", err)
+	}()
 	makeRspFunc := func() {
 		status := msg.Status
 		if status == proto.OpOk {
@@ -476,6 +487,11 @@ func (mp *metaPartition) UnlinkInodeBatch(req *BatchUnlinkInoReq, p *Packet, rem
 			}()
 		}
 	}
+	defer func() {
+		for _, ino := range req.Inodes {
+			mp.auditOp(remoteAddr, p, ino, 0, "", err)
+		}
+	}()
 
 	val, err := inodes.Marshal()
 	if err != nil {
@@ -614,6 +630,9 @@ func (mp *metaPartition) InodeGet(req *InodeGetReq, p *Packet) (err error) {
 			}
 		}
 
+		if proto.IsDir(ino.Type) {
+			resp.Info.DirStat = mp.replyDirStat(ino.Inode)
+		}
 		status = proto.OpOk
 		if getAllVerInfo {
 			inode := mp.getInodeTopLayer(ino)
@@ -654,6 +673,9 @@ func (mp *metaPartition) InodeGetBatch(req *InodeGetReqBatch, p *Packet) (err er
 		if retMsg.Status == proto.OpOk {
 			inoInfo := &proto.InodeInfo{}
 			if replyInfo(inoInfo, retMsg.Msg, quotaInfos) {
+				if proto.IsDir(inoInfo.Mode) {
+					inoInfo.DirStat = mp.replyDirStat(inoInfo.Inode)
+				}
 				resp.Infos = append(resp.Infos, inoInfo)
 			}
 		}
@@ -667,6 +689,19 @@ func (mp *metaPartition) InodeGetBatch(req *InodeGetReqBatch, p *Packet) (err er
 	return
 }
 
+// checkLinkVolume rejects the link to an inode not of the volume or the partition, the client
+// is linking across volumes, which is not supported.
+func (mp *metaPartition) checkLinkVolume(volName string, ino uint64, p *Packet) (err error) {
+	if (volName == "" || volName == mp.config.VolName) && ino >= mp.config.Start && ino <= mp.config.End {
+		return
+	}
+	err = fmt.Errorf("link to ino(%v) of vol(%v) on mp(%v) of vol(%v) [%v, %v], links across volumes are not supported",
+		ino, volName, mp.config.PartitionId, mp.config.VolName, mp.config.Start, mp.config.End)
+	exporter.NewCounter(MetricCrossVolumeLink).AddWithLabels(1, map[string]string{"volName": mp.config.VolName})
+	p.PacketErrorWithBody(proto.OpCrossVolumeNotSupported, []byte(err.Error()))
+	return
+}
+
 func (mp *metaPartition) TxCreateInodeLink(req *proto.TxLinkInodeRequest, p *Packet, remoteAddr string) (err error) {
 	start := time.Now()
 	if mp.IsEnableAuditLog() {
@@ -674,6 +709,12 @@ func (mp *metaPartition) TxCreateInodeLink(req *proto.TxLinkInodeRequest, p *Pac
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, 0, "", err)
+	}()
+	if err = mp.checkLinkVolume(req.VolName, req.Inode, p); err != nil {
+		return
+	}
 	txInfo := req.TxInfo.GetCopy()
 	ino := NewInode(req.Inode, 0)
 	inoResp := mp.getInode(ino, true)
@@ -728,6 +769,12 @@ func (mp *metaPartition) CreateInodeLink(req *LinkInodeReq, p *Packet, remoteAdd
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, 0, "", err)
+	}()
+	if err = mp.checkLinkVolume(req.VolName, req.Inode, p); err != nil {
+		return
+	}
 	var r interface{}
 	var val []byte
 	if req.UniqID > 0 {
@@ -778,6 +825,9 @@ func (mp *metaPartition) EvictInode(req *EvictInodeReq, p *Packet, remoteAddr st
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, req.Inode, 0, "", err)
+	}()
 	ino := NewInode(req.Inode, 0)
 	if item := mp.inodeTree.Get(ino); item == nil {
 		err = fmt.Errorf("mp %v inode %v reqeust cann't found", mp.config.PartitionId, ino)
@@ -824,6 +874,11 @@ func (mp *metaPartition) EvictInodeBatch(req *BatchEvictInodeReq, p *Packet, rem
 			}()
 		}
 	}
+	defer func() {
+		for _, ino := range req.Inodes {
+			mp.auditOp(remoteAddr, p, ino, 0, "", err)
+		}
+	}()
 
 	val, err := inodes.Marshal()
 	if err != nil {
@@ -948,15 +1003,16 @@ func (mp *metaPartition) TxCreateInode(req *proto.TxCreateInodeRequest, p *Packe
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), inoID, 0)
 		}()
 	}
+	defer func() {
+		mp.auditOp(remoteAddr, p, inoID, 0, "", err)
+	}()
 	if requiredStorageClass, err = mp.checkCreateInoStorageClassForCompatibility(req.StorageType, inoID); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		log.LogErrorf("[QuotaCreateInode] %v, req(%+v)", err.Error(), req)
 		return
 	}
 
-	inoID, err = mp.nextInodeID()
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpInodeFullErr, []byte(err.Error()))
+	if inoID, err = mp.allocInodeID(p); err != nil {
 		return
 	}
 
@@ -1010,7 +1066,7 @@ func (mp *metaPartition) TxCreateInode(req *proto.TxCreateInodeRequest, p *Packe
 			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 			return err
 		}
-		resp, err = mp.submit(opFSMTxCreateInodeQuota, val)
+		resp, err = mp.submitTxCreateInode(opFSMTxCreateInodeQuota, val, txIno, req.QuotaIds)
 		if err != nil {
 			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 			return err
@@ -1021,7 +1077,7 @@ func (mp *metaPartition) TxCreateInode(req *proto.TxCreateInodeRequest, p *Packe
 			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 			return err
 		}
-		resp, err = mp.submit(opFSMTxCreateInode, val)
+		resp, err = mp.submitTxCreateInode(opFSMTxCreateInode, val, txIno, nil)
 		if err != nil {
 			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 			return err
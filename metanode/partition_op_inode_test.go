@@ -75,3 +75,41 @@ func TestInodeGetPerf(t *testing.T) {
 		t.Logf("TestInodeGetPerf: cnt %d, cost %dus", testNum, time.Since(start).Microseconds())
 	}
 }
+
+func TestAllocInodeID(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.config.End = 100
+	mp.config.Cursor = 99
+
+	p := &Packet{}
+	ino, err := mp.allocInodeID(p)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, ino)
+
+	_, err = mp.allocInodeID(p)
+	require.ErrorIs(t, err, ErrInodeIDOutOfRange)
+	require.Equal(t, proto.OpCursorExhausted, p.ResultCode)
+
+	mp.config.Cursor = 0
+	mp.SetMemFrozen(true)
+	p = &Packet{}
+	_, err = mp.allocInodeID(p)
+	require.ErrorIs(t, err, ErrMemFrozen)
+	require.Equal(t, proto.OpPartitionFrozen, p.ResultCode)
+	require.Zero(t, mp.config.Cursor)
+}
+
+func TestCreateInodeLinkCrossVolume(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.config.Start = 1
+	mp.config.End = 100
+	link := func(vol string, ino uint64) *Packet {
+		p := &Packet{}
+		req := &LinkInodeReq{VolName: vol, PartitionID: mp.config.PartitionId, Inode: ino}
+		require.Error(t, mp.CreateInodeLink(req, p, ""))
+		return p
+	}
+	require.Equal(t, proto.OpCrossVolumeNotSupported, link("otherVol", 10).ResultCode)
+	require.Equal(t, proto.OpCrossVolumeNotSupported, link(mp.config.VolName, 200).ResultCode)
+	require.Contains(t, string(link("otherVol", 10).Data), "across volumes")
+}
@@ -193,27 +193,39 @@ func (mp *metaPartition) CreateMultipart(req *proto.CreateMultipartRequest, p *P
 	return
 }
 
-func (mp *metaPartition) ListMultipart(req *proto.ListMultipartRequest, p *Packet) (err error) {
-	max := int(req.Max)
-	keyMarker := req.Marker
-	multipartIdMarker := req.MultipartIdMarker
-	prefix := req.Prefix
-	matches := make([]*Multipart, 0, max)
+// listMultipart returns at most max sessions after the marker whose keys have the prefix.
+// The sessions are ordered by key and id, so the walk starts from the greater one of the
+// marker and the prefix and stops at the first key without the prefix. The marker itself
+// is excluded: all sessions of keyMarker if multipartIdMarker is empty, otherwise the
+// sessions of keyMarker whose ids are not greater than multipartIdMarker.
+func (mp *metaPartition) listMultipart(prefix, keyMarker, multipartIdMarker string, max int) (matches []*Multipart) {
+	matches = make([]*Multipart, 0, max)
 	walkTreeFunc := func(i BtreeItem) bool {
 		multipart := i.(*Multipart)
-		// prefix is enabled
 		if len(prefix) > 0 && !strings.HasPrefix(multipart.key, prefix) {
-			// skip and continue
+			// no more keys with the prefix
+			return false
+		}
+		if len(keyMarker) > 0 && multipart.key == keyMarker &&
+			(multipartIdMarker == "" || multipart.id <= multipartIdMarker) {
 			return true
 		}
 		matches = append(matches, multipart)
 		return !(len(matches) >= max)
 	}
-	if len(keyMarker) > 0 {
+	switch {
+	case len(prefix) > 0 && prefix > keyMarker:
+		mp.multipartTree.AscendGreaterOrEqual(&Multipart{key: prefix}, walkTreeFunc)
+	case len(keyMarker) > 0:
 		mp.multipartTree.AscendGreaterOrEqual(&Multipart{key: keyMarker, id: multipartIdMarker}, walkTreeFunc)
-	} else {
+	default:
 		mp.multipartTree.Ascend(walkTreeFunc)
 	}
+	return
+}
+
+func (mp *metaPartition) ListMultipart(req *proto.ListMultipartRequest, p *Packet) (err error) {
+	matches := mp.listMultipart(req.Prefix, req.Marker, req.MultipartIdMarker, int(req.Max))
 	multipartInfos := make([]*proto.MultipartInfo, len(matches))
 
 	convertPartFunc := func(part *Part) *proto.MultipartPartInfo {
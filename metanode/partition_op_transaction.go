@@ -188,6 +188,9 @@ func (mp *metaPartition) TxCommitRM(req *proto.TxApplyRMRequest, p *Packet) erro
 	}
 
 	p.ResultCode = status.(uint8)
+	if p.ResultCode == proto.OpOk {
+		mp.invalidateAttrLeases(txAttrLeaseInodes(ifo, mp.config.PartitionId))
+	}
 	return nil
 }
 
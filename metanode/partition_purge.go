@@ -0,0 +1,90 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/atomicutil"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// purgeCtrl controls the purge of the inodes deleted by the deleteWorker of the partition. The
+// vol is controlled by master through the heartbeats and the partition by the admin of the
+// metanode, the purge is paused if either is.
+type purgeCtrl struct {
+	volPaused atomicutil.Bool
+	paused    atomicutil.Bool
+	forced    atomicutil.Bool // until the free list is empty
+	forceSeq  uint64          // the ForceSeq of the vol seen last, set by the heartbeats only
+	seqSeen   bool
+}
+
+// SetVolPurgeCtrl applies the purge control of the vol told by master, a purge is forced once
+// the ForceSeq grows after the partition has seen it.
+func (mp *metaPartition) SetVolPurgeCtrl(ctrl *proto.VolPurgeCtrl) {
+	c := &mp.purge
+	if ctrl == nil {
+		ctrl = &proto.VolPurgeCtrl{}
+	}
+	if c.volPaused.Swap(ctrl.Paused) != ctrl.Paused {
+		log.LogWarnf("[SetVolPurgeCtrl] vol(%v) mp(%v) purge paused(%v)", mp.config.VolName, mp.config.PartitionId, ctrl.Paused)
+	}
+	if c.seqSeen && ctrl.ForceSeq > c.forceSeq {
+		log.LogWarnf("[SetVolPurgeCtrl] vol(%v) mp(%v) purge forced(%v)", mp.config.VolName, mp.config.PartitionId, ctrl.ForceSeq)
+		c.forced.Store(true)
+	}
+	c.forceSeq = ctrl.ForceSeq
+	c.seqSeen = true
+}
+
+// SetPurge pauses, resumes or forces the purge of the partition by proto.PurgeOpPause and the
+// others, the control is not kept across restarts.
+func (mp *metaPartition) SetPurge(op string) (err error) {
+	c := &mp.purge
+	switch op {
+	case proto.PurgeOpPause:
+		c.paused.Store(true)
+	case proto.PurgeOpResume:
+		c.paused.Store(false)
+	case proto.PurgeOpForce:
+		c.paused.Store(false)
+		c.forced.Store(true)
+	default:
+		return fmt.Errorf("unknown purge op %v", op)
+	}
+	log.LogWarnf("[SetPurge] vol(%v) mp(%v) purge %v", mp.config.VolName, mp.config.PartitionId, op)
+	return
+}
+
+func (mp *metaPartition) purgePaused() bool {
+	return mp.purge.paused.Load() || mp.purge.volPaused.Load()
+}
+
+// GetPurgeStatus returns the purge of the partition, the bytes pending are of the accounting
+// collected last time.
+func (mp *metaPartition) GetPurgeStatus() *proto.MetaPartitionPurgeStatus {
+	status := &proto.MetaPartitionPurgeStatus{
+		PartitionID:  mp.config.PartitionId,
+		Paused:       mp.purgePaused(),
+		Forced:       mp.purge.forced.Load(),
+		BacklogCount: uint64(mp.freeList.Len()),
+	}
+	if stat := mp.stat; stat != nil {
+		status.BacklogBytes = stat.DelInodeSize
+	}
+	return status
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeCtrl(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.freeList.Push(10)
+	mp.freeList.Push(11)
+	mp.stat = &proto.MetaPartitionStat{DelInodeCount: 2, DelInodeSize: 4096}
+
+	status := mp.GetPurgeStatus()
+	require.False(t, status.Paused)
+	require.EqualValues(t, 2, status.BacklogCount)
+	require.EqualValues(t, 4096, status.BacklogBytes)
+
+	// the force seq seen first does not force a purge
+	mp.SetVolPurgeCtrl(&proto.VolPurgeCtrl{Paused: true, ForceSeq: 3})
+	require.True(t, mp.purgePaused())
+	require.False(t, mp.purge.forced.Load())
+	mp.SetVolPurgeCtrl(&proto.VolPurgeCtrl{ForceSeq: 4})
+	require.False(t, mp.purgePaused())
+	require.True(t, mp.purge.forced.Load())
+	mp.purge.forced.Store(false)
+	mp.SetVolPurgeCtrl(nil)
+	require.False(t, mp.purge.forced.Load())
+
+	require.NoError(t, mp.SetPurge(proto.PurgeOpPause))
+	require.True(t, mp.GetPurgeStatus().Paused)
+	// either the vol or the partition pauses the purge
+	mp.SetVolPurgeCtrl(&proto.VolPurgeCtrl{Paused: true})
+	require.NoError(t, mp.SetPurge(proto.PurgeOpResume))
+	require.True(t, mp.purgePaused())
+	mp.SetVolPurgeCtrl(nil)
+	require.False(t, mp.purgePaused())
+
+	require.NoError(t, mp.SetPurge(proto.PurgeOpForce))
+	require.True(t, mp.GetPurgeStatus().Forced)
+	require.Error(t, mp.SetPurge("unknown"))
+}
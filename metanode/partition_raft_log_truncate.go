@@ -0,0 +1,123 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the raft truncates its log asynchronously, the reclaimed space is measured once the first
+// index of the log moves or the wait is over
+var (
+	raftLogTruncateWait     = 3 * time.Second
+	raftLogTruncateInterval = 100 * time.Millisecond
+)
+
+// TruncateRaftLog truncates the raft log of the leader up to req.Index for the operator once
+// the log has grown huge. The index must be stored in the snapshot on disk and applied by all
+// the replicas, no replica needs the log before it then, so the log kept for a follower
+// resuming a snapshot is dropped too. The raft still keeps its retained logs.
+func (mp *metaPartition) TruncateRaftLog(req *proto.MetaRaftLogTruncateRequest) (resp *proto.MetaRaftLogTruncateResponse, err error) {
+	if mp.raftPartition == nil {
+		return nil, fmt.Errorf("mp %v has no raft", mp.config.PartitionId)
+	}
+	if _, ok := mp.IsLeader(); !ok {
+		return nil, fmt.Errorf("mp %v is not the leader", mp.config.PartitionId)
+	}
+	if req.Index == 0 {
+		return nil, fmt.Errorf("invalid index %v", req.Index)
+	}
+	if req.Index > mp.storedApplyId {
+		return nil, fmt.Errorf("index %v is beyond the snapshot stored at %v", req.Index, mp.storedApplyId)
+	}
+
+	resp = &proto.MetaRaftLogTruncateResponse{
+		PartitionID: mp.config.PartitionId,
+		Index:       req.Index,
+		AppliedIDs:  make(map[string]uint64),
+	}
+	for _, peer := range mp.config.Peers {
+		var applied uint64
+		if peer.ID == mp.config.NodeId {
+			applied = mp.GetAppliedID()
+		} else if applied, err = mp.getPeerAppliedID(peer.Addr); err != nil {
+			return nil, fmt.Errorf("get applied id of peer %v: %v", peer.Addr, err)
+		}
+		resp.AppliedIDs[peer.Addr] = applied
+		if applied < req.Index {
+			return nil, fmt.Errorf("peer %v has applied %v only", peer.Addr, applied)
+		}
+	}
+
+	walPath := mp.raftPartition.WalPath()
+	walSize := dirSize(walPath)
+	first := mp.raftPartition.FirstIndex()
+	if req.Index > atomic.LoadUint64(&mp.truncatedIndex) {
+		atomic.StoreUint64(&mp.truncatedIndex, req.Index)
+	}
+	log.LogWarnf("[TruncateRaftLog] mp(%v) truncate raft log from %v to %v, applied %v, wal size %v",
+		mp.config.PartitionId, first, req.Index, resp.AppliedIDs, walSize)
+	mp.raftPartition.Truncate(req.Index)
+
+	for start := time.Now(); time.Since(start) < raftLogTruncateWait; {
+		if resp.FirstIndex = mp.raftPartition.FirstIndex(); resp.FirstIndex > first {
+			break
+		}
+		time.Sleep(raftLogTruncateInterval)
+	}
+	resp.WalSize = dirSize(walPath)
+	resp.ReclaimedBytes = walSize - resp.WalSize
+	log.LogWarnf("[TruncateRaftLog] mp(%v) raft log first index %v, wal size %v, reclaimed %v",
+		mp.config.PartitionId, resp.FirstIndex, resp.WalSize, resp.ReclaimedBytes)
+	return
+}
+
+func (mp *metaPartition) getPeerAppliedID(addr string) (applied uint64, err error) {
+	if mp.config.ConnPool == nil {
+		return 0, fmt.Errorf("no connection pool")
+	}
+	p := proto.NewPacketReqID()
+	p.Opcode = proto.OpMetaGetAppliedID
+	p.PartitionID = mp.config.PartitionId
+	if err = p.MarshalData(&proto.GetAppliedIDRequest{PartitionId: mp.config.PartitionId}); err != nil {
+		return
+	}
+	conn, err := mp.config.ConnPool.GetConnect(addr)
+	if err != nil {
+		return
+	}
+	defer func() {
+		mp.config.ConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		return 0, fmt.Errorf("%v", p.GetResultMsg())
+	}
+	if len(p.Data) < 8 {
+		return 0, fmt.Errorf("invalid applied id of %v bytes", len(p.Data))
+	}
+	return binary.BigEndian.Uint64(p.Data), nil
+}
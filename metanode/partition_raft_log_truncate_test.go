@@ -0,0 +1,76 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	raftstoremock "github.com/cubefs/cubefs/util/mocktest/raftstore"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateRaftLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walPath := t.TempDir()
+	segment := path.Join(walPath, "0000000000000001-0000000000000001.log")
+	require.NoError(t, os.WriteFile(segment, make([]byte, 4096), 0o644))
+	require.NoError(t, os.WriteFile(path.Join(walPath, "0000000000000002-0000000000000101.log"), make([]byte, 1024), 0o644))
+
+	mp := NewMetaPartitionForTest()
+	mp.config.NodeId = 1
+	mp.config.Peers = []proto.Peer{{ID: 1, Addr: "127.0.0.1:17210"}}
+	mp.applyID = 200
+	mp.storedApplyId = 150
+
+	leader, first := uint64(2), uint64(1)
+	raft := raftstoremock.NewMockPartition(ctrl)
+	raft.EXPECT().LeaderTerm().DoAndReturn(func() (uint64, uint64) { return leader, 1 }).AnyTimes()
+	raft.EXPECT().WalPath().Return(walPath).AnyTimes()
+	raft.EXPECT().FirstIndex().DoAndReturn(func() uint64 { return first }).AnyTimes()
+	raft.EXPECT().Truncate(uint64(100)).Do(func(index uint64) {
+		require.NoError(t, os.Remove(segment))
+		first = index + 1
+	}).Times(1)
+	mp.raftPartition = raft
+
+	_, err := mp.TruncateRaftLog(&proto.MetaRaftLogTruncateRequest{Index: 100})
+	require.ErrorContains(t, err, "not the leader")
+
+	leader = 1
+	_, err = mp.TruncateRaftLog(&proto.MetaRaftLogTruncateRequest{Index: 0})
+	require.Error(t, err)
+	_, err = mp.TruncateRaftLog(&proto.MetaRaftLogTruncateRequest{Index: 160})
+	require.ErrorContains(t, err, "beyond the snapshot")
+
+	// a peer whose applied id is unknown refuses the truncation
+	mp.config.Peers = append(mp.config.Peers, proto.Peer{ID: 2, Addr: "127.0.0.1:17211"})
+	_, err = mp.TruncateRaftLog(&proto.MetaRaftLogTruncateRequest{Index: 100})
+	require.ErrorContains(t, err, "127.0.0.1:17211")
+	mp.config.Peers = mp.config.Peers[:1]
+
+	resp, err := mp.TruncateRaftLog(&proto.MetaRaftLogTruncateRequest{Index: 100})
+	require.NoError(t, err)
+	require.EqualValues(t, 101, resp.FirstIndex)
+	require.EqualValues(t, 200, resp.AppliedIDs["127.0.0.1:17210"])
+	require.EqualValues(t, 1024, resp.WalSize)
+	require.EqualValues(t, 4096, resp.ReclaimedBytes)
+	require.EqualValues(t, 100, mp.truncatedIndex)
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	MetricProposalWait = "mpProposalWait"
+	MetricApply        = "mpApply"
+	MetricStore        = "mpStore"
+
+	defaultSlowProposalThreshold = time.Second
+)
+
+// proposalStat records how long the requests of a meta partition spend in raft.
+// wait is the time from proposing a command until it is applied, which contains
+// queuing, replication and applying, apply is the time of applying a command to
+// the in-memory trees and store is the time of dumping a snapshot to disk.
+type proposalStat struct {
+	pending   int64
+	lastWait  int64 // nanoseconds
+	lastApply int64 // nanoseconds
+	lastStore int64 // nanoseconds
+	proposals uint64
+
+	// the proposals counted at the last rate taken, by the heartbeats only
+	rateProposals uint64
+	rateTime      time.Time
+}
+
+// ProposalStatInfo is the view of proposalStat.
+type ProposalStatInfo struct {
+	Pending   int64         `json:"pending"`
+	LastWait  time.Duration `json:"lastWait"`
+	LastApply time.Duration `json:"lastApply"`
+	LastStore time.Duration `json:"lastStore"`
+}
+
+func (s *proposalStat) info() *ProposalStatInfo {
+	return &ProposalStatInfo{
+		Pending:   atomic.LoadInt64(&s.pending),
+		LastWait:  time.Duration(atomic.LoadInt64(&s.lastWait)),
+		LastApply: time.Duration(atomic.LoadInt64(&s.lastApply)),
+		LastStore: time.Duration(atomic.LoadInt64(&s.lastStore)),
+	}
+}
+
+func (mp *metaPartition) proposalLabels() map[string]string {
+	return map[string]string{exporter.Vol: mp.config.VolName}
+}
+
+// beginProposal must be paired with endProposal.
+func (mp *metaPartition) beginProposal() *exporter.TimePoint {
+	atomic.AddInt64(&mp.proposalStat.pending, 1)
+	atomic.AddUint64(&mp.proposalStat.proposals, 1)
+	return exporter.NewTP(MetricProposalWait)
+}
+
+func (mp *metaPartition) endProposal(tp *exporter.TimePoint, op uint32, err error) {
+	pending := atomic.AddInt64(&mp.proposalStat.pending, -1)
+	cost := time.Since(tp.GetStartTime())
+	atomic.StoreInt64(&mp.proposalStat.lastWait, int64(cost))
+	tp.SetWithLabels(mp.proposalLabels())
+	if cost < defaultSlowProposalThreshold {
+		return
+	}
+	log.LogWarnf("[slowProposal] mp(%v) vol(%v) op(%v) wait(%v) pending(%v) lastApply(%v) lastStore(%v) "+
+		"applyID(%v) err(%v)", mp.config.PartitionId, mp.config.VolName, op, cost, pending,
+		time.Duration(atomic.LoadInt64(&mp.proposalStat.lastApply)),
+		time.Duration(atomic.LoadInt64(&mp.proposalStat.lastStore)), mp.getApplyID(), err)
+}
+
+func (mp *metaPartition) recordApply(tp *exporter.TimePoint) {
+	atomic.StoreInt64(&mp.proposalStat.lastApply, int64(time.Since(tp.GetStartTime())))
+	tp.SetWithLabels(mp.proposalLabels())
+}
+
+func (mp *metaPartition) recordStore(tp *exporter.TimePoint) {
+	atomic.StoreInt64(&mp.proposalStat.lastStore, int64(time.Since(tp.GetStartTime())))
+	tp.SetWithLabels(mp.proposalLabels())
+}
+
+// GetProposalStat returns the raft proposal statistics of the partition.
+func (mp *metaPartition) GetProposalStat() *ProposalStatInfo {
+	return mp.proposalStat.info()
+}
+
+// takeProposalRate returns the proposals per second since it was taken last time, the heat of
+// the partition master splits it by. It is taken by the heartbeats only.
+func (mp *metaPartition) takeProposalRate() (rate uint64) {
+	s := &mp.proposalStat
+	now := time.Now()
+	proposals := atomic.LoadUint64(&s.proposals)
+	if !s.rateTime.IsZero() {
+		if elapsed := now.Sub(s.rateTime).Seconds(); elapsed > 0 {
+			rate = uint64(float64(proposals-s.rateProposals) / elapsed)
+		}
+	}
+	s.rateProposals, s.rateTime = proposals, now
+	return
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposalStat(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+
+	tp1 := mp.beginProposal()
+	tp2 := mp.beginProposal()
+	require.EqualValues(t, 2, mp.GetProposalStat().Pending)
+
+	time.Sleep(time.Millisecond)
+	mp.endProposal(tp1, opFSMCreateInode, nil)
+	info := mp.GetProposalStat()
+	require.EqualValues(t, 1, info.Pending)
+	require.True(t, info.LastWait >= time.Millisecond)
+
+	mp.endProposal(tp2, opFSMCreateInode, nil)
+	require.EqualValues(t, 0, mp.GetProposalStat().Pending)
+
+	mp.recordApply(exporter.NewTP(MetricApply))
+	mp.recordStore(exporter.NewTP(MetricStore))
+	info = mp.GetProposalStat()
+	require.True(t, info.LastApply > 0)
+	require.True(t, info.LastStore > 0)
+}
+
+func TestProposalRate(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1, VolName: "vol"}}
+	require.Zero(t, mp.takeProposalRate())
+	for i := 0; i < 10; i++ {
+		mp.endProposal(mp.beginProposal(), opFSMCreateInode, nil)
+	}
+	time.Sleep(100 * time.Millisecond)
+	rate := mp.takeProposalRate()
+	require.True(t, rate > 0 && rate <= 100, "rate %v", rate)
+	time.Sleep(10 * time.Millisecond)
+	require.Zero(t, mp.takeProposalRate())
+}
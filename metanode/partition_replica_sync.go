@@ -0,0 +1,528 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	replicaSyncBatchSize   = 1 << 20 // bytes of snapshot items in a raft log entry of the sync
+	replicaSyncReadTimeout = 60      // seconds
+
+	replicaStagingFile = "replica_staging" // the staging in the snapshot dir, absent if no sync is running
+)
+
+var ErrBadSnapshotFrame = errors.New("bad snapshot frame")
+
+type replicaSyncBegin struct {
+	ID                uint64 `json:"id"`
+	SourceVol         string `json:"source_vol"`
+	SourcePartitionID uint64 `json:"source_pid"`
+}
+
+type replicaSyncEnd struct {
+	ID            uint64 `json:"id"`
+	SourceApplyID uint64 `json:"source_apply_id"`
+	Time          int64  `json:"time"`
+}
+
+// replicaSyncStaging holds the trees built by a sync of a replica partition until the sync ends,
+// then they replace the trees of the partition at once. It's only touched by the apply, and is
+// stored and sent in the snapshots of the partition like the trees, for a replica restarting or
+// applying a snapshot in the middle of a sync to end it the same as the others.
+type replicaSyncStaging struct {
+	id                uint64
+	sourcePartitionID uint64
+	cursor            uint64
+	inodeTree         *BTree
+	dentryTree        *BTree
+	extendTree        *BTree
+	multipartTree     *BTree
+}
+
+type replicaSyncStagingHeader struct {
+	ID                uint64 `json:"id"`
+	SourcePartitionID uint64 `json:"source_pid"`
+	Cursor            uint64 `json:"cursor"`
+}
+
+func newReplicaSyncStaging(id, sourcePartitionID, cursor uint64) *replicaSyncStaging {
+	return &replicaSyncStaging{
+		id:                id,
+		sourcePartitionID: sourcePartitionID,
+		cursor:            cursor,
+		inodeTree:         NewBtree(),
+		dentryTree:        NewBtree(),
+		extendTree:        NewBtree(),
+		multipartTree:     NewBtree(),
+	}
+}
+
+// clone returns the staging as it is, the trees are copied on write.
+func (s *replicaSyncStaging) clone() *replicaSyncStaging {
+	if s == nil {
+		return nil
+	}
+	return &replicaSyncStaging{
+		id:                s.id,
+		sourcePartitionID: s.sourcePartitionID,
+		cursor:            s.cursor,
+		inodeTree:         s.inodeTree.GetTree(),
+		dentryTree:        s.dentryTree.GetTree(),
+		extendTree:        s.extendTree.GetTree(),
+		multipartTree:     s.multipartTree.GetTree(),
+	}
+}
+
+// rangeItems passes the header of the staging in an opFSMReplicaSyncStaging item, then the
+// items of the staged trees as the ones of the source snapshot.
+func (s *replicaSyncStaging) rangeItems(f func(item *MetaItem) bool) (err error) {
+	header, err := json.Marshal(&replicaSyncStagingHeader{ID: s.id, SourcePartitionID: s.sourcePartitionID, Cursor: s.cursor})
+	if err != nil {
+		return
+	}
+	if !f(NewMetaItem(opFSMReplicaSyncStaging, nil, header)) {
+		return
+	}
+	next := true
+	s.inodeTree.Ascend(func(i BtreeItem) bool {
+		ino := i.(*Inode)
+		next = f(NewMetaItem(opFSMCreateInode, ino.MarshalKey(), ino.MarshalValue()))
+		return next
+	})
+	if !next {
+		return
+	}
+	s.dentryTree.Ascend(func(i BtreeItem) bool {
+		dentry := i.(*Dentry)
+		next = f(NewMetaItem(opFSMCreateDentry, dentry.MarshalKey(), dentry.MarshalValue()))
+		return next
+	})
+	if !next {
+		return
+	}
+	s.extendTree.Ascend(func(i BtreeItem) bool {
+		var raw []byte
+		if raw, err = i.(*Extend).Bytes(); err != nil {
+			return false
+		}
+		next = f(NewMetaItem(opFSMSetXAttr, nil, raw))
+		return next
+	})
+	if err != nil || !next {
+		return
+	}
+	s.multipartTree.Ascend(func(i BtreeItem) bool {
+		var raw []byte
+		if raw, err = i.(*Multipart).Bytes(); err != nil {
+			return false
+		}
+		return f(NewMetaItem(opFSMCreateMultipart, nil, raw))
+	})
+	return
+}
+
+// restore takes the items of rangeItems, it returns the staging started by the header.
+func (s *replicaSyncStaging) restore(item *MetaItem) (staging *replicaSyncStaging, err error) {
+	if item.Op == opFSMReplicaSyncStaging {
+		header := &replicaSyncStagingHeader{}
+		if err = json.Unmarshal(item.V, header); err != nil {
+			return
+		}
+		return newReplicaSyncStaging(header.ID, header.SourcePartitionID, header.Cursor), nil
+	}
+	if s == nil {
+		return nil, ErrBadSnapshotFrame
+	}
+	return s, s.insert(item)
+}
+
+// insert takes the namespace items of the source snapshot, the others are the states of the
+// source partition itself.
+func (s *replicaSyncStaging) insert(item *MetaItem) (err error) {
+	switch item.Op {
+	case opFSMCreateInode:
+		ino := NewInode(0, 0)
+		if err = ino.UnmarshalKey(item.K); err != nil {
+			return
+		}
+		if err = ino.UnmarshalValue(item.V); err != nil {
+			return
+		}
+		// the extents of an inode being deleted by the source may be freed at any time
+		if ino.ShouldDelete() {
+			return
+		}
+		if s.cursor < ino.Inode {
+			s.cursor = ino.Inode
+		}
+		s.inodeTree.ReplaceOrInsert(ino, true)
+	case opFSMCreateDentry:
+		dentry := &Dentry{}
+		if err = dentry.UnmarshalKey(item.K); err != nil {
+			return
+		}
+		if err = dentry.UnmarshalValue(item.V); err != nil {
+			return
+		}
+		s.dentryTree.ReplaceOrInsert(dentry, true)
+	case opFSMSetXAttr:
+		var extend *Extend
+		if extend, err = NewExtendFromBytes(item.V); err != nil {
+			return
+		}
+		s.extendTree.ReplaceOrInsert(extend, true)
+	case opFSMCreateMultipart:
+		s.multipartTree.ReplaceOrInsert(MultipartFromBytes(item.V), true)
+	}
+	return
+}
+
+func appendSnapshotFrame(buf []byte, data []byte) []byte {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	return append(append(buf, size[:]...), data...)
+}
+
+func rangeSnapshotFrames(buf []byte, f func(item *MetaItem) error) (err error) {
+	for len(buf) > 0 {
+		if len(buf) < 4 {
+			return ErrBadSnapshotFrame
+		}
+		size := binary.BigEndian.Uint32(buf)
+		buf = buf[4:]
+		if uint32(len(buf)) < size {
+			return ErrBadSnapshotFrame
+		}
+		item := NewMetaItem(0, nil, nil)
+		if err = item.UnmarshalBinary(buf[:size]); err != nil {
+			return
+		}
+		buf = buf[size:]
+		if err = f(item); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// IsVolReplica checks whether the partition belongs to a read-only replica volume, whose
+// metadata is synced from the source volume and whose extents belong to the source.
+func (mp *metaPartition) IsVolReplica() bool {
+	if mp.config.SourceVol != "" {
+		return true
+	}
+	if mp.vol == nil {
+		return false
+	}
+	view := mp.vol.GetVolView()
+	return view != nil && view.SourceVol != ""
+}
+
+// ReadSnapshot sends the items of a snapshot of the partition in frames of about
+// replicaSyncBatchSize bytes.
+func (mp *metaPartition) ReadSnapshot(send func(frames []byte) error) (err error) {
+	snap, err := mp.Snapshot()
+	if err != nil {
+		return
+	}
+	defer snap.Close()
+
+	frames := make([]byte, 0, replicaSyncBatchSize)
+	for {
+		var data []byte
+		if data, err = snap.Next(); err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+		if frames = appendSnapshotFrame(frames, data); len(frames) >= replicaSyncBatchSize {
+			if err = send(frames); err != nil {
+				return
+			}
+			frames = frames[:0]
+		}
+	}
+	if len(frames) > 0 {
+		return send(frames)
+	}
+	return nil
+}
+
+func (mp *metaPartition) GetReplicaSyncStatus() *proto.MetaReplicaSyncStatus {
+	status := &proto.MetaReplicaSyncStatus{PartitionID: mp.config.PartitionId}
+	if last, ok := mp.replicaSyncStatus.Load().(*proto.MetaReplicaSyncStatus); ok {
+		*status = *last
+	}
+	status.Syncing = atomic.LoadInt32(&mp.replicaSyncing) == 1
+	return status
+}
+
+// SyncReplica reports the last sync of the partition of a replica volume, and starts a new one
+// in background if req.Start is set and no sync is running.
+func (mp *metaPartition) SyncReplica(req *proto.SyncMetaReplicaRequest) (status *proto.MetaReplicaSyncStatus, err error) {
+	if _, ok := mp.IsLeader(); !ok {
+		return nil, ErrNotALeader
+	}
+	if req.Start && atomic.CompareAndSwapInt32(&mp.replicaSyncing, 0, 1) {
+		go mp.runReplicaSync(req)
+	}
+	return mp.GetReplicaSyncStatus(), nil
+}
+
+func (mp *metaPartition) runReplicaSync(req *proto.SyncMetaReplicaRequest) {
+	defer atomic.StoreInt32(&mp.replicaSyncing, 0)
+	err := fmt.Errorf("no host of source partition %v", req.SourcePartitionID)
+	for _, addr := range req.SourceHosts {
+		if err = mp.syncReplicaFrom(addr, req); err == nil {
+			return
+		}
+		log.LogWarnf("[runReplicaSync] mp(%v) sync from source mp(%v) on %v failed: %v",
+			mp.config.PartitionId, req.SourcePartitionID, addr, err)
+	}
+	status := mp.GetReplicaSyncStatus()
+	status.SourcePartitionID = req.SourcePartitionID
+	status.Syncing = false
+	status.LastError = err.Error()
+	mp.replicaSyncStatus.Store(status)
+}
+
+// syncReplicaFrom replaces the metadata of the partition with a snapshot of the source partition
+// on addr. The snapshot is passed through raft in batches to the staging trees of every replica,
+// which replace the trees of the partition by the end of the sync.
+func (mp *metaPartition) syncReplicaFrom(addr string, req *proto.SyncMetaReplicaRequest) (err error) {
+	begin := &replicaSyncBegin{
+		ID:                uint64(time.Now().UnixNano()),
+		SourceVol:         req.SourceVol,
+		SourcePartitionID: req.SourcePartitionID,
+	}
+	if err = mp.submitReplicaSync(opFSMReplicaSyncBegin, begin); err != nil {
+		return
+	}
+
+	end := &replicaSyncEnd{ID: begin.ID}
+	batch := make([]byte, 8, replicaSyncBatchSize+8)
+	binary.BigEndian.PutUint64(batch, begin.ID)
+	flush := func() (err error) {
+		if len(batch) == 8 {
+			return
+		}
+		err = mp.submitReplicaSync(opFSMReplicaSyncBatch, batch)
+		batch = batch[:8]
+		return
+	}
+	err = mp.fetchSourceSnapshot(addr, req.SourcePartitionID, func(frames []byte) (err error) {
+		if err = rangeSnapshotFrames(frames, func(item *MetaItem) error {
+			if item.Op == opFSMApplyId && len(item.V) >= 8 {
+				end.SourceApplyID = binary.BigEndian.Uint64(item.V)
+			}
+			return nil
+		}); err != nil {
+			return
+		}
+		if batch = append(batch, frames...); len(batch) >= replicaSyncBatchSize {
+			err = flush()
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+	if err = flush(); err != nil {
+		return
+	}
+	end.Time = time.Now().Unix()
+	if err = mp.submitReplicaSync(opFSMReplicaSyncEnd, end); err != nil {
+		return
+	}
+	log.LogInfof("[syncReplicaFrom] mp(%v) synced from source mp(%v) on %v, source applyID(%v)",
+		mp.config.PartitionId, req.SourcePartitionID, addr, end.SourceApplyID)
+	return
+}
+
+func (mp *metaPartition) submitReplicaSync(op uint32, v interface{}) (err error) {
+	data, ok := v.([]byte)
+	if !ok {
+		if data, err = json.Marshal(v); err != nil {
+			return
+		}
+	}
+	resp, err := mp.submit(op, data)
+	if err != nil {
+		return
+	}
+	if status := resp.(uint8); status != proto.OpOk {
+		p := &Packet{}
+		p.ResultCode = status
+		err = errors.NewErrorf("[submitReplicaSync] op(%v): %s", op, p.GetResultMsg())
+	}
+	return
+}
+
+// fetchSourceSnapshot reads the snapshot of the source partition on addr by OpMetaReadSnapshot,
+// which replies the frames in packets and ends with an empty one.
+func (mp *metaPartition) fetchSourceSnapshot(addr string, pid uint64, recv func(frames []byte) error) (err error) {
+	conn, err := mp.config.ConnPool.GetConnect(addr)
+	if err != nil {
+		return
+	}
+	defer mp.config.ConnPool.PutConnect(conn, ForceClosedConnect)
+
+	p := proto.NewPacketReqID()
+	p.Opcode = proto.OpMetaReadSnapshot
+	p.PartitionID = pid
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	for {
+		reply := proto.NewPacket()
+		if err = reply.ReadFromConnWithVer(conn, replicaSyncReadTimeout); err != nil {
+			return
+		}
+		if reply.ResultCode != proto.OpOk {
+			return errors.NewErrorf("read snapshot of mp(%v) on %v: %s", pid, addr, string(reply.Data))
+		}
+		if reply.Size == 0 {
+			return
+		}
+		if err = recv(reply.Data); err != nil {
+			return
+		}
+	}
+}
+
+func (mp *metaPartition) fsmReplicaSyncBegin(begin *replicaSyncBegin) (status uint8) {
+	if mp.config.SourceVol != begin.SourceVol {
+		mp.config.SourceVol = begin.SourceVol
+		if err := mp.PersistMetadata(); err != nil {
+			log.LogErrorf("[fsmReplicaSyncBegin] mp(%v) persist source vol(%v) failed: %v",
+				mp.config.PartitionId, begin.SourceVol, err)
+		}
+	}
+	mp.replicaStaging = newReplicaSyncStaging(begin.ID, begin.SourcePartitionID, mp.config.Start)
+	return proto.OpOk
+}
+
+func (mp *metaPartition) fsmReplicaSyncBatch(data []byte) (status uint8) {
+	staging := mp.replicaStaging
+	if len(data) < 8 || staging == nil || staging.id != binary.BigEndian.Uint64(data) {
+		// replaced by a newer sync
+		return proto.OpArgMismatchErr
+	}
+	if err := rangeSnapshotFrames(data[8:], staging.insert); err != nil {
+		log.LogErrorf("[fsmReplicaSyncBatch] mp(%v) sync(%v) failed: %v", mp.config.PartitionId, staging.id, err)
+		mp.replicaStaging = nil
+		return proto.OpErr
+	}
+	return proto.OpOk
+}
+
+func (mp *metaPartition) fsmReplicaSyncEnd(end *replicaSyncEnd) (status uint8) {
+	staging := mp.replicaStaging
+	if staging == nil || staging.id != end.ID {
+		return proto.OpArgMismatchErr
+	}
+	mp.replicaStaging = nil
+	mp.inodeTree = staging.inodeTree
+	mp.dentryTree = staging.dentryTree
+	mp.extendTree = staging.extendTree
+	mp.multipartTree = staging.multipartTree
+	mp.config.Cursor = staging.cursor
+	mp.replicaSyncStatus.Store(&proto.MetaReplicaSyncStatus{
+		PartitionID:       mp.config.PartitionId,
+		SourcePartitionID: staging.sourcePartitionID,
+		SourceApplyID:     end.SourceApplyID,
+		InodeCount:        uint64(staging.inodeTree.Len()),
+		DentryCount:       uint64(staging.dentryTree.Len()),
+		LastSyncTime:      end.Time,
+	})
+	log.LogInfof("[fsmReplicaSyncEnd] mp(%v) sync(%v) ends, source applyID(%v) inodes(%v) dentries(%v)",
+		mp.config.PartitionId, end.ID, end.SourceApplyID, staging.inodeTree.Len(), staging.dentryTree.Len())
+	return proto.OpOk
+}
+
+// storeReplicaStaging stores the staging of the sync running by the apply index of sm.
+func (mp *metaPartition) storeReplicaStaging(rootDir string, sm *storeMsg) (err error) {
+	if sm.replicaStaging == nil {
+		return
+	}
+	f, err := newBufFile(path.Join(rootDir, replicaStagingFile), os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err == nil {
+			err = syncSnapshotFile(f)
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	var frame []byte
+	if rangeErr := sm.replicaStaging.rangeItems(func(item *MetaItem) bool {
+		var data []byte
+		if data, err = item.MarshalBinary(); err != nil {
+			return false
+		}
+		frame = appendSnapshotFrame(frame[:0], data)
+		_, err = f.bf.Write(frame)
+		return err == nil
+	}); err == nil {
+		err = rangeErr
+	}
+	if err != nil {
+		return
+	}
+	log.LogInfof("storeReplicaStaging: store complete: partitionID(%v) volume(%v) sync(%v) inodes(%v)",
+		mp.config.PartitionId, mp.config.VolName, sm.replicaStaging.id, sm.replicaStaging.inodeTree.Len())
+	return
+}
+
+func (mp *metaPartition) loadReplicaStaging(rootDir string) (err error) {
+	data, err := os.ReadFile(path.Join(rootDir, replicaStagingFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.NewErrorf("[loadReplicaStaging] ReadFile: %s", err.Error())
+	}
+	var staging *replicaSyncStaging
+	if err = rangeSnapshotFrames(data, func(item *MetaItem) (err error) {
+		staging, err = staging.restore(item)
+		return
+	}); err != nil {
+		return errors.NewErrorf("[loadReplicaStaging] %s", err.Error())
+	}
+	if staging == nil {
+		return errors.NewErrorf("[loadReplicaStaging] %s", ErrBadSnapshotFrame.Error())
+	}
+	mp.replicaStaging = staging
+	log.LogInfof("loadReplicaStaging: load complete: partitionID(%v) volume(%v) sync(%v)",
+		mp.config.PartitionId, mp.config.VolName, staging.id)
+	return
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicaSyncFromSnapshot(t *testing.T) {
+	source := NewMetaPartitionForTest()
+	source.config.RootDir = t.TempDir()
+	source.manager = &metadataManager{metaNode: &MetaNode{raftSyncSnapFormatVersion: SnapFormatVersion_1}}
+	for ino := uint64(10); ino < 13; ino++ {
+		source.inodeTree.ReplaceOrInsert(NewInode(ino, FileModeType), true)
+		source.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: string(rune('a' + ino)), Inode: ino}, true)
+	}
+	deleted := NewInode(13, FileModeType)
+	deleted.SetDeleteMark()
+	source.inodeTree.ReplaceOrInsert(deleted, true)
+	source.applyID = 100
+
+	var chunks [][]byte
+	require.NoError(t, source.ReadSnapshot(func(frames []byte) error {
+		chunks = append(chunks, append([]byte{}, frames...))
+		return nil
+	}))
+	require.NotEmpty(t, chunks)
+
+	replica := NewMetaPartitionForTest()
+	replica.config.RootDir = t.TempDir()
+	replica.inodeTree.ReplaceOrInsert(NewInode(20, FileModeType), true)
+	index := uint64(0)
+	apply := func(op uint32, v []byte) uint8 {
+		cmd, err := NewMetaItem(op, nil, v).MarshalJson()
+		require.NoError(t, err)
+		index++
+		resp, err := replica.Apply(cmd, index)
+		require.NoError(t, err)
+		return resp.(uint8)
+	}
+	jsonOf := func(v interface{}) []byte {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+	batchOf := func(id uint64, frames []byte) []byte {
+		batch := make([]byte, 8, 8+len(frames))
+		binary.BigEndian.PutUint64(batch, id)
+		return append(batch, frames...)
+	}
+
+	require.False(t, replica.IsVolReplica())
+	begin := &replicaSyncBegin{ID: 1, SourceVol: "src", SourcePartitionID: source.config.PartitionId}
+	require.Equal(t, proto.OpOk, apply(opFSMReplicaSyncBegin, jsonOf(begin)))
+	require.True(t, replica.IsVolReplica())
+	for _, frames := range chunks {
+		require.Equal(t, proto.OpOk, apply(opFSMReplicaSyncBatch, batchOf(1, frames)))
+	}
+	// a batch of another sync is refused
+	require.Equal(t, proto.OpArgMismatchErr, apply(opFSMReplicaSyncBatch, batchOf(2, chunks[0])))
+	// nothing changes until the sync ends
+	require.EqualValues(t, 1, replica.inodeTree.Len())
+
+	require.Equal(t, proto.OpArgMismatchErr, apply(opFSMReplicaSyncEnd, jsonOf(&replicaSyncEnd{ID: 2})))
+	require.Equal(t, proto.OpOk, apply(opFSMReplicaSyncEnd, jsonOf(&replicaSyncEnd{ID: 1, SourceApplyID: 100, Time: 1000})))
+	require.EqualValues(t, 3, replica.inodeTree.Len())
+	require.EqualValues(t, 3, replica.dentryTree.Len())
+	require.Nil(t, replica.inodeTree.Get(NewInode(20, 0)))
+	require.Nil(t, replica.inodeTree.Get(NewInode(13, 0)))
+	require.EqualValues(t, 12, replica.GetCursor())
+
+	status := replica.GetReplicaSyncStatus()
+	require.False(t, status.Syncing)
+	require.EqualValues(t, 100, status.SourceApplyID)
+	require.EqualValues(t, 3, status.InodeCount)
+	require.EqualValues(t, 1000, status.LastSyncTime)
+	require.Equal(t, source.config.PartitionId, status.SourcePartitionID)
+}
+
+func TestReplicaSyncStagingInSnapshot(t *testing.T) {
+	replica := NewMetaPartitionForTest()
+	replica.config.RootDir = t.TempDir()
+	replica.manager = &metadataManager{metaNode: &MetaNode{raftSyncSnapFormatVersion: SnapFormatVersion_1}}
+	require.Equal(t, proto.OpOk, replica.fsmReplicaSyncBegin(&replicaSyncBegin{ID: 1, SourceVol: "src", SourcePartitionID: 2}))
+	for ino := uint64(10); ino < 13; ino++ {
+		require.NoError(t, replica.replicaStaging.insert(NewMetaItem(opFSMCreateInode, NewInode(ino, FileModeType).MarshalKey(), NewInode(ino, FileModeType).MarshalValue())))
+	}
+	dentry := &Dentry{ParentId: 1, Name: "a", Inode: 10}
+	require.NoError(t, replica.replicaStaging.insert(NewMetaItem(opFSMCreateDentry, dentry.MarshalKey(), dentry.MarshalValue())))
+
+	end := &replicaSyncEnd{ID: 1, SourceApplyID: 100, Time: 1000}
+	checkEnd := func(mp *metaPartition) {
+		require.Equal(t, proto.OpOk, mp.fsmReplicaSyncEnd(end))
+		require.EqualValues(t, 3, mp.inodeTree.Len())
+		require.EqualValues(t, 1, mp.dentryTree.Len())
+		require.EqualValues(t, 12, mp.GetCursor())
+		require.EqualValues(t, 2, mp.GetReplicaSyncStatus().SourcePartitionID)
+	}
+
+	// a replica restarting in the middle of the sync loads the staging stored
+	dir := t.TempDir()
+	require.NoError(t, replica.storeReplicaStaging(dir, &storeMsg{replicaStaging: replica.replicaStaging.clone()}))
+	loaded := NewMetaPartitionForTest()
+	require.NoError(t, loaded.loadReplicaStaging(dir))
+	checkEnd(loaded)
+	// nothing is stored without a sync running
+	dir = t.TempDir()
+	require.NoError(t, replica.storeReplicaStaging(dir, &storeMsg{}))
+	loaded = NewMetaPartitionForTest()
+	require.NoError(t, loaded.loadReplicaStaging(dir))
+	require.Nil(t, loaded.replicaStaging)
+
+	// a replica applying a snapshot in the middle of the sync takes the staging sent
+	snap, err := replica.Snapshot()
+	require.NoError(t, err)
+	defer snap.Close()
+	var staging *replicaSyncStaging
+	for {
+		data, err := snap.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		item := NewMetaItem(0, nil, nil)
+		require.NoError(t, item.UnmarshalBinary(data))
+		switch item.Op {
+		case opFSMReplicaSyncStaging:
+			staging, err = staging.restore(item)
+			require.NoError(t, err)
+		case opFSMReplicaSyncStagingItem:
+			staged := NewMetaItem(0, nil, nil)
+			require.NoError(t, staged.UnmarshalBinary(item.V))
+			staging, err = staging.restore(staged)
+			require.NoError(t, err)
+		case opFSMCreateInode:
+			require.Fail(t, "the staged inodes are not in the trees of the snapshot")
+		}
+	}
+	applied := NewMetaPartitionForTest()
+	applied.replicaStaging = staging
+	checkEnd(applied)
+
+	checkEnd(replica)
+	require.Nil(t, replica.replicaStaging)
+}
@@ -0,0 +1,485 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	snapChunkItems       = 4096
+	snapshotResumeExpire = 10 * time.Minute
+)
+
+// the markers produced between the snapshot items since SnapFormatVersion_2
+type (
+	snapChunkStart struct{} // the items after it are cut into chunks
+	snapChunkEnd   struct{} // ends the current chunk
+)
+
+// snapSource is the point in time copy of the partition a snapshot is sent from.
+type snapSource struct {
+	applyID        uint64
+	txId           uint64
+	cursor         uint64
+	uniqID         uint64
+	inodeTree      *BTree
+	dentryTree     *BTree
+	extendTree     *BTree
+	multipartTree  *BTree
+	txTree         *BTree
+	txRbInodeTree  *BTree
+	txRbDentryTree *BTree
+	uniqChecker    *uniqChecker
+	verList        []*proto.VolVersionInfo
+	replicaStaging *replicaSyncStaging
+
+	progress map[uint64]uint32 // chunks verified by each follower
+	timer    *time.Timer
+}
+
+// snapReceived is what a follower applied from the verified chunks of a failed snapshot.
+type snapReceived struct {
+	applyID        uint64
+	chunks         uint32
+	cursor         uint64
+	inodeTree      *BTree
+	dentryTree     *BTree
+	extendTree     *BTree
+	multipartTree  *BTree
+	txTree         *BTree
+	txRbInodeTree  *BTree
+	txRbDentryTree *BTree
+	uniqChecker    *uniqChecker
+	replicaStaging *replicaSyncStaging
+	timer          *time.Timer
+}
+
+// snapResume keeps the source of the last snapshot sent by the leader, and what the follower
+// has of the last snapshot it failed to apply. A snapshot resent from the same source skips
+// the chunks the follower has, both are dropped after snapshotResumeExpire unused.
+type snapResume struct {
+	sync.Mutex
+	source   *snapSource
+	received *snapReceived
+}
+
+func (mp *metaPartition) newSnapSource() (src *snapSource) {
+	mp.nonIdempotent.Lock()
+	defer mp.nonIdempotent.Unlock()
+	return &snapSource{
+		applyID:        mp.getApplyID(),
+		txId:           mp.txProcessor.txManager.txIdAlloc.getTransactionID(),
+		cursor:         mp.GetCursor(),
+		uniqID:         mp.GetUniqId(),
+		inodeTree:      mp.inodeTree.GetTree(),
+		dentryTree:     mp.dentryTree.GetTree(),
+		extendTree:     mp.extendTree.GetTree(),
+		multipartTree:  mp.multipartTree.GetTree(),
+		txTree:         mp.txProcessor.txManager.txTree.GetTree(),
+		txRbInodeTree:  mp.txProcessor.txResource.txRbInodeTree.GetTree(),
+		txRbDentryTree: mp.txProcessor.txResource.txRbDentryTree.GetTree(),
+		uniqChecker:    mp.uniqChecker.clone(),
+		verList:        mp.GetAllVerList(),
+		replicaStaging: mp.replicaStaging.clone(),
+	}
+}
+
+// resumableSnapSource returns the source to send a snapshot from and the chunks all the
+// followers reporting progress have of it. The kept source is reused as long as the raft
+// log still has the entries after it.
+func (mp *metaPartition) resumableSnapSource() (src *snapSource, skip uint32) {
+	r := &mp.snapResume
+	r.Lock()
+	defer r.Unlock()
+	if src = r.source; src != nil && src.applyID >= atomic.LoadUint64(&mp.truncatedIndex) {
+		first := true
+		for _, chunks := range src.progress {
+			if first || chunks < skip {
+				skip = chunks
+			}
+			first = false
+		}
+		src.timer.Reset(snapshotResumeExpire)
+		log.LogWarnf("[resumableSnapSource] mp(%v) resend snapshot(%v) from chunk(%v)", mp.config.PartitionId, src.applyID, skip)
+		return
+	}
+	if src != nil {
+		src.timer.Stop()
+	}
+	src = mp.newSnapSource()
+	src.progress = make(map[uint64]uint32)
+	r.source = src
+	src.timer = time.AfterFunc(snapshotResumeExpire, func() {
+		r.Lock()
+		if r.source == src {
+			r.source = nil
+		}
+		r.Unlock()
+	})
+	return
+}
+
+// snapTruncateIndex returns the index the raft log can be truncated to, the log after the
+// kept snapshot source is needed by the follower once the snapshot is applied.
+func (mp *metaPartition) snapTruncateIndex(index uint64) uint64 {
+	r := &mp.snapResume
+	r.Lock()
+	defer r.Unlock()
+	if r.source != nil && r.source.applyID < index {
+		return r.source.applyID
+	}
+	return index
+}
+
+// SnapshotProgress records the chunks of the kept snapshot source a follower has verified.
+func (mp *metaPartition) SnapshotProgress(req *SnapshotProgressReq, p *Packet) (err error) {
+	r := &mp.snapResume
+	r.Lock()
+	if src := r.source; src != nil && src.applyID == req.ApplyID {
+		if req.Done {
+			delete(src.progress, req.NodeID)
+			if len(src.progress) == 0 {
+				src.timer.Stop()
+				r.source = nil
+			}
+		} else {
+			src.progress[req.NodeID] = req.Chunks
+		}
+	}
+	r.Unlock()
+	log.LogInfof("[SnapshotProgress] mp(%v) node(%v) snapshot(%v) chunks(%v) done(%v)",
+		mp.config.PartitionId, req.NodeID, req.ApplyID, req.Chunks, req.Done)
+	p.PacketOkReply()
+	return
+}
+
+// takeSnapReceived returns what the follower has of the snapshot the leader resends from
+// chunk skip, the snapshot can't be applied if the follower has less. What the follower has
+// is reused even if the leader sends the snapshot from the start, the items are the same.
+func (mp *metaPartition) takeSnapReceived(applyID uint64, skip uint32) (recv *snapReceived, err error) {
+	r := &mp.snapResume
+	r.Lock()
+	defer r.Unlock()
+	recv, r.received = r.received, nil
+	if recv != nil {
+		recv.timer.Stop()
+		if recv.applyID != applyID || recv.chunks < skip {
+			recv = nil
+		}
+	}
+	if recv == nil && skip > 0 {
+		return nil, fmt.Errorf("mp(%v) has no %v chunks of snapshot(%v) to resume", mp.config.PartitionId, skip, applyID)
+	}
+	return
+}
+
+func (mp *metaPartition) keepSnapReceived(recv *snapReceived) {
+	r := &mp.snapResume
+	r.Lock()
+	defer r.Unlock()
+	if r.received != nil {
+		r.received.timer.Stop()
+	}
+	r.received = recv
+	recv.timer = time.AfterFunc(snapshotResumeExpire, func() {
+		r.Lock()
+		if r.received == recv {
+			r.received = nil
+		}
+		r.Unlock()
+	})
+}
+
+// reportSnapshotProgress tells the leader how many chunks of its snapshot the follower has.
+func (mp *metaPartition) reportSnapshotProgress(applyID uint64, chunks uint32, done bool) {
+	leaderAddr, isLeader := mp.IsLeader()
+	if isLeader || leaderAddr == "" || mp.config.ConnPool == nil {
+		return
+	}
+	req := &SnapshotProgressReq{
+		PartitionID: mp.config.PartitionId,
+		NodeID:      mp.config.NodeId,
+		ApplyID:     applyID,
+		Chunks:      chunks,
+		Done:        done,
+	}
+	p := proto.NewPacketReqID()
+	p.Opcode = proto.OpMetaSnapshotProgress
+	p.PartitionID = mp.config.PartitionId
+	err := p.MarshalData(req)
+	if err != nil {
+		return
+	}
+	conn, err := mp.config.ConnPool.GetConnect(leaderAddr)
+	if err != nil {
+		log.LogWarnf("[reportSnapshotProgress] mp(%v) connect leader(%v) failed: %v", mp.config.PartitionId, leaderAddr, err)
+		return
+	}
+	defer func() {
+		mp.config.ConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		log.LogWarnf("[reportSnapshotProgress] mp(%v) leader(%v) failed: %v", mp.config.PartitionId, leaderAddr, err)
+	}
+}
+
+func encodeSnapResume(applyID uint64, skip uint32) []byte {
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint64(buf, applyID)
+	binary.BigEndian.PutUint32(buf[8:], skip)
+	return buf
+}
+
+func decodeSnapResume(buf []byte) (applyID uint64, skip uint32, err error) {
+	if len(buf) < 12 {
+		return 0, 0, fmt.Errorf("invalid snapshot resume item of %v bytes", len(buf))
+	}
+	return binary.BigEndian.Uint64(buf), binary.BigEndian.Uint32(buf[8:]), nil
+}
+
+// snapChunker cuts the items sent after snapChunkStart into chunks, which end with an item of
+// the sequence, count and crc of their items. The chunks the follower has are not sent.
+type snapChunker struct {
+	applyID uint64
+	skip    uint32
+	started bool
+	seq     uint32
+	items   uint32
+	crc     uint32
+}
+
+// next returns the data to send for the item or marker, nil if nothing is sent.
+func (c *snapChunker) next(data []byte, marker interface{}) ([]byte, error) {
+	switch marker.(type) {
+	case snapChunkStart:
+		c.started = true
+		return NewMetaItem(opFSMSnapResume, nil, encodeSnapResume(c.applyID, c.skip)).MarshalBinary()
+	case snapChunkEnd:
+		if c.items == 0 {
+			return nil, nil
+		}
+		c.seq++
+		buf := make([]byte, 12)
+		binary.BigEndian.PutUint32(buf, c.seq)
+		binary.BigEndian.PutUint32(buf[4:], c.items)
+		binary.BigEndian.PutUint32(buf[8:], c.crc)
+		c.items, c.crc = 0, 0
+		if c.seq <= c.skip {
+			return nil, nil
+		}
+		return NewMetaItem(opFSMSnapChunk, nil, buf).MarshalBinary()
+	}
+	if !c.started {
+		return data, nil
+	}
+	c.items++
+	c.crc = crc32.Update(c.crc, crc32.IEEETable, data)
+	if c.seq < c.skip {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// snapVerifier checks the chunks received by the follower.
+type snapVerifier struct {
+	started bool
+	files   bool   // the extent del files are being received, their chunks are never skipped
+	seq     uint32 // of the last verified chunk
+	items   uint32
+	crc     uint32
+}
+
+func (v *snapVerifier) start(skip uint32) {
+	v.started, v.seq = true, skip
+}
+
+func (v *snapVerifier) add(data []byte) {
+	v.items++
+	v.crc = crc32.Update(v.crc, crc32.IEEETable, data)
+}
+
+func (v *snapVerifier) check(buf []byte) (err error) {
+	if len(buf) < 12 {
+		return fmt.Errorf("invalid snapshot chunk item of %v bytes", len(buf))
+	}
+	seq, items, crc := binary.BigEndian.Uint32(buf), binary.BigEndian.Uint32(buf[4:]), binary.BigEndian.Uint32(buf[8:])
+	if seq != v.seq+1 || items != v.items || crc != v.crc {
+		return fmt.Errorf("snapshot chunk(%v) items(%v) crc(%v) mismatch, received chunk(%v) items(%v) crc(%v)",
+			seq, items, crc, v.seq+1, v.items, v.crc)
+	}
+	v.seq, v.items, v.crc = seq, 0, 0
+	return
+}
+
+func newSnapSendLimiter(rateMB int) *rate.Limiter {
+	if rateMB <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(rateMB*util.MB), rateMB*util.MB)
+}
+
+// waitSnapSendQuota blocks until n bytes of snapshot can be sent or received by the limiter.
+func waitSnapSendQuota(limiter *rate.Limiter, n int) {
+	if limiter == nil || limiter.Limit() == rate.Inf {
+		return
+	}
+	for n > 0 {
+		size := n
+		if burst := limiter.Burst(); size > burst {
+			size = burst
+		}
+		if err := limiter.WaitN(context.Background(), size); err != nil {
+			return
+		}
+		n -= size
+	}
+}
+
+func setSnapLimiterRate(limiter *rate.Limiter, rateMB int) {
+	if rateMB <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	limiter.SetBurst(rateMB * util.MB)
+	limiter.SetLimit(rate.Limit(rateMB * util.MB))
+}
+
+// the snapshot transfer rates of the node
+const (
+	snapRateSend      = iota // of all the snapshots sent by the node
+	snapRateRecv             // of all the snapshots received by the node
+	snapRatePartition        // of the snapshot of a partition sent or received
+	snapRateCount
+)
+
+// snapshotRates are the bandwidths in MB/s of the snapshot transfers of the node, the ones
+// set by master override the ones of the node unless 0. 0 of a rate is unlimited.
+type snapshotRates struct {
+	sync.Mutex
+	local         [snapRateCount]int
+	master        [snapRateCount]int
+	partitionRate int64 // the one in effect, read by the transfers on the way
+}
+
+func (r *snapshotRates) effectiveLocked(kind int) int {
+	if r.master[kind] > 0 {
+		return r.master[kind]
+	}
+	return r.local[kind]
+}
+
+// SnapshotRates are the bandwidths in MB/s of the snapshot transfers in effect on the node.
+type SnapshotRates struct {
+	SendRateMB      int `json:"sendRateMB"`
+	RecvRateMB      int `json:"recvRateMB"`
+	PartitionRateMB int `json:"partitionRateMB"`
+}
+
+// setSnapshotRate changes one of the rates set on the node or by master, and applies the one
+// in effect to the limiters.
+func (m *metadataManager) setSnapshotRate(kind, rateMB int, byMaster bool) {
+	m.snapRates.Lock()
+	defer m.snapRates.Unlock()
+	old := m.snapRates.effectiveLocked(kind)
+	if byMaster {
+		m.snapRates.master[kind] = rateMB
+	} else {
+		m.snapRates.local[kind] = rateMB
+	}
+	rateMB = m.snapRates.effectiveLocked(kind)
+	switch kind {
+	case snapRateSend:
+		setSnapLimiterRate(m.snapSendLimiter, rateMB)
+	case snapRateRecv:
+		setSnapLimiterRate(m.snapRecvLimiter, rateMB)
+	case snapRatePartition:
+		atomic.StoreInt64(&m.snapRates.partitionRate, int64(rateMB))
+	}
+	if old != rateMB {
+		log.LogWarnf("[setSnapshotRate] snapshot rate(%v) from %v to %v MB/s, byMaster(%v)", kind, old, rateMB, byMaster)
+	}
+}
+
+// updateSnapshotRates applies the snapshot transfer rates set on master, 0 keeps the ones of
+// the node.
+func (m *metadataManager) updateSnapshotRates(sendMB, recvMB, partitionMB uint64) {
+	m.setSnapshotRate(snapRateSend, int(sendMB), true)
+	m.setSnapshotRate(snapRateRecv, int(recvMB), true)
+	m.setSnapshotRate(snapRatePartition, int(partitionMB), true)
+}
+
+// GetSnapshotRates returns the snapshot transfer rates in effect on the node.
+func (m *metadataManager) GetSnapshotRates() SnapshotRates {
+	m.snapRates.Lock()
+	defer m.snapRates.Unlock()
+	return SnapshotRates{
+		SendRateMB:      m.snapRates.effectiveLocked(snapRateSend),
+		RecvRateMB:      m.snapRates.effectiveLocked(snapRateRecv),
+		PartitionRateMB: m.snapRates.effectiveLocked(snapRatePartition),
+	}
+}
+
+// snapPartitionLimiter limits the snapshot transfer of a partition, it follows the partition
+// rate of the node changed on the way.
+type snapPartitionLimiter struct {
+	rates   *snapshotRates
+	rateMB  int64
+	limiter *rate.Limiter
+}
+
+func (m *metadataManager) newSnapPartitionLimiter() *snapPartitionLimiter {
+	rateMB := atomic.LoadInt64(&m.snapRates.partitionRate)
+	return &snapPartitionLimiter{rates: &m.snapRates, rateMB: rateMB, limiter: newSnapSendLimiter(int(rateMB))}
+}
+
+func (l *snapPartitionLimiter) wait(n int) {
+	if l == nil {
+		return
+	}
+	if rateMB := atomic.LoadInt64(&l.rates.partitionRate); rateMB != l.rateMB {
+		l.rateMB = rateMB
+		setSnapLimiterRate(l.limiter, int(rateMB))
+	}
+	waitSnapSendQuota(l.limiter, n)
+}
+
+// SetSnapshotSendRate changes the bandwidth in MB/s the snapshots are sent with by the
+// node, 0 is unlimited. The one set by master overrides it unless 0.
+func (m *metadataManager) SetSnapshotSendRate(rateMB int) {
+	m.setSnapshotRate(snapRateSend, rateMB, false)
+}
+
+// GetSnapshotSendRate returns the bandwidth in MB/s the snapshots are sent with, 0 is unlimited.
+func (m *metadataManager) GetSnapshotSendRate() int {
+	if limit := m.snapSendLimiter.Limit(); limit != rate.Inf {
+		return int(limit) / util.MB
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestSnapshotChunks(t *testing.T) {
+	newItem := func(key string) []byte {
+		data, err := NewMetaItem(opFSMCreateInode, []byte(key), nil).MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+	header := newItem("header")
+	items := make([][]byte, 0)
+	for i := 0; i < 10; i++ {
+		items = append(items, newItem(fmt.Sprintf("item-%d", i)))
+	}
+	// send the items in chunks of 3, the follower has the first skip chunks
+	send := func(skip uint32) (sent [][]byte) {
+		c := &snapChunker{applyID: 100, skip: skip}
+		emit := func(data []byte, marker interface{}) {
+			out, err := c.next(data, marker)
+			require.NoError(t, err)
+			if out != nil {
+				sent = append(sent, out)
+			}
+		}
+		emit(header, nil)
+		emit(nil, snapChunkStart{})
+		for i, item := range items {
+			emit(item, nil)
+			if i%3 == 2 {
+				emit(nil, snapChunkEnd{})
+			}
+		}
+		emit(nil, snapChunkEnd{})
+		emit(nil, snapChunkEnd{})
+		return
+	}
+	// receive checks the chunks, and returns the items applied and the chunks verified
+	receive := func(sent [][]byte, corrupt int) (applied int, seq uint32, err error) {
+		v := &snapVerifier{}
+		for _, data := range sent[1:] {
+			snap := NewMetaItem(0, nil, nil)
+			require.NoError(t, snap.UnmarshalBinary(data))
+			if v.started && snap.Op != opFSMSnapChunk {
+				if applied == corrupt {
+					data = newItem("corrupted")
+				}
+				v.add(data)
+			}
+			switch snap.Op {
+			case opFSMSnapResume:
+				applyID, skip, err := decodeSnapResume(snap.V)
+				require.NoError(t, err)
+				require.EqualValues(t, 100, applyID)
+				v.start(skip)
+			case opFSMSnapChunk:
+				if err = v.check(snap.V); err != nil {
+					return applied, v.seq, err
+				}
+			default:
+				applied++
+			}
+		}
+		return applied, v.seq, nil
+	}
+	sent := send(0)
+	require.Equal(t, header, sent[0])
+	// header, resume, 10 items and 4 chunks
+	require.Len(t, sent, 16)
+	applied, seq, err := receive(sent, -1)
+	require.NoError(t, err)
+	require.Equal(t, 10, applied)
+	require.EqualValues(t, 4, seq)
+
+	// the chunks the follower has are not sent again
+	sent = send(2)
+	require.Len(t, sent, 2+4+2)
+	applied, seq, err = receive(sent, -1)
+	require.NoError(t, err)
+	require.Equal(t, 4, applied)
+	require.EqualValues(t, 4, seq)
+
+	// an item of the third chunk is corrupted on the way
+	_, seq, err = receive(send(0), 7)
+	require.Error(t, err)
+	require.EqualValues(t, 2, seq)
+
+	_, _, err = decodeSnapResume([]byte{1})
+	require.Error(t, err)
+	require.Error(t, (&snapVerifier{}).check(nil))
+}
+
+func TestSnapshotResume(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.config.NodeId = 1
+
+	// the source is kept and reused until the followers have the snapshot
+	src, skip := mp.resumableSnapSource()
+	require.Zero(t, skip)
+	require.Equal(t, src.applyID, mp.snapTruncateIndex(src.applyID+100))
+	p := &Packet{}
+	require.NoError(t, mp.SnapshotProgress(&SnapshotProgressReq{NodeID: 2, ApplyID: src.applyID, Chunks: 5}, p))
+	require.Equal(t, proto.OpOk, p.ResultCode)
+	mp.SnapshotProgress(&SnapshotProgressReq{NodeID: 3, ApplyID: src.applyID, Chunks: 3}, &Packet{})
+	mp.SnapshotProgress(&SnapshotProgressReq{NodeID: 4, ApplyID: src.applyID + 1, Chunks: 1}, &Packet{})
+	again, skip := mp.resumableSnapSource()
+	require.True(t, src == again)
+	require.EqualValues(t, 3, skip)
+
+	mp.SnapshotProgress(&SnapshotProgressReq{NodeID: 3, ApplyID: src.applyID, Done: true}, &Packet{})
+	_, skip = mp.resumableSnapSource()
+	require.EqualValues(t, 5, skip)
+	mp.SnapshotProgress(&SnapshotProgressReq{NodeID: 2, ApplyID: src.applyID, Done: true}, &Packet{})
+	require.Nil(t, mp.snapResume.source)
+	require.EqualValues(t, 1000, mp.snapTruncateIndex(1000))
+
+	// a source older than the truncated raft log is not reused
+	src, _ = mp.resumableSnapSource()
+	mp.SnapshotProgress(&SnapshotProgressReq{NodeID: 2, ApplyID: src.applyID, Chunks: 5}, &Packet{})
+	mp.truncatedIndex = src.applyID + 1
+	again, skip = mp.resumableSnapSource()
+	require.False(t, src == again)
+	require.Zero(t, skip)
+
+	// the follower resumes only the snapshot it has the chunks of
+	recv, err := mp.takeSnapReceived(10, 0)
+	require.NoError(t, err)
+	require.Nil(t, recv)
+	_, err = mp.takeSnapReceived(10, 1)
+	require.Error(t, err)
+	mp.keepSnapReceived(&snapReceived{applyID: 10, chunks: 2})
+	_, err = mp.takeSnapReceived(10, 3)
+	require.Error(t, err)
+	_, err = mp.takeSnapReceived(10, 2)
+	require.Error(t, err, "taken by the last attempt")
+	mp.keepSnapReceived(&snapReceived{applyID: 10, chunks: 2})
+	recv, err = mp.takeSnapReceived(10, 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, recv.chunks)
+	mp.keepSnapReceived(&snapReceived{applyID: 10, chunks: 2})
+	recv, err = mp.takeSnapReceived(11, 0)
+	require.NoError(t, err)
+	require.Nil(t, recv)
+}
+
+func TestSnapshotSendRate(t *testing.T) {
+	m := &metadataManager{snapSendLimiter: newSnapSendLimiter(0)}
+	require.Equal(t, rate.Inf, m.snapSendLimiter.Limit())
+	require.Zero(t, m.GetSnapshotSendRate())
+	waitSnapSendQuota(m.snapSendLimiter, 1<<30)
+	waitSnapSendQuota(nil, 1<<30)
+
+	m.SetSnapshotSendRate(1)
+	require.Equal(t, 1, m.GetSnapshotSendRate())
+	start := time.Now()
+	// 2MB are sent in pieces of the 1MB burst
+	waitSnapSendQuota(m.snapSendLimiter, 2<<20)
+	require.True(t, time.Since(start) > 500*time.Millisecond)
+
+	m.SetSnapshotSendRate(0)
+	require.Zero(t, m.GetSnapshotSendRate())
+}
+
+func TestSnapshotRates(t *testing.T) {
+	m := &metadataManager{snapSendLimiter: newSnapSendLimiter(0), snapRecvLimiter: newSnapSendLimiter(0)}
+	m.snapRates.local = [snapRateCount]int{2, 0, 0}
+	limiter := m.newSnapPartitionLimiter()
+	require.Equal(t, rate.Inf, limiter.limiter.Limit())
+
+	// the rates set by master override the ones of the node unless 0
+	m.updateSnapshotRates(4, 3, 1)
+	require.Equal(t, SnapshotRates{SendRateMB: 4, RecvRateMB: 3, PartitionRateMB: 1}, m.GetSnapshotRates())
+	require.Equal(t, 4, m.GetSnapshotSendRate())
+	require.Equal(t, rate.Limit(3*util.MB), m.snapRecvLimiter.Limit())
+	m.SetSnapshotSendRate(8)
+	require.Equal(t, 4, m.GetSnapshotSendRate())
+
+	// the transfer on the way follows the partition rate
+	start := time.Now()
+	limiter.wait(2 << 20)
+	require.True(t, time.Since(start) > 500*time.Millisecond)
+	require.Equal(t, rate.Limit(util.MB), limiter.limiter.Limit())
+
+	m.updateSnapshotRates(0, 0, 0)
+	require.Equal(t, SnapshotRates{SendRateMB: 8}, m.GetSnapshotRates())
+	require.Equal(t, rate.Inf, m.snapRecvLimiter.Limit())
+	limiter.wait(1 << 30)
+	require.Equal(t, rate.Inf, limiter.limiter.Limit())
+}
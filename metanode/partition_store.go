@@ -30,6 +30,7 @@ import (
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/fileutil"
 	"github.com/cubefs/cubefs/util/log"
 	mmap "github.com/edsrzf/mmap-go"
 )
@@ -85,6 +86,25 @@ func (bf *bufFile) Sync() error {
 	return bf.File.Sync()
 }
 
+// syncSnapshotFile syncs the file of the snapshot in the write through persistence mode. In the
+// write back mode the buffered data is only flushed, the file is left to the page cache.
+func syncSnapshotFile(f interface{ Sync() error }) error {
+	if snapshotWriteBack() {
+		if bf, ok := f.(*bufFile); ok {
+			return bf.bf.Flush()
+		}
+		return nil
+	}
+	return f.Sync()
+}
+
+func writeSnapshotFile(name string, data []byte, perm os.FileMode) error {
+	if snapshotWriteBack() {
+		return os.WriteFile(name, data, perm)
+	}
+	return fileutil.WriteFileWithSync(name, data, perm)
+}
+
 func (bf *bufFile) Close() error {
 	err := bf.bf.Flush()
 	if err != nil {
@@ -911,7 +931,7 @@ func (mp *metaPartition) storeMultiVersion(rootDir string, sm *storeMsg) (crc ui
 		return
 	}
 	defer func() {
-		err = fp.Sync()
+		err = syncSnapshotFile(fp)
 		fp.Close()
 	}()
 	var verData []byte
@@ -991,7 +1011,7 @@ func (mp *metaPartition) storeApplyID(rootDir string, sm *storeMsg) (err error)
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		fp.Close()
 	}()
@@ -1015,7 +1035,7 @@ func (mp *metaPartition) storeTxID(rootDir string, sm *storeMsg) (err error) {
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		fp.Close()
 	}()
@@ -1035,7 +1055,7 @@ func (mp *metaPartition) storeTxRbDentry(rootDir string, sm *storeMsg) (crc uint
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		// TODO Unhandled errors
 		fp.Close()
@@ -1081,7 +1101,7 @@ func (mp *metaPartition) storeTxRbInode(rootDir string, sm *storeMsg) (crc uint3
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		// TODO Unhandled errors
 		fp.Close()
@@ -1127,7 +1147,7 @@ func (mp *metaPartition) storeTxInfo(rootDir string, sm *storeMsg) (crc uint32,
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		// TODO Unhandled errors
 		fp.Close()
@@ -1178,7 +1198,7 @@ func (mp *metaPartition) storeInode(rootDir string,
 
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		// TODO Unhandled errors
 		fp.Close()
@@ -1257,7 +1277,7 @@ func (mp *metaPartition) storeDentry(rootDir string,
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		// TODO Unhandled errors
 		fp.Close()
@@ -1364,7 +1384,7 @@ func (mp *metaPartition) storeExtend(rootDir string, sm *storeMsg) (crc uint32,
 	if err = writer.Flush(); err != nil {
 		return
 	}
-	if err = f.Sync(); err != nil {
+	if err = syncSnapshotFile(f); err != nil {
 		return
 	}
 	crc = crc32.Sum32()
@@ -1428,7 +1448,7 @@ func (mp *metaPartition) storeMultipart(rootDir string, sm *storeMsg) (crc uint3
 	if err = writer.Flush(); err != nil {
 		return
 	}
-	if err = f.Sync(); err != nil {
+	if err = syncSnapshotFile(f); err != nil {
 		return
 	}
 	crc = crc32.Sum32()
@@ -1446,7 +1466,7 @@ func (mp *metaPartition) doStoreUniqID(rootDir string, uniqId uint64) (err error
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		fp.Close()
 	}()
@@ -1471,7 +1491,7 @@ func (mp *metaPartition) storeUniqChecker(rootDir string, sm *storeMsg) (crc uin
 	}
 	defer func() {
 		if err == nil {
-			err = fp.Sync()
+			err = syncSnapshotFile(fp)
 		}
 		fp.Close()
 	}()
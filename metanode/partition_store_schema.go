@@ -0,0 +1,248 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const storeSchemaFile = "schema"
+
+// the schema versions of the snapshot stored by the partition, each one adds files or changes
+// the layout of their records
+const (
+	StoreSchemaVersion_0 uint32 = iota // inode, dentry, extend and multipart
+	StoreSchemaVersion_1               // the transaction trees and id
+	StoreSchemaVersion_2               // the uniq checker and id
+	StoreSchemaVersion_3               // the multi version list
+	StoreSchemaVersion_4               // the schema file recorded with the snapshot
+
+	currentStoreSchemaVersion = StoreSchemaVersion_4
+)
+
+var ErrStoreSchemaTooNew = errors.New("snapshot schema is newer than supported")
+
+// the crc count of the .sign file of each schema version, a version without its own files
+// has the count of the one before
+var storeSchemaCrcCount = []int{
+	StoreSchemaVersion_0: CRC_COUNT_BASIC,
+	StoreSchemaVersion_1: CRC_COUNT_TX_STUFF,
+	StoreSchemaVersion_2: CRC_COUNT_UINQ_STUFF,
+	StoreSchemaVersion_3: CRC_COUNT_MULTI_VER,
+	StoreSchemaVersion_4: CRC_COUNT_MULTI_VER,
+}
+
+// storeSchemaUpgrader upgrades the partition loaded from a snapshot of schema version from
+// to the next one. The snapshot is stored again in the current schema once all the
+// upgraders have run, upgrade only has to fix what is loaded in memory, nil if nothing.
+type storeSchemaUpgrader struct {
+	from    uint32
+	name    string
+	upgrade func(mp *metaPartition) error
+}
+
+// storeSchemaUpgraders has an upgrader for each schema version before the current one, in order.
+// A new schema version is added with the upgrader from the version before it.
+var storeSchemaUpgraders = []storeSchemaUpgrader{
+	{from: StoreSchemaVersion_0, name: "transaction"},  // the transaction trees are empty
+	{from: StoreSchemaVersion_1, name: "uniqChecker"},  // nothing is checked before
+	{from: StoreSchemaVersion_2, name: "multiVersion"}, // the version list is kept in memory
+	{from: StoreSchemaVersion_3, name: "schema"},
+}
+
+type storeSchema struct {
+	Version    uint32 `json:"version"`
+	UpdateTime int64  `json:"updateTime"`
+}
+
+// StoreSchemaStatus is the schema version of the snapshot of a partition, and the migration
+// of the snapshot loaded at startup if it is of an older version.
+type StoreSchemaStatus struct {
+	PartitionID   uint64    `json:"pid"`
+	Version       uint32    `json:"version"`       // of the stored snapshot
+	LoadedVersion uint32    `json:"loadedVersion"` // of the snapshot loaded at startup
+	Current       uint32    `json:"current"`
+	Migrating     bool      `json:"migrating"`
+	Step          string    `json:"step"`
+	Steps         int       `json:"steps"`
+	DoneSteps     int       `json:"doneSteps"`
+	StartTime     time.Time `json:"startTime"`
+	EndTime       time.Time `json:"endTime"`
+	Err           string    `json:"err"`
+}
+
+type storeSchemaMigration struct {
+	sync.RWMutex
+	status StoreSchemaStatus
+}
+
+// loadStoreSchema returns the schema version of the snapshot, the .sign file of which has
+// crcCount crcs. The snapshots stored before the schema file is recorded have the version
+// of their crc count.
+func loadStoreSchema(snapshotPath string, crcCount int) (version uint32, err error) {
+	data, err := os.ReadFile(path.Join(snapshotPath, storeSchemaFile))
+	if os.IsNotExist(err) {
+		for v := currentStoreSchemaVersion; ; v-- {
+			if storeSchemaCrcCount[v] == crcCount {
+				return v, nil
+			}
+			if v == StoreSchemaVersion_0 {
+				break
+			}
+		}
+		log.LogErrorf("action[loadStoreSchema] crc array length %d not match", crcCount)
+		return 0, ErrSnapshotCrcMismatch
+	}
+	if err != nil {
+		return
+	}
+	schema := &storeSchema{}
+	if err = json.Unmarshal(data, schema); err != nil {
+		return 0, errors.NewErrorf("[loadStoreSchema] Unmarshal: %s", err.Error())
+	}
+	if schema.Version > currentStoreSchemaVersion {
+		return schema.Version, errors.NewErrorf("%v: version %v, supported %v",
+			ErrStoreSchemaTooNew, schema.Version, currentStoreSchemaVersion)
+	}
+	if storeSchemaCrcCount[schema.Version] != crcCount {
+		log.LogErrorf("action[loadStoreSchema] schema version %v, crc array length %d not match", schema.Version, crcCount)
+		return schema.Version, ErrSnapshotCrcMismatch
+	}
+	return schema.Version, nil
+}
+
+func storeStoreSchema(rootDir string) (err error) {
+	data, err := json.Marshal(&storeSchema{Version: currentStoreSchemaVersion, UpdateTime: time.Now().Unix()})
+	if err != nil {
+		return
+	}
+	return writeSnapshotFile(path.Join(rootDir, storeSchemaFile), data, 0o644)
+}
+
+func (mp *metaPartition) updateSchemaStatus(update func(status *StoreSchemaStatus)) {
+	mp.schemaMigration.Lock()
+	update(&mp.schemaMigration.status)
+	mp.schemaMigration.Unlock()
+}
+
+// upgradeStoreSchema runs the upgraders after version on the partition loaded from the
+// snapshot, and stores the snapshot in the current schema. The partition fails to load if an
+// upgrader fails. If only the store fails, the snapshot of the old version is kept and the
+// next snapshot stored by the partition is of the current schema.
+func (mp *metaPartition) upgradeStoreSchema(version uint32) (err error) {
+	upgraders := storeSchemaUpgraders[version:]
+	if mp.manager != nil {
+		// the partition is attached once loaded, it is listed with the upgrading ones meanwhile
+		mp.manager.storeSchemaUpgrading.Store(mp.config.PartitionId, mp)
+		defer mp.manager.storeSchemaUpgrading.Delete(mp.config.PartitionId)
+	}
+	mp.updateSchemaStatus(func(status *StoreSchemaStatus) {
+		status.Migrating = true
+		status.Steps = len(upgraders) + 1
+		status.StartTime = time.Now()
+	})
+	log.LogWarnf("action[upgradeStoreSchema] mp(%v) upgrade snapshot schema from %v to %v",
+		mp.config.PartitionId, version, currentStoreSchemaVersion)
+	defer func() {
+		mp.updateSchemaStatus(func(status *StoreSchemaStatus) {
+			status.Migrating = false
+			status.EndTime = time.Now()
+			if err != nil {
+				status.Err = err.Error()
+			}
+		})
+		if err != nil {
+			log.LogErrorf("action[upgradeStoreSchema] mp(%v) upgrade snapshot schema from %v failed: %v",
+				mp.config.PartitionId, version, err)
+		}
+	}()
+	for _, upgrader := range upgraders {
+		mp.updateSchemaStatus(func(status *StoreSchemaStatus) { status.Step = upgrader.name })
+		if upgrader.upgrade != nil {
+			if err = upgrader.upgrade(mp); err != nil {
+				return fmt.Errorf("upgrade %v from schema %v: %v", upgrader.name, upgrader.from, err)
+			}
+		}
+		mp.updateSchemaStatus(func(status *StoreSchemaStatus) { status.DoneSteps++ })
+		log.LogInfof("action[upgradeStoreSchema] mp(%v) upgraded %v from schema %v",
+			mp.config.PartitionId, upgrader.name, upgrader.from)
+	}
+
+	mp.updateSchemaStatus(func(status *StoreSchemaStatus) { status.Step = "store" })
+	msg := &storeMsg{
+		applyIndex:     mp.applyID,
+		txId:           mp.txProcessor.txManager.txIdAlloc.getTransactionID(),
+		inodeTree:      mp.inodeTree.GetTree(),
+		dentryTree:     mp.dentryTree.GetTree(),
+		extendTree:     mp.extendTree.GetTree(),
+		multipartTree:  mp.multipartTree.GetTree(),
+		txTree:         mp.txProcessor.txManager.txTree.GetTree(),
+		txRbInodeTree:  mp.txProcessor.txResource.txRbInodeTree.GetTree(),
+		txRbDentryTree: mp.txProcessor.txResource.txRbDentryTree.GetTree(),
+		uniqId:         mp.GetUniqId(),
+		uniqChecker:    mp.uniqChecker.clone(),
+		multiVerList:   mp.multiVersionList.VerList,
+	}
+	if storeErr := mp.store(msg); storeErr != nil {
+		log.LogWarnf("action[upgradeStoreSchema] mp(%v) store snapshot of schema %v failed: %v",
+			mp.config.PartitionId, currentStoreSchemaVersion, storeErr)
+		mp.updateSchemaStatus(func(status *StoreSchemaStatus) {
+			status.Err = fmt.Sprintf("store snapshot of schema %v: %v", currentStoreSchemaVersion, storeErr)
+		})
+		return
+	}
+	mp.updateSchemaStatus(func(status *StoreSchemaStatus) { status.DoneSteps++ })
+	return
+}
+
+// GetStoreSchema returns the schema status of the snapshot of the partition.
+func (mp *metaPartition) GetStoreSchema() StoreSchemaStatus {
+	mp.schemaMigration.RLock()
+	defer mp.schemaMigration.RUnlock()
+	status := mp.schemaMigration.status
+	status.PartitionID = mp.config.PartitionId
+	status.Current = currentStoreSchemaVersion
+	return status
+}
+
+// GetStoreSchemas returns the schema status of the partitions on the node, including the
+// ones upgrading their snapshots while loaded at startup.
+func (m *metadataManager) GetStoreSchemas() (statuses []StoreSchemaStatus) {
+	statuses = make([]StoreSchemaStatus, 0)
+	listed := make(map[uint64]bool)
+	m.storeSchemaUpgrading.Range(func(_, value interface{}) bool {
+		status := value.(*metaPartition).GetStoreSchema()
+		listed[status.PartitionID] = true
+		statuses = append(statuses, status)
+		return true
+	})
+	m.Range(true, func(id uint64, p MetaPartition) bool {
+		if !listed[id] {
+			statuses = append(statuses, p.GetStoreSchema())
+		}
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].PartitionID < statuses[j].PartitionID })
+	return
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/fileutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSchemaUpgrade(t *testing.T) {
+	require.Len(t, storeSchemaUpgraders, int(currentStoreSchemaVersion))
+	for i, upgrader := range storeSchemaUpgraders {
+		require.EqualValues(t, i, upgrader.from)
+	}
+	require.Len(t, storeSchemaCrcCount, int(currentStoreSchemaVersion)+1)
+
+	testPath := "/tmp/testStoreSchema/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+	metaM := &metadataManager{
+		partitions:      make(map[uint64]MetaPartition),
+		metaNode:        &MetaNode{},
+		fileStatsConfig: &fileStatsConfig{},
+	}
+	newPartition := func() *metaPartition {
+		mpC := &MetaPartitionConfig{PartitionId: 1, VolName: "test_vol", End: 100, RootDir: testPath}
+		mp := NewMetaPartition(mpC, metaM).(*metaPartition)
+		mp.uidManager = NewUidMgr(mpC.VolName, mpC.PartitionId)
+		mp.mqMgr = NewQuotaManager(mpC.VolName, mpC.PartitionId)
+		mp.multiVersionList = &proto.VolVersionInfoList{}
+		return mp
+	}
+	snapshotPath := path.Join(testPath, snapshotDir)
+
+	mp := newPartition()
+	ino := NewInode(10, FileModeType)
+	ino.StorageClass = proto.StorageClass_Replica_HDD
+	mp.inodeTree.ReplaceOrInsert(ino, true)
+	require.NoError(t, mp.store(&storeMsg{
+		txId:           mp.txProcessor.txManager.txIdAlloc.getTransactionID(),
+		inodeTree:      mp.inodeTree,
+		dentryTree:     mp.dentryTree,
+		extendTree:     mp.extendTree,
+		multipartTree:  mp.multipartTree,
+		txTree:         mp.txProcessor.txManager.txTree,
+		txRbInodeTree:  mp.txProcessor.txResource.txRbInodeTree,
+		txRbDentryTree: mp.txProcessor.txResource.txRbDentryTree,
+		uniqChecker:    mp.uniqChecker,
+	}))
+	require.Equal(t, currentStoreSchemaVersion, mp.GetStoreSchema().Version)
+	loaded := newPartition()
+	require.NoError(t, loaded.LoadSnapshot(snapshotPath))
+	status := loaded.GetStoreSchema()
+	require.Equal(t, currentStoreSchemaVersion, status.LoadedVersion)
+	require.Zero(t, status.Steps)
+
+	// a snapshot stored before the uniq checker was added
+	crcs, err := mp.parseCrcFromFile()
+	require.NoError(t, err)
+	old := make([]string, 0)
+	for _, crc := range crcs[:CRC_COUNT_TX_STUFF] {
+		old = append(old, strconv.FormatUint(uint64(crc), 10))
+	}
+	require.NoError(t, fileutil.WriteFileWithSync(path.Join(snapshotPath, SnapshotSign), []byte(strings.Join(old, " ")), 0o644))
+	require.NoError(t, os.Remove(path.Join(snapshotPath, storeSchemaFile)))
+	require.NoError(t, os.Remove(path.Join(snapshotPath, uniqCheckerFile)))
+	require.NoError(t, os.Remove(path.Join(snapshotPath, verdataFile)))
+
+	loaded = newPartition()
+	require.NoError(t, loaded.LoadSnapshot(snapshotPath))
+	require.NotNil(t, loaded.inodeTree.Get(NewInode(10, 0)))
+	status = loaded.GetStoreSchema()
+	require.Equal(t, StoreSchemaVersion_1, status.LoadedVersion)
+	require.Equal(t, currentStoreSchemaVersion, status.Version)
+	require.False(t, status.Migrating)
+	require.Empty(t, status.Err)
+	require.Equal(t, status.Steps, status.DoneSteps)
+	// the upgraders from the transaction schema and the store
+	require.Equal(t, 4, status.Steps)
+	crcs, err = loaded.parseCrcFromFile()
+	require.NoError(t, err)
+	require.Len(t, crcs, CRC_COUNT_MULTI_VER)
+	_, err = os.Stat(path.Join(snapshotPath, storeSchemaFile))
+	require.NoError(t, err)
+
+	// a snapshot of a newer schema is not loaded
+	require.NoError(t, fileutil.WriteFileWithSync(path.Join(snapshotPath, storeSchemaFile), []byte(`{"version":99}`), 0o644))
+	err = newPartition().LoadSnapshot(snapshotPath)
+	require.ErrorContains(t, err, ErrStoreSchemaTooNew.Error())
+}
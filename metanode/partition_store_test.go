@@ -172,3 +172,32 @@ func TestStoreInodeCompitable(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestStoreDentryWriteBack(t *testing.T) {
+	updateSnapshotPersistenceMode(proto.PersistenceModeWriteBack)
+	defer updateSnapshotPersistenceMode(proto.PersistenceModeWriteThrough)
+	require.True(t, snapshotWriteBack())
+	// an unknown mode is ignored
+	updateSnapshotPersistenceMode(10)
+	require.True(t, snapshotWriteBack())
+
+	dTree := NewBtree()
+	for i := 0; i < 100; i++ {
+		dTree.ReplaceOrInsert(&Dentry{
+			ParentId: uint64(101 + i),
+			Inode:    uint64(201 + i),
+			Name:     fmt.Sprintf("test_%d", i),
+		}, true)
+	}
+	rootDir := t.TempDir()
+	mp := newMetaPartition(1024, nil)
+	crc, err := mp.storeDentry(rootDir, &storeMsg{dentryTree: dTree})
+	require.NoError(t, err)
+	require.NoError(t, mp.loadDentry(rootDir, crc))
+	require.Equal(t, 100, mp.dentryTree.Len())
+
+	require.NoError(t, writeSnapshotFile(filepath.Join(rootDir, SnapshotSign), []byte("1 2"), 0o644))
+	data, err := os.ReadFile(filepath.Join(rootDir, SnapshotSign))
+	require.NoError(t, err)
+	require.Equal(t, "1 2", string(data))
+}
@@ -42,6 +42,7 @@ type storeMsg struct {
 	uniqId         uint64
 	uniqChecker    *uniqChecker
 	multiVerList   []*proto.VolVersionInfo
+	replicaStaging *replicaSyncStaging
 }
 
 func (mp *metaPartition) startSchedule(curIndex uint64) {
@@ -60,7 +61,12 @@ func (mp *metaPartition) startSchedule(curIndex uint64) {
 				log.LogWarnf("[startSchedule] start trunc, partitionId=%d: nowAppID"+
 					"=%d, applyID=%d", mp.config.PartitionId, curIndex,
 					msg.applyIndex)
-				mp.raftPartition.Truncate(curIndex)
+				// recorded before the truncate, a snapshot kept for resuming must not fall behind the log
+				truncIndex := mp.snapTruncateIndex(curIndex)
+				if truncIndex > atomic.LoadUint64(&mp.truncatedIndex) {
+					atomic.StoreUint64(&mp.truncatedIndex, truncIndex)
+				}
+				mp.raftPartition.Truncate(truncIndex)
 			} else {
 				// maybe happen when start load dentry
 				log.LogWarnf("[startSchedule] raftPartition is nil so skip" +
@@ -350,3 +350,85 @@ func TestLimitReadDir(t *testing.T) {
 
 	require.True(t, costTime1 > costTime2)
 }
+
+func TestLookupSiblings(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	names := []string{"a", "b", "c", "d", "e"}
+	for i, name := range names {
+		ino := uint64(10 + i)
+		mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: name, Inode: ino, Type: FileModeType}, true)
+		// the inode of d is in another partition
+		if name != "d" {
+			mp.inodeTree.ReplaceOrInsert(NewInode(ino, FileModeType), true)
+		}
+	}
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 2, Name: "f", Inode: 20, Type: FileModeType}, true)
+
+	lookup := func(name string, siblings uint32) (resp *LookupResp) {
+		p := &Packet{}
+		require.NoError(t, mp.Lookup(&LookupReq{ParentID: 1, Name: name, Siblings: siblings}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+		resp = &LookupResp{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		return
+	}
+	resp := lookup("b", 0)
+	require.EqualValues(t, 11, resp.Inode)
+	require.Empty(t, resp.Siblings)
+
+	resp = lookup("b", 2)
+	require.Len(t, resp.Siblings, 2)
+	require.Equal(t, "c", resp.Siblings[0].Name)
+	require.EqualValues(t, 12, resp.Siblings[0].Info.Inode)
+	require.Equal(t, "d", resp.Siblings[1].Name)
+	require.Nil(t, resp.Siblings[1].Info)
+
+	// the siblings end with the parent
+	resp = lookup("d", 10)
+	require.Len(t, resp.Siblings, 1)
+	require.Equal(t, "e", resp.Siblings[0].Name)
+	require.EqualValues(t, 14, resp.Siblings[0].Info.Inode)
+}
+
+func TestReadDirPlus(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.config.Start, mp.config.End = 1, 100
+	names := []string{"a", "b", "c", "d"}
+	for i, name := range names {
+		ino := uint64(10 + i)
+		if name == "c" {
+			// the inode of c is in another partition
+			ino = 1000
+		} else {
+			mp.inodeTree.ReplaceOrInsert(NewInode(ino, FileModeType), true)
+		}
+		mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: name, Inode: ino, Type: FileModeType}, true)
+	}
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 2, Name: "e", Inode: 20, Type: FileModeType}, true)
+
+	readDirPlus := func(marker string, limit uint64) (resp *ReadDirPlusResp) {
+		p := &Packet{}
+		require.NoError(t, mp.ReadDirPlus(&ReadDirPlusReq{ParentID: 1, Marker: marker, Limit: limit}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+		resp = &ReadDirPlusResp{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		return
+	}
+	resp := readDirPlus("", 0)
+	require.Len(t, resp.Children, 4)
+	require.Equal(t, "a", resp.Children[0].Name)
+	require.EqualValues(t, 10, resp.Children[0].Info.Inode)
+	require.Nil(t, resp.Children[2].Info)
+	require.Equal(t, []uint64{1000}, resp.Remote)
+	require.EqualValues(t, 13, resp.Children[3].Info.Inode)
+
+	resp = readDirPlus("b", 2)
+	require.Len(t, resp.Children, 2)
+	require.Equal(t, "b", resp.Children[0].Name)
+	require.Equal(t, "c", resp.Children[1].Name)
+	require.Equal(t, []uint64{1000}, resp.Remote)
+
+	resp = readDirPlus("d", 10)
+	require.Len(t, resp.Children, 1)
+	require.Empty(t, resp.Remote)
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"hash"
+	"hash/crc32"
+	"math"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// rangeCRCBuilder computes the CRCs of the items of a tree in ranges of inode IDs,
+// the items must be added in the ascending order of their IDs.
+type rangeCRCBuilder struct {
+	rangeSize uint64
+	ranges    []*proto.MetaTreeRangeCRC
+	cur       *proto.MetaTreeRangeCRC
+	hash      hash.Hash32
+}
+
+func newRangeCRCBuilder(rangeSize uint64) *rangeCRCBuilder {
+	return &rangeCRCBuilder{rangeSize: rangeSize, ranges: make([]*proto.MetaTreeRangeCRC, 0)}
+}
+
+func (b *rangeCRCBuilder) add(id uint64, data []byte) {
+	start := id / b.rangeSize * b.rangeSize
+	if b.cur == nil || b.cur.Start != start {
+		b.finish()
+		end := start + b.rangeSize
+		if end < start {
+			end = math.MaxUint64
+		}
+		b.cur = &proto.MetaTreeRangeCRC{Start: start, End: end}
+		b.hash = crc32.NewIEEE()
+	}
+	b.cur.Count++
+	b.hash.Write(data)
+}
+
+func (b *rangeCRCBuilder) finish() []*proto.MetaTreeRangeCRC {
+	if b.cur != nil {
+		b.cur.CRC = b.hash.Sum32()
+		b.ranges = append(b.ranges, b.cur)
+		b.cur = nil
+	}
+	return b.ranges
+}
+
+// ComputeTreeCRC computes the CRCs of the inode, dentry and extend trees in ranges of
+// rangeSize inode IDs on a snapshot of the trees.
+func (mp *metaPartition) ComputeTreeCRC(rangeSize uint64) (resp *proto.MetaTreeCRCResponse, err error) {
+	if rangeSize == 0 {
+		rangeSize = proto.DefaultMetaTreeCRCRangeSize
+	}
+	mp.nonIdempotent.Lock()
+	applyID := mp.getApplyID()
+	inodeTree := mp.inodeTree.GetTree()
+	dentryTree := mp.dentryTree.GetTree()
+	extendTree := mp.extendTree.GetTree()
+	mp.nonIdempotent.Unlock()
+
+	resp = &proto.MetaTreeCRCResponse{
+		PartitionID: mp.config.PartitionId,
+		ApplyID:     applyID,
+		RangeSize:   rangeSize,
+		Trees:       make(map[string][]*proto.MetaTreeRangeCRC),
+	}
+
+	var data []byte
+	builder := newRangeCRCBuilder(rangeSize)
+	inodeTree.Ascend(func(i BtreeItem) bool {
+		ino := i.(*Inode)
+		if data, err = ino.Marshal(); err != nil {
+			return false
+		}
+		builder.add(ino.Inode, data)
+		return true
+	})
+	if err != nil {
+		return
+	}
+	resp.Trees[proto.MetaTreeInode] = builder.finish()
+
+	builder = newRangeCRCBuilder(rangeSize)
+	dentryTree.Ascend(func(i BtreeItem) bool {
+		dentry := i.(*Dentry)
+		if data, err = dentry.Marshal(); err != nil {
+			return false
+		}
+		builder.add(dentry.ParentId, data)
+		return true
+	})
+	if err != nil {
+		return
+	}
+	resp.Trees[proto.MetaTreeDentry] = builder.finish()
+
+	builder = newRangeCRCBuilder(rangeSize)
+	extendTree.Ascend(func(i BtreeItem) bool {
+		extend := i.(*Extend)
+		if data, err = extend.Bytes(); err != nil {
+			return false
+		}
+		builder.add(extend.GetInode(), data)
+		return true
+	})
+	if err != nil {
+		return
+	}
+	resp.Trees[proto.MetaTreeExtend] = builder.finish()
+	return
+}
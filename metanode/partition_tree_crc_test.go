@@ -0,0 +1,70 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeTreeCRC(t *testing.T) {
+	fill := func(mp *metaPartition) {
+		for _, id := range []uint64{1, 2, 15, 30} {
+			mp.inodeTree.ReplaceOrInsert(NewInode(id, FileModeType), true)
+			mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "f" + string(rune('a'+id)), Inode: id, Type: FileModeType}, true)
+		}
+		extend := NewExtend(15)
+		extend.Put([]byte("k"), []byte("v"), 0)
+		mp.extendTree.ReplaceOrInsert(extend, true)
+	}
+	mp1 := NewMetaPartitionForTest()
+	mp2 := NewMetaPartitionForTest()
+	fill(mp1)
+	fill(mp2)
+
+	resp1, err := mp1.ComputeTreeCRC(10)
+	require.NoError(t, err)
+	resp2, err := mp2.ComputeTreeCRC(10)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, resp1.RangeSize)
+	require.Equal(t, resp1.Trees, resp2.Trees)
+
+	inodes := resp1.Trees[proto.MetaTreeInode]
+	require.Len(t, inodes, 3)
+	require.EqualValues(t, 0, inodes[0].Start)
+	require.EqualValues(t, 10, inodes[0].End)
+	require.EqualValues(t, 2, inodes[0].Count)
+	require.EqualValues(t, 30, inodes[2].Start)
+	require.Len(t, resp1.Trees[proto.MetaTreeDentry], 1)
+	require.EqualValues(t, 4, resp1.Trees[proto.MetaTreeDentry][0].Count)
+	require.Len(t, resp1.Trees[proto.MetaTreeExtend], 1)
+	require.EqualValues(t, 10, resp1.Trees[proto.MetaTreeExtend][0].Start)
+
+	// only the range of the changed inode diverges
+	item := mp2.inodeTree.Get(NewInode(15, 0)).(*Inode)
+	item.Uid = 1000
+	resp2, err = mp2.ComputeTreeCRC(10)
+	require.NoError(t, err)
+	require.Equal(t, resp1.Trees[proto.MetaTreeInode][0], resp2.Trees[proto.MetaTreeInode][0])
+	require.NotEqual(t, resp1.Trees[proto.MetaTreeInode][1].CRC, resp2.Trees[proto.MetaTreeInode][1].CRC)
+	require.Equal(t, resp1.Trees[proto.MetaTreeInode][2], resp2.Trees[proto.MetaTreeInode][2])
+
+	resp1, err = mp1.ComputeTreeCRC(0)
+	require.NoError(t, err)
+	require.Equal(t, proto.DefaultMetaTreeCRCRangeSize, resp1.RangeSize)
+	require.Len(t, resp1.Trees[proto.MetaTreeInode], 1)
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// VolConfig is the settings of the volume applied by a meta partition. It is never
+// modified after being published, a reload swaps in a new one with a greater version,
+// so the readers always see a consistent set of fields without locking.
+type VolConfig struct {
+	Version                 uint64 `json:"version"`
+	EnablePersistAccessTime bool   `json:"enablePersistAccessTime"`
+	AccessTimeValidInterval uint64 `json:"accessTimeValidInterval"` // seconds
+	AtimeMode               string `json:"atimeMode"`
+	DeleteLockTime          int64  `json:"deleteLockTime"` // hours
+
+	XAttrLimit           proto.XAttrLimit `json:"xattrLimit"` // zero fields are taken from the meta node
+	ExtentConflictPolicy string           `json:"extentConflictPolicy"`
+	EnableOpAudit        bool             `json:"enableOpAudit"`
+	EnableCheckExtentKey bool             `json:"enableCheckExtentKey"`
+	EnableDirStat        bool             `json:"enableDirStat"`
+}
+
+var defaultVolConfig = &VolConfig{
+	AccessTimeValidInterval: proto.DefaultAccessTimeValidInterval,
+}
+
+func (c *VolConfig) equal(o *VolConfig) bool {
+	return c.EnablePersistAccessTime == o.EnablePersistAccessTime &&
+		c.AccessTimeValidInterval == o.AccessTimeValidInterval &&
+		c.AtimeMode == o.AtimeMode &&
+		c.DeleteLockTime == o.DeleteLockTime &&
+		c.XAttrLimit == o.XAttrLimit &&
+		c.ExtentConflictPolicy == o.ExtentConflictPolicy &&
+		c.EnableOpAudit == o.EnableOpAudit &&
+		c.EnableCheckExtentKey == o.EnableCheckExtentKey &&
+		c.EnableDirStat == o.EnableDirStat
+}
+
+// GetVolConfig returns the current settings of the volume.
+func (mp *metaPartition) GetVolConfig() *VolConfig {
+	if conf, ok := mp.volConfig.Load().(*VolConfig); ok {
+		return conf
+	}
+	return defaultVolConfig
+}
+
+// reloadVolConfig publishes the settings in the volume view if any of them changed.
+func (mp *metaPartition) reloadVolConfig(view *proto.SimpleVolView) (changed bool) {
+	mp.volConfigLock.Lock()
+	defer mp.volConfigLock.Unlock()
+
+	old := mp.GetVolConfig()
+	conf := &VolConfig{
+		EnablePersistAccessTime: view.EnablePersistAccessTime,
+		AccessTimeValidInterval: uint64(view.AccessTimeInterval),
+		AtimeMode:               view.AtimeMode,
+		DeleteLockTime:          view.DeleteLockTime,
+		XAttrLimit:              view.XAttrLimit,
+		ExtentConflictPolicy:    view.ExtentConflictPolicy,
+		EnableOpAudit:           view.EnableOpAudit,
+		EnableCheckExtentKey:    view.EnableCheckExtentKey,
+		EnableDirStat:           view.EnableDirStat,
+	}
+	if view.AccessTimeInterval <= proto.MinAccessTimeValidInterval {
+		conf.AccessTimeValidInterval = proto.MinAccessTimeValidInterval
+	}
+	if conf.AtimeMode != "" && !proto.IsValidAtimeMode(conf.AtimeMode) {
+		log.LogWarnf("[reloadVolConfig] mp(%v) ignore invalid atime mode %v", mp.config.PartitionId, conf.AtimeMode)
+		conf.AtimeMode = old.AtimeMode
+	}
+	if !proto.IsValidExtentConflictPolicy(conf.ExtentConflictPolicy) {
+		log.LogWarnf("[reloadVolConfig] mp(%v) ignore invalid extent conflict policy %v",
+			mp.config.PartitionId, conf.ExtentConflictPolicy)
+		conf.ExtentConflictPolicy = old.ExtentConflictPolicy
+	}
+	if conf.equal(old) {
+		return false
+	}
+	conf.Version = old.Version + 1
+	mp.volConfig.Store(conf)
+	if !conf.EnableOpAudit {
+		mp.closeOpAudit()
+	}
+	log.LogInfof("[reloadVolConfig] mp(%v) vol(%v) config from (%+v) to (%+v)",
+		mp.config.PartitionId, mp.config.VolName, *old, *conf)
+	return true
+}
+
+// ReloadVolConfig fetches the view of the volume from master and reloads the settings
+// of its partitions at once instead of waiting for the periodical update.
+func (m *metadataManager) ReloadVolConfig(volName string) (reloaded []uint64, err error) {
+	volView, err := m.getVolumeView(volName)
+	if err != nil {
+		return
+	}
+	reloaded = make([]uint64, 0)
+	m.Range(true, func(id uint64, p MetaPartition) bool {
+		if mp, ok := p.(*metaPartition); ok && mp.config.VolName == volName && mp.reloadVolConfig(volView) {
+			reloaded = append(reloaded, id)
+		}
+		return true
+	})
+	log.LogInfof("[ReloadVolConfig] vol(%v) reloaded partitions %v", volName, reloaded)
+	return
+}
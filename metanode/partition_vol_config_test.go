@@ -0,0 +1,61 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadVolConfig(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}}
+	conf := mp.GetVolConfig()
+	require.EqualValues(t, 0, conf.Version)
+	require.Equal(t, time.Duration(proto.DefaultAccessTimeValidInterval), mp.GetAccessTimeValidInterval())
+
+	view := &proto.SimpleVolView{
+		EnablePersistAccessTime: true,
+		AccessTimeInterval:      1,
+		DeleteLockTime:          2,
+	}
+	require.True(t, mp.reloadVolConfig(view))
+	require.False(t, mp.reloadVolConfig(view))
+	conf = mp.GetVolConfig()
+	require.EqualValues(t, 1, conf.Version)
+	require.True(t, conf.EnablePersistAccessTime)
+	require.EqualValues(t, proto.MinAccessTimeValidInterval, conf.AccessTimeValidInterval)
+	require.EqualValues(t, 2, conf.DeleteLockTime)
+	require.Empty(t, conf.AtimeMode)
+	// the view is shared by partitions and should not be modified
+	require.EqualValues(t, 1, view.AccessTimeInterval)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mp.reloadVolConfig(&proto.SimpleVolView{DeleteLockTime: int64(10 + i)})
+			c := mp.GetVolConfig()
+			require.False(t, c.EnablePersistAccessTime)
+			require.True(t, c.DeleteLockTime >= 10)
+		}(i)
+	}
+	wg.Wait()
+	require.EqualValues(t, 9, mp.GetVolConfig().Version)
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// GetXAttrLimit returns the xattr limit of the volume, the unset fields are taken from
+// the default of the meta node.
+func (mp *metaPartition) GetXAttrLimit() proto.XAttrLimit {
+	limit := mp.GetVolConfig().XAttrLimit
+	if mp.manager != nil {
+		limit = limit.Merge(mp.manager.xattrLimit)
+	}
+	return limit
+}
+
+// checkXAttrLimit checks whether the xattrs of the inode exceed the limit after attrs are set.
+func (mp *metaPartition) checkXAttrLimit(ino uint64, attrs map[string]string) (err error) {
+	limit := mp.GetXAttrLimit()
+	if limit == (proto.XAttrLimit{}) {
+		return
+	}
+	for key, value := range attrs {
+		if limit.MaxKeySize > 0 && uint32(len(key)) > limit.MaxKeySize {
+			return fmt.Errorf("size of xattr key(%v) is %v, exceeds limit %v", key, len(key), limit.MaxKeySize)
+		}
+		if limit.MaxValueSize > 0 && uint32(len(value)) > limit.MaxValueSize {
+			return fmt.Errorf("size of xattr(%v) value is %v, exceeds limit %v", key, len(value), limit.MaxValueSize)
+		}
+	}
+	if limit.MaxCount == 0 && limit.MaxTotalSize == 0 {
+		return
+	}
+
+	var count, total uint64
+	for key, value := range attrs {
+		count++
+		total += uint64(len(key) + len(value))
+	}
+	if item := mp.extendTree.Get(NewExtend(ino)); item != nil {
+		item.(*Extend).Range(func(key, value []byte) bool {
+			if _, ok := attrs[string(key)]; !ok && !strings.HasPrefix(string(key), innerXAttrPrefix) {
+				count++
+				total += uint64(len(key) + len(value))
+			}
+			return true
+		})
+	}
+	if limit.MaxCount > 0 && count > uint64(limit.MaxCount) {
+		return fmt.Errorf("count of xattrs of inode(%v) would be %v, exceeds limit %v", ino, count, limit.MaxCount)
+	}
+	if limit.MaxTotalSize > 0 && total > limit.MaxTotalSize {
+		return fmt.Errorf("size of xattrs of inode(%v) would be %v, exceeds limit %v", ino, total, limit.MaxTotalSize)
+	}
+	return
+}
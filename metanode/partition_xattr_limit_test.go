@@ -0,0 +1,71 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckXAttrLimit(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		extendTree: NewBtree(),
+		manager:    &metadataManager{xattrLimit: proto.XAttrLimit{MaxKeySize: 8, MaxValueSize: 16}},
+	}
+	extend := NewExtend(10)
+	extend.Put([]byte("k1"), []byte("v1"), 0)
+	extend.Put([]byte("k2"), []byte("v2"), 0)
+	mp.extendTree.ReplaceOrInsert(extend, true)
+
+	// default limit of the meta node
+	require.Equal(t, proto.XAttrLimit{MaxKeySize: 8, MaxValueSize: 16}, mp.GetXAttrLimit())
+	require.NoError(t, mp.checkXAttrLimit(10, map[string]string{"k3": "v3"}))
+	require.Error(t, mp.checkXAttrLimit(10, map[string]string{"too-long-key": "v"}))
+	require.Error(t, mp.checkXAttrLimit(10, map[string]string{"k3": "too-long-xattr-value"}))
+
+	// the limit of volume overrides the default
+	mp.reloadVolConfig(&proto.SimpleVolView{XAttrLimit: proto.XAttrLimit{MaxCount: 3, MaxKeySize: 4, MaxTotalSize: 14}})
+	require.Equal(t, proto.XAttrLimit{MaxCount: 3, MaxKeySize: 4, MaxValueSize: 16, MaxTotalSize: 14}, mp.GetXAttrLimit())
+	require.Error(t, mp.checkXAttrLimit(10, map[string]string{"key45": "v"}))
+	require.NoError(t, mp.checkXAttrLimit(10, map[string]string{"k3": "v3"}))
+	require.Error(t, mp.checkXAttrLimit(10, map[string]string{"k3": "v3", "k4": "v4"}))
+	// replacing an existing xattr is not counted twice
+	require.NoError(t, mp.checkXAttrLimit(10, map[string]string{"k1": "v1", "k2": "v2", "k3": "v3"}))
+	require.Error(t, mp.checkXAttrLimit(10, map[string]string{"k1": "v1-longer", "k3": "v3"}))
+	// limit of a new inode
+	require.NoError(t, mp.checkXAttrLimit(11, map[string]string{"k1": "v1", "k2": "v2"}))
+
+	mp.manager = nil
+	mp.reloadVolConfig(&proto.SimpleVolView{})
+	require.NoError(t, mp.checkXAttrLimit(10, map[string]string{"too-long-key": "too-long-xattr-value"}))
+}
+
+func TestSetXAttrLimitExceeded(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1},
+		extendTree: NewBtree(),
+		manager:    &metadataManager{xattrLimit: proto.XAttrLimit{MaxKeySize: 4}},
+	}
+	p := &Packet{}
+	require.Error(t, mp.SetXAttr(&proto.SetXAttrRequest{Inode: 10, Key: "too-long-key", Value: "v"}, p))
+	require.Equal(t, proto.OpXAttrLimitExceeded, p.ResultCode)
+
+	p = &Packet{}
+	require.Error(t, mp.BatchSetXAttr(&proto.BatchSetXAttrRequest{Inode: 10, Attrs: map[string]string{"k": "v", "too-long-key": "v"}}, p))
+	require.Equal(t, proto.OpXAttrLimitExceeded, p.ResultCode)
+}
@@ -19,6 +19,7 @@ import (
 	"io"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/cubefs/cubefs/depends/xtaci/smux"
 
@@ -97,6 +98,11 @@ func (m *MetaNode) serveConn(conn net.Conn, stopC chan uint8) {
 			}
 			return
 		}
+		if m.drainRejects(p) {
+			log.LogWarnf("serve MetaNode: draining, close connection from %v", remoteAddr)
+			return
+		}
+		p.setDeadline(time.Now())
 		if err := m.handlePacket(conn, p, remoteAddr); err != nil {
 			if p.ResultCode == proto.OpWriteOpOfProtoVerForbidden {
 				return
@@ -116,6 +122,18 @@ func (m *MetaNode) handlePacket(conn net.Conn, p *Packet,
 	remoteAddr string,
 ) (err error) {
 	// Handle request
+	m.startPacketSpan(p, remoteAddr)
+	defer m.finishPacketSpan(p)
+	if lane := m.laneOf(p); lane != nil {
+		release := lane.acquire()
+		defer release()
+	}
+	p.span.Event("queued")
+	// the client has given up the request queued too long, don't propose it
+	if p.expired() {
+		p.dropExpired("queue")
+		return
+	}
 	err = m.metadataManager.HandleMetadataOperation(conn, p, remoteAddr)
 	return
 }
@@ -153,6 +171,10 @@ func (m *MetaNode) startSmuxServer() (err error) {
 }
 
 func (m *MetaNode) stopSmuxServer() {
+	if extentDeleter != nil {
+		extentDeleter.stop()
+		extentDeleter = nil
+	}
 	if smuxPool != nil {
 		smuxPool.Close()
 		log.LogDebugf("action[stopSmuxServer] stop smux conn pool")
@@ -225,6 +247,11 @@ func (m *MetaNode) serveSmuxStream(stream *smux.Stream, remoteAddr string, stopC
 			}
 			return
 		}
+		if m.drainRejects(p) {
+			log.LogWarnf("serve MetaNode: draining, close stream from %v", remoteAddr)
+			return
+		}
+		p.setDeadline(time.Now())
 		if err := m.handlePacket(stream, p, remoteAddr); err != nil {
 			log.LogErrorf("serve handlePacket fail: %v", err)
 		}
@@ -2,6 +2,7 @@ package metanode
 
 import (
 	"encoding/json"
+	"math"
 	"sync"
 
 	"github.com/cubefs/cubefs/datanode/storage"
@@ -374,6 +375,28 @@ func (se *SortedExtents) CheckAndAddRef(lastKey *proto.ExtentKey, currEk *proto.
 	return
 }
 
+// OverlappedExtents returns the keys overlapped by ek, fully or partially, except those of
+// the same extent mapped to the same file offset, which are written by the same writer.
+func (se *SortedExtents) OverlappedExtents(ek *proto.ExtentKey) (extents []proto.ExtentKey) {
+	endOffset := ek.FileOffset + uint64(ek.Size)
+	se.RLock()
+	defer se.RUnlock()
+	for _, key := range se.eks {
+		if key.FileOffset >= endOffset {
+			break
+		}
+		if key.FileOffset+uint64(key.Size) <= ek.FileOffset {
+			continue
+		}
+		if key.PartitionId == ek.PartitionId && key.ExtentId == ek.ExtentId &&
+			key.ExtentOffset-key.FileOffset == ek.ExtentOffset-ek.FileOffset {
+			continue
+		}
+		extents = append(extents, key)
+	}
+	return
+}
+
 func (se *SortedExtents) AppendWithCheck(inodeID uint64, ek proto.ExtentKey, addRefFunc func(*proto.ExtentKey), clientDiscardExts []proto.ExtentKey) (deleteExtents []proto.ExtentKey, status uint8) {
 	status = proto.OpOk
 	endOffset := ek.FileOffset + uint64(ek.Size)
@@ -541,6 +564,50 @@ func (se *SortedExtents) Len() int {
 	return len(se.eks)
 }
 
+// sequential returns if right continues left in the same extent, so both can be one key.
+// Keys with snapshot info keep their own refs and are never merged.
+func sequential(left, right *proto.ExtentKey) bool {
+	return left.SnapInfo == nil && right.SnapInfo == nil && left.IsSequenceWithSameSeq(right) &&
+		uint64(left.Size)+uint64(right.Size) <= math.MaxUint32
+}
+
+// SequentialCount returns the number of keys MergeSequential would remove.
+func (se *SortedExtents) SequentialCount() (count int) {
+	se.RLock()
+	defer se.RUnlock()
+	for idx := 1; idx < len(se.eks); idx++ {
+		if sequential(&se.eks[idx-1], &se.eks[idx]) {
+			count++
+		}
+	}
+	return
+}
+
+// MergeSequential merges the adjacent keys which are contiguous both in the file and in
+// the extent into one key and returns the number of keys removed. The data of the file
+// is not moved, the merged key covers the same range of the extent.
+func (se *SortedExtents) MergeSequential() (merged int) {
+	se.Lock()
+	defer se.Unlock()
+	if len(se.eks) < 2 {
+		return
+	}
+	eks := make([]proto.ExtentKey, 0, len(se.eks))
+	for _, ek := range se.eks {
+		if n := len(eks); n > 0 && sequential(&eks[n-1], &ek) {
+			eks[n-1].Size += ek.Size
+			eks[n-1].CRC = 0
+			merged++
+			continue
+		}
+		eks = append(eks, ek)
+	}
+	if merged > 0 {
+		se.eks = eks
+	}
+	return
+}
+
 // Returns the file size
 func (se *SortedExtents) LayerSize() (layerSize uint64) {
 	se.RLock()
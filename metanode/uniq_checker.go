@@ -52,6 +52,20 @@ type uniqChecker struct {
 
 	keepTime int64
 	keepOps  int
+	checks   uint64
+	hits     uint64
+}
+
+// UniqCheckerStat is the dedup table of a partition, Hits counts the requests found
+// repeated in the table out of the Checks since the node started.
+type UniqCheckerStat struct {
+	Size       int     `json:"size"`
+	KeepTime   int64   `json:"keepTime"`
+	KeepOps    int     `json:"keepOps"`
+	Checks     uint64  `json:"checks"`
+	Hits       uint64  `json:"hits"`
+	HitRate    float64 `json:"hitRate"`
+	OldestTime int64   `json:"oldestTime"`
 }
 
 func newUniqChecker() *uniqChecker {
@@ -143,7 +157,9 @@ func (checker *uniqChecker) legalIn(bid uint64) bool {
 	checker.Lock()
 	defer checker.Unlock()
 
+	checker.checks++
 	if _, ok := checker.op[bid]; ok {
+		checker.hits++
 		return false
 	} else {
 		checker.op[bid] = struct{}{}
@@ -153,6 +169,44 @@ func (checker *uniqChecker) legalIn(bid uint64) bool {
 	return true
 }
 
+func (checker *uniqChecker) stat() (st *UniqCheckerStat) {
+	checker.Lock()
+	defer checker.Unlock()
+	st = &UniqCheckerStat{
+		Size:     checker.inQue.len(),
+		KeepTime: checker.keepTime,
+		KeepOps:  checker.keepOps,
+		Checks:   checker.checks,
+		Hits:     checker.hits,
+	}
+	if st.Checks > 0 {
+		st.HitRate = float64(st.Hits) / float64(st.Checks)
+	}
+	if st.Size > 0 {
+		st.OldestTime = checker.inQue.index(0).atime
+	}
+	return
+}
+
+func (checker *uniqChecker) getKeep() (keepTime int64, keepOps int) {
+	checker.Lock()
+	defer checker.Unlock()
+	return checker.keepTime, checker.keepOps
+}
+
+// setKeep sets the window of the records kept in the table: the records over the latest
+// keepOps ones are evicted once older than keepTime seconds, a value not positive is kept.
+func (checker *uniqChecker) setKeep(keepTime int64, keepOps int) {
+	checker.Lock()
+	defer checker.Unlock()
+	if keepTime > 0 {
+		checker.keepTime = keepTime
+	}
+	if keepOps > 0 {
+		checker.keepOps = keepOps
+	}
+}
+
 func (checker *uniqChecker) evictIndex() (left int, idx int, op *uniqOp) {
 	checker.Lock()
 	defer checker.Unlock()
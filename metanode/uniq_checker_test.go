@@ -139,3 +139,32 @@ func TestMarshal(t *testing.T) {
 		return true
 	})
 }
+
+func TestUniqCheckerWindow(t *testing.T) {
+	checker := newUniqChecker()
+	for i := 1; i <= 10; i++ {
+		checker.legalIn(uint64(i))
+	}
+	checker.legalIn(1)
+	st := checker.stat()
+	if st.Size != 10 || st.Checks != 11 || st.Hits != 1 || st.OldestTime == 0 {
+		t.Fatalf("unexpected stat %+v", st)
+	}
+
+	// the records over keepOps are evicted once older than keepTime
+	if _, idx, _ := checker.evictIndex(); idx != -1 {
+		t.Fatalf("evict under the default window, idx %v", idx)
+	}
+	checker.setKeep(0, 4)
+	if keepTime, keepOps := checker.getKeep(); keepTime != opKeepTime || keepOps != 4 {
+		t.Fatalf("unexpected window %v %v", keepTime, keepOps)
+	}
+	checker.inQue.scan(func(op *uniqOp) bool {
+		op.atime -= opKeepTime
+		return true
+	})
+	left, idx, op := checker.evictIndex()
+	if left != 4 || idx != 5 || op.uniqid != 6 {
+		t.Fatalf("unexpected evict left %v idx %v op %v", left, idx, op)
+	}
+}
@@ -0,0 +1,6421 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: admin.proto
+
+package proto
+
+import (
+	context "context"
+	encoding_binary "encoding/binary"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type GetClusterRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetClusterRequest) Reset()         { *m = GetClusterRequest{} }
+func (m *GetClusterRequest) String() string { return proto.CompactTextString(m) }
+func (*GetClusterRequest) ProtoMessage()    {}
+func (*GetClusterRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{0}
+}
+func (m *GetClusterRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetClusterRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetClusterRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetClusterRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetClusterRequest.Merge(m, src)
+}
+func (m *GetClusterRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetClusterRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetClusterRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetClusterRequest proto.InternalMessageInfo
+
+type ClusterInfoReply struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	LeaderAddr           string   `protobuf:"bytes,2,opt,name=LeaderAddr,proto3" json:"LeaderAddr,omitempty"`
+	DisableAutoAlloc     bool     `protobuf:"varint,3,opt,name=DisableAutoAlloc,proto3" json:"DisableAutoAlloc,omitempty"`
+	ForbidMpDecommission bool     `protobuf:"varint,4,opt,name=ForbidMpDecommission,proto3" json:"ForbidMpDecommission,omitempty"`
+	MetaNodeThreshold    float32  `protobuf:"fixed32,5,opt,name=MetaNodeThreshold,proto3" json:"MetaNodeThreshold,omitempty"`
+	Applied              uint64   `protobuf:"varint,6,opt,name=Applied,proto3" json:"Applied,omitempty"`
+	MaxDataPartitionID   uint64   `protobuf:"varint,7,opt,name=MaxDataPartitionID,proto3" json:"MaxDataPartitionID,omitempty"`
+	MaxMetaPartitionID   uint64   `protobuf:"varint,8,opt,name=MaxMetaPartitionID,proto3" json:"MaxMetaPartitionID,omitempty"`
+	MaxMetaNodeID        uint64   `protobuf:"varint,9,opt,name=MaxMetaNodeID,proto3" json:"MaxMetaNodeID,omitempty"`
+	MasterCount          uint32   `protobuf:"varint,10,opt,name=MasterCount,proto3" json:"MasterCount,omitempty"`
+	DataNodeCount        uint32   `protobuf:"varint,11,opt,name=DataNodeCount,proto3" json:"DataNodeCount,omitempty"`
+	MetaNodeCount        uint32   `protobuf:"varint,12,opt,name=MetaNodeCount,proto3" json:"MetaNodeCount,omitempty"`
+	VolCount             uint32   `protobuf:"varint,13,opt,name=VolCount,proto3" json:"VolCount,omitempty"`
+	DataTotalGB          uint64   `protobuf:"varint,14,opt,name=DataTotalGB,proto3" json:"DataTotalGB,omitempty"`
+	DataUsedGB           uint64   `protobuf:"varint,15,opt,name=DataUsedGB,proto3" json:"DataUsedGB,omitempty"`
+	MetaTotalGB          uint64   `protobuf:"varint,16,opt,name=MetaTotalGB,proto3" json:"MetaTotalGB,omitempty"`
+	MetaUsedGB           uint64   `protobuf:"varint,17,opt,name=MetaUsedGB,proto3" json:"MetaUsedGB,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterInfoReply) Reset()         { *m = ClusterInfoReply{} }
+func (m *ClusterInfoReply) String() string { return proto.CompactTextString(m) }
+func (*ClusterInfoReply) ProtoMessage()    {}
+func (*ClusterInfoReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{1}
+}
+func (m *ClusterInfoReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterInfoReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterInfoReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ClusterInfoReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterInfoReply.Merge(m, src)
+}
+func (m *ClusterInfoReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterInfoReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterInfoReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterInfoReply proto.InternalMessageInfo
+
+func (m *ClusterInfoReply) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ClusterInfoReply) GetLeaderAddr() string {
+	if m != nil {
+		return m.LeaderAddr
+	}
+	return ""
+}
+
+func (m *ClusterInfoReply) GetDisableAutoAlloc() bool {
+	if m != nil {
+		return m.DisableAutoAlloc
+	}
+	return false
+}
+
+func (m *ClusterInfoReply) GetForbidMpDecommission() bool {
+	if m != nil {
+		return m.ForbidMpDecommission
+	}
+	return false
+}
+
+func (m *ClusterInfoReply) GetMetaNodeThreshold() float32 {
+	if m != nil {
+		return m.MetaNodeThreshold
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetApplied() uint64 {
+	if m != nil {
+		return m.Applied
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMaxDataPartitionID() uint64 {
+	if m != nil {
+		return m.MaxDataPartitionID
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMaxMetaPartitionID() uint64 {
+	if m != nil {
+		return m.MaxMetaPartitionID
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMaxMetaNodeID() uint64 {
+	if m != nil {
+		return m.MaxMetaNodeID
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMasterCount() uint32 {
+	if m != nil {
+		return m.MasterCount
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetDataNodeCount() uint32 {
+	if m != nil {
+		return m.DataNodeCount
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMetaNodeCount() uint32 {
+	if m != nil {
+		return m.MetaNodeCount
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetVolCount() uint32 {
+	if m != nil {
+		return m.VolCount
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetDataTotalGB() uint64 {
+	if m != nil {
+		return m.DataTotalGB
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetDataUsedGB() uint64 {
+	if m != nil {
+		return m.DataUsedGB
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMetaTotalGB() uint64 {
+	if m != nil {
+		return m.MetaTotalGB
+	}
+	return 0
+}
+
+func (m *ClusterInfoReply) GetMetaUsedGB() uint64 {
+	if m != nil {
+		return m.MetaUsedGB
+	}
+	return 0
+}
+
+type ListNodesRequest struct {
+	ZoneName             string   `protobuf:"bytes,1,opt,name=ZoneName,proto3" json:"ZoneName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListNodesRequest) Reset()         { *m = ListNodesRequest{} }
+func (m *ListNodesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListNodesRequest) ProtoMessage()    {}
+func (*ListNodesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{2}
+}
+func (m *ListNodesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListNodesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListNodesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListNodesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListNodesRequest.Merge(m, src)
+}
+func (m *ListNodesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListNodesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListNodesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListNodesRequest proto.InternalMessageInfo
+
+func (m *ListNodesRequest) GetZoneName() string {
+	if m != nil {
+		return m.ZoneName
+	}
+	return ""
+}
+
+type NodeInfoReply struct {
+	ID                   uint64   `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Addr                 string   `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	DomainAddr           string   `protobuf:"bytes,3,opt,name=DomainAddr,proto3" json:"DomainAddr,omitempty"`
+	ZoneName             string   `protobuf:"bytes,4,opt,name=ZoneName,proto3" json:"ZoneName,omitempty"`
+	NodeSetID            uint64   `protobuf:"varint,5,opt,name=NodeSetID,proto3" json:"NodeSetID,omitempty"`
+	IsActive             bool     `protobuf:"varint,6,opt,name=IsActive,proto3" json:"IsActive,omitempty"`
+	IsWritable           bool     `protobuf:"varint,7,opt,name=IsWritable,proto3" json:"IsWritable,omitempty"`
+	Total                uint64   `protobuf:"varint,8,opt,name=Total,proto3" json:"Total,omitempty"`
+	Used                 uint64   `protobuf:"varint,9,opt,name=Used,proto3" json:"Used,omitempty"`
+	PartitionCount       uint32   `protobuf:"varint,10,opt,name=PartitionCount,proto3" json:"PartitionCount,omitempty"`
+	MediaType            uint32   `protobuf:"varint,11,opt,name=MediaType,proto3" json:"MediaType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodeInfoReply) Reset()         { *m = NodeInfoReply{} }
+func (m *NodeInfoReply) String() string { return proto.CompactTextString(m) }
+func (*NodeInfoReply) ProtoMessage()    {}
+func (*NodeInfoReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{3}
+}
+func (m *NodeInfoReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NodeInfoReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NodeInfoReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NodeInfoReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeInfoReply.Merge(m, src)
+}
+func (m *NodeInfoReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *NodeInfoReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeInfoReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeInfoReply proto.InternalMessageInfo
+
+func (m *NodeInfoReply) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *NodeInfoReply) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *NodeInfoReply) GetDomainAddr() string {
+	if m != nil {
+		return m.DomainAddr
+	}
+	return ""
+}
+
+func (m *NodeInfoReply) GetZoneName() string {
+	if m != nil {
+		return m.ZoneName
+	}
+	return ""
+}
+
+func (m *NodeInfoReply) GetNodeSetID() uint64 {
+	if m != nil {
+		return m.NodeSetID
+	}
+	return 0
+}
+
+func (m *NodeInfoReply) GetIsActive() bool {
+	if m != nil {
+		return m.IsActive
+	}
+	return false
+}
+
+func (m *NodeInfoReply) GetIsWritable() bool {
+	if m != nil {
+		return m.IsWritable
+	}
+	return false
+}
+
+func (m *NodeInfoReply) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *NodeInfoReply) GetUsed() uint64 {
+	if m != nil {
+		return m.Used
+	}
+	return 0
+}
+
+func (m *NodeInfoReply) GetPartitionCount() uint32 {
+	if m != nil {
+		return m.PartitionCount
+	}
+	return 0
+}
+
+func (m *NodeInfoReply) GetMediaType() uint32 {
+	if m != nil {
+		return m.MediaType
+	}
+	return 0
+}
+
+type ListVolsRequest struct {
+	Keywords             string   `protobuf:"bytes,1,opt,name=Keywords,proto3" json:"Keywords,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListVolsRequest) Reset()         { *m = ListVolsRequest{} }
+func (m *ListVolsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListVolsRequest) ProtoMessage()    {}
+func (*ListVolsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{4}
+}
+func (m *ListVolsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListVolsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListVolsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListVolsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListVolsRequest.Merge(m, src)
+}
+func (m *ListVolsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListVolsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListVolsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListVolsRequest proto.InternalMessageInfo
+
+func (m *ListVolsRequest) GetKeywords() string {
+	if m != nil {
+		return m.Keywords
+	}
+	return ""
+}
+
+type GetVolRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVolRequest) Reset()         { *m = GetVolRequest{} }
+func (m *GetVolRequest) String() string { return proto.CompactTextString(m) }
+func (*GetVolRequest) ProtoMessage()    {}
+func (*GetVolRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{5}
+}
+func (m *GetVolRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetVolRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetVolRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetVolRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVolRequest.Merge(m, src)
+}
+func (m *GetVolRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetVolRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVolRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVolRequest proto.InternalMessageInfo
+
+func (m *GetVolRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type VolInfoReply struct {
+	ID                   uint64   `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	Owner                string   `protobuf:"bytes,3,opt,name=Owner,proto3" json:"Owner,omitempty"`
+	ZoneName             string   `protobuf:"bytes,4,opt,name=ZoneName,proto3" json:"ZoneName,omitempty"`
+	Status               uint32   `protobuf:"varint,5,opt,name=Status,proto3" json:"Status,omitempty"`
+	VolType              uint32   `protobuf:"varint,6,opt,name=VolType,proto3" json:"VolType,omitempty"`
+	Capacity             uint64   `protobuf:"varint,7,opt,name=Capacity,proto3" json:"Capacity,omitempty"`
+	TotalSize            uint64   `protobuf:"varint,8,opt,name=TotalSize,proto3" json:"TotalSize,omitempty"`
+	UsedSize             uint64   `protobuf:"varint,9,opt,name=UsedSize,proto3" json:"UsedSize,omitempty"`
+	DpReplicaNum         uint32   `protobuf:"varint,10,opt,name=DpReplicaNum,proto3" json:"DpReplicaNum,omitempty"`
+	MpReplicaNum         uint32   `protobuf:"varint,11,opt,name=MpReplicaNum,proto3" json:"MpReplicaNum,omitempty"`
+	DpCount              uint32   `protobuf:"varint,12,opt,name=DpCount,proto3" json:"DpCount,omitempty"`
+	RwDpCount            uint32   `protobuf:"varint,13,opt,name=RwDpCount,proto3" json:"RwDpCount,omitempty"`
+	MpCount              uint32   `protobuf:"varint,14,opt,name=MpCount,proto3" json:"MpCount,omitempty"`
+	CreateTime           int64    `protobuf:"varint,15,opt,name=CreateTime,proto3" json:"CreateTime,omitempty"`
+	CrossZone            bool     `protobuf:"varint,16,opt,name=CrossZone,proto3" json:"CrossZone,omitempty"`
+	FollowerRead         bool     `protobuf:"varint,17,opt,name=FollowerRead,proto3" json:"FollowerRead,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VolInfoReply) Reset()         { *m = VolInfoReply{} }
+func (m *VolInfoReply) String() string { return proto.CompactTextString(m) }
+func (*VolInfoReply) ProtoMessage()    {}
+func (*VolInfoReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{6}
+}
+func (m *VolInfoReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *VolInfoReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_VolInfoReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *VolInfoReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VolInfoReply.Merge(m, src)
+}
+func (m *VolInfoReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *VolInfoReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_VolInfoReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VolInfoReply proto.InternalMessageInfo
+
+func (m *VolInfoReply) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *VolInfoReply) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *VolInfoReply) GetZoneName() string {
+	if m != nil {
+		return m.ZoneName
+	}
+	return ""
+}
+
+func (m *VolInfoReply) GetStatus() uint32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetVolType() uint32 {
+	if m != nil {
+		return m.VolType
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetCapacity() uint64 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetTotalSize() uint64 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetUsedSize() uint64 {
+	if m != nil {
+		return m.UsedSize
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetDpReplicaNum() uint32 {
+	if m != nil {
+		return m.DpReplicaNum
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetMpReplicaNum() uint32 {
+	if m != nil {
+		return m.MpReplicaNum
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetDpCount() uint32 {
+	if m != nil {
+		return m.DpCount
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetRwDpCount() uint32 {
+	if m != nil {
+		return m.RwDpCount
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetMpCount() uint32 {
+	if m != nil {
+		return m.MpCount
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetCreateTime() int64 {
+	if m != nil {
+		return m.CreateTime
+	}
+	return 0
+}
+
+func (m *VolInfoReply) GetCrossZone() bool {
+	if m != nil {
+		return m.CrossZone
+	}
+	return false
+}
+
+func (m *VolInfoReply) GetFollowerRead() bool {
+	if m != nil {
+		return m.FollowerRead
+	}
+	return false
+}
+
+type CreateVolRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=Owner,proto3" json:"Owner,omitempty"`
+	Capacity             uint64   `protobuf:"varint,3,opt,name=Capacity,proto3" json:"Capacity,omitempty"`
+	ZoneName             string   `protobuf:"bytes,4,opt,name=ZoneName,proto3" json:"ZoneName,omitempty"`
+	VolType              uint32   `protobuf:"varint,5,opt,name=VolType,proto3" json:"VolType,omitempty"`
+	DpReplicaNum         uint32   `protobuf:"varint,6,opt,name=DpReplicaNum,proto3" json:"DpReplicaNum,omitempty"`
+	MpCount              uint32   `protobuf:"varint,7,opt,name=MpCount,proto3" json:"MpCount,omitempty"`
+	DpSize               uint64   `protobuf:"varint,8,opt,name=DpSize,proto3" json:"DpSize,omitempty"`
+	CrossZone            bool     `protobuf:"varint,9,opt,name=CrossZone,proto3" json:"CrossZone,omitempty"`
+	FollowerRead         bool     `protobuf:"varint,10,opt,name=FollowerRead,proto3" json:"FollowerRead,omitempty"`
+	Description          string   `protobuf:"bytes,11,opt,name=Description,proto3" json:"Description,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateVolRequest) Reset()         { *m = CreateVolRequest{} }
+func (m *CreateVolRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateVolRequest) ProtoMessage()    {}
+func (*CreateVolRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{7}
+}
+func (m *CreateVolRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CreateVolRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CreateVolRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CreateVolRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateVolRequest.Merge(m, src)
+}
+func (m *CreateVolRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CreateVolRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateVolRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateVolRequest proto.InternalMessageInfo
+
+func (m *CreateVolRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateVolRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *CreateVolRequest) GetCapacity() uint64 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *CreateVolRequest) GetZoneName() string {
+	if m != nil {
+		return m.ZoneName
+	}
+	return ""
+}
+
+func (m *CreateVolRequest) GetVolType() uint32 {
+	if m != nil {
+		return m.VolType
+	}
+	return 0
+}
+
+func (m *CreateVolRequest) GetDpReplicaNum() uint32 {
+	if m != nil {
+		return m.DpReplicaNum
+	}
+	return 0
+}
+
+func (m *CreateVolRequest) GetMpCount() uint32 {
+	if m != nil {
+		return m.MpCount
+	}
+	return 0
+}
+
+func (m *CreateVolRequest) GetDpSize() uint64 {
+	if m != nil {
+		return m.DpSize
+	}
+	return 0
+}
+
+func (m *CreateVolRequest) GetCrossZone() bool {
+	if m != nil {
+		return m.CrossZone
+	}
+	return false
+}
+
+func (m *CreateVolRequest) GetFollowerRead() bool {
+	if m != nil {
+		return m.FollowerRead
+	}
+	return false
+}
+
+func (m *CreateVolRequest) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type DeleteVolRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	AuthKey              string   `protobuf:"bytes,2,opt,name=AuthKey,proto3" json:"AuthKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteVolRequest) Reset()         { *m = DeleteVolRequest{} }
+func (m *DeleteVolRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteVolRequest) ProtoMessage()    {}
+func (*DeleteVolRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{8}
+}
+func (m *DeleteVolRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DeleteVolRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DeleteVolRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DeleteVolRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteVolRequest.Merge(m, src)
+}
+func (m *DeleteVolRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DeleteVolRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteVolRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteVolRequest proto.InternalMessageInfo
+
+func (m *DeleteVolRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DeleteVolRequest) GetAuthKey() string {
+	if m != nil {
+		return m.AuthKey
+	}
+	return ""
+}
+
+type AdminOpReply struct {
+	Msg                  string   `protobuf:"bytes,1,opt,name=Msg,proto3" json:"Msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AdminOpReply) Reset()         { *m = AdminOpReply{} }
+func (m *AdminOpReply) String() string { return proto.CompactTextString(m) }
+func (*AdminOpReply) ProtoMessage()    {}
+func (*AdminOpReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{9}
+}
+func (m *AdminOpReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdminOpReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminOpReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AdminOpReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminOpReply.Merge(m, src)
+}
+func (m *AdminOpReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminOpReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminOpReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminOpReply proto.InternalMessageInfo
+
+func (m *AdminOpReply) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+type ListPartitionsRequest struct {
+	VolName              string   `protobuf:"bytes,1,opt,name=VolName,proto3" json:"VolName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListPartitionsRequest) Reset()         { *m = ListPartitionsRequest{} }
+func (m *ListPartitionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPartitionsRequest) ProtoMessage()    {}
+func (*ListPartitionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{10}
+}
+func (m *ListPartitionsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListPartitionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListPartitionsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListPartitionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPartitionsRequest.Merge(m, src)
+}
+func (m *ListPartitionsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListPartitionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPartitionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPartitionsRequest proto.InternalMessageInfo
+
+func (m *ListPartitionsRequest) GetVolName() string {
+	if m != nil {
+		return m.VolName
+	}
+	return ""
+}
+
+type GetPartitionRequest struct {
+	PartitionID          uint64   `protobuf:"varint,1,opt,name=PartitionID,proto3" json:"PartitionID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPartitionRequest) Reset()         { *m = GetPartitionRequest{} }
+func (m *GetPartitionRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPartitionRequest) ProtoMessage()    {}
+func (*GetPartitionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{11}
+}
+func (m *GetPartitionRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetPartitionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetPartitionRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetPartitionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPartitionRequest.Merge(m, src)
+}
+func (m *GetPartitionRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetPartitionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPartitionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPartitionRequest proto.InternalMessageInfo
+
+func (m *GetPartitionRequest) GetPartitionID() uint64 {
+	if m != nil {
+		return m.PartitionID
+	}
+	return 0
+}
+
+type DecommissionPartitionRequest struct {
+	PartitionID          uint64   `protobuf:"varint,1,opt,name=PartitionID,proto3" json:"PartitionID,omitempty"`
+	Addr                 string   `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecommissionPartitionRequest) Reset()         { *m = DecommissionPartitionRequest{} }
+func (m *DecommissionPartitionRequest) String() string { return proto.CompactTextString(m) }
+func (*DecommissionPartitionRequest) ProtoMessage()    {}
+func (*DecommissionPartitionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{12}
+}
+func (m *DecommissionPartitionRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DecommissionPartitionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DecommissionPartitionRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DecommissionPartitionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecommissionPartitionRequest.Merge(m, src)
+}
+func (m *DecommissionPartitionRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DecommissionPartitionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecommissionPartitionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecommissionPartitionRequest proto.InternalMessageInfo
+
+func (m *DecommissionPartitionRequest) GetPartitionID() uint64 {
+	if m != nil {
+		return m.PartitionID
+	}
+	return 0
+}
+
+func (m *DecommissionPartitionRequest) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+type DataPartitionReply struct {
+	PartitionID          uint64   `protobuf:"varint,1,opt,name=PartitionID,proto3" json:"PartitionID,omitempty"`
+	VolName              string   `protobuf:"bytes,2,opt,name=VolName,proto3" json:"VolName,omitempty"`
+	Status               int32    `protobuf:"varint,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	ReplicaNum           uint32   `protobuf:"varint,4,opt,name=ReplicaNum,proto3" json:"ReplicaNum,omitempty"`
+	Hosts                []string `protobuf:"bytes,5,rep,name=Hosts,proto3" json:"Hosts,omitempty"`
+	LeaderAddr           string   `protobuf:"bytes,6,opt,name=LeaderAddr,proto3" json:"LeaderAddr,omitempty"`
+	Total                uint64   `protobuf:"varint,7,opt,name=Total,proto3" json:"Total,omitempty"`
+	Used                 uint64   `protobuf:"varint,8,opt,name=Used,proto3" json:"Used,omitempty"`
+	IsRecover            bool     `protobuf:"varint,9,opt,name=IsRecover,proto3" json:"IsRecover,omitempty"`
+	IsDiscard            bool     `protobuf:"varint,10,opt,name=IsDiscard,proto3" json:"IsDiscard,omitempty"`
+	MediaType            uint32   `protobuf:"varint,11,opt,name=MediaType,proto3" json:"MediaType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DataPartitionReply) Reset()         { *m = DataPartitionReply{} }
+func (m *DataPartitionReply) String() string { return proto.CompactTextString(m) }
+func (*DataPartitionReply) ProtoMessage()    {}
+func (*DataPartitionReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{13}
+}
+func (m *DataPartitionReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DataPartitionReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DataPartitionReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DataPartitionReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DataPartitionReply.Merge(m, src)
+}
+func (m *DataPartitionReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *DataPartitionReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_DataPartitionReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DataPartitionReply proto.InternalMessageInfo
+
+func (m *DataPartitionReply) GetPartitionID() uint64 {
+	if m != nil {
+		return m.PartitionID
+	}
+	return 0
+}
+
+func (m *DataPartitionReply) GetVolName() string {
+	if m != nil {
+		return m.VolName
+	}
+	return ""
+}
+
+func (m *DataPartitionReply) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *DataPartitionReply) GetReplicaNum() uint32 {
+	if m != nil {
+		return m.ReplicaNum
+	}
+	return 0
+}
+
+func (m *DataPartitionReply) GetHosts() []string {
+	if m != nil {
+		return m.Hosts
+	}
+	return nil
+}
+
+func (m *DataPartitionReply) GetLeaderAddr() string {
+	if m != nil {
+		return m.LeaderAddr
+	}
+	return ""
+}
+
+func (m *DataPartitionReply) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *DataPartitionReply) GetUsed() uint64 {
+	if m != nil {
+		return m.Used
+	}
+	return 0
+}
+
+func (m *DataPartitionReply) GetIsRecover() bool {
+	if m != nil {
+		return m.IsRecover
+	}
+	return false
+}
+
+func (m *DataPartitionReply) GetIsDiscard() bool {
+	if m != nil {
+		return m.IsDiscard
+	}
+	return false
+}
+
+func (m *DataPartitionReply) GetMediaType() uint32 {
+	if m != nil {
+		return m.MediaType
+	}
+	return 0
+}
+
+type MetaPartitionReply struct {
+	PartitionID          uint64   `protobuf:"varint,1,opt,name=PartitionID,proto3" json:"PartitionID,omitempty"`
+	VolName              string   `protobuf:"bytes,2,opt,name=VolName,proto3" json:"VolName,omitempty"`
+	Status               int32    `protobuf:"varint,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	ReplicaNum           uint32   `protobuf:"varint,4,opt,name=ReplicaNum,proto3" json:"ReplicaNum,omitempty"`
+	Hosts                []string `protobuf:"bytes,5,rep,name=Hosts,proto3" json:"Hosts,omitempty"`
+	LeaderAddr           string   `protobuf:"bytes,6,opt,name=LeaderAddr,proto3" json:"LeaderAddr,omitempty"`
+	Start                uint64   `protobuf:"varint,7,opt,name=Start,proto3" json:"Start,omitempty"`
+	End                  uint64   `protobuf:"varint,8,opt,name=End,proto3" json:"End,omitempty"`
+	MaxInodeID           uint64   `protobuf:"varint,9,opt,name=MaxInodeID,proto3" json:"MaxInodeID,omitempty"`
+	InodeCount           uint64   `protobuf:"varint,10,opt,name=InodeCount,proto3" json:"InodeCount,omitempty"`
+	DentryCount          uint64   `protobuf:"varint,11,opt,name=DentryCount,proto3" json:"DentryCount,omitempty"`
+	IsRecover            bool     `protobuf:"varint,12,opt,name=IsRecover,proto3" json:"IsRecover,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MetaPartitionReply) Reset()         { *m = MetaPartitionReply{} }
+func (m *MetaPartitionReply) String() string { return proto.CompactTextString(m) }
+func (*MetaPartitionReply) ProtoMessage()    {}
+func (*MetaPartitionReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{14}
+}
+func (m *MetaPartitionReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetaPartitionReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetaPartitionReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetaPartitionReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetaPartitionReply.Merge(m, src)
+}
+func (m *MetaPartitionReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetaPartitionReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetaPartitionReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetaPartitionReply proto.InternalMessageInfo
+
+func (m *MetaPartitionReply) GetPartitionID() uint64 {
+	if m != nil {
+		return m.PartitionID
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetVolName() string {
+	if m != nil {
+		return m.VolName
+	}
+	return ""
+}
+
+func (m *MetaPartitionReply) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetReplicaNum() uint32 {
+	if m != nil {
+		return m.ReplicaNum
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetHosts() []string {
+	if m != nil {
+		return m.Hosts
+	}
+	return nil
+}
+
+func (m *MetaPartitionReply) GetLeaderAddr() string {
+	if m != nil {
+		return m.LeaderAddr
+	}
+	return ""
+}
+
+func (m *MetaPartitionReply) GetStart() uint64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetEnd() uint64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetMaxInodeID() uint64 {
+	if m != nil {
+		return m.MaxInodeID
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetInodeCount() uint64 {
+	if m != nil {
+		return m.InodeCount
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetDentryCount() uint64 {
+	if m != nil {
+		return m.DentryCount
+	}
+	return 0
+}
+
+func (m *MetaPartitionReply) GetIsRecover() bool {
+	if m != nil {
+		return m.IsRecover
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*GetClusterRequest)(nil), "proto.GetClusterRequest")
+	proto.RegisterType((*ClusterInfoReply)(nil), "proto.ClusterInfoReply")
+	proto.RegisterType((*ListNodesRequest)(nil), "proto.ListNodesRequest")
+	proto.RegisterType((*NodeInfoReply)(nil), "proto.NodeInfoReply")
+	proto.RegisterType((*ListVolsRequest)(nil), "proto.ListVolsRequest")
+	proto.RegisterType((*GetVolRequest)(nil), "proto.GetVolRequest")
+	proto.RegisterType((*VolInfoReply)(nil), "proto.VolInfoReply")
+	proto.RegisterType((*CreateVolRequest)(nil), "proto.CreateVolRequest")
+	proto.RegisterType((*DeleteVolRequest)(nil), "proto.DeleteVolRequest")
+	proto.RegisterType((*AdminOpReply)(nil), "proto.AdminOpReply")
+	proto.RegisterType((*ListPartitionsRequest)(nil), "proto.ListPartitionsRequest")
+	proto.RegisterType((*GetPartitionRequest)(nil), "proto.GetPartitionRequest")
+	proto.RegisterType((*DecommissionPartitionRequest)(nil), "proto.DecommissionPartitionRequest")
+	proto.RegisterType((*DataPartitionReply)(nil), "proto.DataPartitionReply")
+	proto.RegisterType((*MetaPartitionReply)(nil), "proto.MetaPartitionReply")
+}
+
+func init() { proto.RegisterFile("admin.proto", fileDescriptor_73a7fc70dcc2027c) }
+
+var fileDescriptor_73a7fc70dcc2027c = []byte{
+	// 1238 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x56, 0xcd, 0x6e, 0xdb, 0xc6,
+	0x13, 0xff, 0x53, 0x5f, 0x96, 0xc6, 0x96, 0xa3, 0x6c, 0xfc, 0x4f, 0x19, 0xc1, 0x10, 0x04, 0xa6,
+	0x28, 0x8c, 0xa2, 0x35, 0xd2, 0xe4, 0x50, 0xe4, 0xd4, 0x28, 0x66, 0xa3, 0x0a, 0x09, 0xe3, 0x82,
+	0x76, 0x5c, 0xa0, 0xb7, 0xb5, 0x38, 0xad, 0x09, 0x50, 0x5c, 0x96, 0x5c, 0xc5, 0x51, 0x8f, 0x7d,
+	0x8a, 0x9e, 0xfa, 0x1e, 0xbd, 0xf6, 0x94, 0x63, 0x1f, 0xa1, 0x70, 0x81, 0xa2, 0x8f, 0x51, 0xcc,
+	0xf2, 0x6b, 0x29, 0xc9, 0x32, 0x12, 0xf4, 0xd2, 0x13, 0x39, 0xbf, 0xf9, 0xd8, 0x9d, 0xcf, 0x1d,
+	0xd8, 0xe6, 0xde, 0xcc, 0x0f, 0x0f, 0xa3, 0x58, 0x48, 0xc1, 0x9a, 0xea, 0x63, 0xdd, 0x81, 0xdb,
+	0x63, 0x94, 0x47, 0xc1, 0x3c, 0x91, 0x18, 0xbb, 0xf8, 0xc3, 0x1c, 0x13, 0x69, 0xfd, 0xd4, 0x84,
+	0x5e, 0x06, 0x4d, 0xc2, 0xef, 0x84, 0x8b, 0x51, 0xb0, 0x60, 0x0c, 0x1a, 0x2f, 0xf9, 0x0c, 0x4d,
+	0x63, 0x68, 0x1c, 0x74, 0x5c, 0xf5, 0xcf, 0x06, 0x00, 0x2f, 0x90, 0x7b, 0x18, 0x8f, 0x3c, 0x2f,
+	0x36, 0x6b, 0x8a, 0xa3, 0x21, 0xec, 0x63, 0xe8, 0xd9, 0x7e, 0xc2, 0xcf, 0x03, 0x1c, 0xcd, 0xa5,
+	0x18, 0x05, 0x81, 0x98, 0x9a, 0xf5, 0xa1, 0x71, 0xd0, 0x76, 0x57, 0x70, 0xf6, 0x10, 0xf6, 0x9e,
+	0x89, 0xf8, 0xdc, 0xf7, 0x9c, 0xc8, 0xc6, 0xa9, 0x98, 0xcd, 0xfc, 0x24, 0xf1, 0x45, 0x68, 0x36,
+	0x94, 0xfc, 0x5a, 0x1e, 0xfb, 0x04, 0x6e, 0x3b, 0x28, 0xf9, 0x4b, 0xe1, 0xe1, 0xe9, 0x45, 0x8c,
+	0xc9, 0x85, 0x08, 0x3c, 0xb3, 0x39, 0x34, 0x0e, 0x6a, 0xee, 0x2a, 0x83, 0x99, 0xb0, 0x35, 0x8a,
+	0xa2, 0xc0, 0x47, 0xcf, 0x6c, 0x0d, 0x8d, 0x83, 0x86, 0x9b, 0x93, 0xec, 0x10, 0x98, 0xc3, 0xdf,
+	0xd8, 0x5c, 0xf2, 0xaf, 0x79, 0x2c, 0x7d, 0xe9, 0x8b, 0x70, 0x62, 0x9b, 0x5b, 0x4a, 0x68, 0x0d,
+	0x27, 0x93, 0x77, 0xb0, 0x82, 0x9a, 0xed, 0x42, 0x7e, 0x89, 0xc3, 0x3e, 0x84, 0x6e, 0x86, 0xd2,
+	0x8d, 0x26, 0xb6, 0xd9, 0x51, 0xa2, 0x55, 0x90, 0x0d, 0x61, 0xdb, 0xe1, 0x14, 0xf4, 0x23, 0x31,
+	0x0f, 0xa5, 0x09, 0x43, 0xe3, 0xa0, 0xeb, 0xea, 0x10, 0xd9, 0xb1, 0x79, 0x2a, 0x9f, 0xca, 0x6c,
+	0x2b, 0x99, 0x2a, 0xa8, 0x4e, 0x43, 0x5d, 0x6a, 0x27, 0x95, 0xaa, 0x80, 0xac, 0x0f, 0xed, 0x33,
+	0x11, 0xa4, 0x02, 0x5d, 0x25, 0x50, 0xd0, 0x74, 0x13, 0x32, 0x79, 0x2a, 0x24, 0x0f, 0xc6, 0x4f,
+	0xcd, 0x5d, 0x75, 0x5b, 0x1d, 0xa2, 0xcc, 0x13, 0xf9, 0x2a, 0x41, 0x6f, 0xfc, 0xd4, 0xbc, 0xa5,
+	0x04, 0x34, 0x44, 0xf9, 0x82, 0xa5, 0x85, 0x5e, 0x6a, 0xc1, 0xc1, 0x8a, 0x05, 0x07, 0x73, 0x79,
+	0xf3, 0x76, 0x6a, 0xa1, 0x44, 0xac, 0x43, 0xe8, 0xbd, 0xf0, 0x13, 0x49, 0x17, 0x4e, 0xb2, 0xc2,
+	0xa4, 0x3b, 0x7f, 0x2b, 0x42, 0xd4, 0xea, 0xb0, 0xa0, 0xad, 0x5f, 0x6b, 0xd0, 0x55, 0x81, 0x2c,
+	0x2a, 0x76, 0x17, 0x6a, 0x13, 0x5b, 0xc9, 0x35, 0xdc, 0xda, 0xc4, 0xa6, 0x0a, 0xd6, 0xea, 0x54,
+	0xfd, 0x2b, 0x3f, 0xc4, 0x8c, 0xfb, 0xa1, 0xe2, 0xd4, 0x15, 0x47, 0x43, 0x2a, 0x27, 0x36, 0xaa,
+	0x27, 0xb2, 0x7d, 0xe8, 0xd0, 0x81, 0x27, 0x28, 0x27, 0xb6, 0xaa, 0xba, 0x86, 0x5b, 0x02, 0xa4,
+	0x39, 0x49, 0x46, 0x53, 0xe9, 0xbf, 0x46, 0x55, 0x6e, 0x6d, 0xb7, 0xa0, 0xe9, 0xd4, 0x49, 0xf2,
+	0x4d, 0xec, 0x4b, 0x6a, 0x01, 0x55, 0x67, 0x6d, 0x57, 0x43, 0xd8, 0x1e, 0x34, 0x55, 0x98, 0xb2,
+	0x92, 0x4a, 0x09, 0xba, 0x3f, 0xc5, 0x26, 0x2b, 0x1e, 0xf5, 0xcf, 0x3e, 0x82, 0xdd, 0xa2, 0xd0,
+	0xf4, 0xb2, 0x59, 0x42, 0xe9, 0xae, 0x0e, 0x7a, 0x3e, 0x3f, 0x5d, 0x44, 0x98, 0x55, 0x4d, 0x09,
+	0x58, 0x9f, 0xc2, 0x2d, 0x8a, 0xf5, 0x99, 0x08, 0xf4, 0x50, 0x3f, 0xc7, 0xc5, 0xa5, 0x88, 0xbd,
+	0x24, 0x0f, 0x75, 0x4e, 0x5b, 0xf7, 0xa1, 0x3b, 0x46, 0x92, 0xce, 0x85, 0xd7, 0xcc, 0x06, 0xeb,
+	0xef, 0x3a, 0xec, 0x9c, 0x89, 0x60, 0x63, 0x3a, 0x94, 0x52, 0xad, 0x54, 0x22, 0xc7, 0x8f, 0x2f,
+	0x43, 0xcc, 0x33, 0x91, 0x12, 0x1b, 0x93, 0x70, 0x17, 0x5a, 0x27, 0x92, 0xcb, 0x79, 0xa2, 0x32,
+	0xd0, 0x75, 0x33, 0x8a, 0x9a, 0xfd, 0x4c, 0x04, 0xca, 0xdd, 0x96, 0x62, 0xe4, 0x24, 0x59, 0x3b,
+	0xe2, 0x11, 0x9f, 0xfa, 0x72, 0x91, 0xb5, 0x78, 0x41, 0x53, 0x98, 0x54, 0xac, 0x4f, 0xfc, 0x1f,
+	0x31, 0x0b, 0x7e, 0x09, 0x90, 0x26, 0x05, 0x5d, 0x31, 0xd3, 0x24, 0x14, 0x34, 0xb3, 0x60, 0xc7,
+	0x8e, 0xc8, 0x51, 0x7f, 0xca, 0x5f, 0xce, 0x67, 0x59, 0x1a, 0x2a, 0x18, 0xc9, 0x38, 0xba, 0x4c,
+	0x9a, 0x87, 0x0a, 0x46, 0xf7, 0xb6, 0x23, 0xbd, 0x6d, 0x73, 0x92, 0xee, 0xe6, 0x5e, 0xda, 0x91,
+	0xde, 0xb1, 0x25, 0x40, 0x7a, 0x4e, 0xc6, 0xdb, 0x4d, 0xf5, 0x32, 0x92, 0x8a, 0xed, 0x28, 0x46,
+	0x2e, 0xf1, 0xd4, 0x9f, 0xa1, 0x6a, 0xd5, 0xba, 0xab, 0x21, 0x64, 0xf7, 0x28, 0x16, 0x49, 0x42,
+	0x21, 0x55, 0x8d, 0xda, 0x76, 0x4b, 0x80, 0xee, 0xfc, 0x4c, 0x04, 0x81, 0xb8, 0xa4, 0xe7, 0x81,
+	0x7b, 0xaa, 0x51, 0xdb, 0x6e, 0x05, 0xb3, 0xde, 0xd6, 0xa0, 0x97, 0x1a, 0xdc, 0x5c, 0x13, 0x65,
+	0x7a, 0x6b, 0x4b, 0xe9, 0x2d, 0x12, 0x52, 0x5f, 0x4a, 0xc8, 0xa6, 0xd4, 0x6b, 0x29, 0x6e, 0x56,
+	0x53, 0xbc, 0x9c, 0x8c, 0xd6, 0x9a, 0x64, 0x68, 0x01, 0xdb, 0xaa, 0x06, 0xec, 0x2e, 0xb4, 0xec,
+	0x48, 0xab, 0x80, 0x8c, 0xaa, 0x06, 0xaa, 0x73, 0x53, 0xa0, 0x60, 0x35, 0x50, 0x6a, 0xae, 0x62,
+	0x32, 0x8d, 0xfd, 0x88, 0x3a, 0x53, 0xe5, 0xbf, 0xe3, 0xea, 0x90, 0xf5, 0x04, 0x7a, 0x36, 0x06,
+	0x78, 0x63, 0x24, 0xe9, 0x2d, 0x9b, 0xcb, 0x8b, 0xe7, 0xb8, 0xc8, 0x62, 0x99, 0x93, 0xd6, 0x10,
+	0x76, 0x46, 0xf4, 0xce, 0x1f, 0x47, 0x69, 0xdb, 0xf5, 0xa0, 0xee, 0x24, 0xdf, 0x67, 0xca, 0xf4,
+	0x6b, 0x7d, 0x06, 0xff, 0xa7, 0x6e, 0x2f, 0x26, 0x44, 0xd1, 0xf3, 0x69, 0x40, 0xb5, 0xb3, 0x72,
+	0xd2, 0xfa, 0x1c, 0xee, 0x8c, 0xb1, 0xd4, 0xc8, 0x15, 0x86, 0xb0, 0xad, 0x3f, 0x80, 0x69, 0x6f,
+	0xeb, 0x90, 0x75, 0x0a, 0xfb, 0xfa, 0x8b, 0xfd, 0xee, 0x16, 0xd6, 0x4d, 0x6d, 0xeb, 0xb7, 0x1a,
+	0xb0, 0xca, 0x9b, 0x9c, 0xba, 0x7a, 0xb3, 0x31, 0xcd, 0xc3, 0x5a, 0xc5, 0x43, 0x6d, 0x8e, 0x50,
+	0x09, 0x36, 0x8b, 0x39, 0x32, 0x00, 0xd0, 0x0a, 0xa9, 0xa1, 0x2a, 0x45, 0x43, 0xa8, 0xa4, 0xbf,
+	0x12, 0x89, 0xa4, 0xf1, 0x53, 0xa7, 0x92, 0x56, 0xc4, 0xd2, 0x62, 0xd4, 0x5a, 0x59, 0x8c, 0x8a,
+	0x01, 0xbf, 0xb5, 0x6e, 0xc0, 0xb7, 0xb5, 0x01, 0xbf, 0x0f, 0x9d, 0x49, 0xe2, 0xe2, 0x54, 0xbc,
+	0xc6, 0x38, 0x2f, 0xba, 0x02, 0x48, 0xb9, 0xb6, 0x9f, 0x4c, 0x79, 0x9c, 0x57, 0x5c, 0x09, 0xdc,
+	0x30, 0xf4, 0xff, 0xaa, 0x01, 0xab, 0x2c, 0x2a, 0xff, 0xc1, 0x20, 0x9e, 0x48, 0x1e, 0xcb, 0x3c,
+	0x88, 0x8a, 0xa0, 0x7a, 0xff, 0x32, 0xcc, 0x63, 0x48, 0xbf, 0x6a, 0xd3, 0xe0, 0x6f, 0x26, 0xa1,
+	0xbe, 0x7a, 0x69, 0x88, 0x7a, 0x8d, 0xc3, 0x62, 0x59, 0x82, 0x94, 0x5f, 0x22, 0x69, 0xd7, 0x86,
+	0x32, 0x5e, 0x94, 0x3b, 0x57, 0xc3, 0xd5, 0xa1, 0x6a, 0x92, 0x76, 0x96, 0x92, 0xf4, 0xf0, 0x97,
+	0xad, 0x7c, 0xb1, 0x53, 0x8d, 0xc9, 0xbe, 0x00, 0x28, 0x77, 0x6e, 0x66, 0xa6, 0x0b, 0xf9, 0xe1,
+	0xca, 0x1a, 0xde, 0xff, 0x20, 0xe3, 0xac, 0xac, 0xe2, 0x4f, 0xa0, 0x4b, 0x0d, 0x9c, 0x6f, 0x7d,
+	0x09, 0xcb, 0x25, 0x97, 0x17, 0xa6, 0xfe, 0x5e, 0xc6, 0xa8, 0x2c, 0x46, 0x0f, 0x8c, 0xdc, 0x82,
+	0x83, 0xef, 0x6d, 0xe1, 0x31, 0xb4, 0xf3, 0x95, 0x81, 0xdd, 0xd5, 0x94, 0xb5, 0x1d, 0xa2, 0x7f,
+	0x27, 0xc3, 0xf5, 0x35, 0xe0, 0x81, 0xc1, 0x1e, 0x41, 0x2b, 0x5d, 0x1f, 0xd8, 0x5e, 0xe9, 0x7b,
+	0x39, 0xef, 0xd6, 0xaa, 0xb1, 0xc7, 0xd0, 0x29, 0x9e, 0x98, 0xe2, 0xb6, 0xcb, 0x8f, 0xce, 0xb5,
+	0xaa, 0xc5, 0x4c, 0x2d, 0x54, 0x97, 0xa7, 0x6c, 0xa1, 0x5a, 0x19, 0x9e, 0xc7, 0xc0, 0xf2, 0x48,
+	0x97, 0xe3, 0x92, 0xed, 0x6b, 0xfe, 0xae, 0x4c, 0xd1, 0xfe, 0xbd, 0xfc, 0x84, 0x95, 0x01, 0xf5,
+	0xc0, 0x60, 0x13, 0xe8, 0x8d, 0xb1, 0x6a, 0x8f, 0xf5, 0xcb, 0x28, 0x2c, 0xcf, 0xc7, 0x0d, 0xc6,
+	0xd8, 0x2b, 0xb8, 0xa7, 0x8f, 0xd6, 0xaa, 0xcd, 0xfb, 0x85, 0x9b, 0xd7, 0x0f, 0xdf, 0x8d, 0x2e,
+	0x3b, 0xf8, 0x1e, 0x2e, 0x3b, 0x78, 0xad, 0xcb, 0x0e, 0xbe, 0xab, 0xcb, 0x0e, 0xde, 0xe4, 0xb2,
+	0x83, 0xff, 0x8a, 0xcb, 0x4f, 0x7b, 0x6f, 0xaf, 0x06, 0xc6, 0xef, 0x57, 0x03, 0xe3, 0x8f, 0xab,
+	0x81, 0xf1, 0xf3, 0x9f, 0x83, 0xff, 0x9d, 0xb7, 0x94, 0xd4, 0xa3, 0x7f, 0x06, 0x00, 0x8f, 0xf5,
+	0x22, 0x2c, 0x33, 0x0f, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// MasterAdminClient is the client API for MasterAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MasterAdminClient interface {
+	GetCluster(ctx context.Context, in *GetClusterRequest, opts ...grpc.CallOption) (*ClusterInfoReply, error)
+	ListDataNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (MasterAdmin_ListDataNodesClient, error)
+	ListMetaNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (MasterAdmin_ListMetaNodesClient, error)
+	ListVols(ctx context.Context, in *ListVolsRequest, opts ...grpc.CallOption) (MasterAdmin_ListVolsClient, error)
+	GetVol(ctx context.Context, in *GetVolRequest, opts ...grpc.CallOption) (*VolInfoReply, error)
+	CreateVol(ctx context.Context, in *CreateVolRequest, opts ...grpc.CallOption) (*VolInfoReply, error)
+	DeleteVol(ctx context.Context, in *DeleteVolRequest, opts ...grpc.CallOption) (*AdminOpReply, error)
+	ListDataPartitions(ctx context.Context, in *ListPartitionsRequest, opts ...grpc.CallOption) (MasterAdmin_ListDataPartitionsClient, error)
+	GetDataPartition(ctx context.Context, in *GetPartitionRequest, opts ...grpc.CallOption) (*DataPartitionReply, error)
+	DecommissionDataPartition(ctx context.Context, in *DecommissionPartitionRequest, opts ...grpc.CallOption) (*AdminOpReply, error)
+	ListMetaPartitions(ctx context.Context, in *ListPartitionsRequest, opts ...grpc.CallOption) (MasterAdmin_ListMetaPartitionsClient, error)
+	GetMetaPartition(ctx context.Context, in *GetPartitionRequest, opts ...grpc.CallOption) (*MetaPartitionReply, error)
+	DecommissionMetaPartition(ctx context.Context, in *DecommissionPartitionRequest, opts ...grpc.CallOption) (*AdminOpReply, error)
+}
+
+type masterAdminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMasterAdminClient(cc *grpc.ClientConn) MasterAdminClient {
+	return &masterAdminClient{cc}
+}
+
+func (c *masterAdminClient) GetCluster(ctx context.Context, in *GetClusterRequest, opts ...grpc.CallOption) (*ClusterInfoReply, error) {
+	out := new(ClusterInfoReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/GetCluster", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) ListDataNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (MasterAdmin_ListDataNodesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MasterAdmin_serviceDesc.Streams[0], "/proto.MasterAdmin/ListDataNodes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &masterAdminListDataNodesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MasterAdmin_ListDataNodesClient interface {
+	Recv() (*NodeInfoReply, error)
+	grpc.ClientStream
+}
+
+type masterAdminListDataNodesClient struct {
+	grpc.ClientStream
+}
+
+func (x *masterAdminListDataNodesClient) Recv() (*NodeInfoReply, error) {
+	m := new(NodeInfoReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *masterAdminClient) ListMetaNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (MasterAdmin_ListMetaNodesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MasterAdmin_serviceDesc.Streams[1], "/proto.MasterAdmin/ListMetaNodes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &masterAdminListMetaNodesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MasterAdmin_ListMetaNodesClient interface {
+	Recv() (*NodeInfoReply, error)
+	grpc.ClientStream
+}
+
+type masterAdminListMetaNodesClient struct {
+	grpc.ClientStream
+}
+
+func (x *masterAdminListMetaNodesClient) Recv() (*NodeInfoReply, error) {
+	m := new(NodeInfoReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *masterAdminClient) ListVols(ctx context.Context, in *ListVolsRequest, opts ...grpc.CallOption) (MasterAdmin_ListVolsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MasterAdmin_serviceDesc.Streams[2], "/proto.MasterAdmin/ListVols", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &masterAdminListVolsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MasterAdmin_ListVolsClient interface {
+	Recv() (*VolInfoReply, error)
+	grpc.ClientStream
+}
+
+type masterAdminListVolsClient struct {
+	grpc.ClientStream
+}
+
+func (x *masterAdminListVolsClient) Recv() (*VolInfoReply, error) {
+	m := new(VolInfoReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *masterAdminClient) GetVol(ctx context.Context, in *GetVolRequest, opts ...grpc.CallOption) (*VolInfoReply, error) {
+	out := new(VolInfoReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/GetVol", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) CreateVol(ctx context.Context, in *CreateVolRequest, opts ...grpc.CallOption) (*VolInfoReply, error) {
+	out := new(VolInfoReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/CreateVol", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) DeleteVol(ctx context.Context, in *DeleteVolRequest, opts ...grpc.CallOption) (*AdminOpReply, error) {
+	out := new(AdminOpReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/DeleteVol", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) ListDataPartitions(ctx context.Context, in *ListPartitionsRequest, opts ...grpc.CallOption) (MasterAdmin_ListDataPartitionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MasterAdmin_serviceDesc.Streams[3], "/proto.MasterAdmin/ListDataPartitions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &masterAdminListDataPartitionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MasterAdmin_ListDataPartitionsClient interface {
+	Recv() (*DataPartitionReply, error)
+	grpc.ClientStream
+}
+
+type masterAdminListDataPartitionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *masterAdminListDataPartitionsClient) Recv() (*DataPartitionReply, error) {
+	m := new(DataPartitionReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *masterAdminClient) GetDataPartition(ctx context.Context, in *GetPartitionRequest, opts ...grpc.CallOption) (*DataPartitionReply, error) {
+	out := new(DataPartitionReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/GetDataPartition", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) DecommissionDataPartition(ctx context.Context, in *DecommissionPartitionRequest, opts ...grpc.CallOption) (*AdminOpReply, error) {
+	out := new(AdminOpReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/DecommissionDataPartition", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) ListMetaPartitions(ctx context.Context, in *ListPartitionsRequest, opts ...grpc.CallOption) (MasterAdmin_ListMetaPartitionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MasterAdmin_serviceDesc.Streams[4], "/proto.MasterAdmin/ListMetaPartitions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &masterAdminListMetaPartitionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MasterAdmin_ListMetaPartitionsClient interface {
+	Recv() (*MetaPartitionReply, error)
+	grpc.ClientStream
+}
+
+type masterAdminListMetaPartitionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *masterAdminListMetaPartitionsClient) Recv() (*MetaPartitionReply, error) {
+	m := new(MetaPartitionReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *masterAdminClient) GetMetaPartition(ctx context.Context, in *GetPartitionRequest, opts ...grpc.CallOption) (*MetaPartitionReply, error) {
+	out := new(MetaPartitionReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/GetMetaPartition", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterAdminClient) DecommissionMetaPartition(ctx context.Context, in *DecommissionPartitionRequest, opts ...grpc.CallOption) (*AdminOpReply, error) {
+	out := new(AdminOpReply)
+	err := c.cc.Invoke(ctx, "/proto.MasterAdmin/DecommissionMetaPartition", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MasterAdminServer is the server API for MasterAdmin service.
+type MasterAdminServer interface {
+	GetCluster(context.Context, *GetClusterRequest) (*ClusterInfoReply, error)
+	ListDataNodes(*ListNodesRequest, MasterAdmin_ListDataNodesServer) error
+	ListMetaNodes(*ListNodesRequest, MasterAdmin_ListMetaNodesServer) error
+	ListVols(*ListVolsRequest, MasterAdmin_ListVolsServer) error
+	GetVol(context.Context, *GetVolRequest) (*VolInfoReply, error)
+	CreateVol(context.Context, *CreateVolRequest) (*VolInfoReply, error)
+	DeleteVol(context.Context, *DeleteVolRequest) (*AdminOpReply, error)
+	ListDataPartitions(*ListPartitionsRequest, MasterAdmin_ListDataPartitionsServer) error
+	GetDataPartition(context.Context, *GetPartitionRequest) (*DataPartitionReply, error)
+	DecommissionDataPartition(context.Context, *DecommissionPartitionRequest) (*AdminOpReply, error)
+	ListMetaPartitions(*ListPartitionsRequest, MasterAdmin_ListMetaPartitionsServer) error
+	GetMetaPartition(context.Context, *GetPartitionRequest) (*MetaPartitionReply, error)
+	DecommissionMetaPartition(context.Context, *DecommissionPartitionRequest) (*AdminOpReply, error)
+}
+
+// UnimplementedMasterAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedMasterAdminServer struct {
+}
+
+func (*UnimplementedMasterAdminServer) GetCluster(ctx context.Context, req *GetClusterRequest) (*ClusterInfoReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCluster not implemented")
+}
+func (*UnimplementedMasterAdminServer) ListDataNodes(req *ListNodesRequest, srv MasterAdmin_ListDataNodesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListDataNodes not implemented")
+}
+func (*UnimplementedMasterAdminServer) ListMetaNodes(req *ListNodesRequest, srv MasterAdmin_ListMetaNodesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListMetaNodes not implemented")
+}
+func (*UnimplementedMasterAdminServer) ListVols(req *ListVolsRequest, srv MasterAdmin_ListVolsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListVols not implemented")
+}
+func (*UnimplementedMasterAdminServer) GetVol(ctx context.Context, req *GetVolRequest) (*VolInfoReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVol not implemented")
+}
+func (*UnimplementedMasterAdminServer) CreateVol(ctx context.Context, req *CreateVolRequest) (*VolInfoReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVol not implemented")
+}
+func (*UnimplementedMasterAdminServer) DeleteVol(ctx context.Context, req *DeleteVolRequest) (*AdminOpReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVol not implemented")
+}
+func (*UnimplementedMasterAdminServer) ListDataPartitions(req *ListPartitionsRequest, srv MasterAdmin_ListDataPartitionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListDataPartitions not implemented")
+}
+func (*UnimplementedMasterAdminServer) GetDataPartition(ctx context.Context, req *GetPartitionRequest) (*DataPartitionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDataPartition not implemented")
+}
+func (*UnimplementedMasterAdminServer) DecommissionDataPartition(ctx context.Context, req *DecommissionPartitionRequest) (*AdminOpReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecommissionDataPartition not implemented")
+}
+func (*UnimplementedMasterAdminServer) ListMetaPartitions(req *ListPartitionsRequest, srv MasterAdmin_ListMetaPartitionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListMetaPartitions not implemented")
+}
+func (*UnimplementedMasterAdminServer) GetMetaPartition(ctx context.Context, req *GetPartitionRequest) (*MetaPartitionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetaPartition not implemented")
+}
+func (*UnimplementedMasterAdminServer) DecommissionMetaPartition(ctx context.Context, req *DecommissionPartitionRequest) (*AdminOpReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecommissionMetaPartition not implemented")
+}
+
+func RegisterMasterAdminServer(s *grpc.Server, srv MasterAdminServer) {
+	s.RegisterService(&_MasterAdmin_serviceDesc, srv)
+}
+
+func _MasterAdmin_GetCluster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).GetCluster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/GetCluster",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).GetCluster(ctx, req.(*GetClusterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_ListDataNodes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListNodesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterAdminServer).ListDataNodes(m, &masterAdminListDataNodesServer{stream})
+}
+
+type MasterAdmin_ListDataNodesServer interface {
+	Send(*NodeInfoReply) error
+	grpc.ServerStream
+}
+
+type masterAdminListDataNodesServer struct {
+	grpc.ServerStream
+}
+
+func (x *masterAdminListDataNodesServer) Send(m *NodeInfoReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MasterAdmin_ListMetaNodes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListNodesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterAdminServer).ListMetaNodes(m, &masterAdminListMetaNodesServer{stream})
+}
+
+type MasterAdmin_ListMetaNodesServer interface {
+	Send(*NodeInfoReply) error
+	grpc.ServerStream
+}
+
+type masterAdminListMetaNodesServer struct {
+	grpc.ServerStream
+}
+
+func (x *masterAdminListMetaNodesServer) Send(m *NodeInfoReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MasterAdmin_ListVols_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListVolsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterAdminServer).ListVols(m, &masterAdminListVolsServer{stream})
+}
+
+type MasterAdmin_ListVolsServer interface {
+	Send(*VolInfoReply) error
+	grpc.ServerStream
+}
+
+type masterAdminListVolsServer struct {
+	grpc.ServerStream
+}
+
+func (x *masterAdminListVolsServer) Send(m *VolInfoReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MasterAdmin_GetVol_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).GetVol(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/GetVol",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).GetVol(ctx, req.(*GetVolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_CreateVol_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).CreateVol(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/CreateVol",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).CreateVol(ctx, req.(*CreateVolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_DeleteVol_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).DeleteVol(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/DeleteVol",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).DeleteVol(ctx, req.(*DeleteVolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_ListDataPartitions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPartitionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterAdminServer).ListDataPartitions(m, &masterAdminListDataPartitionsServer{stream})
+}
+
+type MasterAdmin_ListDataPartitionsServer interface {
+	Send(*DataPartitionReply) error
+	grpc.ServerStream
+}
+
+type masterAdminListDataPartitionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *masterAdminListDataPartitionsServer) Send(m *DataPartitionReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MasterAdmin_GetDataPartition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPartitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).GetDataPartition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/GetDataPartition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).GetDataPartition(ctx, req.(*GetPartitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_DecommissionDataPartition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecommissionPartitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).DecommissionDataPartition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/DecommissionDataPartition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).DecommissionDataPartition(ctx, req.(*DecommissionPartitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_ListMetaPartitions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPartitionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterAdminServer).ListMetaPartitions(m, &masterAdminListMetaPartitionsServer{stream})
+}
+
+type MasterAdmin_ListMetaPartitionsServer interface {
+	Send(*MetaPartitionReply) error
+	grpc.ServerStream
+}
+
+type masterAdminListMetaPartitionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *masterAdminListMetaPartitionsServer) Send(m *MetaPartitionReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MasterAdmin_GetMetaPartition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPartitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).GetMetaPartition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/GetMetaPartition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).GetMetaPartition(ctx, req.(*GetPartitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterAdmin_DecommissionMetaPartition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecommissionPartitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterAdminServer).DecommissionMetaPartition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MasterAdmin/DecommissionMetaPartition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterAdminServer).DecommissionMetaPartition(ctx, req.(*DecommissionPartitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MasterAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.MasterAdmin",
+	HandlerType: (*MasterAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCluster",
+			Handler:    _MasterAdmin_GetCluster_Handler,
+		},
+		{
+			MethodName: "GetVol",
+			Handler:    _MasterAdmin_GetVol_Handler,
+		},
+		{
+			MethodName: "CreateVol",
+			Handler:    _MasterAdmin_CreateVol_Handler,
+		},
+		{
+			MethodName: "DeleteVol",
+			Handler:    _MasterAdmin_DeleteVol_Handler,
+		},
+		{
+			MethodName: "GetDataPartition",
+			Handler:    _MasterAdmin_GetDataPartition_Handler,
+		},
+		{
+			MethodName: "DecommissionDataPartition",
+			Handler:    _MasterAdmin_DecommissionDataPartition_Handler,
+		},
+		{
+			MethodName: "GetMetaPartition",
+			Handler:    _MasterAdmin_GetMetaPartition_Handler,
+		},
+		{
+			MethodName: "DecommissionMetaPartition",
+			Handler:    _MasterAdmin_DecommissionMetaPartition_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListDataNodes",
+			Handler:       _MasterAdmin_ListDataNodes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListMetaNodes",
+			Handler:       _MasterAdmin_ListMetaNodes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListVols",
+			Handler:       _MasterAdmin_ListVols_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListDataPartitions",
+			Handler:       _MasterAdmin_ListDataPartitions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListMetaPartitions",
+			Handler:       _MasterAdmin_ListMetaPartitions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "admin.proto",
+}
+
+func (m *GetClusterRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetClusterRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetClusterRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterInfoReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterInfoReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterInfoReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.MetaUsedGB != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MetaUsedGB))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x88
+	}
+	if m.MetaTotalGB != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MetaTotalGB))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x80
+	}
+	if m.DataUsedGB != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DataUsedGB))
+		i--
+		dAtA[i] = 0x78
+	}
+	if m.DataTotalGB != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DataTotalGB))
+		i--
+		dAtA[i] = 0x70
+	}
+	if m.VolCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.VolCount))
+		i--
+		dAtA[i] = 0x68
+	}
+	if m.MetaNodeCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MetaNodeCount))
+		i--
+		dAtA[i] = 0x60
+	}
+	if m.DataNodeCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DataNodeCount))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.MasterCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MasterCount))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.MaxMetaNodeID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MaxMetaNodeID))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.MaxMetaPartitionID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MaxMetaPartitionID))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.MaxDataPartitionID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MaxDataPartitionID))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Applied != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Applied))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.MetaNodeThreshold != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.MetaNodeThreshold))))
+		i--
+		dAtA[i] = 0x2d
+	}
+	if m.ForbidMpDecommission {
+		i--
+		if m.ForbidMpDecommission {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.DisableAutoAlloc {
+		i--
+		if m.DisableAutoAlloc {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.LeaderAddr) > 0 {
+		i -= len(m.LeaderAddr)
+		copy(dAtA[i:], m.LeaderAddr)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.LeaderAddr)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ListNodesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListNodesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListNodesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.ZoneName) > 0 {
+		i -= len(m.ZoneName)
+		copy(dAtA[i:], m.ZoneName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.ZoneName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NodeInfoReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NodeInfoReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NodeInfoReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.MediaType != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MediaType))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.PartitionCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.PartitionCount))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.Used != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Used))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.Total != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Total))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.IsWritable {
+		i--
+		if m.IsWritable {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.IsActive {
+		i--
+		if m.IsActive {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.NodeSetID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.NodeSetID))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.ZoneName) > 0 {
+		i -= len(m.ZoneName)
+		copy(dAtA[i:], m.ZoneName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.ZoneName)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.DomainAddr) > 0 {
+		i -= len(m.DomainAddr)
+		copy(dAtA[i:], m.DomainAddr)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.DomainAddr)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Addr) > 0 {
+		i -= len(m.Addr)
+		copy(dAtA[i:], m.Addr)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Addr)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.ID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.ID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ListVolsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListVolsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListVolsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Keywords) > 0 {
+		i -= len(m.Keywords)
+		copy(dAtA[i:], m.Keywords)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Keywords)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GetVolRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetVolRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetVolRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *VolInfoReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *VolInfoReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *VolInfoReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.FollowerRead {
+		i--
+		if m.FollowerRead {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x88
+	}
+	if m.CrossZone {
+		i--
+		if m.CrossZone {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x80
+	}
+	if m.CreateTime != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.CreateTime))
+		i--
+		dAtA[i] = 0x78
+	}
+	if m.MpCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MpCount))
+		i--
+		dAtA[i] = 0x70
+	}
+	if m.RwDpCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.RwDpCount))
+		i--
+		dAtA[i] = 0x68
+	}
+	if m.DpCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DpCount))
+		i--
+		dAtA[i] = 0x60
+	}
+	if m.MpReplicaNum != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MpReplicaNum))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.DpReplicaNum != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DpReplicaNum))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.UsedSize != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.UsedSize))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.TotalSize != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.TotalSize))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Capacity != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Capacity))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.VolType != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.VolType))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Status != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.ZoneName) > 0 {
+		i -= len(m.ZoneName)
+		copy(dAtA[i:], m.ZoneName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.ZoneName)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Owner) > 0 {
+		i -= len(m.Owner)
+		copy(dAtA[i:], m.Owner)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Owner)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.ID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.ID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CreateVolRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CreateVolRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CreateVolRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Description) > 0 {
+		i -= len(m.Description)
+		copy(dAtA[i:], m.Description)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Description)))
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.FollowerRead {
+		i--
+		if m.FollowerRead {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.CrossZone {
+		i--
+		if m.CrossZone {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.DpSize != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DpSize))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.MpCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MpCount))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.DpReplicaNum != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DpReplicaNum))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.VolType != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.VolType))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.ZoneName) > 0 {
+		i -= len(m.ZoneName)
+		copy(dAtA[i:], m.ZoneName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.ZoneName)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Capacity != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Capacity))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Owner) > 0 {
+		i -= len(m.Owner)
+		copy(dAtA[i:], m.Owner)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Owner)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DeleteVolRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeleteVolRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DeleteVolRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.AuthKey) > 0 {
+		i -= len(m.AuthKey)
+		copy(dAtA[i:], m.AuthKey)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.AuthKey)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminOpReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminOpReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminOpReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Msg) > 0 {
+		i -= len(m.Msg)
+		copy(dAtA[i:], m.Msg)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Msg)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ListPartitionsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListPartitionsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListPartitionsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.VolName) > 0 {
+		i -= len(m.VolName)
+		copy(dAtA[i:], m.VolName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.VolName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GetPartitionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetPartitionRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetPartitionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.PartitionID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.PartitionID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DecommissionPartitionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DecommissionPartitionRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DecommissionPartitionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Addr) > 0 {
+		i -= len(m.Addr)
+		copy(dAtA[i:], m.Addr)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.Addr)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.PartitionID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.PartitionID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DataPartitionReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DataPartitionReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DataPartitionReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.MediaType != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MediaType))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.IsDiscard {
+		i--
+		if m.IsDiscard {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.IsRecover {
+		i--
+		if m.IsRecover {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.Used != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Used))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Total != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Total))
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.LeaderAddr) > 0 {
+		i -= len(m.LeaderAddr)
+		copy(dAtA[i:], m.LeaderAddr)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.LeaderAddr)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Hosts) > 0 {
+		for iNdEx := len(m.Hosts) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Hosts[iNdEx])
+			copy(dAtA[i:], m.Hosts[iNdEx])
+			i = encodeVarintAdmin(dAtA, i, uint64(len(m.Hosts[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if m.ReplicaNum != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.ReplicaNum))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Status != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.VolName) > 0 {
+		i -= len(m.VolName)
+		copy(dAtA[i:], m.VolName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.VolName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.PartitionID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.PartitionID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MetaPartitionReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetaPartitionReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MetaPartitionReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.IsRecover {
+		i--
+		if m.IsRecover {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x60
+	}
+	if m.DentryCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.DentryCount))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.InodeCount != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.InodeCount))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.MaxInodeID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.MaxInodeID))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.End != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Start != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.LeaderAddr) > 0 {
+		i -= len(m.LeaderAddr)
+		copy(dAtA[i:], m.LeaderAddr)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.LeaderAddr)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Hosts) > 0 {
+		for iNdEx := len(m.Hosts) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Hosts[iNdEx])
+			copy(dAtA[i:], m.Hosts[iNdEx])
+			i = encodeVarintAdmin(dAtA, i, uint64(len(m.Hosts[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if m.ReplicaNum != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.ReplicaNum))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Status != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.VolName) > 0 {
+		i -= len(m.VolName)
+		copy(dAtA[i:], m.VolName)
+		i = encodeVarintAdmin(dAtA, i, uint64(len(m.VolName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.PartitionID != 0 {
+		i = encodeVarintAdmin(dAtA, i, uint64(m.PartitionID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintAdmin(dAtA []byte, offset int, v uint64) int {
+	offset -= sovAdmin(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *GetClusterRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterInfoReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.LeaderAddr)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.DisableAutoAlloc {
+		n += 2
+	}
+	if m.ForbidMpDecommission {
+		n += 2
+	}
+	if m.MetaNodeThreshold != 0 {
+		n += 5
+	}
+	if m.Applied != 0 {
+		n += 1 + sovAdmin(uint64(m.Applied))
+	}
+	if m.MaxDataPartitionID != 0 {
+		n += 1 + sovAdmin(uint64(m.MaxDataPartitionID))
+	}
+	if m.MaxMetaPartitionID != 0 {
+		n += 1 + sovAdmin(uint64(m.MaxMetaPartitionID))
+	}
+	if m.MaxMetaNodeID != 0 {
+		n += 1 + sovAdmin(uint64(m.MaxMetaNodeID))
+	}
+	if m.MasterCount != 0 {
+		n += 1 + sovAdmin(uint64(m.MasterCount))
+	}
+	if m.DataNodeCount != 0 {
+		n += 1 + sovAdmin(uint64(m.DataNodeCount))
+	}
+	if m.MetaNodeCount != 0 {
+		n += 1 + sovAdmin(uint64(m.MetaNodeCount))
+	}
+	if m.VolCount != 0 {
+		n += 1 + sovAdmin(uint64(m.VolCount))
+	}
+	if m.DataTotalGB != 0 {
+		n += 1 + sovAdmin(uint64(m.DataTotalGB))
+	}
+	if m.DataUsedGB != 0 {
+		n += 1 + sovAdmin(uint64(m.DataUsedGB))
+	}
+	if m.MetaTotalGB != 0 {
+		n += 2 + sovAdmin(uint64(m.MetaTotalGB))
+	}
+	if m.MetaUsedGB != 0 {
+		n += 2 + sovAdmin(uint64(m.MetaUsedGB))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListNodesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ZoneName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *NodeInfoReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
+		n += 1 + sovAdmin(uint64(m.ID))
+	}
+	l = len(m.Addr)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.DomainAddr)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.ZoneName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.NodeSetID != 0 {
+		n += 1 + sovAdmin(uint64(m.NodeSetID))
+	}
+	if m.IsActive {
+		n += 2
+	}
+	if m.IsWritable {
+		n += 2
+	}
+	if m.Total != 0 {
+		n += 1 + sovAdmin(uint64(m.Total))
+	}
+	if m.Used != 0 {
+		n += 1 + sovAdmin(uint64(m.Used))
+	}
+	if m.PartitionCount != 0 {
+		n += 1 + sovAdmin(uint64(m.PartitionCount))
+	}
+	if m.MediaType != 0 {
+		n += 1 + sovAdmin(uint64(m.MediaType))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListVolsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Keywords)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetVolRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *VolInfoReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
+		n += 1 + sovAdmin(uint64(m.ID))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.ZoneName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovAdmin(uint64(m.Status))
+	}
+	if m.VolType != 0 {
+		n += 1 + sovAdmin(uint64(m.VolType))
+	}
+	if m.Capacity != 0 {
+		n += 1 + sovAdmin(uint64(m.Capacity))
+	}
+	if m.TotalSize != 0 {
+		n += 1 + sovAdmin(uint64(m.TotalSize))
+	}
+	if m.UsedSize != 0 {
+		n += 1 + sovAdmin(uint64(m.UsedSize))
+	}
+	if m.DpReplicaNum != 0 {
+		n += 1 + sovAdmin(uint64(m.DpReplicaNum))
+	}
+	if m.MpReplicaNum != 0 {
+		n += 1 + sovAdmin(uint64(m.MpReplicaNum))
+	}
+	if m.DpCount != 0 {
+		n += 1 + sovAdmin(uint64(m.DpCount))
+	}
+	if m.RwDpCount != 0 {
+		n += 1 + sovAdmin(uint64(m.RwDpCount))
+	}
+	if m.MpCount != 0 {
+		n += 1 + sovAdmin(uint64(m.MpCount))
+	}
+	if m.CreateTime != 0 {
+		n += 1 + sovAdmin(uint64(m.CreateTime))
+	}
+	if m.CrossZone {
+		n += 3
+	}
+	if m.FollowerRead {
+		n += 3
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *CreateVolRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Capacity != 0 {
+		n += 1 + sovAdmin(uint64(m.Capacity))
+	}
+	l = len(m.ZoneName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.VolType != 0 {
+		n += 1 + sovAdmin(uint64(m.VolType))
+	}
+	if m.DpReplicaNum != 0 {
+		n += 1 + sovAdmin(uint64(m.DpReplicaNum))
+	}
+	if m.MpCount != 0 {
+		n += 1 + sovAdmin(uint64(m.MpCount))
+	}
+	if m.DpSize != 0 {
+		n += 1 + sovAdmin(uint64(m.DpSize))
+	}
+	if m.CrossZone {
+		n += 2
+	}
+	if m.FollowerRead {
+		n += 2
+	}
+	l = len(m.Description)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DeleteVolRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	l = len(m.AuthKey)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminOpReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Msg)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListPartitionsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.VolName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetPartitionRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PartitionID != 0 {
+		n += 1 + sovAdmin(uint64(m.PartitionID))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DecommissionPartitionRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PartitionID != 0 {
+		n += 1 + sovAdmin(uint64(m.PartitionID))
+	}
+	l = len(m.Addr)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DataPartitionReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PartitionID != 0 {
+		n += 1 + sovAdmin(uint64(m.PartitionID))
+	}
+	l = len(m.VolName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovAdmin(uint64(m.Status))
+	}
+	if m.ReplicaNum != 0 {
+		n += 1 + sovAdmin(uint64(m.ReplicaNum))
+	}
+	if len(m.Hosts) > 0 {
+		for _, s := range m.Hosts {
+			l = len(s)
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	l = len(m.LeaderAddr)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Total != 0 {
+		n += 1 + sovAdmin(uint64(m.Total))
+	}
+	if m.Used != 0 {
+		n += 1 + sovAdmin(uint64(m.Used))
+	}
+	if m.IsRecover {
+		n += 2
+	}
+	if m.IsDiscard {
+		n += 2
+	}
+	if m.MediaType != 0 {
+		n += 1 + sovAdmin(uint64(m.MediaType))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MetaPartitionReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PartitionID != 0 {
+		n += 1 + sovAdmin(uint64(m.PartitionID))
+	}
+	l = len(m.VolName)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovAdmin(uint64(m.Status))
+	}
+	if m.ReplicaNum != 0 {
+		n += 1 + sovAdmin(uint64(m.ReplicaNum))
+	}
+	if len(m.Hosts) > 0 {
+		for _, s := range m.Hosts {
+			l = len(s)
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	l = len(m.LeaderAddr)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Start != 0 {
+		n += 1 + sovAdmin(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovAdmin(uint64(m.End))
+	}
+	if m.MaxInodeID != 0 {
+		n += 1 + sovAdmin(uint64(m.MaxInodeID))
+	}
+	if m.InodeCount != 0 {
+		n += 1 + sovAdmin(uint64(m.InodeCount))
+	}
+	if m.DentryCount != 0 {
+		n += 1 + sovAdmin(uint64(m.DentryCount))
+	}
+	if m.IsRecover {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovAdmin(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozAdmin(x uint64) (n int) {
+	return sovAdmin(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *GetClusterRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetClusterRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetClusterRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ClusterInfoReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ClusterInfoReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ClusterInfoReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LeaderAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DisableAutoAlloc", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DisableAutoAlloc = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ForbidMpDecommission", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ForbidMpDecommission = bool(v != 0)
+		case 5:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetaNodeThreshold", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.MetaNodeThreshold = float32(math.Float32frombits(v))
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Applied", wireType)
+			}
+			m.Applied = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Applied |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxDataPartitionID", wireType)
+			}
+			m.MaxDataPartitionID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxDataPartitionID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMetaPartitionID", wireType)
+			}
+			m.MaxMetaPartitionID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMetaPartitionID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMetaNodeID", wireType)
+			}
+			m.MaxMetaNodeID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMetaNodeID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MasterCount", wireType)
+			}
+			m.MasterCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MasterCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataNodeCount", wireType)
+			}
+			m.DataNodeCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DataNodeCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetaNodeCount", wireType)
+			}
+			m.MetaNodeCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MetaNodeCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VolCount", wireType)
+			}
+			m.VolCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.VolCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataTotalGB", wireType)
+			}
+			m.DataTotalGB = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DataTotalGB |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataUsedGB", wireType)
+			}
+			m.DataUsedGB = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DataUsedGB |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetaTotalGB", wireType)
+			}
+			m.MetaTotalGB = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MetaTotalGB |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetaUsedGB", wireType)
+			}
+			m.MetaUsedGB = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MetaUsedGB |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListNodesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListNodesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListNodesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ZoneName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ZoneName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NodeInfoReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NodeInfoReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NodeInfoReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			m.ID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DomainAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DomainAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ZoneName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ZoneName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeSetID", wireType)
+			}
+			m.NodeSetID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NodeSetID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsActive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsActive = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsWritable", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsWritable = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Total", wireType)
+			}
+			m.Total = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Total |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Used", wireType)
+			}
+			m.Used = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Used |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionCount", wireType)
+			}
+			m.PartitionCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PartitionCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MediaType", wireType)
+			}
+			m.MediaType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MediaType |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListVolsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListVolsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListVolsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keywords", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keywords = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetVolRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetVolRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetVolRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *VolInfoReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: VolInfoReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: VolInfoReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			m.ID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ZoneName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ZoneName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VolType", wireType)
+			}
+			m.VolType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.VolType |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+			}
+			m.Capacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Capacity |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalSize", wireType)
+			}
+			m.TotalSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UsedSize", wireType)
+			}
+			m.UsedSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.UsedSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DpReplicaNum", wireType)
+			}
+			m.DpReplicaNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DpReplicaNum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MpReplicaNum", wireType)
+			}
+			m.MpReplicaNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MpReplicaNum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DpCount", wireType)
+			}
+			m.DpCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DpCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RwDpCount", wireType)
+			}
+			m.RwDpCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RwDpCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MpCount", wireType)
+			}
+			m.MpCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MpCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreateTime", wireType)
+			}
+			m.CreateTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CreateTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CrossZone", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CrossZone = bool(v != 0)
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FollowerRead", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FollowerRead = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CreateVolRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CreateVolRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CreateVolRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+			}
+			m.Capacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Capacity |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ZoneName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ZoneName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VolType", wireType)
+			}
+			m.VolType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.VolType |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DpReplicaNum", wireType)
+			}
+			m.DpReplicaNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DpReplicaNum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MpCount", wireType)
+			}
+			m.MpCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MpCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DpSize", wireType)
+			}
+			m.DpSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DpSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CrossZone", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CrossZone = bool(v != 0)
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FollowerRead", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FollowerRead = bool(v != 0)
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Description", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Description = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DeleteVolRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeleteVolRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeleteVolRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthKey", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AuthKey = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminOpReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminOpReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminOpReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Msg", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Msg = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListPartitionsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListPartitionsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListPartitionsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VolName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.VolName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetPartitionRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetPartitionRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetPartitionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionID", wireType)
+			}
+			m.PartitionID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PartitionID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DecommissionPartitionRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DecommissionPartitionRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DecommissionPartitionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionID", wireType)
+			}
+			m.PartitionID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PartitionID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DataPartitionReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DataPartitionReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DataPartitionReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionID", wireType)
+			}
+			m.PartitionID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PartitionID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VolName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.VolName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplicaNum", wireType)
+			}
+			m.ReplicaNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReplicaNum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hosts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hosts = append(m.Hosts, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LeaderAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Total", wireType)
+			}
+			m.Total = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Total |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Used", wireType)
+			}
+			m.Used = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Used |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsRecover", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsRecover = bool(v != 0)
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsDiscard", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsDiscard = bool(v != 0)
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MediaType", wireType)
+			}
+			m.MediaType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MediaType |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetaPartitionReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetaPartitionReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetaPartitionReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionID", wireType)
+			}
+			m.PartitionID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PartitionID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VolName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.VolName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplicaNum", wireType)
+			}
+			m.ReplicaNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReplicaNum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hosts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hosts = append(m.Hosts, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LeaderAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxInodeID", wireType)
+			}
+			m.MaxInodeID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxInodeID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InodeCount", wireType)
+			}
+			m.InodeCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InodeCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DentryCount", wireType)
+			}
+			m.DentryCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DentryCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsRecover", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsRecover = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipAdmin(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthAdmin
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupAdmin
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthAdmin
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthAdmin        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowAdmin          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupAdmin = fmt.Errorf("proto: unexpected end of group")
+)
@@ -39,6 +39,9 @@ const (
 	AdminGetApiQpsLimit                               = "/admin/getApiQpsLimit"
 	AdminRemoveApiQpsLimit                            = "/admin/rmApiQpsLimit"
 	AdminGetCluster                                   = "/admin/getCluster"
+	AdminCreateClusterSnapshot                        = "/admin/clusterSnapshot/create"
+	AdminListClusterSnapshots                         = "/admin/clusterSnapshot/list"
+	AdminClusterDiff                                  = "/admin/clusterDiff"
 	AdminSetClusterInfo                               = "/admin/setClusterInfo"
 	AdminGetMonitorPushAddr                           = "/admin/getMonitorPushAddr"
 	AdminGetClusterDataNodes                          = "/admin/cluster/getAllDataNodes"
@@ -57,19 +60,42 @@ const (
 	AdminDeleteDataReplica                            = "/dataReplica/delete"
 	AdminAddDataReplica                               = "/dataReplica/add"
 	AdminDeleteVol                                    = "/vol/delete"
+	AdminListVolRecycleBin                            = "/vol/recycleBin/list"
+	AdminPurgeVolRecycleBin                           = "/vol/recycleBin/purge"
 	AdminUpdateVol                                    = "/vol/update"
 	AdminVolShrink                                    = "/vol/shrink"
 	AdminVolExpand                                    = "/vol/expand"
+	AdminVolPreWarmStatus                             = "/vol/preWarmStatus"
 	AdminVolForbidden                                 = "/vol/forbidden"
 	AdminVolEnableAuditLog                            = "/vol/auditlog"
 	AdminVolSetDpRepairBlockSize                      = "/vol/setDpRepairBlockSize"
+	AdminVolSetDefaultXAttrs                          = "/vol/setDefaultXAttrs"
+	AdminVolSetMetaWorkerWeight                       = "/vol/setMetaWorkerWeight"
+	AdminVolSetMetaMediaType                          = "/vol/setMetaMediaType"
+	AdminVolCheckMetaMediaType                        = "/vol/checkMetaMediaType"
+	AdminVolSetDpPin                                  = "/vol/dpPin/set"
+	AdminVolRemoveDpPin                               = "/vol/dpPin/remove"
+	AdminVolAddReadOnlyWindow                         = "/vol/readOnlyWindow/add"
+	AdminVolListReadOnlyWindows                       = "/vol/readOnlyWindow/list"
+	AdminVolCancelReadOnlyWindow                      = "/vol/readOnlyWindow/cancel"
+	AdminVolMetaFreeze                                = "/vol/metaFreeze"
+	AdminVolMetaAuth                                  = "/vol/metaAuth"
+	AdminVolPurge                                     = "/vol/purge"
+	AdminVolClientKeepAlive                           = "/vol/clientKeepAlive"
+	AdminVolClients                                   = "/vol/clients"
+	AdminCreateVolReplica                             = "/vol/replica/create"
+	AdminGetVolReplicaStatus                          = "/vol/replica/status"
+	AdminSyncVolReplica                               = "/vol/replica/sync"
+	AdminListVolReplicas                              = "/vol/replica/list"
 	AdminCreateVol                                    = "/admin/createVol"
 	AdminGetVol                                       = "/admin/getVol"
 	AdminClusterFreeze                                = "/cluster/freeze"
 	AdminClusterForbidMpDecommission                  = "/cluster/forbidMetaPartitionDecommission"
 	AdminClusterStat                                  = "/cluster/stat"
+	AdminNodeTasks                                    = "/admin/nodeTasks"
 	AdminSetCheckDataReplicasEnable                   = "/cluster/setCheckDataReplicasEnable"
 	AdminGetIP                                        = "/admin/getIp"
+	AdminConsole                                      = "/console/"
 	AdminCreateMetaPartition                          = "/metaPartition/create"
 	AdminSetMetaNodeThreshold                         = "/threshold/set"
 	AdminSetMasterVolDeletionDelayTime                = "/volDeletionDelayTime/set"
@@ -78,6 +104,9 @@ const (
 	AdminListVols                                     = "/vol/list"
 	AdminSetNodeInfo                                  = "/admin/setNodeInfo"
 	AdminGetNodeInfo                                  = "/admin/getNodeInfo"
+	AdminDiagBundle                                   = "/admin/diagBundle"
+	AdminExportClusterConfig                          = "/admin/config/export"
+	AdminImportClusterConfig                          = "/admin/config/import"
 	AdminGetAllNodeSetGrpInfo                         = "/admin/getDomainInfo"
 	AdminGetNodeSetGrpInfo                            = "/admin/getDomainNodeSetGrpInfo"
 	AdminGetIsDomainOn                                = "/admin/getIsDomainOn"
@@ -87,6 +116,7 @@ const (
 	AdminUpdateDomainDataUseRatio                     = "/admin/updateDomainDataRatio"
 	AdminUpdateZoneExcludeRatio                       = "/admin/updateZoneExcludeRatio"
 	AdminSetNodeRdOnly                                = "/admin/setNodeRdOnly"
+	AdminSetNodeStateByAddr                           = "/admin/setNodeStateByAddr"
 	AdminSetDpRdOnly                                  = "/admin/setDpRdOnly"
 	AdminSetConfig                                    = "/admin/setConfig"
 	AdminGetConfig                                    = "/admin/getConfig"
@@ -150,6 +180,8 @@ const (
 	ClientMetaPartition      = "/metaPartition/get"
 	ClientVolStat            = "/client/volStat"
 	ClientMetaPartitions     = "/client/metaPartitions"
+	ClientNsEventEndpoints   = "/client/nsEventEndpoints"
+	ClientMetaToken          = "/client/metaToken"
 	GetAllClients            = "/getAllClients"
 
 	// qos api
@@ -221,13 +253,21 @@ const (
 	AdminLoadMetaPartition             = "/metaPartition/load"
 	AdminDiagnoseMetaPartition         = "/metaPartition/diagnose"
 	AdminDecommissionMetaPartition     = "/metaPartition/decommission"
+	AdminMigrateMetaPartitionToNodeSet = "/metaPartition/migrateToNodeSet"
 	AdminChangeMetaPartitionLeader     = "/metaPartition/changeleader"
 	AdminBalanceMetaPartitionLeader    = "/metaPartition/balanceLeader"
 	AdminMetaPartitionEmptyStatus      = "/metaPartition/emptyStatus"
 	AdminMetaPartitionFreezeEmpty      = "/metaPartition/freezeEmpty"
 	AdminMetaPartitionCleanEmpty       = "/metaPartition/cleanEmpty"
+	AdminMetaPartitionMergeEmpty       = "/metaPartition/mergeEmpty"
 	AdminMetaPartitionRemoveBackup     = "/metaPartition/removeBackup"
 	AdminMetaPartitionGetCleanTask     = "/metaPartition/getCleanTask"
+	AdminMetaPartitionLagInfo          = "/metaPartition/lagInfo"
+	AdminNodeBlastRadius               = "/node/blastRadius"
+	AdminDiffMetaPartitionTree         = "/metaPartition/diffTree"
+	AdminRepairMetaInodeNLink          = "/metaPartition/repairNLink"
+	AdminTruncateMetaRaftLog           = "/metaPartition/truncateRaftLog"
+	AdminMetaPartitionDistribution     = "/metaPartition/distribution"
 	AdminAddMetaReplica                = "/metaReplica/add"
 	AdminDeleteMetaReplica             = "/metaReplica/delete"
 	AdminPutDataPartitions             = "/dataPartitions/set"
@@ -254,12 +294,15 @@ const (
 	GetDataNodeTaskResponse = "/dataNode/response" // Method: 'POST', ContentType: 'application/json'
 	GetLcNodeTaskResponse   = "/lcNode/response"   // Method: 'POST', ContentType: 'application/json'
 
-	GetTopologyView = "/topo/get"
-	UpdateZone      = "/zone/update"
-	GetAllZones     = "/zone/list"
-	GetAllNodeSets  = "/nodeSet/list"
-	GetNodeSet      = "/nodeSet/get"
-	UpdateNodeSet   = "/nodeSet/update"
+	GetTopologyView    = "/topo/get"
+	UpdateZone         = "/zone/update"
+	GetAllZones        = "/zone/list"
+	EvacuateZone       = "/zone/evacuate"
+	EvacuateZoneStatus = "/zone/evacuate/status"
+	EvacuateZoneAbort  = "/zone/evacuate/abort"
+	GetAllNodeSets     = "/nodeSet/list"
+	GetNodeSet         = "/nodeSet/get"
+	UpdateNodeSet      = "/nodeSet/update"
 
 	// Header keys
 	SkipOwnerValidation = "Skip-Owner-Validation"
@@ -358,6 +401,9 @@ var GApiInfo map[string]string = map[string]string{
 	"adminlistvols":                      AdminListVols,
 	"adminsetnodeinfo":                   AdminSetNodeInfo,
 	"admingetnodeinfo":                   AdminGetNodeInfo,
+	"admindiagbundle":                    AdminDiagBundle,
+	"adminexportclusterconfig":           AdminExportClusterConfig,
+	"adminimportclusterconfig":           AdminImportClusterConfig,
 	"admingetallnodesetgrpinfo":          AdminGetAllNodeSetGrpInfo,
 	"admingetnodesetgrpinfo":             AdminGetNodeSetGrpInfo,
 	"admingetisdomainon":                 AdminGetIsDomainOn,
@@ -366,6 +412,7 @@ var GApiInfo map[string]string = map[string]string{
 	"adminupdatedomaindatauseratio":      AdminUpdateDomainDataUseRatio,
 	"adminupdatezoneexcluderatio":        AdminUpdateZoneExcludeRatio,
 	"adminsetnoderdonly":                 AdminSetNodeRdOnly,
+	"adminsetnodestatebyaddr":            AdminSetNodeStateByAddr,
 	"adminsetdprdonly":                   AdminSetDpRdOnly,
 	"admindatapartitionchangeleader":     AdminDataPartitionChangeLeader,
 	"adminsetdpdiscard":                  AdminSetDpDiscard,
@@ -386,6 +433,8 @@ var GApiInfo map[string]string = map[string]string{
 	"clientmetapartition":    ClientMetaPartition,
 	"clientvolstat":          ClientVolStat,
 	"clientmetapartitions":   ClientMetaPartitions,
+	"clientnseventendpoints": ClientNsEventEndpoints,
+	"clientmetatoken":        ClientMetaToken,
 	"qosgetstatus":           QosGetStatus,
 	"qosgetclientslimitinfo": QosGetClientsLimitInfo,
 	"qosgetzonelimitinfo":    QosGetZoneLimitInfo,
@@ -449,6 +498,12 @@ const (
 	EnableRemoteCache      = "enableRemoteCache"
 )
 
+// the states of a meta node set by AdminSetNodeStateByAddr
+const (
+	NodeStateNormal      = "normal"
+	NodeStateNoPlacement = "noPlacement" // no new partition is placed on the node, e.g. before a maintenance
+)
+
 // const TimeFormat = "2006-01-02 15:04:05"
 
 const (
@@ -638,6 +693,42 @@ type ClusterInfo struct {
 	ClusterUuidEnable                  bool
 	ClusterEnableSnapshot              bool
 	RaftPartitionCanUsingDifferentPort bool
+	MetaSnapshotPersistenceMode        uint32 // persistence mode of the snapshot files of meta partitions
+	MetaSnapshotSendRateMB             uint64 // MB/s of the snapshots sent by a metanode, 0 keeps the one of the node
+	MetaSnapshotRecvRateMB             uint64 // MB/s of the snapshots received by a metanode, 0 keeps the one of the node
+	MetaSnapshotPartitionRateMB        uint64 // MB/s of the snapshot of a meta partition sent or received, 0 keeps the one of the node
+
+	// the addresses of the master peers, the clients request them besides the ones configured
+	MasterAddrs []string
+}
+
+// the persistence modes of the snapshot files dumped by the meta partitions
+const (
+	PersistenceModeWriteThrough uint32 = iota // each file is synced once written
+	PersistenceModeWriteBack                  // the files are left to the page cache, for the battery-backed disks
+)
+
+func PersistenceModeString(mode uint32) string {
+	switch mode {
+	case PersistenceModeWriteThrough:
+		return "writeThrough"
+	case PersistenceModeWriteBack:
+		return "writeBack"
+	default:
+		return fmt.Sprintf("unknown(%v)", mode)
+	}
+}
+
+// ParsePersistenceMode returns the persistence mode of the name.
+func ParsePersistenceMode(name string) (mode uint32, err error) {
+	switch strings.ToLower(name) {
+	case "writethrough":
+		return PersistenceModeWriteThrough, nil
+	case "writeback":
+		return PersistenceModeWriteBack, nil
+	default:
+		return 0, fmt.Errorf("unknown persistence mode %v, writeThrough or writeBack", name)
+	}
 }
 
 // CreateDataPartitionRequest defines the request to create a data partition.
@@ -862,8 +953,43 @@ type HeartBeatRequest struct {
 	MetaNodeGOGC                   int
 	DataNodeGOGC                   int
 	FlashNodeHeartBeatInfos
+	VolDefaultXAttrs map[string]map[string]string // default xattrs of new inodes by volume, NOTE: for metanode
+	MetaDeltaReport  bool                         // master accepts delta meta partition reports, NOTE: for metanode
+	MetaReportSeq    uint64                       // seq of the meta partition reports master holds of the node, 0 if none
+
+	VolMetaWorkerWeights map[string]int32 // weights of the request workers of volumes not of the default one, NOTE: for metanode
+	ReadOnlyVols         []string         // volumes in a read-only window, the partitions of them refuse modifications
+	MetaFrozenVols       []string         // volumes with the metadata frozen, the meta partitions of them refuse modifications
+
+	// keys of the volumes enforcing the meta auth, the meta partitions of them refuse
+	// modifications without a token signed by one of the keys
+	VolMetaAccessKeys map[string][]*MetaAccessKey
+
+	// the controls of the purge of the inodes deleted of the volumes, set by the admin
+	VolPurgeCtrls map[string]*VolPurgeCtrl
+
+	// the quotas of the rates of the meta ops of the volumes, set by the admin
+	VolMetaOpQuotas map[string]*MetaOpQuota
+}
+
+// MetaMediaTypeReport lists the meta partitions of the volume with the replicas on the meta nodes
+// not of the meta media type of the volume.
+type MetaMediaTypeReport struct {
+	VolName    string
+	MediaType  uint32
+	Partitions []*MetaPartitionMediaTypeReport
 }
 
+type MetaPartitionMediaTypeReport struct {
+	PartitionID uint64
+	Hosts       []string // the hosts of the replicas not on the media type
+}
+
+const (
+	DefaultMetaWorkerWeight = 1
+	MaxMetaWorkerWeight     = 100
+)
+
 // DataPartitionReport defines the partition report.
 type DataPartitionReport struct {
 	VolName                    string
@@ -970,6 +1096,216 @@ type MetaPartitionReport struct {
 	StatByMigrateStorageClass []*StatOfStorageClass
 	LocalPeers                []Peer
 	ReadOnlyReasons           uint32
+	ApplyID                   uint64
+	Stat                      *MetaPartitionStat
+	OpRate                    uint64 // the write ops per second on the leader since the last report
+
+	// the inodes deleted pending the real deletion, and whether the purge of them is paused
+	PurgeBacklogCount uint64
+	PurgeBacklogBytes uint64
+	PurgePaused       bool
+}
+
+// MetaPartitionStat is the size and count accounting of the trees of a meta partition.
+type MetaPartitionStat struct {
+	Size               uint64 // total size of the inodes
+	DelInodeCount      uint64 // inodes marked deleted but not freed yet
+	DelInodeSize       uint64
+	FileDentryCount    uint64
+	DirDentryCount     uint64
+	SymlinkDentryCount uint64
+	OtherDentryCount   uint64
+	XAttrCount         uint64
+	XAttrBytes         uint64 // total size of the keys and values of the xattrs
+}
+
+// MetaReplicaLagInfo is the apply index lag of a meta partition replica behind the leader.
+type MetaReplicaLagInfo struct {
+	Addr      string
+	IsLeader  bool
+	ApplyID   uint64
+	Lag       uint64
+	LagCycles int
+}
+
+// MetaPartitionLagInfo defines the apply index lag of the replicas of a meta partition.
+type MetaPartitionLagInfo struct {
+	PartitionID   uint64
+	VolName       string
+	LeaderAddr    string
+	LeaderApplyID uint64
+	Replicas      []*MetaReplicaLagInfo
+}
+
+// VolRecycleBinEntry is a volume deleted with delay, its partitions are kept until DeleteExecTime.
+type VolRecycleBinEntry struct {
+	Name               string
+	Owner              string
+	DeleteExecTime     int64
+	Capacity           uint64 // GB
+	UsedSize           uint64
+	DataPartitionCount int
+	MetaPartitionCount int
+}
+
+// VolRecycleBin lists the volumes in the recycle bin and the space held by them.
+type VolRecycleBin struct {
+	Capacity uint64 // GB
+	UsedSize uint64
+	Vols     []*VolRecycleBinEntry
+}
+
+// ZoneEvacuationNode is the progress of a node of the zone being evacuated.
+type ZoneEvacuationNode struct {
+	Addr      string
+	Status    string // decommission status of data node
+	Remaining int    // partitions left on the node
+}
+
+// ZoneEvacuationView is the progress of moving all the partitions out of a zone.
+type ZoneEvacuationView struct {
+	Zone                     string
+	Status                   string
+	StartTime                int64
+	EndTime                  int64
+	DataPartitionTotal       int
+	DataPartitionRemaining   int
+	MetaPartitionTotal       int
+	MetaPartitionRemaining   int
+	MetaPartitionConcurrency int
+	MetaPartitionFailed      map[uint64]string
+	DataNodes                []*ZoneEvacuationNode
+	MetaNodes                []*ZoneEvacuationNode
+}
+
+// ClusterSnapshotInfo describes a snapshot of the cluster state taken for diff.
+type ClusterSnapshotInfo struct {
+	ID                 uint64
+	CreateTime         int64
+	DataNodeCount      int
+	MetaNodeCount      int
+	VolCount           int
+	DataPartitionCount int
+	MetaPartitionCount int
+}
+
+// PartitionHostsChange is a partition whose hosts are changed between two cluster snapshots.
+type PartitionHostsChange struct {
+	PartitionID uint64
+	VolName     string
+	OldHosts    []string
+	NewHosts    []string
+}
+
+// VolSettingChange is a setting of a volume changed between two cluster snapshots.
+type VolSettingChange struct {
+	VolName string
+	Field   string
+	Old     interface{}
+	New     interface{}
+}
+
+// ClusterDiff defines what changed from a cluster snapshot to another one, To is zero if the
+// snapshot is compared with the current state.
+type ClusterDiff struct {
+	From                  uint64
+	To                    uint64
+	FromTime              int64
+	ToTime                int64
+	DataNodesAdded        []string
+	DataNodesRemoved      []string
+	MetaNodesAdded        []string
+	MetaNodesRemoved      []string
+	VolsAdded             []string
+	VolsRemoved           []string
+	DataPartitionsAdded   []uint64
+	DataPartitionsRemoved []uint64
+	MetaPartitionsAdded   []uint64
+	MetaPartitionsRemoved []uint64
+	DataPartitionsMoved   []*PartitionHostsChange
+	MetaPartitionsMoved   []*PartitionHostsChange
+	VolSettingsChanged    []*VolSettingChange
+}
+
+// VolBlastRadius is the part of a volume that would be affected by the loss of a node or disk.
+// A partition at quorum would be left with exactly a quorum of live replicas, a partition
+// losing quorum would be left with less.
+type VolBlastRadius struct {
+	VolName                  string
+	DataPartitionCount       int
+	MetaPartitionCount       int
+	DataPartitionLeaderCount int
+	MetaPartitionLeaderCount int
+	AtQuorumDataPartitions   []uint64
+	LostQuorumDataPartitions []uint64
+	AtQuorumMetaPartitions   []uint64
+	LostQuorumMetaPartitions []uint64
+	DataSize                 uint64
+	InodeCount               uint64
+	DentryCount              uint64
+}
+
+// BlastRadius defines the volumes and partitions that would be affected by the loss of a node,
+// or of a disk of a data node if DiskPath is set.
+type BlastRadius struct {
+	Addr               string
+	DiskPath           string
+	DataPartitionCount int
+	MetaPartitionCount int
+	LeaderCount        int
+	AtQuorumCount      int
+	LostQuorumCount    int
+	DataSize           uint64
+	InodeCount         uint64
+	DentryCount        uint64
+	Vols               []*VolBlastRadius
+}
+
+// the reasons the replicas of a meta partition break the zone policy of the volume
+const (
+	ZoneViolationNotAllowed   = "zoneNotAllowed"   // a replica is out of the zones of the volume
+	ZoneViolationMultiZone    = "multiZone"        // the replicas of a volume not cross zone are in several zones
+	ZoneViolationConcentrated = "zoneConcentrated" // the replicas of a volume cross zone are in too few zones
+	ZoneViolationUnknownNode  = "unknownNode"      // a replica is on a meta node not in the cluster
+)
+
+// MetaPartitionZoneViolation is a meta partition whose replicas break the zone policy of the
+// volume, Zones counts its replicas in each zone.
+type MetaPartitionZoneViolation struct {
+	PartitionID uint64
+	Reasons     []string
+	Zones       map[string]int
+}
+
+// MetaPartitionFixOp is an operation of the plan restoring the zone policy, Op is the API to call
+// with the partition and the address of the replica.
+type MetaPartitionFixOp struct {
+	Op          string
+	PartitionID uint64
+	Addr        string
+	Zone        string
+	Reason      string
+}
+
+// VolMetaPartitionDistribution counts the replicas of the meta partitions of a volume in each
+// zone, nodeset and meta node.
+type VolMetaPartitionDistribution struct {
+	VolName            string
+	ZoneName           string
+	CrossZone          bool
+	ReplicaNum         uint8
+	MetaPartitionCount int
+	Zones              map[string]int
+	NodeSets           map[uint64]int
+	MetaNodes          map[string]int
+	Violations         []*MetaPartitionZoneViolation
+	FixPlan            []*MetaPartitionFixOp `json:",omitempty"`
+}
+
+// MetaPartitionDistribution is the distribution of the meta partitions of the volumes.
+type MetaPartitionDistribution struct {
+	ViolationCount int
+	Vols           []*VolMetaPartitionDistribution
 }
 
 // MetaNodeHeartbeatResponse defines the response to the meta node heartbeat request.
@@ -984,6 +1320,13 @@ type MetaNodeHeartbeatResponse struct {
 	Result                           string
 	CpuUtil                          float64 `json:"cpuUtil"`
 	ReceivedForbidWriteOpOfProtoVer0 bool
+	ReportSeq                        uint64   // seq of the meta partition reports
+	DeltaReport                      bool     // only the partitions changed since BaseReportSeq are reported
+	BaseReportSeq                    uint64   // seq of the reports the delta is based on
+	RemovedPartitions                []uint64 // partitions gone since BaseReportSeq of a delta report
+	Draining                         bool     // the node is drained for a restart
+	Drained                          bool     // the node leads no partition and has no request in flight
+	MediaType                        uint32   // of the disk the partitions are stored on
 }
 
 // LcNodeHeartbeatResponse defines the response to the lc node heartbeat.
@@ -1116,6 +1459,176 @@ type MetaPartitionLoadResponse struct {
 	RaftInfo    RaftInfo
 }
 
+// Trees of a meta partition whose CRCs are computed by OpMetaTreeCRC.
+const (
+	MetaTreeInode  = "inode"
+	MetaTreeDentry = "dentry"
+	MetaTreeExtend = "extend"
+
+	DefaultMetaTreeCRCRangeSize uint64 = 1 << 20
+)
+
+// MetaDentryRefRequest asks a meta partition for the dentries referring to the inode.
+type MetaDentryRefRequest struct {
+	PartitionID uint64
+	Inode       uint64
+}
+
+// MetaDentryRefResponse is the count of the dentries of a meta partition referring to the
+// inode, and of the children of it. The link count of the inode is set if the
+// partition has it.
+type MetaDentryRefResponse struct {
+	PartitionID uint64
+	ApplyID     uint64
+	Refs        uint32
+	Children    uint32
+	HasInode    bool
+	NLink       uint32
+	Type        uint32
+}
+
+// MetaRepairNLinkRequest sets the link count of the inode to NLink if it is still OldNLink.
+type MetaRepairNLinkRequest struct {
+	PartitionID uint64
+	Inode       uint64
+	OldNLink    uint32
+	NLink       uint32
+}
+
+// MetaNLinkRepairReport is the link count of the inode recounted from the dentries of the
+// volume, it is repaired if it differs and the repair is not a dry run.
+type MetaNLinkRepairReport struct {
+	VolName     string
+	Inode       uint64
+	PartitionID uint64
+	NLink       uint32
+	Expected    uint32
+	Refs        uint32
+	Children    uint32
+	DryRun      bool
+	Repaired    bool
+}
+
+// The ops on the purge of the inodes deleted of a volume or a meta partition.
+const (
+	PurgeOpPause  = "pause"
+	PurgeOpResume = "resume"
+	PurgeOpForce  = "force" // purges the backlog without waiting for a full batch, and resumes the purge
+)
+
+// VolPurgeCtrl controls the purge of the inodes deleted of a volume, a purge is forced once
+// ForceSeq changes.
+type VolPurgeCtrl struct {
+	Paused   bool
+	ForceSeq uint64
+}
+
+// MetaOpQuota is the requests per second of the meta ops of a volume taken by each metanode,
+// 0 is unlimited. It is not split among the metanodes, so the limit of the whole volume grows
+// with the metanodes its meta partitions are on.
+type MetaOpQuota struct {
+	CreateOps uint64 // of the inodes and the dentries created
+	DeleteOps uint64 // of the inodes unlinked and the dentries deleted
+	LookupOps uint64
+}
+
+// VolPurgeStatus is the purge control of a volume and the backlog of the purge reported by
+// the leaders of its meta partitions.
+type VolPurgeStatus struct {
+	VolName      string
+	Paused       bool
+	ForceSeq     uint64
+	BacklogCount uint64
+	BacklogBytes uint64
+	Partitions   []*MetaPartitionPurgeStatus
+}
+
+// MetaPartitionPurgeStatus is the purge of the inodes deleted of a meta partition, it is
+// paused if either the volume or the partition is.
+type MetaPartitionPurgeStatus struct {
+	PartitionID  uint64
+	Paused       bool
+	Forced       bool
+	BacklogCount uint64
+	BacklogBytes uint64
+}
+
+// MetaFreeListRebuildResult is the free list of a meta partition rebuilt from the inodes marked
+// deleted by the inode tree, on the replica replied.
+type MetaFreeListRebuildResult struct {
+	PartitionID uint64
+	Scanned     uint64 // the inodes of the inode tree
+	Added       uint64 // the inodes to free missing from the free list
+	HybridAdded uint64 // the inodes to delete the migration keys of missing from the hybrid list
+	Removed     uint64 // the entries of the free lists not to free
+	Total       uint64 // of the free list rebuilt
+	HybridTotal uint64 // of the hybrid list rebuilt
+}
+
+// MetaRaftLogTruncateRequest asks the leader of a meta partition to truncate the raft log up
+// to Index, which all the replicas have applied and the leader has stored the snapshot of.
+type MetaRaftLogTruncateRequest struct {
+	PartitionID uint64
+	Index       uint64
+}
+
+// MetaRaftLogTruncateResponse is the raft log of the leader after the truncation, the raft
+// keeps the retained logs of it anyway.
+type MetaRaftLogTruncateResponse struct {
+	PartitionID    uint64
+	Index          uint64
+	FirstIndex     uint64
+	AppliedIDs     map[string]uint64
+	WalSize        int64
+	ReclaimedBytes int64
+}
+
+// MetaTreeCRCRequest asks a meta partition replica for the CRCs of its trees, computed in
+// ranges of RangeSize inode IDs. Dentries are ranged by the parent inode ID.
+type MetaTreeCRCRequest struct {
+	PartitionID uint64
+	RangeSize   uint64
+}
+
+// MetaTreeRangeCRC is the CRC of the items of a tree in the inode ID range [Start, End).
+// Empty ranges are not reported.
+type MetaTreeRangeCRC struct {
+	Start uint64
+	End   uint64
+	Count uint64
+	CRC   uint32
+}
+
+// MetaTreeCRCResponse defines the response to the request of computing tree CRCs.
+type MetaTreeCRCResponse struct {
+	PartitionID uint64
+	Addr        string
+	ApplyID     uint64
+	RangeSize   uint64
+	Trees       map[string][]*MetaTreeRangeCRC
+}
+
+// MetaTreeRangeDiff is a range of a tree that diverges between the replicas, Replicas is keyed
+// by the replica address and misses the replicas that have no item in the range.
+type MetaTreeRangeDiff struct {
+	Tree     string
+	Start    uint64
+	End      uint64
+	Replicas map[string]*MetaTreeRangeCRC
+}
+
+// MetaPartitionTreeDiff defines the ranges of the trees that diverge between the replicas of a
+// meta partition. The diff may be transient if the replicas are not at the same apply ID.
+type MetaPartitionTreeDiff struct {
+	PartitionID uint64
+	VolName     string
+	RangeSize   uint64
+	SameApplyID bool
+	ApplyIDs    map[string]uint64
+	FailedHosts map[string]string
+	Divergent   []*MetaTreeRangeDiff
+}
+
 // DataPartitionResponse defines the response from a data node to the master that is related to a data partition.
 type DataPartitionResponse struct {
 	PartitionType int
@@ -1128,6 +1641,7 @@ type DataPartitionResponse struct {
 	IsRecover     bool
 	IsDiscard     bool
 	MediaType     uint32
+	Zones         []string `json:",omitempty"` // zones of the hosts
 }
 
 // DataPartitionsView defines the view of a data partition
@@ -1379,6 +1893,12 @@ type SimpleVolView struct {
 	EnableAutoDpMetaRepair  bool
 	AccessTimeInterval      int64
 	EnablePersistAccessTime bool
+	AtimeMode               string
+	XAttrLimit              XAttrLimit
+	ExtentConflictPolicy    string
+	EnableOpAudit           bool
+	EnableCheckExtentKey    bool
+	EnableDirStat           bool
 
 	// hybrid cloud
 	VolStorageClass          uint32
@@ -1400,6 +1920,14 @@ type SimpleVolView struct {
 
 	QosInfo QosSimpleInfo // qos status
 
+	DefaultXAttrs   map[string]string // xattrs set to every new inode of the volume
+	DpPins          []*DataPartitionPin
+	SourceVol       string               `json:",omitempty"` // the volume is a read-only replica of SourceVol if set
+	MetaMediaType   uint32               `json:",omitempty"` // the meta partitions are placed on the meta nodes of the media type
+	ReadOnlyWindows []*VolReadOnlyWindow `json:",omitempty"` // the partitions refuse modifications during the windows
+	MetaFrozen      bool                 `json:",omitempty"` // the meta partitions refuse modifications until unfrozen
+	MetaAuth        bool                 `json:",omitempty"` // the meta partitions refuse modifications without a meta access token
+
 	RemoteCacheRemoveDupReq bool // TODO: using it in metanode, origin was named EnableRemoveDupReq
 }
 
@@ -1417,6 +1945,27 @@ type NodeSetInfo struct {
 	DataNodes    []*DataNodeInfo
 }
 
+// pre-warm status of a volume
+const (
+	VolPreWarmRunning = "running"
+	VolPreWarmDone    = "done"
+	VolPreWarmFailed  = "failed"
+)
+
+// VolPreWarmStatus defines the progress of creating the initial partitions of a volume in background,
+// it is kept by the master leader only and lost if the leader changes.
+type VolPreWarmStatus struct {
+	Name          string
+	Status        string
+	TargetMpCount int
+	MpCount       int
+	TargetDpCount int
+	DpCount       int
+	StartTime     int64
+	EndTime       int64
+	ErrMsg        string
+}
+
 type QosItem struct {
 	Name    string
 	Type    uint32
@@ -1622,6 +2171,111 @@ func IsValidMediaType(mediaType uint32) bool {
 	return false
 }
 
+// VolClientInfo is a client mounting the volume, it is reported by the keepalive of the client.
+type VolClientInfo struct {
+	Host          string
+	Pid           int
+	Role          string
+	Version       string
+	MountTime     int64
+	LastKeepAlive int64
+}
+
+// SyncMetaReplicaRequest is sent to the leader of a meta partition of a replica volume, it reports
+// the last sync of the partition and, if Start is set, syncs the metadata of the partition from
+// the snapshot of the source partition on SourceHosts, tried in order.
+type SyncMetaReplicaRequest struct {
+	PartitionID       uint64
+	SourceVol         string
+	SourcePartitionID uint64
+	SourceHosts       []string
+	Start             bool
+}
+
+// MetaReplicaSyncStatus is the sync status of a meta partition of a replica volume.
+type MetaReplicaSyncStatus struct {
+	PartitionID       uint64
+	SourcePartitionID uint64
+	Syncing           bool
+	SourceApplyID     uint64 // apply ID of the source snapshot last synced
+	InodeCount        uint64
+	DentryCount       uint64
+	LastSyncTime      int64 // unix seconds of the last successful sync
+	LastError         string
+}
+
+// VolReplicaStatus is the sync status of a read-only replica volume.
+type VolReplicaStatus struct {
+	Name         string
+	SourceVol    string
+	SyncInterval int64 // seconds
+	LastSyncTime int64 // unix seconds when master started the last sync
+	Partitions   []*MetaReplicaSyncStatus
+}
+
+// NodeTaskStat is the queue of the admin tasks master holds for a node.
+type NodeTaskStat struct {
+	Addr          string
+	NodeType      string
+	Total         int
+	Pending       int            // not sent yet
+	Running       int            // sent and waiting for the response
+	Retried       int            // sent more than once
+	MaxSendCount  int            // of the tasks in the queue
+	OldestTaskAge int64          // seconds since the oldest task was created
+	OldestTask    string         // op of the oldest task
+	Ops           map[string]int `graphql:"-"` // tasks of each op
+	FailedSends   uint64         // sends failed since master started
+	LastSendError string
+	LastErrorTime int64 // unix seconds
+}
+
+// VolReadOnlyWindow is a maintenance window of a volume, e.g. for an external backup, during
+// which the data and meta partitions of the volume refuse modifications. Start and End are
+// unix seconds.
+type VolReadOnlyWindow struct {
+	ID     uint64
+	Start  int64
+	End    int64
+	Reason string `json:",omitempty"`
+}
+
+// InWindow checks whether the time of unix seconds is in the window.
+func (w *VolReadOnlyWindow) InWindow(now int64) bool {
+	return w.Start <= now && now < w.End
+}
+
+// DataPartitionPin makes the client prefer the data partitions of the given media type
+// and zone when writing files under the path prefix. The path is matched against the full
+// path of a file when its extents are written, the longest pin wins, so a file renamed out
+// of the path is written by another pin or none from then on, and the extents written
+// before stay where they are.
+type DataPartitionPin struct {
+	Path      string
+	MediaType uint32 `json:",omitempty"`
+	ZoneName  string `json:",omitempty"`
+}
+
+// Match checks whether the data partition satisfies the pin, all of its hosts
+// should be in the pinned zone.
+func (pin *DataPartitionPin) Match(dp *DataPartitionResponse) bool {
+	if pin.MediaType != MediaType_Unspecified && dp.MediaType != pin.MediaType {
+		return false
+	}
+	if pin.ZoneName == "" {
+		return true
+	}
+	if len(dp.Zones) == 0 {
+		return false
+	}
+	for _, zone := range dp.Zones {
+		if zone != pin.ZoneName {
+			return false
+		}
+	}
+	return true
+}
+
 type StorageClass uint32
 
 const (
@@ -1725,6 +2379,23 @@ type VolEmptyMpStats struct {
 	MetaPartitions []*MetaPartitionView `json:"metaPartitions"`
 }
 
+// EmptyMpMergePair is a pair of adjacent meta partitions of a volume, the higher one of which
+// is empty and merged into the lower one.
+type EmptyMpMergePair struct {
+	LowerID     uint64 `json:"lowerId"`
+	LowerStart  uint64 `json:"lowerStart"`
+	LowerEnd    uint64 `json:"lowerEnd"`
+	HigherID    uint64 `json:"higherId"`
+	HigherStart uint64 `json:"higherStart"`
+	HigherEnd   uint64 `json:"higherEnd"`
+}
+
+type VolEmptyMpMerge struct {
+	Name   string             `json:"name"`
+	DryRun bool               `json:"dryRun"`
+	Pairs  []EmptyMpMergePair `json:"pairs"`
+}
+
 // FreezeMetaPartitionRequest defines the request of freezing a meta partition.
 type FreezeMetaPartitionRequest struct {
 	PartitionID uint64
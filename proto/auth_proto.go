@@ -206,6 +206,7 @@ const (
 	MsgMasterSetNodeInfoReq      MsgType = MsgMasterAPIAccessReq + 0x20400
 	MsgMasterSetNodeRdOnlyReq    MsgType = MsgMasterAPIAccessReq + 0x20500
 	MsgMasterAutoDecommissionReq MsgType = MsgMasterAPIAccessReq + 0x20600
+	MsgMasterSetNodeStateReq     MsgType = MsgMasterAPIAccessReq + 0x20700
 
 	// Master API volume management
 	MsgMasterCreateVolReq              MsgType = MsgMasterAPIAccessReq + 0x30100
@@ -295,6 +296,7 @@ var MsgType2ResourceMap = map[MsgType]string{
 	MsgMasterSetNodeInfoReq:      "master:setnodeinfo",
 	MsgMasterSetNodeRdOnlyReq:    "master:sernoderdonly",
 	MsgMasterAutoDecommissionReq: "master:autodecommission",
+	MsgMasterSetNodeStateReq:     "master:setnodestate",
 
 	// Master API volume management
 	MsgMasterCreateVolReq:              "master:createvol",
@@ -27,6 +27,10 @@ const (
 	RootIno    = uint64(1)
 	SummaryKey = "cbfs.dir.summary"
 	QuotaKey   = "qa"
+
+	// InnerXAttrPrefix is the prefix of the xattrs kept by the meta node itself, they are
+	// never set by the clients.
+	InnerXAttrPrefix = "cfs_inner_xattr_"
 )
 
 const (
@@ -103,6 +107,20 @@ type InodeInfo struct {
 	MigrationStorageClass         uint32    `json:"migrationStorageClass"`
 	HasMigrationEk                bool      `json:"hasMigrationEk"`
 	MigrationExtentKeyExpiredTime time.Time `json:"mekExpiredTime"`
+	PreAllocated                  bool      `json:"preAlloc"`
+	DirStat                       *DirStat  `json:"dirStat,omitempty"`
+}
+
+// DirStat is kept by the metanodes on the directories of the vols enabling it, so that the usage
+// of a subtree is got without walking it. The children are counted by the dentry ops, the files,
+// dirs and bytes of the subtree are estimates, counted for the direct children by the dentry ops
+// and summed up the tree by the reconciliation of the metanodes in background. It is eventually
+// consistent, a stat may lag behind the subtree until the reconciliation corrects it.
+type DirStat struct {
+	Children uint64 `json:"children"`
+	Files    uint64 `json:"files"`
+	Dirs     uint64 `json:"dirs"`
+	Size     uint64 `json:"size"`
 }
 
 type SimpleExtInfo struct {
@@ -560,6 +578,19 @@ type LookupRequest struct {
 	Name        string `json:"name"`
 	VerSeq      uint64 `json:"seq"`
 	VerAll      bool   `json:"verAll"`
+	Siblings    uint32 `json:"siblings,omitempty"` // the siblings following the name to return at most
+}
+
+// the siblings returned by a lookup at most
+const MaxLookupSiblings = 128
+
+// LookupSibling is a dentry following the one looked up in its parent, with the inode of it if
+// the inode is in the meta partition of the parent.
+type LookupSibling struct {
+	Name  string     `json:"name"`
+	Inode uint64     `json:"ino"`
+	Type  uint32     `json:"type"`
+	Info  *InodeInfo `json:"info,omitempty"`
 }
 
 type DetryInfo struct {
@@ -571,10 +602,11 @@ type DetryInfo struct {
 
 // LookupResponse defines the response for the loopup request.
 type LookupResponse struct {
-	Inode  uint64      `json:"ino"`
-	Mode   uint32      `json:"mode"`
-	VerSeq uint64      `json:"seq"`
-	LayAll []DetryInfo `json:"layerInfo"`
+	Inode    uint64          `json:"ino"`
+	Mode     uint32          `json:"mode"`
+	VerSeq   uint64          `json:"seq"`
+	LayAll   []DetryInfo     `json:"layerInfo"`
+	Siblings []LookupSibling `json:"siblings,omitempty"`
 }
 
 // InodeGetRequest defines the request to get the inode.
@@ -667,6 +699,31 @@ type ReadDirLimitResponse struct {
 	Children []Dentry `json:"children"`
 }
 
+// ReadDirPlusRequest reads the dentries of the dir as ReadDirLimitRequest does, with the inodes
+// of them kept in the partition, for the client to answer a readdirplus in one request.
+type ReadDirPlusRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	ParentID    uint64 `json:"pino"`
+	Marker      string `json:"marker"`
+	Limit       uint64 `json:"limit"`
+}
+
+// MaxReadDirPlusLimit is the dentries a readdirplus returns at most, also if its limit is 0.
+const MaxReadDirPlusLimit = 1024
+
+type DirentPlus struct {
+	Dentry
+	Info *InodeInfo `json:"info,omitempty"` // nil if the inode is not in the partition
+}
+
+// ReadDirPlusResponse has the inodes of the children kept in other partitions in Remote, to be
+// fetched from them in batches.
+type ReadDirPlusResponse struct {
+	Children []DirentPlus `json:"children"`
+	Remote   []uint64     `json:"remote,omitempty"`
+}
+
 // AppendExtentKeyRequest defines the request to append an extent key.
 type AppendExtentKeyRequest struct {
 	VolName     string    `json:"vol"`
@@ -686,6 +743,15 @@ type AppendExtentKeyWithCheckRequest struct {
 	IsCache        bool
 	StorageClass   uint32 `json:"storageClass"`
 	IsMigration    bool
+	// generation of the inode the extents of the writer are based on, the appends overlapping
+	// other extents are decided by it under the extent conflict policy, zero if unknown.
+	Generation uint64 `json:"gen,omitempty"`
+}
+
+// AppendExtentKeyResponse is replied to the append with check, the writer whose extents were of
+// the generation before the append takes the generation for its next appends.
+type AppendExtentKeyResponse struct {
+	Generation uint64 `json:"gen"`
 }
 
 func (ap *AppendExtentKeyWithCheckRequest) EkString() string {
@@ -751,6 +817,18 @@ type TruncateRequest struct {
 	RequestExtend
 }
 
+// ExtentsPreAllocRequest defines the request to reserve the range [Offset, Offset+Size) of
+// an inode before it is written. The size of the inode is kept if KeepSize is set.
+type ExtentsPreAllocRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	Inode       uint64 `json:"ino"`
+	Offset      uint64 `json:"off"`
+	Size        uint64 `json:"sz"`
+	KeepSize    bool   `json:"keepSize"`
+	RequestExtend
+}
+
 type EmptyExtentKeyRequest struct {
 	VolName     string `json:"vol"`
 	PartitionID uint64 `json:"pid"`
@@ -785,6 +863,94 @@ type SetAttrRequest struct {
 	VerSeq      uint64 `json:"seq"`
 }
 
+// UpdateLinkTargetRequest defines the request to rewrite the target of a symlink.
+type UpdateLinkTargetRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	Inode       uint64 `json:"ino"`
+	Target      []byte `json:"tgt"`
+	ModifyTime  int64  `json:"mt"`
+	VerSeq      uint64 `json:"seq"`
+}
+
+// CreateDentryInodeItem is an inode to create together with its dentry by a bulk import.
+type CreateDentryInodeItem struct {
+	ParentID uint64      `json:"pino"`
+	Name     string      `json:"name"`
+	Mode     uint32      `json:"mode"`
+	Uid      uint32      `json:"uid"`
+	Gid      uint32      `json:"gid"`
+	Size     uint64      `json:"sz"`
+	Target   []byte      `json:"tgt"`
+	Extents  []ExtentKey `json:"eks"`
+}
+
+// BatchCreateDentryInodeRequest defines the request to create many inodes and dentries in one
+// raft command, all the parents must belong to the partition.
+type BatchCreateDentryInodeRequest struct {
+	VolName     string                   `json:"vol"`
+	PartitionID uint64                   `json:"pid"`
+	UniqID      uint64                   `json:"uiq"`
+	Items       []*CreateDentryInodeItem `json:"items"`
+	StorageType uint32                   `json:"storageType"`
+}
+
+type CreateDentryInodeResult struct {
+	ParentID uint64 `json:"pino"`
+	Name     string `json:"name"`
+	Inode    uint64 `json:"ino"`
+	Status   uint8  `json:"status"`
+}
+
+// BatchCreateDentryInodeResponse has the results in the order of the items of the request.
+type BatchCreateDentryInodeResponse struct {
+	Results []*CreateDentryInodeResult `json:"results"`
+}
+
+// DeleteSubtreeRequest defines the request to remove the entry Name of ParentID and everything
+// below it, the job runs on the leader of the partition of ParentID.
+type DeleteSubtreeRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	ParentID    uint64 `json:"pino"`
+	Name        string `json:"name"`
+}
+
+type DeleteSubtreeResponse struct {
+	JobID uint64 `json:"job"`
+}
+
+// DeleteSubtreeStatusRequest defines the request to get the progress of a subtree deletion,
+// the job is canceled first if Cancel is set.
+type DeleteSubtreeStatusRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	JobID       uint64 `json:"job"`
+	Cancel      bool   `json:"cancel"`
+}
+
+const (
+	DeleteSubtreeRunning  = "running"
+	DeleteSubtreeDone     = "done"
+	DeleteSubtreeFailed   = "failed"
+	DeleteSubtreeCanceled = "canceled"
+)
+
+// DeleteSubtreeJob is the progress of a subtree deletion.
+type DeleteSubtreeJob struct {
+	JobID           uint64 `json:"job"`
+	ParentID        uint64 `json:"pino"`
+	Name            string `json:"name"`
+	Inode           uint64 `json:"ino"`
+	Status          string `json:"status"`
+	DeletedDentries uint64 `json:"dentries"`
+	DeletedInodes   uint64 `json:"inodes"`
+	Skipped         uint64 `json:"skipped"` // entries failed to delete, left in place
+	Error           string `json:"err"`
+	StartTime       int64  `json:"start"`
+	EndTime         int64  `json:"end"`
+}
+
 const (
 	AttrMode uint32 = 1 << iota
 	AttrUid
@@ -793,6 +959,99 @@ const (
 	AttrAccessTime
 )
 
+// atime mode of volume, decides when the access time of inode is updated by the accesses,
+// the access time set by setattr is applied in any mode. The accesses update it only if the
+// volume enables EnablePersistAccessTime. A volume without a mode updates it once the valid
+// interval has passed.
+const (
+	AtimeModeRelatime = "relatime" // update if atime is older than mtime or the valid interval
+	AtimeModeStrict   = "strict"   // update on every access
+	AtimeModeNoatime  = "noatime"  // never update on access
+)
+
+func IsValidAtimeMode(mode string) bool {
+	switch mode {
+	case AtimeModeRelatime, AtimeModeStrict, AtimeModeNoatime:
+		return true
+	default:
+		return false
+	}
+}
+
+// extent conflict policy of volume, decides how an appended extent key is handled if it
+// overlaps the keys of other extents and the writer is not based on the current extents of
+// the inode, as told by the generation of the append. The appends of the writer based on the
+// current extents, such as the fallback of its own overwrites, always go on.
+const (
+	ExtentConflictPolicyNone   = ""       // only the appends with discard check are rejected
+	ExtentConflictPolicyReject = "reject" // the appends are rejected, the ones without generation too
+	ExtentConflictPolicyLWW    = "lww"    // the stale appends are retried by the writers on the current extents
+)
+
+func IsValidExtentConflictPolicy(policy string) bool {
+	switch policy {
+	case ExtentConflictPolicyNone, ExtentConflictPolicyReject, ExtentConflictPolicyLWW:
+		return true
+	default:
+		return false
+	}
+}
+
+// XAttrLimit limits the xattrs of an inode, zero means unlimited.
+type XAttrLimit struct {
+	MaxCount     uint32 // number of xattrs
+	MaxKeySize   uint32 // bytes of a key
+	MaxValueSize uint32 // bytes of a value
+	MaxTotalSize uint64 // bytes of all the keys and values
+}
+
+// Merge returns the limit that the zero fields of l are taken from def.
+func (l XAttrLimit) Merge(def XAttrLimit) XAttrLimit {
+	if l.MaxCount == 0 {
+		l.MaxCount = def.MaxCount
+	}
+	if l.MaxKeySize == 0 {
+		l.MaxKeySize = def.MaxKeySize
+	}
+	if l.MaxValueSize == 0 {
+		l.MaxValueSize = def.MaxValueSize
+	}
+	if l.MaxTotalSize == 0 {
+		l.MaxTotalSize = def.MaxTotalSize
+	}
+	return l
+}
+
+// Check returns an error if xattrs exceed the non-zero fields of l.
+func (l XAttrLimit) Check(xattrs map[string]string) error {
+	var total uint64
+	for key, value := range xattrs {
+		if l.MaxKeySize > 0 && uint32(len(key)) > l.MaxKeySize {
+			return fmt.Errorf("size of xattr key(%v) is %v, exceeds limit %v", key, len(key), l.MaxKeySize)
+		}
+		if l.MaxValueSize > 0 && uint32(len(value)) > l.MaxValueSize {
+			return fmt.Errorf("size of xattr(%v) value is %v, exceeds limit %v", key, len(value), l.MaxValueSize)
+		}
+		total += uint64(len(key) + len(value))
+	}
+	if l.MaxCount > 0 && uint64(len(xattrs)) > uint64(l.MaxCount) {
+		return fmt.Errorf("count of xattrs is %v, exceeds limit %v", len(xattrs), l.MaxCount)
+	}
+	if l.MaxTotalSize > 0 && total > l.MaxTotalSize {
+		return fmt.Errorf("size of xattrs is %v, exceeds limit %v", total, l.MaxTotalSize)
+	}
+	return nil
+}
+
+// xattrPairEscaper escapes the separators of the xattrs in form of "key1:value1,key2:value2".
+var xattrPairEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `:`, `\:`)
+
+// EscapeXAttrPair escapes the key or the value of an xattr in form of "key1:value1,key2:value2",
+// a backslash keeps the next char as it is.
+func EscapeXAttrPair(s string) string {
+	return xattrPairEscaper.Replace(s)
+}
+
 // DeleteInodeRequest defines the request to delete an inode.
 type DeleteInodeRequest struct {
 	VolName     string `json:"vol"`
@@ -899,6 +1158,25 @@ type BatchGetXAttrResponse struct {
 	XAttrs      []*XAttrInfo
 }
 
+// ScanXAttrRequest scans the xattrs of the inodes of a partition in the order of the inodes
+// from the marker, only the keys given or with the prefix are returned if set.
+type ScanXAttrRequest struct {
+	VolName     string   `json:"vol"`
+	PartitionId uint64   `json:"pid"`
+	Marker      uint64   `json:"marker"`
+	Limit       uint64   `json:"limit"`
+	Keys        []string `json:"keys"`
+	Prefix      string   `json:"prefix"`
+	VerSeq      uint64   `json:"seq"`
+}
+
+type ScanXAttrResponse struct {
+	VolName     string       `json:"vol"`
+	PartitionId uint64       `json:"pid"`
+	XAttrs      []*XAttrInfo `json:"xattrs"`
+	NextMarker  uint64       `json:"next"` // the marker to resume the scan from, 0 if it is done
+}
+
 type UpdateXAttrRequest struct {
 	VolName     string `json:"vol"`
 	PartitionId uint64 `json:"pid"`
@@ -1070,6 +1348,16 @@ type GetAppliedIDRequest struct {
 	PartitionId uint64 `json:"pid"`
 }
 
+// SnapshotProgressRequest tells the leader how many chunks of its snapshot the follower has
+// verified, so a resent snapshot skips them.
+type SnapshotProgressRequest struct {
+	PartitionID uint64 `json:"pid"`
+	NodeID      uint64 `json:"nid"`
+	ApplyID     uint64 `json:"aid"` // of the snapshot
+	Chunks      uint32 `json:"chunks"`
+	Done        bool   `json:"done"` // the snapshot is applied
+}
+
 type LockDirRequest struct {
 	VolName     string    `json:"vol"`
 	PartitionId uint64    `json:"pid"`
@@ -1089,6 +1377,73 @@ type LockDirResponse struct {
 	Status uint8 `json:"status"`
 }
 
+// the types of the file locks
+const (
+	FileLockRead   uint8 = 1 // shared
+	FileLockWrite  uint8 = 2 // exclusive
+	FileLockUnlock uint8 = 3
+)
+
+// FileLockOwner is the client owning file locks, the locks of a client are released once its
+// lease expires.
+type FileLockOwner struct {
+	ClientID uint64 `json:"cid"`
+	ClientIP string `json:"cip"`
+}
+
+// FileLock is a lock of the bytes [Start, End) of an inode, End 0 is the end of file. A flock locks
+// the whole file and does not conflict with the POSIX locks. Owner is the lock owner in the client, and Expire is the unix
+// second the lease of the client expires at.
+type FileLock struct {
+	FileLockOwner
+	Owner  uint64 `json:"owner"`
+	Pid    uint32 `json:"lpid"`
+	Type   uint8  `json:"type"`
+	Start  uint64 `json:"start"`
+	End    uint64 `json:"end"`
+	Flock  bool   `json:"flock"`
+	Expire int64  `json:"expire"`
+}
+
+// FileLockRequest acquires, releases, or with Test only checks, a lock of the inode. A blocking
+// request waits up to WaitMs for the conflicting locks to be released.
+type FileLockRequest struct {
+	VolName     string    `json:"vol"`
+	PartitionID uint64    `json:"pid"`
+	Inode       uint64    `json:"ino"`
+	Lock        FileLock  `json:"lock"`
+	Test        bool      `json:"test"`
+	Block       bool      `json:"block"`
+	WaitMs      uint32    `json:"waitMs"`
+	Lease       uint32    `json:"lease"` // unit seconds
+	SubmitTime  time.Time `json:"submitTime"`
+}
+
+// FileLockResponse returns the conflicting lock if the lock is not acquired.
+type FileLockResponse struct {
+	Status   uint8     `json:"status"`
+	Conflict *FileLock `json:"conflict,omitempty"`
+}
+
+// FileLockHolder is an owner in the client holding locks of an inode.
+type FileLockHolder struct {
+	Inode uint64 `json:"ino"`
+	Owner uint64 `json:"owner"`
+}
+
+// FileLockLeaseRequest renews the lease of the locks of the client in the partition, or releases
+// them if Release. If Holders is set, only the locks of the holders are renewed, and those of
+// the owners gone are left to expire.
+type FileLockLeaseRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	FileLockOwner
+	Holders    []FileLockHolder `json:"holders,omitempty"`
+	Lease      uint32           `json:"lease"` // unit seconds
+	Release    bool             `json:"release"`
+	SubmitTime time.Time        `json:"submitTime"`
+}
+
 type InodeAccessTime struct {
 	Inode      uint64    `json:"ino"`
 	AccessTime time.Time `json:"at"`
@@ -1141,3 +1496,26 @@ type UpdateInodeMetaRequest struct {
 	Inode       uint64 `json:"ino"`
 	PartitionID uint64 `json:"pid"`
 }
+
+// AttrLeaseRequest renews the attr lease of the client on the partition, acquires the leases
+// of Inodes and releases the ones of Release. It waits up to TimeoutMs for an invalidation if
+// there is none yet. Epoch is the one of the last response, zero for the first request.
+type AttrLeaseRequest struct {
+	VolName     string   `json:"vol"`
+	PartitionID uint64   `json:"pid"`
+	ClientID    uint64   `json:"cid"`
+	Epoch       uint64   `json:"epoch"`
+	Inodes      []uint64 `json:"inos,omitempty"`
+	Release     []uint64 `json:"release,omitempty"`
+	TimeoutMs   int64    `json:"timeoutMs"`
+}
+
+// AttrLeaseResponse has the inodes of the client changed since the last request. All is set if
+// the leases of the epoch of the request are lost, e.g. the leader of the partition is changed,
+// the client is to drop the attrs of all its inodes and acquire their leases again then.
+type AttrLeaseResponse struct {
+	Epoch       uint64   `json:"epoch"`
+	LeaseMs     int64    `json:"leaseMs"` // the leases expire unless renewed within it
+	Invalidated []uint64 `json:"invalidated"`
+	All         bool     `json:"all,omitempty"`
+}
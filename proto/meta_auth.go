@@ -0,0 +1,125 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The meta partitions of a vol enforcing the meta auth refuse the modifying ops without a
+// token issued by master to a user authorized to write the vol. A token is signed by a key of
+// the vol, the key rotated out stays valid until the tokens signed by it have expired.
+const (
+	MetaAccessTokenTTL     = 3600 // seconds
+	MetaAccessKeyCount     = 2    // the current key and the one rotated out
+	MetaAccessKeyLen       = 32
+	MaxMetaAccessTokenLen  = 1024
+	MetaTokenRequestWindow = 300 // seconds the signature of a token request is valid for
+)
+
+var (
+	ErrMetaAccessTokenMissing = errors.New("meta access token is missing")
+	ErrMetaAccessTokenExpired = errors.New("meta access token is expired")
+	ErrMetaAccessTokenInvalid = errors.New("meta access token is invalid")
+)
+
+// MetaAccessKey is a key the meta access tokens of a vol are signed by.
+type MetaAccessKey struct {
+	ID         uint32 `json:"id"`
+	Secret     string `json:"secret"` // hex
+	CreateTime int64  `json:"createTime"`
+}
+
+type MetaAccessToken struct {
+	Vol    string `json:"vol"`
+	UserID string `json:"user"`
+	KeyID  uint32 `json:"kid"`
+	Expire int64  `json:"exp"`
+	Sign   string `json:"sign"`
+}
+
+func (t *MetaAccessToken) sign(key *MetaAccessKey) string {
+	mac := hmac.New(sha256.New, []byte(key.Secret))
+	fmt.Fprintf(mac, "%v\n%v\n%v\n%v", t.Vol, t.UserID, t.KeyID, t.Expire)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignBy signs the token by the key.
+func (t *MetaAccessToken) SignBy(key *MetaAccessKey) {
+	t.KeyID = key.ID
+	t.Sign = t.sign(key)
+}
+
+// Verify checks the token is signed by one of the keys of the vol and not expired.
+func (t *MetaAccessToken) Verify(vol string, keys []*MetaAccessKey, now time.Time) error {
+	if t.Vol != vol {
+		return ErrMetaAccessTokenInvalid
+	}
+	for _, key := range keys {
+		if key.ID != t.KeyID {
+			continue
+		}
+		if !hmac.Equal([]byte(t.sign(key)), []byte(t.Sign)) {
+			return ErrMetaAccessTokenInvalid
+		}
+		if now.Unix() > t.Expire {
+			return ErrMetaAccessTokenExpired
+		}
+		return nil
+	}
+	return ErrMetaAccessTokenInvalid
+}
+
+func (t *MetaAccessToken) Encode() (token string, err error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeMetaAccessToken(token []byte) (t *MetaAccessToken, err error) {
+	data := make([]byte, base64.RawURLEncoding.DecodedLen(len(token)))
+	n, err := base64.RawURLEncoding.Decode(data, token)
+	if err != nil {
+		return nil, ErrMetaAccessTokenInvalid
+	}
+	t = &MetaAccessToken{}
+	if err = json.Unmarshal(data[:n], t); err != nil {
+		return nil, ErrMetaAccessTokenInvalid
+	}
+	return
+}
+
+// MetaTokenRequestSign is the signature of the token request of a user, which proves it has
+// the secret key without sending it.
+func MetaTokenRequestSign(secretKey, vol, accessKey string, ts int64) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	fmt.Fprintf(mac, "%v\n%v\n%v", vol, accessKey, ts)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MetaAccessTokenReply has no token if the vol does not enforce the meta auth.
+type MetaAccessTokenReply struct {
+	Token  string `json:"token"`
+	Expire int64  `json:"expire"`
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaAccessToken(t *testing.T) {
+	now := time.Now()
+	key1 := &proto.MetaAccessKey{ID: 1, Secret: "secret1"}
+	key2 := &proto.MetaAccessKey{ID: 2, Secret: "secret2"}
+	token := &proto.MetaAccessToken{Vol: "vol", UserID: "user", Expire: now.Unix() + 10}
+	token.SignBy(key1)
+	s, err := token.Encode()
+	require.NoError(t, err)
+	got, err := proto.DecodeMetaAccessToken([]byte(s))
+	require.NoError(t, err)
+	require.Equal(t, token, got)
+
+	require.NoError(t, got.Verify("vol", []*proto.MetaAccessKey{key1, key2}, now))
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, got.Verify("other", []*proto.MetaAccessKey{key1}, now))
+	// the key is rotated out
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, got.Verify("vol", []*proto.MetaAccessKey{key2}, now))
+	require.Equal(t, proto.ErrMetaAccessTokenExpired, got.Verify("vol", []*proto.MetaAccessKey{key1}, now.Add(time.Minute)))
+
+	got.UserID = "admin"
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, got.Verify("vol", []*proto.MetaAccessKey{key1}, now))
+	_, err = proto.DecodeMetaAccessToken([]byte("not a token"))
+	require.Equal(t, proto.ErrMetaAccessTokenInvalid, err)
+}
+
+func TestPacketAccessToken(t *testing.T) {
+	proto.InitBufferPool(32768)
+	p := proto.NewPacket()
+	p.Opcode = proto.OpMetaCreateInode
+	p.ExtentType = proto.PacketProtocolVersionFlag
+	p.ReqID = proto.GenerateRequestID()
+	require.Nil(t, p.AccessToken())
+	p.SetAccessToken("token")
+
+	client, server := net.Pipe()
+	errC := make(chan error, 1)
+	go func() { errC <- p.WriteToConn(client) }()
+	got := proto.NewPacket()
+	require.NoError(t, got.ReadFromConnWithVer(server, proto.ReadDeadlineTime))
+	require.NoError(t, <-errC)
+	client.Close()
+	server.Close()
+	require.Equal(t, []byte("token"), got.AccessToken())
+	require.False(t, got.IsFollowerReadMetaPkt())
+
+	// the arg of the follower read is kept
+	p = proto.NewPacket()
+	p.Arg = []byte{proto.FollowerReadFlag}
+	p.ArgLen = 1
+	p.SetAccessToken("token")
+	require.Nil(t, p.AccessToken())
+	require.True(t, p.IsFollowerReadMetaPkt())
+}
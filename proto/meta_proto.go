@@ -42,13 +42,14 @@ type Peer struct {
 
 // CreateMetaPartitionRequest defines the request to create a meta partition.
 type CreateMetaPartitionRequest struct {
-	MetaId      string
-	VolName     string
-	Start       uint64
-	End         uint64
-	PartitionID uint64
-	Members     []Peer
-	VerSeq      uint64
+	MetaId        string
+	VolName       string
+	Start         uint64
+	End           uint64
+	PartitionID   uint64
+	Members       []Peer
+	VerSeq        uint64
+	DefaultXAttrs map[string]string
 }
 
 // CreateMetaPartitionResponse defines the response to the request of creating a meta partition.
@@ -50,6 +50,10 @@ type MetaNodeInfo struct {
 	CanAllowPartition         bool
 	MaxMpCntLimit             uint64  `json:"maxMpCntLimit"`
 	CpuUtil                   float64 `json:"cpuUtil"`
+	Draining                  bool    `json:"draining"`
+	Drained                   bool    `json:"drained"` // safe to restart
+	NoPlacement               bool    `json:"noPlacement"`
+	MediaType                 uint32  `json:"mediaType"`
 }
 
 // DataNode stores all the information about a data node
@@ -130,6 +134,7 @@ type MetaReplicaInfo struct {
 	MaxInode        uint64
 	DentryCount     uint64
 	ReadOnlyReasons uint32
+	Stat            *MetaPartitionStat
 }
 
 // ClusterView provides the view of a cluster.
@@ -175,6 +180,7 @@ type ClusterView struct {
 	FlashNodes                                []NodeView
 	FlashNodeHandleReadTimeout                int
 	FlashNodeReadDataNodeTimeout              int
+	StuckTaskNodes                            []*NodeTaskStat // the nodes with admin tasks queued too long
 }
 
 // ClusterNode defines the structure of a cluster node
@@ -315,6 +321,7 @@ type VolStatInfo struct {
 	MetaFollowerRead        bool
 	MaximallyRead           bool
 	LeaderRetryTimeOut      int
+	AtimeMode               string
 	StatByStorageClass      []*StatOfStorageClass
 	StatMigrateStorageClass []*StatOfStorageClass
 	StatByDpMediaType       []*StatOfStorageClass
@@ -481,6 +488,89 @@ type DiskInfos struct {
 	Disks []DiskInfo
 }
 
+// DiagAlarm is an alarm raised by master.
+type DiagAlarm struct {
+	Time string
+	Key  string
+	Msg  string
+}
+
+// DiagBundle is the diagnostics of the cluster gathered by master in one go, with the secrets
+// redacted. Errors has the sections failed to be gathered, the others are kept.
+type DiagBundle struct {
+	Cluster                string
+	LeaderAddr             string
+	CreateTime             string
+	Topology               interface{}
+	Vols                   []*SimpleVolView
+	DataPartitionDiagnosis *DataPartitionDiagnosis
+	MetaPartitionDiagnosis *MetaPartitionDiagnosis
+	BadDisks               DiskInfos
+	NodeInfo               map[string]string
+	Alarms                 []DiagAlarm
+	Errors                 []string
+}
+
+// ClusterConfigVersion is the version of the cluster config exported, an import of the other
+// versions is refused.
+const ClusterConfigVersion = 1
+
+// the actions planned or taken by the import of a cluster config
+const (
+	ClusterConfigActionCreate  = "create"
+	ClusterConfigActionUpdate  = "update"
+	ClusterConfigActionExists  = "exists"
+	ClusterConfigActionMissing = "missing" // not created by the import, e.g. the zones of the nodes
+	ClusterConfigActionError   = "error"
+)
+
+// ClusterConfigVol is the settings of a volume exported, without the partitions.
+type ClusterConfigVol struct {
+	*SimpleVolView
+	DpSize uint64 // GB
+}
+
+type ClusterConfigNodeSet struct {
+	ID        uint64
+	DataNodes []string
+	MetaNodes []string
+}
+
+type ClusterConfigZone struct {
+	Name          string
+	Status        string
+	DataMediaType string
+	NodeSets      []*ClusterConfigNodeSet
+}
+
+// ClusterConfig is the metadata configuration of the cluster exported by master, to rebuild
+// a cluster after a disaster or to clone it to a staging one. The secret keys of the users
+// are kept for the clients to access the volumes rebuilt.
+type ClusterConfig struct {
+	Version    int
+	Cluster    string
+	CreateTime string
+	Zones      []*ClusterConfigZone
+	NodeInfo   map[string]string
+	Vols       []*ClusterConfigVol
+	Users      []*UserInfo
+}
+
+type ClusterConfigAction struct {
+	Kind   string // zone, user, vol, userPolicy or nodeInfo
+	Name   string
+	Action string
+	Msg    string `json:",omitempty"`
+}
+
+// ClusterConfigImportResult is the actions of an import of the cluster config, planned only
+// if DryRun.
+type ClusterConfigImportResult struct {
+	DryRun  bool
+	Actions []*ClusterConfigAction
+	Failed  int
+}
+
 type DiscardDataPartitionInfos struct {
 	DiscardDps []DataPartitionInfo
 }
@@ -669,4 +759,5 @@ type MetaNodeView struct {
 	ForbidWriteOpOfProtoVer0 bool
 	Ratio                    float64
 	SystemRatio              float64
+	NoPlacement              bool
 }
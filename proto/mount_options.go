@@ -64,6 +64,13 @@ const (
 	ReadThreads
 	WriteThreads
 	MetaSendTimeout
+	MetaCompression
+	LookupPrefetch
+	ReadDirPlus
+	MetaTraceSampleRate
+	MetaMuxConns
+	MetaMuxPortShift
+	AttrLease
 	BuffersTotalLimit
 	MaxStreamerLimit
 	EnableAudit
@@ -88,6 +95,9 @@ const (
 	// remotecache
 	ForceRemoteCache
 
+	// the file locks shared by the clients of volume
+	EnableFileLock
+
 	MaxMountOption
 )
 
@@ -166,6 +176,13 @@ func InitMountOptions(opts []MountOption) {
 	opts[ReadThreads] = MountOption{"readThreads", "Cold volume read threads", "", int64(10)}
 	opts[WriteThreads] = MountOption{"writeThreads", "Cold volume write threads", "", int64(10)}
 	opts[MetaSendTimeout] = MountOption{"metaSendTimeout", "Meta send timeout", "", int64(600)}
+	opts[MetaCompression] = MountOption{"metaCompression", "Accept the compressed responses from the metanodes", "", false}
+	opts[LookupPrefetch] = MountOption{"lookupPrefetch", "The siblings prefetched by a lookup into the inode cache, 0 disables it", "", int64(0)}
+	opts[ReadDirPlus] = MountOption{"readDirPlus", "Read the dirs with the inodes of the dentries in one request, the metanodes must support it", "", false}
+	opts[MetaTraceSampleRate] = MountOption{"metaTraceSampleRate", "The ratio in [0, 1] of the meta requests traced across the metanodes, 0 disables it, the requests carrying the access tokens or of follower read are not traced", "", ""}
+	opts[MetaMuxConns] = MountOption{"metaMuxConns", "The connections to a metanode the meta requests are multiplexed over, 0 disables it", "", int64(0)}
+	opts[MetaMuxPortShift] = MountOption{"metaMuxPortShift", "The shift of the smux port of the metanodes, the smuxPortShift of the metanodes, 0 is the default", "", int64(0)}
+	opts[AttrLease] = MountOption{"attrLease", "Lease the attrs of the open files from the metanodes to drop them once changed by the other clients, the metanodes must support it", "", false}
 	opts[BuffersTotalLimit] = MountOption{"buffersTotalLimit", "Send/Receive packets memory limit", "", int64(32768)} // default 4G
 	opts[BufferChanSize] = MountOption{"buffersChanSize", "Send/Receive buffer chan size", "", int64(256)}            // default 256
 	opts[MaxStreamerLimit] = MountOption{"maxStreamerLimit", "The maximum number of streamers", "", int64(0)}         // default 0
@@ -188,6 +205,8 @@ func InitMountOptions(opts []MountOption) {
 	opts[AheadReadWindowCnt] = MountOption{"aheadReadWindowCnt", "ahead read window block count", "", int64(8)}
 
 	opts[ForceRemoteCache] = MountOption{"forceRemoteCache", "All read requests are handled by the remote cache.", "", false}
+
+	opts[EnableFileLock] = MountOption{"enableFileLock", "Enable the flocks and POSIX locks shared by the clients of volume", "", false}
 	for i := 0; i < MaxMountOption; i++ {
 		flag.StringVar(&opts[i].cmdlineValue, opts[i].keyword, "", opts[i].description)
 	}
@@ -340,6 +359,13 @@ type MountOptions struct {
 	EnableUnixPermission    bool
 	NeedRestoreFuse         bool
 	MetaSendTimeout         int64
+	MetaCompression         bool
+	LookupPrefetch          int64
+	ReadDirPlus             bool
+	MetaTraceSampleRate     float64
+	MetaMuxConns            int64
+	MetaMuxPortShift        int64
+	AttrLease               bool
 	BuffersTotalLimit       int64
 	BufferChanSize          int64
 	MaxStreamerLimit        int64
@@ -368,4 +394,6 @@ type MountOptions struct {
 
 	// remote cache
 	ForceRemoteCache bool
+
+	EnableFileLock bool
 }
@@ -0,0 +1,55 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// The namespace events of a meta partition are kept in a ring on each replica of it as the
+// raft entries are applied, so the raft index of an event is the token to resume the watch
+// from on any replica.
+const (
+	NamespaceEventCreate  = "create"  // a dentry is created
+	NamespaceEventUpdate  = "update"  // a dentry is pointed to another inode, as done by a rename over it
+	NamespaceEventUnlink  = "unlink"  // a dentry is deleted
+	NamespaceEventSetAttr = "setattr" // the attrs of an inode are set
+)
+
+type NamespaceEvent struct {
+	Index    uint64 `json:"index"` // raft index of the event
+	Type     string `json:"type"`
+	ParentID uint64 `json:"pid,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Inode    uint64 `json:"ino"`
+	OldInode uint64 `json:"oldIno,omitempty"` // the inode the dentry updated pointed to
+	Mode     uint32 `json:"mode,omitempty"`
+	Time     int64  `json:"time"`
+}
+
+// WatchEventsRequest asks for the events after From, waiting up to TimeoutMs for one if there
+// are none yet. From zero starts the watch from the events to come.
+type WatchEventsRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	From        uint64 `json:"from"`
+	Limit       int    `json:"limit"`
+	TimeoutMs   int64  `json:"timeoutMs"`
+}
+
+// WatchEventsResponse has the events in the order of their indexes, the events of a raft
+// entry are never split. Lost is set if the events right after From are no longer kept, the
+// watcher is to rebuild its view of the namespace then.
+type WatchEventsResponse struct {
+	Events []*NamespaceEvent `json:"events"`
+	Next   uint64            `json:"next"` // From of the next watch
+	Lost   bool              `json:"lost,omitempty"`
+}
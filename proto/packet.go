@@ -49,6 +49,8 @@ func GenerateRequestID() int64 {
 const (
 	AddrSplit        = "/"
 	FollowerReadFlag = 'F'
+	AccessTokenFlag  = 'T'
+	TraceContextFlag = 'R'
 )
 
 // Operations
@@ -125,6 +127,12 @@ const (
 	OpBackupEmptyMetaPartition      uint8 = 0x4A
 	OpRemoveBackupMetaPartition     uint8 = 0x4B
 	OpIsRaftStatusOk                uint8 = 0x4C
+	OpMetaTreeCRC                   uint8 = 0x4D
+	OpSyncMetaReplica               uint8 = 0x4E
+	OpMetaReadSnapshot              uint8 = 0x4F // MetaNode -> MetaNode
+	OpMetaCountDentryRef            uint8 = 0x5C
+	OpMetaRepairNLink               uint8 = 0x5D
+	OpMetaRaftLogTruncate           uint8 = 0x5E
 
 	// Quota
 	OpMetaBatchSetInodeQuota    uint8 = 0x50
@@ -198,9 +206,23 @@ const (
 	OpMetaTxGet          uint8 = 0xAB
 
 	// Operations: Client -> MetaNode.
-	OpMetaGetUniqID       uint8 = 0xAC
-	OpMetaGetAppliedID    uint8 = 0xAD
-	OpMetaUpdateInodeMeta uint8 = 0xAE
+	OpMetaGetUniqID              uint8 = 0xAC
+	OpMetaGetAppliedID           uint8 = 0xAD
+	OpMetaUpdateInodeMeta        uint8 = 0xAE
+	OpMetaUpdateLinkTarget       uint8 = 0xAF
+	OpMetaBatchCreateDentryInode uint8 = 0xB0
+	OpMetaExtentsPreAlloc        uint8 = 0xB9
+	OpMetaDeleteSubtree          uint8 = 0xBA
+	OpMetaDeleteSubtreeStatus    uint8 = 0xBB
+	OpMetaWatchEvents            uint8 = 0xBD
+	OpMetaReadDirPlus            uint8 = 0xBE
+	OpMetaFileLock               uint8 = 0xC0
+	OpMetaFileLockLease          uint8 = 0xC1
+	OpMetaScanXAttr              uint8 = 0xC2
+	OpMetaAttrLease              uint8 = 0xC3
+
+	// Operations: MetaNode Follower -> MetaNode Leader.
+	OpMetaSnapshotProgress uint8 = 0xBC
 
 	// Multi version snapshot
 	OpRandomWriteAppend     uint8 = 0xB1
@@ -294,6 +316,18 @@ const (
 	OpLeaseGenerationNotMatch           uint8 = 0x87
 	OpWriteOpOfProtoVerForbidden        uint8 = 0x88
 	OpMetaForbiddenMigration            uint8 = 0x89
+	OpXAttrLimitExceeded                uint8 = 0x8D
+	// the partition takes no new inodes, the client creates the inode on another partition at once:
+	// the inode ID cursor reached the end of the partition, or the partition is frozen by the memory watermark
+	OpCursorExhausted uint8 = 0x8E
+	OpPartitionFrozen uint8 = 0x8F
+	// the inode linked to is not of the volume or the partition of the request, hard links never cross volumes
+	OpCrossVolumeNotSupported uint8 = 0x94
+	// the meta op of the request is over the quota of the rate of it of the volume on the metanode
+	OpMetaOpRateLimited uint8 = 0x95
+	// the append is based on the extents older than the ones of the inode, the writer refreshes
+	// them and retries under the lww extent conflict policy
+	OpStaleExtentsErr uint8 = 0x96
 	// Distributed cache related OP codes.
 	OpFlashNodeHeartbeat        uint8 = 0xDA
 	OpFlashNodeCachePrepare     uint8 = 0xDB
@@ -317,6 +351,9 @@ const (
 	MultiVersionFlag                          = 0x80
 	VersionListFlag                           = 0x40
 	PacketProtocolVersionFlag                 = 0x10
+	PacketAcceptCompressFlag                  = 0x20 // set by the client if the response may be compressed
+	PacketCompressedFlag                      = 0x08 // set by the metanode if the data of the response is compressed
+	PacketBackgroundFlag                      = 0x02 // set by the client if the request is of a background job
 
 	DefaultRemoteCacheTTL               = 5 * 24 * 3600
 	DefaultRemoteCacheClientReadTimeout = 100 // ms
@@ -545,6 +582,10 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaReadDirLimit"
 	case OpMetaLockDir:
 		m = "OpMetaLockDir"
+	case OpMetaFileLock:
+		m = "OpMetaFileLock"
+	case OpMetaFileLockLease:
+		m = "OpMetaFileLockLease"
 	case OpMetaInodeGet:
 		m = "OpMetaInodeGet"
 	case OpMetaBatchInodeGet:
@@ -583,6 +624,18 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpUpdateMetaPartition"
 	case OpLoadMetaPartition:
 		m = "OpLoadMetaPartition"
+	case OpMetaTreeCRC:
+		m = "OpMetaTreeCRC"
+	case OpMetaCountDentryRef:
+		m = "OpMetaCountDentryRef"
+	case OpMetaRepairNLink:
+		m = "OpMetaRepairNLink"
+	case OpMetaRaftLogTruncate:
+		m = "OpMetaRaftLogTruncate"
+	case OpSyncMetaReplica:
+		m = "OpSyncMetaReplica"
+	case OpMetaReadSnapshot:
+		m = "OpMetaReadSnapshot"
 	case OpDecommissionMetaPartition:
 		m = "OpDecommissionMetaPartition"
 	case OpCreateDataPartition:
@@ -655,6 +708,10 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaListXAttr"
 	case OpMetaBatchGetXAttr:
 		m = "OpMetaBatchGetXAttr"
+	case OpMetaScanXAttr:
+		m = "OpMetaScanXAttr"
+	case OpMetaAttrLease:
+		m = "OpMetaAttrLease"
 	case OpMetaUpdateXAttr:
 		m = "OpMetaUpdateXAttr"
 	case OpCreateMultipart:
@@ -699,6 +756,22 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaTxGet"
 	case OpMetaGetAppliedID:
 		m = "OpMetaGetAppliedId"
+	case OpMetaUpdateLinkTarget:
+		m = "OpMetaUpdateLinkTarget"
+	case OpMetaBatchCreateDentryInode:
+		m = "OpMetaBatchCreateDentryInode"
+	case OpMetaExtentsPreAlloc:
+		m = "OpMetaExtentsPreAlloc"
+	case OpMetaDeleteSubtree:
+		m = "OpMetaDeleteSubtree"
+	case OpMetaDeleteSubtreeStatus:
+		m = "OpMetaDeleteSubtreeStatus"
+	case OpMetaWatchEvents:
+		m = "OpMetaWatchEvents"
+	case OpMetaReadDirPlus:
+		m = "OpMetaReadDirPlus"
+	case OpMetaSnapshotProgress:
+		m = "OpMetaSnapshotProgress"
 	case OpMetaBatchSetInodeQuota:
 		m = "OpMetaBatchSetInodeQuota"
 	case OpMetaBatchDeleteInodeQuota:
@@ -854,6 +927,18 @@ func (p *Packet) GetResultMsg() (m string) {
 		m = "OpLeaseGenerationNotMatch"
 	case OpWriteOpOfProtoVerForbidden:
 		m = "OpWriteOpOfProtoVerForbidden"
+	case OpXAttrLimitExceeded:
+		m = "OpXAttrLimitExceeded"
+	case OpCursorExhausted:
+		m = "CursorExhausted"
+	case OpPartitionFrozen:
+		m = "PartitionFrozen"
+	case OpCrossVolumeNotSupported:
+		m = "CrossVolumeNotSupported"
+	case OpMetaOpRateLimited:
+		m = "MetaOpRateLimited"
+	case OpStaleExtentsErr:
+		m = "StaleExtentsErr"
 	default:
 		return fmt.Sprintf("Unknown ResultCode(%v)", p.ResultCode)
 	}
@@ -1116,12 +1201,25 @@ func (p *Packet) IsReadMetaPkt() bool {
 	if p.Opcode == OpMetaLookup || p.Opcode == OpMetaInodeGet || p.Opcode == OpMetaBatchInodeGet ||
 		p.Opcode == OpMetaReadDir || p.Opcode == OpMetaExtentsList || p.Opcode == OpGetMultipart ||
 		p.Opcode == OpMetaGetXAttr || p.Opcode == OpMetaListXAttr || p.Opcode == OpListMultiparts ||
-		p.Opcode == OpMetaBatchGetXAttr || p.Opcode == OpMetaObjExtentsList || p.Opcode == OpMetaReadDirLimit || p.Opcode == OpMetaGetInodeQuota {
+		p.Opcode == OpMetaBatchGetXAttr || p.Opcode == OpMetaObjExtentsList || p.Opcode == OpMetaReadDirLimit || p.Opcode == OpMetaGetInodeQuota ||
+		p.Opcode == OpMetaReadDirPlus || p.Opcode == OpMetaScanXAttr {
 		return true
 	}
 	return false
 }
 
+// SetMetaTimeout sets the time the client waits for the response of the meta packet, so
+// that metanode can drop the request once the client stops waiting. Meta packets carry no
+// kernel offset, the field holds the timeout in milliseconds and zero means no timeout.
+func (p *Packet) SetMetaTimeout(timeout time.Duration) {
+	p.KernelOffset = uint64(timeout / time.Millisecond)
+}
+
+// MetaTimeout returns the timeout set by SetMetaTimeout.
+func (p *Packet) MetaTimeout() time.Duration {
+	return time.Duration(p.KernelOffset) * time.Millisecond
+}
+
 // ReadFromConn reads the data from the given connection.
 // Recognize the version bit and parse out version,
 // to avoid version field rsp back , the rsp of random write from datanode with replace OpRandomWriteVer to OpRandomWriteVerRsp
@@ -1474,9 +1572,10 @@ func (p *Packet) ShouldRetryWithVersionList() bool {
 	return p.ResultCode == OpAgainVerionList
 }
 
-// ShallRetry returns if we should retry the packet.
+// ShallRetry returns if we should retry the packet. The meta ops over the rate quota of the
+// volume are retried with the backoff of the other retries too.
 func (p *Packet) ShouldRetry() bool {
-	return p.ResultCode == OpAgain || p.ResultCode == OpErr
+	return p.ResultCode == OpAgain || p.ResultCode == OpErr || p.ResultCode == OpMetaOpRateLimited
 }
 
 func (p *Packet) IsBatchDeleteExtents() bool {
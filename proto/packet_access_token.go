@@ -0,0 +1,36 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// SetAccessToken has the meta packet carry the meta access token in its arg, led by
+// AccessTokenFlag as the follower read flag does. The modifying ops have no other arg, a
+// metanode not checking the tokens ignores it.
+func (p *Packet) SetAccessToken(token string) {
+	if token == "" || len(token) > MaxMetaAccessTokenLen || p.ArgLen > 0 {
+		return
+	}
+	p.Arg = make([]byte, 1+len(token))
+	p.Arg[0] = AccessTokenFlag
+	copy(p.Arg[1:], token)
+	p.ArgLen = uint32(len(p.Arg))
+}
+
+// AccessToken returns the meta access token carried by the packet, nil if there is none.
+func (p *Packet) AccessToken() []byte {
+	if p.ArgLen <= 1 || int(p.ArgLen) > len(p.Arg) || p.Arg[0] != AccessTokenFlag {
+		return nil
+	}
+	return p.Arg[1:p.ArgLen]
+}
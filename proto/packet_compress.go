@@ -0,0 +1,128 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// the codecs of the packet data, the compressed data starts with the codec
+const (
+	PacketCompressNone uint8 = iota
+	PacketCompressGzip
+	PacketCompressZstd
+)
+
+const DefaultPacketCompressThreshold = 64 * 1024
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ParsePacketCompressCodec returns the codec of the name, none for an empty one.
+func ParsePacketCompressCodec(name string) (codec uint8, err error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return PacketCompressNone, nil
+	case "gzip":
+		return PacketCompressGzip, nil
+	case "zstd":
+		return PacketCompressZstd, nil
+	default:
+		return PacketCompressNone, fmt.Errorf("unknown packet compress codec %v", name)
+	}
+}
+
+func (p *Packet) IsCompressed() bool {
+	return p.ExtentType&PacketCompressedFlag == PacketCompressedFlag
+}
+
+func (p *Packet) AcceptCompress() bool {
+	return p.ExtentType&PacketAcceptCompressFlag == PacketAcceptCompressFlag
+}
+
+// CompressData compresses the data of the packet with the codec, the data is kept as it is
+// if it is already compressed or not smaller once compressed.
+func (p *Packet) CompressData(codec uint8) (err error) {
+	if p.IsCompressed() || codec == PacketCompressNone || p.Size == 0 {
+		return
+	}
+	data := p.Data[:p.Size]
+	var out []byte
+	switch codec {
+	case PacketCompressGzip:
+		buf := bytes.NewBuffer(make([]byte, 0, len(data)/2))
+		buf.WriteByte(codec)
+		w := gzip.NewWriter(buf)
+		if _, err = w.Write(data); err != nil {
+			return
+		}
+		if err = w.Close(); err != nil {
+			return
+		}
+		out = buf.Bytes()
+	case PacketCompressZstd:
+		out = zstdEncoder.EncodeAll(data, append(make([]byte, 0, len(data)/2), codec))
+	default:
+		return fmt.Errorf("unknown packet compress codec %v", codec)
+	}
+	if len(out) >= len(data) {
+		return
+	}
+	p.Data = out
+	p.Size = uint32(len(out))
+	p.ExtentType |= PacketCompressedFlag
+	return
+}
+
+// DecompressData restores the data of the packet compressed by CompressData.
+func (p *Packet) DecompressData() (err error) {
+	if !p.IsCompressed() {
+		return
+	}
+	if p.Size == 0 {
+		return fmt.Errorf("compressed packet has no data")
+	}
+	data := p.Data[:p.Size]
+	var out []byte
+	switch data[0] {
+	case PacketCompressGzip:
+		var r *gzip.Reader
+		if r, err = gzip.NewReader(bytes.NewReader(data[1:])); err != nil {
+			return
+		}
+		defer r.Close()
+		if out, err = io.ReadAll(r); err != nil {
+			return
+		}
+	case PacketCompressZstd:
+		if out, err = zstdDecoder.DecodeAll(data[1:], nil); err != nil {
+			return
+		}
+	default:
+		return fmt.Errorf("unknown packet compress codec %v", data[0])
+	}
+	p.Data = out
+	p.Size = uint32(len(out))
+	p.ExtentType &^= PacketCompressedFlag
+	return
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketCompress(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"ino":1,"mode":420,"nlink":1}`), 1000)
+	for _, name := range []string{"gzip", "zstd"} {
+		codec, err := proto.ParsePacketCompressCodec(name)
+		require.NoError(t, err)
+		p := proto.NewPacket()
+		p.ExtentType = proto.PacketProtocolVersionFlag
+		p.Data = append([]byte{}, data...)
+		p.Size = uint32(len(data))
+		require.NoError(t, p.CompressData(codec))
+		require.True(t, p.IsCompressed(), name)
+		require.Less(t, int(p.Size), len(data))
+		// compressed only once
+		size := p.Size
+		require.NoError(t, p.CompressData(codec))
+		require.Equal(t, size, p.Size)
+
+		require.NoError(t, p.DecompressData())
+		require.False(t, p.IsCompressed())
+		require.Equal(t, data, p.Data)
+		require.EqualValues(t, len(data), p.Size)
+		require.EqualValues(t, proto.PacketProtocolVersionFlag, p.ExtentType)
+	}
+
+	// data not smaller once compressed is kept
+	p := proto.NewPacket()
+	p.Data = []byte("ok")
+	p.Size = 2
+	require.NoError(t, p.CompressData(proto.PacketCompressZstd))
+	require.False(t, p.IsCompressed())
+	require.NoError(t, p.DecompressData())
+	require.Equal(t, []byte("ok"), p.Data)
+
+	p.ExtentType |= proto.PacketCompressedFlag
+	p.Data = []byte{9, 1}
+	require.Error(t, p.DecompressData())
+	codec, err := proto.ParsePacketCompressCodec("")
+	require.NoError(t, err)
+	require.Equal(t, proto.PacketCompressNone, codec)
+	_, err = proto.ParsePacketCompressCodec("lz4")
+	require.Error(t, err)
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// The priority classes of the requests to the metanode, the class is carried by
+// PacketBackgroundFlag of the header, an older metanode ignores it.
+const (
+	PacketPriorityInteractive uint8 = iota // the ops of the users, e.g. by fuse
+	PacketPriorityBackground               // the batch jobs, e.g. scrubbing, compaction or lifecycle scanning
+
+	PacketPriorityCount = 2
+)
+
+func (p *Packet) SetPriority(priority uint8) {
+	if priority == PacketPriorityBackground {
+		p.ExtentType |= PacketBackgroundFlag
+	} else {
+		p.ExtentType &^= PacketBackgroundFlag
+	}
+}
+
+func (p *Packet) GetPriority() uint8 {
+	if p.ExtentType&PacketBackgroundFlag == PacketBackgroundFlag {
+		return PacketPriorityBackground
+	}
+	return PacketPriorityInteractive
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"encoding/binary"
+)
+
+// traceContextArgLen is the size of the arg carrying the trace context, the flag and the
+// ids of the trace and the span.
+const traceContextArgLen = 1 + 16
+
+// SetTraceContext has the meta packet carry the trace of the request and the span of the sender
+// in its arg, led by TraceContextFlag as the follower read and the access token flags do. The
+// context is carried only if the packet has no other arg, a metanode not tracing the ops
+// ignores it.
+func (p *Packet) SetTraceContext(traceID, spanID uint64) {
+	if p.ArgLen > 0 && !p.HasTraceContext() {
+		return
+	}
+	p.Arg = make([]byte, traceContextArgLen)
+	p.Arg[0] = TraceContextFlag
+	binary.BigEndian.PutUint64(p.Arg[1:9], traceID)
+	binary.BigEndian.PutUint64(p.Arg[9:], spanID)
+	p.ArgLen = uint32(len(p.Arg))
+}
+
+// ClearTraceContext drops the trace context carried by the packet, if any.
+func (p *Packet) ClearTraceContext() {
+	if p.HasTraceContext() {
+		p.Arg = nil
+		p.ArgLen = 0
+	}
+}
+
+func (p *Packet) HasTraceContext() bool {
+	return p.ArgLen == traceContextArgLen && len(p.Arg) >= traceContextArgLen && p.Arg[0] == TraceContextFlag
+}
+
+// TraceContext returns the ids of the trace and the span carried by the packet, zeros if there
+// are none.
+func (p *Packet) TraceContext() (traceID, spanID uint64) {
+	if !p.HasTraceContext() {
+		return
+	}
+	return binary.BigEndian.Uint64(p.Arg[1:9]), binary.BigEndian.Uint64(p.Arg[9:traceContextArgLen])
+}
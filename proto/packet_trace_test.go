@@ -0,0 +1,86 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketTraceContext(t *testing.T) {
+	proto.InitBufferPool(32768)
+	for _, traced := range []bool{false, true} {
+		p := proto.NewPacket()
+		p.Opcode = proto.OpMetaLookup
+		p.ExtentType = proto.PacketProtocolVersionFlag
+		p.ReqID = proto.GenerateRequestID()
+		p.Data = []byte(`{"name":"a"}`)
+		p.Size = uint32(len(p.Data))
+		if traced {
+			p.SetTraceContext(1, 2)
+		}
+
+		client, server := net.Pipe()
+		errC := make(chan error, 1)
+		go func() { errC <- p.WriteToConn(client) }()
+		got := proto.NewPacket()
+		require.NoError(t, got.ReadFromConnWithVer(server, proto.ReadDeadlineTime))
+		require.NoError(t, <-errC)
+		client.Close()
+		server.Close()
+
+		require.Equal(t, traced, got.HasTraceContext())
+		traceID, spanID := got.TraceContext()
+		if traced {
+			require.EqualValues(t, 1, traceID)
+			require.EqualValues(t, 2, spanID)
+		} else {
+			require.Zero(t, traceID)
+			require.Zero(t, spanID)
+		}
+		require.Equal(t, p.ReqID, got.ReqID)
+		require.Equal(t, p.ExtentType, got.ExtentType)
+		require.Equal(t, p.Data, got.Data)
+	}
+}
+
+func TestPacketTraceContextArg(t *testing.T) {
+	// the context of a retry replaces the one of the last try
+	p := proto.NewPacket()
+	p.SetTraceContext(1, 2)
+	p.SetTraceContext(3, 4)
+	traceID, spanID := p.TraceContext()
+	require.EqualValues(t, 3, traceID)
+	require.EqualValues(t, 4, spanID)
+	p.ClearTraceContext()
+	require.False(t, p.HasTraceContext())
+	require.Zero(t, p.ArgLen)
+
+	// the other args are kept
+	p.SetAccessToken("token")
+	p.SetTraceContext(1, 2)
+	require.False(t, p.HasTraceContext())
+	p.ClearTraceContext()
+	require.Equal(t, []byte("token"), p.AccessToken())
+
+	p = proto.NewPacket()
+	p.ArgLen = 1
+	p.Arg = []byte{proto.FollowerReadFlag}
+	p.SetTraceContext(1, 2)
+	require.True(t, p.IsFollowerReadMetaPkt())
+}
@@ -62,10 +62,14 @@ const (
 	MpCursorOutOfRange uint32 = 1 << 0
 	MetaMemUseLimit    uint32 = 1 << 1
 	MetaNodeReadOnly   uint32 = 1 << 2
+	MetaMemFrozen      uint32 = 1 << 3
+	MpApplyFailed      uint32 = 1 << 4
 )
 
 var MpReasonMessages = map[uint32]string{
 	MpCursorOutOfRange: "mp cursor out of Range",
 	MetaMemUseLimit:    "meta mem use reached maximum limit",
 	MetaNodeReadOnly:   "MetaNode is read-only",
+	MetaMemFrozen:      "mp frozen by meta mem watermark",
+	MpApplyFailed:      "mp failed by a panic of apply",
 }
@@ -68,6 +68,12 @@ type Partition interface {
 
 	// Truncate raft log
 	Truncate(index uint64)
+
+	// FirstIndex returns the first index of the raft log kept.
+	FirstIndex() uint64
+
+	// WalPath returns the path of the raft log.
+	WalPath() string
 	TryToLeader(nodeID uint64) error
 	IsOfflinePeer() bool
 
@@ -187,6 +193,17 @@ func (p *partition) Truncate(index uint64) {
 	}
 }
 
+func (p *partition) FirstIndex() uint64 {
+	if p.raft == nil {
+		return 0
+	}
+	return p.raft.FirstCommittedIndex(p.id)
+}
+
+func (p *partition) WalPath() string {
+	return p.walPath
+}
+
 // Backup stops and rename the partition.
 func (p *partition) CloseAndBackup() (err error) {
 	if err = p.Stop(); err != nil {
@@ -65,6 +65,7 @@ type ExtentCache struct {
 	sync.RWMutex
 	inode   uint64
 	gen     uint64 // generation number
+	metaGen uint64 // generation of the extents on the meta node the cache is based on
 	size    uint64 // size of the cache
 	root    *btree.BTree
 	discard *btree.BTree
@@ -132,6 +133,7 @@ func (cache *ExtentCache) update(gen, size uint64, force bool, eks []proto.Exten
 	}
 
 	cache.gen = gen
+	cache.metaGen = gen
 	cache.size = size
 	cache.root.Clear(false)
 	for _, ek := range eks {
@@ -143,6 +145,23 @@ func (cache *ExtentCache) update(gen, size uint64, force bool, eks []proto.Exten
 	}
 }
 
+// MetaGen returns the generation of the extents on the meta node the cache is based on.
+func (cache *ExtentCache) MetaGen() uint64 {
+	cache.RLock()
+	defer cache.RUnlock()
+	return cache.metaGen
+}
+
+// AdvanceMetaGen moves the cache to gen after an append based on the generation from, if the
+// append is the only change of the extents on the meta node since then.
+func (cache *ExtentCache) AdvanceMetaGen(from, gen uint64) {
+	cache.Lock()
+	defer cache.Unlock()
+	if from != 0 && cache.metaGen == from && gen == from+1 {
+		cache.metaGen = gen
+	}
+}
+
 // Split extent key.
 func (cache *ExtentCache) SplitExtentKey(inodeID uint64, ekPivot *proto.ExtentKey) (err error) {
 	cache.Lock()
@@ -55,7 +55,7 @@ const (
 
 type (
 	SplitExtentKeyFunc            func(parentInode, inode uint64, key proto.ExtentKey, storageClass uint32) error
-	AppendExtentKeyFunc           func(parentInode, inode uint64, key proto.ExtentKey, discard []proto.ExtentKey, isCache bool, storageClass uint32, isMigration bool) (int, error)
+	AppendExtentKeyFunc           func(parentInode, inode uint64, key proto.ExtentKey, discard []proto.ExtentKey, gen uint64, isCache bool, storageClass uint32, isMigration bool) (int, uint64, error)
 	GetExtentsFunc                func(inode uint64, isCache bool, openForWrite bool, isMigration bool) (uint64, uint64, []proto.ExtentKey, error)
 	TruncateFunc                  func(inode, size uint64, fullPath string) error
 	EvictIcacheFunc               func(inode uint64)
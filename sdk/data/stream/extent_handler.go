@@ -559,8 +559,8 @@ func (eh *ExtentHandler) appendExtentKey() (err error) {
 			var status int
 			ekey := *eh.key
 			doAppend := func() (err error) {
-				discard := eh.stream.extents.Append(&ekey, true)
-				status, err = eh.stream.client.appendExtentKey(eh.stream.parentInode, eh.inode, ekey, discard, eh.stream.isCache, eh.storageClass, eh.isMigration)
+				var discard []proto.ExtentKey
+				status, discard, err = eh.stream.appendExtentKey(&ekey, eh.storageClass, eh.isMigration)
 				if atomic.LoadInt32(&eh.stream.needUpdateVer) > 0 {
 					if errUpdateExtents := eh.stream.GetExtentsForceRefresh(); errUpdateExtents != nil {
 						log.LogErrorf("action[appendExtentKey] inode %v GetExtents err %v errUpdateExtents %v", eh.stream.inode, err, errUpdateExtents)
@@ -729,7 +729,7 @@ func (eh *ExtentHandler) allocateExtent() (err error) {
 
 	for i := 0; i < MaxSelectDataPartitionForWrite; i++ {
 		if eh.key == nil {
-			if dp, err = eh.stream.client.dataWrapper.GetDataPartitionForWrite(exclude, eh.storageClass, eh.id); err != nil {
+			if dp, err = eh.stream.client.dataWrapper.GetDataPartitionForWriteByPath(exclude, eh.storageClass, eh.id, eh.stream.fullPath); err != nil {
 				log.LogWarnf("allocateExtent: failed to get write data partition, eh(%v) exclude(%v), "+
 					"clear exclude and try again!", eh, exclude)
 				exclude = make(map[string]struct{})
@@ -26,6 +26,7 @@ import (
 	"github.com/cubefs/cubefs/datanode/storage"
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/sdk/data/wrapper"
+	"github.com/cubefs/cubefs/sdk/meta"
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
@@ -36,6 +37,7 @@ const (
 	MaxNewHandlerRetry             = 3
 	MaxPacketErrorCount            = 128
 	MaxDirtyListLen                = 0
+	maxStaleExtentsRetry           = 3 // appends retried on the current extents under last-writer-wins
 )
 
 const (
@@ -601,9 +603,8 @@ func (s *Streamer) doDirectWriteByAppend(req *ExtentRequest, direct bool, op uin
 			return
 		}
 	} else {
-		discards := s.extents.Append(extKey, true)
 		var st int
-		if st, err = s.client.appendExtentKey(s.parentInode, s.inode, *extKey, discards, s.isCache, storageClass, isMigration); err != nil {
+		if st, _, err = s.appendExtentKey(extKey, storageClass, isMigration); err != nil {
 			status = int32(st)
 			log.LogErrorf("action[doDirectWriteByAppend] inode %v meta extent split process err %v", s.inode, err)
 			return
@@ -624,6 +625,32 @@ func (s *Streamer) doDirectWriteByAppend(req *ExtentRequest, direct bool, op uin
 	return
 }
 
+// appendExtentKey appends ek to the local extents and to the meta node. The append carries the
+// generation of the extents it is based on, so that the meta node tells the stale one under the
+// last-writer-wins policy, which is then retried on the current extents of the inode.
+func (s *Streamer) appendExtentKey(ek *proto.ExtentKey, storageClass uint32, isMigration bool) (status int, discard []proto.ExtentKey, err error) {
+	for retry := 0; ; retry++ {
+		discard = s.extents.Append(ek, true)
+		gen := s.extents.MetaGen()
+		var newGen uint64
+		status, newGen, err = s.client.appendExtentKey(s.parentInode, s.inode, *ek, discard, gen, s.isCache, storageClass, isMigration)
+		if err == nil {
+			s.extents.AdvanceMetaGen(gen, newGen)
+			return
+		}
+		if status != meta.StatusStaleExtents || retry >= maxStaleExtentsRetry {
+			return
+		}
+		log.LogWarnf("action[appendExtentKey] inode %v ek %v based on stale extents gen %v, retry %v", s.inode, ek, gen, retry)
+		// the discards are taken again from the current extents
+		s.extents.RemoveDiscard(discard)
+		if errRefresh := s.GetExtentsForceRefresh(); errRefresh != nil {
+			log.LogErrorf("action[appendExtentKey] inode %v GetExtents err %v", s.inode, errRefresh)
+			return
+		}
+	}
+}
+
 func (s *Streamer) doOverwrite(req *ExtentRequest, direct bool, storageClass uint32) (total int, err error) {
 	var dp *wrapper.DataPartition
 
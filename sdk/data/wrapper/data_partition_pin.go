@@ -0,0 +1,134 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package wrapper
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+func (w *Wrapper) updateDpPins(pins []*proto.DataPartitionPin) {
+	w.Lock.Lock()
+	defer w.Lock.Unlock()
+	if len(pins) != len(w.dpPins) {
+		log.LogInfof("updateDpPins: volume(%v) dp pins count from (%v) to (%v)", w.VolName, len(w.dpPins), len(pins))
+	}
+	w.dpPins = pins
+	w.pinnedDps = make(map[string][]*DataPartition, len(pins))
+	for _, dp := range w.partitions {
+		w.indexPinnedPartition(dp)
+	}
+}
+
+// indexPinnedPartition adds dp to the partitions of each pin it matches, the caller holds w.Lock.
+func (w *Wrapper) indexPinnedPartition(dp *DataPartition) {
+	for _, pin := range w.dpPins {
+		if pin.Match(&dp.DataPartitionResponse) {
+			w.pinnedDps[pin.Path] = append(w.pinnedDps[pin.Path], dp)
+		}
+	}
+}
+
+// reindexPinnedPartition moves dp to the pins it matches after its zones changed, the caller holds w.Lock.
+func (w *Wrapper) reindexPinnedPartition(dp *DataPartition) {
+	for _, pin := range w.dpPins {
+		dps := w.pinnedDps[pin.Path]
+		for i := range dps {
+			if dps[i].PartitionID == dp.PartitionID {
+				dps = append(dps[:i:i], dps[i+1:]...)
+				break
+			}
+		}
+		if pin.Match(&dp.DataPartitionResponse) {
+			dps = append(dps, dp)
+		}
+		w.pinnedDps[pin.Path] = dps
+	}
+}
+
+func sameZones(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchDpPin returns the pin with the longest path which is fullPath itself or one of its parents.
+func matchDpPin(pins []*proto.DataPartitionPin, fullPath string) (pin *proto.DataPartitionPin) {
+	if fullPath == "" {
+		return nil
+	}
+	for _, p := range pins {
+		if p.Path != "/" && fullPath != p.Path && !strings.HasPrefix(fullPath, p.Path+"/") {
+			continue
+		}
+		if pin == nil || len(p.Path) > len(pin.Path) {
+			pin = p
+		}
+	}
+	return
+}
+
+func (w *Wrapper) getDpPin(fullPath string) *proto.DataPartitionPin {
+	w.Lock.RLock()
+	defer w.Lock.RUnlock()
+	if len(w.dpPins) == 0 {
+		return nil
+	}
+	return matchDpPin(w.dpPins, fullPath)
+}
+
+// selectPinnedDataPartition selects a writable partition of pin with the media type of the storage
+// class of the file. Master rejects the pins to another media type, but the storage class of the
+// vol may change after, such a pin has no partition for the file.
+func (w *Wrapper) selectPinnedDataPartition(pin *proto.DataPartitionPin, exclude map[string]struct{}, storageClass uint32) *DataPartition {
+	mediaType := proto.GetMediaTypeByStorageClass(storageClass)
+	candidates := make([]*DataPartition, 0)
+	w.Lock.RLock()
+	for _, dp := range w.pinnedDps[pin.Path] {
+		if dp.Status == proto.ReadWrite && (mediaType == proto.MediaType_Unspecified || dp.MediaType == mediaType) &&
+			!isExcluded(dp, exclude) {
+			candidates = append(candidates, dp)
+		}
+	}
+	w.Lock.RUnlock()
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// GetDataPartitionForWriteByPath prefers the data partitions pinned to the path of the file,
+// it falls back to the data partition selector if there is no pin or no available pinned partition.
+func (w *Wrapper) GetDataPartitionForWriteByPath(exclude map[string]struct{}, storageClass uint32, ehID uint64, fullPath string) (*DataPartition, error) {
+	if pin := w.getDpPin(fullPath); pin != nil {
+		if dp := w.selectPinnedDataPartition(pin, exclude, storageClass); dp != nil {
+			log.LogDebugf("GetDataPartitionForWriteByPath: ehID(%v) path(%v) select dp(%v) by pin(%v)",
+				ehID, fullPath, dp.PartitionID, pin.Path)
+			return dp, nil
+		}
+		log.LogWarnf("GetDataPartitionForWriteByPath: ehID(%v) path(%v) storageClass(%v) no writable dp of pin(%v) mediaType(%v) zone(%v), fallback",
+			ehID, fullPath, proto.StorageClassString(storageClass), pin.Path, proto.MediaTypeString(pin.MediaType), pin.ZoneName)
+	}
+	return w.GetDataPartitionForWrite(exclude, storageClass, ehID)
+}
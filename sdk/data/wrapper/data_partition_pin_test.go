@@ -0,0 +1,84 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package wrapper
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchDpPin(t *testing.T) {
+	pins := []*proto.DataPartitionPin{
+		{Path: "/", MediaType: proto.MediaType_HDD},
+		{Path: "/hot", MediaType: proto.MediaType_SSD},
+		{Path: "/hot/db", ZoneName: "z1"},
+	}
+	require.Nil(t, matchDpPin(pins, ""))
+	require.Equal(t, "/", matchDpPin(pins, "/cold/a").Path)
+	require.Equal(t, "/", matchDpPin(pins, "/hotter").Path)
+	require.Equal(t, "/hot", matchDpPin(pins, "/hot").Path)
+	require.Equal(t, "/hot", matchDpPin(pins, "/hot/a").Path)
+	require.Equal(t, "/hot/db", matchDpPin(pins, "/hot/db/wal").Path)
+	require.Nil(t, matchDpPin(pins[1:], "/cold/a"))
+}
+
+func TestGetDataPartitionForWriteByPath(t *testing.T) {
+	newDp := func(id uint64, mediaType uint32, zone string) *DataPartition {
+		return &DataPartition{DataPartitionResponse: proto.DataPartitionResponse{
+			PartitionID: id,
+			Status:      proto.ReadWrite,
+			Hosts:       []string{"host" + zone},
+			MediaType:   mediaType,
+			Zones:       []string{zone, zone},
+		}}
+	}
+	w := &Wrapper{partitions: map[uint64]*DataPartition{
+		1: newDp(1, proto.MediaType_HDD, "z1"),
+		2: newDp(2, proto.MediaType_SSD, "z1"),
+		3: newDp(3, proto.MediaType_SSD, "z2"),
+	}}
+	w.updateDpPins([]*proto.DataPartitionPin{
+		{Path: "/hot", MediaType: proto.MediaType_SSD},
+		{Path: "/hot/z2", ZoneName: "z2"},
+	})
+
+	for i := 0; i < 10; i++ {
+		dp, err := w.GetDataPartitionForWriteByPath(nil, proto.StorageClass_Replica_SSD, 0, "/hot/a")
+		require.NoError(t, err)
+		require.Equal(t, proto.MediaType_SSD, dp.MediaType)
+	}
+	dp, err := w.GetDataPartitionForWriteByPath(nil, proto.StorageClass_Replica_SSD, 0, "/hot/z2/a")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, dp.PartitionID)
+
+	// no pinned partition is available, fall back to the selector
+	pin := w.getDpPin("/hot/z2/a")
+	require.Nil(t, w.selectPinnedDataPartition(pin, map[string]struct{}{"hostz2": {}}, proto.StorageClass_Replica_SSD))
+	require.Nil(t, w.selectPinnedDataPartition(pin, nil, proto.StorageClass_Replica_HDD))
+	// the pin to ssd has no partition for a file of hdd
+	require.Nil(t, w.selectPinnedDataPartition(w.getDpPin("/hot/a"), nil, proto.StorageClass_Replica_HDD))
+
+	// the index follows the partitions inserted and their zones
+	w.replaceOrInsertPartition(newDp(4, proto.MediaType_SSD, "z2"))
+	require.Len(t, w.pinnedDps["/hot/z2"], 2)
+	w.replaceOrInsertPartition(newDp(3, proto.MediaType_SSD, "z1"))
+	require.Len(t, w.pinnedDps["/hot/z2"], 1)
+	require.Len(t, w.pinnedDps["/hot"], 3)
+	dp, err = w.GetDataPartitionForWriteByPath(nil, proto.StorageClass_Replica_SSD, 0, "/hot/z2/a")
+	require.NoError(t, err)
+	require.EqualValues(t, 4, dp.PartitionID)
+}
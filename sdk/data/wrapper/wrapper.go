@@ -19,6 +19,7 @@ import (
 	syslog "log"
 	"math"
 	"net"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +38,9 @@ var (
 	DefaultMinWritableDataPartitionCnt = 10
 )
 
+// as often as the meta wrapper updates the cluster info by the meta partitions
+const clusterInfoUpdateInterval = 5 * time.Minute
+
 type DataPartitionView struct {
 	DataPartitions []*DataPartition
 }
@@ -93,6 +97,11 @@ type Wrapper struct {
 	HostsDelay             sync.Map
 
 	readFailedHosts map[uint64]map[string]time.Time
+
+	dpPins    []*proto.DataPartitionPin
+	pinnedDps map[string][]*DataPartition // the partitions matching each pin by its path
+
+	mountTime int64
 }
 
 // NewDataPartitionWrapper returns a new data partition wrapper.
@@ -111,6 +120,7 @@ func NewDataPartitionWrapper(client SimpleClientInfo, volName string, masters []
 	w.preload = preload
 	w.volStorageClass = volStorageClass
 	w.volAllowedStorageClass = volAllowedStorageClass
+	w.mountTime = time.Now().Unix()
 
 	if w.LocalIp, err = ump.GetLocalIpAddr(); err != nil {
 		err = errors.Trace(err, "NewDataPartitionWrapper:")
@@ -246,6 +256,7 @@ func (w *Wrapper) GetSimpleVolView() (err error) {
 	w.EnablePosixAcl = view.EnablePosixAcl
 
 	w.UpdateUidsView(view)
+	w.updateDpPins(view.DpPins)
 
 	log.LogDebugf("GetSimpleVolView: get volume simple info: ID(%v) name(%v) owner(%v) status(%v) capacity(%v) "+
 		"metaReplicas(%v) dataReplicas(%v) mpCnt(%v) dpCnt(%v) followerRead(%v) createTime(%v) dpSelectorName(%v) "+
@@ -280,12 +291,19 @@ func (w *Wrapper) uploadFlowInfoByTick(clientInfo SimpleClientInfo) {
 
 func (w *Wrapper) update(clientInfo SimpleClientInfo) {
 	ticker := time.NewTicker(time.Minute)
+	clusterInfoUpdated := time.Now()
 	taskFunc := func() {
+		// the master peers are updated with the cluster info
+		if time.Since(clusterInfoUpdated) >= clusterInfoUpdateInterval {
+			w.updateClusterInfo()
+			clusterInfoUpdated = time.Now()
+		}
 		w.updateSimpleVolView(clientInfo)
 		w.updateDataPartition(false)
 		w.updateDataNodeStatus()
 		w.CheckPermission()
 		w.updateVerlist(clientInfo)
+		w.keepAliveVolClient()
 		if w.FollowerRead() && w.NearRead() && w.volStorageClass == proto.MediaType_HDD {
 			w.updateReadFailedHosts()
 		}
@@ -326,6 +344,20 @@ func (w *Wrapper) UploadFlowInfo(clientInfo SimpleClientInfo, init bool) (work b
 	return
 }
 
+// keepAliveVolClient reports the client to master so that it is listed as mounting the volume.
+func (w *Wrapper) keepAliveVolClient() {
+	client := &proto.VolClientInfo{
+		Host:      w.LocalIp,
+		Pid:       os.Getpid(),
+		Role:      proto.Role,
+		Version:   proto.Version,
+		MountTime: w.mountTime,
+	}
+	if err := w.mc.AdminAPI().VolumeClientKeepAlive(w.VolName, client); err != nil {
+		log.LogWarnf("keepAliveVolClient: volume(%v) err(%v)", w.VolName, err)
+	}
+}
+
 func (w *Wrapper) CheckPermission() {
 	if info, err := w.mc.UserAPI().AclOperation(w.VolName, w.LocalIp, util.AclCheckIP); err != nil {
 		syslog.Println(err)
@@ -374,6 +406,7 @@ func (w *Wrapper) updateSimpleVolView(clientInfo SimpleClientInfo) (err error) {
 	}
 
 	w.UpdateUidsView(view)
+	w.updateDpPins(view.DpPins)
 
 	if w.followerRead != view.FollowerRead && !w.followerReadClientCfg {
 		log.LogDebugf("UpdateSimpleVolView: update followerRead from old(%v) to new(%v)",
@@ -553,6 +586,7 @@ func (w *Wrapper) clearPartitions() {
 	w.Lock.Lock()
 	defer w.Lock.Unlock()
 	w.partitions = make(map[uint64]*DataPartition)
+	w.pinnedDps = make(map[string][]*DataPartition, len(w.dpPins))
 }
 
 func (w *Wrapper) replaceOrInsertPartition(dp *DataPartition) {
@@ -567,11 +601,17 @@ func (w *Wrapper) replaceOrInsertPartition(dp *DataPartition) {
 		old.Hosts = dp.Hosts
 		old.IsDiscard = dp.IsDiscard
 		old.NearHosts = dp.NearHosts
+		zonesChanged := !sameZones(old.Zones, dp.Zones)
+		old.Zones = dp.Zones
+		if zonesChanged {
+			w.reindexPinnedPartition(old)
+		}
 
 		dp.Metrics = old.Metrics
 	} else {
 		dp.Metrics = NewDataPartitionMetrics()
 		w.partitions[dp.PartitionID] = dp
+		w.indexPinnedPartition(dp)
 	}
 
 	w.Lock.Unlock()
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
@@ -169,6 +170,152 @@ func (api *AdminAPI) DiagnoseMetaPartition() (diagnosis *proto.MetaPartitionDiag
 	return
 }
 
+// GetDiagBundle returns the diagnostics of the cluster gathered by master, with the secrets redacted.
+func (api *AdminAPI) GetDiagBundle() (bundle *proto.DiagBundle, err error) {
+	bundle = &proto.DiagBundle{}
+	err = api.mc.requestWith(bundle, newRequest(get, proto.AdminDiagBundle).Header(api.h))
+	return
+}
+
+// ExportClusterConfig returns the config of the cluster to be imported by ImportClusterConfig.
+func (api *AdminAPI) ExportClusterConfig() (conf *proto.ClusterConfig, err error) {
+	conf = &proto.ClusterConfig{}
+	err = api.mc.requestWith(conf, newRequest(get, proto.AdminExportClusterConfig).Header(api.h))
+	return
+}
+
+// ImportClusterConfig imports the config exported, the actions are only planned by dryRun.
+func (api *AdminAPI) ImportClusterConfig(conf *proto.ClusterConfig, dryRun bool) (result *proto.ClusterConfigImportResult, err error) {
+	result = &proto.ClusterConfigImportResult{}
+	err = api.mc.requestWith(result, newRequest(post, proto.AdminImportClusterConfig).Header(api.h).
+		addParamAny("dryRun", dryRun).Body(conf))
+	return
+}
+
+// GetMetaPartitionLagInfo returns the apply index lag of the meta partition.
+func (api *AdminAPI) GetMetaPartitionLagInfo(partitionID uint64) (info *proto.MetaPartitionLagInfo, err error) {
+	info = &proto.MetaPartitionLagInfo{}
+	err = api.mc.requestWith(info, newRequest(get, proto.AdminMetaPartitionLagInfo).Header(api.h).Param(
+		anyParam{"id", partitionID},
+	))
+	return
+}
+
+// DiffMetaPartitionTree returns the ranges of the trees that diverge between the replicas of the
+// meta partition, rangeSize is the number of inode IDs of a range and the default is used if zero.
+func (api *AdminAPI) DiffMetaPartitionTree(partitionID, rangeSize uint64) (diff *proto.MetaPartitionTreeDiff, err error) {
+	request := newRequest(get, proto.AdminDiffMetaPartitionTree).Header(api.h)
+	request.addParamAny("id", partitionID)
+	if rangeSize > 0 {
+		request.addParamAny("rangeSize", rangeSize)
+	}
+	diff = &proto.MetaPartitionTreeDiff{}
+	err = api.mc.requestWith(diff, request)
+	return
+}
+
+// RepairInodeNLink recounts the link count of the inode from the dentries of the volume, and
+// repairs it if it differs unless dryRun.
+func (api *AdminAPI) RepairInodeNLink(volName string, ino uint64, dryRun bool) (report *proto.MetaNLinkRepairReport, err error) {
+	request := newRequest(post, proto.AdminRepairMetaInodeNLink).Header(api.h)
+	request.addParam("name", volName)
+	request.addParamAny("ino", ino)
+	request.addParamAny("dryRun", dryRun)
+	report = &proto.MetaNLinkRepairReport{}
+	err = api.mc.requestWith(report, request)
+	return
+}
+
+// TruncateMetaRaftLog truncates the raft log of the leader of the meta partition up to index,
+// which all the replicas must have applied.
+func (api *AdminAPI) TruncateMetaRaftLog(partitionID, index uint64) (resp *proto.MetaRaftLogTruncateResponse, err error) {
+	request := newRequest(post, proto.AdminTruncateMetaRaftLog).Header(api.h)
+	request.addParamAny("id", partitionID)
+	request.addParamAny("index", index)
+	resp = &proto.MetaRaftLogTruncateResponse{}
+	err = api.mc.requestWith(resp, request)
+	return
+}
+
+// GetMetaPartitionDistribution returns the replicas of the meta partitions of the volume, or of
+// all the volumes if volName is empty, in each zone, nodeset and meta node, with the partitions
+// breaking the zone policy. The decommissions restoring the policy are returned if fixPlan.
+func (api *AdminAPI) GetMetaPartitionDistribution(volName string, fixPlan bool) (dist *proto.MetaPartitionDistribution, err error) {
+	request := newRequest(get, proto.AdminMetaPartitionDistribution).Header(api.h)
+	if volName != "" {
+		request.addParam("name", volName)
+	}
+	request.addParamAny("fixPlan", fixPlan)
+	dist = &proto.MetaPartitionDistribution{}
+	err = api.mc.requestWith(dist, request)
+	return
+}
+
+// SetVolumePurge pauses, resumes or forces the purge of the inodes deleted of the volume by op,
+// one of proto.PurgeOpPause and the others, the backlog of the purge is returned. Only the
+// backlog is returned if op is empty.
+func (api *AdminAPI) SetVolumePurge(volName, op string) (status *proto.VolPurgeStatus, err error) {
+	request := newRequest(post, proto.AdminVolPurge).Header(api.h)
+	request.addParam("name", volName)
+	if op != "" {
+		request.addParam("op", op)
+	}
+	status = &proto.VolPurgeStatus{}
+	err = api.mc.requestWith(status, request)
+	return
+}
+
+// ListLaggingMetaPartitions returns the meta partitions of the volume, or of the cluster if
+// volName is empty, that have replicas lagging behind the leader.
+func (api *AdminAPI) ListLaggingMetaPartitions(volName string) (infos []*proto.MetaPartitionLagInfo, err error) {
+	request := newRequest(get, proto.AdminMetaPartitionLagInfo).Header(api.h)
+	if volName != "" {
+		request.addParam("name", volName)
+	}
+	infos = make([]*proto.MetaPartitionLagInfo, 0)
+	err = api.mc.requestWith(&infos, request)
+	return
+}
+
+// CreateClusterSnapshot takes a snapshot of the cluster state on the master for ClusterDiff.
+func (api *AdminAPI) CreateClusterSnapshot() (info *proto.ClusterSnapshotInfo, err error) {
+	info = &proto.ClusterSnapshotInfo{}
+	err = api.mc.requestWith(info, newRequest(post, proto.AdminCreateClusterSnapshot).Header(api.h))
+	return
+}
+
+func (api *AdminAPI) ListClusterSnapshots() (infos []*proto.ClusterSnapshotInfo, err error) {
+	infos = make([]*proto.ClusterSnapshotInfo, 0)
+	err = api.mc.requestWith(&infos, newRequest(get, proto.AdminListClusterSnapshots).Header(api.h))
+	return
+}
+
+// ClusterDiff returns what changed from the cluster snapshot from to the snapshot to, or to
+// the current state of the cluster if to is zero.
+func (api *AdminAPI) ClusterDiff(from, to uint64) (diff *proto.ClusterDiff, err error) {
+	request := newRequest(get, proto.AdminClusterDiff).Header(api.h)
+	request.addParamAny("from", from)
+	if to > 0 {
+		request.addParamAny("to", to)
+	}
+	diff = &proto.ClusterDiff{}
+	err = api.mc.requestWith(diff, request)
+	return
+}
+
+// GetNodeBlastRadius returns the volumes and partitions affected by the loss of the node on addr,
+// or of the disk of the data node if diskPath is not empty.
+func (api *AdminAPI) GetNodeBlastRadius(addr, diskPath string) (radius *proto.BlastRadius, err error) {
+	request := newRequest(get, proto.AdminNodeBlastRadius).Header(api.h)
+	request.addParam("addr", addr)
+	if diskPath != "" {
+		request.addParam("disk", diskPath)
+	}
+	radius = &proto.BlastRadius{}
+	err = api.mc.requestWith(radius, request)
+	return
+}
+
 func (api *AdminAPI) LoadDataPartition(volName string, partitionID uint64, clientIDKey string) (err error) {
 	return api.mc.request(newRequest(get, proto.AdminLoadDataPartition).Header(api.h).Param(
 		anyParam{"id", partitionID},
@@ -210,6 +357,15 @@ func (api *AdminAPI) DecommissionMetaPartition(metaPartitionID uint64, nodeAddr,
 	return
 }
 
+// MigrateMetaPartitionToNodeSet starts moving all the replicas of the meta partition into the nodeset.
+func (api *AdminAPI) MigrateMetaPartitionToNodeSet(metaPartitionID, nodeSetID uint64) (err error) {
+	request := newRequest(post, proto.AdminMigrateMetaPartitionToNodeSet).Header(api.h)
+	request.addParam("id", strconv.FormatUint(metaPartitionID, 10))
+	request.addParam("nodesetId", strconv.FormatUint(nodeSetID, 10))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
 func (api *AdminAPI) DeleteDataReplica(dataPartitionID uint64, nodeAddr, clientIDKey string, raftForce bool) (err error) {
 	request := newRequest(get, proto.AdminDeleteDataReplica).Header(api.h)
 	request.addParam("id", strconv.FormatUint(dataPartitionID, 10))
@@ -295,6 +451,45 @@ func (api *AdminAPI) UnDeleteVolume(volName, authKey string, status bool) (err e
 	return
 }
 
+// ListVolRecycleBin returns the volumes deleted with delay that can still be undeleted.
+func (api *AdminAPI) ListVolRecycleBin() (bin *proto.VolRecycleBin, err error) {
+	bin = &proto.VolRecycleBin{}
+	err = api.mc.requestWith(bin, newRequest(get, proto.AdminListVolRecycleBin).Header(api.h))
+	return
+}
+
+// PurgeVolRecycleBin deletes the volume in the recycle bin without waiting for its retention to end.
+func (api *AdminAPI) PurgeVolRecycleBin(volName, authKey string) (err error) {
+	request := newRequest(post, proto.AdminPurgeVolRecycleBin).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// EvacuateZone starts moving all the data and meta partitions out of the zone.
+func (api *AdminAPI) EvacuateZone(zoneName string, mpConcurrency int) (view *proto.ZoneEvacuationView, err error) {
+	view = &proto.ZoneEvacuationView{}
+	request := newRequest(post, proto.EvacuateZone).Header(api.h)
+	request.addParam("name", zoneName)
+	request.addParamAny("mpConcurrency", mpConcurrency)
+	err = api.mc.requestWith(view, request)
+	return
+}
+
+func (api *AdminAPI) GetZoneEvacuation(zoneName string) (view *proto.ZoneEvacuationView, err error) {
+	view = &proto.ZoneEvacuationView{}
+	err = api.mc.requestWith(view, newRequest(get, proto.EvacuateZoneStatus).Header(api.h).addParam("name", zoneName))
+	return
+}
+
+func (api *AdminAPI) AbortZoneEvacuation(zoneName string) (err error) {
+	request := newRequest(post, proto.EvacuateZoneAbort).Header(api.h)
+	request.addParam("name", zoneName)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
 func (api *AdminAPI) UpdateVolume(
 	vv *proto.SimpleVolView,
 	txTimeout int64,
@@ -329,6 +524,15 @@ func (api *AdminAPI) UpdateVolume(
 	request.addParam("trashInterval", strconv.FormatInt(vv.TrashInterval, 10))
 	request.addParam("accessTimeValidInterval", strconv.FormatInt(vv.AccessTimeInterval, 10))
 	request.addParam("enablePersistAccessTime", strconv.FormatBool(vv.EnablePersistAccessTime))
+	request.addParam("atimeMode", vv.AtimeMode)
+	request.addParamAny("xattrMaxCount", vv.XAttrLimit.MaxCount)
+	request.addParamAny("xattrMaxKeySize", vv.XAttrLimit.MaxKeySize)
+	request.addParamAny("xattrMaxValueSize", vv.XAttrLimit.MaxValueSize)
+	request.addParamAny("xattrMaxTotalSize", vv.XAttrLimit.MaxTotalSize)
+	request.addParam("extentConflictPolicy", vv.ExtentConflictPolicy)
+	request.addParam("enableOpAudit", strconv.FormatBool(vv.EnableOpAudit))
+	request.addParam("enableCheckExtentKey", strconv.FormatBool(vv.EnableCheckExtentKey))
+	request.addParam("enableDirStat", strconv.FormatBool(vv.EnableDirStat))
 	request.addParam("volStorageClass", strconv.FormatUint(uint64(vv.VolStorageClass), 10))
 	request.addParam("forbidWriteOpOfProtoVersion0", strconv.FormatBool(vv.ForbidWriteOpOfProtoVer0))
 	request.addParam(proto.LeaderRetryTimeoutKey, strconv.FormatUint(uint64(vv.LeaderRetryTimeOut), 10))
@@ -480,6 +684,12 @@ func (api *AdminAPI) GetVolumeSimpleInfo(volName string) (vv *proto.SimpleVolVie
 	return
 }
 
+func (api *AdminAPI) GetVolumePreWarmStatus(volName string) (status *proto.VolPreWarmStatus, err error) {
+	status = &proto.VolPreWarmStatus{}
+	err = api.mc.requestWith(status, newRequest(get, proto.AdminVolPreWarmStatus).Header(api.h).addParam("name", volName))
+	return
+}
+
 func (api *AdminAPI) SetVolumeForbidden(volName string, forbidden bool) (err error) {
 	request := newRequest(post, proto.AdminVolForbidden).Header(api.h)
 	request.addParam("name", volName)
@@ -504,6 +714,189 @@ func (api *AdminAPI) SetVolumeDpRepairBlockSize(volName string, repairSize uint6
 	return
 }
 
+// SetVolumeDefaultXAttrs sets the xattrs of every new inode of the volume, an empty xattrs clears them.
+func (api *AdminAPI) SetVolumeDefaultXAttrs(volName string, xattrs map[string]string) (err error) {
+	pairs := make([]string, 0, len(xattrs))
+	for k, v := range xattrs {
+		pairs = append(pairs, proto.EscapeXAttrPair(k)+":"+proto.EscapeXAttrPair(v))
+	}
+	request := newRequest(post, proto.AdminVolSetDefaultXAttrs).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("xattrs", strings.Join(pairs, ","))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// SetVolumeMetaWorkerWeight sets the share of the request workers of metanode the volume has,
+// 0 is the default one.
+func (api *AdminAPI) SetVolumeMetaWorkerWeight(volName string, weight int) (err error) {
+	request := newRequest(post, proto.AdminVolSetMetaWorkerWeight).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("weight", strconv.Itoa(weight))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// SetVolumeMetaMediaType places the meta partitions of the volume on the meta nodes of the media
+// type, it returns the partitions with the replicas on the other meta nodes. 0 of the media type
+// places them on any meta node.
+func (api *AdminAPI) SetVolumeMetaMediaType(volName string, mediaType uint32) (report *proto.MetaMediaTypeReport, err error) {
+	request := newRequest(post, proto.AdminVolSetMetaMediaType).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("mediaType", strconv.FormatUint(uint64(mediaType), 10))
+	report = &proto.MetaMediaTypeReport{}
+	err = api.mc.requestWith(report, request)
+	return
+}
+
+// CheckVolumeMetaMediaType returns the meta partitions of the volume with the replicas on the
+// meta nodes not of the meta media type of the volume.
+func (api *AdminAPI) CheckVolumeMetaMediaType(volName string) (report *proto.MetaMediaTypeReport, err error) {
+	request := newRequest(get, proto.AdminVolCheckMetaMediaType).Header(api.h)
+	request.addParam("name", volName)
+	report = &proto.MetaMediaTypeReport{}
+	err = api.mc.requestWith(report, request)
+	return
+}
+
+// SetVolumeDpPin makes the client prefer the data partitions of the media type and zone when
+// writing files under the path, either mediaType or zoneName should be specified. The mediaType
+// should be the one of the storage class of the vol.
+func (api *AdminAPI) SetVolumeDpPin(volName, path string, mediaType uint32, zoneName string) (err error) {
+	request := newRequest(post, proto.AdminVolSetDpPin).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("path", path)
+	request.addParam("mediaType", strconv.FormatUint(uint64(mediaType), 10))
+	request.addParam("zoneName", zoneName)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+func (api *AdminAPI) RemoveVolumeDpPin(volName, path string) (err error) {
+	request := newRequest(post, proto.AdminVolRemoveDpPin).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("path", path)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// AddVolumeReadOnlyWindow adds a window of unix seconds during which the partitions of the
+// volume refuse modifications, it starts at once if start is 0.
+func (api *AdminAPI) AddVolumeReadOnlyWindow(volName string, start, end int64, reason string) (window *proto.VolReadOnlyWindow, err error) {
+	request := newRequest(post, proto.AdminVolAddReadOnlyWindow).Header(api.h)
+	request.addParam("name", volName)
+	if start > 0 {
+		request.addParam("start", strconv.FormatInt(start, 10))
+	}
+	request.addParam("end", strconv.FormatInt(end, 10))
+	request.addParam("reason", reason)
+	window = &proto.VolReadOnlyWindow{}
+	err = api.mc.requestWith(window, request)
+	return
+}
+
+func (api *AdminAPI) ListVolumeReadOnlyWindows(volName string) (windows []*proto.VolReadOnlyWindow, err error) {
+	request := newRequest(get, proto.AdminVolListReadOnlyWindows).Header(api.h)
+	request.addParam("name", volName)
+	windows = make([]*proto.VolReadOnlyWindow, 0)
+	err = api.mc.requestWith(&windows, request)
+	return
+}
+
+func (api *AdminAPI) CancelVolumeReadOnlyWindow(volName string, id uint64) (err error) {
+	request := newRequest(post, proto.AdminVolCancelReadOnlyWindow).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("id", strconv.FormatUint(id, 10))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// SetVolumeMetaFreeze freezes the meta partitions of the volume to refuse modifications, or
+// unfreezes them.
+func (api *AdminAPI) SetVolumeMetaFreeze(volName string, freeze bool) (err error) {
+	request := newRequest(post, proto.AdminVolMetaFreeze).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("freeze", strconv.FormatBool(freeze))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// SetVolumeMetaAuth has the meta partitions of the volume refuse modifications without a meta
+// access token, or stop refusing them. rotate signs the tokens to come by a new key.
+func (api *AdminAPI) SetVolumeMetaAuth(volName string, enable, rotate bool) (err error) {
+	request := newRequest(post, proto.AdminVolMetaAuth).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("enable", strconv.FormatBool(enable))
+	request.addParam("rotate", strconv.FormatBool(rotate))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// VolumeClientKeepAlive tells master the client is still mounting the volume.
+func (api *AdminAPI) VolumeClientKeepAlive(volName string, client *proto.VolClientInfo) (err error) {
+	request := newRequest(post, proto.AdminVolClientKeepAlive).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("host", client.Host)
+	request.addParam("pid", strconv.Itoa(client.Pid))
+	request.addParam("role", client.Role)
+	request.addParam("version", client.Version)
+	request.addParam("mountTime", strconv.FormatInt(client.MountTime, 10))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// GetVolumeClients returns the clients mounting the volume.
+func (api *AdminAPI) GetVolumeClients(volName string) (clients []*proto.VolClientInfo, err error) {
+	clients = make([]*proto.VolClientInfo, 0)
+	err = api.mc.requestWith(&clients, newRequest(get, proto.AdminVolClients).Header(api.h).addParam("name", volName))
+	return
+}
+
+// CreateVolReplica creates a read-only replica of the source volume, authKey is the one of the source.
+func (api *AdminAPI) CreateVolReplica(volName, owner, sourceVol, authKey string, syncInterval int64) (err error) {
+	request := newRequest(post, proto.AdminCreateVolReplica).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("owner", owner)
+	request.addParam("sourceVol", sourceVol)
+	request.addParam("authKey", authKey)
+	request.addParam("syncInterval", strconv.FormatInt(syncInterval, 10))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// GetVolReplicaStatus returns the sync status of the meta partitions of the replica volume.
+func (api *AdminAPI) GetVolReplicaStatus(volName string) (status *proto.VolReplicaStatus, err error) {
+	status = &proto.VolReplicaStatus{}
+	err = api.mc.requestWith(status, newRequest(get, proto.AdminGetVolReplicaStatus).Header(api.h).addParam("name", volName))
+	return
+}
+
+// SyncVolReplica starts syncing the replica volume from its source now.
+func (api *AdminAPI) SyncVolReplica(volName string) (status *proto.VolReplicaStatus, err error) {
+	status = &proto.VolReplicaStatus{}
+	err = api.mc.requestWith(status, newRequest(post, proto.AdminSyncVolReplica).Header(api.h).addParam("name", volName))
+	return
+}
+
+// ListVolReplicas returns the names of the replicas of the source volume.
+func (api *AdminAPI) ListVolReplicas(sourceVol string) (replicas []string, err error) {
+	replicas = make([]string, 0)
+	err = api.mc.requestWith(&replicas, newRequest(get, proto.AdminListVolReplicas).Header(api.h).addParam("sourceVol", sourceVol))
+	return
+}
+
+// GetNodeTasks gets the admin tasks master queued for the node of addr, or for all the nodes
+// with tasks queued if addr is empty.
+func (api *AdminAPI) GetNodeTasks(addr string) (stats []*proto.NodeTaskStat, err error) {
+	stats = make([]*proto.NodeTaskStat, 0)
+	request := newRequest(get, proto.AdminNodeTasks).Header(api.h)
+	if addr != "" {
+		request.addParam("addr", addr)
+	}
+	err = api.mc.requestWith(&stats, request)
+	return
+}
+
 func (api *AdminAPI) GetMonitorPushAddr() (addr string, err error) {
 	err = api.mc.requestWith(&addr, newRequest(get, proto.AdminGetMonitorPushAddr).Header(api.h))
 	return
@@ -534,9 +927,14 @@ func (api *AdminAPI) CheckACL() (ci *proto.ClusterInfo, err error) {
 	return
 }
 
+// GetClusterInfo returns the cluster info, the masters of the client are updated with the
+// master peers of it.
 func (api *AdminAPI) GetClusterInfo() (ci *proto.ClusterInfo, err error) {
 	ci = &proto.ClusterInfo{}
-	err = api.mc.requestWith(ci, newRequest(get, proto.AdminGetIP).Header(api.h))
+	if err = api.mc.requestWith(ci, newRequest(get, proto.AdminGetIP).Header(api.h)); err != nil {
+		return
+	}
+	api.mc.UpdateMasterPeers(ci.MasterAddrs)
 	return
 }
 
@@ -598,7 +996,9 @@ func (api *AdminAPI) SetClusterParas(batchCount, markDeleteRate, deleteWorkerSle
 	enableAutoDpMetaRepair string, autoDpMetaRepairParallelCnt string,
 	dpRepairTimeout string, dpTimeout string, mpTimeout string, dpBackupTimeout string,
 	decommissionDpLimit, decommissionDiskLimit, forbidWriteOpOfProtoVersion0 string, mediaType string,
-	handleTimeout string, readDataNodeTimeout string,
+	handleTimeout string, readDataNodeTimeout string, metaSnapshotPersistenceMode string,
+	metaSnapshotSendRate, metaSnapshotRecvRate, metaSnapshotPartitionRate string,
+	mpHeatSplitOpRate, mpHeatSplitInterval string,
 ) (err error) {
 	request := newRequest(get, proto.AdminSetNodeInfo).Header(api.h)
 	request.addParam("batchCount", batchCount)
@@ -659,11 +1059,41 @@ func (api *AdminAPI) SetClusterParas(batchCount, markDeleteRate, deleteWorkerSle
 	if readDataNodeTimeout != "" {
 		request.addParam("flashNodeReadDataNodeTimeout", readDataNodeTimeout)
 	}
+	if metaSnapshotPersistenceMode != "" {
+		request.addParam("metaSnapshotPersistenceMode", metaSnapshotPersistenceMode)
+	}
+	if metaSnapshotSendRate != "" {
+		request.addParam("metaSnapshotSendRateMB", metaSnapshotSendRate)
+	}
+	if metaSnapshotRecvRate != "" {
+		request.addParam("metaSnapshotRecvRateMB", metaSnapshotRecvRate)
+	}
+	if metaSnapshotPartitionRate != "" {
+		request.addParam("metaSnapshotPartitionRateMB", metaSnapshotPartitionRate)
+	}
+	if mpHeatSplitOpRate != "" {
+		request.addParam("mpHeatSplitOpRate", mpHeatSplitOpRate)
+	}
+	if mpHeatSplitInterval != "" {
+		request.addParam("mpHeatSplitInterval", mpHeatSplitInterval)
+	}
 
 	_, err = api.mc.serveRequest(request)
 	return
 }
 
+// SetVolMetaOpQuota sets the rates of the meta ops of the vol taken by each metanode, 0 is
+// unlimited.
+func (api *AdminAPI) SetVolMetaOpQuota(volName string, quota proto.MetaOpQuota) (err error) {
+	request := newRequest(get, proto.AdminSetNodeInfo).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("metaCreateOpRate", strconv.FormatUint(quota.CreateOps, 10))
+	request.addParam("metaDeleteOpRate", strconv.FormatUint(quota.DeleteOps, 10))
+	request.addParam("metaLookupOpRate", strconv.FormatUint(quota.LookupOps, 10))
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
 func (api *AdminAPI) GetClusterParas() (delParas map[string]string, err error) {
 	request := newRequest(get, proto.AdminGetNodeInfo).Header(api.h)
 	if _, err = api.mc.serveRequest(request); err != nil {
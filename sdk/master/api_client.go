@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/iputil"
@@ -112,6 +113,30 @@ func (api *ClientAPI) GetMetaPartitions(volName string) (views []*proto.MetaPart
 	return
 }
 
+// GetNsEventEndpoints returns the meta partitions to watch the namespace events of the vol
+// from, only the one keeping the dentries of the dir ino if it is not zero.
+func (api *ClientAPI) GetNsEventEndpoints(volName string, ino uint64) (views []*proto.MetaPartitionView, err error) {
+	views = make([]*proto.MetaPartitionView, 0)
+	request := newRequest(get, proto.ClientNsEventEndpoints).Header(api.h).addParam("name", volName)
+	if ino > 0 {
+		request.addParamAny("ino", ino)
+	}
+	err = api.mc.requestWith(&views, request)
+	return
+}
+
+// GetMetaAccessToken asks for the meta access token of the vol by the keys of the user, the
+// token is empty if the vol does not enforce the meta auth.
+func (api *ClientAPI) GetMetaAccessToken(volName, accessKey, secretKey string) (reply *proto.MetaAccessTokenReply, err error) {
+	ts := time.Now().Unix()
+	reply = &proto.MetaAccessTokenReply{}
+	request := newRequest(get, proto.ClientMetaToken).Header(api.h).addParam("name", volName).
+		addParam("ak", accessKey).addParamAny("ts", ts).
+		addParam("sign", proto.MetaTokenRequestSign(secretKey, volName, accessKey, ts))
+	err = api.mc.requestWith(reply, request)
+	return
+}
+
 func (api *ClientAPI) GetDataPartitionsFromLeader(volName string) (view *proto.DataPartitionsView, err error) {
 	request := newRequest(get, proto.ClientDataPartitions).Header(api.h).addParam("name", volName)
 	var data []byte
@@ -249,3 +249,11 @@ func (api *NodeAPI) OfflineMetaNode(nodeAddr string) (err error) {
 	}
 	return
 }
+
+func (api *NodeAPI) SetMetaNodeState(nodeAddr, state string) (err error) {
+	request := newRequest(post, proto.AdminSetNodeStateByAddr).Header(api.h)
+	request.addParam("addr", nodeAddr)
+	request.addParam("state", state)
+	_, err = api.mc.serveRequest(request)
+	return
+}
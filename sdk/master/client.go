@@ -58,6 +58,8 @@ type MasterCLientWithResolver struct {
 type MasterClient struct {
 	sync.RWMutex
 	masters     []string
+	seeds       []string // the addresses given to the client
+	health      map[string]*masterHealth
 	useSSL      bool
 	leaderAddr  string
 	timeout     time.Duration
@@ -80,6 +82,7 @@ func (c *MasterClient) ReplaceMasterAddresses(addrs []string) {
 	c.Lock()
 	defer c.Unlock()
 	c.masters = addrs
+	c.seeds = addrs
 	c.leaderAddr = ""
 }
 
@@ -147,30 +150,25 @@ func (c *MasterClient) SetClientIDKey(clientIDKey string) {
 }
 
 func (c *MasterClient) serveRequest(r *request) (repsData []byte, err error) {
-	leaderAddr, nodes := c.prepareRequest()
-	host := leaderAddr
-	for i := -1; i < len(nodes); i++ {
-		if i == -1 {
-			if host == "" {
-				continue
-			}
-		} else {
-			host = nodes[i]
-		}
+	leaderAddr, hosts := c.orderMasters()
+	for _, host := range hosts {
 		var resp *http.Response
 		schema := "http"
 		if c.useSSL {
 			schema = "https"
 		}
 		url := fmt.Sprintf("%s://%s%s", schema, host, r.path)
+		start := time.Now()
 		resp, err = c.httpRequest(r.method, url, r)
 		if err != nil {
+			c.recordMaster(host, time.Since(start), true)
 			log.LogErrorf("serveRequest: send http request fail: method(%v) url(%v) err(%v)", r.method, url, err)
 			continue
 		}
 		stateCode := resp.StatusCode
 		repsData, err = io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
+		c.recordMaster(host, time.Since(start), err != nil || stateCode >= http.StatusInternalServerError)
 		if err != nil {
 			log.LogErrorf("serveRequest: read http response body fail: err(%v)", err)
 			continue
@@ -301,15 +299,6 @@ func (c *MasterClient) Nodes() (nodes []string) {
 	return
 }
 
-// prepareRequest returns the leader address and all master addresses.
-func (c *MasterClient) prepareRequest() (addr string, nodes []string) {
-	c.RLock()
-	addr = c.leaderAddr
-	nodes = c.masters
-	c.RUnlock()
-	return
-}
-
 func (c *MasterClient) httpRequest(method, url string, r *request) (resp *http.Response, err error) {
 	client := http.DefaultClient
 	if c.client != nil {
@@ -374,7 +363,7 @@ func (c *MasterClient) mergeRequestUrl(url string, params map[string]string) str
 
 func NewMasterCLientWithResolver(masters []string, useSSL bool, updateInverval int) *MasterCLientWithResolver {
 	mc := &MasterCLientWithResolver{
-		MasterClient:   MasterClient{masters: masters, useSSL: useSSL, timeout: requestTimeout},
+		MasterClient:   MasterClient{masters: masters, seeds: masters, useSSL: useSSL, timeout: requestTimeout},
 		updateInverval: updateInverval,
 		stopC:          make(chan struct{}),
 	}
@@ -456,7 +445,7 @@ func (mc *MasterCLientWithResolver) Stop() {
 
 // NewMasterHelper returns a new MasterClient instance.
 func NewMasterClient(masters []string, useSSL bool) *MasterClient {
-	mc := &MasterClient{masters: masters, useSSL: useSSL, timeout: requestTimeout}
+	mc := &MasterClient{masters: masters, seeds: masters, useSSL: useSSL, timeout: requestTimeout}
 	mc.adminAPI = &AdminAPI{mc: mc}
 	mc.clientAPI = &ClientAPI{mc: mc}
 	mc.nodeAPI = &NodeAPI{mc: mc}
@@ -0,0 +1,143 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// the weight of the last request in the latency and error rate of a master
+	masterHealthDecay = 0.2
+	// the latency assumed of a master not requested yet
+	defaultMasterLatency = 50 * time.Millisecond
+	// a master failed within it is tried after all the others
+	masterFailurePenalty = 30 * time.Second
+)
+
+// masterHealth scores a master address by the latency and error rate of the requests sent to
+// it, a lower score is better.
+type masterHealth struct {
+	latency  float64 // moving average in seconds
+	errRate  float64 // moving average of the failures
+	failedAt time.Time
+}
+
+func newMasterHealth() *masterHealth {
+	return &masterHealth{latency: defaultMasterLatency.Seconds()}
+}
+
+func (h *masterHealth) record(latency time.Duration, failed bool, now time.Time) {
+	if failed {
+		h.errRate += masterHealthDecay * (1 - h.errRate)
+		h.failedAt = now
+		return
+	}
+	h.errRate -= masterHealthDecay * h.errRate
+	h.latency += masterHealthDecay * (latency.Seconds() - h.latency)
+}
+
+func (h *masterHealth) penalized(now time.Time) bool {
+	return now.Sub(h.failedAt) < masterFailurePenalty
+}
+
+func (h *masterHealth) score() float64 {
+	// a master failing half of the requests scores as one ten times slower
+	return h.latency * (1 + 18*h.errRate)
+}
+
+// recordMaster records the result of a request sent to the master.
+func (c *MasterClient) recordMaster(addr string, latency time.Duration, failed bool) {
+	c.Lock()
+	defer c.Unlock()
+	if c.health == nil {
+		c.health = make(map[string]*masterHealth)
+	}
+	h, ok := c.health[addr]
+	if !ok {
+		h = newMasterHealth()
+		c.health[addr] = h
+	}
+	h.record(latency, failed, time.Now())
+}
+
+// orderMasters returns the masters in the order to request. The leader, or the last master
+// answering, is kept first unless it has failed lately, the others are ordered by their
+// health, the penalized ones last.
+func (c *MasterClient) orderMasters() (leader string, hosts []string) {
+	c.RLock()
+	defer c.RUnlock()
+	now := time.Now()
+	leader = c.leaderAddr
+	hosts = make([]string, 0, len(c.masters)+1)
+	for _, addr := range c.masters {
+		if addr != leader {
+			hosts = append(hosts, addr)
+		}
+	}
+	health := func(addr string) *masterHealth {
+		if h, ok := c.health[addr]; ok {
+			return h
+		}
+		return newMasterHealth()
+	}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		hi, hj := health(hosts[i]), health(hosts[j])
+		if pi, pj := hi.penalized(now), hj.penalized(now); pi != pj {
+			return pj
+		}
+		return hi.score() < hj.score()
+	})
+	if leader == "" {
+		return
+	}
+	if health(leader).penalized(now) {
+		i := sort.Search(len(hosts), func(i int) bool { return health(hosts[i]).penalized(now) })
+		hosts = append(hosts[:i], append([]string{leader}, hosts[i:]...)...)
+		return
+	}
+	hosts = append([]string{leader}, hosts...)
+	return
+}
+
+// UpdateMasterPeers updates the masters to request with the peers told by the cluster, the
+// addresses given to the client are kept, as they may be the VIPs of the peers.
+func (c *MasterClient) UpdateMasterPeers(peers []string) {
+	if len(peers) == 0 {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	masters := make([]string, 0, len(c.seeds)+len(peers))
+	seen := make(map[string]bool)
+	for _, addrs := range [][]string{c.seeds, peers} {
+		for _, addr := range addrs {
+			if !seen[addr] {
+				seen[addr] = true
+				masters = append(masters, addr)
+			}
+		}
+	}
+	for addr := range c.health {
+		if !seen[addr] {
+			delete(c.health, addr)
+		}
+	}
+	if c.leaderAddr != "" && !seen[c.leaderAddr] {
+		c.leaderAddr = ""
+	}
+	c.masters = masters
+}
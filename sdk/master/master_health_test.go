@@ -0,0 +1,84 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterHealthOrder(t *testing.T) {
+	mc := NewMasterClient([]string{"a", "b", "c"}, false)
+	_, hosts := mc.orderMasters()
+	require.Equal(t, []string{"a", "b", "c"}, hosts)
+
+	mc.recordMaster("a", time.Second, false)
+	mc.recordMaster("c", time.Millisecond, false)
+	_, hosts = mc.orderMasters()
+	require.Equal(t, []string{"c", "b", "a"}, hosts)
+
+	// the leader is sticky unless it fails
+	mc.SetLeader("a")
+	_, hosts = mc.orderMasters()
+	require.Equal(t, []string{"a", "c", "b"}, hosts)
+	mc.recordMaster("a", 0, true)
+	mc.recordMaster("b", 0, true)
+	_, hosts = mc.orderMasters()
+	require.Equal(t, []string{"c", "a", "b"}, hosts)
+
+	// the masters given are kept besides the peers
+	mc.UpdateMasterPeers([]string{"d", "b"})
+	require.Equal(t, []string{"a", "b", "c", "d"}, mc.Nodes())
+	mc.ReplaceMasterAddresses([]string{"vip"})
+	mc.UpdateMasterPeers([]string{"d", "e"})
+	require.Equal(t, []string{"vip", "d", "e"}, mc.Nodes())
+	require.NotContains(t, mc.health, "a")
+}
+
+func TestMasterFailover(t *testing.T) {
+	var peer string
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := json.Marshal(&proto.ClusterInfo{Cluster: "test", MasterAddrs: []string{peer}})
+		reply, _ := json.Marshal(&proto.HTTPReplyRaw{Code: proto.ErrCodeSuccess, Data: data})
+		w.Write(reply)
+	}))
+	defer master.Close()
+	peer = strings.TrimPrefix(master.URL, "http://")
+	down := httptest.NewServer(http.NotFoundHandler())
+	downAddr := strings.TrimPrefix(down.URL, "http://")
+	down.Close()
+
+	mc := NewMasterClient([]string{downAddr}, false)
+	mc.SetTimeout(1)
+	_, err := mc.AdminAPI().GetClusterInfo()
+	require.Error(t, err)
+
+	mc.UpdateMasterPeers([]string{peer})
+	ci, err := mc.AdminAPI().GetClusterInfo()
+	require.NoError(t, err)
+	require.Equal(t, "test", ci.Cluster)
+	require.Equal(t, peer, mc.Leader())
+
+	// the failed master is tried after the one answering
+	_, hosts := mc.orderMasters()
+	require.Equal(t, []string{peer, downAddr}, hosts)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
 )
 
@@ -39,6 +40,8 @@ const (
 	OpenRetryInterval = 5 * time.Millisecond
 	OpenRetryLimit    = 1000
 	maxUniqID         = 5000
+
+	maxBatchCreateDentryInodeCount = 4096
 )
 
 const (
@@ -179,6 +182,10 @@ func (mw *MetaWrapper) txCreate_ll(parentID uint64, name string, mode, uid, gid
 		status, info, err = mw.txIcreate(tx, mp, mode, uid, gid, target, quotaIds, fullPath)
 		if err == nil && status == statusOK {
 			goto create_dentry
+		} else if isCreateOnOtherStatus(status) {
+			log.LogWarnf("Mp(%v) takes no new inode, status(%v), create on the next one", mp, status)
+			mw.dropRWPartition(mp)
+			tx.Rollback(mw)
 		} else if status == statusNoSpace || status == statusForbid {
 			log.LogErrorf("Create_ll status %v", status)
 			return nil, statusToErrno(status)
@@ -263,6 +270,9 @@ get_rwmp:
 			status, info, err = mw.quotaIcreate(mp, mode, uid, gid, target, quotaIds, fullPath)
 			if err == nil && status == statusOK {
 				goto create_dentry
+			} else if isCreateOnOtherStatus(status) {
+				log.LogWarnf("Mp(%v) takes no new inode, status(%v), create on the next one", mp, status)
+				mw.dropRWPartition(mp)
 			} else if status == statusFull {
 				if retryTime >= InodeFullMaxRetryTime {
 					break
@@ -286,6 +296,9 @@ get_rwmp:
 			status, info, err = mw.icreate(mp, mode, uid, gid, target, fullPath)
 			if err == nil && status == statusOK {
 				goto create_dentry
+			} else if isCreateOnOtherStatus(status) {
+				log.LogWarnf("Mp(%v) takes no new inode, status(%v), create on the next one", mp, status)
+				mw.dropRWPartition(mp)
 			} else if status == statusFull {
 				if retryTime >= InodeFullMaxRetryTime {
 					break
@@ -355,6 +368,30 @@ func (mw *MetaWrapper) Lookup_ll(parentID uint64, name string) (inode uint64, mo
 	return inode, mode, nil
 }
 
+// LookupWithSiblings_ll looks up the name as Lookup_ll, and returns the dentries following it in
+// the parent, up to the lookup prefetch count of the wrapper, for the caller to warm its caches.
+func (mw *MetaWrapper) LookupWithSiblings_ll(parentID uint64, name string) (inode uint64, mode uint32, siblings []proto.LookupSibling, err error) {
+	if mw.lookupPrefetch == 0 {
+		inode, mode, err = mw.Lookup_ll(parentID, name)
+		return
+	}
+	parentMP := mw.getPartitionByInode(parentID)
+	if parentMP == nil {
+		log.LogErrorf("LookupWithSiblings_ll: No parent partition, parentID(%v) name(%v)", parentID, name)
+		return 0, 0, nil, syscall.ENOENT
+	}
+
+	status, inode, mode, siblings, err := mw.lookupWithSiblings(parentMP, parentID, name, mw.VerReadSeq, mw.lookupPrefetch)
+	if err != nil || status != statusOK {
+		return 0, 0, nil, statusToErrno(status)
+	}
+	// only save dir
+	if proto.IsDir(mode) {
+		mw.AddInoInfoCache(inode, parentID, name)
+	}
+	return inode, mode, siblings, nil
+}
+
 func (mw *MetaWrapper) BatchGetExpiredMultipart(prefix string, days int) (expiredIds []*proto.ExpiredMultipartInfo, err error) {
 	partitions := mw.partitions
 	var mp *MetaPartition
@@ -547,6 +584,49 @@ func (mw *MetaWrapper) BatchGetXAttr(inodes []uint64, keys []string) ([]*proto.X
 	return xattrs, nil
 }
 
+// ScanXAttr returns a page of the xattrs of the inodes of the partition from the marker, only
+// the keys given or with the prefix if set, and the marker of the next page, 0 if it is the last.
+func (mw *MetaWrapper) ScanXAttr(pid, marker uint64, limit int, keys []string, prefix string) ([]*proto.XAttrInfo, uint64, error) {
+	mp := mw.getPartitionByID(pid)
+	if mp == nil {
+		log.LogErrorf("ScanXAttr: no such partition, volume(%v) partitionID(%v)", mw.volname, pid)
+		return nil, 0, syscall.ENOENT
+	}
+	return mw.scanXAttr(mp, marker, limit, keys, prefix)
+}
+
+// ScanVolXAttr visits the xattrs of all the inodes of the volume partition by partition, until
+// the visitor returns false.
+func (mw *MetaWrapper) ScanVolXAttr(keys []string, prefix string, visitor func(info *proto.XAttrInfo) bool) error {
+	mw.RLock()
+	pids := make([]uint64, 0, len(mw.partitions))
+	for pid := range mw.partitions {
+		pids = append(pids, pid)
+	}
+	mw.RUnlock()
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	for _, pid := range pids {
+		var marker uint64
+		for {
+			xattrs, next, err := mw.ScanXAttr(pid, marker, 0, keys, prefix)
+			if err != nil {
+				return err
+			}
+			for _, info := range xattrs {
+				if !visitor(info) {
+					return nil
+				}
+			}
+			if next == 0 {
+				break
+			}
+			marker = next
+		}
+	}
+	return nil
+}
+
 func (mw *MetaWrapper) shouldNotMoveToTrash(parentMP *MetaPartition, parentIno uint64, entry string, isDir bool) (error, bool) {
 	log.LogDebugf("action[shouldNotMoveToTrash]: parentIno(%v) entry(%v)", parentIno, entry)
 
@@ -595,6 +675,10 @@ func (mw *MetaWrapper) shouldNotMoveToTrash(parentMP *MetaPartition, parentIno u
 	if mw.trashPolicy.IsTrashRoot(parentIno, entry) {
 		return syscall.EOPNOTSUPP, false
 	}
+	// the trash of the subtree is turned off by the xattr of a dir
+	if mw.isTrashOffFor(parentIno, inode, isDir) {
+		return nil, true
+	}
 	// check if is sub dir of .Trash
 	// get parent path to mount sub
 	currentPath := mw.getCurrentPathToMountSub(parentIno)
@@ -1366,6 +1450,46 @@ func (mw *MetaWrapper) ReadDirLimit_ll(parentID uint64, from string, limit uint6
 	return children, nil
 }
 
+// ReadDirPlus_ll reads limit dentries of the dir from the name as ReadDirLimit_ll does, with
+// the infos of the inodes of them. The inodes in the partition of the dir come with the
+// dentries, the others are fetched by BatchInodeGet. It is ReadDirLimit_ll followed by
+// BatchInodeGet if the readdirplus is not enabled, or the dir is read at a snapshot.
+func (mw *MetaWrapper) ReadDirPlus_ll(parentID uint64, from string, limit uint64) (children []proto.Dentry, infos []*proto.InodeInfo, err error) {
+	plus := mw.readDirPlusEnabled && mw.VerReadSeq == 0 && limit > 0 && limit <= proto.MaxReadDirPlusLimit
+	if !plus {
+		if children, err = mw.ReadDirLimit_ll(parentID, from, limit); err != nil {
+			return
+		}
+		inodes := make([]uint64, 0, len(children))
+		for _, child := range children {
+			inodes = append(inodes, child.Inode)
+		}
+		infos = mw.BatchInodeGet(inodes)
+		return
+	}
+
+	parentMP := mw.getPartitionByInode(parentID)
+	if parentMP == nil {
+		return nil, nil, syscall.ENOENT
+	}
+	status, dirents, remote, err := mw.readDirPlus(parentMP, parentID, from, limit)
+	if err != nil || status != statusOK {
+		return nil, nil, statusToErrno(status)
+	}
+	children = make([]proto.Dentry, 0, len(dirents))
+	infos = make([]*proto.InodeInfo, 0, len(dirents))
+	for _, dirent := range dirents {
+		children = append(children, dirent.Dentry)
+		if dirent.Info != nil {
+			infos = append(infos, dirent.Info)
+		}
+	}
+	if len(remote) > 0 {
+		infos = append(infos, mw.BatchInodeGet(remote)...)
+	}
+	return children, infos, nil
+}
+
 func (mw *MetaWrapper) DentryCreate_ll(parentID uint64, name string, inode uint64, mode uint32, fullPath string) error {
 	parentMP := mw.getPartitionByInode(parentID)
 	if parentMP == nil {
@@ -1400,7 +1524,7 @@ func (mw *MetaWrapper) SplitExtentKey(parentInode, inode uint64, ek proto.Extent
 		return syscall.ENOENT
 	}
 
-	status, err := mw.appendExtentKey(mp, inode, ek, nil, true, false, storageClass, false)
+	status, _, err := mw.appendExtentKey(mp, inode, ek, nil, 0, true, false, storageClass, false)
 	if err != nil || status != statusOK {
 		log.LogErrorf("SplitExtentKey: inode(%v) ek(%v) err(%v) status(%v)", inode, ek, err, status)
 		return statusToErrno(status)
@@ -1410,23 +1534,25 @@ func (mw *MetaWrapper) SplitExtentKey(parentInode, inode uint64, ek proto.Extent
 	return nil
 }
 
-// Used as a callback by stream sdk
+// Used as a callback by stream sdk. gen is the generation of the extents the writer is based on,
+// zero if unknown, and newGen is the one of the inode after the append, zero if not replied.
 func (mw *MetaWrapper) AppendExtentKey(parentInode, inode uint64, ek proto.ExtentKey, discard []proto.ExtentKey,
-	isCache bool, storageClass uint32, isMigration bool,
-) (int, error) {
+	gen uint64, isCache bool, storageClass uint32, isMigration bool,
+) (status int, newGen uint64, err error) {
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
-		return statusError, syscall.ENOENT
+		return statusError, 0, syscall.ENOENT
 	}
 
-	status, err := mw.appendExtentKey(mp, inode, ek, discard, false, isCache, storageClass, isMigration)
+	status, newGen, err = mw.appendExtentKey(mp, inode, ek, discard, gen, false, isCache, storageClass, isMigration)
 	if err != nil || status != statusOK {
-		log.LogErrorf("MetaWrapper AppendExtentKey: inode(%v) ek(%v) local discard(%v) err(%v) status(%v)", inode, ek, discard, err, status)
-		return status, statusToErrno(status)
+		log.LogErrorf("MetaWrapper AppendExtentKey: inode(%v) ek(%v) local discard(%v) gen(%v) err(%v) status(%v)",
+			inode, ek, discard, gen, err, status)
+		return status, 0, statusToErrno(status)
 	}
-	log.LogDebugf("MetaWrapper AppendExtentKey: ino(%v) ek(%v) discard(%v)", inode, ek, discard)
+	log.LogDebugf("MetaWrapper AppendExtentKey: ino(%v) ek(%v) discard(%v) gen(%v) newGen(%v)", inode, ek, discard, gen, newGen)
 
-	return statusOK, nil
+	return statusOK, newGen, nil
 }
 
 // AppendExtentKeys append multiple extent key into specified inode with single request.
@@ -1523,6 +1649,22 @@ func (mw *MetaWrapper) Truncate(inode, size uint64, fullPath string) error {
 	return nil
 }
 
+// ExtentsPreAlloc reserves the range [offset, offset+size) of the inode before it is written,
+// the size of the inode is extended to the end of the range unless keepSize is set.
+func (mw *MetaWrapper) ExtentsPreAlloc(inode, offset, size uint64, keepSize bool, fullPath string) error {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("ExtentsPreAlloc: No inode partition, ino(%v)", inode)
+		return syscall.ENOENT
+	}
+
+	status, err := mw.extentsPreAlloc(mp, inode, offset, size, keepSize, fullPath)
+	if err != nil || status != statusOK {
+		return statusToErrno(status)
+	}
+	return nil
+}
+
 func (mw *MetaWrapper) Link(parentID uint64, name string, ino uint64, fullPath string) (*proto.InodeInfo, error) {
 	// if mw.EnableTransaction {
 	if mw.EnableTransaction&proto.TxOpMaskLink > 0 {
@@ -1541,10 +1683,9 @@ func (mw *MetaWrapper) txLink(parentID uint64, name string, ino uint64, fullPath
 		return nil, syscall.ENOENT
 	}
 
-	mp := mw.getPartitionByInode(ino)
-	if mp == nil {
-		log.LogErrorf("txLink: No target inode partition, ino(%v)", ino)
-		return nil, syscall.ENOENT
+	mp, err := mw.linkTarget(ino)
+	if err != nil {
+		return nil, err
 	}
 	var tx *Transaction
 
@@ -1570,6 +1711,9 @@ func (mw *MetaWrapper) txLink(parentID uint64, name string, ino uint64, fullPath
 		var newSt int
 		var newErr error
 		newSt, info, newErr = mw.txIlink(tx, mp, ino, fullPath)
+		if newSt == statusCrossVolume {
+			mw.countCrossVolumeLink()
+		}
 		return newSt, newErr
 	})
 
@@ -1640,15 +1784,17 @@ func (mw *MetaWrapper) link(parentID uint64, name string, ino uint64, fullPath s
 		return nil, syscall.EDQUOT
 	}
 
-	mp := mw.getPartitionByInode(ino)
-	if mp == nil {
-		log.LogErrorf("Link: No target inode partition, ino(%v)", ino)
-		return nil, syscall.ENOENT
+	mp, err := mw.linkTarget(ino)
+	if err != nil {
+		return nil, err
 	}
 
 	// increase inode nlink
 	status, info, err = mw.ilink(mp, ino, fullPath)
 	if err != nil || status != statusOK {
+		if status == statusCrossVolume {
+			mw.countCrossVolumeLink()
+		}
 		return nil, statusToErrno(status)
 	}
 	if mw.EnableQuota {
@@ -1678,6 +1824,22 @@ func (mw *MetaWrapper) link(parentID uint64, name string, ino uint64, fullPath s
 	return info, nil
 }
 
+// linkTarget returns the partition of the inode linked to. An inode in none of the partitions
+// of the volume is of another volume, and hard links never cross volumes.
+func (mw *MetaWrapper) linkTarget(ino uint64) (*MetaPartition, error) {
+	mp := mw.getPartitionByInode(ino)
+	if mp == nil {
+		mw.countCrossVolumeLink()
+		log.LogErrorf("Link: ino(%v) is in no partition of vol(%v), links across volumes are not supported", ino, mw.volname)
+		return nil, syscall.EXDEV
+	}
+	return mp, nil
+}
+
+func (mw *MetaWrapper) countCrossVolumeLink() {
+	exporter.NewCounter("crossVolumeLink").AddWithLabels(1, map[string]string{exporter.Vol: mw.volname})
+}
+
 func (mw *MetaWrapper) Evict(inode uint64, fullPath string) error {
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
@@ -1712,6 +1874,116 @@ func (mw *MetaWrapper) Setattr(inode uint64, valid, mode, uid, gid uint32, atime
 	return nil
 }
 
+// UpdateLinkTarget rewrites the target of the symlink inode without changing its inode number.
+func (mw *MetaWrapper) UpdateLinkTarget(inode uint64, target []byte) error {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("UpdateLinkTarget: No such partition, ino(%v)", inode)
+		return syscall.EINVAL
+	}
+
+	status, err := mw.updateLinkTarget(mp, inode, target)
+	if err != nil || status != statusOK {
+		log.LogErrorf("UpdateLinkTarget: ino(%v) err(%v) status(%v)", inode, err, status)
+		return statusToErrno(status)
+	}
+
+	return nil
+}
+
+// BatchCreateDentryInode creates the inodes and dentries of the items for bulk imports, the
+// items are sent in one request per meta partition of their parents. The results are in the
+// order of the items, the status of an item is its own result of creating.
+func (mw *MetaWrapper) BatchCreateDentryInode(items []*proto.CreateDentryInodeItem) ([]*proto.CreateDentryInodeResult, error) {
+	batches := make(map[uint64][]int)
+	mps := make(map[uint64]*MetaPartition)
+	for i, item := range items {
+		mp := mw.getPartitionByInode(item.ParentID)
+		if mp == nil {
+			log.LogErrorf("BatchCreateDentryInode: No such partition, ino(%v)", item.ParentID)
+			return nil, syscall.EINVAL
+		}
+		mps[mp.PartitionID] = mp
+		batches[mp.PartitionID] = append(batches[mp.PartitionID], i)
+	}
+
+	results := make([]*proto.CreateDentryInodeResult, len(items))
+	for pid, indexes := range batches {
+		for len(indexes) > 0 {
+			n := len(indexes)
+			if n > maxBatchCreateDentryInodeCount {
+				n = maxBatchCreateDentryInodeCount
+			}
+			batch := make([]*proto.CreateDentryInodeItem, 0, n)
+			for _, i := range indexes[:n] {
+				batch = append(batch, items[i])
+			}
+			status, batchResults, err := mw.batchCreateDentryInode(mps[pid], batch)
+			if err != nil || status != statusOK {
+				log.LogErrorf("BatchCreateDentryInode: mp(%v) count(%v) err(%v) status(%v)", pid, len(batch), err, status)
+				return nil, statusToErrno(status)
+			}
+			if len(batchResults) != n {
+				log.LogErrorf("BatchCreateDentryInode: mp(%v) count(%v) but got %v results", pid, len(batch), len(batchResults))
+				return nil, syscall.EIO
+			}
+			for j, i := range indexes[:n] {
+				results[i] = batchResults[j]
+			}
+			indexes = indexes[n:]
+		}
+	}
+	return results, nil
+}
+
+// DeleteSubtree starts a job on the meta node to delete the entry name of parentID and everything
+// below it, which saves the per-entry ops of rm -rf on a huge directory. The trash of the volume
+// is not used, the subtree should be moved to the trash by a rename instead if it is enabled.
+func (mw *MetaWrapper) DeleteSubtree(parentID uint64, name string) (jobID uint64, err error) {
+	mp := mw.getPartitionByInode(parentID)
+	if mp == nil {
+		log.LogErrorf("DeleteSubtree: No parent partition, parentID(%v) name(%v)", parentID, name)
+		return 0, syscall.ENOENT
+	}
+	if mw.EnableQuota {
+		status, inode, mode, err := mw.lookup(mp, parentID, name, mw.LastVerSeq)
+		if err != nil || status != statusOK {
+			return 0, statusErrToErrno(status, err)
+		}
+		if proto.IsDir(mode) {
+			quotaInfos, err := mw.GetInodeQuota_ll(inode)
+			if err != nil {
+				return 0, syscall.ENOENT
+			}
+			for _, info := range quotaInfos {
+				if info.RootInode {
+					log.LogErrorf("DeleteSubtree: can not remove quota root inode(%v)", inode)
+					return 0, syscall.EACCES
+				}
+			}
+		}
+	}
+	status, jobID, err := mw.deleteSubtree(mp, parentID, name)
+	if err != nil || status != statusOK {
+		return 0, statusErrToErrno(status, err)
+	}
+	return jobID, nil
+}
+
+// DeleteSubtreeStatus gets the progress of a job started by DeleteSubtree on parentID, the job is
+// canceled first if cancel is set.
+func (mw *MetaWrapper) DeleteSubtreeStatus(parentID, jobID uint64, cancel bool) (*proto.DeleteSubtreeJob, error) {
+	mp := mw.getPartitionByInode(parentID)
+	if mp == nil {
+		return nil, syscall.ENOENT
+	}
+	status, job, err := mw.deleteSubtreeStatus(mp, jobID, cancel)
+	if err != nil || status != statusOK {
+		return nil, statusErrToErrno(status, err)
+	}
+	return job, nil
+}
+
 func (mw *MetaWrapper) InodeCreate_ll(parentID uint64, mode, uid, gid uint32, target []byte, quotaIds []uint64, fullPath string) (*proto.InodeInfo, error) {
 	var (
 		status       int
@@ -1747,6 +2019,9 @@ get_rwmp:
 			status, info, err = mw.quotaIcreate(mp, mode, uid, gid, target, quotaIds, fullPath)
 			if err == nil && status == statusOK {
 				return info, nil
+			} else if isCreateOnOtherStatus(status) {
+				log.LogWarnf("Mp(%v) takes no new inode, status(%v), create on the next one", mp, status)
+				mw.dropRWPartition(mp)
 			} else if status == statusFull {
 				if retryTime >= InodeFullMaxRetryTime {
 					break
@@ -1770,6 +2045,9 @@ get_rwmp:
 			status, info, err = mw.icreate(mp, mode, uid, gid, target, fullPath)
 			if err == nil && status == statusOK {
 				return info, nil
+			} else if isCreateOnOtherStatus(status) {
+				log.LogWarnf("Mp(%v) takes no new inode, status(%v), create on the next one", mp, status)
+				mw.dropRWPartition(mp)
 			} else if status == statusFull {
 				if retryTime >= InodeFullMaxRetryTime {
 					break
@@ -2006,7 +2284,12 @@ func (mw *MetaWrapper) ListMultipart_ll(prefix, delimiter, keyMarker string, mul
 			}
 			wl.Lock()
 			defer wl.Unlock()
-			sessions = append(sessions, response.Multiparts...)
+			for _, session := range response.Multiparts {
+				// metanodes of old versions return the marker itself and do not stop at the prefix
+				if multipartAfterMarker(session, prefix, keyMarker, multipartIdMarker) {
+					sessions = append(sessions, session)
+				}
+			}
 		}(mp)
 	}
 
@@ -2017,9 +2300,23 @@ func (mw *MetaWrapper) ListMultipart_ll(prefix, delimiter, keyMarker string, mul
 	sort.SliceStable(sessions, func(i, j int) bool {
 		return (sessions[i].Path < sessions[j].Path) || ((sessions[i].Path == sessions[j].Path) && (sessions[i].ID < sessions[j].ID))
 	})
+	// one more session than maxUploads tells the caller the listing is truncated
+	if uint64(len(sessions)) > maxUploads+1 {
+		sessions = sessions[:maxUploads+1]
+	}
 	return sessions, nil
 }
 
+func multipartAfterMarker(session *proto.MultipartInfo, prefix, keyMarker, multipartIdMarker string) bool {
+	if !strings.HasPrefix(session.Path, prefix) {
+		return false
+	}
+	if keyMarker == "" || session.Path > keyMarker {
+		return true
+	}
+	return session.Path == keyMarker && multipartIdMarker != "" && session.ID > multipartIdMarker
+}
+
 func (mw *MetaWrapper) XAttrSet_ll(inode uint64, name, value []byte) error {
 	var err error
 	mp := mw.getPartitionByInode(inode)
@@ -2032,6 +2329,9 @@ func (mw *MetaWrapper) XAttrSet_ll(inode uint64, name, value []byte) error {
 	if err != nil || status != statusOK {
 		return statusToErrno(status)
 	}
+	if string(name) == TrashXAttrKey {
+		mw.trashXAttrs.delete(inode)
+	}
 	log.LogDebugf("XAttrSet_ll: set xattr: volume(%v) inode(%v) name(%v) value(%v) status(%v)",
 		mw.volname, inode, name, value, status)
 	return nil
@@ -2114,6 +2414,9 @@ func (mw *MetaWrapper) XAttrDel_ll(inode uint64, name string) error {
 	if err != nil || status != statusOK {
 		return statusToErrno(status)
 	}
+	if name == TrashXAttrKey {
+		mw.trashXAttrs.delete(inode)
+	}
 	log.LogDebugf("XAttrDel_ll: remove xattr, inode(%v) name(%v) status(%v)", inode, name, status)
 	return nil
 }
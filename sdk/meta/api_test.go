@@ -0,0 +1,36 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/btree"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkCrossVolume(t *testing.T) {
+	mw := &MetaWrapper{partitions: make(map[uint64]*MetaPartition), ranges: btree.New(32)}
+	mw.replaceOrInsertPartition(&MetaPartition{PartitionID: 1, Start: 1, End: 100})
+
+	mp, err := mw.linkTarget(10)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, mp.PartitionID)
+	_, err = mw.linkTarget(1000)
+	require.Equal(t, syscall.EXDEV, err)
+	require.Equal(t, syscall.EXDEV, statusToErrno(parseStatus(proto.OpCrossVolumeNotSupported)))
+}
@@ -0,0 +1,243 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	attrLeaseWait          = 3 * time.Second // waited by the metanode for an invalidation each poll
+	attrLeaseRetryInterval = time.Second
+)
+
+// attrLeaser keeps the attr leases of the inodes the client caches the attrs of, e.g. the open
+// files, on the leaders of their partitions. Each partition is polled for the inodes changed by
+// the others, and the attrs of all the inodes of a partition are dropped once its leases are
+// lost.
+type attrLeaser struct {
+	sync.Mutex
+	clientID   uint64
+	invalidate func(ino uint64)
+	parts      map[uint64]*attrLeasePart
+}
+
+type attrLeasePart struct {
+	inodes   map[uint64]int // inode -> acquired times
+	added    []uint64       // acquired by the next poll
+	released []uint64       // released by the next poll
+	epoch    uint64         // zero to acquire all the inodes again by the next poll
+	renewed  time.Time      // of the last poll succeeded
+	lease    time.Duration
+}
+
+func newAttrLeaser() *attrLeaser {
+	return &attrLeaser{
+		clientID: rand.New(rand.NewSource(time.Now().UnixNano())).Uint64(),
+		parts:    make(map[uint64]*attrLeasePart),
+	}
+}
+
+func (part *attrLeasePart) allInodes() (inodes []uint64) {
+	inodes = make([]uint64, 0, len(part.inodes))
+	for ino := range part.inodes {
+		inodes = append(inodes, ino)
+	}
+	return
+}
+
+// SetAttrInvalidator sets the func the attrs cached of the inodes changed by the others are
+// dropped by, it is called without the attr leases enabled.
+func (mw *MetaWrapper) SetAttrInvalidator(invalidate func(ino uint64)) {
+	if mw.attrLeases == nil {
+		return
+	}
+	mw.attrLeases.Lock()
+	mw.attrLeases.invalidate = invalidate
+	mw.attrLeases.Unlock()
+}
+
+// AcquireAttrLease acquires the attr lease of the inode, the invalidator is called once the
+// inode is changed by the others until the lease is released as many times as acquired.
+func (mw *MetaWrapper) AcquireAttrLease(ino uint64) {
+	l := mw.attrLeases
+	if l == nil {
+		return
+	}
+	mp := mw.getPartitionByInode(ino)
+	if mp == nil {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	part, ok := l.parts[mp.PartitionID]
+	if !ok {
+		part = &attrLeasePart{inodes: make(map[uint64]int)}
+		l.parts[mp.PartitionID] = part
+		go mw.pollAttrLeases(mp.PartitionID, part)
+	}
+	if part.inodes[ino]++; part.inodes[ino] == 1 {
+		part.added = append(part.added, ino)
+	}
+}
+
+// ReleaseAttrLease releases the attr lease of the inode acquired.
+func (mw *MetaWrapper) ReleaseAttrLease(ino uint64) {
+	l := mw.attrLeases
+	if l == nil {
+		return
+	}
+	mp := mw.getPartitionByInode(ino)
+	if mp == nil {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	part, ok := l.parts[mp.PartitionID]
+	if !ok || part.inodes[ino] == 0 {
+		return
+	}
+	if part.inodes[ino]--; part.inodes[ino] == 0 {
+		delete(part.inodes, ino)
+		part.released = append(part.released, ino)
+	}
+}
+
+// nextPoll returns the request of the next poll with the changes since the last one, or nil
+// if all the leases are released.
+func (part *attrLeasePart) nextPoll() (req *proto.AttrLeaseRequest) {
+	if len(part.inodes) == 0 && len(part.released) == 0 {
+		return nil
+	}
+	req = &proto.AttrLeaseRequest{
+		Epoch:     part.epoch,
+		Inodes:    part.added,
+		Release:   part.released,
+		TimeoutMs: attrLeaseWait.Milliseconds(),
+	}
+	if part.epoch == 0 {
+		req.Inodes, req.Release = part.allInodes(), nil
+	}
+	part.added, part.released = nil, nil
+	return
+}
+
+// polled updates the leases by the result of the poll sent at sent, and returns the inodes
+// whose attrs are to be dropped.
+func (part *attrLeasePart) polled(req *proto.AttrLeaseRequest, resp *proto.AttrLeaseResponse, err error, sent time.Time) (invalidated []uint64) {
+	if err != nil {
+		// acquired again once the leader is reached
+		part.epoch = 0
+		if time.Since(part.renewed) > part.lease {
+			invalidated = part.allInodes()
+		}
+		return
+	}
+	part.renewed, part.lease = sent, time.Duration(resp.LeaseMs)*time.Millisecond
+	part.epoch = resp.Epoch
+	if !resp.All {
+		return resp.Invalidated
+	}
+	if req.Epoch != 0 {
+		// the leases are lost, acquire all again
+		part.epoch = 0
+	}
+	return part.allInodes()
+}
+
+// pollAttrLeases renews the attr leases of the partition until all are released.
+func (mw *MetaWrapper) pollAttrLeases(pid uint64, part *attrLeasePart) {
+	l := mw.attrLeases
+	for {
+		l.Lock()
+		req := part.nextPoll()
+		if req == nil {
+			delete(l.parts, pid)
+			l.Unlock()
+			return
+		}
+		l.Unlock()
+		req.VolName, req.PartitionID, req.ClientID = mw.volname, pid, l.clientID
+
+		sent := time.Now()
+		resp, err := mw.attrLease(req)
+
+		l.Lock()
+		invalidated := part.polled(req, resp, err, sent)
+		invalidate := l.invalidate
+		l.Unlock()
+
+		if invalidate != nil {
+			for _, ino := range invalidated {
+				invalidate(ino)
+			}
+		}
+		if len(invalidated) > 0 {
+			exporter.NewCounter("attrLeaseInvalidated").AddWithLabels(int64(len(invalidated)), map[string]string{exporter.Vol: mw.volname})
+		}
+		if err != nil {
+			log.LogWarnf("pollAttrLeases: vol(%v) mp(%v) err(%v)", mw.volname, pid, err)
+			select {
+			case <-time.After(attrLeaseRetryInterval):
+			case <-mw.closeCh:
+				return
+			}
+			continue
+		}
+		select {
+		case <-mw.closeCh:
+			return
+		default:
+		}
+	}
+}
+
+func (mw *MetaWrapper) attrLease(req *proto.AttrLeaseRequest) (resp *proto.AttrLeaseResponse, err error) {
+	mp := mw.getPartitionByID(req.PartitionID)
+	if mp == nil {
+		return nil, fmt.Errorf("no such partition %v", req.PartitionID)
+	}
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaAttrLease
+	packet.PartitionID = req.PartitionID
+	if err = packet.MarshalData(req); err != nil {
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	if packet, err = mw.sendToMetaPartition(mp, packet); err != nil {
+		return
+	}
+	if status := parseStatus(packet.ResultCode); status != statusOK {
+		err = fmt.Errorf("attr lease of mp(%v): %v", req.PartitionID, packet.GetResultMsg())
+		return
+	}
+	resp = &proto.AttrLeaseResponse{}
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("attrLease: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+	}
+	return
+}
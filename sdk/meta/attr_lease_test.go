@@ -0,0 +1,66 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttrLeasePart(t *testing.T) {
+	part := &attrLeasePart{inodes: map[uint64]int{10: 1, 11: 2}, added: []uint64{10, 11}}
+
+	// the first poll acquires all
+	req := part.nextPoll()
+	require.ElementsMatch(t, []uint64{10, 11}, req.Inodes)
+	resp := &proto.AttrLeaseResponse{Epoch: 7, LeaseMs: 10000, All: true}
+	require.ElementsMatch(t, []uint64{10, 11}, part.polled(req, resp, nil, time.Now()))
+	require.EqualValues(t, 7, part.epoch)
+
+	// the next polls carry the changes only
+	part.inodes[12] = 1
+	part.added = append(part.added, 12)
+	delete(part.inodes, 10)
+	part.released = append(part.released, 10)
+	req = part.nextPoll()
+	require.EqualValues(t, 7, req.Epoch)
+	require.Equal(t, []uint64{12}, req.Inodes)
+	require.Equal(t, []uint64{10}, req.Release)
+	resp = &proto.AttrLeaseResponse{Epoch: 7, LeaseMs: 10000, Invalidated: []uint64{11}}
+	require.Equal(t, []uint64{11}, part.polled(req, resp, nil, time.Now()))
+
+	// the leases lost are acquired again
+	req = part.nextPoll()
+	require.Empty(t, req.Inodes)
+	resp = &proto.AttrLeaseResponse{Epoch: 8, LeaseMs: 10000, All: true}
+	require.ElementsMatch(t, []uint64{11, 12}, part.polled(req, resp, nil, time.Now()))
+	require.Zero(t, part.epoch)
+	req = part.nextPoll()
+	require.Zero(t, req.Epoch)
+	require.ElementsMatch(t, []uint64{11, 12}, req.Inodes)
+
+	// the attrs are kept across the failures until the lease expires
+	err := errors.New("timeout")
+	require.Empty(t, part.polled(req, nil, err, time.Now()))
+	part.renewed = time.Now().Add(-time.Minute)
+	require.ElementsMatch(t, []uint64{11, 12}, part.polled(req, nil, err, time.Now()))
+
+	part.inodes = map[uint64]int{}
+	require.Nil(t, part.nextPoll())
+}
@@ -18,15 +18,18 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/cubefs/cubefs/depends/xtaci/smux"
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
 	"github.com/cubefs/cubefs/util/stat"
+	"github.com/cubefs/cubefs/util/tracing"
 )
 
 const (
@@ -37,9 +40,15 @@ const (
 )
 
 type MetaConn struct {
-	conn *net.TCPConn
-	id   uint64 // PartitionID
-	addr string // MetaNode addr
+	conn  net.Conn // a pooled connection, or a stream of a multiplexed one if muxed
+	muxed bool
+	id    uint64 // PartitionID
+	addr  string // MetaNode addr
+
+	acceptCompress bool
+	background     bool
+	tracer         *tracing.Tracer
+	accessToken    string
 }
 
 // Connection managements
@@ -50,16 +59,25 @@ func (mc *MetaConn) String() string {
 }
 
 func (mw *MetaWrapper) getConn(partitionID uint64, addr string) (*MetaConn, error) {
+	mc := &MetaConn{id: partitionID, addr: addr, acceptCompress: mw.metaCompression, background: mw.background, tracer: mw.tracer, accessToken: mw.getMetaToken()}
+	if stream := mw.getMuxStream(addr); stream != nil {
+		mc.conn, mc.muxed = stream, true
+		return mc, nil
+	}
 	conn, err := mw.conns.GetConnect(addr)
 	if err != nil {
 		return nil, err
 	}
-	mc := &MetaConn{conn: conn, id: partitionID, addr: addr}
+	mc.conn = conn
 	return mc, nil
 }
 
 func (mw *MetaWrapper) putConn(mc *MetaConn, err error) {
-	mw.conns.PutConnectEx(mc.conn, err)
+	if mc.muxed {
+		mw.putMuxStream(mc.conn.(*smux.Stream), err)
+		return
+	}
+	mw.conns.PutConnectEx(mc.conn.(*net.TCPConn), err)
 }
 
 func (mw *MetaWrapper) sendToMetaPartitionLeader(mp *MetaPartition, req *proto.Packet, sendTimeLimit int) (*proto.Packet, error) {
@@ -355,11 +373,37 @@ func (mw *MetaWrapper) getAppliedID(mp *MetaPartition, addr string) (id uint64,
 
 func (mc *MetaConn) send(req *proto.Packet) (resp *proto.Packet, err error) {
 	req.ExtentType |= proto.PacketProtocolVersionFlag
+	if mc.acceptCompress {
+		req.ExtentType |= proto.PacketAcceptCompressFlag
+	}
+	if mc.background {
+		req.SetPriority(proto.PacketPriorityBackground)
+	}
+	req.SetMetaTimeout(proto.ReadDeadlineTime * time.Second)
+	req.SetAccessToken(mc.accessToken)
+	// each try of the request is a trace of its own
+	req.ClearTraceContext()
+	span := mc.tracer.StartSpan(req.GetOpMsg())
+	if span != nil {
+		req.SetTraceContext(span.IDs())
+		span.SetAttr("mp", strconv.FormatUint(req.PartitionID, 10))
+		span.SetAttr("reqId", strconv.FormatInt(req.ReqID, 10))
+		span.SetAttr("addr", mc.addr)
+		defer func() {
+			if err != nil {
+				span.SetAttr("err", err.Error())
+			} else {
+				span.SetAttr("result", resp.GetResultMsg())
+			}
+			span.Finish()
+		}()
+	}
 
 	err = req.WriteToConn(mc.conn)
 	if err != nil {
 		return nil, errors.Trace(err, "Failed to write to conn, req(%v)", req)
 	}
+	span.Event("sent")
 	resp = proto.NewPacket()
 	err = resp.ReadFromConnWithVer(mc.conn, proto.ReadDeadlineTime)
 	if err != nil {
@@ -371,5 +415,8 @@ func (mc *MetaConn) send(req *proto.Packet) (resp *proto.Packet, err error) {
 			mc.conn.LocalAddr(), mc.conn.RemoteAddr(), req, resp)
 		return nil, syscall.EBADMSG
 	}
+	if err = resp.DecompressData(); err != nil {
+		return nil, errors.Trace(err, "Failed to decompress, req(%v)", req)
+	}
 	return resp, nil
 }
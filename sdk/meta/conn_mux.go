@@ -0,0 +1,93 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/xtaci/smux"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	muxStreamsPerConn = 64
+	muxDialTimeout    = 3 * time.Second
+	// the metanodes failing the multiplexed connections, e.g. of an old version, are sent to
+	// over the pooled connections again after it
+	muxRetryInterval = time.Minute
+)
+
+// metaMux multiplexes the requests to the metanodes over a few connections each, a request
+// sent over a stream of its own with its own deadlines. The streams are served by the smux
+// server of the metanodes on the port shifted by the smuxPortShift of the metanodes.
+type metaMux struct {
+	pool      *util.SmuxConnectPool
+	failedMu  sync.RWMutex
+	failed    map[string]time.Time // the metanodes failing the multiplexed connections
+	portShift int
+}
+
+func newMetaMux(conns, portShift int) *metaMux {
+	if portShift <= 0 {
+		portShift = util.DefaultSmuxPortShift
+	}
+	cfg := util.DefaultSmuxConnPoolConfig()
+	cfg.ConnsPerAddr = conns
+	cfg.StreamsPerConn = muxStreamsPerConn
+	cfg.DialTimeout = muxDialTimeout
+	return &metaMux{
+		pool:      util.NewSmuxConnectPool(cfg),
+		failed:    make(map[string]time.Time),
+		portShift: portShift,
+	}
+}
+
+func (m *metaMux) isFailed(addr string) bool {
+	m.failedMu.RLock()
+	at, ok := m.failed[addr]
+	m.failedMu.RUnlock()
+	return ok && time.Since(at) < muxRetryInterval
+}
+
+func (m *metaMux) setFailed(addr string) {
+	m.failedMu.Lock()
+	m.failed[addr] = time.Now()
+	m.failedMu.Unlock()
+}
+
+// getMuxStream returns a stream to the metanode, nil if the requests are not multiplexed or
+// the metanode fails the multiplexed connections.
+func (mw *MetaWrapper) getMuxStream(addr string) *smux.Stream {
+	m := mw.mux
+	if m == nil || m.isFailed(addr) {
+		return nil
+	}
+	stream, err := m.pool.GetConnect(util.ShiftAddrPort(addr, m.portShift))
+	if err != nil {
+		log.LogWarnf("getMuxStream: metanode(%v) fails the multiplexed connections, send over the pooled ones for %v, err(%v)",
+			addr, muxRetryInterval, err)
+		m.setFailed(addr)
+		return nil
+	}
+	return stream
+}
+
+// putMuxStream closes the stream failed, e.g. timed out, the other streams of the connection
+// are kept.
+func (mw *MetaWrapper) putMuxStream(stream *smux.Stream, err error) {
+	mw.mux.pool.PutConnect(stream, err != nil)
+}
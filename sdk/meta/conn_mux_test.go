@@ -0,0 +1,117 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/xtaci/smux"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+// startMuxMetaNode serves the packets over smux streams as the metanodes, each is replied after
+// a delay, and returns the addr of the metanode with the port shifted by 1.
+func startMuxMetaNode(t *testing.T, sessions *int32) (addr string, ln net.Listener) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(sessions, 1)
+			go func() {
+				sess, err := smux.Server(conn, smux.DefaultConfig())
+				if err != nil {
+					return
+				}
+				for {
+					stream, err := sess.AcceptStream()
+					if err != nil {
+						return
+					}
+					go func() {
+						for {
+							p := proto.NewPacket()
+							if err := p.ReadFromConnWithVer(stream, proto.NoReadDeadlineTime); err != nil {
+								return
+							}
+							time.Sleep(50 * time.Millisecond)
+							p.ResultCode = proto.OpOk
+							if err := p.WriteToConn(stream); err != nil {
+								return
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+	return fmt.Sprintf("127.0.0.1:%d", port-1), ln
+}
+
+func TestMetaMux(t *testing.T) {
+	proto.InitBufferPool(32768)
+	var sessions int32
+	addr, ln := startMuxMetaNode(t, &sessions)
+	defer ln.Close()
+
+	require.Equal(t, util.DefaultSmuxPortShift, newMetaMux(2, 0).portShift)
+	mw := &MetaWrapper{conns: util.NewConnectPool(), mux: newMetaMux(2, 1)}
+	defer mw.mux.pool.Close()
+
+	// the requests in flight share the connections of the metanode
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mc, err := mw.getConn(1, addr)
+			require.NoError(t, err)
+			require.True(t, mc.muxed)
+			req := proto.NewPacketReqID()
+			req.Opcode = proto.OpMetaInodeGet
+			resp, err := mc.send(req)
+			mw.putConn(mc, err)
+			require.NoError(t, err)
+			require.Equal(t, req.ReqID, resp.ReqID)
+		}()
+	}
+	wg.Wait()
+	require.Less(t, time.Since(start), 2*time.Second)
+	require.LessOrEqual(t, atomic.LoadInt32(&sessions), int32(2))
+
+	// a metanode failing the multiplexed connections is sent to over the pooled ones
+	ln2, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln2.Close()
+	plain := ln2.Addr().String()
+	require.Nil(t, mw.getMuxStream(plain))
+	require.True(t, mw.mux.isFailed(plain))
+	mc, err := mw.getConn(1, plain)
+	require.NoError(t, err)
+	require.False(t, mc.muxed)
+	mw.putConn(mc, nil)
+}
@@ -0,0 +1,273 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"encoding/json"
+	"math/rand"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/stat"
+)
+
+const (
+	fileLockLease         = 30 // unit seconds
+	fileLockRenewInterval = 10 * time.Second
+	fileLockWait          = 3 * time.Second // waited by the metanode for a blocking lock each time, under the read deadline
+)
+
+// initFileLock sets the owner of the file locks of the client and starts renewing their
+// leases, the metanodes release the locks once the leases expire.
+func (mw *MetaWrapper) initFileLock() {
+	mw.fileLockOnce.Do(func() {
+		mw.fileLockOwner = proto.FileLockOwner{
+			ClientID: rand.New(rand.NewSource(time.Now().UnixNano())).Uint64(),
+			ClientIP: mw.localIP,
+		}
+		mw.fileLockMutex.Lock()
+		mw.fileLockHolders = make(map[uint64]map[proto.FileLockHolder]struct{})
+		mw.fileLockMutex.Unlock()
+		go mw.renewFileLocks()
+	})
+}
+
+// FileLock acquires or releases the flock or POSIX lock of the inode for the owner of the
+// lock, EAGAIN is returned if it conflicts with another lock. A blocking lock waits until it
+// is acquired, and a test returns the conflicting lock, nil if none, without acquiring it.
+func (mw *MetaWrapper) FileLock(ino uint64, lock *proto.FileLock, block, test bool) (conflict *proto.FileLock, err error) {
+	mp := mw.getPartitionByInode(ino)
+	if mp == nil {
+		log.LogErrorf("FileLock: no such partition, ino(%v)", ino)
+		return nil, syscall.ENOENT
+	}
+	mw.initFileLock()
+	lk := *lock
+	lk.FileLockOwner = mw.fileLockOwner
+	holder := proto.FileLockHolder{Inode: ino, Owner: lk.Owner}
+	if !test && lk.Type != proto.FileLockUnlock && !mw.isFileLockHeld(mp.PartitionID, holder) {
+		// held before the lock is granted, not to miss the renewal of the lease
+		mw.holdFileLock(mp.PartitionID, holder, true)
+		defer func() {
+			if err != nil {
+				mw.holdFileLock(mp.PartitionID, holder, false)
+			}
+		}()
+	} else if !test && lk.Type == proto.FileLockUnlock && (lk.Flock || lk.Start == 0 && lk.End == 0) {
+		// the owner unlocks all its locks of the kind
+		defer mw.holdFileLock(mp.PartitionID, holder, false)
+	}
+	for {
+		var status int
+		status, conflict, err = mw.fileLock(mp, ino, &lk, block, test)
+		if err != nil {
+			if !test && lk.Type != proto.FileLockUnlock {
+				// the lock may be granted with the response lost, not to be held by nobody knowing it
+				unlock := lk
+				unlock.Type = proto.FileLockUnlock
+				if _, _, uerr := mw.fileLock(mp, ino, &unlock, false, false); uerr != nil {
+					log.LogWarnf("FileLock: unlock after failure, ino(%v) lock(%v) err(%v)", ino, unlock, uerr)
+				}
+			}
+			return
+		}
+		if status == statusExist {
+			if test {
+				return conflict, nil
+			}
+			if block {
+				select {
+				case <-mw.closeCh:
+					return nil, syscall.EINTR
+				default:
+				}
+				continue
+			}
+			return conflict, syscall.EAGAIN
+		}
+		if status != statusOK {
+			return nil, statusToErrno(status)
+		}
+		return nil, nil
+	}
+}
+
+// ReleaseFileLocks releases the flocks or the POSIX locks of the owner on the inode, once the
+// owner closes the file. Nothing is sent if the owner holds no lock of the inode.
+func (mw *MetaWrapper) ReleaseFileLocks(ino, owner uint64, flock bool) error {
+	mp := mw.getPartitionByInode(ino)
+	if mp == nil || !mw.isFileLockHeld(mp.PartitionID, proto.FileLockHolder{Inode: ino, Owner: owner}) {
+		return nil
+	}
+	_, err := mw.FileLock(ino, &proto.FileLock{Owner: owner, Type: proto.FileLockUnlock, Flock: flock}, false, false)
+	return err
+}
+
+func (mw *MetaWrapper) holdFileLock(pid uint64, holder proto.FileLockHolder, hold bool) {
+	mw.fileLockMutex.Lock()
+	defer mw.fileLockMutex.Unlock()
+	holders, ok := mw.fileLockHolders[pid]
+	if hold && !ok {
+		holders = make(map[proto.FileLockHolder]struct{})
+		mw.fileLockHolders[pid] = holders
+	}
+	if hold {
+		holders[holder] = struct{}{}
+	} else if ok {
+		delete(holders, holder)
+	}
+}
+
+func (mw *MetaWrapper) isFileLockHeld(pid uint64, holder proto.FileLockHolder) bool {
+	mw.fileLockMutex.Lock()
+	defer mw.fileLockMutex.Unlock()
+	_, ok := mw.fileLockHolders[pid][holder]
+	return ok
+}
+
+func (mw *MetaWrapper) fileLock(mp *MetaPartition, ino uint64, lk *proto.FileLock, block, test bool) (status int, conflict *proto.FileLock, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("fileLock", err, bgTime, 1)
+	}()
+
+	req := &proto.FileLockRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		Inode:       ino,
+		Lock:        *lk,
+		Test:        test,
+		Block:       block,
+		WaitMs:      uint32(fileLockWait / time.Millisecond),
+		Lease:       fileLockLease,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaFileLock
+	packet.PartitionID = mp.PartitionID
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("fileLock: marshal packet fail, err(%v)", err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("fileLock: send to partition fail, packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK && status != statusExist {
+		log.LogWarnf("fileLock: received fail status, packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	resp := &proto.FileLockResponse{}
+	if err = json.Unmarshal(packet.Data, resp); err != nil {
+		log.LogErrorf("fileLock: unmarshal fail, packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+	log.LogDebugf("fileLock: packet(%v) mp(%v) req(%v) conflict(%v)", packet, mp, *req, resp.Conflict)
+	return status, resp.Conflict, nil
+}
+
+func (mw *MetaWrapper) fileLockLease(mp *MetaPartition, holders []proto.FileLockHolder, release bool) (err error) {
+	req := &proto.FileLockLeaseRequest{
+		VolName:       mw.volname,
+		PartitionID:   mp.PartitionID,
+		FileLockOwner: mw.fileLockOwner,
+		Holders:       holders,
+		Lease:         fileLockLease,
+		Release:       release,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaFileLockLease
+	packet.PartitionID = mp.PartitionID
+	if err = packet.MarshalData(req); err != nil {
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	if packet, err = mw.sendToMetaPartition(mp, packet); err != nil {
+		return
+	}
+	if status := parseStatus(packet.ResultCode); status != statusOK {
+		err = statusToErrno(status)
+	}
+	return
+}
+
+// fileLockPartitions returns the holders of the locks by partition, the partitions without
+// holders any more are dropped.
+func (mw *MetaWrapper) fileLockPartitions() (holders map[*MetaPartition][]proto.FileLockHolder) {
+	mw.fileLockMutex.Lock()
+	defer mw.fileLockMutex.Unlock()
+	holders = make(map[*MetaPartition][]proto.FileLockHolder, len(mw.fileLockHolders))
+	for pid, held := range mw.fileLockHolders {
+		if len(held) == 0 {
+			delete(mw.fileLockHolders, pid)
+			continue
+		}
+		mp := mw.getPartitionByID(pid)
+		if mp == nil {
+			continue
+		}
+		for holder := range held {
+			holders[mp] = append(holders[mp], holder)
+		}
+	}
+	return
+}
+
+// renewFileLocks renews the leases of the locks still held, those of the owners gone, e.g.
+// failing to be released once closed, expire with their leases.
+func (mw *MetaWrapper) renewFileLocks() {
+	t := time.NewTicker(fileLockRenewInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			for mp, holders := range mw.fileLockPartitions() {
+				if err := mw.fileLockLease(mp, holders, false); err != nil {
+					log.LogWarnf("renewFileLocks: vol(%v) mp(%v) err(%v)", mw.volname, mp.PartitionID, err)
+				}
+			}
+		case <-mw.closeCh:
+			return
+		}
+	}
+}
+
+// releaseFileLocks releases the file locks of the client once it closes, those failing are
+// released by the metanodes once their leases expire.
+func (mw *MetaWrapper) releaseFileLocks() {
+	for mp := range mw.fileLockPartitions() {
+		if err := mw.fileLockLease(mp, nil, true); err != nil {
+			log.LogWarnf("releaseFileLocks: vol(%v) mp(%v) err(%v)", mw.volname, mp.PartitionID, err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ package meta
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/cubefs/cubefs/util/btree"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/tracing"
 )
 
 const (
@@ -61,6 +63,12 @@ const (
 	statusNotEmpty
 	statusLeaseOccupiedByOthers
 	statusLeaseGenerationNotMatch
+	statusXAttrLimitExceeded
+	statusCursorExhausted
+	statusPartitionFrozen
+	statusCrossVolume
+	statusOpRateLimited
+	StatusStaleExtents
 )
 
 const (
@@ -93,6 +101,16 @@ type MetaConfig struct {
 	ValidateOwner    bool
 	OnAsyncTaskError AsyncTaskErrorFunc
 	MetaSendTimeout  int64
+	MetaCompression  bool    // accept the compressed responses
+	LookupPrefetch   uint32  // the siblings following the name returned by a lookup, 0 disables it
+	ReadDirPlus      bool    // the dirs are read with the inodes of the dentries, the metanodes must support it
+	TraceSampleRate  float64 // the ratio of the requests traced across the metanodes, 0 disables it
+	Background       bool    // the requests are of a background job, scheduled after the interactive ones
+	AccessKey        string  // of the user the meta access token of the vol is asked for by
+	SecretKey        string
+	MuxConns         int  // the connections to a metanode the requests are multiplexed over, 0 disables it
+	MuxPortShift     int  // of the smux port of the metanodes, 0 is the default one
+	AttrLease        bool // the attrs of the inodes leased are dropped once they are changed by the others
 	// EnableTransaction uint8
 	// EnableTransaction bool
 	MountPoint                 string
@@ -118,6 +136,8 @@ type MetaWrapper struct {
 	mc                *masterSDK.MasterClient
 	ac                *authSDK.AuthClient
 	conns             *util.ConnectPool
+	mux               *metaMux    // nil if the requests are not multiplexed
+	attrLeases        *attrLeaser // nil if the attr leases are not enabled
 
 	// Callback handler for handling asynchronous task errors.
 	onAsyncTaskError AsyncTaskErrorFunc
@@ -148,6 +168,18 @@ type MetaWrapper struct {
 	closeCh   chan struct{}
 	closeOnce sync.Once
 
+	// the file locks of the client are renewed for the owners holding them, by partition
+	fileLockOnce    sync.Once
+	fileLockOwner   proto.FileLockOwner
+	fileLockMutex   sync.Mutex
+	fileLockHolders map[uint64]map[proto.FileLockHolder]struct{}
+
+	// the meta access token of the vol, asked for by the keys of the user, if the vol
+	// enforces the meta auth
+	accessKey string
+	secretKey string
+	metaToken atomic.Value // string
+
 	// Allocated to signal the go routines which are waiting for partition view update
 	partMutex sync.Mutex
 	partCond  *sync.Cond
@@ -157,6 +189,11 @@ type MetaWrapper struct {
 	forceUpdateLimit        *rate.Limiter
 	singleflight            singleflight.Group
 	metaSendTimeout         int64
+	metaCompression         bool
+	background              bool            // the requests are sent with proto.PacketPriorityBackground
+	tracer                  *tracing.Tracer // nil if the requests are not traced
+	lookupPrefetch          uint32
+	readDirPlusEnabled      bool
 	leaderRetryTimeout      int64 // s
 	DirChildrenNumLimit     uint32
 	EnableTransaction       proto.TxOpMask
@@ -182,6 +219,7 @@ type MetaWrapper struct {
 	subDir        string
 
 	disableTrashByClient bool
+	trashXAttrs          trashXAttrCache // of the dirs, to turn off the trash of subtrees
 
 	VerReadSeq          uint64
 	LastVerSeq          uint64
@@ -239,7 +277,23 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 	mw.mc = masterSDK.NewMasterClient(config.Masters, false)
 	mw.onAsyncTaskError = config.OnAsyncTaskError
 	mw.metaSendTimeout = config.MetaSendTimeout
+	mw.metaCompression = config.MetaCompression
+	mw.background = config.Background
+	mw.lookupPrefetch = config.LookupPrefetch
+	mw.readDirPlusEnabled = config.ReadDirPlus
+	if mw.lookupPrefetch > proto.MaxLookupSiblings {
+		mw.lookupPrefetch = proto.MaxLookupSiblings
+	}
+	if config.TraceSampleRate > 0 {
+		mw.tracer = tracing.NewTracer("client", config.TraceSampleRate, tracing.DefaultRecentSpans)
+	}
 	mw.conns = util.NewConnectPool()
+	if config.MuxConns > 0 {
+		mw.mux = newMetaMux(config.MuxConns, config.MuxPortShift)
+	}
+	if config.AttrLease {
+		mw.attrLeases = newAttrLeaser()
+	}
 	mw.partitions = make(map[uint64]*MetaPartition)
 	mw.ranges = btree.New(32)
 	mw.rwPartitions = make([]*MetaPartition, 0)
@@ -256,6 +310,8 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 	mw.DefaultStorageClass = proto.StorageClass_Unspecified
 	mw.InnerReq = config.InnerReq
 	mw.disableTrashByClient = config.DisableTrashByClient
+	mw.accessKey = config.AccessKey
+	mw.secretKey = config.SecretKey
 
 	for limit > 0 {
 		err = mw.initMetaWrapper()
@@ -287,6 +343,11 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 
 	go mw.updateQuotaInfoTick()
 	go mw.refresh()
+	if mw.accessKey != "" {
+		// the modifying ops right after the mount carry the token
+		next := mw.updateMetaToken()
+		go mw.refreshMetaToken(next)
+	}
 	return mw, nil
 }
 
@@ -354,8 +415,12 @@ func (mw *MetaWrapper) VolCreateTime() int64 {
 
 func (mw *MetaWrapper) Close() error {
 	mw.closeOnce.Do(func() {
+		mw.releaseFileLocks()
 		close(mw.closeCh)
 		mw.conns.Close()
+		if mw.mux != nil {
+			mw.mux.pool.Close()
+		}
 		mw.qc.Close()
 	})
 	return nil
@@ -408,6 +473,18 @@ func parseStatus(result uint8) (status int) {
 		status = statusLeaseOccupiedByOthers
 	case proto.OpLeaseGenerationNotMatch:
 		status = statusLeaseGenerationNotMatch
+	case proto.OpXAttrLimitExceeded:
+		status = statusXAttrLimitExceeded
+	case proto.OpCursorExhausted:
+		status = statusCursorExhausted
+	case proto.OpPartitionFrozen:
+		status = statusPartitionFrozen
+	case proto.OpCrossVolumeNotSupported:
+		status = statusCrossVolume
+	case proto.OpMetaOpRateLimited:
+		status = statusOpRateLimited
+	case proto.OpStaleExtentsErr:
+		status = StatusStaleExtents
 	default:
 		status = statusError
 	}
@@ -463,6 +540,18 @@ func statusToErrno(status int) error {
 		return errors.New("lease occupied by others")
 	case statusLeaseGenerationNotMatch:
 		return errors.New("lease generation not match")
+	case statusXAttrLimitExceeded:
+		return syscall.E2BIG
+	case statusCursorExhausted:
+		return syscall.ENOMEM
+	case statusPartitionFrozen:
+		return syscall.ENOSPC
+	case statusCrossVolume:
+		return syscall.EXDEV
+	case statusOpRateLimited:
+		return syscall.EAGAIN
+	case StatusStaleExtents:
+		return syscall.EAGAIN
 	default:
 	}
 	return syscall.EIO
@@ -0,0 +1,62 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the interval of asking for the token again if master replies none or fails
+const metaTokenRetryInterval = time.Minute
+
+func (mw *MetaWrapper) getMetaToken() string {
+	token, _ := mw.metaToken.Load().(string)
+	return token
+}
+
+// updateMetaToken asks master for the meta access token of the vol, and returns when to ask
+// for it again, halfway to its expiry. The token asked for before is kept on failures until
+// it expires, the metanodes refuse it then.
+func (mw *MetaWrapper) updateMetaToken() (next time.Duration) {
+	reply, err := mw.mc.ClientAPI().GetMetaAccessToken(mw.volname, mw.accessKey, mw.secretKey)
+	if err != nil {
+		log.LogWarnf("updateMetaToken: vol(%v) ak(%v) err(%v)", mw.volname, mw.accessKey, err)
+		return metaTokenRetryInterval
+	}
+	mw.metaToken.Store(reply.Token)
+	if reply.Token == "" {
+		return metaTokenRetryInterval
+	}
+	log.LogInfof("updateMetaToken: vol(%v) ak(%v) token expires at %v", mw.volname, mw.accessKey, time.Unix(reply.Expire, 0))
+	if next = time.Until(time.Unix(reply.Expire, 0)) / 2; next < metaTokenRetryInterval {
+		next = metaTokenRetryInterval
+	}
+	return
+}
+
+func (mw *MetaWrapper) refreshMetaToken(next time.Duration) {
+	t := time.NewTimer(next)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			t.Reset(mw.updateMetaToken())
+		case <-mw.closeCh:
+			return
+		}
+	}
+}
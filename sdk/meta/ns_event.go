@@ -0,0 +1,66 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// WatchEvents returns the namespace events of the meta partition after from, which is the
+// Next of the last response, or zero to watch from the events to come. It waits up to timeout
+// for an event if there are none yet, the metanode waits up to 3s.
+func (mw *MetaWrapper) WatchEvents(partitionID, from uint64, limit int, timeout time.Duration) (resp *proto.WatchEventsResponse, err error) {
+	mp := mw.getPartitionByID(partitionID)
+	if mp == nil {
+		return nil, syscall.ENOENT
+	}
+	req := &proto.WatchEventsRequest{
+		VolName:     mw.volname,
+		PartitionID: partitionID,
+		From:        from,
+		Limit:       limit,
+		TimeoutMs:   timeout.Milliseconds(),
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaWatchEvents
+	packet.PartitionID = partitionID
+	if err = packet.MarshalData(req); err != nil {
+		return
+	}
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("WatchEvents: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+	if status := parseStatus(packet.ResultCode); status != statusOK {
+		err = fmt.Errorf("watch events of mp(%v): %v", partitionID, packet.GetResultMsg())
+		log.LogErrorf("WatchEvents: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp = &proto.WatchEventsResponse{}
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("WatchEvents: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	return
+}
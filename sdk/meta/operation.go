@@ -82,7 +82,7 @@ func (mw *MetaWrapper) txIcreate(tx *Transaction, mp *MetaPartition, mode, uid,
 	if status != statusOK {
 		// set tx error msg
 		err = errors.New(packet.GetResultMsg())
-		if status == statusFull {
+		if status == statusFull || isCreateOnOtherStatus(status) {
 			log.LogWarnf("txIcreate: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
 			return
 		}
@@ -213,7 +213,7 @@ func (mw *MetaWrapper) icreate(mp *MetaPartition, mode, uid, gid uint32, target
 	status = parseStatus(packet.ResultCode)
 	if status != statusOK {
 		err = errors.New(packet.GetResultMsg())
-		if status == statusFull {
+		if status == statusFull || isCreateOnOtherStatus(status) {
 			log.LogWarnf("icreate: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
 			return
 		}
@@ -972,6 +972,13 @@ func (mw *MetaWrapper) ddeletes(mp *MetaPartition, parentID uint64, dentries []p
 }
 
 func (mw *MetaWrapper) lookup(mp *MetaPartition, parentID uint64, name string, verSeq uint64) (status int, inode uint64, mode uint32, err error) {
+	status, inode, mode, _, err = mw.lookupWithSiblings(mp, parentID, name, verSeq, 0)
+	return
+}
+
+// lookupWithSiblings looks up the name, and returns up to siblingCnt dentries following it in
+// the parent with the inodes of them in the partition.
+func (mw *MetaWrapper) lookupWithSiblings(mp *MetaPartition, parentID uint64, name string, verSeq uint64, siblingCnt uint32) (status int, inode uint64, mode uint32, siblings []proto.LookupSibling, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("lookup", err, bgTime, 1)
@@ -983,6 +990,7 @@ func (mw *MetaWrapper) lookup(mp *MetaPartition, parentID uint64, name string, v
 		ParentID:    parentID,
 		Name:        name,
 		VerSeq:      verSeq,
+		Siblings:    siblingCnt,
 	}
 	packet := proto.NewPacketReqID()
 	packet.Opcode = proto.OpMetaLookup
@@ -1029,8 +1037,9 @@ func (mw *MetaWrapper) lookup(mp *MetaPartition, parentID uint64, name string, v
 		errMetric.AddWithLabels(1, map[string]string{exporter.Vol: mw.volname, exporter.Err: "EIO"})
 		return
 	}
-	log.LogDebugf("lookup exit: packet(%v) mp(%v) req(%v) ino(%v) mode(%v)", packet, mp, *req, resp.Inode, resp.Mode)
-	return statusOK, resp.Inode, resp.Mode, nil
+	log.LogDebugf("lookup exit: packet(%v) mp(%v) req(%v) ino(%v) mode(%v) siblings(%v)",
+		packet, mp, *req, resp.Inode, resp.Mode, len(resp.Siblings))
+	return statusOK, resp.Inode, resp.Mode, resp.Siblings, nil
 }
 
 func (mw *MetaWrapper) iget(mp *MetaPartition, inode uint64, verSeq uint64) (status int, info *proto.InodeInfo, err error) {
@@ -1207,9 +1216,60 @@ func (mw *MetaWrapper) readDirLimit(mp *MetaPartition, parentID uint64, from str
 	return statusOK, resp.Children, nil
 }
 
+// readDirPlus reads limit dentries of the dir from the name, with the inodes of them kept in
+// the partition, and the ones kept in others in remote.
+func (mw *MetaWrapper) readDirPlus(mp *MetaPartition, parentID uint64, from string, limit uint64) (status int, children []proto.DirentPlus, remote []uint64, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("readDirPlus", err, bgTime, 1)
+	}()
+
+	req := &proto.ReadDirPlusRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		ParentID:    parentID,
+		Marker:      from,
+		Limit:       limit,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaReadDirPlus
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("readDirPlus: req(%v) err(%v)", *req, err)
+		return
+	}
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("readDirPlus: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("readDirPlus: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.ReadDirPlusResponse)
+	err = packet.UnmarshalData(resp)
+	if err != nil {
+		log.LogErrorf("readDirPlus: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	log.LogDebugf("readDirPlus: packet(%v) mp(%v) req(%v) children(%v) remote(%v)", packet, mp, *req, len(resp.Children), len(resp.Remote))
+	return statusOK, resp.Children, resp.Remote, nil
+}
+
 func (mw *MetaWrapper) appendExtentKey(mp *MetaPartition, inode uint64, extent proto.ExtentKey,
-	discard []proto.ExtentKey, isSplit bool, isCache bool, storageClass uint32, isMigration bool,
-) (status int, err error) {
+	discard []proto.ExtentKey, gen uint64, isSplit bool, isCache bool, storageClass uint32, isMigration bool,
+) (status int, newGen uint64, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("appendExtentKey", err, bgTime, 1)
@@ -1225,6 +1285,7 @@ func (mw *MetaWrapper) appendExtentKey(mp *MetaPartition, inode uint64, extent p
 		IsCache:        isCache,
 		StorageClass:   storageClass,
 		IsMigration:    isMigration,
+		Generation:     gen,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -1250,11 +1311,19 @@ func (mw *MetaWrapper) appendExtentKey(mp *MetaPartition, inode uint64, extent p
 	status = parseStatus(packet.ResultCode)
 	if status != statusOK {
 		err = errors.New(packet.GetResultMsg())
-		if status != StatusConflictExtents {
+		if status != StatusConflictExtents && status != StatusStaleExtents {
 			log.LogErrorf("appendExtentKey: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
 		}
+		return status, 0, err
 	}
-	return status, err
+	// the meta nodes without the generation of the appends reply nothing
+	if len(packet.Data) > 0 {
+		resp := new(proto.AppendExtentKeyResponse)
+		if packet.UnmarshalData(resp) == nil {
+			newGen = resp.Generation
+		}
+	}
+	return status, newGen, nil
 }
 
 func (mw *MetaWrapper) getExtents(mp *MetaPartition, inode uint64, isCache bool, openForWrite, isMigration bool) (resp *proto.GetExtentsResponse, err error) {
@@ -1449,6 +1518,53 @@ func (mw *MetaWrapper) truncate(mp *MetaPartition, inode, size uint64, fullPath
 	return statusOK, nil
 }
 
+func (mw *MetaWrapper) extentsPreAlloc(mp *MetaPartition, inode, offset, size uint64, keepSize bool, fullPath string) (status int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("extentsPreAlloc", err, bgTime, 1)
+	}()
+
+	req := &proto.ExtentsPreAllocRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		Inode:       inode,
+		Offset:      offset,
+		Size:        size,
+		KeepSize:    keepSize,
+	}
+	req.FullPaths = []string{fullPath}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaExtentsPreAlloc
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("extentsPreAlloc: ino(%v) offset(%v) size(%v) err(%v)", inode, offset, size, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("extentsPreAlloc: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("extentsPreAlloc: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	log.LogDebugf("extentsPreAlloc exit: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
+
 func (mw *MetaWrapper) txIlink(tx *Transaction, mp *MetaPartition, inode uint64, fullPath string) (status int, info *proto.InodeInfo, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
@@ -1601,6 +1717,112 @@ func (mw *MetaWrapper) setattr(mp *MetaPartition, inode uint64, valid, mode, uid
 	return statusOK, nil
 }
 
+func (mw *MetaWrapper) updateLinkTarget(mp *MetaPartition, inode uint64, target []byte) (status int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("updateLinkTarget", err, bgTime, 1)
+	}()
+
+	req := &proto.UpdateLinkTargetRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		Inode:       inode,
+		Target:      target,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaUpdateLinkTarget
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("updateLinkTarget: err(%v)", err)
+		return
+	}
+
+	log.LogDebugf("updateLinkTarget enter: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("updateLinkTarget: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("updateLinkTarget: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	log.LogDebugf("updateLinkTarget exit: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
+
+func (mw *MetaWrapper) batchCreateDentryInode(mp *MetaPartition, items []*proto.CreateDentryInodeItem) (status int, results []*proto.CreateDentryInodeResult, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("batchCreateDentryInode", err, bgTime, 1)
+	}()
+
+	// use uniq id to dedup request
+	status, uniqID, err := mw.consumeUniqID(mp)
+	if err != nil || status != statusOK {
+		err = statusToErrno(status)
+		return
+	}
+
+	req := &proto.BatchCreateDentryInodeRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		UniqID:      uniqID,
+		Items:       items,
+		StorageType: mw.DefaultStorageClass,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaBatchCreateDentryInode
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("batchCreateDentryInode: err(%v)", err)
+		return
+	}
+
+	log.LogDebugf("batchCreateDentryInode enter: packet(%v) mp(%v) count(%v)", packet, mp, len(items))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("batchCreateDentryInode: packet(%v) mp(%v) count(%v) err(%v)", packet, mp, len(items), err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("batchCreateDentryInode: packet(%v) mp(%v) count(%v) result(%v)", packet, mp, len(items), packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.BatchCreateDentryInodeResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("batchCreateDentryInode: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+
+	log.LogDebugf("batchCreateDentryInode exit: packet(%v) mp(%v) count(%v)", packet, mp, len(items))
+	return statusOK, resp.Results, nil
+}
+
 func (mw *MetaWrapper) createMultipart(mp *MetaPartition, path string, extend map[string]string) (status int, multipartId string, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
@@ -2390,6 +2612,54 @@ func (mw *MetaWrapper) batchGetXAttr(mp *MetaPartition, inodes []uint64, keys []
 	return resp.XAttrs, nil
 }
 
+func (mw *MetaWrapper) scanXAttr(mp *MetaPartition, marker uint64, limit int, keys []string, prefix string) (xattrs []*proto.XAttrInfo, next uint64, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("scanXAttr", err, bgTime, 1)
+	}()
+
+	req := &proto.ScanXAttrRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		Marker:      marker,
+		Limit:       uint64(limit),
+		Keys:        keys,
+		Prefix:      prefix,
+		VerSeq:      mw.VerReadSeq,
+	}
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaScanXAttr
+	packet.PartitionID = mp.PartitionID
+	if err = packet.MarshalData(req); err != nil {
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("scanXAttr: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status := parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("scanXAttr: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.ScanXAttrResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("scanXAttr: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	return resp.XAttrs, resp.NextMarker, nil
+}
+
 func (mw *MetaWrapper) batchSetInodeQuota(mp *MetaPartition, inodes []uint64, quotaId uint32,
 	IsRoot bool,
 ) (resp *proto.BatchSetMetaserverQuotaResponse, err error) {
@@ -3034,3 +3304,90 @@ func (mw *MetaWrapper) UpdateInodeMeta(ino uint64) (err error) {
 	}
 	return err
 }
+
+func (mw *MetaWrapper) deleteSubtree(mp *MetaPartition, parentID uint64, name string) (status int, jobID uint64, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("deleteSubtree", err, bgTime, 1)
+	}()
+
+	req := &proto.DeleteSubtreeRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		ParentID:    parentID,
+		Name:        name,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaDeleteSubtree
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("deleteSubtree: err(%v)", err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("deleteSubtree: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("deleteSubtree: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.DeleteSubtreeResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("deleteSubtree: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	log.LogDebugf("deleteSubtree: packet(%v) mp(%v) req(%v) job(%v)", packet, mp, *req, resp.JobID)
+	return statusOK, resp.JobID, nil
+}
+
+func (mw *MetaWrapper) deleteSubtreeStatus(mp *MetaPartition, jobID uint64, cancel bool) (status int, job *proto.DeleteSubtreeJob, err error) {
+	req := &proto.DeleteSubtreeStatusRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		JobID:       jobID,
+		Cancel:      cancel,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaDeleteSubtreeStatus
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("deleteSubtreeStatus: err(%v)", err)
+		return
+	}
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("deleteSubtreeStatus: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("deleteSubtreeStatus: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	job = new(proto.DeleteSubtreeJob)
+	if err = packet.UnmarshalData(job); err != nil {
+		log.LogErrorf("deleteSubtreeStatus: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	return statusOK, job, nil
+}
@@ -111,6 +111,28 @@ func (mw *MetaWrapper) getPartitionByInode(ino uint64) *MetaPartition {
 //	return rwPartitions
 //}
 
+// isCreateOnOtherStatus returns whether the partition takes no new inodes, and the inode is
+// created on another partition at once.
+func isCreateOnOtherStatus(status int) bool {
+	return status == statusCursorExhausted || status == statusPartitionFrozen
+}
+
+// dropRWPartition stops creating inodes on the partition until the partitions are updated
+// from the master, the last writable partition is kept.
+func (mw *MetaWrapper) dropRWPartition(mp *MetaPartition) {
+	mw.Lock()
+	defer mw.Unlock()
+	rwPartitions := make([]*MetaPartition, 0, len(mw.rwPartitions))
+	for _, rw := range mw.rwPartitions {
+		if rw.PartitionID != mp.PartitionID {
+			rwPartitions = append(rwPartitions, rw)
+		}
+	}
+	if len(rwPartitions) > 0 && len(rwPartitions) < len(mw.rwPartitions) {
+		mw.rwPartitions = rwPartitions
+	}
+}
+
 func (mw *MetaWrapper) getRWPartitions() []*MetaPartition {
 	mw.RLock()
 	defer mw.RUnlock()
@@ -0,0 +1,126 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The trash of a subtree is turned off by setting TrashXAttrKey of the dir to TrashXAttrOff,
+// the entries deleted below it are deleted directly. The xattr of the nearest dir having it
+// wins, TrashXAttrOn turns the trash on again for a subtree of a dir turned off.
+const (
+	TrashXAttrKey = "cfs.trash"
+	TrashXAttrOff = "off"
+	TrashXAttrOn  = "on"
+
+	trashXAttrExpiration = 30 * time.Second
+)
+
+type trashXAttr struct {
+	value  string
+	expire time.Time
+}
+
+// trashXAttrCache caches TrashXAttrKey of the dirs, so that a delete does not get the xattrs
+// of all the dirs above the entry.
+type trashXAttrCache struct {
+	sync.RWMutex
+	dirs map[uint64]trashXAttr
+}
+
+func (c *trashXAttrCache) get(ino uint64) (value string, ok bool) {
+	c.RLock()
+	defer c.RUnlock()
+	attr, ok := c.dirs[ino]
+	if !ok || time.Now().After(attr.expire) {
+		return "", false
+	}
+	return attr.value, true
+}
+
+func (c *trashXAttrCache) put(ino uint64, value string) {
+	c.Lock()
+	defer c.Unlock()
+	if c.dirs == nil {
+		c.dirs = make(map[uint64]trashXAttr)
+	}
+	now := time.Now()
+	for dir, attr := range c.dirs {
+		if now.After(attr.expire) {
+			delete(c.dirs, dir)
+		}
+	}
+	c.dirs[ino] = trashXAttr{value: value, expire: now.Add(trashXAttrExpiration)}
+}
+
+func (c *trashXAttrCache) delete(ino uint64) {
+	c.Lock()
+	delete(c.dirs, ino)
+	c.Unlock()
+}
+
+func (mw *MetaWrapper) getTrashXAttr(ino uint64) (value string, err error) {
+	if value, ok := mw.trashXAttrs.get(ino); ok {
+		return value, nil
+	}
+	info, err := mw.XAttrGet_ll(ino, TrashXAttrKey)
+	if err != nil {
+		return
+	}
+	value = info.XAttrs[TrashXAttrKey]
+	mw.trashXAttrs.put(ino, value)
+	return
+}
+
+// isTrashOffFor tells whether the trash is turned off for the entry deleted from parentIno,
+// by the xattr of the entry if it is a dir, or of the nearest dir above it having the xattr.
+// The dirs are walked up by the dir cache, the trash is kept on if a dir is unknown.
+func (mw *MetaWrapper) isTrashOffFor(parentIno, ino uint64, isDir bool) bool {
+	dirs := make([]uint64, 0)
+	if isDir {
+		dirs = append(dirs, ino)
+	}
+	dirs = append(dirs, parentIno)
+	for dir := parentIno; dir != mw.rootIno; {
+		mw.inoInfoLk.RLock()
+		node, ok := mw.dirCache[dir]
+		mw.inoInfoLk.RUnlock()
+		if !ok {
+			break
+		}
+		dir = node.parentIno
+		dirs = append(dirs, dir)
+	}
+
+	for _, dir := range dirs {
+		value, err := mw.getTrashXAttr(dir)
+		if err != nil {
+			log.LogWarnf("isTrashOffFor: get xattr of dir(%v) failed: %v", dir, err)
+			return false
+		}
+		switch value {
+		case TrashXAttrOff:
+			log.LogDebugf("isTrashOffFor: trash is off by dir(%v), parentIno(%v) ino(%v)", dir, parentIno, ino)
+			return true
+		case TrashXAttrOn:
+			return false
+		}
+	}
+	return false
+}
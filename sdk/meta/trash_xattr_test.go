@@ -0,0 +1,46 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTrashOffFor(t *testing.T) {
+	// /tmp(2)/keep(3)/f, /tmp(2)/x(4)/f
+	mw := &MetaWrapper{rootIno: 1, dirCache: map[uint64]dirInfoCache{
+		2: {ino: 2, parentIno: 1, name: "tmp"},
+		3: {ino: 3, parentIno: 2, name: "keep"},
+		4: {ino: 4, parentIno: 2, name: "x"},
+	}}
+	for ino, value := range map[uint64]string{1: "", 2: TrashXAttrOff, 3: TrashXAttrOn, 4: "", 5: ""} {
+		mw.trashXAttrs.put(ino, value)
+	}
+
+	require.True(t, mw.isTrashOffFor(2, 10, false))
+	require.True(t, mw.isTrashOffFor(4, 10, false))
+	require.False(t, mw.isTrashOffFor(3, 10, false))
+	require.False(t, mw.isTrashOffFor(1, 5, true))
+	// a dir with the xattr off itself
+	require.True(t, mw.isTrashOffFor(1, 2, true))
+
+	mw.trashXAttrs.put(2, "")
+	require.False(t, mw.isTrashOffFor(4, 10, false))
+	mw.trashXAttrs.delete(2)
+	_, ok := mw.trashXAttrs.get(2)
+	require.False(t, ok)
+}
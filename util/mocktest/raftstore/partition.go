@@ -106,6 +106,20 @@ func (mr *MockPartitionMockRecorder) Delete() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPartition)(nil).Delete))
 }
 
+// FirstIndex mocks base method.
+func (m *MockPartition) FirstIndex() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FirstIndex")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// FirstIndex indicates an expected call of FirstIndex.
+func (mr *MockPartitionMockRecorder) FirstIndex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FirstIndex", reflect.TypeOf((*MockPartition)(nil).FirstIndex))
+}
+
 // IsOfflinePeer mocks base method.
 func (m *MockPartition) IsOfflinePeer() bool {
 	m.ctrl.T.Helper()
@@ -231,3 +245,17 @@ func (mr *MockPartitionMockRecorder) TryToLeader(nodeID interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryToLeader", reflect.TypeOf((*MockPartition)(nil).TryToLeader), nodeID)
 }
+
+// WalPath mocks base method.
+func (m *MockPartition) WalPath() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalPath")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// WalPath indicates an expected call of WalPath.
+func (mr *MockPartitionMockRecorder) WalPath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalPath", reflect.TypeOf((*MockPartition)(nil).WalPath))
+}
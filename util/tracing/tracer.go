@@ -0,0 +1,238 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing samples the requests across the client and the nodes and exports their spans.
+// The spans are the opentracing spans of blobstore/common/trace, a span carries the trace of the
+// request it belongs to, the spans of a trace are joined by the trace id once exported to the log
+// of each process.
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/opentracing/opentracing-go"
+)
+
+const DefaultRecentSpans = 1024
+
+const eventKey = "event"
+
+// Event is a step of the span, at the offset from its start.
+type Event struct {
+	Name   string        `json:"name"`
+	Offset time.Duration `json:"offset"`
+}
+
+// Record is a span finished and exported.
+type Record struct {
+	Service  string            `json:"service"`
+	Name     string            `json:"name"`
+	TraceID  uint64            `json:"traceId"`
+	SpanID   uint64            `json:"spanId"`
+	ParentID uint64            `json:"parentId,omitempty"`
+	Start    time.Time         `json:"start"`
+	Duration time.Duration     `json:"duration"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Events   []Event           `json:"events,omitempty"`
+}
+
+// Span is a traced operation of a process.
+type Span struct {
+	span     trace.Span
+	traceID  uint64
+	spanID   uint64
+	parentID uint64
+	start    time.Time
+
+	tracer *Tracer
+}
+
+// Tracer samples the requests of a process and exports their spans, it keeps the recent ones
+// to be looked up.
+type Tracer struct {
+	service    string
+	tracer     *trace.Tracer
+	sampleRate uint64 // math.Float64bits of the ratio of the requests traced
+
+	mu     sync.Mutex
+	recent []*Record
+	next   int
+}
+
+// NewTracer returns a tracer of the service sampling the requests at the rate in [0, 1].
+func NewTracer(service string, sampleRate float64, recentSpans int) *Tracer {
+	if recentSpans <= 0 {
+		recentSpans = DefaultRecentSpans
+	}
+	t := &Tracer{service: service, tracer: trace.NewTracer(service), recent: make([]*Record, 0, recentSpans)}
+	t.SetSampleRate(sampleRate)
+	return t
+}
+
+func (t *Tracer) SetSampleRate(rate float64) {
+	if rate < 0 || math.IsNaN(rate) {
+		rate = 0
+	} else if rate > 1 {
+		rate = 1
+	}
+	atomic.StoreUint64(&t.sampleRate, math.Float64bits(rate))
+}
+
+func (t *Tracer) SampleRate() float64 {
+	if t == nil {
+		return 0
+	}
+	return math.Float64frombits(atomic.LoadUint64(&t.sampleRate))
+}
+
+// Sampled tells whether a request not traced yet is traced.
+func (t *Tracer) Sampled() bool {
+	rate := t.SampleRate()
+	return rate > 0 && (rate >= 1 || rand.Float64() < rate)
+}
+
+// StartSpan starts a span of a new trace if the request is sampled, nil otherwise.
+func (t *Tracer) StartSpan(name string) *Span {
+	if !t.Sampled() {
+		return nil
+	}
+	return t.startSpan(name, 0)
+}
+
+// StartChildSpan starts a span of the trace the request is in, a nil tracer traces nothing.
+func (t *Tracer) StartChildSpan(name string, traceID, parentID uint64) *Span {
+	if t == nil || traceID == 0 {
+		return nil
+	}
+	parent, err := t.tracer.Extract(trace.TextMap, trace.TextMapCarrier{
+		trace.FieldKeyTraceID: trace.ID(traceID).String(),
+		trace.FieldKeySpanID:  trace.ID(parentID).String(),
+	})
+	if err != nil {
+		log.LogWarnf("StartChildSpan: trace(%v) parent(%v) extract err(%v)", traceID, parentID, err)
+		return nil
+	}
+	return t.startSpan(name, parentID, opentracing.ChildOf(parent))
+}
+
+func (t *Tracer) startSpan(name string, parentID uint64, opts ...opentracing.StartSpanOption) *Span {
+	s := &Span{parentID: parentID, start: time.Now(), tracer: t}
+	s.span = t.tracer.StartSpan(name, append(opts, trace.StartTime(s.start))...).(trace.Span)
+	carrier := trace.TextMapCarrier{}
+	if err := t.tracer.Inject(s.span.Context(), trace.TextMap, carrier); err == nil {
+		s.traceID, _ = strconv.ParseUint(carrier[trace.FieldKeyTraceID], 16, 64)
+		s.spanID, _ = strconv.ParseUint(carrier[trace.FieldKeySpanID], 16, 64)
+	}
+	if s.traceID == 0 || s.spanID == 0 {
+		return nil
+	}
+	return s
+}
+
+// IDs returns the trace of the span and the span, to be carried to the receiver of the request.
+func (s *Span) IDs() (traceID, spanID uint64) {
+	return s.traceID, s.spanID
+}
+
+// SetAttr sets an attribute of the span, the methods of a nil span do nothing.
+func (s *Span) SetAttr(key, value string) {
+	if s == nil {
+		return
+	}
+	s.span.SetTag(key, value)
+}
+
+// Event records the step of the span done now.
+func (s *Span) Event(name string) {
+	if s == nil {
+		return
+	}
+	s.span.LogKV(eventKey, name)
+}
+
+// Finish ends the span and exports it.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.span.FinishWithOptions(opentracing.FinishOptions{FinishTime: end})
+	s.tracer.export(s.record(end))
+}
+
+func (s *Span) record(end time.Time) *Record {
+	r := &Record{
+		Service:  s.tracer.service,
+		Name:     s.span.OperationName(),
+		TraceID:  s.traceID,
+		SpanID:   s.spanID,
+		ParentID: s.parentID,
+		Start:    s.start,
+		Duration: end.Sub(s.start),
+	}
+	if tags := s.span.Tags(); len(tags) > 0 {
+		r.Attrs = make(map[string]string, len(tags))
+		for k, v := range tags {
+			r.Attrs[k] = fmt.Sprint(v)
+		}
+	}
+	for _, l := range s.span.Logs() {
+		for _, f := range l.Fields {
+			if f.Key() == eventKey {
+				r.Events = append(r.Events, Event{Name: fmt.Sprint(f.Value()), Offset: l.Timestamp.Sub(s.start)})
+			}
+		}
+	}
+	return r
+}
+
+func (t *Tracer) export(r *Record) {
+	t.mu.Lock()
+	if len(t.recent) < cap(t.recent) {
+		t.recent = append(t.recent, r)
+	} else {
+		t.recent[t.next] = r
+		t.next = (t.next + 1) % cap(t.recent)
+	}
+	t.mu.Unlock()
+	if data, err := json.Marshal(r); err == nil {
+		log.LogInfof("[trace] %s", data)
+	}
+}
+
+// Recent returns the recent spans of the trace, of all the traces if traceID is 0.
+func (t *Tracer) Recent(traceID uint64) (records []*Record) {
+	records = make([]*Record, 0)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.recent {
+		r := t.recent[(t.next+i)%len(t.recent)]
+		if traceID == 0 || r.TraceID == traceID {
+			records = append(records, r)
+		}
+	}
+	return
+}
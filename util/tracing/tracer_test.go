@@ -0,0 +1,72 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing_test
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/util/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer(t *testing.T) {
+	var nilTracer *tracing.Tracer
+	require.False(t, nilTracer.Sampled())
+	require.Nil(t, nilTracer.StartChildSpan("op", 1, 2))
+	require.Empty(t, nilTracer.Recent(0))
+
+	tracer := tracing.NewTracer("test", 0, 2)
+	require.Nil(t, tracer.StartSpan("op"))
+	// the methods of a span not sampled do nothing
+	var span *tracing.Span
+	span.SetAttr("k", "v")
+	span.Event("e")
+	span.Finish()
+
+	tracer.SetSampleRate(2)
+	require.EqualValues(t, 1, tracer.SampleRate())
+	root := tracer.StartSpan("client")
+	require.NotNil(t, root)
+	rootTrace, rootSpan := root.IDs()
+	require.NotZero(t, rootTrace)
+	require.NotZero(t, rootSpan)
+
+	child := tracer.StartChildSpan("server", rootTrace, rootSpan)
+	child.SetAttr("op", "lookup")
+	child.Event("handled")
+	child.Finish()
+	root.Finish()
+
+	spans := tracer.Recent(rootTrace)
+	require.Len(t, spans, 2)
+	require.Equal(t, "server", spans[0].Name)
+	require.Equal(t, rootTrace, spans[0].TraceID)
+	require.Equal(t, rootSpan, spans[0].ParentID)
+	require.Len(t, spans[0].Events, 1)
+	require.Equal(t, "handled", spans[0].Events[0].Name)
+	require.Equal(t, "lookup", spans[0].Attrs["op"])
+	require.Equal(t, "client", spans[1].Name)
+	require.Zero(t, spans[1].ParentID)
+
+	other := tracer.StartSpan("other")
+	other.Finish()
+	otherTrace, _ := other.IDs()
+	// the oldest span is dropped once the recent ones are full
+	spans = tracer.Recent(0)
+	require.Len(t, spans, 2)
+	require.Equal(t, "client", spans[0].Name)
+	require.Equal(t, otherTrace, spans[1].TraceID)
+	require.Len(t, tracer.Recent(rootTrace), 1)
+}